@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -26,10 +28,14 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/entropy"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/googleapikey"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/jwt"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/tui"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/updater"
@@ -43,12 +49,18 @@ var (
 	trace               = cli.Flag("trace", "Run in trace mode.").Bool()
 	profile             = cli.Flag("profile", "Enables profiling and sets a pprof and fgprof server on :18066.").Bool()
 	localDev            = cli.Flag("local-dev", "Hidden feature to disable overseer for local dev.").Hidden().Bool()
-	jsonOut             = cli.Flag("json", "Output in JSON format.").Short('j').Bool()
+	jsonOut             = cli.Flag("json", "Output in JSON format, as one JSON object per line written as soon as each result is found (NDJSON).").Short('j').Bool()
+	ndjsonOut           = cli.Flag("ndjson", "Output in newline-delimited JSON (NDJSON) format. Alias of --json.").Bool()
+	jsonRedaction       = cli.Flag("json-redaction", "How to write the Raw, RawV2 and Redacted fields of results, and the passwords, tokens and URIs of their ExtraData, with --json: none, omit, or a hash algorithm (sha256, sha384, sha512) to replace the raw secrets by their hash.").Default("none").Enum(output.RedactionPolicies...)
 	jsonLegacy          = cli.Flag("json-legacy", "Use the pre-v3.0 JSON format. Only works with git, gitlab, and github sources.").Bool()
 	gitHubActionsFormat = cli.Flag("github-actions", "Output in GitHub Actions format.").Bool()
-	concurrency         = cli.Flag("concurrency", "Number of concurrent workers.").Default(strconv.Itoa(runtime.NumCPU())).Int()
+	sarifOut            = cli.Flag("sarif", "Output in SARIF 2.1.0 format.").Bool()
+	concurrency         = cli.Flag("concurrency", "Number of concurrent workers.").Default(strconv.Itoa(runtime.GOMAXPROCS(0))).Int()
+	autoTuneConcurrency = cli.Flag("auto-tune-concurrency", "Fetch the units of network-bound sources, such as S3 buckets or GitHub repositories, with 4 times as many workers as --concurrency, which defaults to the number of usable CPUs. --source-concurrency takes precedence.").Bool()
+	sourceConcurrency   = cli.Flag("source-concurrency", "Number of units, such as repositories, buckets or files, each source fetches concurrently. 0 defaults to --concurrency, or to the auto-tuned concurrency with --auto-tune-concurrency.").Default("0").Int()
 	noVerification      = cli.Flag("no-verification", "Don't verify the results.").Bool()
 	onlyVerified        = cli.Flag("only-verified", "Only output verified results.").Bool()
+	verifiedDetails     = cli.Flag("only-verified-with-details", "Only output verified results, including the status and up to 4KB of the body of the HTTP response that verified them. Response bodies may contain sensitive data.").Bool()
 	results             = cli.Flag("results", "Specifies which type(s) of results to output: verified, unknown, unverified. Defaults to all types.").Hidden().String()
 
 	allowVerificationOverlap   = cli.Flag("allow-verification-overlap", "Allow verification of similar credentials across detectors").Bool()
@@ -61,14 +73,57 @@ var (
 	printAvgDetectorTime = cli.Flag("print-avg-detector-time", "Print the average time spent on each detector.").Bool()
 	noUpdate             = cli.Flag("no-update", "Don't check for updates.").Bool()
 	fail                 = cli.Flag("fail", "Exit with code 183 if results are found.").Bool()
+	failVerified         = cli.Flag("fail-verified", "Exit with code 183 if verified results are found.").Bool()
+	failUnverified       = cli.Flag("fail-unverified", "Exit with code 184 if unverified results are found and no verified result triggered an exit.").Bool()
 	verifiers            = cli.Flag("verifier", "Set custom verification endpoints.").StringMap()
 	customVerifiersOnly  = cli.Flag("custom-verifiers-only", "Only use custom verification endpoints.").Bool()
+	verifyDatabases      = cli.Flag("verify-database-connections", "Verify PostgreSQL and MySQL connection URIs by connecting to their databases. The connections are closed once authenticated, without running any query.").Bool()
+	noGoogleKeyProbes    = cli.Flag("no-google-api-key-probes", "Don't verify Google API keys. Keys are verified by calling the Firebase Authentication API with them.").Bool()
+	googleKeyGeocoding   = cli.Flag("google-api-key-geocoding", "Also verify Google API keys by calling the Maps Geocoding API with them. Geocoding requests count towards the quota of the key's project.").Bool()
+	jwtPublicKeys        = cli.Flag("jwt-public-key", "Path to a PEM public key, certificate, or JWKS file used to verify the signature of JWTs. JWKS URLs can be set with --verifier jwt=<url>. You can repeat this flag.").ExistingFiles()
+	entropyDetector      = cli.Flag("entropy-detector", "Enable the catch-all detector of long hex and base64 strings with a high Shannon entropy. Its results are low-confidence and are never verified.").Bool()
+	entropyHexThreshold  = cli.Flag("entropy-hex-threshold", "Minimum Shannon entropy, in bits per character, of the hex strings reported by the entropy detector. Hex strings have at most 4 bits per character.").Default("3.0").Float64()
+	entropyB64Threshold  = cli.Flag("entropy-base64-threshold", "Minimum Shannon entropy, in bits per character, of the base64 strings reported by the entropy detector. Base64 strings have at most 6 bits per character.").Default("4.3").Float64()
+	entropyMinLength     = cli.Flag("entropy-min-length", "Minimum length of the strings reported by the entropy detector.").Default("20").Int()
+	entropyCharsets      = cli.Flag("entropy-charset", "Comma separated list of the alphabets the entropy detector looks for: hex, base64. Defaults to both.").String()
 	archiveMaxSize       = cli.Flag("archive-max-size", "Maximum size of archive to scan. (Byte units eg. 512B, 2KB, 4MB)").Bytes()
-	archiveMaxDepth      = cli.Flag("archive-max-depth", "Maximum depth of archive to scan.").Int()
+	archiveMaxDepth      = cli.Flag("archive-max-depth", "Maximum depth of nested archives to scan. Extraction halts once exceeded.").Default("5").Int()
+	scanExtensions       = cli.Flag("scan-extension", "Only scan files and archive entries with this extension, such as .env or json. Archives are still extracted. Can be repeated.").Strings()
+	archiveEntryGlobs    = cli.Flag("archive-entry-glob", "Only scan archive entries whose path matches this glob. Nested archives are always extracted. Can be repeated.").Strings()
 	archiveTimeout       = cli.Flag("archive-timeout", "Maximum time to spend extracting an archive.").Duration()
 	includeDetectors     = cli.Flag("include-detectors", "Comma separated list of detector types to include. Protobuf name or IDs may be used, as well as ranges.").Default("all").String()
+	decoderNames         = cli.Flag("decoders", "Comma separated, ordered list of decoders to run on each chunk: plain, base64, utf16, utf32, escaped_unicode. Defaults to all decoders.").String()
 	excludeDetectors     = cli.Flag("exclude-detectors", "Comma separated list of detector types to exclude. Protobuf name or IDs may be used, as well as ranges. IDs defined here take precedence over the include list.").String()
+	detectorCategories   = cli.Flag("detector-category", "Comma separated list of detector categories to include, e.g. cloud,payment. Only detectors that are in one of the categories and in the include list run. --dry-run lists the categories.").String()
+	excludeCategories    = cli.Flag("exclude-detector-category", "Comma separated list of detector categories to exclude. Categories defined here take precedence over the included categories.").String()
 	jobReportFile        = cli.Flag("output-report", "Write a scan report to the provided path.").Hidden().OpenFile(os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	verificationCAFile   = cli.Flag("verification-ca-file", "Path to a PEM bundle of additional CA certificates to trust when making HTTP requests. Proxies are configured with the HTTPS_PROXY environment variable.").ExistingFile()
+	verificationTimeout  = cli.Flag("verification-timeout", "Timeout of each HTTP request made to verify a result.").Duration()
+	verificationRetries  = cli.Flag("verification-retries", "Maximum number of attempts at each HTTP request made to verify a result. Requests are retried on connection errors and 429 and 5xx responses.").Default("3").Int()
+	verificationBackoff  = cli.Flag("verification-retry-delay", "Delay before retrying a verification request. It doubles with each retry.").Default("500ms").Duration()
+	verificationRates    = cli.Flag("verification-rate-limit", "Override the requests per second that verification requests may make to a host, e.g. api.github.com=5. Use 0 to remove a host's default limit. You can repeat this flag.").StringMap()
+	noVerificationCache  = cli.Flag("no-verification-cache", "Verify every occurrence of a secret instead of reusing the outcome of verifying the same secret earlier in the scan.").Bool()
+	resumeFile           = cli.Flag("resume-file", "Path to a checkpoint file. Fully scanned source units are recorded to it and skipped when rerunning with the same arguments. S3 buckets resume listing from the last scanned page.").String()
+	detectorTimeout      = cli.Flag("detector-timeout", "Maximum time a detector may spend finding and verifying the secrets of a single match. Secrets whose verification times out are reported as unverified.").Default("10s").Duration()
+	detectorTimeouts     = cli.Flag("detector-timeouts", "Override the detector timeout for a detector type, e.g. aws=30s. You can repeat this flag.").StringMap()
+	maxScanDuration      = cli.Flag("max-scan-duration", "Stop scanning after this duration, e.g. 15m, and report the results found so far. The scan is marked incomplete in the JSON and SARIF output, and exits with code 185 unless --fail flags exit with 183 or 184 for the results found, so check the output or --source-stats-file rather than the exit code. 0 means no limit.").Default("0").Duration()
+	resultsLimit         = cli.Flag("results-limit", "Stop scanning once this number of results is found, and report them. Which results are reported varies from one scan to the next, as it depends on which workers find them first. 0 means no limit.").Default("0").Int()
+	resultsLimitVerified = cli.Flag("results-limit-verified", "Only count verified results towards --results-limit.").Bool()
+	continueOnSourceErr  = cli.Flag("continue-on-source-error", "Skip the repositories, buckets and other parts of sources that fail to be listed or scanned, such as deleted or inaccessible repositories, instead of failing the scan. They're summarized when the scan finishes.").Bool()
+	sourceStatsFile      = cli.Flag("source-stats-file", "Write per-source scan statistics as JSON to this file when the scan completes.").String()
+	printSummary         = cli.Flag("summary", "Print the number of results by detector and verification status to stderr when the scan completes. With --dedup-results, each distinct secret is counted once.").Bool()
+	dedupResults         = cli.Flag("dedup-results", "Report each distinct secret once per detector, listing every location it was found in. Results are printed when the scan finishes.").Bool()
+	redactedContext      = cli.Flag("redacted-context", "Include the lines around each result found in a file-based source in its extra data under \"context\", with the secret redacted. Multi-part credentials, such as AWS keys, get no context.").Bool()
+	contextLines         = cli.Flag("context-lines", "Number of lines before and after the secret included with --redacted-context.").Default("2").Int()
+	chunkOverlap         = cli.Flag("chunk-overlap", "Number of bytes consecutive chunks overlap by, so secrets split across chunks are found. It's raised to the longest secret a detector can match. Larger overlaps use more memory per chunk. (Byte units eg. 4KB)").Bytes()
+	chunkBuffer          = cli.Flag("chunk-buffer", "Number of chunks buffered between the sources and the detector workers. Sources wait when the buffer is full, which bounds memory usage.").Default("64").Int()
+	progressInterval     = cli.Flag("progress-interval", "Interval at which the progress of the scan, with an ETA once the total is known, is written to stderr. 0 disables it.").Default("30s").Duration()
+	quiet                = cli.Flag("quiet", "Don't write the progress of the scan to stderr.").Bool()
+	allowlistFile        = cli.Flag("allowlist", "Path to a file of fingerprints of known or accepted secrets, one per line, optionally followed by a path glob. Their results are suppressed and don't affect the exit code. Fingerprints are printed with each result.").ExistingFile()
+	showSuppressed       = cli.Flag("show-suppressed", "Print the results suppressed by --allowlist, marked as suppressed.").Bool()
+	anonymizePaths       = cli.Flag("anonymize-paths", "Replace the file paths, repository and link URLs, and host, image, bucket, project and channel names of results by tokens, so that results can be shared without revealing them. Paths keep their extension, and the same value gets the same token within a scan.").Bool()
+	anonymizeMapping     = cli.Flag("anonymize-paths-mapping", "Write each token of --anonymize-paths and the value it replaces, as JSON lines, to this file to map tokens back. Implies --anonymize-paths.").OpenFile(os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	dryRun               = cli.Flag("dry-run", "Initialize the sources and detectors, print the enabled detectors and the source units that would be scanned, and exit without scanning.").Bool()
 
 	gitScan             = cli.Command("git", "Find credentials in git repositories.")
 	gitScanURI          = gitScan.Arg("uri", "Git repository URL. https://, file://, or ssh:// schema expected.").Required().String()
@@ -77,8 +132,17 @@ var (
 	gitScanExcludeGlobs = gitScan.Flag("exclude-globs", "Comma separated list of globs to exclude in scan. This option filters at the `git log` level, resulting in faster scans.").String()
 	gitScanSinceCommit  = gitScan.Flag("since-commit", "Commit to start scan from.").String()
 	gitScanBranch       = gitScan.Flag("branch", "Branch to scan.").String()
-	gitScanMaxDepth     = gitScan.Flag("max-depth", "Maximum depth of commits to scan.").Int()
+	gitScanMaxDepth     = gitScan.Flag("max-depth", "Maximum number of most recent commits to scan on each scanned branch or ref. Secrets introduced and removed in older commits are missed.").Int()
 	gitScanBare         = gitScan.Flag("bare", "Scan bare repository (e.g. useful while using in pre-receive hooks)").Bool()
+	gitScanSubmodules   = gitScan.Flag("include-submodules", "Scan initialized submodules of the repository.").Bool()
+	gitScanRefs         = gitScan.Flag("ref", "Branch, tag, or other ref to scan (e.g. refs/pull/123/head). Only commits reachable from the given refs are scanned. You can repeat this flag.").Strings()
+	gitScanCommitMsgs   = gitScan.Flag("commit-messages", "Scan each commit message as its own chunk.").Bool()
+	gitScanFetchLFS     = gitScan.Flag("fetch-lfs", "Download the files referenced by Git LFS pointers from the repository's LFS server and scan them instead of the pointers.").Bool()
+	gitScanMaxLFSSize   = gitScan.Flag("max-lfs-size", "Maximum size of Git LFS files to download with --fetch-lfs. (Byte units eg. 512B, 2KB, 4MB)").Default("100MB").Bytes()
+	gitScanWorkingTree  = gitScan.Flag("working-tree-only", "Scan only the staged and unstaged changes and the untracked files of a local repository, skipping its history and binary files (e.g. useful in pre-commit hooks).").Bool()
+	gitScanSinceDate    = gitScan.Flag("since-date", "Only report secrets on lines introduced by commits authored at or after this date or RFC 3339 timestamp, e.g. 2024-01-02. Lines are attributed with git blame, following moves and copies, which makes scans much slower.").String()
+	gitScanNotes        = gitScan.Flag("notes", "Scan the notes of all notes refs (refs/notes/*), attributed to the commits they annotate.").Bool()
+	gitScanStashes      = gitScan.Flag("stashes", "Scan the staged, unstaged and untracked changes of every entry of the stash (refs/stash) of a local repository.").Bool()
 	_                   = gitScan.Flag("allow", "No-op flag for backwards compat.").Bool()
 	_                   = gitScan.Flag("entropy", "No-op flag for backwards compat.").Bool()
 	_                   = gitScan.Flag("regex", "No-op flag for backwards compat.").Bool()
@@ -99,6 +163,9 @@ var (
 	githubScanIssueComments = githubScan.Flag("issue-comments", "Include issue descriptions and comments in scan.").Bool()
 	githubScanPRComments    = githubScan.Flag("pr-comments", "Include pull request descriptions and comments in scan.").Bool()
 	githubScanGistComments  = githubScan.Flag("gist-comments", "Include gist comments in scan.").Bool()
+	githubScanStateFile     = githubScan.Flag("incremental-state-file", "Path to a file recording the last scanned commit of each repository. Only commits made since the previous scan are scanned.").String()
+	githubScanCommitMsgs    = githubScan.Flag("commit-messages", "Scan each commit message as its own chunk.").Bool()
+	githubScanPullRequest   = githubScan.Flag("pr", "Number of a pull request to scan. Only the lines added by the pull request are scanned. Requires a single --repo.").Int()
 
 	gitlabScan = cli.Command("gitlab", "Find credentials in GitLab repositories.")
 	// TODO: Add more GitLab options
@@ -107,6 +174,10 @@ var (
 	gitlabScanToken        = gitlabScan.Flag("token", "GitLab token. Can be provided with environment variable GITLAB_TOKEN.").Envar("GITLAB_TOKEN").Required().String()
 	gitlabScanIncludePaths = gitlabScan.Flag("include-paths", "Path to file with newline separated regexes for files to include in scan.").Short('i').String()
 	gitlabScanExcludePaths = gitlabScan.Flag("exclude-paths", "Path to file with newline separated regexes for files to exclude in scan.").Short('x').String()
+	gitlabScanMRs          = gitlabScan.Flag("merge-requests", "Also scan the descriptions and notes of merge requests.").Bool()
+	gitlabScanGroups       = gitlabScan.Flag("group", "GitLab group ID or full path whose projects, including those of its subgroups, are scanned when no repo is given. You can repeat this flag.").Strings()
+	gitlabScanSkipArchived = gitlabScan.Flag("skip-archived", "Skip archived projects when enumerating projects.").Bool()
+	gitlabScanLastActivity = gitlabScan.Flag("last-activity-after", "Only scan enumerated projects with activity since this RFC 3339 timestamp, e.g. 2024-01-02T15:04:05Z.").String()
 
 	filesystemScan  = cli.Command("filesystem", "Find credentials in a filesystem.")
 	filesystemPaths = filesystemScan.Arg("path", "Path to file or directory to scan.").Strings()
@@ -116,16 +187,29 @@ var (
 	// filesystemScanRecursive = filesystemScan.Flag("recursive", "Scan recursively.").Short('r').Bool()
 	filesystemScanIncludePaths = filesystemScan.Flag("include-paths", "Path to file with newline separated regexes for files to include in scan.").Short('i').String()
 	filesystemScanExcludePaths = filesystemScan.Flag("exclude-paths", "Path to file with newline separated regexes for files to exclude in scan.").Short('x').String()
+	filesystemScanIncludeRegex = filesystemScan.Flag("include-paths-regex", "Regex matched against paths relative to the scanned directory for files to include in scan. You can repeat this flag.").Strings()
+	filesystemScanExcludeRegex = filesystemScan.Flag("exclude-paths-regex", "Regex matched against paths relative to the scanned directory for files to exclude from scan. You can repeat this flag.").Strings()
+	filesystemScanSymlinks     = filesystemScan.Flag("follow-symlinks", "Follow symlinks instead of skipping them. Symlinks that loop back to a directory being scanned are skipped.").Bool()
+	filesystemScanConfine      = filesystemScan.Flag("confine-to-root", "Skip followed symlinks that point outside the scanned path.").Bool()
+	filesystemScanSkipBinary   = filesystemScan.Flag("skip-binaries", "Skip binary files, unless they are archives or other formats trufflehog extracts content from.").Bool()
+	filesystemScanBinaryExts   = filesystemScan.Flag("allow-binary-extension", "Extension of binary files to scan even with --skip-binaries. You can repeat this flag.").Strings()
+	filesystemBufferThreshold  = filesystemScan.Flag("buffer-threshold", "Size above which files are scanned as they're read, or extracted from a temporary file for archives, instead of being held in memory.").Default("10MB").Bytes()
 
 	s3Scan              = cli.Command("s3", "Find credentials in S3 buckets.")
 	s3ScanKey           = s3Scan.Flag("key", "S3 key used to authenticate. Can be provided with environment variable AWS_ACCESS_KEY_ID.").Envar("AWS_ACCESS_KEY_ID").String()
 	s3ScanRoleArns      = s3Scan.Flag("role-arn", "Specify the ARN of an IAM role to assume for scanning. You can repeat this flag.").Strings()
+	s3ScanExternalID    = s3Scan.Flag("external-id", "External ID to pass when assuming the roles given with --role-arn.").String()
 	s3ScanSecret        = s3Scan.Flag("secret", "S3 secret used to authenticate. Can be provided with environment variable AWS_SECRET_ACCESS_KEY.").Envar("AWS_SECRET_ACCESS_KEY").String()
 	s3ScanSessionToken  = s3Scan.Flag("session-token", "S3 session token used to authenticate temporary credentials. Can be provided with environment variable AWS_SESSION_TOKEN.").Envar("AWS_SESSION_TOKEN").String()
 	s3ScanCloudEnv      = s3Scan.Flag("cloud-environment", "Use IAM credentials in cloud environment.").Bool()
 	s3ScanBuckets       = s3Scan.Flag("bucket", "Name of S3 bucket to scan. You can repeat this flag. Incompatible with --ignore-bucket.").Strings()
 	s3ScanIgnoreBuckets = s3Scan.Flag("ignore-bucket", "Name of S3 bucket to ignore. You can repeat this flag. Incompatible with --bucket.").Strings()
 	s3ScanMaxObjectSize = s3Scan.Flag("max-object-size", "Maximum size of objects to scan. Objects larger than this will be skipped. (Byte units eg. 512B, 2KB, 4MB)").Default("250MB").Bytes()
+	s3ScanVersions      = s3Scan.Flag("object-versions", "Scan every version of the objects in buckets with versioning enabled, not just the current versions.").Bool()
+	s3ScanIncludePrefix = s3Scan.Flag("include-prefix", "Only scan objects whose key starts with this prefix, e.g. logs/. You can repeat this flag.").Strings()
+	s3ScanExcludePrefix = s3Scan.Flag("exclude-prefix", "Skip objects whose key starts with this prefix. Takes precedence over --include-prefix. You can repeat this flag.").Strings()
+	s3ScanExcludeSuffix = s3Scan.Flag("exclude-suffix", "Skip objects whose key ends with this suffix, e.g. .parquet. Takes precedence over --include-prefix. You can repeat this flag.").Strings()
+	s3ScanDownloads     = s3Scan.Flag("download-concurrency", "Number of objects downloaded concurrently. Defaults to --source-concurrency.").Int()
 
 	gcsScan           = cli.Command("gcs", "Find credentials in GCS buckets.")
 	gcsProjectID      = gcsScan.Flag("project-id", "GCS project ID used to authenticate. Can NOT be used with unauth scan. Can be provided with environment variable GOOGLE_CLOUD_PROJECT.").Envar("GOOGLE_CLOUD_PROJECT").String()
@@ -137,14 +221,16 @@ var (
 	gcsExcludeBuckets = gcsScan.Flag("exclude-buckets", "Buckets to exclude from scan. Comma separated list of buckets. Globs are supported").Short('X').Strings()
 	gcsIncludeObjects = gcsScan.Flag("include-objects", "Objects to scan. Comma separated list of objects. you can repeat this flag. Globs are supported").Short('i').Strings()
 	gcsExcludeObjects = gcsScan.Flag("exclude-objects", "Objects to exclude from scan. Comma separated list of objects. You can repeat this flag. Globs are supported").Short('x').Strings()
+	gcsPrefix         = gcsScan.Flag("prefix", "Only scan objects whose names start with this prefix.").String()
 	gcsMaxObjectSize  = gcsScan.Flag("max-object-size", "Maximum size of objects to scan. Objects larger than this will be skipped. (Byte units eg. 512B, 2KB, 4MB)").Default("10MB").Bytes()
+	gcsDownloads      = gcsScan.Flag("download-concurrency", "Number of objects downloaded concurrently. Defaults to --source-concurrency.").Int()
 
 	syslogScan     = cli.Command("syslog", "Scan syslog")
 	syslogAddress  = syslogScan.Flag("address", "Address and port to listen on for syslog. Example: 127.0.0.1:514").String()
 	syslogProtocol = syslogScan.Flag("protocol", "Protocol to listen on. udp or tcp").String()
 	syslogTLSCert  = syslogScan.Flag("cert", "Path to TLS cert.").String()
 	syslogTLSKey   = syslogScan.Flag("key", "Path to TLS key.").String()
-	syslogFormat   = syslogScan.Flag("format", "Log format. Can be rfc3164 or rfc5424").String()
+	syslogFormat   = syslogScan.Flag("format", "Log format. Can be rfc3164 or rfc5424. The format of each message is detected, so messages in the other format are parsed too, and malformed messages are scanned as raw text.").String()
 
 	circleCiScan      = cli.Command("circleci", "Scan CircleCI")
 	circleCiScanToken = circleCiScan.Flag("token", "CircleCI token. Can also be provided with environment variable").Envar("CIRCLECI_TOKEN").Required().String()
@@ -199,6 +285,33 @@ var (
 	jenkinsPassword              = jenkinsScan.Flag("password", "Jenkins password").Envar("JENKINS_PASSWORD").String()
 	jenkinsInsecureSkipVerifyTLS = jenkinsScan.Flag("insecure-skip-verify-tls", "Skip TLS verification").Envar("JENKINS_INSECURE_SKIP_VERIFY_TLS").Bool()
 
+	jiraScan                  = cli.Command("jira", "Scan the issues and comments of Jira projects.")
+	jiraURL                   = jiraScan.Flag("url", "Jira base URL.").Envar("JIRA_URL").Required().String()
+	jiraToken                 = jiraScan.Flag("token", "Jira personal access token.").Envar("JIRA_TOKEN").String()
+	jiraUsername              = jiraScan.Flag("username", "Jira username. On Jira Cloud, this is the email address of the user.").Envar("JIRA_USERNAME").String()
+	jiraPassword              = jiraScan.Flag("password", "Jira password. On Jira Cloud, use an API token.").Envar("JIRA_PASSWORD").String()
+	jiraProjects              = jiraScan.Flag("project", "Key of a project to scan. You can repeat this flag. Defaults to every visible project.").Strings()
+	jiraIgnoreProjects        = jiraScan.Flag("ignore-project", "Key of a project to skip. You can repeat this flag.").Strings()
+	jiraJQL                   = jiraScan.Flag("jql", "JQL query restricting the issues to scan.").String()
+	jiraUpdatedSince          = jiraScan.Flag("updated-since", "Only scan issues updated since this RFC 3339 timestamp, e.g. 2024-01-02T15:04:05Z.").String()
+	jiraInsecureSkipVerifyTLS = jiraScan.Flag("insecure-skip-verify-tls", "Skip TLS verification").Envar("JIRA_INSECURE_SKIP_VERIFY_TLS").Bool()
+
+	confluenceScan                  = cli.Command("confluence", "Scan the pages and attachments of Confluence spaces.")
+	confluenceURL                   = confluenceScan.Flag("url", "Confluence base URL, including its context path, e.g. https://example.atlassian.net/wiki.").Envar("CONFLUENCE_URL").Required().String()
+	confluenceToken                 = confluenceScan.Flag("token", "Confluence personal access token.").Envar("CONFLUENCE_TOKEN").String()
+	confluenceUsername              = confluenceScan.Flag("username", "Confluence username. On Confluence Cloud, this is the email address of the user.").Envar("CONFLUENCE_USERNAME").String()
+	confluencePassword              = confluenceScan.Flag("password", "Confluence password. On Confluence Cloud, use an API token.").Envar("CONFLUENCE_PASSWORD").String()
+	confluenceSpaces                = confluenceScan.Flag("space", "Key of a space to scan. You can repeat this flag. Defaults to every visible space.").Strings()
+	confluenceIgnoreSpaces          = confluenceScan.Flag("ignore-space", "Key of a space to skip. You can repeat this flag.").Strings()
+	confluenceIncludeAttachments    = confluenceScan.Flag("include-attachments", "Scan the attachments of pages.").Bool()
+	confluenceModifiedSince         = confluenceScan.Flag("modified-since", "Only scan pages modified since this RFC 3339 timestamp, e.g. 2024-01-02T15:04:05Z.").String()
+	confluenceInsecureSkipVerifyTLS = confluenceScan.Flag("insecure-skip-verify-tls", "Skip TLS verification").Envar("CONFLUENCE_INSECURE_SKIP_VERIFY_TLS").Bool()
+
+	stdinScan = cli.Command("stdin", "Scan the content piped to the standard input, e.g. cat file | trufflehog stdin.")
+
+	manifestScan     = cli.Command("manifest", "Scan the git, S3 and filesystem sources listed in a YAML or JSON manifest, with their options.")
+	manifestScanPath = manifestScan.Arg("path", "Path to the manifest.").Required().String()
+
 	usingTUI = false
 )
 
@@ -241,7 +354,7 @@ func init() {
 func main() {
 	// setup logger
 	logFormat := log.WithConsoleSink
-	if *jsonOut {
+	if *jsonOut || *ndjsonOut {
 		logFormat = log.WithJSONSink
 	}
 	logger, sync := log.New("trufflehog", logFormat(os.Stderr))
@@ -319,6 +432,7 @@ func run(state overseer.State) {
 	// When setting a base commit, chunks must be scanned in order.
 	if *gitScanSinceCommit != "" {
 		*concurrency = 1
+		*sourceConcurrency = 1
 	}
 
 	if *profile {
@@ -342,16 +456,41 @@ func run(state overseer.State) {
 		}
 	}
 
+	if *entropyDetector {
+		var charsets []entropy.Charset
+		for _, name := range strings.Split(*entropyCharsets, ",") {
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+			charset, err := entropy.ParseCharset(name)
+			if err != nil {
+				logFatal(err, "invalid entropy detector configuration")
+			}
+			charsets = append(charsets, charset)
+		}
+		// The entropy detector isn't one of the default detectors, to avoid its noise.
+		conf.Detectors = append(conf.Detectors, entropy.New(
+			entropy.WithHexThreshold(*entropyHexThreshold),
+			entropy.WithBase64Threshold(*entropyB64Threshold),
+			entropy.WithMinLength(*entropyMinLength),
+			entropy.WithCharsets(charsets...),
+		))
+	}
+
 	if *archiveMaxSize != 0 {
 		handlers.SetArchiveMaxSize(int(*archiveMaxSize))
 	}
-	if *archiveMaxDepth != 0 {
-		handlers.SetArchiveMaxDepth(*archiveMaxDepth)
-	}
 	if *archiveTimeout != 0 {
 		handlers.SetArchiveMaxTimeout(*archiveTimeout)
 	}
 
+	if err := common.ConfigureHTTPClients(common.HTTPClientConfig{
+		CACertFile: *verificationCAFile,
+		Timeout:    *verificationTimeout,
+	}); err != nil {
+		logFatal(err, "error configuring HTTP clients")
+	}
+
 	// Build include and exclude detector sets for filtering on engine initialization.
 	// Exit if there was an error to inform the user of the misconfiguration.
 	var includeDetectorSet, excludeDetectorSet map[config.DetectorID]struct{}
@@ -372,6 +511,23 @@ func run(state overseer.State) {
 		includeDetectorSet = detectorTypeToSet(includeList)
 		excludeDetectorSet = detectorTypeToSet(excludeList)
 	}
+	includeCategoryList, err := detectors.ParseCategories(*detectorCategories)
+	if err != nil {
+		logFatal(err, "invalid detector category configuration")
+	}
+	excludeCategoryList, err := detectors.ParseCategories(*excludeCategories)
+	if err != nil {
+		logFatal(err, "invalid excluded detector category configuration")
+	}
+
+	parsedDetectorTimeouts, err := config.ParseDetectorTimeouts(*detectorTimeouts)
+	if err != nil {
+		logFatal(err, "invalid detector timeout configuration")
+	}
+	parsedRateLimits, err := config.ParseVerificationRateLimits(*verificationRates)
+	if err != nil {
+		logFatal(err, "invalid verification rate limit configuration")
+	}
 
 	// Verify that all the user-provided detectors support the optional
 	// detector features.
@@ -406,6 +562,12 @@ func run(state overseer.State) {
 		_, ok := getWithDetectorID(d, excludeDetectorSet)
 		return !ok
 	}
+	categoryFilter := func(d detectors.Detector) bool {
+		if len(includeCategoryList) > 0 && !detectors.HasCategory(d, includeCategoryList) {
+			return false
+		}
+		return !detectors.HasCategory(d, excludeCategoryList)
+	}
 	// Abuse filter to cause a side-effect.
 	endpointCustomizer := func(d detectors.Detector) bool {
 		urls, ok := getWithDetectorID(d, detectorsWithCustomVerifierEndpoints)
@@ -433,21 +595,59 @@ func run(state overseer.State) {
 		)
 		return true
 	}
+	// Give the JWT detector its public keys.
+	jwtKeys := make([][]byte, 0, len(*jwtPublicKeys))
+	for _, path := range *jwtPublicKeys {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			logFatal(err, "failed reading JWT public key", "path", path)
+		}
+		jwtKeys = append(jwtKeys, key)
+	}
+	jwtKeySetter := func(d detectors.Detector) error {
+		if scanner, ok := d.(*jwt.Scanner); ok && len(jwtKeys) > 0 {
+			if err := scanner.SetPublicKeys(jwtKeys...); err != nil {
+				return fmt.Errorf("invalid JWT public key: %w", err)
+			}
+		}
+		return nil
+	}
+	// Let Google API keys be verified by probing the services they can call.
+	googleKeyProbes := func(d detectors.Detector) error {
+		if scanner, ok := d.(*googleapikey.Scanner); ok {
+			scanner.SetServiceProbes(!*noGoogleKeyProbes)
+			scanner.SetGeocodingProbe(*googleKeyGeocoding)
+		}
+		return nil
+	}
+	// Let the database detectors connect to the databases they find.
+	databaseVerifier := func(d detectors.Detector) error {
+		if verifier, ok := d.(detectors.DatabaseVerifier); ok {
+			verifier.SetDatabaseVerification(*verifyDatabases)
+		}
+		return nil
+	}
 
 	// Set how the engine will print its results.
+	redaction, err := output.ParseRedaction(*jsonRedaction)
+	if err != nil {
+		logFatal(err, "invalid JSON redaction policy")
+	}
 	var printer engine.Printer
 	switch {
 	case *jsonLegacy:
 		printer = new(output.LegacyJSONPrinter)
-	case *jsonOut:
-		printer = new(output.JSONPrinter)
+	case *jsonOut || *ndjsonOut:
+		printer = output.NewJSONPrinter(output.WithRedaction(redaction))
 	case *gitHubActionsFormat:
 		printer = new(output.GitHubActionsPrinter)
+	case *sarifOut:
+		printer = new(output.SARIFPrinter)
 	default:
 		printer = new(output.PlainPrinter)
 	}
 
-	if !*jsonLegacy && !*jsonOut {
+	if !*jsonLegacy && !*jsonOut && !*ndjsonOut {
 		fmt.Fprintf(os.Stderr, "🐷🔑🐷  TruffleHog. Unearth your secrets. 🐷🔑🐷\n\n")
 	}
 
@@ -457,7 +657,7 @@ func run(state overseer.State) {
 	}
 
 	// Parse --results flag.
-	if *onlyVerified {
+	if *onlyVerified || *verifiedDetails {
 		r := "verified"
 		results = &r
 	}
@@ -466,14 +666,50 @@ func run(state overseer.State) {
 		logFatal(err, "failed to configure results flag")
 	}
 
+	decoderTypes, err := parseDecoders(*decoderNames)
+	if err != nil {
+		logFatal(err, "failed to configure decoders")
+	}
+
+	var resultContextLines int
+	if *redactedContext {
+		resultContextLines = *contextLines
+	}
+
+	var allowlist *engine.Allowlist
+	if *allowlistFile != "" {
+		if allowlist, err = engine.LoadAllowlist(*allowlistFile); err != nil {
+			logFatal(err, "failed to load allowlist")
+		}
+		logger.V(2).Info("loaded allowlist", "fingerprints", allowlist.Len())
+	}
+
+	var anonymizePathsMapping io.Writer
+	if *anonymizeMapping != nil {
+		anonymizePathsMapping = *anonymizeMapping
+		*anonymizePaths = true
+	}
+
+	progressEvery := *progressInterval
+	if *quiet {
+		progressEvery = 0
+	}
+
 	scanConfig := scanConfig{
 		Command:                  cmd,
 		Concurrency:              *concurrency,
+		SourceConcurrency:        *sourceConcurrency,
+		AutoTuneConcurrency:      *autoTuneConcurrency,
 		Decoders:                 decoders.DefaultDecoders(),
+		DecoderTypes:             decoderTypes,
 		Conf:                     conf,
 		IncludeFilter:            includeFilter,
 		ExcludeFilter:            excludeFilter,
+		CategoryFilter:           categoryFilter,
 		EndpointCustomizer:       endpointCustomizer,
+		JWTKeySetter:             jwtKeySetter,
+		DatabaseVerifier:         databaseVerifier,
+		GoogleKeyProbes:          googleKeyProbes,
 		NoVerification:           *noVerification,
 		PrintAvgDetectorTime:     *printAvgDetectorTime,
 		FilterUnverified:         *filterUnverified,
@@ -483,6 +719,31 @@ func run(state overseer.State) {
 		AllowVerificationOverlap: *allowVerificationOverlap,
 		ParsedResults:            parsedResults,
 		Printer:                  printer,
+		MaxArchiveDepth:          *archiveMaxDepth,
+		ArchiveEntryGlobs:        *archiveEntryGlobs,
+		ScanExtensions:           *scanExtensions,
+		ResumeFile:               *resumeFile,
+		VerificationResponses:    *verifiedDetails,
+		VerificationRetries:      *verificationRetries,
+		VerificationRetryDelay:   *verificationBackoff,
+		VerificationRateLimits:   parsedRateLimits,
+		NoVerificationCache:      *noVerificationCache,
+		DryRun:                   *dryRun,
+		DedupResults:             *dedupResults,
+		ChunkBuffer:              *chunkBuffer,
+		ChunkOverlap:             int(*chunkOverlap),
+		ProgressInterval:         progressEvery,
+		ContextLines:             resultContextLines,
+		DetectorTimeout:          *detectorTimeout,
+		DetectorTimeouts:         parsedDetectorTimeouts,
+		MaxScanDuration:          *maxScanDuration,
+		ResultsLimit:             *resultsLimit,
+		ResultsLimitVerified:     *resultsLimitVerified,
+		ContinueOnSourceError:    *continueOnSourceErr,
+		Allowlist:                allowlist,
+		ShowSuppressed:           *showSuppressed,
+		AnonymizePaths:           *anonymizePaths,
+		AnonymizePathsMapping:    anonymizePathsMapping,
 	}
 
 	if *compareDetectionStrategies {
@@ -504,24 +765,89 @@ func run(state overseer.State) {
 		"bytes", metrics.BytesScanned,
 		"verified_secrets", metrics.VerifiedSecretsFound,
 		"unverified_secrets", metrics.UnverifiedSecretsFound,
+		"suppressed_secrets", metrics.SuppressedSecretsFound,
 		"scan_duration", metrics.ScanDuration.String(),
+		"scan_incomplete", metrics.ScanIncomplete,
+		"results_limit_reached", metrics.ResultsLimitReached,
+		"skipped_units", metrics.SkippedUnits,
 		"trufflehog_version", version.BuildVersion,
 	)
 
-	if metrics.hasFoundResults && *fail {
-		logger.V(2).Info("exiting with code 183 because results were found")
-		os.Exit(183)
+	if code := resultsExitCode(metrics); code != 0 {
+		logger.V(2).Info("exiting because results were found", "code", code)
+		os.Exit(code)
+	}
+	if metrics.ScanIncomplete {
+		logger.V(2).Info("exiting because the scan is incomplete", "code", exitCodeScanIncomplete)
+		os.Exit(exitCodeScanIncomplete)
+	}
+}
+
+const (
+	// exitCodeResults is returned if results were found with --fail, or
+	// verified results were found with --fail-verified.
+	exitCodeResults = 183
+	// exitCodeUnverifiedResults is returned if unverified results were found
+	// with --fail-unverified.
+	exitCodeUnverifiedResults = 184
+	// exitCodeScanIncomplete is returned if the scan was stopped by
+	// --max-scan-duration, or sources of a manifest failed to start, and no
+	// results triggered another exit code.
+	exitCodeScanIncomplete = 185
+)
+
+// resultsExitCode returns the exit code requested by the --fail flags for
+// the results of a successful scan, or 0 if none applies.
+func resultsExitCode(m metrics) int {
+	switch {
+	case *fail && m.hasFoundResults:
+		return exitCodeResults
+	case *failVerified && m.VerifiedSecretsFound > 0:
+		return exitCodeResults
+	case *failUnverified && m.UnverifiedSecretsFound > 0:
+		return exitCodeUnverifiedResults
+	default:
+		return 0
 	}
 }
 
+// resumeConfig returns the command line arguments that identify a scan for
+// checkpointing, omitting the checkpoint file itself.
+func resumeConfig(args []string) []string {
+	config := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--resume-file":
+			i++
+		case strings.HasPrefix(args[i], "--resume-file="):
+		default:
+			config = append(config, args[i])
+		}
+	}
+	return config
+}
+
+// stdinIsTerminal reports whether the standard input is a terminal rather than piped content.
+func stdinIsTerminal() bool {
+	stat, err := os.Stdin.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
 type scanConfig struct {
 	Command                  string
 	Concurrency              int
+	SourceConcurrency        int
+	AutoTuneConcurrency      bool
 	Decoders                 []decoders.Decoder
+	DecoderTypes             []detectorspb.DecoderType
 	Conf                     *config.Config
 	IncludeFilter            func(detectors.Detector) bool
 	ExcludeFilter            func(detectors.Detector) bool
+	CategoryFilter           func(detectors.Detector) bool
 	EndpointCustomizer       func(detectors.Detector) bool
+	JWTKeySetter             func(detectors.Detector) error
+	DatabaseVerifier         func(detectors.Detector) error
+	GoogleKeyProbes          func(detectors.Detector) error
 	NoVerification           bool
 	PrintAvgDetectorTime     bool
 	FilterUnverified         bool
@@ -531,6 +857,31 @@ type scanConfig struct {
 	AllowVerificationOverlap bool
 	ParsedResults            map[string]struct{}
 	Printer                  engine.Printer
+	MaxArchiveDepth          int
+	ArchiveEntryGlobs        []string
+	ScanExtensions           []string
+	ResumeFile               string
+	VerificationResponses    bool
+	VerificationRetries      int
+	VerificationRetryDelay   time.Duration
+	VerificationRateLimits   map[string]float64
+	NoVerificationCache      bool
+	DryRun                   bool
+	DedupResults             bool
+	ChunkBuffer              int
+	ChunkOverlap             int
+	ProgressInterval         time.Duration
+	ContextLines             int
+	DetectorTimeout          time.Duration
+	DetectorTimeouts         map[detectorspb.DetectorType]time.Duration
+	MaxScanDuration          time.Duration
+	ResultsLimit             int
+	ResultsLimitVerified     bool
+	ContinueOnSourceError    bool
+	Allowlist                *engine.Allowlist
+	ShowSuppressed           bool
+	AnonymizePaths           bool
+	AnonymizePathsMapping    io.Writer
 }
 
 func compareScans(ctx context.Context, cfg scanConfig) error {
@@ -588,13 +939,20 @@ type metrics struct {
 func runSingleScan(ctx context.Context, cfg scanConfig, scanEntireChunk bool) (metrics, error) {
 	eng, err := engine.Start(ctx,
 		engine.WithConcurrency(cfg.Concurrency),
+		engine.WithSourceConcurrency(cfg.SourceConcurrency),
+		engine.WithAutoTuneConcurrency(cfg.AutoTuneConcurrency),
 		engine.WithDecoders(cfg.Decoders...),
+		engine.WithDecoderTypes(cfg.DecoderTypes...),
 		engine.WithDetectors(engine.DefaultDetectors()...),
 		engine.WithDetectors(cfg.Conf.Detectors...),
 		engine.WithVerify(!cfg.NoVerification),
 		engine.WithFilterDetectors(cfg.IncludeFilter),
 		engine.WithFilterDetectors(cfg.ExcludeFilter),
+		engine.WithFilterDetectors(cfg.CategoryFilter),
 		engine.WithFilterDetectors(cfg.EndpointCustomizer),
+		engine.WithDetectorConfig(cfg.JWTKeySetter),
+		engine.WithDetectorConfig(cfg.DatabaseVerifier),
+		engine.WithDetectorConfig(cfg.GoogleKeyProbes),
 		engine.WithFilterUnverified(cfg.FilterUnverified),
 		engine.WithResults(cfg.ParsedResults),
 		engine.WithPrintAvgDetectorTime(cfg.PrintAvgDetectorTime),
@@ -602,6 +960,28 @@ func runSingleScan(ctx context.Context, cfg scanConfig, scanEntireChunk bool) (m
 		engine.WithFilterEntropy(cfg.FilterEntropy),
 		engine.WithVerificationOverlap(cfg.AllowVerificationOverlap),
 		engine.WithEntireChunkScan(scanEntireChunk),
+		engine.WithMaxArchiveDepth(cfg.MaxArchiveDepth),
+		engine.WithArchiveEntryGlobs(cfg.ArchiveEntryGlobs...),
+		engine.WithScanExtensions(cfg.ScanExtensions...),
+		engine.WithResumeFile(cfg.ResumeFile, resumeConfig(os.Args[1:])...),
+		engine.WithVerificationResponses(cfg.VerificationResponses),
+		engine.WithVerificationRetries(cfg.VerificationRetries, cfg.VerificationRetryDelay),
+		engine.WithVerificationRateLimits(cfg.VerificationRateLimits),
+		engine.WithVerificationCache(!cfg.NoVerificationCache),
+		engine.WithDryRun(cfg.DryRun),
+		engine.WithDedupResults(cfg.DedupResults),
+		engine.WithConcurrentChunkBuffer(cfg.ChunkBuffer),
+		engine.WithChunkOverlap(cfg.ChunkOverlap),
+		engine.WithProgress(os.Stderr, cfg.ProgressInterval),
+		engine.WithContextLines(cfg.ContextLines),
+		engine.WithDetectorTimeout(cfg.DetectorTimeout),
+		engine.WithDetectorTimeouts(cfg.DetectorTimeouts),
+		engine.WithMaxScanDuration(cfg.MaxScanDuration),
+		engine.WithResultsLimit(cfg.ResultsLimit, cfg.ResultsLimitVerified),
+		engine.WithContinueOnSourceError(cfg.ContinueOnSourceError),
+		engine.WithAllowlist(cfg.Allowlist),
+		engine.WithShowSuppressed(cfg.ShowSuppressed),
+		engine.WithAnonymizePaths(cfg.AnonymizePaths, cfg.AnonymizePathsMapping),
 	)
 	if err != nil {
 		return metrics{}, fmt.Errorf("error initializing engine: %v", err)
@@ -618,14 +998,30 @@ func runSingleScan(ctx context.Context, cfg scanConfig, scanEntireChunk bool) (m
 	switch cfg.Command {
 	case gitScan.FullCommand():
 		gitCfg := sources.GitConfig{
-			URI:              *gitScanURI,
-			IncludePathsFile: *gitScanIncludePaths,
-			ExcludePathsFile: *gitScanExcludePaths,
-			HeadRef:          *gitScanBranch,
-			BaseRef:          *gitScanSinceCommit,
-			MaxDepth:         *gitScanMaxDepth,
-			Bare:             *gitScanBare,
-			ExcludeGlobs:     *gitScanExcludeGlobs,
+			URI:                *gitScanURI,
+			IncludePathsFile:   *gitScanIncludePaths,
+			ExcludePathsFile:   *gitScanExcludePaths,
+			HeadRef:            *gitScanBranch,
+			BaseRef:            *gitScanSinceCommit,
+			MaxDepth:           *gitScanMaxDepth,
+			Bare:               *gitScanBare,
+			ExcludeGlobs:       *gitScanExcludeGlobs,
+			IncludeSubmodules:  *gitScanSubmodules,
+			Refs:               *gitScanRefs,
+			ScanCommitMessages: *gitScanCommitMsgs,
+			FetchLFS:           *gitScanFetchLFS,
+			MaxLFSObjectSize:   int64(*gitScanMaxLFSSize),
+			WorkingTreeOnly:    *gitScanWorkingTree,
+			ScanNotes:          *gitScanNotes,
+			ScanStashes:        *gitScanStashes,
+		}
+		if *gitScanSinceDate != "" {
+			if gitCfg.SinceDate, err = parseSinceDate(*gitScanSinceDate); err != nil {
+				return scanMetrics, fmt.Errorf("invalid --since-date: %v", err)
+			}
+		}
+		if gitCfg.WorkingTreeOnly && (gitCfg.BaseRef != "" || gitCfg.HeadRef != "" || len(gitCfg.Refs) > 0 || gitCfg.Bare) {
+			return scanMetrics, fmt.Errorf("--working-tree-only can't be used with --since-commit, --branch, --ref or --bare")
 		}
 		if err = eng.ScanGit(ctx, gitCfg); err != nil {
 			return scanMetrics, fmt.Errorf("failed to scan Git: %v", err)
@@ -645,7 +1041,6 @@ func runSingleScan(ctx context.Context, cfg scanConfig, scanEntireChunk bool) (m
 			IncludeForks:               *githubIncludeForks,
 			IncludeMembers:             *githubIncludeMembers,
 			IncludeWikis:               *githubIncludeWikis,
-			Concurrency:                *concurrency,
 			ExcludeRepos:               *githubExcludeRepos,
 			IncludeRepos:               *githubIncludeRepos,
 			Repos:                      *githubScanRepos,
@@ -653,6 +1048,9 @@ func runSingleScan(ctx context.Context, cfg scanConfig, scanEntireChunk bool) (m
 			IncludeIssueComments:       *githubScanIssueComments,
 			IncludePullRequestComments: *githubScanPRComments,
 			IncludeGistComments:        *githubScanGistComments,
+			IncrementalStateFile:       *githubScanStateFile,
+			PullRequest:                *githubScanPullRequest,
+			ScanCommitMessages:         *githubScanCommitMsgs,
 			Filter:                     filter,
 		}
 		if err := eng.ScanGitHub(ctx, cfg); err != nil {
@@ -665,10 +1063,18 @@ func runSingleScan(ctx context.Context, cfg scanConfig, scanEntireChunk bool) (m
 		}
 
 		cfg := sources.GitlabConfig{
-			Endpoint: *gitlabScanEndpoint,
-			Token:    *gitlabScanToken,
-			Repos:    *gitlabScanRepos,
-			Filter:   filter,
+			Endpoint:          *gitlabScanEndpoint,
+			Token:             *gitlabScanToken,
+			Repos:             *gitlabScanRepos,
+			Filter:            filter,
+			ScanMergeRequests: *gitlabScanMRs,
+			Groups:            *gitlabScanGroups,
+			SkipArchived:      *gitlabScanSkipArchived,
+		}
+		if *gitlabScanLastActivity != "" {
+			if cfg.LastActivityAfter, err = time.Parse(time.RFC3339, *gitlabScanLastActivity); err != nil {
+				return scanMetrics, fmt.Errorf("invalid --last-activity-after timestamp: %v", err)
+			}
 		}
 		if err := eng.ScanGitLab(ctx, cfg); err != nil {
 			return scanMetrics, fmt.Errorf("failed to scan GitLab: %v", err)
@@ -681,23 +1087,37 @@ func runSingleScan(ctx context.Context, cfg scanConfig, scanEntireChunk bool) (m
 		paths = append(paths, *filesystemPaths...)
 		paths = append(paths, *filesystemDirectories...)
 		cfg := sources.FilesystemConfig{
-			Paths:            paths,
-			IncludePathsFile: *filesystemScanIncludePaths,
-			ExcludePathsFile: *filesystemScanExcludePaths,
+			Paths:                   paths,
+			IncludePathsFile:        *filesystemScanIncludePaths,
+			ExcludePathsFile:        *filesystemScanExcludePaths,
+			IncludePathsRegex:       *filesystemScanIncludeRegex,
+			ExcludePathsRegex:       *filesystemScanExcludeRegex,
+			FollowSymlinks:          *filesystemScanSymlinks,
+			ConfineToRoot:           *filesystemScanConfine,
+			SkipBinaries:            *filesystemScanSkipBinary,
+			AllowedBinaryExtensions: *filesystemScanBinaryExts,
+			ScanExtensions:          *scanExtensions,
+			BufferThreshold:         uint64(*filesystemBufferThreshold),
 		}
 		if err = eng.ScanFileSystem(ctx, cfg); err != nil {
 			return scanMetrics, fmt.Errorf("failed to scan filesystem: %v", err)
 		}
 	case s3Scan.FullCommand():
 		cfg := sources.S3Config{
-			Key:           *s3ScanKey,
-			Secret:        *s3ScanSecret,
-			SessionToken:  *s3ScanSessionToken,
-			Buckets:       *s3ScanBuckets,
-			IgnoreBuckets: *s3ScanIgnoreBuckets,
-			Roles:         *s3ScanRoleArns,
-			CloudCred:     *s3ScanCloudEnv,
-			MaxObjectSize: int64(*s3ScanMaxObjectSize),
+			Key:                *s3ScanKey,
+			Secret:             *s3ScanSecret,
+			SessionToken:       *s3ScanSessionToken,
+			Buckets:            *s3ScanBuckets,
+			IgnoreBuckets:      *s3ScanIgnoreBuckets,
+			Roles:              *s3ScanRoleArns,
+			ExternalID:         *s3ScanExternalID,
+			CloudCred:          *s3ScanCloudEnv,
+			MaxObjectSize:      int64(*s3ScanMaxObjectSize),
+			ScanObjectVersions: *s3ScanVersions,
+			IncludePrefixes:    *s3ScanIncludePrefix,
+			ExcludePrefixes:    *s3ScanExcludePrefix,
+			ExcludeSuffixes:    *s3ScanExcludeSuffix,
+			Concurrency:        *s3ScanDownloads,
 		}
 		if err := eng.ScanS3(ctx, cfg); err != nil {
 			return scanMetrics, fmt.Errorf("failed to scan S3: %v", err)
@@ -733,8 +1153,9 @@ func runSingleScan(ctx context.Context, cfg scanConfig, scanEntireChunk bool) (m
 			ExcludeBuckets: commaSeparatedToSlice(*gcsExcludeBuckets),
 			IncludeObjects: commaSeparatedToSlice(*gcsIncludeObjects),
 			ExcludeObjects: commaSeparatedToSlice(*gcsExcludeObjects),
-			Concurrency:    *concurrency,
+			Concurrency:    *gcsDownloads,
 			MaxObjectSize:  int64(*gcsMaxObjectSize),
+			Prefix:         *gcsPrefix,
 		}
 		if err := eng.ScanGCS(ctx, cfg); err != nil {
 			return scanMetrics, fmt.Errorf("failed to scan GCS: %v", err)
@@ -811,12 +1232,75 @@ func runSingleScan(ctx context.Context, cfg scanConfig, scanEntireChunk bool) (m
 		if err := eng.ScanJenkins(ctx, cfg); err != nil {
 			return scanMetrics, fmt.Errorf("failed to scan Jenkins: %v", err)
 		}
+	case jiraScan.FullCommand():
+		cfg := sources.JiraConfig{
+			Endpoint:              *jiraURL,
+			Token:                 *jiraToken,
+			Username:              *jiraUsername,
+			Password:              *jiraPassword,
+			Projects:              *jiraProjects,
+			IgnoreProjects:        *jiraIgnoreProjects,
+			JQL:                   *jiraJQL,
+			InsecureSkipVerifyTLS: *jiraInsecureSkipVerifyTLS,
+		}
+		if *jiraUpdatedSince != "" {
+			if cfg.UpdatedSince, err = time.Parse(time.RFC3339, *jiraUpdatedSince); err != nil {
+				return scanMetrics, fmt.Errorf("invalid --updated-since timestamp: %v", err)
+			}
+		}
+		if err := eng.ScanJira(ctx, cfg); err != nil {
+			return scanMetrics, fmt.Errorf("failed to scan Jira: %v", err)
+		}
+	case confluenceScan.FullCommand():
+		cfg := sources.ConfluenceConfig{
+			Endpoint:              *confluenceURL,
+			Token:                 *confluenceToken,
+			Username:              *confluenceUsername,
+			Password:              *confluencePassword,
+			Spaces:                *confluenceSpaces,
+			IgnoreSpaces:          *confluenceIgnoreSpaces,
+			IncludeAttachments:    *confluenceIncludeAttachments,
+			InsecureSkipVerifyTLS: *confluenceInsecureSkipVerifyTLS,
+		}
+		if *confluenceModifiedSince != "" {
+			if cfg.ModifiedSince, err = time.Parse(time.RFC3339, *confluenceModifiedSince); err != nil {
+				return scanMetrics, fmt.Errorf("invalid --modified-since timestamp: %v", err)
+			}
+		}
+		if err := eng.ScanConfluence(ctx, cfg); err != nil {
+			return scanMetrics, fmt.Errorf("failed to scan Confluence: %v", err)
+		}
+	case stdinScan.FullCommand():
+		if stdinIsTerminal() {
+			return scanMetrics, fmt.Errorf("no input was piped to stdin, pipe the content to scan, e.g. cat file | trufflehog stdin")
+		}
+		if err := eng.ScanStdin(ctx); err != nil {
+			return scanMetrics, fmt.Errorf("failed to scan stdin: %v", err)
+		}
+	case manifestScan.FullCommand():
+		manifest, err := engine.LoadManifest(*manifestScanPath)
+		if err != nil {
+			return scanMetrics, err
+		}
+		for _, src := range manifest.Sources {
+			if src.Filesystem != nil {
+				src.Filesystem.ScanExtensions = *scanExtensions
+			}
+		}
+		// Sources that fail to start are reported, and the others are still scanned.
+		if err := eng.ScanManifest(ctx, manifest); err != nil {
+			ctx.Logger().Error(err, "failed to scan some sources of the manifest")
+		}
 	default:
 		return scanMetrics, fmt.Errorf("invalid command: %s", cfg.Command)
 	}
 
 	// Wait for all workers to finish.
-	if err = eng.Finish(ctx); err != nil {
+	err = eng.Finish(ctx)
+	if cfg.DryRun {
+		printDryRun(eng)
+	}
+	if err != nil {
 		return scanMetrics, fmt.Errorf("engine failed to finish execution: %v", err)
 	}
 
@@ -824,6 +1308,16 @@ func runSingleScan(ctx context.Context, cfg scanConfig, scanEntireChunk bool) (m
 		printAverageDetectorTime(eng)
 	}
 
+	if *printSummary {
+		printResultSummary(eng.ResultSummary())
+	}
+
+	if *sourceStatsFile != "" {
+		if err := writeSourceStats(eng, *sourceStatsFile); err != nil {
+			ctx.Logger().Error(err, "error writing source stats")
+		}
+	}
+
 	return metrics{Metrics: eng.GetMetrics(), hasFoundResults: eng.HasFoundResults()}, nil
 }
 
@@ -851,6 +1345,25 @@ func parseResults(input *string) (map[string]struct{}, error) {
 	return results, nil
 }
 
+// parseDecoders returns the decoder types for a comma separated list of
+// decoder names, preserving their order.
+func parseDecoders(input string) ([]detectorspb.DecoderType, error) {
+	if input == "" {
+		return nil, nil
+	}
+
+	names := strings.Split(input, ",")
+	decoderTypes := make([]detectorspb.DecoderType, 0, len(names))
+	for _, name := range names {
+		decoderType, err := decoders.ParseDecoderType(name)
+		if err != nil {
+			return nil, err
+		}
+		decoderTypes = append(decoderTypes, decoderType)
+	}
+	return decoderTypes, nil
+}
+
 // logFatalFunc returns a log.Fatal style function. Calling the returned
 // function will terminate the program without cleanup.
 func logFatalFunc(logger logr.Logger) func(error, string, ...any) {
@@ -888,6 +1401,75 @@ func printAverageDetectorTime(e *engine.Engine) {
 	}
 }
 
+// printDryRun prints the detectors and source units a scan would use, along
+// with any errors encountered while enumerating the sources.
+func printDryRun(e *engine.Engine) {
+	categories := detectors.AllCategories()
+	fmt.Printf("Detector categories (%d):\n", len(categories))
+	for _, c := range categories {
+		fmt.Printf("  %s\n", c)
+	}
+
+	enabled := e.EnabledDetectors()
+	enabledCategories := e.EnabledCategories()
+	fmt.Printf("Enabled detectors (%d):\n", len(enabled))
+	for _, id := range enabled {
+		if categories := enabledCategories[id]; len(categories) > 0 {
+			fmt.Printf("  %s %v\n", id, categories)
+			continue
+		}
+		fmt.Printf("  %s\n", id)
+	}
+
+	units := e.EnumeratedUnits()
+	fmt.Printf("Source units (%d):\n", len(units))
+	for _, unit := range units {
+		fmt.Printf("  [%s] %s: %s\n", unit.SourceName, unit.Kind, unit.Display)
+	}
+
+	if errs := e.EnumerationErrors(); len(errs) > 0 {
+		fmt.Printf("Errors (%d):\n", len(errs))
+		for _, err := range errs {
+			fmt.Printf("  %s\n", err)
+		}
+	}
+}
+
+// printResultSummary prints the number of results by detector, most frequent
+// first, along with the totals.
+func printResultSummary(summary engine.ResultSummary) {
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DETECTOR\tVERIFIED\tUNVERIFIED\tTOTAL")
+	for _, d := range summary.Detectors {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", d.DetectorName, d.Verified, d.Unverified, d.Total())
+	}
+	fmt.Fprintf(w, "Total\t%d\t%d\t%d\n", summary.Verified, summary.Unverified, summary.Total)
+	_ = w.Flush()
+}
+
+// writeSourceStats writes the per-source statistics of the scan as JSON to path.
+// Incomplete scans are marked as such, since their statistics don't cover all
+// of the content.
+func writeSourceStats(e *engine.Engine, path string) error {
+	data, err := json.MarshalIndent(map[string]any{
+		"version":    1,
+		"incomplete": e.GetMetrics().ScanIncomplete,
+		"sources":    e.SourceStats(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal source stats: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// parseSinceDate parses a date, e.g. 2024-01-02, or an RFC 3339 timestamp.
+func parseSinceDate(value string) (time.Time, error) {
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
 // detectorTypeToSet is a helper function to convert a slice of detector IDs into a set.
 func detectorTypeToSet(detectors []config.DetectorID) map[config.DetectorID]struct{} {
 	out := make(map[config.DetectorID]struct{}, len(detectors))
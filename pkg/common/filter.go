@@ -50,6 +50,38 @@ func FilterFromFiles(includeFilterPath, excludeFilterPath string) (*Filter, erro
 	return filter, nil
 }
 
+// FilterFromRegexes creates a Filter using the provided include and exclude regular expressions.
+// An empty include list passes every object, and exclude rules take precedence over include rules.
+func FilterFromRegexes(includePatterns, excludePatterns []string) (*Filter, error) {
+	includeRules, err := FilterRulesFromRegexes(includePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("could not create include rules: %w", err)
+	}
+	excludeRules, err := FilterRulesFromRegexes(excludePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("could not create exclude rules: %w", err)
+	}
+
+	if len(includePatterns) == 0 {
+		includeRules = &FilterRuleSet{*regexp.MustCompile("")}
+	}
+
+	return &Filter{include: includeRules, exclude: excludeRules}, nil
+}
+
+// FilterRulesFromRegexes compiles the list of regular expressions in `patterns` into a FilterRuleSet.
+func FilterRulesFromRegexes(patterns []string) (*FilterRuleSet, error) {
+	rules := make(FilterRuleSet, 0, len(patterns))
+	for _, p := range patterns {
+		pattern, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("can not compile regular expression %q: %w", p, err)
+		}
+		rules = append(rules, *pattern)
+	}
+	return &rules, nil
+}
+
 // FilterRulesFromFile loads the list of regular expression filter rules in `source` and creates a FilterRuleSet.
 func FilterRulesFromFile(source string) (*FilterRuleSet, error) {
 	rules := FilterRuleSet{}
@@ -176,3 +176,35 @@ func testFilterWriteFile(filename string, content []byte) error {
 	}
 	return f.Close()
 }
+
+func TestFilterFromRegexes(t *testing.T) {
+	tests := map[string]struct {
+		include []string
+		exclude []string
+		object  string
+		pass    bool
+	}{
+		"EmptyPasses":            {object: "dir/file.go", pass: true},
+		"IncludePassed":          {include: []string{`\.go$`}, object: "dir/file.go", pass: true},
+		"IncludeFiltered":        {include: []string{`\.go$`}, object: "dir/file.txt", pass: false},
+		"ExcludeFiltered":        {exclude: []string{`^dir/`}, object: "dir/file.go", pass: false},
+		"ExcludeTakesPrecedence": {include: []string{`\.go$`}, exclude: []string{`_test\.go$`}, object: "file_test.go", pass: false},
+	}
+
+	for name, test := range tests {
+		filter, err := FilterFromRegexes(test.include, test.exclude)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if filter.Pass(test.object) != test.pass {
+			t.Errorf("%s: unexpected filter result. object: %q, pass: %t", name, test.object, !test.pass)
+		}
+	}
+
+	if _, err := FilterFromRegexes([]string{"("}, nil); err == nil {
+		t.Error("expected error for invalid include regex")
+	}
+	if _, err := FilterFromRegexes(nil, []string{"["}); err == nil {
+		t.Error("expected error for invalid exclude regex")
+	}
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"io"
@@ -86,18 +87,57 @@ func (t FakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 type CustomTransport struct {
 	T http.RoundTripper
+	// timeout bounds each request, including reading its body, unless a timeout is set with
+	// ConfigureHTTPClients. Zero means no timeout.
+	timeout time.Duration
+	// retry retries requests that fail transiently according to the policy set with
+	// SetVerificationRetryPolicy.
+	retry bool
+	// rateLimit throttles requests to each host according to the limits set with
+	// SetVerificationRateLimits.
+	rateLimit bool
 }
 
 func (t *CustomTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.Header.Add("User-Agent", "TruffleHog")
-	return t.T.RoundTrip(req)
+	roundTrip := t.roundTrip
+	if t.rateLimit {
+		roundTrip = func(req *http.Request) (*http.Response, error) {
+			return rateLimitedRoundTrip(req, t.roundTrip)
+		}
+	}
+	if t.retry {
+		return retryRoundTrip(req, roundTrip)
+	}
+	return roundTrip(req)
+}
+
+// roundTrip makes a single attempt at sending req.
+func (t *CustomTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(req.Context(), requestTimeout(t.timeout))
+		req = req.WithContext(ctx)
+	}
+
+	res, err := withCACerts(t.T).RoundTrip(req)
+	if err != nil {
+		cancel()
+		return res, err
+	}
+	if rec := responseRecorderFromContext(req.Context()); rec != nil {
+		rec.record(req, res)
+	}
+	res.Body = cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
 }
 
 func NewCustomTransport(T http.RoundTripper) *CustomTransport {
 	if T == nil {
 		T = http.DefaultTransport
 	}
-	return &CustomTransport{T}
+	return &CustomTransport{T: T}
 }
 
 func ConstantResponseHttpClient(statusCode int, body string) *http.Client {
@@ -208,10 +248,13 @@ var saneTransport = &http.Transport{
 	ExpectContinueTimeout: 1 * time.Second,
 }
 
+// SaneHttpClient returns the client used to verify detector results. Its timeout and trusted
+// CA certificates can be changed with ConfigureHTTPClients, its retries with
+// SetVerificationRetryPolicy, and its rate limits with SetVerificationRateLimits.
 func SaneHttpClient() *http.Client {
 	httpClient := &http.Client{}
-	httpClient.Timeout = DefaultResponseTimeout
-	httpClient.Transport = NewCustomTransport(saneTransport)
+	// The timeout is enforced by the transport so that it can be configured after the client is created.
+	httpClient.Transport = &CustomTransport{T: saneTransport, timeout: DefaultResponseTimeout, retry: true, rateLimit: true}
 	return httpClient
 }
 
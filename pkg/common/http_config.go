@@ -0,0 +1,119 @@
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPClientConfig configures the HTTP clients created by this package.
+// Proxies are always taken from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+type HTTPClientConfig struct {
+	// CACertFile is the path of a PEM bundle of CA certificates to trust in addition to the
+	// certificates normally trusted by each client.
+	CACertFile string
+	// Timeout replaces the default timeout of clients created by SaneHttpClient, which are
+	// used to verify detector results. Zero keeps the default.
+	Timeout time.Duration
+}
+
+// httpClientSettings is the applied form of an HTTPClientConfig.
+type httpClientSettings struct {
+	caCerts []byte
+	timeout time.Duration
+
+	// transports caches the transports derived from each base *http.Transport.
+	transports sync.Map
+}
+
+var currentHTTPSettings atomic.Pointer[httpClientSettings]
+
+// ConfigureHTTPClients applies cfg to every HTTP client created by this package, including
+// clients created before it was called. It should be called before any requests are made.
+func ConfigureHTTPClients(cfg HTTPClientConfig) error {
+	settings := &httpClientSettings{timeout: cfg.Timeout}
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return fmt.Errorf("unable to read CA certificate file: %w", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in CA certificate file %s", cfg.CACertFile)
+		}
+		settings.caCerts = pem
+	}
+	currentHTTPSettings.Store(settings)
+	return nil
+}
+
+// ConfiguredTransport returns a transport that sends requests through T, trusting the CA
+// certificates set with ConfigureHTTPClients. Unlike CustomTransport, it leaves requests unmodified.
+func ConfiguredTransport(T http.RoundTripper) http.RoundTripper {
+	if T == nil {
+		T = http.DefaultTransport
+	}
+	return configuredTransport{T}
+}
+
+type configuredTransport struct {
+	T http.RoundTripper
+}
+
+func (t configuredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return withCACerts(t.T).RoundTrip(req)
+}
+
+// withCACerts returns a copy of T that also trusts the configured CA certificates.
+// T is returned unchanged if no certificates are configured or it isn't an *http.Transport.
+func withCACerts(T http.RoundTripper) http.RoundTripper {
+	settings := currentHTTPSettings.Load()
+	base, ok := T.(*http.Transport)
+	if settings == nil || len(settings.caCerts) == 0 || !ok {
+		return T
+	}
+	if transport, ok := settings.transports.Load(base); ok {
+		return transport.(*http.Transport)
+	}
+
+	transport := base.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	pool := transport.TLSClientConfig.RootCAs
+	if pool != nil {
+		pool = pool.Clone()
+	} else if pool, _ = x509.SystemCertPool(); pool == nil {
+		pool = x509.NewCertPool()
+	}
+	pool.AppendCertsFromPEM(settings.caCerts)
+	transport.TLSClientConfig.RootCAs = pool
+
+	actual, _ := settings.transports.LoadOrStore(base, transport)
+	return actual.(*http.Transport)
+}
+
+// requestTimeout returns the configured timeout, or fallback if none is configured.
+func requestTimeout(fallback time.Duration) time.Duration {
+	if settings := currentHTTPSettings.Load(); settings != nil && settings.timeout > 0 {
+		return settings.timeout
+	}
+	return fallback
+}
+
+// cancelOnClose releases the context of a request once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureHTTPClientsCACert(t *testing.T) {
+	t.Cleanup(func() { currentHTTPSettings.Store(nil) })
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The client is created before the configuration, as detectors do.
+	client := SaneHttpClient()
+
+	_, err := client.Get(server.URL) //nolint:bodyclose
+	assert.Error(t, err, "expected the test server certificate to be untrusted")
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(caFile, certPEM, 0600))
+	assert.NoError(t, ConfigureHTTPClients(HTTPClientConfig{CACertFile: caFile}))
+
+	res, err := client.Get(server.URL)
+	if assert.NoError(t, err) {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestConfigureHTTPClientsInvalidCACert(t *testing.T) {
+	t.Cleanup(func() { currentHTTPSettings.Store(nil) })
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0600))
+
+	assert.Error(t, ConfigureHTTPClients(HTTPClientConfig{CACertFile: caFile}))
+	assert.Error(t, ConfigureHTTPClients(HTTPClientConfig{CACertFile: filepath.Join(t.TempDir(), "missing.pem")}))
+}
+
+func TestConfigureHTTPClientsTimeout(t *testing.T) {
+	t.Cleanup(func() { currentHTTPSettings.Store(nil) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := SaneHttpClient()
+	assert.NoError(t, ConfigureHTTPClients(HTTPClientConfig{Timeout: 50 * time.Millisecond}))
+
+	start := time.Now()
+	_, err := client.Get(server.URL) //nolint:bodyclose
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
@@ -0,0 +1,108 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// MaxRecordedBodySize is the maximum number of response body bytes kept by a ResponseRecorder.
+const MaxRecordedBodySize = 4 * 1024
+
+type responseRecorderKey struct{}
+
+// RecordedResponse is a truncated copy of an HTTP response and the request that produced it.
+type RecordedResponse struct {
+	Request    *http.Request
+	StatusCode int
+	Body       []byte
+	// Truncated is set if the body was larger than MaxRecordedBodySize.
+	Truncated bool
+}
+
+// ResponseRecorder collects the responses to requests sent through a CustomTransport
+// with a context returned by WithResponseRecorder. It is safe for concurrent use.
+type ResponseRecorder struct {
+	mu        sync.Mutex
+	responses []RecordedResponse
+}
+
+// WithResponseRecorder returns a copy of ctx that records HTTP responses to rec.
+func WithResponseRecorder(ctx context.Context, rec *ResponseRecorder) context.Context {
+	return context.WithValue(ctx, responseRecorderKey{}, rec)
+}
+
+func responseRecorderFromContext(ctx context.Context) *ResponseRecorder {
+	rec, _ := ctx.Value(responseRecorderKey{}).(*ResponseRecorder)
+	return rec
+}
+
+// Responses returns the recorded responses in the order they were received.
+func (r *ResponseRecorder) Responses() []RecordedResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedResponse(nil), r.responses...)
+}
+
+// ResponseFor returns the last recorded response whose request carried the secret
+// in its URL or headers, including in HTTP basic auth credentials.
+func (r *ResponseRecorder) ResponseFor(secret string) (RecordedResponse, bool) {
+	if secret == "" {
+		return RecordedResponse{}, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.responses) - 1; i >= 0; i-- {
+		if requestContains(r.responses[i].Request, secret) {
+			return r.responses[i], true
+		}
+	}
+	return RecordedResponse{}, false
+}
+
+// record copies up to MaxRecordedBodySize bytes of the response body, leaving the
+// response readable in full by the caller.
+func (r *ResponseRecorder) record(req *http.Request, res *http.Response) {
+	recorded := RecordedResponse{Request: req, StatusCode: res.StatusCode}
+	if res.Body != nil {
+		prefix, _ := io.ReadAll(io.LimitReader(res.Body, MaxRecordedBodySize+1))
+		res.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(prefix), res.Body), res.Body}
+
+		if len(prefix) > MaxRecordedBodySize {
+			prefix = prefix[:MaxRecordedBodySize]
+			recorded.Truncated = true
+		}
+		recorded.Body = prefix
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses = append(r.responses, recorded)
+}
+
+func requestContains(req *http.Request, secret string) bool {
+	if req == nil {
+		return false
+	}
+	if strings.Contains(req.URL.String(), secret) {
+		return true
+	}
+	if user, pass, ok := req.BasicAuth(); ok && (strings.Contains(user, secret) || strings.Contains(pass, secret)) {
+		return true
+	}
+	for _, values := range req.Header {
+		for _, v := range values {
+			if strings.Contains(v, secret) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	largeBody := strings.Repeat("a", MaxRecordedBodySize+100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			_, _ = w.Write([]byte(largeBody))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"user":"octocat"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := SaneHttpClient()
+	rec := new(ResponseRecorder)
+	ctx := WithResponseRecorder(context.Background(), rec)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/user", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token-one")
+	res, err := client.Do(req)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, res.Body.Close())
+	assert.Equal(t, `{"user":"octocat"}`, string(body))
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/large", nil)
+	assert.NoError(t, err)
+	req.SetBasicAuth("user", "token-two")
+	res, err = client.Do(req)
+	assert.NoError(t, err)
+	body, err = io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, res.Body.Close())
+	// The caller still receives the full body.
+	assert.Equal(t, largeBody, string(body))
+
+	// Requests without a recorder in their context are not recorded.
+	res, err = client.Get(server.URL + "/user")
+	assert.NoError(t, err)
+	assert.NoError(t, res.Body.Close())
+
+	assert.Len(t, rec.Responses(), 2)
+
+	first, ok := rec.ResponseFor("token-one")
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, first.StatusCode)
+	assert.Equal(t, `{"user":"octocat"}`, string(first.Body))
+	assert.False(t, first.Truncated)
+
+	second, ok := rec.ResponseFor("token-two")
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusOK, second.StatusCode)
+	assert.Len(t, second.Body, MaxRecordedBodySize)
+	assert.True(t, second.Truncated)
+
+	_, ok = rec.ResponseFor("token-three")
+	assert.False(t, ok)
+}
@@ -0,0 +1,247 @@
+package common
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultVerificationRateLimits are the requests per second allowed to the hosts of
+// well-known APIs, below their documented limits. Requests to other hosts aren't limited.
+var DefaultVerificationRateLimits = map[string]float64{
+	"api.github.com":            10,
+	"gitlab.com":                10,
+	"slack.com":                 5,
+	"api.stripe.com":            20,
+	"api.twilio.com":            10,
+	"api.openai.com":            5,
+	"api.sendgrid.com":          10,
+	"api.mailgun.net":           10,
+	"api.heroku.com":            10,
+	"api.digitalocean.com":      5,
+	"sts.amazonaws.com":         10,
+	"www.googleapis.com":        5,
+	"maps.googleapis.com":       5,
+	"login.microsoftonline.com": 10,
+	"upload.pypi.org":           5,
+}
+
+// maxRetryAfter bounds how long a Retry-After header can pause requests to a host.
+const maxRetryAfter = 5 * time.Minute
+
+// rateLimits is the applied form of the verification rate limits.
+type rateLimits struct {
+	// perSecond is the number of requests per second allowed to each host.
+	perSecond map[string]float64
+	// limiters holds a *hostLimiter per rateLimitKey. They're created on first use and
+	// shared by every worker.
+	limiters sync.Map
+}
+
+var verificationRateLimits atomic.Pointer[rateLimits]
+
+func init() { SetVerificationRateLimits(nil) }
+
+// SetVerificationRateLimits sets the requests per second allowed to each host by the
+// clients created by SaneHttpClient, which are used to verify detector results. The
+// overrides replace the DefaultVerificationRateLimits of the same hosts, and a limit of
+// zero removes a host's limit.
+func SetVerificationRateLimits(overrides map[string]float64) {
+	limits := &rateLimits{perSecond: make(map[string]float64, len(DefaultVerificationRateLimits)+len(overrides))}
+	for host, perSecond := range DefaultVerificationRateLimits {
+		limits.perSecond[host] = perSecond
+	}
+	for host, perSecond := range overrides {
+		limits.perSecond[host] = perSecond
+	}
+	verificationRateLimits.Store(limits)
+}
+
+type rateLimitDetectorKey struct{}
+
+// WithRateLimitDetector returns a copy of ctx whose verification requests are rate
+// limited separately from the requests of other detectors to the same host.
+func WithRateLimitDetector(ctx context.Context, detector string) context.Context {
+	return context.WithValue(ctx, rateLimitDetectorKey{}, detector)
+}
+
+// verificationTimeout cancels its context once the requests it was given to have run for
+// its timeout. The clock is paused while requests wait for their rate limit, so that a
+// detector isn't timed out by the requests other workers sent before it.
+type verificationTimeout struct {
+	context.Context
+	cancel context.CancelCauseFunc
+
+	mu        sync.Mutex
+	timer     *time.Timer
+	remaining time.Duration
+	started   time.Time
+	// waiting is the number of requests waiting for their rate limit.
+	waiting int
+}
+
+type verificationTimeoutKey struct{}
+
+// WithVerificationTimeout returns a copy of ctx that is cancelled after timeout, like
+// context.WithTimeout, except that the time its requests spend waiting for the rate limits
+// set with SetVerificationRateLimits doesn't count. Err returns context.DeadlineExceeded
+// once the timeout has passed.
+func WithVerificationTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	cancelCtx, cancel := context.WithCancelCause(ctx)
+	t := &verificationTimeout{Context: cancelCtx, cancel: cancel, remaining: timeout, started: time.Now()}
+	t.timer = time.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+	return t, func() {
+		t.timer.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+func (t *verificationTimeout) Err() error {
+	if err := t.Context.Err(); err != nil {
+		if cause := context.Cause(t.Context); cause == context.DeadlineExceeded {
+			return cause
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *verificationTimeout) Value(key any) any {
+	if key == (verificationTimeoutKey{}) {
+		return t
+	}
+	return t.Context.Value(key)
+}
+
+// pause stops the clock until resume is called, if the timeout hasn't passed yet.
+func (t *verificationTimeout) pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.waiting++
+	if t.waiting == 1 && t.timer.Stop() {
+		t.remaining -= time.Since(t.started)
+	}
+}
+
+// resume restarts the clock once no request is waiting anymore.
+func (t *verificationTimeout) resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.waiting--
+	if t.waiting == 0 && t.Context.Err() == nil {
+		t.started = time.Now()
+		t.timer.Reset(max(t.remaining, 0))
+	}
+}
+
+// rateLimitKey identifies the requests that share a limiter.
+type rateLimitKey struct {
+	detector string
+	host     string
+}
+
+// hostLimiter throttles the requests of a detector to a host.
+type hostLimiter struct {
+	limiter *rate.Limiter
+
+	mu sync.Mutex
+	// pausedUntil is set from the Retry-After header of the host's responses.
+	pausedUntil time.Time
+}
+
+// limiterFor returns the limiter of the host of req, or nil if the host isn't limited.
+func (l *rateLimits) limiterFor(req *http.Request) *hostLimiter {
+	host := req.URL.Hostname()
+	perSecond, ok := l.perSecond[host]
+	if !ok || perSecond <= 0 {
+		return nil
+	}
+
+	detector, _ := req.Context().Value(rateLimitDetectorKey{}).(string)
+	key := rateLimitKey{detector: detector, host: host}
+	if limiter, ok := l.limiters.Load(key); ok {
+		return limiter.(*hostLimiter)
+	}
+	burst := int(math.Ceil(perSecond))
+	limiter, _ := l.limiters.LoadOrStore(key, &hostLimiter{limiter: rate.NewLimiter(rate.Limit(perSecond), burst)})
+	return limiter.(*hostLimiter)
+}
+
+// wait blocks until a request may be sent, or ctx is done. The verification timeout of
+// ctx is paused meanwhile.
+func (h *hostLimiter) wait(ctx context.Context) error {
+	if t, ok := ctx.Value(verificationTimeoutKey{}).(*verificationTimeout); ok {
+		t.pause()
+		defer t.resume()
+	}
+	h.mu.Lock()
+	pause := time.Until(h.pausedUntil)
+	h.mu.Unlock()
+	if pause > 0 {
+		timer := time.NewTimer(pause)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return h.limiter.Wait(ctx)
+}
+
+// observe pauses requests to the host if res asks to retry later.
+func (h *hostLimiter) observe(res *http.Response) {
+	if res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable {
+		return
+	}
+	delay, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return
+	}
+	until := time.Now().Add(min(delay, maxRetryAfter))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if until.After(h.pausedUntil) {
+		h.pausedUntil = until
+	}
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of seconds or
+// an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
+
+// rateLimitedRoundTrip sends req with roundTrip once the verification rate limit of its
+// host allows it.
+func rateLimitedRoundTrip(req *http.Request, roundTrip func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	limiter := verificationRateLimits.Load().limiterFor(req)
+	if limiter == nil {
+		return roundTrip(req)
+	}
+	if err := limiter.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	res, err := roundTrip(req)
+	if err == nil {
+		limiter.observe(res)
+	}
+	return res, err
+}
@@ -0,0 +1,172 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "120", want: 2 * time.Minute, wantOK: true},
+		{value: "-5", want: 0, wantOK: true},
+		{value: "Mon, 01 Jan 2024 12:00:30 GMT", want: 30 * time.Second, wantOK: true},
+		{value: "Mon, 01 Jan 2024 11:00:00 GMT", want: 0, wantOK: true},
+		{value: "soon", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		assert.Equal(t, tt.wantOK, ok, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+}
+
+func TestSaneHttpClientRateLimits(t *testing.T) {
+	t.Cleanup(func() { SetVerificationRateLimits(nil) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := SaneHttpClient()
+
+	// The limit is shared by concurrent requests.
+	SetVerificationRateLimits(map[string]float64{"127.0.0.1": 50})
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := client.Get(server.URL)
+			if assert.NoError(t, err) {
+				res.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	// The burst of 50 requests is sent at once, and the other 10 at 50 per second.
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	// Each detector has its own limiter.
+	SetVerificationRateLimits(map[string]float64{"127.0.0.1": 1})
+	start = time.Now()
+	for _, detector := range []string{"AWS", "Github", "AWS"} {
+		ctx, cancel := context.WithTimeout(WithRateLimitDetector(context.Background(), detector), 5*time.Second)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		res, err := client.Do(req)
+		if assert.NoError(t, err) {
+			res.Body.Close()
+		}
+		cancel()
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+
+	// A limit of zero removes the default limit.
+	SetVerificationRateLimits(map[string]float64{"api.github.com": 0})
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	assert.Nil(t, verificationRateLimits.Load().limiterFor(req))
+	req, _ = http.NewRequest(http.MethodGet, "https://api.stripe.com/v1/charges", nil)
+	assert.NotNil(t, verificationRateLimits.Load().limiterFor(req))
+}
+
+func TestSaneHttpClientRetryAfter(t *testing.T) {
+	t.Cleanup(func() { SetVerificationRateLimits(nil) })
+	SetVerificationRateLimits(map[string]float64{"127.0.0.1": 100})
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := SaneHttpClient()
+
+	res, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+
+	// Requests to the host are paused until the Retry-After delay has passed.
+	start := time.Now()
+	res, err = client.Get(server.URL)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+
+	// After another 429, a request whose deadline is before the end of the pause fails
+	// without being sent.
+	requests.Store(0)
+	res, err = client.Get(server.URL)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	_, err = client.Do(req)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestVerificationTimeout(t *testing.T) {
+	t.Cleanup(func() { SetVerificationRateLimits(nil) })
+	SetVerificationRateLimits(map[string]float64{"127.0.0.1": 2})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := SaneHttpClient()
+
+	// Use up the burst, so that the next requests wait for the limiter.
+	for i := 0; i < 2; i++ {
+		res, err := client.Get(server.URL)
+		if assert.NoError(t, err) {
+			res.Body.Close()
+		}
+	}
+
+	// The requests wait about a second for the limiter, longer than the timeout, but the
+	// wait doesn't count towards it.
+	ctx, cancel := WithVerificationTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		res, err := client.Do(req)
+		if assert.NoError(t, err) {
+			res.Body.Close()
+		}
+	}
+	assert.NoError(t, ctx.Err())
+
+	// The timeout still runs outside of the waits.
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("verification timeout not reached")
+	}
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+
+	// Cancelling the context isn't a timeout.
+	ctx, cancel = WithVerificationTimeout(context.Background(), time.Minute)
+	cancel()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
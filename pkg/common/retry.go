@@ -0,0 +1,105 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy configures how requests that fail transiently are retried.
+// Requests are retried on connection errors and on 429 and 5xx responses.
+// Other responses, including 401 and 403, are definitive and never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles with each
+	// subsequent retry, and is jittered to avoid retrying in lockstep.
+	BaseDelay time.Duration
+}
+
+// IsRetryableStatus reports whether a response status indicates a transient failure.
+func IsRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// Retry calls do until it succeeds or fails definitively, the attempts are
+// exhausted, or ctx is done. The bodies of responses that are retried are
+// closed. The response and error of the last attempt are returned.
+func (p RetryPolicy) Retry(ctx context.Context, do func() (*http.Response, error)) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		res, err := do()
+		if attempt >= p.MaxAttempts || !shouldRetry(ctx, res, err) {
+			return res, err
+		}
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the retry following attempt. Half of the
+// delay is jittered.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		// Requests that timed out or were canceled have used up their time.
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return IsRetryableStatus(res.StatusCode)
+}
+
+var verificationRetryPolicy atomic.Pointer[RetryPolicy]
+
+// SetVerificationRetryPolicy sets how the clients created by SaneHttpClient,
+// which are used to verify detector results, retry transient failures. By
+// default they don't retry.
+func SetVerificationRetryPolicy(policy RetryPolicy) { verificationRetryPolicy.Store(&policy) }
+
+// retryRoundTrip sends req with roundTrip, retrying it according to the
+// verification retry policy. Requests whose body can't be replayed are only
+// sent once.
+func retryRoundTrip(req *http.Request, roundTrip func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	policy := verificationRetryPolicy.Load()
+	if policy == nil || policy.MaxAttempts < 2 || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return roundTrip(req)
+	}
+
+	attempt := 0
+	return policy.Retry(req.Context(), func() (*http.Response, error) {
+		attempt++
+		if attempt == 1 || req.GetBody == nil {
+			return roundTrip(req)
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry := req.Clone(req.Context())
+		retry.Body = body
+		return roundTrip(retry)
+	})
+}
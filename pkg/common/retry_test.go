@@ -0,0 +1,113 @@
+package common
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxAttempts  int
+		wantStatus   int
+		wantAttempts int
+	}{
+		{name: "success", statuses: []int{200}, maxAttempts: 3, wantStatus: 200, wantAttempts: 1},
+		{name: "server error then success", statuses: []int{503, 500, 200}, maxAttempts: 3, wantStatus: 200, wantAttempts: 3},
+		{name: "rate limited then success", statuses: []int{429, 200}, maxAttempts: 3, wantStatus: 200, wantAttempts: 2},
+		{name: "attempts exhausted", statuses: []int{502, 502, 502, 200}, maxAttempts: 3, wantStatus: 502, wantAttempts: 3},
+		{name: "unauthorized is definitive", statuses: []int{401, 200}, maxAttempts: 3, wantStatus: 401, wantAttempts: 1},
+		{name: "forbidden is definitive", statuses: []int{403, 200}, maxAttempts: 3, wantStatus: 403, wantAttempts: 1},
+		{name: "retries disabled", statuses: []int{500, 200}, maxAttempts: 1, wantStatus: 500, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := RetryPolicy{MaxAttempts: tt.maxAttempts, BaseDelay: time.Millisecond}
+			attempts := 0
+			res, err := policy.Retry(context.Background(), func() (*http.Response, error) {
+				status := tt.statuses[attempts]
+				attempts++
+				return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			assert.Equal(t, tt.wantAttempts, attempts)
+		})
+	}
+}
+
+func TestRetryPolicyRetryConnectionError(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	attempts := 0
+	res, err := policy.Retry(context.Background(), func() (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return &http.Response{StatusCode: 200, Body: http.NoBody}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, 2, attempts)
+
+	// Canceled requests aren't retried.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts = 0
+	_, err = policy.Retry(ctx, func() (*http.Response, error) {
+		attempts++
+		return nil, context.Canceled
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond} {
+		delay := policy.backoff(attempt)
+		assert.GreaterOrEqual(t, delay, want/2)
+		assert.LessOrEqual(t, delay, want)
+	}
+	assert.Zero(t, RetryPolicy{}.backoff(1))
+}
+
+func TestSaneHttpClientRetries(t *testing.T) {
+	t.Cleanup(func() { verificationRetryPolicy.Store(nil) })
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "payload", string(body))
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := SaneHttpClient()
+	res, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, int32(1), requests.Load())
+
+	requests.Store(0)
+	SetVerificationRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	res, err = client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int32(3), requests.Load())
+}
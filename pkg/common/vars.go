@@ -58,7 +58,6 @@ var (
 		"wmv":  {},
 
 		// documents
-		"pdf": {},
 		"psd": {},
 
 		// fonts
@@ -124,3 +123,32 @@ func IsBinary(filename string) bool {
 	_, ok := binaryExtensions[strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))]
 	return ok
 }
+
+// ExtensionSet is a set of file extensions, lowercased and without a leading dot.
+type ExtensionSet map[string]struct{}
+
+// NewExtensionSet returns the set of extensions, which may have a leading dot
+// and any case. It returns nil if no non-empty extension is given.
+func NewExtensionSet(extensions ...string) ExtensionSet {
+	var set ExtensionSet
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if ext == "" {
+			continue
+		}
+		if set == nil {
+			set = make(ExtensionSet, len(extensions))
+		}
+		set[ext] = struct{}{}
+	}
+	return set
+}
+
+// Matches returns true if the extension of filename is in the set, or if the set is empty.
+func (s ExtensionSet) Matches(filename string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	_, ok := s[strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))]
+	return ok
+}
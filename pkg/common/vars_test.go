@@ -75,3 +75,29 @@ func BenchmarkIsBinary(b *testing.B) {
 		IsBinary("test.exe")
 	}
 }
+
+func TestExtensionSet(t *testing.T) {
+	set := NewExtensionSet(".env", "JSON", "yaml", " .PEM ", "")
+	testCases := map[string]bool{
+		".env":                  true,
+		"config/prod.json":      true,
+		"CONFIG.YAML":           true,
+		"certs/server.pem":      true,
+		"app.yml":               false,
+		"main.go":               false,
+		"Makefile":              false,
+		"archive.json.tar.gz":   false,
+		"dir.json/settings.ini": false,
+	}
+	for file, want := range testCases {
+		t.Run(file, func(t *testing.T) {
+			if got := set.Matches(file); got != want {
+				t.Errorf("Matches(%v) got %v, want %v", file, got, want)
+			}
+		})
+	}
+
+	if empty := NewExtensionSet("", "."); empty != nil || !empty.Matches("main.go") {
+		t.Errorf("NewExtensionSet of empty extensions got %v, want a nil set matching every file", empty)
+	}
+}
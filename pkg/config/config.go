@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/custom_detectors"
@@ -30,9 +31,15 @@ func NewYAML(input []byte) (*Config, error) {
 	if err := protoyaml.UnmarshalStrict(input, &messages); err != nil {
 		return nil, err
 	}
-	// Convert the structured YAML into detectors.
+	// Convert the structured YAML into detectors. Detectors are told apart by
+	// their names, including when matching their keywords.
 	var d []detectors.Detector
+	names := make(map[string]struct{}, len(messages.Detectors))
 	for _, detectorConfig := range messages.Detectors {
+		if _, ok := names[detectorConfig.GetName()]; ok {
+			return nil, fmt.Errorf("duplicate custom detector name %q", detectorConfig.GetName())
+		}
+		names[detectorConfig.GetName()] = struct{}{}
 		detector, err := custom_detectors.NewWebhookCustomRegex(detectorConfig)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewYAML(t *testing.T) {
+	conf, err := NewYAML([]byte(`detectors:
+- name: hog
+  keywords: [hog_]
+  regex:
+    token: hog_[a-z]{8}
+  verify:
+  - endpoint: https://example.com/users/{token}
+    method: GET
+    headers:
+    - 'Authorization: Bearer {token}'
+    successRanges: ['200-299']`))
+	assert.NoError(t, err)
+	assert.Len(t, conf.Detectors, 1)
+	assert.Equal(t, []string{"hog_"}, conf.Detectors[0].Keywords())
+}
+
+func TestNewYAML_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"invalid regex": `detectors:
+- name: hog
+  keywords: [hog_]
+  regex:
+    token: hog_[a-z`,
+		"unknown template variable": `detectors:
+- name: hog
+  keywords: [hog_]
+  regex:
+    token: hog_[a-z]{8}
+  verify:
+  - endpoint: https://example.com/{id}
+    method: GET`,
+		"duplicate name": `detectors:
+- name: hog
+  keywords: [hog_]
+  regex:
+    token: hog_[a-z]{8}
+- name: hog
+  keywords: [pig_]
+  regex:
+    token: pig_[a-z]{8}`,
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewYAML([]byte(input))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -2,10 +2,12 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	dpb "github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
@@ -116,6 +118,50 @@ func ParseVerifierEndpoints(verifierURLs map[string]string) (map[DetectorID][]st
 	return verifiers, nil
 }
 
+// ParseDetectorTimeouts parses a map of user supplied detector timeouts. The
+// input keys are detector IDs and the values are durations such as "30s".
+// Timeouts apply to every version of a detector type.
+func ParseDetectorTimeouts(detectorTimeouts map[string]string) (map[dpb.DetectorType]time.Duration, error) {
+	timeouts := make(map[dpb.DetectorType]time.Duration, len(detectorTimeouts))
+	for detectorID, rawTimeout := range detectorTimeouts {
+		key, err := ParseDetector(detectorID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid detector ID for timeout: %w", err)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(rawTimeout))
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q for detector %s: %w", rawTimeout, key, err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("timeout for detector %s must be positive: %q", key, rawTimeout)
+		}
+		timeouts[key.ID] = timeout
+	}
+	return timeouts, nil
+}
+
+// ParseVerificationRateLimits parses a map of user supplied verification rate
+// limits. The input keys are host names and the values are requests per second,
+// where 0 removes the host's limit.
+func ParseVerificationRateLimits(rateLimits map[string]string) (map[string]float64, error) {
+	limits := make(map[string]float64, len(rateLimits))
+	for host, rawLimit := range rateLimits {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" || strings.ContainsAny(host, "/:") {
+			return nil, fmt.Errorf("invalid host for rate limit: %q", host)
+		}
+		limit, err := strconv.ParseFloat(strings.TrimSpace(rawLimit), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit %q for host %s: %w", rawLimit, host, err)
+		}
+		if limit < 0 || math.IsNaN(limit) || math.IsInf(limit, 0) {
+			return nil, fmt.Errorf("rate limit for host %s must not be negative: %q", host, rawLimit)
+		}
+		limits[host] = limit
+	}
+	return limits, nil
+}
+
 func (id DetectorID) String() string {
 	name := dpb.DetectorType_name[int32(id.ID)]
 	if name == "" {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	dpb "github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
@@ -45,3 +46,55 @@ func TestDetectorParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectorTimeoutsParsing(t *testing.T) {
+	tests := map[string]struct {
+		input    map[string]string
+		expected map[dpb.DetectorType]time.Duration
+	}{
+		"named":            {map[string]string{"aws": "30s"}, map[dpb.DetectorType]time.Duration{dpb.DetectorType_AWS: 30 * time.Second}},
+		"id number":        {map[string]string{"8": "1m"}, map[dpb.DetectorType]time.Duration{dpb.DetectorType_Github: time.Minute}},
+		"version ignored":  {map[string]string{"github.v1": "5s"}, map[dpb.DetectorType]time.Duration{dpb.DetectorType_Github: 5 * time.Second}},
+		"invalid name":     {map[string]string{"foo": "5s"}, nil},
+		"invalid duration": {map[string]string{"aws": "5"}, nil},
+		"zero duration":    {map[string]string{"aws": "0s"}, nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, gotErr := ParseDetectorTimeouts(tt.input)
+			if tt.expected == nil {
+				assert.Error(t, gotErr)
+				return
+			}
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestVerificationRateLimitsParsing(t *testing.T) {
+	tests := map[string]struct {
+		input    map[string]string
+		expected map[string]float64
+	}{
+		"requests per second": {map[string]string{"api.github.com": "5"}, map[string]float64{"api.github.com": 5}},
+		"fractional":          {map[string]string{" Slack.com ": "0.5"}, map[string]float64{"slack.com": 0.5}},
+		"unlimited":           {map[string]string{"api.github.com": "0"}, map[string]float64{"api.github.com": 0}},
+		"url":                 {map[string]string{"https://api.github.com": "5"}, nil},
+		"invalid limit":       {map[string]string{"api.github.com": "fast"}, nil},
+		"negative limit":      {map[string]string{"api.github.com": "-1"}, nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, gotErr := ParseVerificationRateLimits(tt.input)
+			if tt.expected == nil {
+				assert.Error(t, gotErr)
+				return
+			}
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
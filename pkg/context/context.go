@@ -128,6 +128,12 @@ func Cause(ctx context.Context) error {
 	return context.Cause(ctx)
 }
 
+// AfterFunc calls f in its own goroutine once ctx is done, unless the
+// returned stop function is called first. See context.AfterFunc.
+func AfterFunc(ctx context.Context, f func()) (stop func() bool) {
+	return context.AfterFunc(ctx, f)
+}
+
 // WithValue returns context.WithValue with the log object propagated and
 // the value added to the structured log values (if the key is a string).
 func WithValue(parent Context, key, val any) Context {
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
@@ -25,11 +28,14 @@ const maxTotalMatches = 100
 // initialization).
 type CustomRegexWebhook struct {
 	*custom_detectorspb.CustomRegex
+	// regexes are the compiled regexes, by name.
+	regexes map[string]*regexp.Regexp
 }
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*CustomRegexWebhook)(nil)
 var _ detectors.CustomFalsePositiveChecker = (*CustomRegexWebhook)(nil)
+var _ detectors.Categorizer = (*CustomRegexWebhook)(nil)
 
 // NewWebhookCustomRegex initializes and validates a CustomRegexWebhook. An
 // unexported type is intentionally returned here to ensure the values have
@@ -42,6 +48,9 @@ func NewWebhookCustomRegex(pb *custom_detectorspb.CustomRegex) (*CustomRegexWebh
 	if err := ValidateRegex(pb.Regex); err != nil {
 		return nil, err
 	}
+	if err := ValidateCategories(pb.Categories); err != nil {
+		return nil, err
+	}
 
 	for _, verify := range pb.Verify {
 		if err := ValidateVerifyEndpoint(verify.Endpoint, verify.Unsafe); err != nil {
@@ -50,25 +59,59 @@ func NewWebhookCustomRegex(pb *custom_detectorspb.CustomRegex) (*CustomRegexWebh
 		if err := ValidateVerifyHeaders(verify.Headers); err != nil {
 			return nil, err
 		}
+		if err := ValidateVerifyRanges(verify.SuccessRanges); err != nil {
+			return nil, err
+		}
+		if verify.Method == "" {
+			if verify.Body != "" {
+				return nil, fmt.Errorf("verify endpoint %q: a body requires a method", verify.Endpoint)
+			}
+			continue
+		}
+		if err := ValidateVerifyMethod(verify.Method); err != nil {
+			return nil, err
+		}
+		if err := ValidateRegexVars(pb.Regex, append([]string{verify.Endpoint, verify.Body}, verify.Headers...)...); err != nil {
+			return nil, err
+		}
+	}
+
+	regexes, err := compileRegexes(pb.Regex)
+	if err != nil {
+		return nil, err
 	}
 
 	// TODO: Copy only necessary data out of pb.
-	return &CustomRegexWebhook{pb}, nil
+	return &CustomRegexWebhook{CustomRegex: pb, regexes: regexes}, nil
+}
+
+func compileRegexes(regex map[string]string) (map[string]*regexp.Regexp, error) {
+	regexes := make(map[string]*regexp.Regexp, len(regex))
+	for name, reg := range regex {
+		compiled, err := regexp.Compile(reg)
+		if err != nil {
+			return nil, err
+		}
+		regexes[name] = compiled
+	}
+	return regexes, nil
 }
 
 var httpClient = common.SaneHttpClient()
 
 func (c *CustomRegexWebhook) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
-	regexMatches := make(map[string][][]string, len(c.GetRegex()))
-
-	// Find all submatches for each regex.
-	for name, regex := range c.GetRegex() {
-		regex, err := regexp.Compile(regex)
-		if err != nil {
+	regexes := c.regexes
+	if regexes == nil {
+		if regexes, err = compileRegexes(c.GetRegex()); err != nil {
 			// This will only happen if the regex is invalid.
 			return nil, err
 		}
+	}
+	regexMatches := make(map[string][][]string, len(regexes))
+
+	// Find all submatches for each regex.
+	for name, regex := range regexes {
 		regexMatches[name] = regex.FindAllStringSubmatch(dataStr, -1)
 	}
 
@@ -138,40 +181,24 @@ func (c *CustomRegexWebhook) createResults(ctx context.Context, match map[string
 			return nil
 		}
 	}
-	// Verify via webhook.
-	jsonBody, err := json.Marshal(map[string]map[string][]string{
-		c.GetName(): match,
-	})
-	if err != nil {
-		// This should never happen, but if it does, return nil to not
-		// disrupt other verification.
-		return nil
-	}
 	// Try each config until we successfully verify.
 	for _, verifyConfig := range c.GetVerify() {
 		if common.IsDone(ctx) {
 			// TODO: Log we're possibly leaving out results.
 			return ctx.Err()
 		}
-		req, err := http.NewRequestWithContext(ctx, "POST", verifyConfig.GetEndpoint(), bytes.NewReader(jsonBody))
+		req, err := c.verificationRequest(ctx, verifyConfig, match)
 		if err != nil {
 			continue
 		}
-		for _, header := range verifyConfig.GetHeaders() {
-			key, value, found := strings.Cut(header, ":")
-			if !found {
-				// Should be unreachable due to validation.
-				continue
-			}
-			req.Header.Add(key, strings.TrimLeft(value, "\t\n\v\f\r "))
-		}
 		res, err := httpClient.Do(req)
 		if err != nil {
 			continue
 		}
 		// TODO: Read response body.
+		_, _ = io.Copy(io.Discard, res.Body)
 		res.Body.Close()
-		if res.StatusCode == http.StatusOK {
+		if isSuccessStatus(res.StatusCode, verifyConfig.GetSuccessRanges()) {
 			result.Verified = true
 			break
 		}
@@ -185,6 +212,66 @@ func (c *CustomRegexWebhook) createResults(ctx context.Context, match map[string
 	}
 }
 
+// verificationRequest builds the request verifying a match. Verifiers with a
+// method are request templates, which are expanded with the match. Otherwise
+// the match is posted to the endpoint as a webhook.
+func (c *CustomRegexWebhook) verificationRequest(ctx context.Context, verifyConfig *custom_detectorspb.VerifierConfig, match map[string][]string) (*http.Request, error) {
+	expand := func(s string) string { return s }
+	method, endpoint, body := verifyConfig.GetMethod(), verifyConfig.GetEndpoint(), []byte(nil)
+	if method == "" {
+		jsonBody, err := json.Marshal(map[string]map[string][]string{
+			c.GetName(): match,
+		})
+		if err != nil {
+			return nil, err
+		}
+		method, body = http.MethodPost, jsonBody
+	} else {
+		expand = func(s string) string { return NewRegexVarString(s).Expand(match) }
+		endpoint, body = expand(endpoint), []byte(expand(verifyConfig.GetBody()))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range verifyConfig.GetHeaders() {
+		key, value, found := strings.Cut(expand(header), ":")
+		if !found {
+			// Should be unreachable due to validation.
+			continue
+		}
+		req.Header.Add(key, strings.TrimLeft(value, "\t\n\v\f\r "))
+	}
+	return req, nil
+}
+
+// isSuccessStatus reports whether a verification response status is in one
+// of the validated success ranges. Only 200 is a success without ranges.
+func isSuccessStatus(status int, successRanges []string) bool {
+	if len(successRanges) == 0 {
+		return status == http.StatusOK
+	}
+	for _, successRange := range successRanges {
+		lower, upper, found := strings.Cut(successRange, "-")
+		if !found {
+			upper = lower
+		}
+		lowerBound, err := strconv.Atoi(lower)
+		if err != nil {
+			continue
+		}
+		upperBound, err := strconv.Atoi(upper)
+		if err != nil {
+			continue
+		}
+		if status >= lowerBound && status <= upperBound {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *CustomRegexWebhook) Keywords() []string {
 	return c.GetKeywords()
 }
@@ -250,6 +337,17 @@ func permutateMatches(regexMatches map[string][][]string) []map[string][]string
 	return matches
 }
 
+// Categories returns the categories the detector was tagged with in its configuration.
+func (c *CustomRegexWebhook) Categories() []detectors.Category {
+	categories := make([]detectors.Category, 0, len(c.GetCategories()))
+	for _, name := range c.GetCategories() {
+		// The names were validated when the detector was created.
+		category, _ := detectors.ParseCategory(name)
+		categories = append(categories, category)
+	}
+	return categories
+}
+
 func (c *CustomRegexWebhook) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_CustomRegex
 }
@@ -2,6 +2,9 @@ package custom_detectors
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -106,7 +109,7 @@ func TestCustomDetectorsParsing(t *testing.T) {
 
 func TestFromData_InvalidRegEx(t *testing.T) {
 	c := &CustomRegexWebhook{
-		&custom_detectorspb.CustomRegex{
+		CustomRegex: &custom_detectorspb.CustomRegex{
 			Name:     "Internal bi tool",
 			Keywords: []string{"secret_v1_", "pat_v2_"},
 			Regex: map[string]string{
@@ -208,6 +211,96 @@ func TestDetector(t *testing.T) {
 	assert.Equal(t, results[0].Raw, []byte(`password="123456"`))
 }
 
+func TestDetector_VerifyTemplate(t *testing.T) {
+	var gotAuth, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotAuth, gotBody = r.Header.Get("Authorization"), string(body)
+		if r.Method != http.MethodPut || r.URL.Path != "/users/4242" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	detector, err := NewWebhookCustomRegex(&custom_detectorspb.CustomRegex{
+		Name:     "template",
+		Keywords: []string{"hog_"},
+		Regex:    map[string]string{"id": `hog_id=([0-9]+)`, "token": `hog_[a-z]{8}`},
+		Verify: []*custom_detectorspb.VerifierConfig{{
+			Endpoint:      server.URL + "/users/{id.1}",
+			Unsafe:        true,
+			Method:        http.MethodPut,
+			Headers:       []string{"Authorization: Bearer {token}"},
+			Body:          `{"user": "{id.1}"}`,
+			SuccessRanges: []string{"200-299"},
+		}},
+	})
+	assert.NoError(t, err)
+
+	results, err := detector.FromData(context.Background(), true, []byte("hog_id=4242 hog_abcdefgh"))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.True(t, results[0].Verified)
+	}
+	assert.Equal(t, "Bearer hog_abcdefgh", gotAuth)
+	assert.Equal(t, `{"user": "4242"}`, gotBody)
+}
+
+func TestDetector_VerifyWebhookSuccessRanges(t *testing.T) {
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		successRanges []string
+		verified      bool
+	}{
+		{successRanges: nil, verified: false},
+		{successRanges: []string{"200", "204"}, verified: true},
+	} {
+		detector, err := NewWebhookCustomRegex(&custom_detectorspb.CustomRegex{
+			Name:     "webhook",
+			Keywords: []string{"hog_"},
+			Regex:    map[string]string{"token": `hog_[a-z]{8}`},
+			Verify:   []*custom_detectorspb.VerifierConfig{{Endpoint: server.URL, Unsafe: true, SuccessRanges: tt.successRanges}},
+		})
+		assert.NoError(t, err)
+
+		results, err := detector.FromData(context.Background(), true, []byte("hog_abcdefgh"))
+		assert.NoError(t, err)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, tt.verified, results[0].Verified)
+		}
+		assert.JSONEq(t, `{"webhook": {"token": ["hog_abcdefgh"]}}`, gotBody)
+	}
+}
+
+func TestNewWebhookCustomRegex_InvalidVerifier(t *testing.T) {
+	tests := map[string]*custom_detectorspb.VerifierConfig{
+		"unknown variable":    {Endpoint: "https://example.com/{other}", Method: http.MethodGet},
+		"unsupported method":  {Endpoint: "https://example.com/", Method: "TRACE"},
+		"body without method": {Endpoint: "https://example.com/", Body: "{token}"},
+		"invalid range":       {Endpoint: "https://example.com/", SuccessRanges: []string{"299-200"}},
+	}
+	for name, verifier := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewWebhookCustomRegex(&custom_detectorspb.CustomRegex{
+				Name:     "invalid",
+				Keywords: []string{"hog_"},
+				Regex:    map[string]string{"token": `hog_[a-z]{8}`},
+				Verify:   []*custom_detectorspb.VerifierConfig{verifier},
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
 func BenchmarkProductIndices(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = productIndices(3, 2, 6)
@@ -42,3 +42,25 @@ func NewRegexVarString(original string) RegexVarString {
 		variables: variables,
 	}
 }
+
+// Expand returns the string with each variable replaced by the group of the
+// named regex match. Variables of unknown names or groups, including groups
+// too large to parse, are replaced by an empty string.
+func (r RegexVarString) Expand(match map[string][]string) string {
+	return nameGroupRegex.ReplaceAllStringFunc(r.original, func(variable string) string {
+		groups := nameGroupRegex.FindStringSubmatch(variable)
+		name, group := groups[1], 0
+		if len(groups[2]) > 1 {
+			g, err := strconv.Atoi(strings.TrimSpace(groups[2][1:]))
+			if err != nil {
+				return ""
+			}
+			group = g
+		}
+		values := match[name]
+		if group >= len(values) {
+			return ""
+		}
+		return values[group]
+	})
+}
@@ -74,3 +74,51 @@ func TestVarString(t *testing.T) {
 		})
 	}
 }
+
+func TestVarStringExpand(t *testing.T) {
+	match := map[string][]string{
+		"id":     {"id=abc", "abc"},
+		"secret": {"s3cr3t"},
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "no variables",
+			input: "https://example.com/",
+			want:  "https://example.com/",
+		},
+		{
+			name:  "whole match and group",
+			input: "https://example.com/{id.1}?q={ id }",
+			want:  "https://example.com/abc?q=id=abc",
+		},
+		{
+			name:  "repeated variable",
+			input: "{secret}:{secret.0}",
+			want:  "s3cr3t:s3cr3t",
+		},
+		{
+			name:  "unknown group",
+			input: "Bearer {secret.2}",
+			want:  "Bearer ",
+		},
+		{
+			name:  "invalid group",
+			input: "Bearer {secret.99999999999999999999}",
+			want:  "Bearer ",
+		},
+		{
+			name:  "not a variable",
+			input: `{"token": "{secret}"}`,
+			want:  `{"token": "s3cr3t"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewRegexVarString(tt.input).Expand(match))
+		})
+	}
+}
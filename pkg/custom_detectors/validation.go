@@ -2,9 +2,12 @@ package custom_detectors
 
 import (
 	"fmt"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 )
 
 func ValidateKeywords(keywords []string) error {
@@ -52,6 +55,17 @@ func ValidateVerifyHeaders(headers []string) error {
 	return nil
 }
 
+// ValidateVerifyMethod validates the HTTP method of a verification request
+// template.
+func ValidateVerifyMethod(method string) error {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return nil
+	default:
+		return fmt.Errorf("unsupported http method %q", method)
+	}
+}
+
 func ValidateVerifyRanges(ranges []string) error {
 	const httpLowerRange = 100
 	const httpUpperRange = 599
@@ -107,3 +121,12 @@ func ValidateRegexVars(regex map[string]string, body ...string) error {
 	}
 	return nil
 }
+
+func ValidateCategories(categories []string) error {
+	for _, category := range categories {
+		if _, err := detectors.ParseCategory(category); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -232,3 +232,74 @@ func TestCustomDetectorsVerifyRegexVarsValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomDetectorsVerifyMethodValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		wantErr bool
+	}{
+		{
+			name:    "Test GET",
+			method:  "GET",
+			wantErr: false,
+		},
+		{
+			name:    "Test POST",
+			method:  "POST",
+			wantErr: false,
+		},
+		{
+			name:    "Test lowercase method",
+			method:  "get",
+			wantErr: true,
+		},
+		{
+			name:    "Test unsupported method",
+			method:  "CONNECT",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateVerifyMethod(tt.method)
+
+			if (got != nil && !tt.wantErr) || (got == nil && tt.wantErr) {
+				t.Errorf("ValidateVerifyMethod() error = %v, wantErr %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCustomDetectorsCategoriesValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []string
+		wantErr    bool
+	}{
+		{
+			name:       "No categories",
+			categories: nil,
+			wantErr:    false,
+		},
+		{
+			name:       "Known categories",
+			categories: []string{"cloud", "Database"},
+			wantErr:    false,
+		},
+		{
+			name:       "Unknown category",
+			categories: []string{"cloud", "weather"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateCategories(tt.categories)
+
+			if (got != nil && !tt.wantErr) || (got == nil && tt.wantErr) {
+				t.Errorf("ValidateCategories() error = %v, wantErr %v", got, tt.wantErr)
+			}
+		})
+	}
+}
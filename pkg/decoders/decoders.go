@@ -1,6 +1,9 @@
 package decoders
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
@@ -10,11 +13,61 @@ func DefaultDecoders() []Decoder {
 		// UTF8 must be first for duplicate detection
 		&UTF8{},
 		&Base64{},
+		// UTF32 must run before UTF16, which would otherwise replace UTF-32LE
+		// text with only its ASCII characters.
+		&UTF32{},
 		&UTF16{},
 		&EscapedUnicode{},
 	}
 }
 
+// DecoderFromType returns a new decoder of the given type.
+func DecoderFromType(decoderType detectorspb.DecoderType) (Decoder, error) {
+	switch decoderType {
+	case detectorspb.DecoderType_PLAIN:
+		return &UTF8{}, nil
+	case detectorspb.DecoderType_BASE64:
+		return &Base64{}, nil
+	case detectorspb.DecoderType_UTF16:
+		return &UTF16{}, nil
+	case detectorspb.DecoderType_ESCAPED_UNICODE:
+		return &EscapedUnicode{}, nil
+	case detectorspb.DecoderType_UTF32:
+		return &UTF32{}, nil
+	default:
+		return nil, fmt.Errorf("unknown decoder type: %s", decoderType)
+	}
+}
+
+// DecodersFromTypes returns decoders for the given types, in the given order.
+// Decoder types that are unknown or listed more than once result in an error.
+func DecodersFromTypes(decoderTypes ...detectorspb.DecoderType) ([]Decoder, error) {
+	seen := make(map[detectorspb.DecoderType]struct{}, len(decoderTypes))
+	decs := make([]Decoder, 0, len(decoderTypes))
+	for _, decoderType := range decoderTypes {
+		if _, ok := seen[decoderType]; ok {
+			return nil, fmt.Errorf("decoder type specified more than once: %s", decoderType)
+		}
+		seen[decoderType] = struct{}{}
+
+		dec, err := DecoderFromType(decoderType)
+		if err != nil {
+			return nil, err
+		}
+		decs = append(decs, dec)
+	}
+	return decs, nil
+}
+
+// ParseDecoderType returns the decoder type for a case-insensitive name such as "base64".
+func ParseDecoderType(name string) (detectorspb.DecoderType, error) {
+	value, ok := detectorspb.DecoderType_value[strings.ToUpper(strings.TrimSpace(name))]
+	if !ok || detectorspb.DecoderType(value) == detectorspb.DecoderType_UNKNOWN {
+		return detectorspb.DecoderType_UNKNOWN, fmt.Errorf("unknown decoder: %q", name)
+	}
+	return detectorspb.DecoderType(value), nil
+}
+
 // DecodableChunk is a chunk that includes the type of decoder used.
 // This allows us to avoid a type assertion on each decoder.
 type DecodableChunk struct {
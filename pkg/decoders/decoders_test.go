@@ -0,0 +1,56 @@
+package decoders
+
+import (
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+func TestDecodersFromTypes(t *testing.T) {
+	decs, err := DecodersFromTypes(detectorspb.DecoderType_ESCAPED_UNICODE, detectorspb.DecoderType_PLAIN)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decs) != 2 {
+		t.Fatalf("expected 2 decoders, got %d", len(decs))
+	}
+	if _, ok := decs[0].(*EscapedUnicode); !ok {
+		t.Errorf("expected EscapedUnicode decoder first, got %T", decs[0])
+	}
+	if _, ok := decs[1].(*UTF8); !ok {
+		t.Errorf("expected UTF8 decoder second, got %T", decs[1])
+	}
+
+	if _, err := DecodersFromTypes(detectorspb.DecoderType_UNKNOWN); err == nil {
+		t.Error("expected error for unknown decoder type")
+	}
+	if _, err := DecodersFromTypes(detectorspb.DecoderType_PLAIN, detectorspb.DecoderType_PLAIN); err == nil {
+		t.Error("expected error for duplicate decoder type")
+	}
+}
+
+func TestParseDecoderType(t *testing.T) {
+	tests := map[string]struct {
+		want    detectorspb.DecoderType
+		wantErr bool
+	}{
+		"plain":           {want: detectorspb.DecoderType_PLAIN},
+		"BASE64":          {want: detectorspb.DecoderType_BASE64},
+		" utf16 ":         {want: detectorspb.DecoderType_UTF16},
+		"escaped_unicode": {want: detectorspb.DecoderType_ESCAPED_UNICODE},
+		"unknown":         {wantErr: true},
+		"rot13":           {wantErr: true},
+		"":                {wantErr: true},
+	}
+
+	for name, tt := range tests {
+		got, err := ParseDecoderType(name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDecoderType(%q) error = %v, wantErr %v", name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDecoderType(%q) = %s, want %s", name, got, tt.want)
+		}
+	}
+}
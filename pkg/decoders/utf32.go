@@ -0,0 +1,91 @@
+package decoders
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+type UTF32 struct{}
+
+func (d *UTF32) FromChunk(chunk *sources.Chunk) *DecodableChunk {
+	if chunk == nil || len(chunk.Data) == 0 {
+		return nil
+	}
+
+	utf32Data := utf32ToUTF8(chunk.Data)
+	if len(utf32Data) == 0 {
+		return nil
+	}
+	chunk.Data = utf32Data
+	return &DecodableChunk{Chunk: chunk, DecoderType: detectorspb.DecoderType_UTF32}
+}
+
+var (
+	utf32LEBOM = []byte{0xFF, 0xFE, 0x00, 0x00}
+	utf32BEBOM = []byte{0x00, 0x00, 0xFE, 0xFF}
+)
+
+// utf32ToUTF8 converts a byte slice containing UTF-32 encoded data to a UTF-8
+// encoded byte slice. The byte order is taken from the byte order mark if
+// there is one, and otherwise guessed from the position of the null bytes
+// that pad most UTF-32 code units. Nil is returned for data that doesn't look
+// like UTF-32, so other text isn't mangled.
+func utf32ToUTF8(b []byte) []byte {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(b, utf32LEBOM):
+		order, b = binary.LittleEndian, b[len(utf32LEBOM):]
+	case bytes.HasPrefix(b, utf32BEBOM):
+		order, b = binary.BigEndian, b[len(utf32BEBOM):]
+	default:
+		order = guessUTF32ByteOrder(b)
+		if order == nil {
+			return nil
+		}
+	}
+
+	var buf bytes.Buffer
+	for i := 0; i+4 <= len(b); i += 4 {
+		r := rune(order.Uint32(b[i:]))
+		if utf8.ValidRune(r) && (unicode.IsPrint(r) || unicode.IsSpace(r)) {
+			buf.WriteRune(r)
+		}
+	}
+	return buf.Bytes()
+}
+
+// guessUTF32ByteOrder returns the byte order of data without a byte order
+// mark, or nil if the data isn't UTF-32. Code points never use the high byte,
+// and text rarely leaves the Basic Multilingual Plane, so in UTF-32 text most
+// code units have two null bytes at the high end and a non-null low byte.
+func guessUTF32ByteOrder(b []byte) binary.ByteOrder {
+	units := len(b) / 4
+	if units == 0 {
+		return nil
+	}
+
+	var le, be int
+	for i := 0; i+4 <= len(b); i += 4 {
+		if b[i] != 0 && b[i+2] == 0 && b[i+3] == 0 {
+			le++
+		}
+		if b[i] == 0 && b[i+1] == 0 && b[i+3] != 0 {
+			be++
+		}
+	}
+
+	// Require a majority of the code units to match the pattern.
+	switch {
+	case le > be && le*2 > units:
+		return binary.LittleEndian
+	case be > le && be*2 > units:
+		return binary.BigEndian
+	default:
+		return nil
+	}
+}
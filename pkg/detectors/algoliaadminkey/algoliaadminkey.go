@@ -2,27 +2,34 @@ package algoliaadminkey
 
 import (
 	"context"
-	regexp "github.com/wasilibs/go-re2"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 
+	regexp "github.com/wasilibs/go-re2"
+
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{
+type Scanner struct {
 	detectors.DefaultMultiPartCredentialProvider
+	client *http.Client
 }
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
 var (
-	client = common.SaneHttpClient()
+	defaultClient = common.SaneHttpClient()
 
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
-	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"algolia"}) + `\b([a-zA-Z0-9]{32})\b`)
+	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"algolia"}) + `\b([a-f0-9]{32})\b`)
 	idPat  = regexp.MustCompile(detectors.PrefixRegex([]string{"algolia"}) + `\b([A-Z0-9]{10})\b`)
 )
 
@@ -36,40 +43,30 @@ func (s Scanner) Keywords() []string {
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
-	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
-	idMatches := idPat.FindAllStringSubmatch(dataStr, -1)
+	keys := detectors.UniqueMatches(keyPat, dataStr)
+	appIDs := detectors.UniqueMatches(idPat, dataStr)
 
-	for _, match := range matches {
-		if len(match) != 2 {
-			continue
-		}
-		resMatch := strings.TrimSpace(match[1])
-		for _, idMatch := range idMatches {
-			if len(idMatch) != 2 {
-				continue
-			}
-			resIdMatch := strings.TrimSpace(idMatch[1])
+	client := s.client
+	if client == nil {
+		client = defaultClient
+	}
 
+	pairs := 0
+	for _, key := range keys {
+		for _, appID := range appIDs {
 			s1 := detectors.Result{
 				DetectorType: detectorspb.DetectorType_AlgoliaAdminKey,
-				Raw:          []byte(resMatch),
-				RawV2:        []byte(resMatch + resIdMatch),
+				Raw:          []byte(key),
+				RawV2:        []byte(key + appID),
+				Redacted:     redact(appID, key),
 			}
 
-			if verify {
-				req, err := http.NewRequestWithContext(ctx, "GET", "https://"+resIdMatch+"-dsn.algolia.net/1/keys", nil)
-				if err != nil {
-					continue
-				}
-				req.Header.Add("X-Algolia-Application-Id", resIdMatch)
-				req.Header.Add("X-Algolia-API-Key", resMatch)
-				res, err := client.Do(req)
-				if err == nil {
-					defer res.Body.Close()
-					if res.StatusCode >= 200 && res.StatusCode < 300 {
-						s1.Verified = true
-					}
-				}
+			if verify && pairs < detectors.MaxVerifiedPairs {
+				pairs++
+				verified, extraData, verificationErr := verifyKey(ctx, client, appID, key)
+				s1.Verified = verified
+				s1.ExtraData = extraData
+				s1.SetVerificationError(verificationErr, key)
 			}
 
 			results = append(results, s1)
@@ -78,6 +75,92 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	return results, nil
 }
 
+// keyResponse is the part of the description of an API key that tells what it grants.
+type keyResponse struct {
+	ACL     []string `json:"acl"`
+	Indexes []string `json:"indexes"`
+}
+
+// verifyKey validates the key of an application and returns the permissions it grants. Any key
+// can retrieve its own description, which lists its ACL, except the admin key, which isn't
+// described but is the only key that can always list the keys of the application.
+// https://www.algolia.com/doc/rest-api/search/#get-api-key-permissions
+func verifyKey(ctx context.Context, client *http.Client, appID, key string) (bool, map[string]string, error) {
+	res, err := get(ctx, client, appID, key, "/1/keys/"+url.PathEscape(key))
+	if err != nil {
+		// Applications that don't exist have no host.
+		if strings.Contains(err.Error(), "no such host") {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		var keyRes keyResponse
+		if err := json.NewDecoder(res.Body).Decode(&keyRes); err != nil {
+			return false, nil, err
+		}
+		sort.Strings(keyRes.ACL)
+		extraData := map[string]string{
+			"admin_key": "false",
+			"acl":       strings.Join(keyRes.ACL, ","),
+		}
+		if len(keyRes.Indexes) > 0 {
+			extraData["indexes"] = strings.Join(keyRes.Indexes, ",")
+		}
+		return true, extraData, nil
+	case http.StatusNotFound:
+		// The key was accepted, but isn't one of the keys of the application.
+		return verifyAdminKey(ctx, client, appID, key)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, nil, nil
+	default:
+		return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
+// verifyAdminKey reports whether the key is the admin key of the application, by listing its keys.
+func verifyAdminKey(ctx context.Context, client *http.Client, appID, key string) (bool, map[string]string, error) {
+	res, err := get(ctx, client, appID, key, "/1/keys")
+	if err != nil {
+		return false, nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, map[string]string{"admin_key": "true"}, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, nil, nil
+	default:
+		return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
+func get(ctx context.Context, client *http.Client, appID, key, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+appID+"-dsn.algolia.net"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("X-Algolia-Application-Id", appID)
+	req.Header.Add("X-Algolia-API-Key", key)
+	return client.Do(req)
+}
+
+// redact keeps the application ID of the key, which isn't secret, and the ends of the key so
+// that keys of the same application can be told apart.
+func redact(appID, key string) string {
+	return appID + ":" + key[:4] + strings.Repeat("*", 8) + key[len(key)-4:]
+}
+
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_AlgoliaAdminKey
 }
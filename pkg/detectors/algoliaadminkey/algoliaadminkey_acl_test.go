@@ -0,0 +1,121 @@
+package algoliaadminkey
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+const (
+	testAppID     = "ABCDE12345"
+	testSearchKey = "0123456789abcdef0123456789abcdef"
+	testAdminKey  = "fedcba9876543210fedcba9876543210"
+)
+
+func TestAlgoliaAdminKey_ACL(t *testing.T) {
+	var requests []string
+	client := &http.Client{Transport: common.FakeTransport{
+		CreateResponse: func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.URL.Host+req.URL.Path)
+			key := req.Header.Get("X-Algolia-API-Key")
+			status, body := http.StatusForbidden, `{"message":"Invalid Application-ID or API key","status":403}`
+			switch {
+			case req.Header.Get("X-Algolia-Application-Id") != testAppID:
+			case key == testSearchKey && req.URL.Path == "/1/keys/"+testSearchKey:
+				status, body = http.StatusOK, `{"value":"`+testSearchKey+`","acl":["search","browse"],"indexes":["products"]}`
+			case key == testAdminKey && req.URL.Path == "/1/keys/"+testAdminKey:
+				status, body = http.StatusNotFound, `{"message":"Key does not exist","status":404}`
+			case key == testAdminKey && req.URL.Path == "/1/keys":
+				status, body = http.StatusOK, `{"keys":[]}`
+			}
+			return &http.Response{Request: req, StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}}
+	s := Scanner{client: client}
+
+	tests := []struct {
+		name          string
+		key           string
+		wantVerified  bool
+		wantExtraData map[string]string
+		wantRequests  []string
+	}{
+		{
+			name:          "search key",
+			key:           testSearchKey,
+			wantVerified:  true,
+			wantExtraData: map[string]string{"admin_key": "false", "acl": "browse,search", "indexes": "products"},
+			wantRequests:  []string{"ABCDE12345-dsn.algolia.net/1/keys/" + testSearchKey},
+		},
+		{
+			name:          "admin key",
+			key:           testAdminKey,
+			wantVerified:  true,
+			wantExtraData: map[string]string{"admin_key": "true"},
+			wantRequests:  []string{"ABCDE12345-dsn.algolia.net/1/keys/" + testAdminKey, "ABCDE12345-dsn.algolia.net/1/keys"},
+		},
+		{
+			name:         "invalid key",
+			key:          "00000000000000000000000000000000",
+			wantRequests: []string{"ABCDE12345-dsn.algolia.net/1/keys/00000000000000000000000000000000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			data := fmt.Sprintf("ALGOLIA_APP_ID=%s\nALGOLIA_API_KEY=%s", testAppID, tt.key)
+			results, err := s.FromData(context.Background(), true, []byte(data))
+			assert.NoError(t, err)
+			if assert.Len(t, results, 1) {
+				assert.Equal(t, tt.wantVerified, results[0].Verified)
+				assert.Equal(t, tt.wantExtraData, results[0].ExtraData)
+				assert.NoError(t, results[0].VerificationError())
+				assert.Equal(t, "ABCDE12345:"+tt.key[:4]+"********"+tt.key[28:], results[0].Redacted)
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestAlgoliaAdminKey_MaxPairs(t *testing.T) {
+	var data strings.Builder
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(&data, "algolia app id ABCDE1234%d\n", i)
+		fmt.Fprintf(&data, "algolia api key %032x\n", i)
+	}
+
+	var requests int
+	client := &http.Client{Transport: common.FakeTransport{
+		CreateResponse: func(req *http.Request) (*http.Response, error) {
+			requests++
+			return &http.Response{Request: req, StatusCode: http.StatusForbidden, Body: io.NopCloser(strings.NewReader(""))}, nil
+		},
+	}}
+
+	// Every pair is reported, but only the first ones are verified.
+	results, err := Scanner{client: client}.FromData(context.Background(), true, []byte(data.String()))
+	assert.NoError(t, err)
+	assert.Len(t, results, 25)
+	assert.Equal(t, detectors.MaxVerifiedPairs, requests)
+}
+
+func TestAlgoliaAdminKey_UnexpectedStatus(t *testing.T) {
+	s := Scanner{client: common.ConstantResponseHttpClient(http.StatusServiceUnavailable, `{"message":"unavailable"}`)}
+
+	data := fmt.Sprintf("algolia %s %s", testAppID, testSearchKey)
+	results, err := s.FromData(context.Background(), true, []byte(data))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.False(t, results[0].Verified)
+		assert.Error(t, results[0].VerificationError())
+	}
+}
@@ -1,10 +1,9 @@
 package anthropic
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -56,8 +55,9 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 			if client == nil {
 				client = defaultClient
 			}
-			isVerified, err := verifyToken(ctx, client, resMatch)
+			isVerified, extraData, err := verifyToken(ctx, client, resMatch)
 			s1.Verified = isVerified
+			s1.ExtraData = extraData
 			s1.SetVerificationError(err, resMatch)
 		}
 
@@ -67,57 +67,37 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	return results, nil
 }
 
-type response struct {
-	Error struct {
-		Message string `json:"message"`
-	} `json:"error"`
-}
-
-func verifyToken(ctx context.Context, client *http.Client, apiKey string) (bool, error) {
-	body := map[string]any{
-		"model":      "claude-3-opus-20240229",
-		"max_tokens": 1024,
-		"messages": []map[string]string{
-			{"role": "user", "content": "Hello, world"},
-		},
-	}
-
-	bodyBytes, err := json.Marshal(body)
-	if err != nil {
-		return false, nil
-	}
-
-	// https://docs.anthropic.com/claude/reference/messages_post
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.anthropic.com/v1/messages", bytes.NewReader(bodyBytes))
+// verifyToken lists the models available to the key, which isn't billed unlike requesting a
+// completion, and returns the organization the key belongs to.
+func verifyToken(ctx context.Context, client *http.Client, apiKey string) (bool, map[string]string, error) {
+	// https://docs.anthropic.com/en/api/models-list
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.anthropic.com/v1/models?limit=1", nil)
 	if err != nil {
-		return false, nil
+		return false, nil, err
 	}
 	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("anthropic-version", "2023-06-01")
 
 	res, err := client.Do(req)
 	if err != nil {
-		return false, err
+		return false, nil, err
 	}
-	defer res.Body.Close()
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
 
 	switch res.StatusCode {
 	case http.StatusOK:
-		return true, nil
-
-	case http.StatusBadRequest:
-		var resp response
-		if err = json.NewDecoder(res.Body).Decode(&resp); err != nil {
-			return false, fmt.Errorf("unexpected HTTP response body: %w", err)
+		var extraData map[string]string
+		if orgID := res.Header.Get("anthropic-organization-id"); orgID != "" {
+			extraData = map[string]string{"organization_id": orgID}
 		}
-		return true, nil
-
+		return true, extraData, nil
 	case http.StatusUnauthorized:
-		return false, nil
-
+		return false, nil, nil
 	default:
-		return false, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+		return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
 	}
 }
 
@@ -0,0 +1,82 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+const testKey = "sk-ant-REDACTED"
+
+func TestAnthropic_Models(t *testing.T) {
+	var requests []string
+	client := &http.Client{Transport: common.FakeTransport{
+		CreateResponse: func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.Method+" "+req.URL.Path)
+			res := &http.Response{
+				Request:    req,
+				StatusCode: http.StatusUnauthorized,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`)),
+			}
+			if req.Header.Get("x-api-key") == testKey {
+				res.StatusCode = http.StatusOK
+				res.Header.Set("anthropic-organization-id", "0f4c1a2b-3d5e-4f60-8a7b-9c0d1e2f3a4b")
+				res.Body = io.NopCloser(strings.NewReader(`{"data":[{"type":"model","id":"claude-3-5-sonnet-20241022"}],"has_more":true}`))
+			}
+			return res, nil
+		},
+	}}
+	s := Scanner{client: client}
+
+	tests := []struct {
+		name          string
+		key           string
+		wantVerified  bool
+		wantExtraData map[string]string
+	}{
+		{
+			name:          "verified",
+			key:           testKey,
+			wantVerified:  true,
+			wantExtraData: map[string]string{"organization_id": "0f4c1a2b-3d5e-4f60-8a7b-9c0d1e2f3a4b"},
+		},
+		{
+			name: "unverified",
+			key:  strings.Replace(testKey, "EMnY", "ZZZZ", 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			results, err := s.FromData(context.Background(), true, []byte("ANTHROPIC_API_KEY="+tt.key))
+			assert.NoError(t, err)
+			if assert.Len(t, results, 1) {
+				assert.Equal(t, tt.key, string(results[0].Raw))
+				assert.Equal(t, tt.wantVerified, results[0].Verified)
+				assert.Equal(t, tt.wantExtraData, results[0].ExtraData)
+				assert.NoError(t, results[0].VerificationError())
+			}
+			// Only the models are listed, no completion is requested.
+			assert.Equal(t, []string{"GET /v1/models"}, requests)
+		})
+	}
+}
+
+func TestAnthropic_UnexpectedStatus(t *testing.T) {
+	s := Scanner{client: common.ConstantResponseHttpClient(http.StatusInternalServerError, "")}
+
+	results, err := s.FromData(context.Background(), true, []byte(testKey))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.False(t, results[0].Verified)
+		assert.Error(t, results[0].VerificationError())
+	}
+}
@@ -27,6 +27,7 @@ func TestAnthropic_FromChunk(t *testing.T) {
 	}
 	secret := testSecrets.MustGetField("ANTHROPIC")
 	inactiveSecret := testSecrets.MustGetField("ANTHROPIC_INACTIVE")
+	organizationID := testSecrets.MustGetField("ANTHROPIC_ORGANIZATION_ID")
 
 	type args struct {
 		ctx    context.Context
@@ -53,6 +54,7 @@ func TestAnthropic_FromChunk(t *testing.T) {
 				{
 					DetectorType: detectorspb.DetectorType_Anthropic,
 					Verified:     true,
+					ExtraData:    map[string]string{"organization_id": organizationID},
 				},
 			},
 			wantErr:             false,
@@ -2,18 +2,28 @@ package azure
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	regexp "github.com/wasilibs/go-re2"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 
-	"github.com/Azure/go-autorest/autorest/azure/auth"
+	regexp "github.com/wasilibs/go-re2"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
+// Scanner detects the client secrets of Azure AD (Microsoft Entra ID) app registrations. A secret
+// is only valid for its application in its tenant, so secrets are paired with the tenant and
+// client IDs of the chunk, and verified by requesting a token with the client credentials flow.
 type Scanner struct {
 	detectors.DefaultMultiPartCredentialProvider
+	client *http.Client
 }
 
 // Ensure the Scanner satisfies the interface at compile time.
@@ -25,58 +35,101 @@ func mustFmtPat(id, pat string) *regexp.Regexp {
 }
 
 var (
+	defaultClient = common.SaneHttpClient()
+
 	// TODO: Azure storage access keys and investigate other types of creds.
 
 	// Azure App Oauth
-	idPatFmt    = `(?i)(%s).{0,20}([a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12})`
+	idPatFmt    = `(?i)(?:%s).{0,20}([a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12})`
 	clientIDPat = mustFmtPat("client_id", idPatFmt)
 	tenantIDPat = mustFmtPat("tenant_id", idPatFmt)
 
 	// TODO: support old patterns
-	secretPatFmt    = `(?i)(%s).{0,20}([a-z0-9_\.\-~]{34})`
+	secretPatFmt    = `(?i)(?:%s).{0,20}([a-z0-9_\.\-~]{34})`
 	clientSecretPat = mustFmtPat("client_secret", secretPatFmt)
+
+	// Secrets created since 2019 are 40 characters long: a 3-character hint, a digit, "Q~" and
+	// 34 characters, so they're found without a label. They can end with a dash or a tilde, so a
+	// word boundary can't end them.
+	appSecretPat = regexp.MustCompile(`\b([\w~.-]{3}\dQ~[\w~.-]{34})(?:[^\w~.-]|$)`)
+
+	guid = `([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})\b`
+	// IDs are also recognized by the names pipelines and tools give them, such as
+	// AZURE_TENANT_ID, ARM_CLIENT_ID, tenantId or appId, and tenants by the login URL.
+	tenantIDNamePat = regexp.MustCompile(`(?i)(?:(?:tenant|directory)(?:[_.-]?id)?['"]?\s{0,3}[:=]{1,2}\s{0,3}['"]?|login\.microsoftonline\.com/)` + guid)
+	clientIDNamePat = regexp.MustCompile(`(?i)(?:client|app(?:lication)?)[_.-]?id['"]?\s{0,3}[:=]{1,2}\s{0,3}['"]?` + guid)
 )
 
+// tokenURL is the token endpoint of the Microsoft identity platform, formatted with the tenant.
+const tokenURL = "https://login.microsoftonline.com/%s/oauth2/v2.0/token"
+
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"azure"}
+	return []string{"azure", "Q~"}
 }
 
-// FromData will find and optionally verify Azure secrets in a given set of bytes.
+// FromData will find and optionally verify Azure secrets in a given set of bytes. Secrets in the
+// current format are reported unverified on their own if the chunk has no tenant or client ID.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
-	clientSecretMatches := clientSecretPat.FindAllStringSubmatch(dataStr, -1)
-	for _, clientSecret := range clientSecretMatches {
-		tenantIDMatches := tenantIDPat.FindAllStringSubmatch(dataStr, -1)
-		for _, tenantID := range tenantIDMatches {
-			clientIDMatches := clientIDPat.FindAllStringSubmatch(dataStr, -1)
-			for _, clientID := range clientIDMatches {
-				s := detectors.Result{
+	appSecrets := detectors.UniqueMatches(appSecretPat, dataStr)
+	secrets := appSecrets
+	for _, secret := range detectors.UniqueMatches(clientSecretPat, dataStr) {
+		// Labeled secrets can be the first characters of a secret in the current format.
+		if !containedIn(secret, appSecrets) {
+			secrets = append(secrets, secret)
+		}
+	}
+	tenantIDs := uniqueMatches(dataStr, tenantIDPat, tenantIDNamePat)
+	clientIDs := uniqueMatches(dataStr, clientIDPat, clientIDNamePat)
+
+	client := s.client
+	if client == nil {
+		client = defaultClient
+	}
+
+	pairs := 0
+	for _, secret := range secrets {
+		if len(tenantIDs) == 0 || len(clientIDs) == 0 {
+			if containedIn(secret, appSecrets) {
+				results = append(results, detectors.Result{
 					DetectorType: detectorspb.DetectorType_Azure,
-					Raw:          []byte(clientSecret[2]),
-					RawV2:        []byte(clientID[2] + clientSecret[2] + tenantID[2]),
-					Redacted:     clientID[2],
-				}
-				// Set the RotationGuideURL in the ExtraData
-				s.ExtraData = map[string]string{
-					"rotation_guide": "https://howtorotate.com/docs/tutorials/azure/",
+					Raw:          []byte(secret),
+					Redacted:     secret[:3] + strings.Repeat("*", len(secret)-3),
+					ExtraData: map[string]string{
+						"rotation_guide": "https://howtorotate.com/docs/tutorials/azure/",
+					},
+				})
+			}
+			continue
+		}
+
+		for _, tenantID := range tenantIDs {
+			for _, clientID := range clientIDs {
+				s1 := detectors.Result{
+					DetectorType: detectorspb.DetectorType_Azure,
+					Raw:          []byte(secret),
+					RawV2:        []byte(clientID + secret + tenantID),
+					Redacted:     clientID,
+					ExtraData: map[string]string{
+						"rotation_guide": "https://howtorotate.com/docs/tutorials/azure/",
+						"tenant_id":      tenantID,
+					},
 				}
 
-				if verify {
-					cred := auth.NewClientCredentialsConfig(clientID[2], clientSecret[2], tenantID[2])
-					token, err := cred.ServicePrincipalToken()
-					if err != nil {
-						continue
-					}
-					err = token.Refresh()
-					if err == nil {
-						s.Verified = true
+				if verify && pairs < detectors.MaxVerifiedPairs {
+					pairs++
+					isVerified, tokenErr, verificationErr := verifySecret(ctx, client, tenantID, clientID, secret)
+					s1.Verified = isVerified
+					if tokenErr != "" {
+						s1.ExtraData["token_error"] = tokenErr
 					}
+					s1.SetVerificationError(verificationErr, secret)
 				}
 
-				results = append(results, s)
+				results = append(results, s1)
 			}
 		}
 	}
@@ -84,6 +137,105 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	return results, nil
 }
 
+// uniqueMatches returns the distinct values matched by any of pats, sorted.
+func uniqueMatches(data string, pats ...*regexp.Regexp) []string {
+	unique := make(map[string]struct{})
+	for _, pat := range pats {
+		for _, value := range detectors.UniqueMatches(pat, data) {
+			unique[strings.ToLower(value)] = struct{}{}
+		}
+	}
+	values := make([]string, 0, len(unique))
+	for value := range unique {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// containedIn reports whether secret is part of any of the given secrets.
+func containedIn(secret string, secrets []string) bool {
+	for _, s := range secrets {
+		if strings.Contains(s, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidCredentialsCodes are the AADSTS error codes of requests whose secret isn't valid for the
+// client, or whose client or tenant don't exist.
+var invalidCredentialsCodes = map[int]struct{}{
+	7000215: {}, // Invalid client secret provided.
+	7000222: {}, // The client secret is expired.
+	700016:  {}, // Application not found in the directory.
+	90002:   {}, // Tenant not found.
+	900023:  {}, // The tenant identifier isn't a valid tenant.
+}
+
+// refusedTokenCodes are the AADSTS error codes of requests that were authenticated, but didn't
+// get a token because of the configuration of the application or tenant.
+var refusedTokenCodes = map[int]struct{}{
+	53003:   {}, // Blocked by a Conditional Access policy.
+	7000112: {}, // The application is disabled.
+	500011:  {}, // The resource principal wasn't found in the tenant.
+}
+
+// tokenErrorResponse is the error returned by the token endpoint.
+type tokenErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	ErrorCodes       []int  `json:"error_codes"`
+}
+
+// verifySecret requests a token for Microsoft Graph with the client credentials. Secrets that are
+// valid but refused a token are verified, and the AADSTS error code is returned along with them.
+func verifySecret(ctx context.Context, client *http.Client, tenantID, clientID, secret string) (bool, string, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {clientID},
+		"client_secret": {secret},
+		"scope":         {"https://graph.microsoft.com/.default"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(tokenURL, tenantID), strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, "", err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, "", nil
+	case http.StatusBadRequest, http.StatusUnauthorized:
+		var tokenErr tokenErrorResponse
+		if err := json.NewDecoder(res.Body).Decode(&tokenErr); err != nil {
+			return false, "", fmt.Errorf("unexpected HTTP response body: %w", err)
+		}
+		for _, code := range tokenErr.ErrorCodes {
+			if _, ok := invalidCredentialsCodes[code]; ok {
+				return false, "", nil
+			}
+		}
+		for _, code := range tokenErr.ErrorCodes {
+			if _, ok := refusedTokenCodes[code]; ok {
+				return true, "AADSTS" + strconv.Itoa(code), nil
+			}
+		}
+		return false, "", fmt.Errorf("unexpected token error %q: %s", tokenErr.Error, tokenErr.ErrorDescription)
+	default:
+		return false, "", fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_Azure
 }
@@ -0,0 +1,274 @@
+package azuresastoken
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds Azure Storage shared access signature (SAS) tokens. Tokens
+// found in a storage URL are verified by reading the resource of the URL, and
+// tokens found on their own by listing the resources of the storage endpoints
+// found in the same data, such as the ones of a connection string.
+type Scanner struct {
+	client *http.Client
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	defaultClient = common.SaneHttpClient()
+
+	// A token is a query string, whose parameters may be in any order.
+	tokenPat = regexp.MustCompile(`\b([a-z]{2,6}=[^&\s"'<>;]+(?:&[a-z]{2,6}=[^&\s"'<>;]+){2,20})`)
+	// endpointPat matches the URLs of storage accounts, with the path of a
+	// resource and a query string if any.
+	endpointPat = regexp.MustCompile(`\bhttps://([a-z0-9]{3,24})\.(blob|file|queue|table)\.core\.windows\.net(/[^\s?"'<>;#]*)?(?:\?([^\s"'<>;#]+))?`)
+)
+
+// sasParams are the query parameters of SAS tokens. Other parameters of the
+// URL the token was found in aren't part of the token.
+var sasParams = map[string]struct{}{
+	"sv": {}, "ss": {}, "srt": {}, "sr": {}, "sp": {}, "st": {}, "se": {}, "sip": {},
+	"spr": {}, "si": {}, "sdd": {}, "skoid": {}, "sktid": {}, "skt": {}, "ske": {},
+	"sks": {}, "skv": {}, "saoid": {}, "suoid": {}, "scid": {}, "ses": {}, "sig": {},
+	"rscc": {}, "rscd": {}, "rsce": {}, "rscl": {}, "rsct": {},
+}
+
+// permissionMismatchCodes are the error codes of requests whose signature
+// was accepted, but which the token doesn't grant access to.
+var permissionMismatchCodes = map[string]struct{}{
+	"AuthorizationPermissionMismatch":   {},
+	"AuthorizationResourceTypeMismatch": {},
+	"AuthorizationServiceMismatch":      {},
+	"AuthorizationSourceIPMismatch":     {},
+}
+
+// token is a SAS token, with the parameters of the signature.
+type token struct {
+	raw    string
+	params url.Values
+}
+
+// parseToken returns the SAS token of a query string, without its other
+// parameters. It returns false if the query string isn't a SAS token.
+func parseToken(query string) (token, bool) {
+	var kept []string
+	for _, param := range strings.Split(query, "&") {
+		key, _, _ := strings.Cut(param, "=")
+		if _, ok := sasParams[key]; ok {
+			kept = append(kept, param)
+		}
+	}
+	raw := strings.Join(kept, "&")
+	params, err := url.ParseQuery(raw)
+	if err != nil || params.Get("sv") == "" || params.Get("se") == "" || len(params.Get("sig")) < 40 {
+		return token{}, false
+	}
+	return token{raw: raw, params: params}, true
+}
+
+// expiry returns the time the token expires. Dates without a time are in UTC.
+func (t token) expiry() (time.Time, error) {
+	se := t.params.Get("se")
+	if expiry, err := time.Parse(time.RFC3339, se); err == nil {
+		return expiry, nil
+	}
+	return time.Parse("2006-01-02", se)
+}
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"sig="}
+}
+
+func (s Scanner) getClient() *http.Client {
+	if s.client != nil {
+		return s.client
+	}
+	return defaultClient
+}
+
+// FromData will find and optionally verify Azure SAS tokens in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	// Tokens found in a URL are verified against it, others against the
+	// endpoints found without a token.
+	tokenEndpoints := make(map[string]*url.URL)
+	var endpoints []*url.URL
+	for _, match := range endpointPat.FindAllStringSubmatch(dataStr, -1) {
+		endpoint, err := url.Parse(strings.TrimSuffix(match[0], "?"+match[4]))
+		if err != nil {
+			continue
+		}
+		if tok, ok := parseToken(match[4]); ok {
+			tokenEndpoints[tok.raw] = endpoint
+		} else if len(endpoints) < maxEndpoints {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	uniqueTokens := make(map[string]token)
+	for _, match := range tokenPat.FindAllStringSubmatch(dataStr, -1) {
+		if tok, ok := parseToken(match[1]); ok {
+			uniqueTokens[tok.raw] = tok
+		}
+	}
+
+	for raw, tok := range uniqueTokens {
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_AzureSasToken,
+			Raw:          []byte(raw),
+			ExtraData: map[string]string{
+				"expiry":      tok.params.Get("se"),
+				"permissions": tok.params.Get("sp"),
+			},
+		}
+		if services := tok.params.Get("ss"); services != "" {
+			s1.ExtraData["services"] = services
+		}
+
+		candidates := endpoints
+		if endpoint, ok := tokenEndpoints[raw]; ok {
+			candidates = []*url.URL{endpoint}
+			s1.RawV2 = []byte(endpoint.String() + "?" + raw)
+		}
+		if len(candidates) == 1 {
+			s1.ExtraData["account_name"] = accountName(candidates[0])
+		}
+
+		expiry, expiryErr := tok.expiry()
+		expired := expiryErr == nil && time.Now().After(expiry)
+		if expired {
+			// Storage rejects expired tokens with a 403, so they aren't
+			// sent to it.
+			s1.ExtraData["expired"] = "true"
+		}
+
+		if verify && !expired {
+			for _, endpoint := range candidates {
+				isVerified, verificationErr := verifyToken(ctx, s.getClient(), endpoint, raw)
+				s1.Verified = isVerified
+				s1.SetVerificationError(verificationErr, raw)
+				if isVerified {
+					s1.ExtraData["account_name"] = accountName(endpoint)
+					break
+				}
+			}
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// maxEndpoints bounds the number of endpoints tokens found on their own are
+// verified against.
+const maxEndpoints = 5
+
+// accountName returns the name of the storage account of an endpoint.
+func accountName(endpoint *url.URL) string {
+	name, _, _ := strings.Cut(endpoint.Host, ".")
+	return name
+}
+
+// readRequest returns a request reading the resource at the endpoint with the
+// token. Services, containers, shares and queues are listed, or their
+// metadata read, and the properties of blobs and files are read, so that no
+// content is downloaded.
+func readRequest(ctx context.Context, endpoint *url.URL, raw string) (*http.Request, error) {
+	service := strings.Split(endpoint.Host, ".")[1]
+	var segments []string
+	for _, segment := range strings.Split(endpoint.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	u := *endpoint
+	method, query := http.MethodGet, ""
+	switch {
+	case len(segments) == 0 && service == "table":
+		u.Path = "/Tables"
+		query = "$top=1"
+	case len(segments) == 0:
+		query = "comp=list&maxresults=1"
+	case len(segments) == 1 && service == "blob":
+		query = "restype=container&comp=list&maxresults=1"
+	case len(segments) == 1 && service == "file":
+		query = "restype=directory&comp=list&maxresults=1"
+	case len(segments) == 1 && service == "queue":
+		query = "comp=metadata"
+	case len(segments) == 1 && service == "table":
+		u.Path = "/" + segments[0] + "()"
+		query = "$top=1"
+	case service == "blob" || service == "file":
+		method = http.MethodHead
+	}
+	if query != "" {
+		query += "&"
+	}
+	u.RawQuery = query + raw
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-version", "2021-08-06")
+	req.Header.Set("Accept", "application/json;odata=nometadata")
+	return req, nil
+}
+
+func verifyToken(ctx context.Context, client *http.Client, endpoint *url.URL, raw string) (bool, error) {
+	req, err := readRequest(ctx, endpoint, raw)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		// If the host is not found, we can assume that the account doesn't exist.
+		if strings.Contains(err.Error(), "no such host") {
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusForbidden:
+		// The signature was accepted if the token doesn't grant access to
+		// the resource, or not from this address. It's rejected otherwise,
+		// such as when the token was revoked or the signature is invalid.
+		_, ok := permissionMismatchCodes[res.Header.Get("x-ms-error-code")]
+		return ok, nil
+	case http.StatusNotFound:
+		// The resource was deleted.
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_AzureSasToken
+}
@@ -0,0 +1,169 @@
+package azuresastoken
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+)
+
+const (
+	testSig    = "sig=G7Wl0JbFbNBZlZVWIm9e8OIJw2yRfMZrSR0eRjq6u0s%3D"
+	testToken  = "sv=2022-11-02&ss=b&srt=sco&sp=rl&se=2099-01-01T00:00:00Z&st=2024-01-01T00:00:00Z&spr=https&" + testSig
+	testBlob   = "sp=r&st=2024-01-01T00:00:00Z&se=2099-01-01T00:00:00Z&spr=https&sv=2022-11-02&sr=b&" + testSig
+	oldToken   = "sv=2020-08-04&ss=b&srt=sco&sp=rwdl&se=2021-01-01&" + testSig
+	testDomain = "acct.blob.core.windows.net"
+)
+
+func TestAzureSasToken_Pattern(t *testing.T) {
+	d := Scanner{}
+	ahoCorasickCore := ahocorasick.NewAhoCorasickCore([]detectors.Detector{d})
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "account token",
+			input: "AZURE_SAS_TOKEN='?" + testToken + "'",
+			want:  []string{testToken},
+		},
+		{
+			name:  "url with other parameters",
+			input: "curl https://" + testDomain + "/container/file.txt?foo=bar&" + testBlob + "&timeout=30",
+			want:  []string{testBlob},
+		},
+		{
+			name:  "connection string",
+			input: "BlobEndpoint=https://" + testDomain + "/;SharedAccessSignature=" + testToken,
+			want:  []string{testToken},
+		},
+		{
+			name:  "no expiry",
+			input: "https://" + testDomain + "/?sv=2022-11-02&ss=b&sp=rl&" + testSig,
+		},
+		{
+			name:  "no signature",
+			input: "https://" + testDomain + "/?sv=2022-11-02&ss=b&sp=rl&se=2099-01-01&sig=",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if len(ahoCorasickCore.FindDetectorMatches([]byte(test.input))) == 0 {
+				t.Errorf("keywords '%v' not matched by: %s", d.Keywords(), test.input)
+				return
+			}
+
+			results, err := d.FromData(context.Background(), false, []byte(test.input))
+			assert.NoError(t, err)
+			var got []string
+			for _, r := range results {
+				got = append(got, string(r.Raw))
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+// rewriteTransport sends every request to a test server.
+type rewriteTransport struct{ server *url.URL }
+
+func (rt rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = rt.server.Scheme, rt.server.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestAzureSasToken_Verify(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.Host+r.URL.Path+"?"+r.URL.RawQuery)
+		switch {
+		case r.URL.Query().Get("sig") == "":
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Query().Get("sp") == "r" && r.URL.Query().Get("comp") == "list":
+			w.Header().Set("x-ms-error-code", "AuthorizationResourceTypeMismatch")
+			w.WriteHeader(http.StatusForbidden)
+		case strings.HasPrefix(r.Host, "revoked."):
+			w.Header().Set("x-ms-error-code", "AuthenticationFailed")
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	s := Scanner{client: &http.Client{Transport: rewriteTransport{server: serverURL}}}
+
+	tests := []struct {
+		name         string
+		input        string
+		wantVerified bool
+		wantRequests []string
+		wantExtra    map[string]string
+	}{
+		{
+			name:         "blob url",
+			input:        "https://" + testDomain + "/container/dir/file.txt?" + testBlob,
+			wantVerified: true,
+			wantRequests: []string{"HEAD " + testDomain + "/container/dir/file.txt?" + testBlob},
+			wantExtra:    map[string]string{"account_name": "acct", "expiry": "2099-01-01T00:00:00Z", "permissions": "r"},
+		},
+		{
+			name:         "token with account endpoint",
+			input:        "BlobEndpoint=https://" + testDomain + "/;SharedAccessSignature=" + testToken,
+			wantVerified: true,
+			wantRequests: []string{"GET " + testDomain + "/?comp=list&maxresults=1&" + testToken},
+			wantExtra:    map[string]string{"account_name": "acct", "expiry": "2099-01-01T00:00:00Z", "permissions": "rl", "services": "b"},
+		},
+		{
+			name:         "signature accepted without permission",
+			input:        "https://acct.blob.core.windows.net/container?" + testBlob,
+			wantVerified: true,
+			wantRequests: []string{"GET " + testDomain + "/container?restype=container&comp=list&maxresults=1&" + testBlob},
+			wantExtra:    map[string]string{"account_name": "acct", "expiry": "2099-01-01T00:00:00Z", "permissions": "r"},
+		},
+		{
+			name:         "revoked",
+			input:        "https://revoked.queue.core.windows.net/jobs?" + testToken,
+			wantRequests: []string{"GET revoked.queue.core.windows.net/jobs?comp=metadata&" + testToken},
+			wantExtra:    map[string]string{"account_name": "revoked", "expiry": "2099-01-01T00:00:00Z", "permissions": "rl", "services": "b"},
+		},
+		{
+			name:      "expired",
+			input:     "https://" + testDomain + "/?" + oldToken,
+			wantExtra: map[string]string{"account_name": "acct", "expiry": "2021-01-01", "permissions": "rwdl", "services": "b", "expired": "true"},
+		},
+		{
+			name:      "token without endpoint",
+			input:     "token: " + testToken,
+			wantExtra: map[string]string{"expiry": "2099-01-01T00:00:00Z", "permissions": "rl", "services": "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			results, err := s.FromData(ctx, true, []byte(tt.input))
+			assert.NoError(t, err)
+			if !assert.Len(t, results, 1) {
+				return
+			}
+			assert.Equal(t, tt.wantVerified, results[0].Verified)
+			assert.NoError(t, results[0].VerificationError())
+			assert.Equal(t, tt.wantExtra, results[0].ExtraData)
+			sort.Strings(requests)
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
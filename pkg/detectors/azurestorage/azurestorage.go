@@ -14,6 +14,7 @@ import (
 
 	regexp "github.com/wasilibs/go-re2"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
@@ -25,10 +26,36 @@ type Scanner struct {
 var _ detectors.Detector = (*Scanner)(nil)
 
 var (
-	defaultClient = http.DefaultClient
-	keyPat        = regexp.MustCompile(`DefaultEndpointsProtocol=https;AccountName=(?P<account_name>[^;]+);AccountKey=(?P<account_key>[^;]+);EndpointSuffix=core\.windows\.net`)
+	defaultClient = common.SaneHttpClient()
+	// Connection strings are semicolon separated settings, in any order. The
+	// account key is the base64 encoding of a 64 byte key.
+	connectionStringPat = regexp.MustCompile(`((?:\b[A-Za-z]+=[^;\s"'<>]+;){0,8}AccountKey=[A-Za-z0-9+/]{86}==(?:;[A-Za-z]+=[^;\s"'<>]+){0,8})`)
+	accountNamePat      = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
 )
 
+const defaultEndpointSuffix = "core.windows.net"
+
+// endpointSuffixes are the endpoint suffixes of the Azure clouds. Keys of
+// other endpoints, such as the ones of storage emulators, aren't verified.
+var endpointSuffixes = map[string]struct{}{
+	defaultEndpointSuffix:    {},
+	"core.chinacloudapi.cn":  {},
+	"core.usgovcloudapi.net": {},
+	"core.cloudapi.de":       {},
+}
+
+// parseConnectionString returns the settings of a connection string.
+func parseConnectionString(connectionString string) map[string]string {
+	settings := make(map[string]string)
+	for _, setting := range strings.Split(connectionString, ";") {
+		key, value, ok := strings.Cut(setting, "=")
+		if ok {
+			settings[strings.ToLower(key)] = strings.TrimSpace(value)
+		}
+	}
+	return settings
+}
+
 type storageResponse struct {
 	Containers struct {
 		Container []container `xml:"Container"`
@@ -40,7 +67,7 @@ type container struct {
 }
 
 func (s Scanner) Keywords() []string {
-	return []string{"DefaultEndpointsProtocol=https;AccountName="}
+	return []string{"AccountKey="}
 }
 
 func (s Scanner) getClient() *http.Client {
@@ -53,14 +80,21 @@ func (s Scanner) getClient() *http.Client {
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
-	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
-
-	for _, match := range matches {
-		if len(match) != 3 {
+	uniqueKeys := make(map[string]struct{})
+	for _, match := range connectionStringPat.FindAllStringSubmatch(dataStr, -1) {
+		settings := parseConnectionString(match[1])
+		accountName, accountKey := settings["accountname"], settings["accountkey"]
+		if !accountNamePat.MatchString(accountName) {
 			continue
 		}
-		accountName := strings.TrimSpace(match[1])
-		accountKey := strings.TrimSpace(match[2])
+		if _, ok := uniqueKeys[accountName+accountKey]; ok {
+			continue
+		}
+		uniqueKeys[accountName+accountKey] = struct{}{}
+		endpointSuffix := settings["endpointsuffix"]
+		if endpointSuffix == "" {
+			endpointSuffix = defaultEndpointSuffix
+		}
 
 		s1 := detectors.Result{
 			DetectorType: detectorspb.DetectorType_AzureStorage,
@@ -70,10 +104,10 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 			},
 		}
 
-		if verify {
+		if _, ok := endpointSuffixes[endpointSuffix]; verify && ok {
 			client := s.getClient()
 
-			isVerified, verificationErr := verifyAzureStorageKey(ctx, client, accountName, accountKey, s1.ExtraData)
+			isVerified, verificationErr := verifyAzureStorageKey(ctx, client, accountName, accountKey, endpointSuffix, s1.ExtraData)
 			s1.Verified = isVerified
 			s1.SetVerificationError(verificationErr, accountKey)
 		}
@@ -84,7 +118,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	return results, nil
 }
 
-func verifyAzureStorageKey(ctx context.Context, client *http.Client, accountName, accountKey string, extraData map[string]string) (bool, error) {
+func verifyAzureStorageKey(ctx context.Context, client *http.Client, accountName, accountKey, endpointSuffix string, extraData map[string]string) (bool, error) {
 	now := time.Now().UTC().Format(http.TimeFormat)
 	stringToSign := "GET\n\n\n\n\n\n\n\n\n\n\n\nx-ms-date:" + now + "\nx-ms-version:2019-12-12\n/" + accountName + "/\ncomp:list"
 	accountKeyBytes, _ := base64.StdEncoding.DecodeString(accountKey)
@@ -92,7 +126,7 @@ func verifyAzureStorageKey(ctx context.Context, client *http.Client, accountName
 	h.Write([]byte(stringToSign))
 	signature := base64.StdEncoding.EncodeToString(h.Sum(nil))
 
-	url := "https://" + accountName + ".blob.core.windows.net/?comp=list"
+	url := "https://" + accountName + ".blob." + endpointSuffix + "/?comp=list"
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return false, err
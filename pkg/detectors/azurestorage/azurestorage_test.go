@@ -19,6 +19,51 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
+func TestAzurestorage_Pattern(t *testing.T) {
+	const key = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8gISIjJCUmJygpKissLS4vMDEyMzQ1Njc4OTo7PD0+Pw=="
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]string
+	}{
+		{
+			name:  "typical connection string",
+			input: "DefaultEndpointsProtocol=https;AccountName=teststorage;AccountKey=" + key + ";EndpointSuffix=core.windows.net",
+			want:  map[string]string{key: "teststorage"},
+		},
+		{
+			name:  "settings in another order",
+			input: `"AccountKey=` + key + `;AccountName=otherstorage;DefaultEndpointsProtocol=http"`,
+			want:  map[string]string{key: "otherstorage"},
+		},
+		{
+			name:  "invalid account name",
+			input: "AccountName=Not_Valid;AccountKey=" + key,
+			want:  map[string]string{},
+		},
+		{
+			name:  "short key",
+			input: "AccountName=teststorage;AccountKey=Zm9vYmFy==",
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Scanner{}.FromData(context.Background(), false, []byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]string, len(results))
+			for _, r := range results {
+				got[string(r.Raw)] = r.ExtraData["account_name"]
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Azurestorage.FromData() %s diff: (-want +got)\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
 func TestAzurestorage_FromChunk(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
@@ -9,6 +9,7 @@ import (
 	"net/http/cookiejar"
 	"strings"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 	"golang.org/x/net/publicsuffix"
@@ -43,7 +44,8 @@ func (s Scanner) getClient(cookieJar *cookiejar.Jar) *http.Client {
 	}
 	// Using custom HTTP client instead of common.SaneHttpClient() here because, for unknown reasons, browserstack blocks those requests even with cookie jar attached
 	return &http.Client{
-		Jar: cookieJar,
+		Jar:       cookieJar,
+		Transport: common.ConfiguredTransport(nil),
 	}
 }
 
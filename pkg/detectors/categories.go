@@ -0,0 +1,254 @@
+package detectors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Category groups detectors by the kind of service their secrets are for, so that scans can be
+// limited to, or exclude, a kind of service.
+type Category string
+
+const (
+	CategoryAI              Category = "ai"
+	CategoryCI              Category = "ci"
+	CategoryCloud           Category = "cloud"
+	CategoryDatabase        Category = "database"
+	CategoryGeneric         Category = "generic"
+	CategoryIdentity        Category = "identity"
+	CategoryMessaging       Category = "messaging"
+	CategoryMonitoring      Category = "monitoring"
+	CategoryPackageRegistry Category = "package-registry"
+	CategoryPayment         Category = "payment"
+	CategorySourceControl   Category = "source-control"
+)
+
+// Categorizer is an optional interface that a detector can implement to
+// declare its categories itself, instead of them being looked up by its type.
+type Categorizer interface {
+	Categories() []Category
+}
+
+// detectorCategories are the categories of the detectors by type. Detectors of types that
+// aren't listed have no category.
+var detectorCategories = map[detectorspb.DetectorType][]Category{
+	// Cloud providers.
+	detectorspb.DetectorType_AWS:                                   {CategoryCloud},
+	detectorspb.DetectorType_AWSSessionKey:                         {CategoryCloud},
+	detectorspb.DetectorType_Alibaba:                               {CategoryCloud},
+	detectorspb.DetectorType_Azure:                                 {CategoryCloud, CategoryIdentity},
+	detectorspb.DetectorType_AzureActiveDirectoryApplicationSecret: {CategoryCloud, CategoryIdentity},
+	detectorspb.DetectorType_AzureBatch:                            {CategoryCloud},
+	detectorspb.DetectorType_AzureContainerRegistry:                {CategoryCloud, CategoryPackageRegistry},
+	detectorspb.DetectorType_AzureFunctionKey:                      {CategoryCloud},
+	detectorspb.DetectorType_AzureManagementCertificate:            {CategoryCloud},
+	detectorspb.DetectorType_AzureSasToken:                         {CategoryCloud},
+	detectorspb.DetectorType_AzureSearchAdminKey:                   {CategoryCloud},
+	detectorspb.DetectorType_AzureSearchQueryKey:                   {CategoryCloud},
+	detectorspb.DetectorType_AzureStorage:                          {CategoryCloud},
+	detectorspb.DetectorType_CloudflareApiToken:                    {CategoryCloud},
+	detectorspb.DetectorType_CloudflareCaKey:                       {CategoryCloud},
+	detectorspb.DetectorType_CloudflareGlobalApiKey:                {CategoryCloud},
+	detectorspb.DetectorType_DigitalOceanToken:                     {CategoryCloud},
+	detectorspb.DetectorType_DigitalOceanV2:                        {CategoryCloud},
+	detectorspb.DetectorType_FastlyPersonalToken:                   {CategoryCloud},
+	detectorspb.DetectorType_FlyIO:                                 {CategoryCloud},
+	detectorspb.DetectorType_GCP:                                   {CategoryCloud},
+	detectorspb.DetectorType_GCPApplicationDefaultCredentials:      {CategoryCloud},
+	detectorspb.DetectorType_GoogleApiKey:                          {CategoryCloud},
+	detectorspb.DetectorType_Heroku:                                {CategoryCloud},
+	detectorspb.DetectorType_IbmCloudUserKey:                       {CategoryCloud},
+	detectorspb.DetectorType_Linode:                                {CategoryCloud},
+	detectorspb.DetectorType_Netlify:                               {CategoryCloud},
+	detectorspb.DetectorType_ScalewayKey:                           {CategoryCloud},
+	detectorspb.DetectorType_TencentCloudKey:                       {CategoryCloud},
+	detectorspb.DetectorType_Vercel:                                {CategoryCloud},
+	detectorspb.DetectorType_VultrApiKey:                           {CategoryCloud},
+
+	// Payment processors.
+	detectorspb.DetectorType_BraintreePayments: {CategoryPayment},
+	detectorspb.DetectorType_Checkout:          {CategoryPayment},
+	detectorspb.DetectorType_Coinbase:          {CategoryPayment},
+	detectorspb.DetectorType_Dwolla:            {CategoryPayment},
+	detectorspb.DetectorType_Flutterwave:       {CategoryPayment},
+	detectorspb.DetectorType_MollieAPIKey:      {CategoryPayment},
+	detectorspb.DetectorType_MollieAccessToken: {CategoryPayment},
+	detectorspb.DetectorType_Pagarme:           {CategoryPayment},
+	detectorspb.DetectorType_Paymongo:          {CategoryPayment},
+	detectorspb.DetectorType_PaypalOauth:       {CategoryPayment},
+	detectorspb.DetectorType_Paystack:          {CategoryPayment},
+	detectorspb.DetectorType_PlaidKey:          {CategoryPayment},
+	detectorspb.DetectorType_PlaidToken:        {CategoryPayment},
+	detectorspb.DetectorType_RazorPay:          {CategoryPayment},
+	detectorspb.DetectorType_Square:            {CategoryPayment},
+	detectorspb.DetectorType_SquareApp:         {CategoryPayment},
+	detectorspb.DetectorType_Stripe:            {CategoryPayment},
+
+	// Source control.
+	detectorspb.DetectorType_AzureDevopsPersonalAccessToken: {CategorySourceControl, CategoryCI},
+	detectorspb.DetectorType_GitHubApp:                      {CategorySourceControl},
+	detectorspb.DetectorType_Github:                         {CategorySourceControl},
+	detectorspb.DetectorType_Gitlab:                         {CategorySourceControl, CategoryCI},
+
+	// CI/CD.
+	detectorspb.DetectorType_Buildkite:                   {CategoryCI},
+	detectorspb.DetectorType_CircleCI:                    {CategoryCI},
+	detectorspb.DetectorType_DroneCI:                     {CategoryCI},
+	detectorspb.DetectorType_Pulumi:                      {CategoryCI, CategoryCloud},
+	detectorspb.DetectorType_TerraformCloudPersonalToken: {CategoryCI, CategoryCloud},
+	detectorspb.DetectorType_TravisCI:                    {CategoryCI},
+
+	// Package registries.
+	detectorspb.DetectorType_ArtifactoryAccessToken: {CategoryPackageRegistry},
+	detectorspb.DetectorType_Dockerhub:              {CategoryPackageRegistry},
+	detectorspb.DetectorType_NpmToken:               {CategoryPackageRegistry},
+	detectorspb.DetectorType_NuGetApiKey:            {CategoryPackageRegistry},
+	detectorspb.DetectorType_PyPIUploadToken:        {CategoryPackageRegistry},
+	detectorspb.DetectorType_RubyGems:               {CategoryPackageRegistry},
+
+	// Messaging and email.
+	detectorspb.DetectorType_DiscordBotToken:         {CategoryMessaging},
+	detectorspb.DetectorType_DiscordWebhook:          {CategoryMessaging},
+	detectorspb.DetectorType_Mailchimp:               {CategoryMessaging},
+	detectorspb.DetectorType_Mailgun:                 {CategoryMessaging},
+	detectorspb.DetectorType_Mandrill:                {CategoryMessaging},
+	detectorspb.DetectorType_MattermostPersonalToken: {CategoryMessaging},
+	detectorspb.DetectorType_MicrosoftTeamsWebhook:   {CategoryMessaging},
+	detectorspb.DetectorType_NexmoApiKey:             {CategoryMessaging},
+	detectorspb.DetectorType_Postmark:                {CategoryMessaging},
+	detectorspb.DetectorType_SendGrid:                {CategoryMessaging},
+	detectorspb.DetectorType_SendinBlueV2:            {CategoryMessaging},
+	detectorspb.DetectorType_Slack:                   {CategoryMessaging},
+	detectorspb.DetectorType_SlackWebhook:            {CategoryMessaging},
+	detectorspb.DetectorType_Sparkpost:               {CategoryMessaging},
+	detectorspb.DetectorType_TelegramBotToken:        {CategoryMessaging},
+	detectorspb.DetectorType_Twilio:                  {CategoryMessaging},
+	detectorspb.DetectorType_Vonage:                  {CategoryMessaging},
+	detectorspb.DetectorType_FirebaseCloudMessaging:  {CategoryMessaging},
+
+	// Databases.
+	detectorspb.DetectorType_AzureCacheForRedisAccessKey:  {CategoryDatabase, CategoryCloud},
+	detectorspb.DetectorType_AzureCosmosDBKeyIdentifiable: {CategoryDatabase, CategoryCloud},
+	detectorspb.DetectorType_AzureSQL:                     {CategoryDatabase, CategoryCloud},
+	detectorspb.DetectorType_Couchbase:                    {CategoryDatabase},
+	detectorspb.DetectorType_JDBC:                         {CategoryDatabase},
+	detectorspb.DetectorType_MongoDB:                      {CategoryDatabase},
+	detectorspb.DetectorType_MySQL:                        {CategoryDatabase},
+	detectorspb.DetectorType_PlanetScale:                  {CategoryDatabase},
+	detectorspb.DetectorType_PlanetScaleDb:                {CategoryDatabase},
+	detectorspb.DetectorType_Postgres:                     {CategoryDatabase},
+	detectorspb.DetectorType_Redis:                        {CategoryDatabase},
+	detectorspb.DetectorType_SQLServer:                    {CategoryDatabase},
+	detectorspb.DetectorType_Snowflake:                    {CategoryDatabase},
+	detectorspb.DetectorType_SupabaseToken:                {CategoryDatabase},
+
+	// AI providers.
+	detectorspb.DetectorType_Anthropic:   {CategoryAI},
+	detectorspb.DetectorType_Deepgram:    {CategoryAI},
+	detectorspb.DetectorType_Groq:        {CategoryAI},
+	detectorspb.DetectorType_HuggingFace: {CategoryAI},
+	detectorspb.DetectorType_OpenAI:      {CategoryAI},
+	detectorspb.DetectorType_Replicate:   {CategoryAI},
+
+	// Monitoring and observability.
+	detectorspb.DetectorType_DatadogToken:               {CategoryMonitoring},
+	detectorspb.DetectorType_Dynatrace:                  {CategoryMonitoring},
+	detectorspb.DetectorType_Grafana:                    {CategoryMonitoring},
+	detectorspb.DetectorType_GrafanaServiceAccount:      {CategoryMonitoring},
+	detectorspb.DetectorType_Honeycomb:                  {CategoryMonitoring},
+	detectorspb.DetectorType_Loggly:                     {CategoryMonitoring},
+	detectorspb.DetectorType_LogzIO:                     {CategoryMonitoring},
+	detectorspb.DetectorType_NewRelicPersonalApiKey:     {CategoryMonitoring},
+	detectorspb.DetectorType_Opsgenie:                   {CategoryMonitoring},
+	detectorspb.DetectorType_PagerDutyApiKey:            {CategoryMonitoring},
+	detectorspb.DetectorType_SentryToken:                {CategoryMonitoring},
+	detectorspb.DetectorType_SplunkOberservabilityToken: {CategoryMonitoring},
+
+	// Identity providers.
+	detectorspb.DetectorType_Auth0ManagementApiToken: {CategoryIdentity},
+	detectorspb.DetectorType_Auth0oauth:              {CategoryIdentity},
+	detectorspb.DetectorType_HashiCorpVaultToken:     {CategoryIdentity},
+	detectorspb.DetectorType_Okta:                    {CategoryIdentity},
+	detectorspb.DetectorType_OneLogin:                {CategoryIdentity},
+
+	// Secrets that aren't specific to a service.
+	detectorspb.DetectorType_Entropy:    {CategoryGeneric},
+	detectorspb.DetectorType_JWT:        {CategoryGeneric},
+	detectorspb.DetectorType_PrivateKey: {CategoryGeneric},
+	detectorspb.DetectorType_URI:        {CategoryGeneric},
+}
+
+// CategoriesOf returns the categories of a detector.
+func CategoriesOf(d Detector) []Category {
+	if c, ok := d.(Categorizer); ok {
+		return c.Categories()
+	}
+	return detectorCategories[d.Type()]
+}
+
+// AllCategories returns the known categories, sorted by name.
+func AllCategories() []Category {
+	seen := make(map[Category]struct{})
+	for _, categories := range detectorCategories {
+		for _, c := range categories {
+			seen[c] = struct{}{}
+		}
+	}
+	all := make([]Category, 0, len(seen))
+	for c := range seen {
+		all = append(all, c)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	return all
+}
+
+// ParseCategory returns the category with the given name, or an error if it doesn't exist.
+func ParseCategory(name string) (Category, error) {
+	c := Category(strings.ToLower(strings.TrimSpace(name)))
+	for _, known := range AllCategories() {
+		if c == known {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown detector category %q, expected one of %s", name, joinCategories(AllCategories()))
+}
+
+// ParseCategories parses a comma separated list of categories.
+func ParseCategories(input string) ([]Category, error) {
+	var categories []Category
+	for _, name := range strings.Split(input, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		c, err := ParseCategory(name)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+// HasCategory reports whether the detector is in any of the given categories.
+func HasCategory(d Detector, categories []Category) bool {
+	for _, c := range CategoriesOf(d) {
+		for _, want := range categories {
+			if c == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// joinCategories joins the names of categories with commas.
+func joinCategories(categories []Category) string {
+	names := make([]string, len(categories))
+	for i, c := range categories {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
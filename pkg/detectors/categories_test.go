@@ -0,0 +1,62 @@
+package detectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type categoryTestDetector struct {
+	detectorType detectorspb.DetectorType
+	categories   []Category
+}
+
+func (d categoryTestDetector) FromData(context.Context, bool, []byte) ([]Result, error) {
+	return nil, nil
+}
+func (d categoryTestDetector) Keywords() []string             { return nil }
+func (d categoryTestDetector) Type() detectorspb.DetectorType { return d.detectorType }
+
+type categorizingTestDetector struct{ categoryTestDetector }
+
+func (d categorizingTestDetector) Categories() []Category { return d.categories }
+
+func TestCategoriesOf(t *testing.T) {
+	aws := categoryTestDetector{detectorType: detectorspb.DetectorType_AWS}
+	assert.Equal(t, []Category{CategoryCloud}, CategoriesOf(aws))
+	assert.True(t, HasCategory(aws, []Category{CategoryPayment, CategoryCloud}))
+	assert.False(t, HasCategory(aws, []Category{CategoryPayment}))
+
+	// Detectors that declare their categories aren't looked up by type.
+	custom := categorizingTestDetector{categoryTestDetector{
+		detectorType: detectorspb.DetectorType_AWS,
+		categories:   []Category{CategoryDatabase},
+	}}
+	assert.Equal(t, []Category{CategoryDatabase}, CategoriesOf(custom))
+	assert.False(t, HasCategory(custom, []Category{CategoryCloud}))
+
+	assert.Empty(t, CategoriesOf(categoryTestDetector{detectorType: detectorspb.DetectorType_CustomRegex}))
+}
+
+func TestParseCategories(t *testing.T) {
+	categories, err := ParseCategories(" Cloud, payment,,")
+	assert.NoError(t, err)
+	assert.Equal(t, []Category{CategoryCloud, CategoryPayment}, categories)
+
+	categories, err = ParseCategories("")
+	assert.NoError(t, err)
+	assert.Empty(t, categories)
+
+	_, err = ParseCategories("cloud,weather")
+	assert.ErrorContains(t, err, `unknown detector category "weather"`)
+}
+
+func TestAllCategories(t *testing.T) {
+	all := AllCategories()
+	assert.IsIncreasing(t, all)
+	assert.Contains(t, all, CategoryCloud)
+	assert.Contains(t, all, CategorySourceControl)
+}
@@ -2,27 +2,35 @@ package cloudflareapitoken
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	regexp "github.com/wasilibs/go-re2"
+	"io"
 	"net/http"
+	"sort"
 	"strings"
 
+	regexp "github.com/wasilibs/go-re2"
+
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	client *http.Client
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
 var (
-	client = common.SaneHttpClient()
+	defaultClient = common.SaneHttpClient()
 
 	keyPat = regexp.MustCompile(detectors.PrefixRegex([]string{"cloudflare"}) + `\b([A-Za-z0-9_-]{40})\b`)
 )
 
+const baseURL = "https://api.cloudflare.com/client/v4"
+
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
@@ -33,33 +41,27 @@ func (s Scanner) Keywords() []string {
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
-	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
-
-	for _, match := range matches {
-		if len(match) != 2 {
-			continue
-		}
-		resMatch := strings.TrimSpace(match[1])
+	uniqueMatches := make(map[string]struct{})
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		uniqueMatches[strings.TrimSpace(match[1])] = struct{}{}
+	}
 
+	for match := range uniqueMatches {
 		s1 := detectors.Result{
 			DetectorType: detectorspb.DetectorType_CloudflareApiToken,
-			Raw:          []byte(resMatch),
+			Raw:          []byte(match),
 		}
 
 		if verify {
-			req, err := http.NewRequestWithContext(ctx, "GET", "https://api.cloudflare.com/client/v4/user/tokens/verify", nil)
-			if err != nil {
-				continue
-			}
-			req.Header.Add("Content-Type", "application/json")
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", resMatch))
-			res, err := client.Do(req)
-			if err == nil {
-				defer res.Body.Close()
-				if res.StatusCode >= 200 && res.StatusCode < 300 {
-					s1.Verified = true
-				}
+			client := s.client
+			if client == nil {
+				client = defaultClient
 			}
+
+			isVerified, extraData, verificationErr := verifyMatch(ctx, client, match)
+			s1.Verified = isVerified
+			s1.ExtraData = extraData
+			s1.SetVerificationError(verificationErr, match)
 		}
 
 		results = append(results, s1)
@@ -68,6 +70,118 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	return results, nil
 }
 
+type verifyResponse struct {
+	Result struct {
+		ID        string `json:"id"`
+		Status    string `json:"status"`
+		ExpiresOn string `json:"expires_on"`
+	} `json:"result"`
+}
+
+type tokenResponse struct {
+	Result struct {
+		Policies []struct {
+			PermissionGroups []struct {
+				Name string `json:"name"`
+			} `json:"permission_groups"`
+		} `json:"policies"`
+	} `json:"result"`
+}
+
+func verifyMatch(ctx context.Context, client *http.Client, token string) (bool, map[string]string, error) {
+	// https://developers.cloudflare.com/api/operations/user-api-tokens-verify-token
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/user/tokens/verify", nil)
+	if err != nil {
+		return false, nil, nil
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		var verifyRes verifyResponse
+		if err := json.NewDecoder(res.Body).Decode(&verifyRes); err != nil {
+			return false, nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		// Disabled and expired tokens are still recognized by the endpoint.
+		if verifyRes.Result.Status != "active" {
+			return false, nil, nil
+		}
+
+		extraData := map[string]string{"token_id": verifyRes.Result.ID}
+		if verifyRes.Result.ExpiresOn != "" {
+			extraData["expires_on"] = verifyRes.Result.ExpiresOn
+		}
+		if permissions := tokenPermissions(ctx, client, token, verifyRes.Result.ID); len(permissions) > 0 {
+			extraData["permissions"] = strings.Join(permissions, ", ")
+		}
+		return true, extraData, nil
+	case http.StatusUnauthorized:
+		// The secret is determinately not verified (nothing to do)
+		return false, nil, nil
+	default:
+		return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
+// tokenPermissions returns the names of the permission groups granted to the token.
+// Reading token details requires the token to have the "API Tokens Read" permission,
+// so a failure here does not affect the verification result.
+func tokenPermissions(ctx context.Context, client *http.Client, token, id string) []string {
+	if id == "" {
+		return nil
+	}
+
+	// https://developers.cloudflare.com/api/operations/user-api-tokens-token-details
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/user/tokens/"+id, nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	var tokenRes tokenResponse
+	if err := json.NewDecoder(res.Body).Decode(&tokenRes); err != nil {
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	var permissions []string
+	for _, policy := range tokenRes.Result.Policies {
+		for _, group := range policy.PermissionGroups {
+			if _, ok := seen[group.Name]; ok || group.Name == "" {
+				continue
+			}
+			seen[group.Name] = struct{}{}
+			permissions = append(permissions, group.Name)
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_CloudflareApiToken
 }
@@ -9,13 +9,79 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/kylelemons/godebug/pretty"
-	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
+func TestCloudflareApiToken_Pattern(t *testing.T) {
+	d := Scanner{}
+	ahoCorasickCore := ahocorasick.NewAhoCorasickCore([]detectors.Detector{d})
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "typical pattern",
+			input: "CLOUDFLARE_API_TOKEN=Tq3rZcN0w_8vXh2Lk-5yBd7Fj9Sg1Ua4Pe6Mo0Wi",
+			want:  []string{"Tq3rZcN0w_8vXh2Lk-5yBd7Fj9Sg1Ua4Pe6Mo0Wi"},
+		},
+		{
+			name:  "duplicate tokens",
+			input: "cloudflare token: Tq3rZcN0w_8vXh2Lk-5yBd7Fj9Sg1Ua4Pe6Mo0Wi\ncloudflare token: Tq3rZcN0w_8vXh2Lk-5yBd7Fj9Sg1Ua4Pe6Mo0Wi",
+			want:  []string{"Tq3rZcN0w_8vXh2Lk-5yBd7Fj9Sg1Ua4Pe6Mo0Wi"},
+		},
+		{
+			name:  "invalid pattern",
+			input: "cloudflare token: Tq3rZcN0w_8vXh2Lk",
+			want:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchedDetectors := ahoCorasickCore.FindDetectorMatches([]byte(test.input))
+			if len(matchedDetectors) == 0 {
+				t.Errorf("keywords '%v' not matched by: %s", d.Keywords(), test.input)
+				return
+			}
+
+			results, err := d.FromData(context.Background(), false, []byte(test.input))
+			if err != nil {
+				t.Errorf("error = %v", err)
+				return
+			}
+
+			if len(results) != len(test.want) {
+				if len(results) == 0 {
+					t.Errorf("did not receive result")
+				} else {
+					t.Errorf("expected %d results, only received %d", len(test.want), len(results))
+				}
+				return
+			}
+
+			actual := make(map[string]struct{}, len(results))
+			for _, r := range results {
+				actual[string(r.Raw)] = struct{}{}
+			}
+			expected := make(map[string]struct{}, len(test.want))
+			for _, v := range test.want {
+				expected[v] = struct{}{}
+			}
+
+			if diff := cmp.Diff(expected, actual); diff != "" {
+				t.Errorf("%s diff: (-want +got)\n%s", test.name, diff)
+			}
+		})
+	}
+}
+
 func TestCloudflareApiToken_FromChunk(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
@@ -112,6 +178,11 @@ func TestCloudflareApiToken_FromChunk(t *testing.T) {
 					t.Fatalf("no raw secret present: \n %+v", got[i])
 				}
 				got[i].Raw = nil
+				// Permissions depend on the token, so only check they are reported for verified tokens.
+				if got[i].Verified && got[i].ExtraData["token_id"] == "" {
+					t.Fatalf("no token id present for verified token: \n %+v", got[i])
+				}
+				got[i].ExtraData = nil
 			}
 			if diff := pretty.Compare(got, tt.want); diff != "" {
 				t.Errorf("CloudflareApiToken.FromData() %s diff: (-got +want)\n%s", tt.name, diff)
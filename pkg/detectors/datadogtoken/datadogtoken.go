@@ -3,7 +3,10 @@ package datadogtoken
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	regexp "github.com/wasilibs/go-re2"
@@ -16,6 +19,7 @@ import (
 type Scanner struct {
 	detectors.EndpointSetter
 	detectors.DefaultMultiPartCredentialProvider
+	client *http.Client
 }
 
 // Ensure the Scanner satisfies the interface at compile time.
@@ -25,13 +29,24 @@ var _ detectors.EndpointCustomizer = (*Scanner)(nil)
 func (Scanner) DefaultEndpoint() string { return "https://api.datadoghq.com" }
 
 var (
-	client = common.SaneHttpClient()
+	defaultClient = common.SaneHttpClient()
 
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
 	appPat = regexp.MustCompile(detectors.PrefixRegex([]string{"datadog", "dd"}) + `\b([a-zA-Z-0-9]{40})\b`)
 	apiPat = regexp.MustCompile(detectors.PrefixRegex([]string{"datadog", "dd"}) + `\b([a-zA-Z-0-9]{32})\b`)
 )
 
+// site is a Datadog site, whose organizations are only known to its API endpoint.
+type site struct {
+	name, endpoint string
+}
+
+// defaultSites are tried in order when verifying keys, unless endpoints are configured.
+var defaultSites = []site{
+	{name: "us1", endpoint: "https://api.datadoghq.com"},
+	{name: "eu1", endpoint: "https://api.datadoghq.eu"},
+}
+
 type userServiceResponse struct {
 	Data     []*user    `json:"data"`
 	Included []*options `json:"included"`
@@ -101,98 +116,153 @@ func (s Scanner) Keywords() []string {
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
-	appMatches := appPat.FindAllStringSubmatch(dataStr, -1)
-	apiMatches := apiPat.FindAllStringSubmatch(dataStr, -1)
+	apiKeys := detectors.UniqueMatches(apiPat, dataStr)
+	appKeys := detectors.UniqueMatches(appPat, dataStr)
 
-	for _, apiMatch := range apiMatches {
-		if len(apiMatch) != 2 {
-			continue
-		}
-		resApiMatch := strings.TrimSpace(apiMatch[1])
-		appIncluded := false
-		for _, appMatch := range appMatches {
-			if len(appMatch) != 2 {
-				continue
-			}
-			resAppMatch := strings.TrimSpace(appMatch[1])
+	client := s.client
+	if client == nil {
+		client = defaultClient
+	}
+	sites := s.sites()
 
+	pairs := 0
+	for _, apiKey := range apiKeys {
+		for _, appKey := range appKeys {
 			s1 := detectors.Result{
 				DetectorType: detectorspb.DetectorType_DatadogToken,
-				Raw:          []byte(resAppMatch),
-				RawV2:        []byte(resAppMatch + resApiMatch),
+				Raw:          []byte(appKey),
+				RawV2:        []byte(appKey + apiKey),
 				ExtraData: map[string]string{
 					"Type": "Application+APIKey",
 				},
 			}
 
-			if verify {
-				for _, baseURL := range s.Endpoints(s.DefaultEndpoint()) {
-					req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/api/v2/users", nil)
-					if err != nil {
-						continue
+			if verify && pairs < detectors.MaxVerifiedPairs {
+				pairs++
+				verifiedSite, users, verificationErr := verifyKeys(ctx, client, sites, apiKey, appKey)
+				if verifiedSite != nil {
+					s1.Verified = true
+					s1.ExtraData["site"] = verifiedSite.name
+					if users != nil && len(users.Data) > 0 {
+						setUserEmails(users.Data, &s1)
 					}
-					req.Header.Add("Content-Type", "application/json")
-					req.Header.Add("DD-API-KEY", resApiMatch)
-					req.Header.Add("DD-APPLICATION-KEY", resAppMatch)
-					res, err := client.Do(req)
-					if err == nil {
-						defer res.Body.Close()
-						if res.StatusCode >= 200 && res.StatusCode < 300 {
-							s1.Verified = true
-							var serviceResponse userServiceResponse
-							if err := json.NewDecoder(res.Body).Decode(&serviceResponse); err == nil {
-								// setup emails
-								if len(serviceResponse.Data) > 0 {
-									setUserEmails(serviceResponse.Data, &s1)
-								}
-								// setup organizations
-								if len(serviceResponse.Included) > 0 {
-									setOrganizationInfo(serviceResponse.Included, &s1)
-								}
-							}
-						}
+					if users != nil && len(users.Included) > 0 {
+						setOrganizationInfo(users.Included, &s1)
 					}
 				}
+				s1.SetVerificationError(verificationErr, apiKey, appKey)
 			}
-			appIncluded = true
 			results = append(results, s1)
 		}
 
-		if !appIncluded {
-			s1 := detectors.Result{
-				DetectorType: detectorspb.DetectorType_DatadogToken,
-				Raw:          []byte(resApiMatch),
-				RawV2:        []byte(resApiMatch),
-				ExtraData: map[string]string{
-					"Type": "APIKeyOnly",
-				},
-			}
+		if len(appKeys) > 0 {
+			continue
+		}
 
-			if verify {
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_DatadogToken,
+			Raw:          []byte(apiKey),
+			RawV2:        []byte(apiKey),
+			ExtraData: map[string]string{
+				"Type": "APIKeyOnly",
+			},
+		}
 
-				for _, baseURL := range s.Endpoints(s.DefaultEndpoint()) {
-					req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/api/v1/validate", nil)
-					if err != nil {
-						continue
-					}
-					req.Header.Add("Content-Type", "application/json")
-					req.Header.Add("DD-API-KEY", resApiMatch)
-					res, err := client.Do(req)
-					if err == nil {
-						defer res.Body.Close()
-						if res.StatusCode >= 200 && res.StatusCode < 300 {
-							s1.Verified = true
-						}
-					}
-				}
+		if verify {
+			verifiedSite, _, verificationErr := verifyKeys(ctx, client, sites, apiKey, "")
+			if verifiedSite != nil {
+				s1.Verified = true
+				s1.ExtraData["site"] = verifiedSite.name
 			}
-			results = append(results, s1)
+			s1.SetVerificationError(verificationErr, apiKey)
 		}
+		results = append(results, s1)
 	}
 
 	return results, nil
 }
 
+// sites returns the sites keys are verified against: the configured endpoints, named after their
+// host, or the default sites.
+func (s Scanner) sites() []site {
+	endpoints := s.Endpoints("")
+	if len(endpoints) == 1 && endpoints[0] == "" {
+		return defaultSites
+	}
+	sites := make([]site, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		name := endpoint
+		if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+			name = u.Host
+		}
+		sites = append(sites, site{name: name, endpoint: endpoint})
+	}
+	return sites
+}
+
+// verifyKeys validates the keys against each site until one of them accepts them, as each site
+// only knows its own organizations, and returns that site, along with the users of the
+// organization if an application key was validated and they could be read. The keys are
+// unverified if every site rejects them.
+func verifyKeys(ctx context.Context, client *http.Client, sites []site, apiKey, appKey string) (*site, *userServiceResponse, error) {
+	for i := range sites {
+		ok, users, err := validate(ctx, client, sites[i].endpoint, apiKey, appKey)
+		if err != nil {
+			// The keys may be valid on this site, so they can't be known to be invalid.
+			return nil, nil, fmt.Errorf("site %s: %w", sites[i].name, err)
+		}
+		if ok {
+			return &sites[i], users, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// validate reports whether the site accepts the keys. An API key alone is validated with the
+// validation endpoint, which ignores application keys, so a pair is validated by listing the
+// users of the organization, which requires both keys.
+func validate(ctx context.Context, client *http.Client, endpoint, apiKey, appKey string) (bool, *userServiceResponse, error) {
+	path := "/api/v1/validate"
+	if appKey != "" {
+		path = "/api/v2/users"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+path, nil)
+	if err != nil {
+		return false, nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("DD-API-KEY", apiKey)
+	if appKey != "" {
+		req.Header.Add("DD-APPLICATION-KEY", appKey)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		if appKey == "" {
+			return true, nil, nil
+		}
+		// The users are only reported if they can be decoded.
+		var users userServiceResponse
+		if err := json.NewDecoder(res.Body).Decode(&users); err != nil {
+			return true, nil, nil
+		}
+		return true, &users, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, nil, nil
+	default:
+		return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_DatadogToken
 }
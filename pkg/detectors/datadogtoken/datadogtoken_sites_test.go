@@ -0,0 +1,132 @@
+package datadogtoken
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+const (
+	testAPIKey   = "0123456789abcdef0123456789abcdef"
+	testAppKey   = "0123456789abcdef0123456789abcdef01234567"
+	testEUAPIKey = "fedcba9876543210fedcba9876543210"
+	testEUAppKey = "fedcba9876543210fedcba9876543210fedcba98"
+)
+
+func TestDatadogToken_Sites(t *testing.T) {
+	var requests []string
+	client := &http.Client{Transport: common.FakeTransport{
+		CreateResponse: func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.URL.Host+req.URL.Path)
+			apiKey, appKey := req.Header.Get("DD-API-KEY"), req.Header.Get("DD-APPLICATION-KEY")
+			status, body := http.StatusForbidden, `{"errors": ["Forbidden"]}`
+			switch {
+			case req.URL.Path == "/api/v1/validate" && req.URL.Host == "api.datadoghq.com" && apiKey == testAPIKey,
+				req.URL.Path == "/api/v1/validate" && req.URL.Host == "api.datadoghq.eu" && apiKey == testEUAPIKey:
+				// Application keys are ignored by the validation endpoint.
+				status, body = http.StatusOK, `{"valid": true}`
+			case req.URL.Path == "/api/v2/users" && req.URL.Host == "api.datadoghq.eu" && apiKey == testEUAPIKey && appKey == testEUAppKey:
+				status, body = http.StatusOK, `{"data": [{"attributes": {"email": "ops@example.eu", "verified": true}}], "included": [{"type": "orgs", "attributes": {"name": "Example EU", "url": "/account/settings"}}]}`
+			}
+			return &http.Response{Request: req, StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}}
+	s := Scanner{client: client}
+
+	tests := []struct {
+		name          string
+		data          string
+		wantVerified  bool
+		wantExtraData map[string]string
+		wantRequests  []string
+	}{
+		{
+			name:          "US API key",
+			data:          fmt.Sprintf("datadog api key %s", testAPIKey),
+			wantVerified:  true,
+			wantExtraData: map[string]string{"Type": "APIKeyOnly", "site": "us1"},
+			wantRequests:  []string{"api.datadoghq.com/api/v1/validate"},
+		},
+		{
+			name:         "EU key pair",
+			data:         fmt.Sprintf("datadog api key %s\ndatadog app key %s", testEUAPIKey, testEUAppKey),
+			wantVerified: true,
+			wantExtraData: map[string]string{
+				"Type":        "Application+APIKey",
+				"site":        "eu1",
+				"user_emails": "ops@example.eu",
+				"org_name":    "Example EU",
+				"org_url":     "/account/settings",
+			},
+			wantRequests: []string{"api.datadoghq.com/api/v2/users", "api.datadoghq.eu/api/v2/users"},
+		},
+		{
+			name:          "valid API key with a wrong application key",
+			data:          fmt.Sprintf("datadog api key %s\ndatadog app key %s", testAPIKey, testEUAppKey),
+			wantVerified:  false,
+			wantExtraData: map[string]string{"Type": "Application+APIKey"},
+			wantRequests:  []string{"api.datadoghq.com/api/v2/users", "api.datadoghq.eu/api/v2/users"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			results, err := s.FromData(context.Background(), true, []byte(tt.data))
+			assert.NoError(t, err)
+			if assert.Len(t, results, 1) {
+				assert.Equal(t, tt.wantVerified, results[0].Verified)
+				assert.Equal(t, tt.wantExtraData, results[0].ExtraData)
+				assert.NoError(t, results[0].VerificationError())
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestDatadogToken_MaxPairs(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 6; i++ {
+		fmt.Fprintf(&sb, "datadog api key %032d\n", i)
+		fmt.Fprintf(&sb, "datadog app key %040d\n", i)
+	}
+	var requests int
+	client := &http.Client{Transport: common.FakeTransport{
+		CreateResponse: func(req *http.Request) (*http.Response, error) {
+			requests++
+			return &http.Response{Request: req, StatusCode: http.StatusForbidden, Body: io.NopCloser(strings.NewReader(""))}, nil
+		},
+	}}
+
+	results, err := Scanner{client: client}.FromData(context.Background(), true, []byte(sb.String()))
+	assert.NoError(t, err)
+
+	// Every pair is reported, but only the first ones are verified, on each default site.
+	appKeys := make(map[string]struct{})
+	for _, result := range results {
+		assert.Equal(t, "Application+APIKey", result.ExtraData["Type"])
+		appKeys[string(result.Raw)] = struct{}{}
+	}
+	assert.Len(t, results, 36)
+	assert.Len(t, appKeys, 6)
+	assert.Equal(t, detectors.MaxVerifiedPairs*len(defaultSites), requests)
+}
+
+func TestDatadogToken_UnexpectedStatus(t *testing.T) {
+	s := Scanner{client: common.ConstantResponseHttpClient(http.StatusInternalServerError, "")}
+
+	results, err := s.FromData(context.Background(), true, []byte(fmt.Sprintf("datadog api key %s", testAPIKey)))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.False(t, results[0].Verified)
+		assert.Error(t, results[0].VerificationError())
+	}
+}
@@ -53,6 +53,7 @@ func TestDatadogToken_FromChunk(t *testing.T) {
 					Verified:     true,
 					ExtraData: map[string]string{
 						"Type": "Application+APIKey",
+						"site": "us1",
 					},
 				},
 			},
@@ -91,6 +92,7 @@ func TestDatadogToken_FromChunk(t *testing.T) {
 					Verified:     true,
 					ExtraData: map[string]string{
 						"Type": "APIKeyOnly",
+						"site": "us1",
 					},
 				},
 			},
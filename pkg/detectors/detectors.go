@@ -3,6 +3,8 @@ package detectors
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"math/big"
 	"net/url"
@@ -60,6 +62,13 @@ type EndpointCustomizer interface {
 	DefaultEndpoint() string
 }
 
+// DatabaseVerifier is an optional interface that a detector can implement to
+// verify credentials by connecting to the database they grant access to. As
+// this makes outbound database connections, it's disabled until enabled.
+type DatabaseVerifier interface {
+	SetDatabaseVerification(enabled bool)
+}
+
 type Result struct {
 	// DetectorType is the type of Detector.
 	DetectorType detectorspb.DetectorType
@@ -96,6 +105,19 @@ func (r *Result) VerificationError() error {
 	return r.verificationError
 }
 
+// Fingerprint identifies the secret of a result across scans without exposing it. It's
+// the SHA-256 of the detector type and RawV2, or Raw if the result has no RawV2, as hex.
+// Redacted isn't used, as it's only the ID of the secret for many detectors, such as the
+// key ID of AWS credentials or the host of a database.
+func (r *Result) Fingerprint() string {
+	secret := string(r.RawV2)
+	if secret == "" {
+		secret = string(r.Raw)
+	}
+	h := sha256.Sum256([]byte(r.DetectorType.String() + ":" + secret))
+	return hex.EncodeToString(h[:])
+}
+
 // redactSecrets replaces all instances of the given secrets with [REDACTED] in the error message.
 func redactSecrets(err error, secrets ...string) error {
 	lastErr := unwrapToLast(err)
@@ -132,6 +154,20 @@ type ResultWithMetadata struct {
 	Result
 	// Data from the sources.Chunk which this result was emitted for
 	Data []byte
+	// Locations lists every occurrence of the result, including the one described by the
+	// fields above. It is only set when the engine deduplicates results across chunks.
+	Locations []ResultLocation
+	// Tracker is the tracker of the chunk the result was found in. The engine releases
+	// a reference to it once the result has been notified.
+	Tracker *sources.ChunkTracker
+}
+
+// ResultLocation describes where an occurrence of a result was found.
+type ResultLocation struct {
+	SourceMetadata *source_metadatapb.MetaData
+	SourceID       sources.SourceID
+	SourceType     sourcespb.SourceType
+	SourceName     string
 }
 
 // CopyMetadata returns a detector result with included metadata from the source chunk.
@@ -0,0 +1,190 @@
+package entropy
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Charset is an alphabet of the strings the detector looks for.
+type Charset string
+
+const (
+	CharsetHex    Charset = "hex"
+	CharsetBase64 Charset = "base64"
+)
+
+const (
+	// The entropy of a string is at most 4 bits per character for hex strings, and 6 bits for
+	// base64 strings, so each alphabet has its own threshold. Random base64 strings of 32
+	// characters have about 4.6 bits per character, and English identifiers of that length
+	// about 4.
+	DefaultHexThreshold    = 3.0
+	DefaultBase64Threshold = 4.3
+	DefaultMinLength       = 20
+)
+
+// shortStringMargin is how far below its maximum the threshold of short strings is set. A
+// string of n characters has at most log2(n) bits per character, so a threshold above that
+// could never be exceeded: random base64 strings of 20 characters have about 4 bits per
+// character, for a maximum of 4.32.
+const shortStringMargin = 0.5
+
+// ParseCharset parses the name of a charset.
+func ParseCharset(name string) (Charset, error) {
+	switch charset := Charset(strings.ToLower(strings.TrimSpace(name))); charset {
+	case CharsetHex, CharsetBase64:
+		return charset, nil
+	default:
+		return "", fmt.Errorf("unknown entropy charset %q, expected %q or %q", name, CharsetHex, CharsetBase64)
+	}
+}
+
+// Scanner is a catch-all detector for credentials without a known format. It reports long runs
+// of hex or base64 characters whose Shannon entropy exceeds the threshold of their alphabet.
+// Its results are low-confidence, as many random-looking strings, such as hashes, aren't
+// credentials, and they can't be verified. It isn't one of the default detectors.
+type Scanner struct {
+	hexThreshold, base64Threshold float64
+	minLength                     int
+	charsets                      map[Charset]struct{}
+	pat                           *regexp.Regexp
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+// New creates an entropy Scanner with the given options, looking for strings of both alphabets
+// with the default thresholds and minimum length otherwise.
+func New(opts ...func(*Scanner)) *Scanner {
+	s := &Scanner{
+		hexThreshold:    DefaultHexThreshold,
+		base64Threshold: DefaultBase64Threshold,
+		minLength:       DefaultMinLength,
+		charsets:        map[Charset]struct{}{CharsetHex: {}, CharsetBase64: {}},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	// Hex strings are matched as base64 strings, which is a superset of their alphabet, and told
+	// apart afterwards. Standard and URL-safe base64 strings are both matched, with their padding.
+	s.pat = regexp.MustCompile(`[A-Za-z0-9+/_-]{` + strconv.Itoa(s.minLength) + `,}={0,2}`)
+	return s
+}
+
+// WithHexThreshold sets the minimum entropy, in bits per character, of hex strings.
+func WithHexThreshold(threshold float64) func(*Scanner) {
+	return func(s *Scanner) { s.hexThreshold = threshold }
+}
+
+// WithBase64Threshold sets the minimum entropy, in bits per character, of base64 strings.
+func WithBase64Threshold(threshold float64) func(*Scanner) {
+	return func(s *Scanner) { s.base64Threshold = threshold }
+}
+
+// WithMinLength sets the minimum length of the strings. Non-positive lengths are ignored.
+func WithMinLength(length int) func(*Scanner) {
+	return func(s *Scanner) {
+		if length > 0 {
+			s.minLength = length
+		}
+	}
+}
+
+// WithCharsets limits the strings to those of the given alphabets. An empty list is ignored.
+func WithCharsets(charsets ...Charset) func(*Scanner) {
+	return func(s *Scanner) {
+		if len(charsets) == 0 {
+			return
+		}
+		s.charsets = make(map[Charset]struct{}, len(charsets))
+		for _, charset := range charsets {
+			s.charsets[charset] = struct{}{}
+		}
+	}
+}
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Credentials without a known format have no identifier, so the strings are looked for next to
+// the names credentials are usually given.
+func (s *Scanner) Keywords() []string {
+	return []string{"key", "secret", "token", "passw", "pwd", "credential", "auth"}
+}
+
+// FromData will find high-entropy strings in a given set of bytes. They're never verified.
+func (s *Scanner) FromData(_ context.Context, _ bool, data []byte) (results []detectors.Result, err error) {
+	seen := make(map[string]struct{})
+	for _, match := range s.pat.FindAllString(string(data), -1) {
+		if _, ok := seen[match]; ok {
+			continue
+		}
+		seen[match] = struct{}{}
+
+		charset, entropy, ok := s.classify(match)
+		if !ok {
+			continue
+		}
+
+		results = append(results, detectors.Result{
+			DetectorType: detectorspb.DetectorType_Entropy,
+			Raw:          []byte(match),
+			Redacted:     redact(match),
+			ExtraData: map[string]string{
+				"charset":    string(charset),
+				"entropy":    strconv.FormatFloat(entropy, 'f', 2, 64),
+				"confidence": "low",
+			},
+		})
+	}
+
+	return results, nil
+}
+
+// classify returns the alphabet and the entropy of a string, and reports whether the string
+// is of one of the alphabets looked for, mixes letters and digits, and its entropy exceeds the
+// alphabet's threshold, lowered for strings too short to reach it.
+func (s *Scanner) classify(match string) (Charset, float64, bool) {
+	charset, threshold := CharsetBase64, s.base64Threshold
+	if isHex(match) {
+		charset, threshold = CharsetHex, s.hexThreshold
+	}
+	if _, ok := s.charsets[charset]; !ok {
+		return charset, 0, false
+	}
+	match = strings.TrimRight(match, "=")
+	if !strings.ContainsAny(match, "0123456789") || strings.Trim(match, "0123456789+/_-") == "" {
+		return charset, 0, false
+	}
+	threshold = min(threshold, math.Log2(float64(len(match)))-shortStringMargin)
+	entropy := detectors.StringShannonEntropy(match)
+	return charset, entropy, entropy > threshold
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// redact keeps the first and last four characters of a string, unless it's too short for any
+// of it to be kept.
+func redact(s string) string {
+	if len(s) <= 16 {
+		return strings.Repeat("*", 8)
+	}
+	return s[:4] + strings.Repeat("*", 8) + s[len(s)-4:]
+}
+
+func (s *Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_Entropy
+}
@@ -0,0 +1,153 @@
+package entropy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+)
+
+const (
+	// testHex has 16 distinct characters, for an entropy of 4 bits per character.
+	testHex = "0123456789abcdef0123456789abcdef"
+	// testBase64 has 32 distinct characters, for an entropy of 5 bits per character.
+	testBase64 = "aB3dE5gH7jK9mN1pQ2rS4tU6vW8xY0zZ"
+)
+
+func TestEntropy_FromData(t *testing.T) {
+	tests := []struct {
+		name        string
+		s           *Scanner
+		data        string
+		wantRaw     []string
+		wantCharset string
+	}{
+		{
+			name:        "hex",
+			s:           New(),
+			data:        "secret = " + testHex,
+			wantRaw:     []string{testHex},
+			wantCharset: "hex",
+		},
+		{
+			name:        "base64 with padding",
+			s:           New(),
+			data:        "token: " + testBase64 + "==",
+			wantRaw:     []string{testBase64 + "=="},
+			wantCharset: "base64",
+		},
+		{
+			name:        "short base64 token",
+			s:           New(),
+			data:        "api_key: Xk9vQ2mT7pL4wR8nZ3bY",
+			wantRaw:     []string{"Xk9vQ2mT7pL4wR8nZ3bY"},
+			wantCharset: "base64",
+		},
+		{
+			name:        "base64 token",
+			s:           New(),
+			data:        `"client_secret": "q8Zt3Vw-1xKb7YpLm2Rc9sHd4NfJ6uEa0Go5"`,
+			wantRaw:     []string{"q8Zt3Vw-1xKb7YpLm2Rc9sHd4NfJ6uEa0Go5"},
+			wantCharset: "base64",
+		},
+		{
+			name:        "short hex token",
+			s:           New(),
+			data:        "token=9f86d081884c7d659a2f",
+			wantRaw:     []string{"9f86d081884c7d659a2f"},
+			wantCharset: "hex",
+		},
+		{
+			name: "identifier",
+			s:    New(),
+			data: "authHandler = AbstractSingletonProxyFactoryBean",
+		},
+		{
+			name: "identifier with digits",
+			s:    New(),
+			data: "key: com/example/project2/service/impl",
+		},
+		{
+			name: "digits",
+			s:    New(),
+			data: "account_key = 81234567890123456789",
+		},
+		{
+			name: "low entropy",
+			s:    New(),
+			data: "value = aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaab",
+		},
+		{
+			name: "too short",
+			s:    New(WithMinLength(40)),
+			data: "secret = " + testHex,
+		},
+		{
+			name: "hex above its threshold",
+			s:    New(WithHexThreshold(4)),
+			data: "secret = " + testHex,
+		},
+		{
+			name: "base64 not looked for",
+			s:    New(WithCharsets(CharsetHex)),
+			data: "token: " + testBase64,
+		},
+		{
+			name:        "hex looked for",
+			s:           New(WithCharsets(CharsetHex)),
+			data:        "token: " + testBase64 + "\nsecret = " + testHex,
+			wantRaw:     []string{testHex},
+			wantCharset: "hex",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.s.FromData(context.Background(), true, []byte(tt.data))
+			assert.NoError(t, err)
+
+			var raw []string
+			for _, result := range results {
+				raw = append(raw, string(result.Raw))
+				assert.False(t, result.Verified)
+				assert.Equal(t, tt.wantCharset, result.ExtraData["charset"])
+				assert.Equal(t, "low", result.ExtraData["confidence"])
+				assert.NotEmpty(t, result.ExtraData["entropy"])
+				assert.NotContains(t, result.Redacted, string(result.Raw))
+			}
+			assert.Equal(t, tt.wantRaw, raw)
+		})
+	}
+}
+
+func TestEntropy_Keywords(t *testing.T) {
+	s := New()
+	ahoCorasickCore := ahocorasick.NewAhoCorasickCore([]detectors.Detector{s})
+
+	// Strings are only looked for next to the names of credentials.
+	assert.NotEmpty(t, ahoCorasickCore.FindDetectorMatches([]byte("DB_PASSWORD="+testBase64)))
+	assert.NotEmpty(t, ahoCorasickCore.FindDetectorMatches([]byte(`{"authToken": "`+testBase64+`"}`)))
+	assert.Empty(t, ahoCorasickCore.FindDetectorMatches([]byte("sha256: "+testHex)))
+	assert.Empty(t, ahoCorasickCore.FindDetectorMatches([]byte("url = https://example.com/"+testBase64)))
+}
+
+func TestEntropy_ExtraData(t *testing.T) {
+	results, err := New().FromData(context.Background(), false, []byte("secret = "+testHex))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "4.00", results[0].ExtraData["entropy"])
+		assert.Equal(t, "0123********cdef", results[0].Redacted)
+	}
+}
+
+func TestParseCharset(t *testing.T) {
+	charset, err := ParseCharset(" Base64 ")
+	assert.NoError(t, err)
+	assert.Equal(t, CharsetBase64, charset)
+
+	_, err = ParseCharset("base32")
+	assert.Error(t, err)
+}
@@ -3,18 +3,28 @@ package gcp
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
 	"strings"
 
 	regexp "github.com/wasilibs/go-re2"
 
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	client *http.Client
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
@@ -23,9 +33,18 @@ var _ detectors.MaxSecretSizeProvider = (*Scanner)(nil)
 var _ detectors.StartOffsetProvider = (*Scanner)(nil)
 
 var (
-	keyPat = regexp.MustCompile(`\{[^{]+auth_provider_x509_cert_url[^}]+\}`)
+	defaultClient = common.SaneHttpClient()
+
+	// Service account keys are flat JSON objects, so the innermost braces
+	// around one of their fields delimit the key, even when it is embedded in
+	// a larger document or escaped in a string.
+	keyPat = regexp.MustCompile(`\{[^{}]*(?:auth_provider_x509_cert_url|service_account)[^{}]*\}`)
 )
 
+// maxUnescapeDepth is the number of times a key that is escaped in a string
+// is unescaped, to find keys in documents that are themselves embedded.
+const maxUnescapeDepth = 3
+
 type gcpKey struct {
 	Type                    string `json:"type"`
 	ProjectID               string `json:"project_id"`
@@ -48,10 +67,11 @@ func trimCarrots(s string) string {
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"provider_x509"}
+	return []string{"provider_x509", "service_account"}
 }
 
-const maxGCPKeySize = 2048
+// Escaped keys are considerably larger than the ~2KB of a key file.
+const maxGCPKeySize = 4096
 
 // MaxSecretSize returns the maximum size of a secret that this detector can find.
 func (Scanner) MaxSecretSize() int64 { return maxGCPKeySize }
@@ -68,15 +88,8 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	matches := keyPat.FindAllString(dataStr, -1)
 
 	for _, match := range matches {
-		key := match
-
-		key = strings.ReplaceAll(key, `,\\n`, `\n`)
-		key = strings.ReplaceAll(key, `\"\\n`, `\n`)
-		key = strings.ReplaceAll(key, `\\"`, `"`)
-
-		creds := gcpKey{}
-		err := json.Unmarshal([]byte(key), &creds)
-		if err != nil {
+		creds, ok := parseKey(match)
+		if !ok {
 			continue
 		}
 
@@ -92,7 +105,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		// Not sure why this might happen, but we've observed this with a verified cred
 		raw := []byte(creds.ClientEmail)
 		if len(raw) == 0 {
-			raw = []byte(key)
+			raw = []byte(match)
 		}
 		// This is an unprivileged service account used in Kubernetes' tests. It is intentionally public.
 		// https://github.com/kubernetes/kubernetes/blob/10a06602223eab17e02e197d1da591727c756d32/test/e2e_node/runtime_conformance_test.go#L50
@@ -102,37 +115,103 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 
 		credBytes, _ := json.Marshal(creds)
 
-		s := detectors.Result{
+		r := detectors.Result{
 			DetectorType: detectorspb.DetectorType_GCP,
 			Raw:          raw,
 			RawV2:        credBytes,
 			Redacted:     creds.ClientEmail,
 		}
-		// Set the RotationGuideURL in the ExtraData
-		s.ExtraData = map[string]string{
+		// Set the RotationGuideURL in the ExtraData. The private key is never
+		// included in the output.
+		r.ExtraData = map[string]string{
 			"rotation_guide": "https://howtorotate.com/docs/tutorials/gcp/",
 			"project":        creds.ProjectID,
+			"private_key_id": creds.PrivateKeyID,
 		}
 
 		if verify {
-			credentials, err := google.CredentialsFromJSON(ctx, credBytes, "https://www.googleapis.com/auth/cloud-platform")
-			if err != nil {
-				continue
-			}
-			if credentials != nil {
-				_, err = credentials.TokenSource.Token()
-				if err == nil {
-					s.Verified = true
-				}
+			client := s.client
+			if client == nil {
+				client = defaultClient
 			}
+			verified, verificationErr := verifyKey(ctx, client, creds)
+			r.Verified = verified
+			r.SetVerificationError(verificationErr, creds.PrivateKey)
 		}
 
-		results = append(results, s)
+		results = append(results, r)
 	}
 
 	return
 }
 
+// parseKey parses a service account key from a match. Keys that are escaped
+// in a string, possibly several times, are unescaped. Matches without a
+// private key, such as OAuth client configurations, are not keys.
+func parseKey(match string) (gcpKey, bool) {
+	// Keys mangled by chat clients have partially escaped newlines and quotes.
+	mangled := strings.ReplaceAll(match, `,\\n`, `\n`)
+	mangled = strings.ReplaceAll(mangled, `\"\\n`, `\n`)
+	mangled = strings.ReplaceAll(mangled, `\\"`, `"`)
+	candidates := []string{match, mangled}
+
+	escaped := match
+	for i := 0; i < maxUnescapeDepth && strings.Contains(escaped, `\"`); i++ {
+		if err := json.Unmarshal([]byte(`"`+escaped+`"`), &escaped); err != nil {
+			break
+		}
+		candidates = append(candidates, escaped)
+	}
+
+	for _, candidate := range candidates {
+		var creds gcpKey
+		if err := json.Unmarshal([]byte(candidate), &creds); err == nil && creds.PrivateKey != "" {
+			return creds, true
+		}
+	}
+	return gcpKey{}, false
+}
+
+// verifyKey exchanges a JWT signed with the private key of the service account
+// for an access token. The token endpoint of the key is ignored, so assertions
+// signed with a leaked key are only ever sent to Google.
+func verifyKey(ctx context.Context, client *http.Client, creds gcpKey) (bool, error) {
+	// Keys that can't sign a JWT can't be valid.
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return false, nil
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		if _, err := x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return false, nil
+		}
+	}
+
+	config := &jwt.Config{
+		Email:        creds.ClientEmail,
+		PrivateKey:   []byte(creds.PrivateKey),
+		PrivateKeyID: creds.PrivateKeyID,
+		Scopes:       []string{"https://www.googleapis.com/auth/cloud-platform"},
+		TokenURL:     google.JWTTokenURL,
+	}
+	_, err := config.TokenSource(context.WithValue(ctx, oauth2.HTTPClient, client)).Token()
+	if err == nil {
+		return true, nil
+	}
+
+	// Deleted and disabled keys and accounts are rejected with invalid_grant.
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		switch retrieveErr.Response.StatusCode {
+		case http.StatusBadRequest, http.StatusUnauthorized:
+			return false, nil
+		default:
+			return false, fmt.Errorf("unexpected HTTP response status %d", retrieveErr.Response.StatusCode)
+		}
+	}
+	return false, err
+}
+
 func (s Scanner) IsFalsePositive(_ detectors.Result) bool {
 	return false
 }
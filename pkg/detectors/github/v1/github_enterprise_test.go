@@ -0,0 +1,42 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyGithub_Enterprise(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/user" || r.Header.Get("Authorization") != "token abc123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-OAuth-Scopes", "repo, read:org")
+		_, _ = w.Write([]byte(`{"login":"octocat","ldap_dn":"uid=octocat"}`))
+	}))
+	defer server.Close()
+
+	t.Setenv(enterpriseAPIURLEnv, server.URL+"/api/v3/")
+	s := Scanner{}
+	assert.Equal(t, server.URL+"/api/v3", s.DefaultEndpoint())
+
+	verified, user, headers, err := s.VerifyGithub(context.Background(), server.Client(), "abc123")
+	assert.NoError(t, err)
+	assert.True(t, verified)
+	assert.Equal(t, "octocat", user.Login)
+	assert.Equal(t, "uid=octocat", user.LdapDN)
+	assert.Equal(t, "repo, read:org", headers.Scopes)
+
+	verified, _, _, err = s.VerifyGithub(context.Background(), server.Client(), "wrong")
+	assert.NoError(t, err)
+	assert.False(t, verified)
+}
+
+func TestDefaultEndpoint_Public(t *testing.T) {
+	t.Setenv(enterpriseAPIURLEnv, "")
+	assert.Equal(t, "https://api.github.com", Scanner{}.DefaultEndpoint())
+}
@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	regexp "github.com/wasilibs/go-re2"
 
@@ -20,8 +24,19 @@ var _ detectors.Detector = (*Scanner)(nil)
 var _ detectors.Versioner = (*Scanner)(nil)
 var _ detectors.EndpointCustomizer = (*Scanner)(nil)
 
-func (Scanner) Version() int            { return 1 }
-func (Scanner) DefaultEndpoint() string { return "https://api.github.com" }
+func (Scanner) Version() int { return 1 }
+
+// enterpriseAPIURLEnv names the environment variable holding the REST API root
+// of a GitHub Enterprise Server instance, e.g. https://ghe.example.com/api/v3,
+// to verify tokens against instead of github.com. It is used as-is.
+const enterpriseAPIURLEnv = "GITHUB_ENTERPRISE_API_URL"
+
+func (Scanner) DefaultEndpoint() string {
+	if apiURL := os.Getenv(enterpriseAPIURLEnv); apiURL != "" {
+		return strings.TrimRight(apiURL, "/")
+	}
+	return "https://api.github.com"
+}
 
 var (
 	// Oauth token
@@ -111,10 +126,10 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 func (s Scanner) VerifyGithub(ctx context.Context, client *http.Client, token string) (bool, *UserRes, *HeaderInfo, error) {
 	// https://developer.github.com/v3/users/#get-the-authenticated-user
 	var requestErr error
-	for _, url := range s.Endpoints(s.DefaultEndpoint()) {
+	for _, endpoint := range s.Endpoints(s.DefaultEndpoint()) {
 		requestErr = nil
 
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/user", url), nil)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/user", strings.TrimRight(endpoint, "/")), nil)
 		if err != nil {
 			continue
 		}
@@ -142,6 +157,71 @@ func (s Scanner) VerifyGithub(ctx context.Context, client *http.Client, token st
 	return false, nil, nil, requestErr
 }
 
+// RepositoryAccess describes the repositories a token can access. Fine-grained personal access
+// tokens are granted access to the repositories of a resource owner rather than scopes.
+type RepositoryAccess struct {
+	// Owners are the distinct owners of the repositories, sorted.
+	Owners []string
+	// Repositories is the number of repositories listed.
+	Repositories int
+	// Truncated is set if the token can access more repositories than were listed.
+	Truncated bool
+}
+
+type repoRes struct {
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+// ListRepositoryAccess lists the first page of the repositories the token can access.
+func (s Scanner) ListRepositoryAccess(ctx context.Context, client *http.Client, token string) (*RepositoryAccess, error) {
+	// https://docs.github.com/en/rest/repos/repos#list-repositories-for-the-authenticated-user
+	var requestErr error
+	for _, endpoint := range s.Endpoints(s.DefaultEndpoint()) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/user/repos?per_page=100", strings.TrimRight(endpoint, "/")), nil)
+		if err != nil {
+			continue
+		}
+
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+		res, err := client.Do(req)
+		if err != nil {
+			requestErr = err
+			continue
+		}
+
+		var repos []repoRes
+		if res.StatusCode >= 200 && res.StatusCode < 300 {
+			err = json.NewDecoder(res.Body).Decode(&repos)
+		} else {
+			err = fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+		}
+		res.Body.Close()
+		if err != nil {
+			requestErr = err
+			continue
+		}
+
+		owners := make(map[string]struct{})
+		for _, repo := range repos {
+			owners[repo.Owner.Login] = struct{}{}
+		}
+		access := &RepositoryAccess{
+			Owners:       make([]string, 0, len(owners)),
+			Repositories: len(repos),
+			Truncated:    strings.Contains(res.Header.Get("Link"), `rel="next"`),
+		}
+		for owner := range owners {
+			access.Owners = append(access.Owners, owner)
+		}
+		sort.Strings(access.Owners)
+		return access, nil
+	}
+	return nil, requestErr
+}
+
 func SetUserResponse(userResponse *UserRes, s1 *detectors.Result) {
 	s1.ExtraData["username"] = userResponse.Login
 	s1.ExtraData["url"] = userResponse.UserURL
@@ -178,6 +258,17 @@ func SetHeaderInfo(headers *HeaderInfo, s1 *detectors.Result) {
 	}
 }
 
+func SetRepositoryAccess(access *RepositoryAccess, s1 *detectors.Result) {
+	if len(access.Owners) > 0 {
+		s1.ExtraData["resource_owners"] = strings.Join(access.Owners, ",")
+	}
+	repositories := strconv.Itoa(access.Repositories)
+	if access.Truncated {
+		repositories += "+"
+	}
+	s1.ExtraData["repositories"] = repositories
+}
+
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_Github
 }
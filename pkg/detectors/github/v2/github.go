@@ -3,6 +3,8 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 
 	regexp "github.com/wasilibs/go-re2"
 
@@ -15,6 +17,7 @@ import (
 
 type Scanner struct {
 	v1.Scanner
+	client *http.Client
 }
 
 // Ensure the Scanner satisfies the interfaces at compile time.
@@ -25,15 +28,20 @@ var _ detectors.EndpointCustomizer = (*v1.Scanner)(nil)
 func (s Scanner) Version() int {
 	return 2
 }
-func (Scanner) DefaultEndpoint() string { return "https://api.github.com" }
+func (s Scanner) DefaultEndpoint() string { return s.Scanner.DefaultEndpoint() }
 
 var (
+	defaultClient = common.SaneHttpClient()
+
 	// Oauth token
 	// https://developer.github.com/v3/#oauth2-token-sent-in-a-header
 	// Token type list:
 	// https://github.blog/2021-04-05-behind-githubs-new-authentication-token-formats/
 	// https://github.blog/changelog/2022-10-18-introducing-fine-grained-personal-access-tokens/
-	keyPat = regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr|github_pat)_[a-zA-Z0-9_]{36,255})\b`)
+	keyPat = regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[a-zA-Z0-9_]{36,255})\b`)
+	// Fine-grained personal access tokens have a 22 character ID and a 59 character secret,
+	// separated by an underscore, for 93 characters in all.
+	fineGrainedPat = regexp.MustCompile(`\b(github_pat_[a-zA-Z0-9]{22}_[a-zA-Z0-9]{59})\b`)
 
 	// TODO: Oauth2 client_id and client_secret
 	// https://developer.github.com/v3/#oauth2-keysecret
@@ -50,6 +58,12 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	dataStr := string(data)
 
 	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
+	matches = append(matches, fineGrainedPat.FindAllStringSubmatch(dataStr, -1)...)
+
+	client := s.client
+	if client == nil {
+		client = defaultClient
+	}
 
 	for _, match := range matches {
 		// First match is entire regex, second is the first group.
@@ -69,8 +83,6 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		}
 
 		if verify {
-			client := common.SaneHttpClient()
-
 			isVerified, userResponse, headers, err := s.VerifyGithub(ctx, client, token)
 			s1.Verified = isVerified
 			s1.SetVerificationError(err, token)
@@ -81,6 +93,12 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 			if headers != nil {
 				v1.SetHeaderInfo(headers, &s1)
 			}
+			// Fine-grained tokens have no scopes, but access to the repositories of their resource owner.
+			if isVerified && strings.HasPrefix(token, "github_pat_") {
+				if access, err := s.ListRepositoryAccess(ctx, client, token); err == nil {
+					v1.SetRepositoryAccess(access, &s1)
+				}
+			}
 		}
 
 		results = append(results, s1)
@@ -0,0 +1,265 @@
+package googleapikey
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner detects Google API keys, which Firebase and Google Maps apps embed in their bundles.
+// A key isn't tied to a single API, so it's verified by probing a few services that accept
+// keys without any other credential.
+type Scanner struct {
+	client *http.Client
+	// skipProbes disables verification, which calls the probed services.
+	skipProbes bool
+	// geocoding enables the Maps Geocoding probe, whose requests are billed.
+	geocoding bool
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	defaultClient = common.SaneHttpClient()
+
+	// Keys are 39 characters long. A dash can end them, so a word boundary can't.
+	keyPat = regexp.MustCompile(`\b(AIza[\w-]{35})(?:[^\w-]|$)`)
+)
+
+// SetServiceProbes enables or disables the requests to Google services that verify keys.
+// Probes are enabled by default.
+func (s *Scanner) SetServiceProbes(enabled bool) {
+	s.skipProbes = !enabled
+}
+
+// SetGeocodingProbe enables or disables the Maps Geocoding probe. Geocoding requests count
+// towards the quota of the key's project, so the probe is disabled by default and keys are
+// only verified with Firebase Authentication.
+func (s *Scanner) SetGeocodingProbe(enabled bool) {
+	s.geocoding = enabled
+}
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"AIza"}
+}
+
+// FromData will find and optionally verify Google API keys in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	uniqueMatches := make(map[string]struct{})
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		uniqueMatches[match[1]] = struct{}{}
+	}
+
+	for key := range uniqueMatches {
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_GoogleApiKey,
+			Raw:          []byte(key),
+		}
+
+		if verify && !s.skipProbes {
+			client := s.client
+			if client == nil {
+				client = defaultClient
+			}
+			isVerified, extraData, verificationErr := verifyKey(ctx, client, key, s.geocoding)
+			s1.Verified = isVerified
+			s1.ExtraData = extraData
+			s1.SetVerificationError(verificationErr, key)
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// probeResult is what a service tells of a key.
+type probeResult int
+
+const (
+	// keyInvalid is returned for keys that don't exist, or were deleted.
+	keyInvalid probeResult = iota
+	// keyDenied is returned for valid keys that can't call the service, because it's not
+	// enabled in their project or the key is restricted to other services or apps.
+	keyDenied
+	// keyAllowed is returned for valid keys that can call the service.
+	keyAllowed
+)
+
+// probe calls a service with a key.
+type probe struct {
+	service string
+	run     func(ctx context.Context, client *http.Client, key string) (probeResult, map[string]string, error)
+	// billed probes count towards the quota of the key's project.
+	billed bool
+}
+
+// probes are called in order. They only read data.
+var probes = []probe{
+	{service: "firebase_auth", run: probeFirebaseAuth},
+	{service: "maps_geocoding", run: probeGeocoding, billed: true},
+}
+
+// verifyKey reports whether the key is valid, and the services it can call in ExtraData. The
+// probes stop at the first one that finds the key invalid. Billed probes are skipped unless
+// billed is true.
+func verifyKey(ctx context.Context, client *http.Client, key string, billed bool) (bool, map[string]string, error) {
+	var (
+		valid    bool
+		services []string
+		errs     []error
+	)
+	extraData := make(map[string]string)
+	for _, p := range probes {
+		if p.billed && !billed {
+			continue
+		}
+		result, data, err := p.run(ctx, client, key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.service, err))
+			continue
+		}
+		if result == keyInvalid {
+			return false, nil, nil
+		}
+		valid = true
+		if result == keyAllowed {
+			services = append(services, p.service)
+			for k, v := range data {
+				extraData[k] = v
+			}
+		}
+	}
+	if !valid {
+		return false, nil, errors.Join(errs...)
+	}
+	if len(services) > 0 {
+		extraData["services"] = strings.Join(services, ",")
+	}
+	if len(extraData) == 0 {
+		extraData = nil
+	}
+	return true, extraData, nil
+}
+
+// googleAPIError is the error returned by the APIs of googleapis.com.
+type googleAPIError struct {
+	Error struct {
+		Message string `json:"message"`
+		Details []struct {
+			Reason string `json:"reason"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func (e googleAPIError) keyInvalid() bool {
+	for _, detail := range e.Error.Details {
+		if detail.Reason == "API_KEY_INVALID" {
+			return true
+		}
+	}
+	return strings.Contains(e.Error.Message, "API key not valid")
+}
+
+// probeFirebaseAuth reads the Firebase Authentication configuration of the key's project, which
+// Firebase apps read with their key before signing users in.
+func probeFirebaseAuth(ctx context.Context, client *http.Client, key string) (probeResult, map[string]string, error) {
+	endpoint := "https://www.googleapis.com/identitytoolkit/v3/relyingparty/getProjectConfig?key=" + url.QueryEscape(key)
+	res, err := get(ctx, client, endpoint)
+	if err != nil {
+		return keyInvalid, nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		var config struct {
+			ProjectID string `json:"projectId"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&config); err != nil || config.ProjectID == "" {
+			return keyAllowed, nil, nil
+		}
+		return keyAllowed, map[string]string{"project_id": config.ProjectID}, nil
+	case http.StatusBadRequest, http.StatusForbidden:
+		var apiErr googleAPIError
+		if err := json.NewDecoder(res.Body).Decode(&apiErr); err != nil {
+			return keyInvalid, nil, fmt.Errorf("unexpected HTTP response body: %w", err)
+		}
+		if apiErr.keyInvalid() {
+			return keyInvalid, nil, nil
+		}
+		return keyDenied, nil, nil
+	default:
+		return keyInvalid, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
+// probeGeocoding geocodes an address with the Maps Geocoding API.
+func probeGeocoding(ctx context.Context, client *http.Client, key string) (probeResult, map[string]string, error) {
+	endpoint := "https://maps.googleapis.com/maps/api/geocode/json?address=Googleplex&key=" + url.QueryEscape(key)
+	res, err := get(ctx, client, endpoint)
+	if err != nil {
+		return keyInvalid, nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return keyInvalid, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+	// The API reports errors in the body of responses with a 200 status.
+	var geocode struct {
+		Status       string `json:"status"`
+		ErrorMessage string `json:"error_message"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&geocode); err != nil {
+		return keyInvalid, nil, fmt.Errorf("unexpected HTTP response body: %w", err)
+	}
+	switch geocode.Status {
+	case "OK", "ZERO_RESULTS":
+		return keyAllowed, nil, nil
+	case "REQUEST_DENIED":
+		if strings.Contains(geocode.ErrorMessage, "API key is invalid") {
+			return keyInvalid, nil, nil
+		}
+		return keyDenied, nil, nil
+	case "OVER_QUERY_LIMIT":
+		// Quotas are only tracked for valid keys.
+		return keyDenied, nil, nil
+	default:
+		return keyInvalid, nil, fmt.Errorf("unexpected geocoding status %q", geocode.Status)
+	}
+}
+
+func get(ctx context.Context, client *http.Client, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_GoogleApiKey
+}
@@ -0,0 +1,148 @@
+package hashicorpvaulttoken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner detects HashiCorp Vault service and batch tokens. Vault servers are self-hosted, so
+// tokens are only verified against the address of a server, which is either set as a custom
+// verification endpoint or read from the VAULT_ADDR environment variable. Tokens are reported
+// unverified when no address is known.
+type Scanner struct {
+	detectors.EndpointSetter
+	client *http.Client
+}
+
+// Ensure the Scanner satisfies the interfaces at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+var _ detectors.EndpointCustomizer = (*Scanner)(nil)
+
+// addrEnv names the environment variable holding the address of the Vault server, as it does
+// for the Vault CLI.
+const addrEnv = "VAULT_ADDR"
+
+func (Scanner) DefaultEndpoint() string { return strings.TrimRight(os.Getenv(addrEnv), "/") }
+
+var (
+	defaultClient = common.SaneHttpClient()
+
+	// Service and batch tokens are prefixed with hvs. and hvb. since Vault 1.10.
+	tokenPat = regexp.MustCompile(`\b(hv[sb]\.[A-Za-z0-9_-]{24,})\b`)
+	// Tokens created by older versions are prefixed with s. and b., which is too common to be
+	// matched without the context of Vault.
+	legacyTokenPat = regexp.MustCompile(detectors.PrefixRegex([]string{"vault"}) + `\b(s\.[A-Za-z0-9]{24}|b\.[A-Za-z0-9_-]{24,})\b`)
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"hvs.", "hvb.", "vault"}
+}
+
+// FromData will find and optionally verify HashiCorp Vault tokens in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	uniqueMatches := make(map[string]struct{})
+	for _, pat := range []*regexp.Regexp{tokenPat, legacyTokenPat} {
+		for _, match := range pat.FindAllStringSubmatch(dataStr, -1) {
+			uniqueMatches[match[1]] = struct{}{}
+		}
+	}
+
+	for token := range uniqueMatches {
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_HashiCorpVaultToken,
+			Raw:          []byte(token),
+		}
+
+		if verify {
+			client := s.client
+			if client == nil {
+				client = defaultClient
+			}
+
+			for _, addr := range s.Endpoints(s.DefaultEndpoint()) {
+				// The token can't be verified without the address of the server it was issued by.
+				if addr == "" {
+					continue
+				}
+				isVerified, extraData, verificationErr := lookupSelf(ctx, client, addr, token)
+				s1.Verified = isVerified
+				s1.ExtraData = extraData
+				s1.SetVerificationError(verificationErr, token)
+				if isVerified {
+					break
+				}
+			}
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+type lookupSelfResponse struct {
+	Data struct {
+		Policies []string `json:"policies"`
+		TTL      int64    `json:"ttl"`
+	} `json:"data"`
+}
+
+// lookupSelf looks up the token on the Vault server, and returns its policies and its remaining
+// time to live in seconds. Vault returns 403 for tokens it doesn't know.
+func lookupSelf(ctx context.Context, client *http.Client, addr, token string) (bool, map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(addr, "/")+"/v1/auth/token/lookup-self", nil)
+	if err != nil {
+		return false, nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		var lookup lookupSelfResponse
+		if err := json.NewDecoder(res.Body).Decode(&lookup); err != nil {
+			// The token was accepted even if its properties can't be read.
+			return true, nil, nil
+		}
+		policies := append([]string(nil), lookup.Data.Policies...)
+		sort.Strings(policies)
+		return true, map[string]string{
+			"policies": strings.Join(policies, ","),
+			// A TTL of 0 means the token never expires.
+			"ttl": strconv.FormatInt(lookup.Data.TTL, 10),
+		}, nil
+	case http.StatusForbidden:
+		return false, nil, nil
+	default:
+		return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_HashiCorpVaultToken
+}
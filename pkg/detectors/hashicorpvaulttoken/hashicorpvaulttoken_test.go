@@ -0,0 +1,128 @@
+package hashicorpvaulttoken
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+const (
+	testToken       = "hvs.CAESIJlWjpq1pP4PnQwCFMoXwUOrUfbFNSdDGMuL1FLmGrj3Gh4KHGh2cy5hQ0VaR1BXc2dBbTlEOXdVS0tBclFHT2M"
+	testBatchToken  = "hvb.AAAAAQJqZ2dzY3JpcHRlZC1iYXRjaC10b2tlbi1mb3ItdGVzdGluZw"
+	testLegacyToken = "s.Ga3gUUZqGBRmyIsVb6JzWg2f"
+)
+
+func TestHashiCorpVaultToken_Pattern(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{name: "service token", data: "export VAULT_TOKEN=" + testToken, want: []string{testToken}},
+		{name: "batch token", data: "token: " + testBatchToken, want: []string{testBatchToken}},
+		{name: "legacy token", data: "vault login " + testLegacyToken, want: []string{testLegacyToken}},
+		{name: "legacy token without context", data: "vault\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n" + testLegacyToken},
+		{name: "too short", data: "vault token hvs.tooshort"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Scanner{}.FromData(context.Background(), false, []byte(tt.data))
+			assert.NoError(t, err)
+			var got []string
+			for _, result := range results {
+				got = append(got, string(result.Raw))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHashiCorpVaultToken_Verification(t *testing.T) {
+	var requests []string
+	client := &http.Client{Transport: common.FakeTransport{
+		CreateResponse: func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.URL.String())
+			status, body := http.StatusForbidden, `{"errors":["permission denied"]}`
+			if req.Header.Get("X-Vault-Token") == testToken {
+				status, body = http.StatusOK, `{"data":{"policies":["deploy","default"],"ttl":2764800}}`
+			}
+			return &http.Response{Request: req, StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}}
+
+	tests := []struct {
+		name          string
+		addrEnv       string
+		endpoints     []string
+		token         string
+		wantVerified  bool
+		wantExtraData map[string]string
+		wantRequests  []string
+	}{
+		{
+			name:          "verified",
+			addrEnv:       "https://vault.example.com:8200/",
+			token:         testToken,
+			wantVerified:  true,
+			wantExtraData: map[string]string{"policies": "default,deploy", "ttl": "2764800"},
+			wantRequests:  []string{"https://vault.example.com:8200/v1/auth/token/lookup-self"},
+		},
+		{
+			name:         "unverified",
+			addrEnv:      "https://vault.example.com:8200",
+			token:        testBatchToken,
+			wantRequests: []string{"https://vault.example.com:8200/v1/auth/token/lookup-self"},
+		},
+		{
+			name:          "custom endpoint",
+			endpoints:     []string{"https://vault.internal"},
+			token:         testToken,
+			wantVerified:  true,
+			wantExtraData: map[string]string{"policies": "default,deploy", "ttl": "2764800"},
+			wantRequests:  []string{"https://vault.internal/v1/auth/token/lookup-self"},
+		},
+		{
+			name:  "no address",
+			token: testToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(addrEnv, tt.addrEnv)
+			requests = nil
+			s := Scanner{client: client}
+			if len(tt.endpoints) > 0 {
+				assert.NoError(t, s.SetEndpoints(tt.endpoints...))
+			}
+
+			results, err := s.FromData(context.Background(), true, []byte("VAULT_TOKEN="+tt.token))
+			assert.NoError(t, err)
+			if assert.Len(t, results, 1) {
+				assert.Equal(t, tt.wantVerified, results[0].Verified)
+				assert.Equal(t, tt.wantExtraData, results[0].ExtraData)
+				assert.NoError(t, results[0].VerificationError())
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestHashiCorpVaultToken_UnexpectedStatus(t *testing.T) {
+	t.Setenv(addrEnv, "https://vault.example.com")
+	s := Scanner{client: common.ConstantResponseHttpClient(http.StatusServiceUnavailable, `{"errors":["Vault is sealed"]}`)}
+
+	results, err := s.FromData(context.Background(), true, []byte("VAULT_TOKEN="+testToken))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.False(t, results[0].Verified)
+		assert.Error(t, results[0].VerificationError())
+	}
+}
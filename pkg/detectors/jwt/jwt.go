@@ -0,0 +1,283 @@
+package jwt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v4"
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds JSON Web Tokens. Tokens are verified by checking their
+// signature against the keys of the JWKS endpoints set with SetEndpoints and
+// the keys set with SetPublicKeys, and checking they are currently valid.
+// Without any key, tokens are reported unverified.
+type Scanner struct {
+	client *http.Client
+	detectors.EndpointSetter
+	publicKeys []publicKey
+
+	// jwks caches the keys of every JWKS endpoint for the duration of the scan.
+	jwksMu sync.Mutex
+	jwks   map[string][]publicKey
+}
+
+// Ensure the Scanner satisfies the interfaces at compile time.
+var (
+	_ detectors.Detector           = (*Scanner)(nil)
+	_ detectors.EndpointCustomizer = (*Scanner)(nil)
+)
+
+// DefaultEndpoint is empty, as there is no default JWKS endpoint.
+func (*Scanner) DefaultEndpoint() string { return "" }
+
+var (
+	defaultClient = common.SaneHttpClient()
+	// The header and the payload are base64url encoded JSON objects, so both
+	// start with eyJ. The signature is empty for unsigned tokens.
+	tokenPat = regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{0,1000})`)
+)
+
+// SetPublicKeys sets the keys tokens are verified with. Each input is a PEM
+// file holding public keys or certificates, or a JWKS document.
+func (s *Scanner) SetPublicKeys(inputs ...[]byte) error {
+	var keys []publicKey
+	for _, input := range inputs {
+		parsed, err := parsePublicKeys(input)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, parsed...)
+	}
+	s.publicKeys = keys
+	return nil
+}
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Every base64 encoded JSON object starts with eyJ, so tokens are only looked
+// for next to words that tell they are credentials.
+func (s *Scanner) Keywords() []string {
+	return []string{"jwt", "bearer", "authorization", "token"}
+}
+
+// FromData will find and optionally verify JWTs in a given set of bytes.
+func (s *Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	uniqueMatches := make(map[string]struct{})
+	for _, match := range tokenPat.FindAllStringSubmatch(dataStr, -1) {
+		uniqueMatches[match[1]] = struct{}{}
+	}
+
+	now := time.Now()
+	for match := range uniqueMatches {
+		t, ok := parseToken(match)
+		if !ok {
+			continue
+		}
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_JWT,
+			Raw:          []byte(match),
+			Redacted:     match[:strings.LastIndexByte(match, '.')],
+			ExtraData:    t.extraData(now),
+		}
+
+		if verify {
+			signed, verificationErr := s.verifySignature(ctx, t)
+			if signed {
+				s1.ExtraData["signature"] = "valid"
+			}
+			s1.Verified = signed && t.validAt(now)
+			s1.SetVerificationError(verificationErr, match)
+		}
+
+		results = append(results, s1)
+	}
+
+	return
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// audience is the aud claim, which is either a string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+type claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+}
+
+type token struct {
+	raw    string
+	header header
+	claims claims
+}
+
+// parseToken decodes the header and the claims of a token, and reports
+// whether it looks like a JWT.
+func parseToken(raw string) (token, bool) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return token{}, false
+	}
+
+	t := token{raw: raw}
+	if err := decodeSegment(parts[0], &t.header); err != nil || t.header.Alg == "" {
+		return token{}, false
+	}
+	if err := decodeSegment(parts[1], &t.claims); err != nil {
+		return token{}, false
+	}
+	return t, true
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(int64(seconds), 0).UTC()
+}
+
+// validAt reports whether the token is neither expired nor not yet valid at now.
+func (t token) validAt(now time.Time) bool {
+	if exp := t.claims.ExpiresAt; exp != nil && !now.Before(unixTime(*exp)) {
+		return false
+	}
+	if nbf := t.claims.NotBefore; nbf != nil && now.Before(unixTime(*nbf)) {
+		return false
+	}
+	return true
+}
+
+func (t token) extraData(now time.Time) map[string]string {
+	extraData := map[string]string{"algorithm": t.header.Alg}
+	if t.claims.Issuer != "" {
+		extraData["issuer"] = t.claims.Issuer
+	}
+	if t.claims.Subject != "" {
+		extraData["subject"] = t.claims.Subject
+	}
+	if len(t.claims.Audience) > 0 {
+		extraData["audience"] = strings.Join(t.claims.Audience, ",")
+	}
+	if exp := t.claims.ExpiresAt; exp != nil {
+		extraData["expiry"] = unixTime(*exp).Format(time.RFC3339)
+		if !now.Before(unixTime(*exp)) {
+			extraData["expired"] = "true"
+		}
+	}
+	if nbf := t.claims.NotBefore; nbf != nil && now.Before(unixTime(*nbf)) {
+		extraData["not_yet_valid"] = "true"
+	}
+	if strings.EqualFold(t.header.Alg, "none") {
+		extraData["unsigned"] = "true"
+	}
+	return extraData
+}
+
+// verifySignature reports whether the token is signed by one of the
+// configured keys. Unsigned tokens and tokens signed with a shared secret
+// can't be verified.
+func (s *Scanner) verifySignature(ctx context.Context, t token) (bool, error) {
+	if strings.EqualFold(t.header.Alg, "none") || strings.HasPrefix(t.header.Alg, "HS") {
+		return false, nil
+	}
+
+	keys, keysErr := s.keys(ctx)
+	parser := gojwt.NewParser(gojwt.WithoutClaimsValidation())
+	for _, key := range keys {
+		if key.id != "" && t.header.Kid != "" && key.id != t.header.Kid {
+			continue
+		}
+		_, err := parser.Parse(t.raw, func(*gojwt.Token) (any, error) { return key.key, nil })
+		if err == nil {
+			return true, nil
+		}
+	}
+	// The token may be signed by a key of an endpoint that couldn't be fetched.
+	return false, keysErr
+}
+
+// keys returns the configured keys and the keys of every JWKS endpoint.
+func (s *Scanner) keys(ctx context.Context) ([]publicKey, error) {
+	keys := append([]publicKey(nil), s.publicKeys...)
+
+	client := s.client
+	if client == nil {
+		client = defaultClient
+	}
+
+	var errs []error
+	for _, endpoint := range s.Endpoints(s.DefaultEndpoint()) {
+		if endpoint == "" {
+			continue
+		}
+		jwks, err := s.endpointKeys(ctx, client, endpoint)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error fetching JWKS from %s: %w", endpoint, err))
+			continue
+		}
+		keys = append(keys, jwks...)
+	}
+	return keys, errors.Join(errs...)
+}
+
+// endpointKeys returns the keys of a JWKS endpoint, fetching them once.
+func (s *Scanner) endpointKeys(ctx context.Context, client *http.Client, endpoint string) ([]publicKey, error) {
+	s.jwksMu.Lock()
+	defer s.jwksMu.Unlock()
+
+	if keys, ok := s.jwks[endpoint]; ok {
+		return keys, nil
+	}
+	keys, err := fetchJWKS(ctx, client, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if s.jwks == nil {
+		s.jwks = make(map[string][]publicKey)
+	}
+	s.jwks[endpoint] = keys
+	return keys, nil
+}
+
+func (s *Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_JWT
+}
@@ -2,25 +2,29 @@ package mailgun
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	regexp "github.com/wasilibs/go-re2"
+	"io"
 	"net/http"
 	"strings"
 
+	regexp "github.com/wasilibs/go-re2"
+
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{
+type Scanner struct {
 	detectors.DefaultMultiPartCredentialProvider
+	client *http.Client
 }
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
 var (
-	client = common.SaneHttpClient()
+	defaultClient = common.SaneHttpClient()
 
 	tokenPats = map[string]*regexp.Regexp{
 		"Original MailGun Token": regexp.MustCompile(detectors.PrefixRegex([]string{"mailgun"}) + `\b([a-zA-Z-0-9]{72})\b`),
@@ -29,6 +33,17 @@ var (
 	}
 )
 
+// region is a Mailgun region, whose accounts are only known to its API host.
+type region struct {
+	name, host string
+}
+
+// regions are tried in order when verifying a key, the US region being the default one.
+var regions = []region{
+	{name: "us", host: "api.mailgun.net"},
+	{name: "eu", host: "api.eu.mailgun.net"},
+}
+
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
@@ -39,49 +54,110 @@ func (s Scanner) Keywords() []string {
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
+	uniqueMatches := make(map[string]struct{})
 	for _, tokenPat := range tokenPats {
-		matches := tokenPat.FindAllStringSubmatch(dataStr, -1)
-		for _, match := range matches {
-			if len(match) != 2 {
-				continue
-			}
-			resMatch := strings.TrimSpace(match[1])
-
-			s1 := detectors.Result{
-				DetectorType: detectorspb.DetectorType_Mailgun,
-				Raw:          []byte(resMatch),
-			}
+		for _, match := range tokenPat.FindAllStringSubmatch(dataStr, -1) {
+			uniqueMatches[strings.TrimSpace(match[1])] = struct{}{}
+		}
+	}
 
-			if verify {
-				req, err := http.NewRequestWithContext(ctx, "GET", "https://api.mailgun.net/v3/domains", nil)
-				if err != nil {
-					continue
-				}
-
-				// If resMatch has "key" prefix, use it as the username for basic auth.
-				if strings.HasPrefix(resMatch, "key-") {
-					req.SetBasicAuth("api", resMatch)
-				} else {
-					req.Header.Add("Authorization", fmt.Sprintf("Basic %s", resMatch))
-				}
-
-				res, err := client.Do(req)
-				if err == nil {
-					defer res.Body.Close()
-					if res.StatusCode >= 200 && res.StatusCode < 300 {
-						s1.Verified = true
-					}
-				}
+	for resMatch := range uniqueMatches {
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_Mailgun,
+			Raw:          []byte(resMatch),
+		}
 
+		if verify {
+			client := s.client
+			if client == nil {
+				client = defaultClient
 			}
 
-			results = append(results, s1)
+			isVerified, extraData, verificationErr := verifyMatch(ctx, client, resMatch)
+			s1.Verified = isVerified
+			s1.ExtraData = extraData
+			s1.SetVerificationError(verificationErr, resMatch)
 		}
+
+		results = append(results, s1)
 	}
 
 	return results, nil
 }
 
+// verifyMatch lists the domains of the key's account in each region until one of them accepts
+// the key, as each region only knows its own accounts. The key is unverified if every region
+// rejects it.
+func verifyMatch(ctx context.Context, client *http.Client, key string) (bool, map[string]string, error) {
+	for _, r := range regions {
+		domains, ok, err := listDomains(ctx, client, r.host, key)
+		if err != nil {
+			// The key may be valid in this region, so it can't be known to be invalid.
+			return false, nil, fmt.Errorf("region %s: %w", r.name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		extraData := map[string]string{"region": r.name}
+		if len(domains) > 0 {
+			extraData["domains"] = strings.Join(domains, ",")
+		}
+		return true, extraData, nil
+	}
+	return false, nil, nil
+}
+
+type domainsResponse struct {
+	Items []struct {
+		Name string `json:"name"`
+	} `json:"items"`
+}
+
+// listDomains returns the sending domains of the key's account using the API of a region, and
+// whether the region accepted the key.
+func listDomains(ctx context.Context, client *http.Client, host, key string) ([]string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+host+"/v3/domains", nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The original 72 character format is already the encoded credentials. Keys with the "key-"
+	// prefix, and those in the hex format, are the password of the api user.
+	if len(key) == 72 {
+		req.Header.Add("Authorization", fmt.Sprintf("Basic %s", key))
+	} else {
+		req.SetBasicAuth("api", key)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		var domainsRes domainsResponse
+		if err := json.NewDecoder(res.Body).Decode(&domainsRes); err != nil {
+			// The key was accepted even if the domains can't be read.
+			return nil, true, nil
+		}
+		domains := make([]string, 0, len(domainsRes.Items))
+		for _, item := range domainsRes.Items {
+			domains = append(domains, item.Name)
+		}
+		return domains, true, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_Mailgun
 }
@@ -0,0 +1,90 @@
+package mailgun
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+const (
+	testUSKey = "key-0123456789abcdef0123456789abcdef"
+	testEUKey = "0123456789abcdef0123456789abcdef-01234567-89abcdef"
+)
+
+func TestMailgun_Regions(t *testing.T) {
+	var requests []string
+	client := &http.Client{Transport: common.FakeTransport{
+		CreateResponse: func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.URL.Host)
+			user, key, _ := req.BasicAuth()
+			status, body := http.StatusUnauthorized, "Forbidden"
+			switch {
+			case user != "api":
+				status = http.StatusBadRequest
+			case req.URL.Host == "api.mailgun.net" && key == testUSKey:
+				status, body = http.StatusOK, `{"total_count": 1, "items": [{"name": "mg.example.com"}]}`
+			case req.URL.Host == "api.eu.mailgun.net" && key == testEUKey:
+				status, body = http.StatusOK, `{"total_count": 2, "items": [{"name": "mg.example.eu"}, {"name": "mail.example.eu"}]}`
+			}
+			return &http.Response{Request: req, StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}}
+	s := Scanner{client: client}
+
+	tests := []struct {
+		name          string
+		key           string
+		wantVerified  bool
+		wantExtraData map[string]string
+		wantRequests  []string
+	}{
+		{
+			name:          "US key",
+			key:           testUSKey,
+			wantVerified:  true,
+			wantExtraData: map[string]string{"region": "us", "domains": "mg.example.com"},
+			wantRequests:  []string{"api.mailgun.net"},
+		},
+		{
+			name:          "EU key",
+			key:           testEUKey,
+			wantVerified:  true,
+			wantExtraData: map[string]string{"region": "eu", "domains": "mg.example.eu,mail.example.eu"},
+			wantRequests:  []string{"api.mailgun.net", "api.eu.mailgun.net"},
+		},
+		{
+			name:         "revoked key",
+			key:          "key-ffffffffffffffffffffffffffffffff",
+			wantRequests: []string{"api.mailgun.net", "api.eu.mailgun.net"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			results, err := s.FromData(context.Background(), true, []byte("MAILGUN_API_KEY="+tt.key))
+			assert.NoError(t, err)
+			if assert.Len(t, results, 1) {
+				assert.Equal(t, tt.key, string(results[0].Raw))
+				assert.Equal(t, tt.wantVerified, results[0].Verified)
+				assert.Equal(t, tt.wantExtraData, results[0].ExtraData)
+				assert.NoError(t, results[0].VerificationError())
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+
+	// Errors of a region make the key indeterminate rather than unverified.
+	s = Scanner{client: common.ConstantResponseHttpClient(http.StatusInternalServerError, "")}
+	results, err := s.FromData(context.Background(), true, []byte("MAILGUN_API_KEY="+testEUKey))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.False(t, results[0].Verified)
+		assert.Error(t, results[0].VerificationError())
+	}
+}
@@ -27,6 +27,9 @@ func TestMailgun_FromChunk(t *testing.T) {
 	inactiveSecret := testSecrets.MustGetField("MAILGUN_INACTIVE")
 	keyDashSecret := testSecrets.MustGetField("NEW_MAILGUN_TOKEN_ACTIVE")
 	inactiveHexEncodedSecret := testSecrets.MustGetField("NEW_MAILGUN_TOKEN_INACTIVE")
+	// The sending domains of the accounts, as reported by verification.
+	domains := testSecrets.MustGetField("MAILGUN_DOMAINS")
+	keyDashDomains := testSecrets.MustGetField("NEW_MAILGUN_TOKEN_DOMAINS")
 
 	type args struct {
 		ctx    context.Context
@@ -52,6 +55,7 @@ func TestMailgun_FromChunk(t *testing.T) {
 				{
 					DetectorType: detectorspb.DetectorType_Mailgun,
 					Verified:     true,
+					ExtraData:    map[string]string{"region": "us", "domains": domains},
 				},
 			},
 			wantErr: false,
@@ -68,6 +72,7 @@ func TestMailgun_FromChunk(t *testing.T) {
 				{
 					DetectorType: detectorspb.DetectorType_Mailgun,
 					Verified:     true,
+					ExtraData:    map[string]string{"region": "us", "domains": keyDashDomains},
 				},
 			},
 			wantErr: false,
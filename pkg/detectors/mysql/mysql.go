@@ -0,0 +1,186 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const (
+	defaultPort    = "3306"
+	defaultTimeout = 5 * time.Second
+)
+
+// This detector finds MySQL connection string URIs, including the schemes used by ORMs such as
+// mysql2:// (Rails) and mysql+pymysql:// (SQLAlchemy). Verification connects to the server and
+// closes the connection as soon as the handshake has authenticated the credentials. It's only
+// enabled with SetDatabaseVerification, as it makes outbound database connections.
+type Scanner struct {
+	detectLoopback bool // Automated tests run against localhost, but we want to ignore those results in the wild
+	// verifyConnections enables verification, which connects to the database.
+	verifyConnections bool
+}
+
+// Ensure the Scanner satisfies the interfaces at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+var _ detectors.DatabaseVerifier = (*Scanner)(nil)
+
+var (
+	uriPat = regexp.MustCompile(`\b(?i)mysql(?:2|\+[a-z]+)?://\S+\b`)
+)
+
+// SetDatabaseVerification enables verification of the connection URIs by connecting to their
+// database. The connection is closed as soon as it's authenticated, without running any query.
+func (s *Scanner) SetDatabaseVerification(enabled bool) {
+	s.verifyConnections = enabled
+}
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"mysql"}
+}
+
+// connectionURI is a MySQL connection URI with credentials.
+type connectionURI struct {
+	user, password, host, port, database string
+}
+
+// FromData will find and optionally verify MySQL connection URIs in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	uniqueURIs := make(map[connectionURI]struct{})
+	for _, match := range uriPat.FindAllString(string(data), -1) {
+		uri, ok := parseURI(match)
+		if !ok {
+			continue
+		}
+		if !s.detectLoopback {
+			if uri.host == "localhost" {
+				continue
+			}
+			if ip := net.ParseIP(uri.host); ip != nil && ip.IsLoopback() {
+				continue
+			}
+		}
+		uniqueURIs[uri] = struct{}{}
+	}
+
+	for uri := range uniqueURIs {
+		if common.IsDone(ctx) {
+			break
+		}
+		raw := []byte(fmt.Sprintf("mysql://%s:%s@%s", uri.user, uri.password, net.JoinHostPort(uri.host, uri.port)))
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_MySQL,
+			Raw:          raw,
+			RawV2:        raw,
+			Redacted:     fmt.Sprintf("mysql://%s:********@%s", uri.user, net.JoinHostPort(uri.host, uri.port)),
+			ExtraData: map[string]string{
+				"host": uri.host,
+				"port": uri.port,
+			},
+		}
+		if uri.database != "" {
+			s1.ExtraData["database"] = uri.database
+		}
+
+		if verify && s.verifyConnections {
+			isVerified, verificationErr := verifyMySQL(ctx, uri)
+			s1.Verified = isVerified
+			s1.SetVerificationError(verificationErr, uri.password)
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// parseURI parses a connection URI, and reports whether it has a user, a password and a host.
+func parseURI(match string) (connectionURI, bool) {
+	u, err := url.Parse(match)
+	if err != nil || u.User == nil || u.Hostname() == "" {
+		return connectionURI{}, false
+	}
+	password, ok := u.User.Password()
+	// Skip passwords that were redacted with "*" characters.
+	if !ok || u.User.Username() == "" || strings.Trim(password, "*") == "" {
+		return connectionURI{}, false
+	}
+
+	uri := connectionURI{
+		user:     u.User.Username(),
+		password: password,
+		host:     u.Hostname(),
+		port:     u.Port(),
+	}
+	if uri.port == "" {
+		uri.port = defaultPort
+	}
+	uri.database, _, _ = strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	return uri, true
+}
+
+// verifyMySQL authenticates with the server, and closes the connection once the handshake is
+// done. Access denied errors mean the credentials are invalid; errors about the database mean
+// they were accepted.
+func verifyMySQL(ctx context.Context, uri connectionURI) (bool, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = uri.user
+	cfg.Passwd = uri.password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(uri.host, uri.port)
+	cfg.DBName = uri.database
+	cfg.TLSConfig = "preferred"
+	cfg.Logger = &mysql.NopLogger{}
+	// The driver uses its timeouts for the handshake, so they're bounded by the deadline of the
+	// context.
+	cfg.Timeout = defaultTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		cfg.Timeout = time.Until(deadline)
+		if cfg.Timeout <= 0 {
+			return false, ctx.Err()
+		}
+	}
+	cfg.ReadTimeout = cfg.Timeout
+	cfg.WriteTimeout = cfg.Timeout
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return false, err
+	}
+	conn, err := connector.Connect(ctx)
+	if err == nil {
+		_ = conn.Close()
+		return true, nil
+	}
+
+	// MySQL error numbers from https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1045:
+			// Access denied for the user.
+			return false, nil
+		case 1044, 1049:
+			// Access denied to the database, or unknown database, after authenticating.
+			return true, nil
+		}
+	}
+	return false, err
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_MySQL
+}
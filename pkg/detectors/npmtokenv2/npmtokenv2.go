@@ -2,28 +2,42 @@ package npmtokenv2
 
 import (
 	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	regexp "github.com/wasilibs/go-re2"
+	"io"
 	"net/http"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	client *http.Client
+	detectors.EndpointSetter
+}
 
 // Ensure the Scanner satisfies the interfaces at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 var _ detectors.Versioner = (*Scanner)(nil)
+var _ detectors.EndpointCustomizer = (*Scanner)(nil)
 
 func (s Scanner) Version() int { return 2 }
 
+// DefaultEndpoint is the public npm registry. Tokens can be verified against private registries,
+// such as Artifactory, by setting their URL with --verifier.
+func (Scanner) DefaultEndpoint() string { return "https://registry.npmjs.org" }
+
 var (
-	client = common.SaneHttpClient()
+	defaultClient = common.SaneHttpClient()
 
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
-	keyPat = regexp.MustCompile(`(npm_[0-9a-zA-Z]{36})`)
+	keyPat = regexp.MustCompile(`\b(npm_[0-9a-zA-Z]{36})\b`)
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
@@ -36,41 +50,134 @@ func (s Scanner) Keywords() []string {
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
-	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
-
-	for _, match := range matches {
-		if len(match) != 2 {
-			continue
-		}
-		resMatch := match[1]
+	uniqueMatches := make(map[string]struct{})
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		uniqueMatches[match[1]] = struct{}{}
+	}
 
+	for token := range uniqueMatches {
 		s1 := detectors.Result{
 			DetectorType: detectorspb.DetectorType_NpmToken,
-			Raw:          []byte(resMatch),
+			Raw:          []byte(token),
 		}
 		s1.ExtraData = map[string]string{
 			"rotation_guide": "https://howtorotate.com/docs/tutorials/npm/",
 		}
 
 		if verify {
-			req, err := http.NewRequestWithContext(ctx, "GET", "https://registry.npmjs.org/-/whoami", nil)
-			if err != nil {
-				continue
+			client := s.client
+			if client == nil {
+				client = defaultClient
 			}
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", resMatch))
-			res, err := client.Do(req)
-			if err == nil {
-				defer res.Body.Close()
-				if res.StatusCode >= 200 && res.StatusCode < 300 {
+			// The token is verified by the first registry that accepts it.
+			var verificationErr error
+			for _, registry := range s.Endpoints(s.DefaultEndpoint()) {
+				isVerified, extraData, err := verifyToken(ctx, client, registry, token)
+				if err != nil {
+					verificationErr = err
+					continue
+				}
+				if isVerified {
 					s1.Verified = true
+					for k, v := range extraData {
+						s1.ExtraData[k] = v
+					}
+					s1.ExtraData["registry"] = registry
+					break
 				}
 			}
+			if !s1.Verified {
+				s1.SetVerificationError(verificationErr, token)
+			}
 		}
 
 		results = append(results, s1)
 	}
 
-	return
+	return results, nil
+}
+
+// verifyToken verifies a token with the whoami endpoint of a registry, and returns the name of
+// its user and, if the token may list the tokens of its user, its type.
+func verifyToken(ctx context.Context, client *http.Client, registry, token string) (bool, map[string]string, error) {
+	var whoami struct {
+		Username string `json:"username"`
+	}
+	ok, err := getJSON(ctx, client, strings.TrimSuffix(registry, "/")+"/-/whoami", token, &whoami)
+	if !ok || err != nil {
+		return false, nil, err
+	}
+
+	extraData := map[string]string{"username": whoami.Username}
+	if tokenType := getTokenType(ctx, client, registry, token); tokenType != "" {
+		extraData["token_type"] = tokenType
+	}
+	return true, extraData, nil
+}
+
+// getTokenType finds the token among the tokens of its user, which the registry identifies by
+// the SHA-512 hash of the token, and returns its type: automation, read-only or publish. It
+// returns "" if the type can't be determined, as for granular access tokens, which can't list
+// tokens.
+func getTokenType(ctx context.Context, client *http.Client, registry, token string) string {
+	var tokens struct {
+		Objects []struct {
+			Key        string `json:"key"`
+			Readonly   bool   `json:"readonly"`
+			Automation bool   `json:"automation"`
+		} `json:"objects"`
+	}
+	ok, err := getJSON(ctx, client, strings.TrimSuffix(registry, "/")+"/-/npm/v1/tokens", token, &tokens)
+	if !ok || err != nil {
+		return ""
+	}
+
+	hash := sha512.Sum512([]byte(token))
+	key := hex.EncodeToString(hash[:])
+	for _, t := range tokens.Objects {
+		if t.Key != key {
+			continue
+		}
+		switch {
+		case t.Automation:
+			return "automation"
+		case t.Readonly:
+			return "read-only"
+		default:
+			return "publish"
+		}
+	}
+	return ""
+}
+
+// getJSON makes an authenticated GET request and decodes its JSON response into v. It returns
+// false if the token was rejected.
+func getJSON(ctx context.Context, client *http.Client, url, token string, v any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			return false, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
 }
 
 func (s Scanner) Type() detectorspb.DetectorType {
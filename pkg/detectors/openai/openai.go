@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
+	"strings"
 
 	regexp "github.com/wasilibs/go-re2"
 
@@ -27,7 +27,17 @@ var (
 	defaultClient = common.SaneHttpClient()
 
 	// The magic string T3BlbkFJ is the base64-encoded string: OpenAI
-	keyPat = regexp.MustCompile(`\b(sk-(?:(?:proj|[a-z0-9](?:[a-z0-9-]{0,40}[a-z0-9])?)-)?[[:alnum:]]{20}T3BlbkFJ[[:alnum:]]{20})\b`)
+	// Classic keys have 20 characters on either side of it, while project
+	// (sk-proj-), service account (sk-svcacct-) and admin (sk-admin-) keys
+	// are much longer and also contain dashes and underscores. The key must
+	// not be followed by a character it could contain, so that a key ending
+	// with a dash isn't truncated.
+	keyPat = regexp.MustCompile(`\b(sk-[\w-]{20,200}T3BlbkFJ[\w-]{20,200})(?:[^\w-]|$)`)
+
+	// keyPrefixes are the prefixes of the key formats kept unredacted.
+	keyPrefixes = []string{"sk-proj-", "sk-svcacct-", "sk-admin-", "sk-None-"}
+
+	verifyURL = "https://api.openai.com/v1/models"
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
@@ -48,7 +58,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	for token := range uniqueMatches {
 		s1 := detectors.Result{
 			DetectorType: detectorspb.DetectorType_OpenAI,
-			Redacted:     token[:3] + "..." + token[47:],
+			Redacted:     redact(token),
 			Raw:          []byte(token),
 		}
 
@@ -61,7 +71,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 			verified, extraData, verificationErr := verifyToken(ctx, client, token)
 			s1.Verified = verified
 			s1.ExtraData = extraData
-			s1.SetVerificationError(verificationErr)
+			s1.SetVerificationError(verificationErr, token)
 		}
 
 		results = append(results, s1)
@@ -70,15 +80,26 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	return
 }
 
+// redact keeps the format prefix and the last 4 characters of a key.
+func redact(token string) string {
+	prefix := "sk-"
+	for _, p := range keyPrefixes {
+		if strings.HasPrefix(token, p) {
+			prefix = p
+			break
+		}
+	}
+	return prefix + "..." + token[len(token)-4:]
+}
+
+// verifyToken lists the models available to the key. The organization and
+// project the key belongs to are read from the response headers.
 func verifyToken(ctx context.Context, client *http.Client, token string) (bool, map[string]string, error) {
-	// Undocumented API
-	// https://api.openai.com/v1/organizations
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.openai.com/v1/organizations", nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, verifyURL, nil)
 	if err != nil {
 		return false, nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	res, err := client.Do(req)
 	if err != nil {
@@ -90,45 +111,42 @@ func verifyToken(ctx context.Context, client *http.Client, token string) (bool,
 	}()
 
 	switch res.StatusCode {
-	case 200:
-		var orgs orgResponse
-		if err = json.NewDecoder(res.Body).Decode(&orgs); err != nil {
-			return false, nil, err
-		}
-
-		org := orgs.Data[0]
-		extraData := map[string]string{
-			"id":          org.ID,
-			"title":       org.Title,
-			"user":        org.User,
-			"description": org.Description,
-			"role":        org.Role,
-			"is_personal": strconv.FormatBool(org.Personal),
-			"is_default":  strconv.FormatBool(org.Default),
-			"total_orgs":  fmt.Sprintf("%d", len(orgs.Data)),
-		}
-		return true, extraData, nil
-	case 401:
+	case http.StatusOK:
+		return true, keyInfo(res.Header), nil
+	case http.StatusUnauthorized:
 		// Invalid
 		return false, nil, nil
+	case http.StatusForbidden:
+		// Restricted keys without the permission to list models are still valid.
+		var body errorResponse
+		if err := json.NewDecoder(res.Body).Decode(&body); err == nil && strings.Contains(body.Error.Message, "insufficient permissions") {
+			extraData := keyInfo(res.Header)
+			extraData["restricted"] = "true"
+			return true, extraData, nil
+		}
+		return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
 	default:
 		return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
 	}
 }
 
-// TODO: Add secret context?? Information about access, ownership etc
-type orgResponse struct {
-	Data []organization `json:"data"`
+// keyInfo returns the organization and project of a key from the headers of
+// a response to a request made with it.
+func keyInfo(header http.Header) map[string]string {
+	extraData := make(map[string]string, 2)
+	if org := header.Get("openai-organization"); org != "" {
+		extraData["organization"] = org
+	}
+	if project := header.Get("openai-project"); project != "" {
+		extraData["project"] = project
+	}
+	return extraData
 }
 
-type organization struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	User        string `json:"name"`
-	Description string `json:"description"`
-	Personal    bool   `json:"personal"`
-	Default     bool   `json:"is_default"`
-	Role        string `json:"role"`
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
 }
 
 func (s Scanner) Type() detectorspb.DetectorType {
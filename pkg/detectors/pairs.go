@@ -0,0 +1,33 @@
+package detectors
+
+import (
+	"sort"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
+)
+
+// MaxVerifiedPairs caps the number of combinations of the parts of a multi-part credential that
+// are verified for a chunk, as every part may be paired with every other part found in it, such
+// as every key with every ID. The other combinations are reported unverified.
+const MaxVerifiedPairs = 20
+
+// UniqueMatches returns the distinct values of the first group matched by pat, or of the whole
+// match if pat has no group, trimmed of spaces. They're sorted so that the combinations that are
+// verified don't depend on the order of the values in the data.
+func UniqueMatches(pat *regexp.Regexp, data string) []string {
+	unique := make(map[string]struct{})
+	for _, match := range pat.FindAllStringSubmatch(data, -1) {
+		value := match[0]
+		if len(match) > 1 {
+			value = match[1]
+		}
+		unique[strings.TrimSpace(value)] = struct{}{}
+	}
+	values := make([]string, 0, len(unique))
+	for value := range unique {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
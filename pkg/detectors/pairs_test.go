@@ -0,0 +1,26 @@
+package detectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	regexp "github.com/wasilibs/go-re2"
+)
+
+func TestUniqueMatches(t *testing.T) {
+	data := "key=b2 id: a1 key= a1 key=b2"
+	tests := []struct {
+		name string
+		pat  *regexp.Regexp
+		want []string
+	}{
+		{name: "first group", pat: regexp.MustCompile(`key=(\s?[a-z]\d)`), want: []string{"a1", "b2"}},
+		{name: "whole match", pat: regexp.MustCompile(`[a-z]\d`), want: []string{"a1", "b2"}},
+		{name: "no match", pat: regexp.MustCompile(`secret=(\w+)`), want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UniqueMatches(tt.pat, data))
+		})
+	}
+}
@@ -54,10 +54,19 @@ var (
 type Scanner struct {
 	detectors.DefaultMultiPartCredentialProvider
 	detectLoopback bool // Automated tests run against localhost, but we want to ignore those results in the wild
+	// verifyConnections enables verification, which connects to the database.
+	verifyConnections bool
 }
 
 var _ detectors.Detector = (*Scanner)(nil)
 var _ detectors.CustomFalsePositiveChecker = (*Scanner)(nil)
+var _ detectors.DatabaseVerifier = (*Scanner)(nil)
+
+// SetDatabaseVerification enables verification of the connection URIs by connecting to their
+// database. The connection is closed as soon as it's authenticated, without running any query.
+func (s *Scanner) SetDatabaseVerification(enabled bool) {
+	s.verifyConnections = enabled
+}
 
 func (s Scanner) Keywords() []string {
 	return []string{"postgres"}
@@ -106,6 +115,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) ([]dete
 			DetectorType: detectorspb.DetectorType_Postgres,
 			Raw:          raw,
 			RawV2:        raw,
+			Redacted:     fmt.Sprintf("postgresql://%s:********@%s:%s", user, host, port),
 		}
 
 		// We don't need to normalize the (deprecated) requiressl option into the (up-to-date) sslmode option - pq can
@@ -118,7 +128,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) ([]dete
 			params[pg_sslmode] = pg_sslmode_require
 		}
 
-		if verify {
+		if verify && s.verifyConnections {
 			// pq appears to ignore the context deadline, so we copy any timeout that's been set into the connection
 			// parameters themselves.
 			if timeout, ok := getDeadlineInSeconds(ctx); ok && timeout > 0 {
@@ -140,6 +150,11 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) ([]dete
 		}
 		result.ExtraData = map[string]string{
 			pg_sslmode: sslmode,
+			pg_host:    host,
+			pg_port:    port,
+		}
+		if dbname := params[pg_dbname]; dbname != "" {
+			result.ExtraData["database"] = dbname
 		}
 
 		results = append(results, result)
@@ -0,0 +1,179 @@
+package pypiuploadtoken
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner detects the API tokens packages are uploaded to PyPI with. Tokens of private
+// package indexes running Warehouse, PyPI's software, are verified against their upload URL,
+// which is set as a custom verification endpoint.
+type Scanner struct {
+	detectors.EndpointSetter
+	client *http.Client
+}
+
+// Ensure the Scanner satisfies the interfaces at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+var _ detectors.EndpointCustomizer = (*Scanner)(nil)
+
+func (Scanner) DefaultEndpoint() string { return "https://upload.pypi.org/legacy/" }
+
+var (
+	defaultClient = common.SaneHttpClient()
+
+	// Tokens are macaroons, serialized in the binary format whose first bytes, "AgE" once
+	// encoded, introduce the location of the index that issued them. They're encoded in URL-safe
+	// base64, which can end them with a dash, so a word boundary can't.
+	keyPat = regexp.MustCompile(`\b(pypi-AgE[\w-]{70,})(?:[^\w-]|$)`)
+	// hostPat matches the locations tokens are expected to have.
+	hostPat = regexp.MustCompile(`^[\w.-]+(?::\d+)?$`)
+)
+
+// testPyPIUploadURL is where the tokens issued by TestPyPI are verified, unless custom
+// endpoints are set.
+const testPyPIUploadURL = "https://test.pypi.org/legacy/"
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"pypi-"}
+}
+
+// FromData will find and optionally verify PyPI upload tokens in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	uniqueMatches := make(map[string]struct{})
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		uniqueMatches[match[1]] = struct{}{}
+	}
+
+	for token := range uniqueMatches {
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_PyPIUploadToken,
+			Raw:          []byte(token),
+		}
+		location, ok := tokenLocation(token)
+		if ok {
+			s1.ExtraData = map[string]string{"index": location}
+		}
+
+		if verify {
+			client := s.client
+			if client == nil {
+				client = defaultClient
+			}
+
+			for _, endpoint := range s.Endpoints(s.DefaultEndpoint()) {
+				if endpoint == s.DefaultEndpoint() && location == "test.pypi.org" {
+					endpoint = testPyPIUploadURL
+				}
+				isVerified, verificationErr := verifyToken(ctx, client, uploadURL(endpoint), token)
+				s1.Verified = isVerified
+				s1.SetVerificationError(verificationErr, token)
+				if isVerified {
+					break
+				}
+			}
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// tokenLocation returns the location of the macaroon of the token, which is the hostname of the
+// index that issued it, such as pypi.org.
+func tokenLocation(token string) (string, bool) {
+	// The location is at the start of the macaroon, so only that much of it is decoded.
+	encoded := strings.TrimPrefix(token, "pypi-")
+	encoded = encoded[:len(encoded)-len(encoded)%4]
+	macaroon, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(macaroon) < 3 || macaroon[0] != 2 || macaroon[1] != 1 {
+		return "", false
+	}
+	size, n := binary.Uvarint(macaroon[2:])
+	if n <= 0 || size > uint64(len(macaroon)-2-n) {
+		return "", false
+	}
+	location := string(macaroon[2+n : 2+n+int(size)])
+	return location, hostPat.MatchString(location)
+}
+
+// uploadURL returns the upload URL of an index given its upload URL or its hostname.
+func uploadURL(endpoint string) string {
+	if !strings.Contains(endpoint, "://") {
+		return "https://" + strings.TrimRight(endpoint, "/") + "/legacy/"
+	}
+	return endpoint
+}
+
+// invalidAuthMessage starts the error Warehouse rejects unknown or revoked tokens with.
+const invalidAuthMessage = "Invalid or non-existent authentication information"
+
+// verifyToken uploads nothing with the token. Warehouse authenticates uploads before validating
+// them, so it rejects the upload as invalid if it accepts the token, and as forbidden
+// otherwise, and no package is ever created.
+func verifyToken(ctx context.Context, client *http.Client, url, token string) (bool, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	_ = form.WriteField(":action", "file_upload")
+	_ = form.WriteField("protocol_version", "1")
+	if err := form.Close(); err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return false, err
+	}
+	req.SetBasicAuth("__token__", token)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusBadRequest:
+		return true, nil
+	case http.StatusUnauthorized:
+		return false, nil
+	case http.StatusForbidden:
+		// Uploads can be forbidden for other reasons, such as maintenance.
+		message, err := io.ReadAll(io.LimitReader(res.Body, 4096))
+		if err != nil {
+			return false, err
+		}
+		if bytes.Contains(message, []byte(invalidAuthMessage)) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	default:
+		return false, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_PyPIUploadToken
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 
 	regexp "github.com/wasilibs/go-re2"
@@ -48,6 +49,7 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 		s1 := detectors.Result{
 			DetectorType: detectorspb.DetectorType_SendGrid,
 			Raw:          []byte(token),
+			Redacted:     redact(token),
 		}
 
 		if verify {
@@ -98,7 +100,9 @@ func verifyToken(ctx context.Context, client *http.Client, token string) (bool,
 			return false, nil, err
 		}
 
+		// The scopes tell whether the key has full access or is restricted.
 		if len(scopesRes.Scopes) > 0 {
+			sort.Strings(scopesRes.Scopes)
 			extraData["scopes"] = strings.Join(scopesRes.Scopes, ",")
 		}
 
@@ -107,17 +111,51 @@ func verifyToken(ctx context.Context, client *http.Client, token string) (bool,
 		// 401 means the key is definitively invalid.
 		return false, nil, nil
 	case http.StatusForbidden:
-		// 403 means good key but not the right scope
-		return true, nil, nil
+		// 403 means good key but not the right scope, but only if the error says so.
+		var errorsRes errorsResponse
+		if err := json.NewDecoder(res.Body).Decode(&errorsRes); err != nil {
+			return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+		}
+		for _, e := range errorsRes.Errors {
+			if isScopeError(e.Message) {
+				return true, map[string]string{
+					"rotation_guide": "https://howtorotate.com/docs/tutorials/sendgrid/",
+					"scopes_error":   e.Message,
+				}, nil
+			}
+		}
+		return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
 	default:
 		return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
 	}
 }
 
+// isScopeError reports whether the message of a 403 response is about the key lacking a
+// permission, which SendGrid only reports for authenticated keys.
+func isScopeError(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "access forbidden") ||
+		strings.Contains(message, "scope") ||
+		strings.Contains(message, "permission")
+}
+
+// redact keeps the ID of the key, which identifies it in the SendGrid console, and hides its
+// secret part.
+func redact(token string) string {
+	id, _, _ := strings.Cut(strings.TrimPrefix(token, "SG."), ".")
+	return "SG." + id + ".********"
+}
+
 type scopesResponse struct {
 	Scopes []string `json:"scopes"`
 }
 
+type errorsResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_SendGrid
 }
@@ -3,7 +3,10 @@ package shopify
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strings"
 
 	regexp "github.com/wasilibs/go-re2"
@@ -13,8 +16,9 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{
+type Scanner struct {
 	detectors.DefaultMultiPartCredentialProvider
+	client *http.Client
 }
 
 // Ensure the Scanner satisfies the interface at compile time
@@ -22,73 +26,144 @@ var _ detectors.Detector = (*Scanner)(nil)
 var _ detectors.CustomFalsePositiveChecker = (*Scanner)(nil)
 
 var (
-	client = common.SaneHttpClient()
+	defaultClient = common.SaneHttpClient()
 
 	// Make sure that your group is surrounded in boundary characters such as below to reduce false positives.
-	keyPat    = regexp.MustCompile(`\b(shppa_|shpat_)([0-9A-Fa-f]{32})\b`)
-	domainPat = regexp.MustCompile(`[a-zA-Z0-9-]+\.myshopify\.com`)
+	keyPat    = regexp.MustCompile(`\b((shpat|shpca|shppa|shpss)_[0-9A-Fa-f]{32})\b`)
+	domainPat = regexp.MustCompile(`\b[a-zA-Z0-9-]+\.myshopify\.com\b`)
 )
 
+// tokenTypes describes the tokens by their prefix. Shared secrets sign the requests of an app
+// rather than authenticate it, so they can't be verified against a shop.
+var tokenTypes = map[string]string{
+	"shpat": "Admin API access token",
+	"shpca": "custom app access token",
+	"shppa": "private app password",
+	"shpss": "app shared secret",
+}
+
+// apiVersion is the version of the Admin API shops are queried with.
+const apiVersion = "2024-07"
+
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"shppa_", "shpat_"}
+	return []string{"shpat_", "shpca_", "shppa_", "shpss_"}
 }
 
-// FromData will find and optionally verify Shopify secrets in a given set of bytes.
+// FromData will find and optionally verify Shopify secrets in a given set of bytes. Tokens are
+// only valid for the shop they were issued by, so tokens are paired with the shop domains of
+// the chunk, and reported unverified on their own if it has none.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
-	keyMatches := keyPat.FindAllString(dataStr, -1)
-	domainMatches := domainPat.FindAllString(dataStr, -1)
+	keys := detectors.UniqueMatches(keyPat, dataStr)
+	domains := detectors.UniqueMatches(domainPat, dataStr)
 
-	for _, match := range keyMatches {
-		key := strings.TrimSpace(match)
+	client := s.client
+	if client == nil {
+		client = defaultClient
+	}
+
+	pairs := 0
+	for _, key := range keys {
+		prefix, _, _ := strings.Cut(key, "_")
+		tokenType := tokenTypes[prefix]
 
-		for _, domainMatch := range domainMatches {
-			domainRes := strings.TrimSpace(domainMatch)
+		if len(domains) == 0 {
+			results = append(results, detectors.Result{
+				DetectorType: detectorspb.DetectorType_Shopify,
+				Raw:          []byte(key),
+				ExtraData:    map[string]string{"token_type": tokenType},
+			})
+			continue
+		}
 
+		for _, domain := range domains {
 			s1 := detectors.Result{
 				DetectorType: detectorspb.DetectorType_Shopify,
-				Redacted:     domainRes,
-				Raw:          []byte(key + domainRes),
+				Redacted:     domain,
+				Raw:          []byte(key + domain),
+				ExtraData:    map[string]string{"token_type": tokenType},
 			}
 
-			if verify {
-				req, err := http.NewRequestWithContext(ctx, "GET", "https://"+domainRes+"/admin/oauth/access_scopes.json", nil)
-				if err != nil {
-					continue
-				}
-				req.Header.Add("X-Shopify-Access-Token", key)
-				res, err := client.Do(req)
-				if err == nil {
-					if res.StatusCode >= 200 && res.StatusCode < 300 {
-						shopifyTokenAccessScopes := shopifyTokenAccessScopes{}
-						err := json.NewDecoder(res.Body).Decode(&shopifyTokenAccessScopes)
-						if err == nil {
-							var handleArray []string
-							for _, handle := range shopifyTokenAccessScopes.AccessScopes {
-								handleArray = append(handleArray, handle.Handle)
-
-							}
-							s1.Verified = true
-							s1.ExtraData = map[string]string{
-								"access_scopes": strings.Join(handleArray, ","),
-							}
-						}
-						res.Body.Close()
+			if verify && prefix != "shpss" && pairs < detectors.MaxVerifiedPairs {
+				pairs++
+				verified, verificationErr := verifyToken(ctx, client, domain, key)
+				s1.Verified = verified
+				s1.SetVerificationError(verificationErr, key)
+				if verified {
+					s1.ExtraData["shop_domain"] = domain
+					// The scopes are only reported if they can be listed.
+					if scopes, err := accessScopes(ctx, client, domain, key); err == nil {
+						s1.ExtraData["access_scopes"] = strings.Join(scopes, ",")
 					}
 				}
 			}
 
 			results = append(results, s1)
-
 		}
-
 	}
 
 	return results, nil
+}
 
+// verifyToken reports whether the shop accepts the token, by reading the shop's details, which
+// any access token of the shop may read.
+func verifyToken(ctx context.Context, client *http.Client, domain, key string) (bool, error) {
+	res, err := get(ctx, client, "https://"+domain+"/admin/api/"+apiVersion+"/shop.json", key)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusPaymentRequired:
+		// Shops that don't exist, or aren't active, don't accept any token.
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
+// accessScopes returns the access scopes granted to the token, sorted.
+func accessScopes(ctx context.Context, client *http.Client, domain, key string) ([]string, error) {
+	res, err := get(ctx, client, "https://"+domain+"/admin/oauth/access_scopes.json", key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+	var shopifyTokenAccessScopes shopifyTokenAccessScopes
+	if err := json.NewDecoder(res.Body).Decode(&shopifyTokenAccessScopes); err != nil {
+		return nil, err
+	}
+	handles := make([]string, 0, len(shopifyTokenAccessScopes.AccessScopes))
+	for _, scope := range shopifyTokenAccessScopes.AccessScopes {
+		handles = append(handles, scope.Handle)
+	}
+	sort.Strings(handles)
+	return handles, nil
+}
+
+func get(ctx context.Context, client *http.Client, url, key string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("X-Shopify-Access-Token", key)
+	return client.Do(req)
 }
 
 func (s Scanner) IsFalsePositive(_ detectors.Result) bool {
@@ -51,6 +51,8 @@ func TestShopify_FromChunk(t *testing.T) {
 					Verified:     true,
 					ExtraData: map[string]string{
 						"access_scopes": "read_analytics,unauthenticated_read_selling_plans",
+						"shop_domain":   domain,
+						"token_type":    "Admin API access token",
 					},
 				},
 			},
@@ -69,7 +71,7 @@ func TestShopify_FromChunk(t *testing.T) {
 					DetectorType: detectorspb.DetectorType_Shopify,
 					Redacted:     domain,
 					Verified:     false,
-					ExtraData:    nil,
+					ExtraData:    map[string]string{"token_type": "Admin API access token"},
 				},
 			},
 			wantErr: false,
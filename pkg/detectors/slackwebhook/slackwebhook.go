@@ -59,47 +59,14 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 			}
 
 			if verify {
-
 				client := s.client
 				if client == nil {
 					client = defaultClient
 				}
 
-				// We don't want to actually send anything to webhooks we find. To verify them without spamming them, we
-				// send an intentionally malformed message and look for a particular expected error message.
-				payload := strings.NewReader(`intentionally malformed JSON from TruffleHog scan`)
-				req, err := http.NewRequestWithContext(ctx, "POST", resMatch, payload)
-				if err != nil {
-					continue
-				}
-				req.Header.Add("Content-Type", "application/json")
-				res, err := client.Do(req)
-				if err == nil {
-					defer res.Body.Close()
-					bodyBytes, err := io.ReadAll(res.Body)
-					if err != nil {
-						continue
-					}
-
-					defer res.Body.Close()
-
-					switch {
-					case res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices:
-						// Hopefully this never happens - it means we actually sent something to a channel somewhere. But
-						// we at least know the secret is verified.
-						s1.Verified = true
-					case res.StatusCode == http.StatusBadRequest && bytes.Equal(bodyBytes, []byte("invalid_payload")):
-						s1.Verified = true
-					case res.StatusCode == http.StatusNotFound:
-						// Not a real webhook or the owning app's OAuth token has been revoked or the app has been deleted
-						// You might want to handle this case or log it.
-					default:
-						err = fmt.Errorf("unexpected HTTP response status %d: %s", res.StatusCode, bodyBytes)
-						s1.SetVerificationError(err, resMatch)
-					}
-				} else {
-					s1.SetVerificationError(err, resMatch)
-				}
+				isVerified, verificationErr := verifyWebhook(ctx, client, resMatch)
+				s1.Verified = isVerified
+				s1.SetVerificationError(verificationErr, resMatch)
 			}
 
 			results = append(results, s1)
@@ -109,6 +76,50 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	return results, nil
 }
 
+// verifyWebhook checks whether a webhook URL is live without posting a message
+// to the channel it belongs to.
+//
+// Slack validates the payload only after it has resolved the webhook, so we
+// send an intentionally malformed body: a live webhook rejects it with a 400
+// "invalid_payload", while a webhook that doesn't exist, or whose app has been
+// deleted or had its token revoked, answers with a 404 "no_service". Nothing is
+// ever delivered to the channel in either case.
+func verifyWebhook(ctx context.Context, client *http.Client, webhookURL string) (bool, error) {
+	payload := strings.NewReader(`intentionally malformed JSON from TruffleHog scan`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, payload)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	bodyBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return false, err
+	}
+	body := bytes.TrimSpace(bodyBytes)
+
+	switch {
+	case res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices:
+		// Hopefully this never happens - it means we actually sent something to a channel somewhere. But
+		// we at least know the secret is verified.
+		return true, nil
+	case res.StatusCode == http.StatusBadRequest && bytes.Equal(body, []byte("invalid_payload")):
+		return true, nil
+	case res.StatusCode == http.StatusNotFound:
+		// "no_service": not a real webhook, or the owning app's OAuth token has been revoked or the app has
+		// been deleted.
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected HTTP response status %d: %s", res.StatusCode, body)
+	}
+}
+
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_SlackWebhook
 }
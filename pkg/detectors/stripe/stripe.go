@@ -3,28 +3,45 @@ package stripe
 import (
 	"context"
 	"fmt"
-	regexp "github.com/wasilibs/go-re2"
 	"net/http"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	client *http.Client
+}
 
 // Ensure the Scanner satisfies the interface at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
 
 var (
-	// doesn't include test keys with "sk_test"
-	secretKey = regexp.MustCompile(`[rs]k_live_[a-zA-Z0-9]{20,247}`)
+	defaultClient = common.SaneHttpClient()
+
+	// doesn't include test keys with "sk_test", but does include restricted test keys with "rk_test"
+	secretKey = regexp.MustCompile(`[rs]k_live_[a-zA-Z0-9]{20,247}|rk_test_[a-zA-Z0-9]{20,247}`)
+
+	// restrictedKeyResources are probed, along with verificationResource, to find
+	// what a restricted key can read. Stripe doesn't expose the permissions of a
+	// key, so they are inferred from the list endpoints the key is allowed to call.
+	restrictedKeyResources = []string{"customers", "payment_intents", "subscriptions", "payouts"}
+)
+
+const (
+	apiURL = "https://api.stripe.com/v1"
+	// verificationResource is listed to verify keys.
+	verificationResource = "charges"
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
 // Use identifiers in the secret preferably, or the provider name.
 func (s Scanner) Keywords() []string {
-	return []string{"k_live"}
+	return []string{"k_live", "rk_test"}
 }
 
 // FromData will find and optionally verify Stripe secrets in a given set of bytes.
@@ -36,43 +53,90 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 
 	for _, match := range matches {
 
-		s := detectors.Result{
+		s1 := detectors.Result{
 			DetectorType: detectorspb.DetectorType_Stripe,
 			Raw:          []byte(match),
 		}
-		s.ExtraData = map[string]string{
+		s1.ExtraData = map[string]string{
 			"rotation_guide": "https://howtorotate.com/docs/tutorials/stripe/",
 		}
 
-		if verify {
-
-			baseURL := "https://api.stripe.com/v1/charges"
+		restricted := strings.HasPrefix(match, "rk_")
+		if restricted {
+			s1.ExtraData["key_type"] = "restricted"
+		} else {
+			s1.ExtraData["key_type"] = "secret"
+		}
+		// Test mode keys can't move real money, so they are marked as low
+		// severity to let triage prioritize live keys.
+		if strings.Contains(match, "_test_") {
+			s1.ExtraData["mode"] = "test"
+			s1.ExtraData["severity"] = "low"
+		} else {
+			s1.ExtraData["mode"] = "live"
+			s1.ExtraData["severity"] = "high"
+		}
 
-			client := common.SaneHttpClient()
+		if verify {
+			client := s.client
+			if client == nil {
+				client = defaultClient
+			}
 
-			// test `read_user` scope
-			req, err := http.NewRequestWithContext(ctx, "GET", baseURL, nil)
-			if err != nil {
-				continue
+			status, err := listResource(ctx, client, match, verificationResource)
+			switch {
+			case err != nil:
+				s1.SetVerificationError(err, match)
+			// Restricted keys without access to charges are valid but forbidden.
+			case status == http.StatusOK || status == http.StatusForbidden:
+				s1.Verified = true
+			case status != http.StatusUnauthorized:
+				s1.SetVerificationError(fmt.Errorf("unexpected HTTP response status %d", status), match)
 			}
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", match))
-			req.Header.Add("Content-Type", "application/json")
-			res, err := client.Do(req)
-			if err == nil {
-				res.Body.Close() // The request body is unused.
-
-				if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusForbidden {
-					s.Verified = true
+
+			if s1.Verified && restricted {
+				// The verification request already probed whether the key can read charges.
+				var readable []string
+				if status == http.StatusOK {
+					readable = append(readable, verificationResource)
+				}
+				if readable = readableResources(ctx, client, match, readable); len(readable) > 0 {
+					s1.ExtraData["readable_resources"] = strings.Join(readable, ",")
 				}
 			}
 		}
 
-		results = append(results, s)
+		results = append(results, s1)
 	}
 
 	return
 }
 
+// listResource requests a single object of a resource and returns the response status.
+func listResource(ctx context.Context, client *http.Client, key, resource string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s?limit=1", apiURL, resource), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", key))
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close() // The response body is unused.
+	return res.StatusCode, nil
+}
+
+// readableResources appends the resources the restricted key is allowed to read to readable.
+func readableResources(ctx context.Context, client *http.Client, key string, readable []string) []string {
+	for _, resource := range restrictedKeyResources {
+		if status, err := listResource(ctx, client, key, resource); err == nil && status == http.StatusOK {
+			readable = append(readable, resource)
+		}
+	}
+	return readable
+}
+
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_Stripe
 }
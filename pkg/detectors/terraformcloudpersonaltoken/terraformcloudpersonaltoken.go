@@ -2,25 +2,41 @@ package terraformcloudpersonaltoken
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	regexp "github.com/wasilibs/go-re2"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 
+	regexp "github.com/wasilibs/go-re2"
+
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 )
 
-type Scanner struct{}
+// Scanner detects the user, team and organization API tokens of HCP Terraform, formerly
+// Terraform Cloud. Tokens of Terraform Enterprise installations are verified against their
+// hostname, which is set as a custom verification endpoint.
+type Scanner struct {
+	detectors.EndpointSetter
+	client *http.Client
+}
 
-// Ensure the Scanner satisfies the interface at compile time.
+// Ensure the Scanner satisfies the interfaces at compile time.
 var _ detectors.Detector = (*Scanner)(nil)
+var _ detectors.EndpointCustomizer = (*Scanner)(nil)
+
+func (Scanner) DefaultEndpoint() string { return "https://app.terraform.io" }
 
 var (
-	client = common.SaneHttpClient()
+	defaultClient = common.SaneHttpClient()
 
-	keyPat = regexp.MustCompile(`\b([A-Za-z0-9]{14}.atlasv1.[A-Za-z0-9]{67})\b`)
+	// Tokens are made of an ID, the atlasv1 version and a secret, which is longer in tokens
+	// created by recent versions.
+	keyPat = regexp.MustCompile(`\b([A-Za-z0-9]{14}\.atlasv1\.[A-Za-z0-9_-]{60,100})\b`)
 )
 
 // Keywords are used for efficiently pre-filtering chunks.
@@ -33,30 +49,30 @@ func (s Scanner) Keywords() []string {
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
 
-	matches := keyPat.FindAllStringSubmatch(dataStr, -1)
-
-	for _, match := range matches {
-		if len(match) != 2 {
-			continue
-		}
-		resMatch := strings.TrimSpace(match[1])
+	uniqueMatches := make(map[string]struct{})
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		uniqueMatches[match[1]] = struct{}{}
+	}
 
+	for token := range uniqueMatches {
 		s1 := detectors.Result{
 			DetectorType: detectorspb.DetectorType_TerraformCloudPersonalToken,
-			Raw:          []byte(resMatch),
+			Raw:          []byte(token),
 		}
 
 		if verify {
-			req, err := http.NewRequestWithContext(ctx, "GET", "https://app.terraform.io/api/v2/account/details", nil)
-			if err != nil {
-				continue
+			client := s.client
+			if client == nil {
+				client = defaultClient
 			}
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", resMatch))
-			res, err := client.Do(req)
-			if err == nil {
-				defer res.Body.Close()
-				if res.StatusCode >= 200 && res.StatusCode < 300 {
-					s1.Verified = true
+
+			for _, endpoint := range s.Endpoints(s.DefaultEndpoint()) {
+				isVerified, extraData, verificationErr := verifyToken(ctx, client, baseURL(endpoint), token)
+				s1.Verified = isVerified
+				s1.ExtraData = extraData
+				s1.SetVerificationError(verificationErr, token)
+				if isVerified {
+					break
 				}
 			}
 		}
@@ -67,6 +83,87 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	return results, nil
 }
 
+// baseURL returns the URL of a Terraform Enterprise installation given its URL or its hostname.
+func baseURL(endpoint string) string {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	return endpoint
+}
+
+type accountDetailsResponse struct {
+	Data struct {
+		Attributes struct {
+			Username         string `json:"username"`
+			IsServiceAccount bool   `json:"is-service-account"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+type organizationsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// verifyToken reads the account the token belongs to, which is a service account for team and
+// organization tokens, and the organizations it can access.
+func verifyToken(ctx context.Context, client *http.Client, baseURL, token string) (bool, map[string]string, error) {
+	var details accountDetailsResponse
+	if ok, err := get(ctx, client, baseURL+"/api/v2/account/details", token, &details); !ok {
+		return false, nil, err
+	}
+
+	extraData := map[string]string{
+		"username":        details.Data.Attributes.Username,
+		"service_account": strconv.FormatBool(details.Data.Attributes.IsServiceAccount),
+	}
+	// The organizations are only reported if they can be listed.
+	var orgs organizationsResponse
+	if ok, err := get(ctx, client, baseURL+"/api/v2/organizations?page%5Bsize%5D=100", token, &orgs); ok && err == nil {
+		names := make([]string, 0, len(orgs.Data))
+		for _, org := range orgs.Data {
+			names = append(names, org.ID)
+		}
+		sort.Strings(names)
+		extraData["organizations"] = strings.Join(names, ",")
+	}
+	return true, extraData, nil
+}
+
+// get decodes the response of the API to a request authenticated with the token into v. It
+// returns whether the token was accepted, even if the response can't be decoded.
+func get(ctx context.Context, client *http.Client, url, token string, v any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/vnd.api+json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			return true, fmt.Errorf("error decoding response: %w", err)
+		}
+		return true, nil
+	case http.StatusUnauthorized:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
 func (s Scanner) Type() detectorspb.DetectorType {
 	return detectorspb.DetectorType_TerraformCloudPersonalToken
 }
@@ -0,0 +1,146 @@
+package twilio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+var (
+	apiKeySIDPat    = regexp.MustCompile(`\bSK[0-9a-f]{32}\b`)
+	apiKeySecretPat = regexp.MustCompile(`\b[0-9A-Za-z]{32}\b`)
+)
+
+// match is a candidate found in a chunk, with its offset.
+type match struct {
+	value  string
+	offset int
+}
+
+func findMatches(pat *regexp.Regexp, data string) []match {
+	var matches []match
+	seen := make(map[string]struct{})
+	for _, loc := range pat.FindAllStringIndex(data, -1) {
+		value := data[loc[0]:loc[1]]
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		matches = append(matches, match{value: value, offset: loc[0]})
+	}
+	return matches
+}
+
+// nearest returns the candidates sorted by their distance to offset, so that the pairings
+// most likely to belong together are tried first.
+func nearest(candidates []match, offset int) []match {
+	sorted := append([]match(nil), candidates...)
+	distance := func(m match) int {
+		if m.offset < offset {
+			return offset - m.offset
+		}
+		return m.offset - offset
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return distance(sorted[i]) < distance(sorted[j]) })
+	return sorted
+}
+
+// apiKeyResults finds the API keys in data, each made of an SK key SID and a secret, and
+// optionally verifies them against the account SIDs found in the same data. The API of an
+// account can only be called with an API key of the account, so the pairing that verifies
+// identifies both the secret and the account of the key. At most maxVerifications pairings
+// are verified, nearest first, and keys whose pairings weren't all tried report
+// errVerificationBudget unless one of them verified.
+func (s Scanner) apiKeyResults(ctx context.Context, client *http.Client, verify bool, data string) []detectors.Result {
+	keySIDs := findMatches(apiKeySIDPat, data)
+	secrets := findMatches(apiKeySecretPat, data)
+	if len(keySIDs) == 0 || len(secrets) == 0 {
+		return nil
+	}
+	accounts := findMatches(sidPat, data)
+	verifications := maxVerifications
+
+	var results []detectors.Result
+	for _, keySID := range keySIDs {
+		candidates := nearest(secrets, keySID.offset)
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_Twilio,
+			Raw:          []byte(keySID.value),
+			RawV2:        []byte(keySID.value + candidates[0].value),
+			Redacted:     keySID.value,
+			ExtraData: map[string]string{
+				"rotation_guide": "https://howtorotate.com/docs/tutorials/twilio/",
+				"key_sid":        keySID.value,
+			},
+		}
+
+		if verify {
+		pairings:
+			for _, account := range nearest(accounts, keySID.offset) {
+				for _, secret := range candidates {
+					if verifications <= 0 {
+						s1.SetVerificationError(errVerificationBudget)
+						break pairings
+					}
+					verifications--
+
+					isVerified, verificationErr := verifyAPIKey(ctx, client, account.value, keySID.value, secret.value)
+					if verificationErr != nil {
+						s1.SetVerificationError(verificationErr, secret.value)
+						continue
+					}
+					if isVerified {
+						s1 = detectors.Result{
+							DetectorType: s1.DetectorType,
+							Verified:     true,
+							Raw:          s1.Raw,
+							RawV2:        []byte(keySID.value + secret.value),
+							Redacted:     s1.Redacted,
+							ExtraData:    s1.ExtraData,
+						}
+						s1.ExtraData["account_sid"] = account.value
+						break pairings
+					}
+				}
+			}
+		}
+
+		results = append(results, s1)
+	}
+	return results
+}
+
+// verifyAPIKey verifies an API key by reading the account it was paired with.
+func verifyAPIKey(ctx context.Context, client *http.Client, accountSID, keySID, secret string) (bool, error) {
+	url := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s.json", accountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.SetBasicAuth(keySID, secret)
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		// The secret is wrong, or the key belongs to another account.
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
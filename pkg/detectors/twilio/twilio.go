@@ -3,6 +3,7 @@ package twilio
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -44,6 +45,15 @@ func (s Scanner) Keywords() []string {
 	return []string{"sid"}
 }
 
+// maxVerifications bounds the number of pairings of identifiers and secrets verified per
+// chunk, as every account SID and API key SID found is paired with every candidate secret.
+// Auth tokens and API keys each have their own budget.
+const maxVerifications = 20
+
+// errVerificationBudget is the verification error of the credentials that weren't verified
+// because the chunk had more pairings than maxVerifications.
+var errVerificationBudget = errors.New("too many candidate pairings in the chunk to verify them all")
+
 // FromData will find and optionally verify Twilio secrets in a given set of bytes.
 func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
 	dataStr := string(data)
@@ -57,6 +67,14 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 	keyMatches := keyPat.FindAllString(dataStr, -1)
 	sidMatches := sidPat.FindAllString(dataStr, -1)
 
+	client := s.client
+	if client == nil {
+		client = defaultClient
+	}
+	// verifications is the number of auth token verifications left for the chunk.
+	verifications := maxVerifications
+	var starved bool
+
 	for _, sid := range sidMatches {
 		for _, key := range keyMatches {
 			s1 := detectors.Result{
@@ -70,50 +88,68 @@ func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (result
 				"rotation_guide": "https://howtorotate.com/docs/tutorials/twilio/",
 			}
 
-			if verify {
-				client := s.client
-				if client == nil {
-					client = defaultClient
-				}
-
-				req, err := http.NewRequestWithContext(
-					ctx, "GET", "https://verify.twilio.com/v2/Services", nil)
-				if err != nil {
-					continue
-				}
-				req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-				req.Header.Add("Accept", "*/*")
-				req.SetBasicAuth(sid, key)
-				res, err := client.Do(req)
-				if err == nil {
-					defer res.Body.Close()
-
-					if res.StatusCode >= 200 && res.StatusCode < 300 {
-						s1.Verified = true
-						var serviceResponse serviceResponse
-						if err := json.NewDecoder(res.Body).Decode(&serviceResponse); err == nil && len(serviceResponse.Services) > 0 { // no error in parsing and have at least one service
-							service := serviceResponse.Services[0]
-							s1.ExtraData["friendly_name"] = service.FriendlyName
-							s1.ExtraData["account_sid"] = service.AccountSID
-						}
-					} else if res.StatusCode == 401 || res.StatusCode == 403 {
-						// The secret is determinately not verified (nothing to do)
-					} else {
-						err = fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
-						s1.SetVerificationError(err, key)
-					}
-				} else {
-					s1.SetVerificationError(err, key)
+			if verify && verifications <= 0 {
+				starved = true
+			} else if verify {
+				verifications--
+				isVerified, extraData, verificationErr := verifyAuthToken(ctx, client, sid, key)
+				s1.Verified = isVerified
+				for k, v := range extraData {
+					s1.ExtraData[k] = v
 				}
+				s1.SetVerificationError(verificationErr, key)
 			}
 
-			if len(keyMatches) > 0 {
-				results = append(results, s1)
-			}
+			results = append(results, s1)
 		}
 	}
 
-	return detectors.CleanResults(results), nil
+	results = detectors.CleanResults(results)
+	// The account's result is unverified because some pairings weren't tried, not because
+	// they were all rejected.
+	if starved && len(results) == 1 && !results[0].Verified && results[0].VerificationError() == nil {
+		results[0].SetVerificationError(errVerificationBudget)
+	}
+
+	// API keys are reported whether or not an auth token was verified.
+	results = append(results, s.apiKeyResults(ctx, client, verify, dataStr)...)
+
+	return results, nil
+}
+
+// verifyAuthToken verifies the auth token of an account. The friendly name and account SID of
+// the first Verify service of the account are returned if it has any.
+func verifyAuthToken(ctx context.Context, client *http.Client, sid, key string) (bool, map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://verify.twilio.com/v2/Services", nil)
+	if err != nil {
+		return false, nil, err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Accept", "*/*")
+	req.SetBasicAuth(sid, key)
+	res, err := client.Do(req)
+	if err != nil {
+		return false, nil, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		var serviceResponse serviceResponse
+		if err := json.NewDecoder(res.Body).Decode(&serviceResponse); err == nil && len(serviceResponse.Services) > 0 { // no error in parsing and have at least one service
+			service := serviceResponse.Services[0]
+			return true, map[string]string{
+				"friendly_name": service.FriendlyName,
+				"account_sid":   service.AccountSID,
+			}, nil
+		}
+		return true, nil, nil
+	case res.StatusCode == 401 || res.StatusCode == 403:
+		// The secret is determinately not verified (nothing to do)
+		return false, nil, nil
+	default:
+		return false, nil, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
 }
 
 func (s Scanner) Type() detectorspb.DetectorType {
@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// Allowlist holds the fingerprints of known or accepted secrets whose results
+// are suppressed. A fingerprint either suppresses the secret wherever it's
+// found, or only in the files matching one of its path globs.
+type Allowlist struct {
+	// fingerprints maps each fingerprint to its path globs. A fingerprint
+	// without globs matches every location.
+	fingerprints map[string][]string
+}
+
+// LoadAllowlist reads an allowlist from a file with one entry per line. An
+// entry is a fingerprint, as printed with each result, optionally followed by
+// a path glob the secret is only suppressed in. Empty lines and lines starting
+// with # are ignored.
+func LoadAllowlist(path string) (*Allowlist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open allowlist: %w", err)
+	}
+	defer f.Close()
+
+	a := &Allowlist{fingerprints: make(map[string][]string)}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fingerprint, glob, _ := strings.Cut(line, " ")
+		glob = strings.TrimSpace(glob)
+		if err := a.add(strings.ToLower(fingerprint), glob); err != nil {
+			return nil, fmt.Errorf("invalid allowlist entry on line %d: %w", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read allowlist: %w", err)
+	}
+	return a, nil
+}
+
+// add records an entry of the allowlist.
+func (a *Allowlist) add(fingerprint, glob string) error {
+	if b, err := hex.DecodeString(fingerprint); err != nil || len(b) != 32 {
+		return fmt.Errorf("%q is not a fingerprint", fingerprint)
+	}
+	globs, ok := a.fingerprints[fingerprint]
+	if ok && len(globs) == 0 {
+		// The secret is already suppressed everywhere.
+		return nil
+	}
+	if glob == "" {
+		a.fingerprints[fingerprint] = nil
+		return nil
+	}
+	if _, err := filepath.Match(glob, ""); err != nil {
+		return fmt.Errorf("invalid path glob %q: %w", glob, err)
+	}
+	a.fingerprints[fingerprint] = append(globs, glob)
+	return nil
+}
+
+// Len returns the number of fingerprints in the allowlist.
+func (a *Allowlist) Len() int {
+	if a == nil {
+		return 0
+	}
+	return len(a.fingerprints)
+}
+
+// Suppresses returns whether the result is allowlisted. A nil allowlist
+// suppresses nothing.
+func (a *Allowlist) Suppresses(r *detectors.ResultWithMetadata) bool {
+	if a == nil {
+		return false
+	}
+	globs, ok := a.fingerprints[r.Fingerprint()]
+	if !ok {
+		return false
+	}
+	if len(globs) == 0 {
+		return true
+	}
+	path := resultPath(r.SourceMetadata)
+	if path == "" {
+		return false
+	}
+	for _, glob := range globs {
+		if matched, _ := filepath.Match(glob, path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// resultPath returns the path of the file a result was found in, as given by
+// the file or filename field of its source metadata, or "" if it has none.
+func resultPath(metadata *source_metadatapb.MetaData) string {
+	if metadata == nil {
+		return ""
+	}
+	m := metadata.ProtoReflect()
+	field := m.WhichOneof(m.Descriptor().Oneofs().ByName("data"))
+	if field == nil || field.Kind() != protoreflect.MessageKind {
+		return ""
+	}
+	data := m.Get(field).Message()
+	for _, name := range []protoreflect.Name{"file", "filename"} {
+		if fd := data.Descriptor().Fields().ByName(name); fd != nil && fd.Kind() == protoreflect.StringKind {
+			if path := data.Get(fd).String(); path != "" {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// WithAllowlist suppresses the results whose secret is in the allowlist.
+// Suppressed results aren't printed, given to result hooks, or counted as
+// found, unless WithShowSuppressed is set, in which case they're printed with
+// "suppressed" set in their ExtraData but still not counted.
+func WithAllowlist(allowlist *Allowlist) Option {
+	return func(e *Engine) { e.allowlist = allowlist }
+}
+
+// WithShowSuppressed configures the engine to print the results suppressed by
+// the allowlist, marked as suppressed.
+func WithShowSuppressed(show bool) Option {
+	return func(e *Engine) { e.showSuppressed = show }
+}
+
+// notifySuppressed records a result suppressed by the allowlist, and prints it
+// if suppressed results are shown.
+func (e *Engine) notifySuppressed(ctx context.Context, r detectors.ResultWithMetadata) {
+	atomic.AddUint64(&e.metrics.SuppressedSecretsFound, 1)
+	if !e.showSuppressed {
+		return
+	}
+
+	// The extra data may be shared with other results of the same secret.
+	extraData := make(map[string]string, len(r.ExtraData)+1)
+	for k, v := range r.ExtraData {
+		extraData[k] = v
+	}
+	extraData["suppressed"] = "true"
+	r.ExtraData = extraData
+
+	if e.pathAnonymizer != nil {
+		r = *e.pathAnonymizer.anonymizeResult(ctx, &r)
+	}
+	if err := e.printer.Print(ctx, &r); err != nil {
+		ctx.Logger().Error(err, "error printing result")
+	}
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func writeAllowlist(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "allowlist")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestAllowlist_Suppresses(t *testing.T) {
+	secret := detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("AKIA"), Redacted: "AKIAXXXX"}
+	other := detectors.Result{DetectorType: detectorspb.DetectorType_Github, Raw: []byte("ghp_secret")}
+	inFile := func(r detectors.Result, file string) *detectors.ResultWithMetadata {
+		return &detectors.ResultWithMetadata{
+			SourceMetadata: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{File: file},
+			}},
+			Result: r,
+		}
+	}
+
+	a, err := LoadAllowlist(writeAllowlist(t, "# Accepted secrets.\n\n"+
+		secret.Fingerprint()+" testdata/*.json\n"+
+		"  "+other.Fingerprint()+"\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, a.Len())
+
+	assert.True(t, a.Suppresses(inFile(secret, "testdata/creds.json")))
+	assert.False(t, a.Suppresses(inFile(secret, "config/creds.json")))
+	assert.False(t, a.Suppresses(&detectors.ResultWithMetadata{Result: secret}))
+	assert.True(t, a.Suppresses(inFile(other, "main.go")))
+	assert.True(t, a.Suppresses(&detectors.ResultWithMetadata{Result: other}))
+
+	// The fingerprint is of the secret, not of its verification or redacted form.
+	verified := secret
+	verified.Verified, verified.Redacted = true, "AKIA****"
+	assert.True(t, a.Suppresses(inFile(verified, "testdata/other.json")))
+
+	// Secrets sharing an ID have distinct fingerprints.
+	withSecret := secret
+	withSecret.RawV2 = []byte("AKIAsecret")
+	otherSecret := secret
+	otherSecret.RawV2 = []byte("AKIAother secret")
+	assert.NotEqual(t, withSecret.Fingerprint(), otherSecret.Fingerprint())
+	assert.False(t, a.Suppresses(inFile(withSecret, "testdata/creds.json")))
+
+	var none *Allowlist
+	assert.False(t, none.Suppresses(inFile(other, "main.go")))
+}
+
+func TestLoadAllowlist_Invalid(t *testing.T) {
+	_, err := LoadAllowlist(writeAllowlist(t, "# comment\nnot-a-fingerprint\n"))
+	assert.ErrorContains(t, err, "line 2")
+
+	fingerprint := (&detectors.Result{Raw: []byte("secret")}).Fingerprint()
+	_, err = LoadAllowlist(writeAllowlist(t, fingerprint+" [\n"))
+	assert.ErrorContains(t, err, "invalid path glob")
+
+	_, err = LoadAllowlist(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestEngine_Allowlist(t *testing.T) {
+	suppressed := detectors.Result{DetectorType: detectorspb.DetectorType(-1), Raw: []byte("fake secret v1")}
+	allowlist, err := LoadAllowlist(writeAllowlist(t, suppressed.Fingerprint()+"\n"))
+	assert.NoError(t, err)
+
+	for _, show := range []bool{false, true} {
+		ctx := context.Background()
+		collector := new(resultCollector)
+		e, err := Start(ctx,
+			WithDetectors(fakeDetectorV1{}, fakeDetectorV2{}),
+			WithConcurrency(1),
+			WithPrinter(collector),
+			WithAllowlist(allowlist),
+			WithShowSuppressed(show),
+		)
+		assert.NoError(t, err)
+		e.ScanChunk(&sources.Chunk{Data: []byte(fakeDetectorKeyword)})
+		assert.NoError(t, e.Finish(ctx))
+
+		metrics := e.GetMetrics()
+		assert.Equal(t, uint64(1), metrics.VerifiedSecretsFound)
+		assert.Equal(t, uint64(1), metrics.SuppressedSecretsFound)
+
+		got := make(map[string]string)
+		for _, r := range collector.results {
+			got[string(r.Raw)] = r.ExtraData["suppressed"]
+		}
+		want := map[string]string{"fake secret v2": ""}
+		if show {
+			want["fake secret v1"] = "true"
+		}
+		assert.Equal(t, want, got)
+	}
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// anonymizedFields maps the names of the source metadata fields holding paths, URLs or the
+// names of hosts and resources to the kind of token they're replaced with. The fields are
+// anonymized whatever the source.
+var anonymizedFields = map[protoreflect.Name]string{
+	"file":              pathToken,
+	"filename":          pathToken,
+	"path":              pathToken,
+	"submodule_path":    pathToken,
+	"package_file":      pathToken,
+	"link":              urlToken,
+	"repository":        urlToken,
+	"parent_repository": urlToken,
+	"repo":              urlToken,
+	"registry":          urlToken,
+	"image":             nameToken,
+	"bucket":            nameToken,
+	"project":           nameToken,
+	"project_name":      nameToken,
+	"project_owner":     nameToken,
+	"host":              nameToken,
+	"hostname":          nameToken,
+	"credential_host":   nameToken,
+	"channel_name":      nameToken,
+}
+
+const (
+	pathToken = "path"
+	urlToken  = "url"
+	nameToken = "name"
+)
+
+// WithAnonymizePaths configures the engine to replace the file paths, the repository and
+// link URLs, and the names of hosts, images, buckets, projects and channels in the source
+// metadata of printed results, and of the results given to result hooks, by tokens, so that results can be shared without revealing them. The same value is replaced
+// by the same token during a scan, but not across scans. The extension of paths is kept for
+// triage.
+//
+// If mapping isn't nil, a JSON object with each token and the value it replaces is written to
+// it, one per line, the first time the token is used, so that tokens can be mapped back.
+func WithAnonymizePaths(anonymize bool, mapping io.Writer) Option {
+	return func(e *Engine) {
+		e.pathAnonymizer = nil
+		if anonymize {
+			e.pathAnonymizer = newPathAnonymizer(mapping)
+		}
+	}
+}
+
+// pathAnonymizer replaces paths and URLs by tokens derived from a key that's unique to the scan.
+type pathAnonymizer struct {
+	key []byte
+
+	mu      sync.Mutex
+	mapping io.Writer
+	written map[string]struct{}
+}
+
+func newPathAnonymizer(mapping io.Writer) *pathAnonymizer {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return &pathAnonymizer{key: key, mapping: mapping, written: make(map[string]struct{})}
+}
+
+// anonymizeResult returns a copy of r whose source metadata, including the metadata of its
+// locations, is anonymized. r isn't modified, as its metadata is shared with the other results
+// of the same chunk.
+func (a *pathAnonymizer) anonymizeResult(ctx context.Context, r *detectors.ResultWithMetadata) *detectors.ResultWithMetadata {
+	anonymized := *r
+	anonymized.SourceMetadata = a.anonymizeMetadata(ctx, r.SourceMetadata)
+	if len(r.Locations) > 0 {
+		anonymized.Locations = make([]detectors.ResultLocation, len(r.Locations))
+		for i, loc := range r.Locations {
+			loc.SourceMetadata = a.anonymizeMetadata(ctx, loc.SourceMetadata)
+			anonymized.Locations[i] = loc
+		}
+	}
+	return &anonymized
+}
+
+func (a *pathAnonymizer) anonymizeMetadata(ctx context.Context, metadata *source_metadatapb.MetaData) *source_metadatapb.MetaData {
+	if metadata == nil {
+		return nil
+	}
+	anonymized := proto.Clone(metadata).(*source_metadatapb.MetaData)
+	a.anonymizeMessage(ctx, anonymized.ProtoReflect())
+	return anonymized
+}
+
+// anonymizeMessage replaces the anonymized fields of msg and of the messages it holds.
+func (a *pathAnonymizer) anonymizeMessage(ctx context.Context, msg protoreflect.Message) {
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList() || fd.IsMap():
+		case fd.Kind() == protoreflect.MessageKind:
+			a.anonymizeMessage(ctx, v.Message())
+		case fd.Kind() == protoreflect.StringKind:
+			if kind, ok := anonymizedFields[fd.Name()]; ok && v.String() != "" {
+				msg.Set(fd, protoreflect.ValueOfString(a.token(ctx, kind, v.String())))
+			}
+		}
+		return true
+	})
+}
+
+// token returns the token replacing value. Paths keep their extension.
+func (a *pathAnonymizer) token(ctx context.Context, kind, value string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(kind + ":" + value))
+	token := kind + "-" + hex.EncodeToString(mac.Sum(nil)[:8])
+	if kind == pathToken {
+		token += path.Ext(value)
+	}
+
+	if a.mapping == nil {
+		return token
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.written[token]; ok {
+		return token
+	}
+	a.written[token] = struct{}{}
+	line, err := json.Marshal(struct {
+		Token string `json:"token"`
+		Value string `json:"value"`
+	}{token, value})
+	if err == nil {
+		_, err = a.mapping.Write(append(line, '\n'))
+	}
+	if err != nil {
+		ctx.Logger().Error(err, "error writing anonymized path mapping")
+	}
+	return token
+}
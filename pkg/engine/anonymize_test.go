@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func gitMetadata(repository, file string) *source_metadatapb.MetaData {
+	return &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{
+			Commit:     "abc123",
+			Repository: repository,
+			File:       file,
+			Line:       3,
+		}},
+	}
+}
+
+func TestPathAnonymizer(t *testing.T) {
+	ctx := context.Background()
+	var mapping bytes.Buffer
+	a := newPathAnonymizer(&mapping)
+
+	original := gitMetadata("https://git.internal.example.com/team/app.git", "deploy/config/prod.env")
+	r := &detectors.ResultWithMetadata{
+		SourceMetadata: original,
+		Locations: []detectors.ResultLocation{
+			{SourceMetadata: original},
+			{SourceMetadata: gitMetadata("https://git.internal.example.com/team/app.git", "README")},
+		},
+	}
+	got := a.anonymizeResult(ctx, r)
+
+	git := got.SourceMetadata.GetGit()
+	assert.Regexp(t, `^url-[0-9a-f]{16}$`, git.Repository)
+	assert.Regexp(t, `^path-[0-9a-f]{16}\.env$`, git.File)
+	// Other fields are kept.
+	assert.Equal(t, "abc123", git.Commit)
+	assert.Equal(t, int64(3), git.Line)
+	// The metadata of the result is shared, so it's left as is.
+	assert.Equal(t, "deploy/config/prod.env", original.GetGit().File)
+
+	// The same value is replaced by the same token.
+	assert.Equal(t, git.File, got.Locations[0].SourceMetadata.GetGit().File)
+	assert.Equal(t, git.Repository, got.Locations[1].SourceMetadata.GetGit().Repository)
+	assert.Regexp(t, `^path-[0-9a-f]{16}$`, got.Locations[1].SourceMetadata.GetGit().File)
+
+	// Each token is mapped back once.
+	var lines []map[string]string
+	for _, line := range strings.Split(strings.TrimSpace(mapping.String()), "\n") {
+		var m map[string]string
+		assert.NoError(t, json.Unmarshal([]byte(line), &m))
+		lines = append(lines, m)
+	}
+	assert.ElementsMatch(t, []map[string]string{
+		{"token": git.Repository, "value": "https://git.internal.example.com/team/app.git"},
+		{"token": git.File, "value": "deploy/config/prod.env"},
+		{"token": got.Locations[1].SourceMetadata.GetGit().File, "value": "README"},
+	}, lines)
+
+	// Tokens aren't the same across scans.
+	other := newPathAnonymizer(nil).anonymizeResult(ctx, r)
+	assert.NotEqual(t, git.File, other.SourceMetadata.GetGit().File)
+}
+
+func TestEngine_AnonymizePaths(t *testing.T) {
+	ctx := context.Background()
+	printer := new(resultCollector)
+	var hooked []detectors.ResultWithMetadata
+	e, err := Start(ctx,
+		WithConcurrency(1),
+		WithDetectors(fakeDetectorV1{}),
+		WithVerify(false),
+		WithPrinter(printer),
+		WithAnonymizePaths(true, nil),
+		WithResultHook(func(_ context.Context, r detectors.ResultWithMetadata) { hooked = append(hooked, r) }),
+	)
+	assert.NoError(t, err)
+
+	e.ScanChunk(&sources.Chunk{
+		SourceType: sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: "/srv/app/secrets.yaml"}},
+		},
+		Data: []byte(fakeDetectorKeyword + " = fake secret v1"),
+	})
+	assert.NoError(t, e.Finish(ctx))
+
+	if assert.Len(t, printer.results, 1) && assert.Len(t, hooked, 1) {
+		file := printer.results[0].SourceMetadata.GetFilesystem().GetFile()
+		assert.Regexp(t, `^path-[0-9a-f]{16}\.yaml$`, file)
+		assert.Equal(t, file, hooked[0].SourceMetadata.GetFilesystem().GetFile())
+	}
+}
+
+// sensitiveFieldParts are parts of the names of source metadata fields that identify where a
+// secret was found, and must be anonymized.
+var sensitiveFieldParts = []string{"file", "path", "link", "url", "repo", "registry", "image", "bucket", "project", "host", "channel_name"}
+
+// fillStrings sets every string field of msg and of its message fields to a value derived
+// from the field's name.
+func fillStrings(msg protoreflect.Message) {
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		switch {
+		case fd.IsList() || fd.IsMap() || fd.ContainingOneof() != nil && msg.WhichOneof(fd.ContainingOneof()) != nil:
+		case fd.Kind() == protoreflect.MessageKind:
+			fillStrings(msg.Mutable(fd).Message())
+		case fd.Kind() == protoreflect.StringKind:
+			msg.Set(fd, protoreflect.ValueOfString("value-of-"+string(fd.Name())))
+		}
+	}
+}
+
+func TestPathAnonymizer_AllMetadataTypes(t *testing.T) {
+	ctx := context.Background()
+	a := newPathAnonymizer(nil)
+
+	data := (&source_metadatapb.MetaData{}).ProtoReflect().Descriptor().Oneofs().ByName("data").Fields()
+	for i := 0; i < data.Len(); i++ {
+		fd := data.Get(i)
+		t.Run(string(fd.Name()), func(t *testing.T) {
+			metadata := &source_metadatapb.MetaData{}
+			fillStrings(metadata.ProtoReflect().Mutable(fd).Message())
+			anonymized := a.anonymizeMetadata(ctx, metadata).ProtoReflect().Get(fd).Message()
+
+			var walk func(protoreflect.Message)
+			walk = func(msg protoreflect.Message) {
+				msg.Range(func(field protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+					switch {
+					case field.Kind() == protoreflect.MessageKind && !field.IsList() && !field.IsMap():
+						walk(v.Message())
+					case field.Kind() == protoreflect.StringKind && !field.IsList() && !field.IsMap():
+						for _, part := range sensitiveFieldParts {
+							if strings.Contains(string(field.Name()), part) {
+								assert.NotContains(t, v.String(), "value-of-", "%s.%s isn't anonymized", msg.Descriptor().Name(), field.Name())
+							}
+						}
+					}
+					return true
+				})
+			}
+			walk(anonymized)
+		})
+	}
+}
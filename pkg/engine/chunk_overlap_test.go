@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"bytes"
+	aCtx "context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// longSecretDetector finds secrets longer than the default chunk overlap.
+type longSecretDetector struct{}
+
+var _ detectors.MaxSecretSizeProvider = (*longSecretDetector)(nil)
+
+const longSecretSize = 2 * sources.PeekSize
+
+func (longSecretDetector) FromData(_ aCtx.Context, _ bool, data []byte) ([]detectors.Result, error) {
+	start := bytes.Index(data, []byte("BEGIN"))
+	if start < 0 {
+		return nil, nil
+	}
+	end := bytes.Index(data[start:], []byte("END"))
+	if end < 0 {
+		return nil, nil
+	}
+	return []detectors.Result{{
+		DetectorType: detectorspb.DetectorType(-1),
+		Raw:          data[start : start+end+len("END")],
+	}}, nil
+}
+
+func (longSecretDetector) Keywords() []string             { return []string{"BEGIN"} }
+func (longSecretDetector) Type() detectorspb.DetectorType { return detectorspb.DetectorType(-1) }
+func (longSecretDetector) MaxSecretSize() int64           { return longSecretSize }
+
+func TestEngine_ChunkOverlap(t *testing.T) {
+	ctx := context.Background()
+	secret := "BEGIN" + strings.Repeat("s", longSecretSize-len("BEGIN")-len("END")) + "END"
+	// The secret starts right before the end of the first chunk, so only the
+	// first chunk's overlap can hold all of it.
+	data := strings.Repeat("a", sources.ChunkSize-10) + secret + strings.Repeat("b", 2*sources.ChunkSize)
+
+	results, err := ScanBytes(ctx, []byte(data), WithDetectors(longSecretDetector{}), WithConcurrency(1))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, secret, string(results[0].Raw))
+	}
+
+	tests := map[string]struct {
+		options []Option
+		want    int
+	}{
+		"longest secret":   {options: []Option{WithDetectors(longSecretDetector{})}, want: longSecretSize},
+		"configured":       {options: []Option{WithDetectors(longSecretDetector{}), WithChunkOverlap(3 * longSecretSize)}, want: 3 * longSecretSize},
+		"below the secret": {options: []Option{WithDetectors(longSecretDetector{}), WithChunkOverlap(10)}, want: longSecretSize},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, err := Start(ctx, append(tt.options, WithConcurrency(1))...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, e.chunkOverlap)
+			assert.NoError(t, e.Finish(ctx))
+		})
+	}
+}
@@ -1,8 +1,6 @@
 package engine
 
 import (
-	"runtime"
-
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
@@ -30,7 +28,7 @@ func (e *Engine) ScanCircleCI(ctx context.Context, token string) error {
 	sourceID, jobID, _ := e.sourceManager.GetIDs(ctx, sourceName, circleci.SourceType)
 
 	circleSource := &circleci.Source{}
-	if err := circleSource.Init(ctx, "trufflehog - Circle CI", jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+	if err := circleSource.Init(ctx, "trufflehog - Circle CI", jobID, sourceID, true, &conn, e.initConcurrency(circleci.SourceType, 0)); err != nil {
 		return err
 	}
 	_, err = e.sourceManager.Run(ctx, sourceName, circleSource)
@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// ioBoundConcurrencyMultiplier is the number of units of network-bound sources
+// that are fetched concurrently per detection worker when concurrency is
+// auto-tuned. Fetching them mostly waits on the network, so running more of
+// them than there are CPUs keeps the detection workers busy.
+const ioBoundConcurrencyMultiplier = 4
+
+// ioBoundSources are the sources that fetch their content from remote APIs or
+// object stores, and can fetch several units at once. Other sources read local
+// files or repositories, or are fed their content, and are bound by the
+// detection workers. The Jenkins and Postman sources fetch their content one
+// unit at a time, so they aren't tuned.
+var ioBoundSources = map[sourcespb.SourceType]struct{}{
+	sourcespb.SourceType_SOURCE_TYPE_S3:            {},
+	sourcespb.SourceType_SOURCE_TYPE_GCS:           {},
+	sourcespb.SourceType_SOURCE_TYPE_GITHUB:        {},
+	sourcespb.SourceType_SOURCE_TYPE_GITLAB:        {},
+	sourcespb.SourceType_SOURCE_TYPE_CIRCLECI:      {},
+	sourcespb.SourceType_SOURCE_TYPE_TRAVISCI:      {},
+	sourcespb.SourceType_SOURCE_TYPE_CONFLUENCE:    {},
+	sourcespb.SourceType_SOURCE_TYPE_JIRA:          {},
+	sourcespb.SourceType_SOURCE_TYPE_ELASTICSEARCH: {},
+	sourcespb.SourceType_SOURCE_TYPE_DOCKER:        {},
+}
+
+// WithAutoTuneConcurrency sizes the workers after the machine and the type of
+// the sources when their number isn't set explicitly: detection defaults to
+// GOMAXPROCS workers, and network-bound sources fetch
+// ioBoundConcurrencyMultiplier times as many units concurrently.
+func WithAutoTuneConcurrency(autoTune bool) Option {
+	return func(e *Engine) {
+		e.autoTuneConcurrency = autoTune
+	}
+}
+
+// WithSourceConcurrency sets the number of units each source fetches
+// concurrently, regardless of its type. Zero defaults to the detection
+// concurrency, or to the auto-tuned concurrency if it's enabled.
+func WithSourceConcurrency(concurrency int) Option {
+	return func(e *Engine) {
+		e.sourceConcurrency = concurrency
+	}
+}
+
+// defaultConcurrency is the number of detection workers used if it isn't set.
+func defaultConcurrency() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// sourceUnitConcurrency returns the number of units a source of the given
+// type fetches concurrently.
+func (e *Engine) sourceUnitConcurrency(sourceType sourcespb.SourceType) int {
+	if e.sourceConcurrency > 0 {
+		return e.sourceConcurrency
+	}
+	if _, ok := ioBoundSources[sourceType]; ok && e.autoTuneConcurrency {
+		return e.concurrency * ioBoundConcurrencyMultiplier
+	}
+	return e.concurrency
+}
+
+// initConcurrency returns the concurrency a source that fetches its units on
+// its own, rather than through the source manager, is initialized with, which
+// bounds the units it fetches concurrently. configured is the concurrency set
+// in the source's config, which takes precedence if it's positive.
+func (e *Engine) initConcurrency(sourceType sourcespb.SourceType, configured int) int {
+	if configured > 0 {
+		return configured
+	}
+	return e.sourceUnitConcurrency(sourceType)
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+func TestSourceUnitConcurrency(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		sourceType sourcespb.SourceType
+		want       int
+	}{
+		{
+			name:       "defaults to the detection concurrency",
+			opts:       []Option{WithConcurrency(3)},
+			sourceType: sourcespb.SourceType_SOURCE_TYPE_S3,
+			want:       3,
+		},
+		{
+			name:       "auto-tuned network-bound source",
+			opts:       []Option{WithConcurrency(3), WithAutoTuneConcurrency(true)},
+			sourceType: sourcespb.SourceType_SOURCE_TYPE_S3,
+			want:       3 * ioBoundConcurrencyMultiplier,
+		},
+		{
+			name:       "auto-tuned local source",
+			opts:       []Option{WithConcurrency(3), WithAutoTuneConcurrency(true)},
+			sourceType: sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM,
+			want:       3,
+		},
+		{
+			name:       "explicit source concurrency",
+			opts:       []Option{WithConcurrency(3), WithAutoTuneConcurrency(true), WithSourceConcurrency(5)},
+			sourceType: sourcespb.SourceType_SOURCE_TYPE_S3,
+			want:       5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := new(Engine)
+			for _, opt := range tt.opts {
+				opt(e)
+			}
+			assert.Equal(t, tt.want, e.sourceUnitConcurrency(tt.sourceType))
+		})
+	}
+}
+
+func TestEngine_AutoTuneConcurrency(t *testing.T) {
+	ctx := context.Background()
+	e, err := Start(ctx,
+		WithDetectors(fakeDetectorV1{}),
+		WithVerify(false),
+		WithPrinter(new(discardPrinter)),
+		WithAutoTuneConcurrency(true),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, e.Finish(ctx))
+
+	assert.Equal(t, runtime.GOMAXPROCS(0), e.concurrency)
+	assert.Equal(t, runtime.GOMAXPROCS(0)*ioBoundConcurrencyMultiplier, e.sourceUnitConcurrency(sourcespb.SourceType_SOURCE_TYPE_GITHUB))
+}
+
+func TestInitConcurrency(t *testing.T) {
+	e := new(Engine)
+	for _, opt := range []Option{WithConcurrency(3), WithAutoTuneConcurrency(true)} {
+		opt(e)
+	}
+
+	// Sources that fetch their units on their own are tuned too, unless their config sets it.
+	assert.Equal(t, 3*ioBoundConcurrencyMultiplier, e.initConcurrency(sourcespb.SourceType_SOURCE_TYPE_S3, 0))
+	assert.Equal(t, 3*ioBoundConcurrencyMultiplier, e.initConcurrency(sourcespb.SourceType_SOURCE_TYPE_GITHUB, 0))
+	assert.Equal(t, 7, e.initConcurrency(sourcespb.SourceType_SOURCE_TYPE_S3, 7))
+	assert.Equal(t, 3, e.initConcurrency(sourcespb.SourceType_SOURCE_TYPE_POSTMAN, 0))
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"runtime"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/confluence"
+)
+
+// ScanConfluence scans the pages and attachments of a Confluence instance.
+func (e *Engine) ScanConfluence(ctx context.Context, c sources.ConfluenceConfig) error {
+	connection := &sourcespb.Confluence{
+		Endpoint:              c.Endpoint,
+		Spaces:                c.Spaces,
+		IgnoreSpaces:          c.IgnoreSpaces,
+		IncludeAttachments:    c.IncludeAttachments,
+		InsecureSkipVerifyTls: c.InsecureSkipVerifyTLS,
+	}
+	switch {
+	case c.Token != "":
+		connection.Credential = &sourcespb.Confluence_Token{Token: c.Token}
+	case c.Username != "" || c.Password != "":
+		connection.Credential = &sourcespb.Confluence_BasicAuth{
+			BasicAuth: &credentialspb.BasicAuth{
+				Username: c.Username,
+				Password: c.Password,
+			},
+		}
+	default:
+		connection.Credential = &sourcespb.Confluence_Unauthenticated{
+			Unauthenticated: &credentialspb.Unauthenticated{},
+		}
+	}
+	if !c.ModifiedSince.IsZero() {
+		connection.ModifiedSince = timestamppb.New(c.ModifiedSince)
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		ctx.Logger().Error(err, "failed to marshal Confluence connection")
+		return err
+	}
+
+	sourceName := "trufflehog - Confluence"
+	sourceID, jobID, _ := e.sourceManager.GetIDs(ctx, sourceName, confluence.SourceType)
+
+	confluenceSource := &confluence.Source{}
+	if err := confluenceSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+		return err
+	}
+	_, err = e.sourceManager.Run(ctx, sourceName, confluenceSource)
+	return err
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// resultAggregator collapses results that share a detector type and raw
+// secret into a single result listing every location the secret was found.
+type resultAggregator struct {
+	mu      sync.Mutex
+	order   []string
+	results map[string]*detectors.ResultWithMetadata
+	// trackers hold the units the results were found in back from being
+	// checkpointed until the results are reported.
+	trackers []*sources.ChunkTracker
+}
+
+func newResultAggregator() *resultAggregator {
+	return &resultAggregator{results: make(map[string]*detectors.ResultWithMetadata)}
+}
+
+// normalizeRaw strips the surrounding whitespace and quotes that commonly
+// differ between copies of the same secret.
+func normalizeRaw(raw []byte) []byte {
+	return bytes.Trim(bytes.TrimSpace(raw), "\"'`")
+}
+
+// add records r as an occurrence of its secret.
+func (a *resultAggregator) add(r detectors.ResultWithMetadata) {
+	key := r.DetectorType.String() + ":" + string(normalizeRaw(r.Raw))
+	location := detectors.ResultLocation{
+		SourceMetadata: r.SourceMetadata,
+		SourceID:       r.SourceID,
+		SourceType:     r.SourceType,
+		SourceName:     r.SourceName,
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if r.Tracker != nil {
+		r.Tracker.Add()
+		a.trackers = append(a.trackers, r.Tracker)
+	}
+
+	existing, ok := a.results[key]
+	if !ok {
+		r.Locations = []detectors.ResultLocation{location}
+		a.results[key] = &r
+		a.order = append(a.order, key)
+		return
+	}
+
+	existing.Locations = append(existing.Locations, location)
+	// Prefer the outcome of a successful verification over an unverified one.
+	if r.Verified && !existing.Verified {
+		existing.Result = r.Result
+	}
+}
+
+// aggregated returns one result per distinct secret, in the order the
+// secrets were first found.
+func (a *resultAggregator) aggregated() []*detectors.ResultWithMetadata {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	results := make([]*detectors.ResultWithMetadata, 0, len(a.order))
+	for _, key := range a.order {
+		results = append(results, a.results[key])
+	}
+	return results
+}
+
+// release releases the checkpoint trackers of the results once the
+// aggregated results have been reported.
+func (a *resultAggregator) release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, t := range a.trackers {
+		t.Done()
+	}
+	a.trackers = nil
+}
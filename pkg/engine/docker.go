@@ -1,8 +1,6 @@
 package engine
 
 import (
-	"runtime"
-
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
@@ -36,7 +34,7 @@ func (e *Engine) ScanDocker(ctx context.Context, c sources.DockerConfig) error {
 	sourceID, jobID, _ := e.sourceManager.GetIDs(ctx, sourceName, docker.SourceType)
 
 	dockerSource := &docker.Source{}
-	if err := dockerSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+	if err := dockerSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, e.initConcurrency(docker.SourceType, 0)); err != nil {
 		return err
 	}
 	_, err = e.sourceManager.Run(ctx, sourceName, dockerSource)
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// EnumeratedUnit is a source unit that was enumerated during a dry run.
+type EnumeratedUnit struct {
+	SourceName string
+	ID         string
+	Kind       sources.SourceUnitKind
+	Display    string
+}
+
+// dryRunHook records the units and errors reported by sources during a dry
+// run.
+type dryRunHook struct {
+	sources.NoopHook
+
+	mu     sync.Mutex
+	units  []EnumeratedUnit
+	errors []error
+}
+
+func (h *dryRunHook) ReportUnit(ref sources.JobProgressRef, unit sources.SourceUnit) {
+	id, kind := unit.SourceUnitID()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.units = append(h.units, EnumeratedUnit{
+		SourceName: ref.SourceName,
+		ID:         id,
+		Kind:       kind,
+		Display:    unit.Display(),
+	})
+}
+
+func (h *dryRunHook) ReportError(ref sources.JobProgressRef, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errors = append(h.errors, fmt.Errorf("%s: %w", ref.SourceName, err))
+}
+
+// EnabledDetectors returns the IDs of the detectors the engine runs, after
+// any filtering, sorted by type and version.
+func (e *Engine) EnabledDetectors() []config.DetectorID {
+	ids := make([]config.DetectorID, 0, len(e.detectors))
+	for _, d := range e.detectors {
+		ids = append(ids, config.GetDetectorID(d))
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].ID != ids[j].ID {
+			return ids[i].ID < ids[j].ID
+		}
+		return ids[i].Version < ids[j].Version
+	})
+	return ids
+}
+
+// EnabledCategories returns the categories of the detectors the engine runs,
+// by detector ID. Detectors sharing an ID, like custom detectors, have the
+// categories of all of them.
+func (e *Engine) EnabledCategories() map[config.DetectorID][]detectors.Category {
+	categories := make(map[config.DetectorID][]detectors.Category, len(e.detectors))
+	for _, d := range e.detectors {
+		id := config.GetDetectorID(d)
+		for _, c := range detectors.CategoriesOf(d) {
+			if !slices.Contains(categories[id], c) {
+				categories[id] = append(categories[id], c)
+			}
+		}
+	}
+	return categories
+}
+
+// EnumeratedUnits returns the source units reported during a dry run. It
+// should be called after Finish.
+func (e *Engine) EnumeratedUnits() []EnumeratedUnit {
+	if e.dryRunHook == nil {
+		return nil
+	}
+	e.dryRunHook.mu.Lock()
+	defer e.dryRunHook.mu.Unlock()
+	return append([]EnumeratedUnit(nil), e.dryRunHook.units...)
+}
+
+// EnumerationErrors returns the errors sources reported during a dry run. It
+// should be called after Finish.
+func (e *Engine) EnumerationErrors() []error {
+	if e.dryRunHook == nil {
+		return nil
+	}
+	e.dryRunHook.mu.Lock()
+	defer e.dryRunHook.mu.Unlock()
+	return append([]error(nil), e.dryRunHook.errors...)
+}
@@ -1,8 +1,6 @@
 package engine
 
 import (
-	"runtime"
-
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
@@ -38,7 +36,7 @@ func (e *Engine) ScanElasticsearch(ctx context.Context, c sources.ElasticsearchC
 	sourceID, jobID, _ := e.sourceManager.GetIDs(ctx, sourceName, elasticsearch.SourceType)
 
 	elasticsearchSource := &elasticsearch.Source{}
-	if err := elasticsearchSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+	if err := elasticsearchSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, e.initConcurrency(elasticsearch.SourceType, 0)); err != nil {
 		return err
 	}
 	_, err = e.sourceManager.Run(ctx, sourceName, elasticsearchSource)
@@ -2,11 +2,15 @@ package engine
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -17,12 +21,14 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common/glob"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/giturl"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
@@ -41,7 +47,20 @@ type Metrics struct {
 	ChunksScanned          uint64
 	VerifiedSecretsFound   uint64
 	UnverifiedSecretsFound uint64
+	// SuppressedSecretsFound is the number of results suppressed by the
+	// allowlist, which aren't counted as verified or unverified.
+	SuppressedSecretsFound uint64
 	AvgDetectorTime        map[string]time.Duration
+	// ScanIncomplete is set if sources were stopped because the scan reached
+	// its maximum duration, or sources of a manifest failed to start, so not
+	// all of the content was scanned.
+	ScanIncomplete bool
+	// ResultsLimitReached is set if the scan was stopped because the limit of
+	// results set with WithResultsLimit was reached.
+	ResultsLimitReached bool
+	// SkippedUnits is the number of units of sources that failed and were
+	// skipped, with WithContinueOnSourceError.
+	SkippedUnits uint64
 
 	scanStartTime time.Time
 	ScanDuration  time.Duration
@@ -55,17 +74,54 @@ type runtimeMetrics struct {
 }
 
 // Printer is used to format found results and output them to the user. Ex JSON, plain text, etc.
-// Please note printer implementations SHOULD BE thread safe.
+// Printers aren't limited to writing to a terminal: library users can set any implementation
+// with WithPrinter to send results to their own destination, such as a backend service.
+//
+// Print is called once per reported result, from multiple goroutines concurrently, so printer
+// implementations MUST BE thread safe. It isn't called once Finish has flushed the printer.
 type Printer interface {
 	Print(ctx context.Context, r *detectors.ResultWithMetadata) error
 }
 
+// FlushPrinter is a Printer that buffers results, or holds resources such as a connection,
+// until the scan is done. Flush is called once by Finish, after the last call to Print, even
+// if no result was reported. Its error is logged.
+type FlushPrinter interface {
+	Printer
+	Flush() error
+}
+
+// IncompletePrinter is a Printer that reports whether all the content was scanned.
+// MarkIncomplete is called by Finish, before Flush, when the scan stopped early.
+type IncompletePrinter interface {
+	Printer
+	MarkIncomplete(reason string)
+}
+
+var (
+	_ Printer           = (*output.PlainPrinter)(nil)
+	_ Printer           = (*output.JSONPrinter)(nil)
+	_ Printer           = (*output.LegacyJSONPrinter)(nil)
+	_ Printer           = (*output.GitHubActionsPrinter)(nil)
+	_ FlushPrinter      = (*output.SARIFPrinter)(nil)
+	_ IncompletePrinter = (*output.SARIFPrinter)(nil)
+	_ IncompletePrinter = (*output.JSONPrinter)(nil)
+)
+
 type Engine struct {
 	// CLI flags.
 	concurrency     int
 	decoders        []decoders.Decoder
 	detectors       []detectors.Detector
+	detectorConfigs []func(detectors.Detector) error
 	jobReportWriter io.WriteCloser
+	// sourceConcurrency overrides the number of units each source fetches
+	// concurrently, which autoTuneConcurrency raises for network-bound sources.
+	sourceConcurrency   int
+	autoTuneConcurrency bool
+	// decoderTypes is an ordered list of decoders to build. If empty, the
+	// decoders field (or the default decoders) is used.
+	decoderTypes []detectorspb.DecoderType
 	// filterUnverified is used to reduce the number of unverified results.
 	// If there are multiple unverified results for the same chunk for the same detector,
 	// only the first one will be kept.
@@ -78,9 +134,69 @@ type Engine struct {
 	logFilteredUnverified   bool
 	verificationOverlap     bool
 	printAvgDetectorTime    bool
+	// detectorTimeout bounds the time a detector may spend on a single match,
+	// including verification. detectorTimeouts overrides it per detector type.
+	detectorTimeout  time.Duration
+	detectorTimeouts map[detectorspb.DetectorType]time.Duration
+	// maxScanDuration stops the sources once the scan has run for this long.
+	// Zero means no limit.
+	maxScanDuration time.Duration
+	// incompleteReason explains why the scan is marked incomplete, if it is.
+	incompleteReason string
+	// continueOnSourceError skips the units of sources that fail instead of
+	// failing their source.
+	continueOnSourceError bool
 	// By default, the engine will only scan a subset of the chunk if a detector matches the chunk.
 	// If this flag is set to true, the engine will scan the entire chunk.
 	scanEntireChunk bool
+	// maxArchiveDepth bounds the recursion depth of nested archive extraction.
+	// A zero value keeps the archive handler's default.
+	maxArchiveDepth int
+	// archiveEntryGlobs restricts the archive entries that are scanned to
+	// those whose path matches one of the globs, if set.
+	archiveEntryGlobs []string
+	// scanExtensions restricts the archive entries that are scanned to those
+	// with one of the extensions, if set.
+	scanExtensions []string
+	// chunkOverlap is the number of bytes consecutive chunks overlap by. It's
+	// raised to the longest secret the detectors can match if that's larger.
+	chunkOverlap int
+	// resumeFile is the path of the checkpoint used to resume interrupted scans.
+	resumeFile   string
+	resumeConfig []string
+	checkpoint   *sources.Checkpoint
+	// dryRun only initializes sources and enumerates their units without
+	// reading any chunk content.
+	dryRun     bool
+	dryRunHook *dryRunHook
+	// dedupResults collapses results with the same detector type and raw
+	// secret into a single result that lists every location it was found.
+	dedupResults     bool
+	resultAggregator *resultAggregator
+	// sourceStatsHook collects per-source statistics, which are logged when
+	// the scan finishes.
+	sourceStatsHook *sourceStatsHook
+	// resultSummarizer counts the reported results by detector.
+	resultSummarizer *resultSummarizer
+	// chunkBufferSize is the number of chunks buffered between the sources
+	// and the detector workers.
+	chunkBufferSize int
+	// contextLines is the number of lines around each result included,
+	// redacted, in its ExtraData. Zero disables it.
+	contextLines int
+	// progressWriter receives a line with the progress of the scan every
+	// progressInterval, if set.
+	progressWriter   io.Writer
+	progressInterval time.Duration
+	progress         *progressReporter
+	// allowlist suppresses the results of known or accepted secrets, which
+	// are printed, marked as suppressed, only if showSuppressed is set.
+	allowlist      *Allowlist
+	showSuppressed bool
+	// pathAnonymizer replaces the paths and URLs of printed results by tokens, if set.
+	pathAnonymizer *pathAnonymizer
+	// resultsLimiter stops the scan once enough results are found, if set.
+	resultsLimiter *resultsLimiter
 
 	// ahoCorasickHandler manages the Aho-Corasick trie and related keyword lookups.
 	ahoCorasickCore *ahocorasick.Core
@@ -104,6 +220,8 @@ type Engine struct {
 	// The specific implementation (e.g., JSON, plain text)
 	// should be set during initialization based on user preference or program requirements.
 	printer Printer
+	// resultHooks are called, in order, with every result after it's printed.
+	resultHooks []ResultHook
 
 	// dedupeCache is used to deduplicate results by comparing the
 	// detector type, raw result, and source metadata
@@ -111,6 +229,19 @@ type Engine struct {
 
 	// verify determines whether the scanner will attempt to verify candidate secrets
 	verify bool
+	// captureVerificationResponses stores a truncated copy of the HTTP response used to verify
+	// each verified result in its ExtraData.
+	captureVerificationResponses bool
+	// verificationRetries configures how verification requests that fail
+	// transiently are retried. It's only applied if set.
+	verificationRetries *common.RetryPolicy
+	// verificationRateLimits overrides the requests per second allowed to each
+	// host by verification requests. It's only applied if set.
+	verificationRateLimits map[string]float64
+	// verificationCache reuses the verification outcome of secrets found more
+	// than once. It's nil if verificationCacheEnabled is unset.
+	verificationCacheEnabled bool
+	verificationCache        *verificationCache
 
 	// Note: bad hack only used for testing
 	verificationOverlapTracker *verificationOverlapTracker
@@ -156,6 +287,15 @@ func WithDecoders(decoders ...decoders.Decoder) Option {
 	}
 }
 
+// WithDecoderTypes configures the engine to run only the given decoders, in
+// the given order. Unknown or duplicate types cause Start to fail. If no types
+// are provided, the decoders set by WithDecoders or the defaults are used.
+func WithDecoderTypes(decoderTypes ...detectorspb.DecoderType) Option {
+	return func(e *Engine) {
+		e.decoderTypes = decoderTypes
+	}
+}
+
 // WithFilterUnverified sets the filterUnverified flag on the engine. If set to
 // true, the engine will only return the first unverified result for a chunk for a detector.
 func WithFilterUnverified(filter bool) Option {
@@ -220,6 +360,15 @@ func WithFilterDetectors(filterFunc func(detectors.Detector) bool) Option {
 	}
 }
 
+// WithDetectorConfig configures the detectors the engine scans with. The
+// configure func is called with every detector once the list of detectors is
+// final, and the engine fails to start if it returns an error.
+func WithDetectorConfig(configure func(detectors.Detector) error) Option {
+	return func(e *Engine) {
+		e.detectorConfigs = append(e.detectorConfigs, configure)
+	}
+}
+
 // WithPrinter sets the Printer on the engine.
 func WithPrinter(printer Printer) Option {
 	return func(e *Engine) {
@@ -259,11 +408,169 @@ func filterDetectors(filterFunc func(detectors.Detector) bool, input []detectors
 	return out
 }
 
+// WithMaxScanDuration stops the scan once it has run for duration. The sources
+// still running are cancelled and no new sources are started, but the chunks
+// they already produced are scanned and their results are reported, so the
+// output is valid. Metrics.ScanIncomplete records whether the limit was hit.
+func WithMaxScanDuration(duration time.Duration) Option {
+	return func(e *Engine) { e.maxScanDuration = duration }
+}
+
+// WithContinueOnSourceError skips the repositories, buckets and other units of
+// sources that fail to be enumerated or scanned, such as deleted repositories or
+// repositories the credentials can't read, instead of failing their source.
+// Their errors are logged as they happen and summarized by source when the scan
+// finishes, and Metrics.SkippedUnits counts them.
+func WithContinueOnSourceError(enabled bool) Option {
+	return func(e *Engine) { e.continueOnSourceError = enabled }
+}
+
+// WithDetectorTimeout sets the maximum time a detector may spend finding and
+// verifying the secrets of a single match. Results whose verification does not
+// finish in time are reported as unverified with a verification error.
+func WithDetectorTimeout(timeout time.Duration) Option {
+	return func(e *Engine) { e.detectorTimeout = timeout }
+}
+
+// WithDetectorTimeouts overrides the detector timeout for specific detector types.
+func WithDetectorTimeouts(timeouts map[detectorspb.DetectorType]time.Duration) Option {
+	return func(e *Engine) { e.detectorTimeouts = timeouts }
+}
+
 // WithEntireChunkScan sets the flag to configure AhoCorasickCore to scan entire chunks.
 func WithEntireChunkScan(enabled bool) Option {
 	return func(e *Engine) { e.scanEntireChunk = enabled }
 }
 
+// WithMaxArchiveDepth sets the maximum depth of nested archives that will be extracted.
+// Extraction halts once the depth is exceeded, which protects against zip-bomb style recursion.
+func WithMaxArchiveDepth(depth int) Option {
+	return func(e *Engine) { e.maxArchiveDepth = depth }
+}
+
+// WithArchiveEntryGlobs restricts the archive entries that are scanned to those whose
+// path in their archive matches one of the globs. Other entries are skipped without
+// being read, except for nested archives, whose entries are filtered in turn.
+func WithArchiveEntryGlobs(globs ...string) Option {
+	return func(e *Engine) { e.archiveEntryGlobs = append(e.archiveEntryGlobs, globs...) }
+}
+
+// WithScanExtensions restricts the archive entries that are scanned to those whose
+// extension is one of extensions, matched case-insensitively with or without a
+// leading dot. Like with WithArchiveEntryGlobs, nested archives are still extracted.
+// Files of the filesystem source are filtered with sources.FilesystemConfig.ScanExtensions.
+func WithScanExtensions(extensions ...string) Option {
+	return func(e *Engine) { e.scanExtensions = append(e.scanExtensions, extensions...) }
+}
+
+// WithChunkOverlap sets the number of bytes consecutive chunks overlap by, so that
+// secrets crossing a chunk boundary are found. The overlap is never smaller than
+// sources.PeekSize or the longest secret the configured detectors can match.
+// Every chunk holds its overlap on top of its data, so a larger overlap uses more
+// memory and scans more data twice.
+func WithChunkOverlap(size int) Option {
+	return func(e *Engine) { e.chunkOverlap = size }
+}
+
+// WithVerificationResponses configures the engine to store the status and a truncated
+// body of the HTTP response that verified a result in the result's ExtraData under the
+// "verification_response" key. Response bodies may contain sensitive data, so this is
+// disabled by default. Results verified by non-HTTP means are left unchanged.
+func WithVerificationResponses(enabled bool) Option {
+	return func(e *Engine) {
+		e.captureVerificationResponses = enabled
+	}
+}
+
+// WithVerificationRetries configures the engine to retry verification requests
+// that fail with a connection error or a 429 or 5xx response, up to maxAttempts
+// attempts in total. The delay before a retry starts at baseDelay and doubles
+// with each retry. Responses such as 401 and 403 are never retried. The policy
+// applies to the HTTP clients detectors create with common.SaneHttpClient.
+func WithVerificationRetries(maxAttempts int, baseDelay time.Duration) Option {
+	return func(e *Engine) {
+		e.verificationRetries = &common.RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: baseDelay}
+	}
+}
+
+// WithVerificationRateLimits overrides the requests per second that verification
+// requests may make to specific hosts, in addition to the limits in
+// common.DefaultVerificationRateLimits. A limit of zero removes a host's limit.
+// Each detector's requests to a host are limited separately, and the limits are
+// shared by every worker.
+func WithVerificationRateLimits(limits map[string]float64) Option {
+	return func(e *Engine) { e.verificationRateLimits = limits }
+}
+
+// WithResumeFile records fully scanned source units to a checkpoint file at
+// path and skips units already recorded there. The checkpoint is invalidated
+// if the configured detectors or the provided config values differ from the
+// run that wrote it.
+func WithResumeFile(path string, config ...string) Option {
+	return func(e *Engine) {
+		e.resumeFile = path
+		e.resumeConfig = config
+	}
+}
+
+// WithDryRun configures the engine to only initialize sources and enumerate
+// their units without scanning any content. The enumerated units are
+// available from EnumeratedUnits once the engine finishes.
+func WithDryRun(dryRun bool) Option {
+	return func(e *Engine) { e.dryRun = dryRun }
+}
+
+// WithProgress configures the engine to write the progress of the scan to w
+// every interval, and once more when the scan finishes. The progress includes
+// the percentage of units scanned and an ETA once every source has enumerated
+// its units, or the number of units scanned and the rate otherwise.
+func WithProgress(w io.Writer, interval time.Duration) Option {
+	return func(e *Engine) {
+		e.progressWriter = w
+		e.progressInterval = interval
+	}
+}
+
+// WithDedupResults configures the engine to report each distinct secret once
+// per detector type, with the locations of all of its occurrences, instead of
+// reporting every occurrence separately. Results are reported once the scan
+// finishes.
+func WithDedupResults(dedup bool) Option {
+	return func(e *Engine) { e.dedupResults = dedup }
+}
+
+// WithConcurrentChunkBuffer sets the number of chunks that can be buffered
+// between the sources and the detector workers. When the buffer is full,
+// sources block until a worker takes a chunk, which bounds the memory held by
+// chunks waiting to be scanned. Every detector worker (50 per unit of
+// concurrency) reads from this buffer, so a buffer much larger than the
+// number of workers only helps absorb bursts from the sources. Sizes smaller
+// than 1 use the default of 64.
+func WithConcurrentChunkBuffer(size int) Option {
+	return func(e *Engine) { e.chunkBufferSize = size }
+}
+
+// checkpointConfigHash returns a hash identifying the scan configuration a
+// checkpoint is valid for.
+func (e *Engine) checkpointConfigHash() string {
+	dets := make([]string, 0, len(e.detectors))
+	for _, d := range e.detectors {
+		id := d.Type().String()
+		if v, ok := d.(detectors.Versioner); ok {
+			id = fmt.Sprintf("%s.v%d", id, v.Version())
+		}
+		dets = append(dets, id)
+	}
+	sort.Strings(dets)
+
+	h := sha256.New()
+	for _, part := range append(dets, e.resumeConfig...) {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // HasFoundResults returns true if any results are found.
 func (e *Engine) HasFoundResults() bool {
 	return atomic.LoadUint32(&e.numFoundResults) > 0
@@ -352,10 +659,18 @@ func Start(ctx context.Context, options ...Option) (*Engine, error) {
 	if err := e.initialize(ctx, options...); err != nil {
 		return nil, err
 	}
-	e.initSourceManager(ctx)
 	e.setDefaults(ctx)
+	if err := e.configureDetectors(); err != nil {
+		return nil, err
+	}
+	e.setChunkOverlap(ctx)
+	e.initSourceManager(ctx)
 	e.sanityChecks(ctx)
 	e.startWorkers(ctx)
+	if e.progress != nil {
+		e.progress.start = time.Now()
+		e.progress.run()
+	}
 
 	return e, nil
 }
@@ -394,6 +709,7 @@ func (e *Engine) initialize(ctx context.Context, options ...Option) error {
 	e.notifyVerifiedResults = true
 	e.notifyUnknownResults = true
 	e.notifyUnverifiedResults = true
+	e.verificationCacheEnabled = true
 	e.verificationOverlapChunksChan = make(
 		chan verificationOverlapChunk, defaultChannelBuffer*verificationOverlapChunksChanMultiplier,
 	)
@@ -407,6 +723,55 @@ func (e *Engine) initialize(ctx context.Context, options ...Option) error {
 	}
 	ctx.Logger().V(4).Info("engine initialized")
 
+	if e.dedupResults {
+		e.resultAggregator = newResultAggregator()
+	}
+	e.sourceStatsHook = newSourceStatsHook()
+	e.resultSummarizer = newResultSummarizer()
+
+	if e.maxArchiveDepth > 0 {
+		handlers.SetArchiveMaxDepth(e.maxArchiveDepth)
+	}
+	if len(e.archiveEntryGlobs) > 0 {
+		filter, err := glob.NewGlobFilter(glob.WithIncludeGlobs(e.archiveEntryGlobs...))
+		if err != nil {
+			return fmt.Errorf("invalid archive entry glob: %w", err)
+		}
+		handlers.SetArchiveEntryFilter(filter)
+	}
+	if len(e.scanExtensions) > 0 {
+		handlers.SetScanExtensions(common.NewExtensionSet(e.scanExtensions...))
+	}
+
+	if e.verificationRetries != nil {
+		common.SetVerificationRetryPolicy(*e.verificationRetries)
+	}
+	if e.verificationRateLimits != nil {
+		common.SetVerificationRateLimits(e.verificationRateLimits)
+	}
+
+	if e.verificationCacheEnabled {
+		if e.verificationCache, err = newVerificationCache(verificationCacheSize); err != nil {
+			return fmt.Errorf("failed to initialize verification cache: %w", err)
+		}
+	}
+
+	if len(e.decoderTypes) > 0 {
+		decs, err := decoders.DecodersFromTypes(e.decoderTypes...)
+		if err != nil {
+			return fmt.Errorf("invalid decoder configuration: %w", err)
+		}
+		e.decoders = decs
+	}
+
+	if e.resumeFile != "" {
+		cp, err := sources.LoadCheckpoint(ctx, e.resumeFile, e.checkpointConfigHash())
+		if err != nil {
+			return fmt.Errorf("failed to load checkpoint: %w", err)
+		}
+		e.checkpoint = cp
+	}
+
 	// Configure the EntireChunkSpanCalculator if the engine is set to scan the entire chunk.
 	var ahoCOptions []ahocorasick.CoreOption
 	if e.scanEntireChunk {
@@ -423,11 +788,39 @@ func (e *Engine) initialize(ctx context.Context, options ...Option) error {
 func (e *Engine) initSourceManager(ctx context.Context) {
 	const defaultOutputBufferSize = 64
 
+	bufferSize := e.chunkBufferSize
+	if bufferSize < 1 {
+		bufferSize = defaultOutputBufferSize
+	}
 	opts := []func(*sources.SourceManager){
 		sources.WithConcurrentSources(e.concurrency),
-		sources.WithConcurrentUnits(e.concurrency),
+		sources.WithConcurrentUnitsFunc(e.sourceUnitConcurrency),
 		sources.WithSourceUnits(),
-		sources.WithBufferedOutput(defaultOutputBufferSize),
+		sources.WithBufferedOutput(bufferSize),
+		sources.WithReportHook(e.sourceStatsHook),
+		sources.WithChunkOverlap(e.chunkOverlap),
+	}
+	if e.checkpoint != nil {
+		opts = append(opts, sources.WithCheckpoint(e.checkpoint))
+	}
+	if e.maxScanDuration > 0 {
+		opts = append(opts, sources.WithDeadline(e.metrics.scanStartTime.Add(e.maxScanDuration)))
+	}
+	if e.continueOnSourceError {
+		opts = append(opts, sources.WithContinueOnSourceError())
+	}
+	if e.dryRun {
+		e.dryRunHook = new(dryRunHook)
+		opts = append(opts, sources.WithEnumerationOnly(), sources.WithReportHook(e.dryRunHook))
+	}
+	if e.progressWriter != nil && e.progressInterval > 0 {
+		e.progress = &progressReporter{
+			hook:     new(progressHook),
+			w:        e.progressWriter,
+			interval: e.progressInterval,
+			done:     make(chan struct{}),
+		}
+		opts = append(opts, sources.WithReportHook(e.progress.hook))
 	}
 	if e.jobReportWriter != nil {
 		unitHook, finishedMetrics := sources.NewUnitHook(ctx)
@@ -468,11 +861,14 @@ func (e *Engine) initSourceManager(ctx context.Context) {
 // incomplete configuration.
 func (e *Engine) setDefaults(ctx context.Context) {
 	if e.concurrency == 0 {
-		numCPU := runtime.NumCPU()
-		ctx.Logger().Info("No concurrency specified, defaulting to max", "cpu", numCPU)
-		e.concurrency = numCPU
+		e.concurrency = defaultConcurrency()
+		if e.autoTuneConcurrency {
+			ctx.Logger().V(2).Info("auto-tuned concurrency", "gomaxprocs", e.concurrency)
+		} else {
+			ctx.Logger().Info("No concurrency specified, defaulting to max", "gomaxprocs", e.concurrency)
+		}
 	}
-	ctx.Logger().V(3).Info("engine started", "workers", e.concurrency)
+	ctx.Logger().V(3).Info("engine started", "workers", e.concurrency, "source_workers", e.sourceConcurrency)
 
 	// Default decoders handle common encoding formats.
 	if len(e.decoders) == 0 {
@@ -482,9 +878,38 @@ func (e *Engine) setDefaults(ctx context.Context) {
 	if len(e.detectors) == 0 {
 		e.detectors = DefaultDetectors()
 	}
+
+	if e.detectorTimeout <= 0 {
+		e.detectorTimeout = defaultDetectorTimeout
+	}
 	ctx.Logger().V(4).Info("default engine options set")
 }
 
+// configureDetectors applies the configuration set with WithDetectorConfig.
+func (e *Engine) configureDetectors() error {
+	for _, configure := range e.detectorConfigs {
+		for _, d := range e.detectors {
+			if err := configure(d); err != nil {
+				return fmt.Errorf("failed to configure detector %s: %w", d.Type(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// setChunkOverlap makes consecutive chunks overlap by at least the size of the
+// longest secret the detectors can match, so that it's whole in one of them.
+func (e *Engine) setChunkOverlap(ctx context.Context) {
+	overlap := max(e.chunkOverlap, sources.PeekSize)
+	for _, d := range e.detectors {
+		if provider, ok := d.(detectors.MaxSecretSizeProvider); ok {
+			overlap = max(overlap, int(provider.MaxSecretSize()))
+		}
+	}
+	e.chunkOverlap = overlap
+	ctx.Logger().V(4).Info("chunk overlap set", "bytes", overlap)
+}
+
 // Sanity check detectors for duplicate configuration. Only log in case
 // a detector has been configured in a way that isn't represented by
 // the DetectorID (type and version).
@@ -561,6 +986,14 @@ func (e *Engine) startWorkers(ctx context.Context) {
 	}
 }
 
+// markIncomplete marks the scan incomplete. The first reason is kept.
+func (e *Engine) markIncomplete(reason string) {
+	if !e.metrics.ScanIncomplete {
+		e.metrics.ScanIncomplete = true
+		e.incompleteReason = reason
+	}
+}
+
 // Finish waits for running sources to complete and workers to finish scanning
 // chunks before closing their respective channels. Once Finish is called, no
 // more sources may be scanned by the engine.
@@ -568,6 +1001,14 @@ func (e *Engine) Finish(ctx context.Context) error {
 	defer common.RecoverWithExit(ctx)
 	// Wait for the sources to finish putting chunks onto the chunks channel.
 	err := e.sourceManager.Wait()
+	if e.progress != nil {
+		e.progress.stop()
+	}
+	if e.sourceManager.DeadlineExceeded() {
+		e.markIncomplete(sources.ErrDeadlineExceeded.Error())
+		ctx.Logger().Info("scan stopped at the maximum scan duration, not all content was scanned",
+			"max_scan_duration", e.maxScanDuration.String())
+	}
 
 	e.workersWg.Wait() // Wait for the workers to finish scanning chunks.
 
@@ -579,9 +1020,56 @@ func (e *Engine) Finish(ctx context.Context) error {
 
 	close(e.results)    // Detector workers are done, close the results channel and call it a day.
 	e.WgNotifier.Wait() // Wait for the notifier workers to finish notifying results.
+	e.metrics.ResultsLimitReached = e.resultsLimiter != nil && e.resultsLimiter.reached()
+
+	// Deduplicated results are only complete once every result has been notified.
+	if e.resultAggregator != nil {
+		for _, r := range e.resultAggregator.aggregated() {
+			e.printResult(ctx, r)
+		}
+		e.resultAggregator.release()
+	}
+
+	// Printers that buffer results, such as SARIF, write them once all results are known.
+	if marker, ok := e.printer.(IncompletePrinter); ok && e.metrics.ScanIncomplete {
+		marker.MarkIncomplete(e.incompleteReason)
+	}
+	if flusher, ok := e.printer.(FlushPrinter); ok {
+		if flushErr := flusher.Flush(); flushErr != nil {
+			ctx.Logger().Error(flushErr, "error flushing printer")
+		}
+	}
+
+	if e.checkpoint != nil {
+		if flushErr := e.checkpoint.Flush(); flushErr != nil {
+			ctx.Logger().Error(flushErr, "error writing checkpoint")
+		}
+	}
 
 	e.metrics.ScanDuration = time.Since(e.metrics.scanStartTime)
 
+	for _, stats := range e.SourceStats() {
+		e.metrics.SkippedUnits += stats.SkippedUnits
+		ctx.Logger().Info("finished scanning source",
+			"source_name", stats.SourceName,
+			"source_id", stats.SourceID,
+			"units", stats.Units,
+			"skipped_units", stats.SkippedUnits,
+			"chunks", stats.Chunks,
+			"bytes", stats.Bytes,
+			"results", stats.Results,
+			"elapsed", stats.Elapsed.String(),
+		)
+		if stats.SkippedUnits > 0 {
+			ctx.Logger().Info("skipped units of the source that failed",
+				"source_name", stats.SourceName,
+				"source_id", stats.SourceID,
+				"skipped_units", stats.SkippedUnits,
+				"errors", stats.SkippedErrors,
+			)
+		}
+	}
+
 	return err
 }
 
@@ -606,6 +1094,9 @@ type detectableChunk struct {
 	chunk    sources.Chunk
 	decoder  detectorspb.DecoderType
 	wgDoneFn func()
+	// tracker is the checkpoint tracker of the chunk, if any. It holds a reference
+	// until the chunk has been detected.
+	tracker *sources.ChunkTracker
 }
 
 // verificationOverlapChunk is a decoded chunk that has multiple detectors that match it.
@@ -616,6 +1107,7 @@ type verificationOverlapChunk struct {
 	decoder                     detectorspb.DecoderType
 	detectors                   []*ahocorasick.DetectorMatch
 	verificationOverlapWgDoneFn func()
+	tracker                     *sources.ChunkTracker
 }
 
 func (e *Engine) detectorWorker(ctx context.Context) {
@@ -623,6 +1115,8 @@ func (e *Engine) detectorWorker(ctx context.Context) {
 	var wgVerificationOverlap sync.WaitGroup
 
 	for chunk := range e.ChunksChan() {
+		// The chunk's reference is held until the work derived from it holds its own.
+		tracker := e.checkpoint.ChunkTracker(chunk)
 		atomic.AddUint64(&e.metrics.BytesScanned, uint64(len(chunk.Data)))
 		for _, decoder := range e.decoders {
 			decoded := decoder.FromChunk(chunk)
@@ -634,11 +1128,13 @@ func (e *Engine) detectorWorker(ctx context.Context) {
 			matchingDetectors := e.ahoCorasickCore.FindDetectorMatches(decoded.Chunk.Data)
 			if len(matchingDetectors) > 1 && !e.verificationOverlap {
 				wgVerificationOverlap.Add(1)
+				tracker.Add()
 				e.verificationOverlapChunksChan <- verificationOverlapChunk{
 					chunk:                       *decoded.Chunk,
 					detectors:                   matchingDetectors,
 					decoder:                     decoded.DecoderType,
 					verificationOverlapWgDoneFn: wgVerificationOverlap.Done,
+					tracker:                     tracker,
 				}
 				continue
 			}
@@ -646,17 +1142,20 @@ func (e *Engine) detectorWorker(ctx context.Context) {
 			for _, detector := range matchingDetectors {
 				decoded.Chunk.Verify = e.verify
 				wgDetect.Add(1)
+				tracker.Add()
 				e.detectableChunksChan <- detectableChunk{
 					chunk:    *decoded.Chunk,
 					detector: detector,
 					decoder:  decoded.DecoderType,
 					wgDoneFn: wgDetect.Done,
+					tracker:  tracker,
 				}
 			}
 			continue
 		}
 
 		atomic.AddUint64(&e.metrics.ChunksScanned, 1)
+		tracker.Done()
 	}
 
 	wgVerificationOverlap.Wait()
@@ -765,6 +1264,7 @@ func (e *Engine) verificationOverlapWorker(ctx context.Context) {
 							detector: detector,
 							decoder:  chunk.decoder,
 							wgDoneFn: wgDetect.Done,
+							tracker:  chunk.tracker,
 						}, res)
 
 						// Remove the detector key from the list of detector keys with results.
@@ -779,12 +1279,14 @@ func (e *Engine) verificationOverlapWorker(ctx context.Context) {
 
 		for _, detector := range detectorKeysWithResults {
 			wgDetect.Add(1)
+			chunk.tracker.Add()
 			chunk.chunk.Verify = e.verify
 			e.detectableChunksChan <- detectableChunk{
 				chunk:    chunk.chunk,
 				detector: detector,
 				decoder:  chunk.decoder,
 				wgDoneFn: wgDetect.Done,
+				tracker:  chunk.tracker,
 			}
 		}
 
@@ -797,6 +1299,7 @@ func (e *Engine) verificationOverlapWorker(ctx context.Context) {
 		}
 
 		chunk.verificationOverlapWgDoneFn()
+		chunk.tracker.Done()
 	}
 
 	wgDetect.Wait()
@@ -808,14 +1311,17 @@ func (e *Engine) detectChunks(ctx context.Context) {
 	}
 }
 
+// defaultDetectorTimeout is the time a detector may spend on a single match
+// when no timeout is configured.
+const defaultDetectorTimeout = 10 * time.Second
+
 func (e *Engine) detectChunk(ctx context.Context, data detectableChunk) {
 	var start time.Time
 	if e.printAvgDetectorTime {
 		start = time.Now()
 	}
-	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 	defer common.Recover(ctx)
-	defer cancel()
+	defer data.tracker.Done()
 
 	// To reduce the overhead of regex calls in the detector,
 	// we limit the amount of data passed to each detector.
@@ -824,7 +1330,7 @@ func (e *Engine) detectChunk(ctx context.Context, data detectableChunk) {
 	// This avoids the need for additional regex processing on the entire chunk data.
 	matchedBytes := data.detector.Matches()
 	for _, match := range matchedBytes {
-		results, err := data.detector.FromData(ctx, data.chunk.Verify, match)
+		results, err := e.detectMatch(ctx, data, match)
 		if err != nil {
 			ctx.Logger().Error(err, "error scanning chunk")
 			continue
@@ -854,6 +1360,117 @@ func (e *Engine) detectChunk(ctx context.Context, data detectableChunk) {
 	data.wgDoneFn()
 }
 
+// timeoutFor returns the timeout configured for the detector's type.
+func (e *Engine) timeoutFor(detector detectors.Detector) time.Duration {
+	if timeout, ok := e.detectorTimeouts[detector.Type()]; ok {
+		return timeout
+	}
+	return e.detectorTimeout
+}
+
+// detectMatch runs the chunk's detector on a single match within the
+// detector's timeout. If the timeout is hit while verifying, the secrets are
+// reported as unverified with a verification error instead of being dropped.
+// Secrets whose verification outcome is cached aren't verified again.
+func (e *Engine) detectMatch(ctx context.Context, data detectableChunk, match []byte) ([]detectors.Result, error) {
+	if e.verificationCache == nil || !data.chunk.Verify {
+		return e.verifyMatch(ctx, data, match)
+	}
+	if results, ok := e.detectCachedMatch(ctx, data, match); ok {
+		return results, nil
+	}
+	results, err := e.verifyMatch(ctx, data, match)
+	if err == nil {
+		e.verificationCache.store(data.detector.Detector, results)
+	}
+	return results, err
+}
+
+// verifyMatch implements detectMatch without the verification cache.
+func (e *Engine) verifyMatch(ctx context.Context, data detectableChunk, match []byte) ([]detectors.Result, error) {
+	timeout := e.timeoutFor(data.detector.Detector)
+	// The timeout doesn't run while verification requests wait for their rate limit.
+	timeoutCtx, cancel := common.WithVerificationTimeout(ctx, timeout)
+	defer cancel()
+	detectCtx := context.WithLogger(timeoutCtx, ctx.Logger().WithValues("timeout", timeout))
+	// Verification requests are rate limited per detector.
+	verifyCtx := common.WithRateLimitDetector(detectCtx, data.detector.Type().String())
+
+	var (
+		results []detectors.Result
+		err     error
+	)
+	if e.captureVerificationResponses && data.chunk.Verify {
+		rec := new(common.ResponseRecorder)
+		results, err = data.detector.FromData(common.WithResponseRecorder(verifyCtx, rec), true, match)
+		addVerificationResponses(results, rec)
+	} else {
+		results, err = data.detector.FromData(verifyCtx, data.chunk.Verify, match)
+	}
+	// Only the detector's own deadline counts as a timeout, not the scan being cancelled.
+	if !data.chunk.Verify || detectCtx.Err() == nil || ctx.Err() != nil {
+		return results, err
+	}
+
+	timeoutErr := fmt.Errorf("verification timed out after %s", timeout)
+	if err != nil {
+		// The detector gave up without returning its secrets, so find them
+		// again without verifying.
+		detectCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		if results, err = data.detector.FromData(detectCtx, false, match); err != nil {
+			return nil, err
+		}
+	}
+	for i := range results {
+		if !results[i].Verified && results[i].VerificationError() == nil {
+			results[i].SetVerificationError(timeoutErr)
+		}
+	}
+	return results, nil
+}
+
+const verificationResponseKey = "verification_response"
+
+// addVerificationResponses stores the recorded verification response of each verified
+// result in its ExtraData. A response is matched to a result by the secret sent with the
+// request. If only a single result was verified, the last recorded response is used.
+func addVerificationResponses(results []detectors.Result, rec *common.ResponseRecorder) {
+	responses := rec.Responses()
+	if len(responses) == 0 {
+		return
+	}
+
+	numVerified := 0
+	for _, r := range results {
+		if r.Verified {
+			numVerified++
+		}
+	}
+
+	for i := range results {
+		if !results[i].Verified {
+			continue
+		}
+		res, ok := rec.ResponseFor(string(results[i].Raw))
+		if !ok && numVerified == 1 {
+			res, ok = responses[len(responses)-1], true
+		}
+		if !ok {
+			continue
+		}
+
+		value := fmt.Sprintf("%d %s", res.StatusCode, strings.ToValidUTF8(string(res.Body), ""))
+		if res.Truncated {
+			value += " [truncated]"
+		}
+		if results[i].ExtraData == nil {
+			results[i].ExtraData = make(map[string]string, 1)
+		}
+		results[i].ExtraData[verificationResponseKey] = value
+	}
+}
+
 // filterResults applies multiple filters to the detection results to reduce false positives
 // and ensure the results meet specific criteria such as verification status and entropy level.
 // This function centralizes the filtering logic, making it reusable across different stages
@@ -893,55 +1510,90 @@ func (e *Engine) processResult(ctx context.Context, data detectableChunk, res de
 	if ignoreLinePresent {
 		return
 	}
+	if e.contextLines > 0 && SupportsLineNumbers(data.chunk.SourceType) {
+		addRedactedContext(&res, data.chunk.Data, e.contextLines)
+	}
 
 	secret := detectors.CopyMetadata(&data.chunk, res)
 	secret.DecoderType = data.decoder
+	secret.Tracker = data.tracker
+	secret.Tracker.Add()
 	e.results <- secret
 }
 
 func (e *Engine) notifyResults(ctx context.Context) {
 	for r := range e.ResultsChan() {
-		// Filter unwanted results, based on `--results`.
-		if !r.Verified {
-			if r.VerificationError() != nil {
-				if !e.notifyUnknownResults {
-					// Skip results with verification errors.
-					continue
-				}
-			} else if !e.notifyUnverifiedResults {
-				// Skip unverified results.
-				continue
+		e.notifyResult(ctx, r)
+		// The result has been reported, so the unit it was found in may be checkpointed.
+		r.Tracker.Done()
+	}
+}
+
+// notifyResult filters, deduplicates and prints a single result.
+func (e *Engine) notifyResult(ctx context.Context, r detectors.ResultWithMetadata) {
+	// Filter unwanted results, based on `--results`.
+	if !r.Verified {
+		if r.VerificationError() != nil {
+			if !e.notifyUnknownResults {
+				// Skip results with verification errors.
+				return
 			}
-		} else if !e.notifyVerifiedResults {
-			// Skip verified results.
-			// TODO: Is this a legitimate use case?
-			continue
-		}
-		atomic.AddUint32(&e.numFoundResults, 1)
-
-		// Dedupe results by comparing the detector type, raw result, and source metadata.
-		// We want to avoid duplicate results with different decoder types, but we also
-		// want to include duplicate results with the same decoder type.
-		// Duplicate results with the same decoder type SHOULD have their own entry in the
-		// results list, this would happen if the same secret is found multiple times.
-		// Note: If the source type is postman, we dedupe the results regardless of decoder type.
-		key := fmt.Sprintf("%s%s%s%+v", r.DetectorType.String(), r.Raw, r.RawV2, r.SourceMetadata)
-		if val, ok := e.dedupeCache.Get(key); ok && (val != r.DecoderType ||
-			r.SourceType == sourcespb.SourceType_SOURCE_TYPE_POSTMAN) {
-			continue
+		} else if !e.notifyUnverifiedResults {
+			// Skip unverified results.
+			return
 		}
-		e.dedupeCache.Add(key, r.DecoderType)
+	} else if !e.notifyVerifiedResults {
+		// Skip verified results.
+		// TODO: Is this a legitimate use case?
+		return
+	}
 
-		if r.Verified {
-			atomic.AddUint64(&e.metrics.VerifiedSecretsFound, 1)
-		} else {
-			atomic.AddUint64(&e.metrics.UnverifiedSecretsFound, 1)
-		}
+	// Dedupe results by comparing the detector type, raw result, and source metadata.
+	// We want to avoid duplicate results with different decoder types, but we also
+	// want to include duplicate results with the same decoder type.
+	// Duplicate results with the same decoder type SHOULD have their own entry in the
+	// results list, this would happen if the same secret is found multiple times.
+	// Note: If the source type is postman, we dedupe the results regardless of decoder type.
+	key := fmt.Sprintf("%s%s%s%+v", r.DetectorType.String(), r.Raw, r.RawV2, r.SourceMetadata)
+	if val, ok := e.dedupeCache.Get(key); ok && (val != r.DecoderType ||
+		r.SourceType == sourcespb.SourceType_SOURCE_TYPE_POSTMAN) {
+		return
+	}
+	e.dedupeCache.Add(key, r.DecoderType)
 
-		if err := e.printer.Print(ctx, &r); err != nil {
-			ctx.Logger().Error(err, "error printing result")
-		}
+	if e.allowlist.Suppresses(&r) {
+		e.notifySuppressed(ctx, r)
+		return
 	}
+	if !e.limitResult(ctx, &r) {
+		return
+	}
+	atomic.AddUint32(&e.numFoundResults, 1)
+
+	if e.resultAggregator != nil {
+		e.resultAggregator.add(r)
+		return
+	}
+	e.printResult(ctx, &r)
+}
+
+// printResult records r in the metrics, prints it and calls the result hooks.
+func (e *Engine) printResult(ctx context.Context, r *detectors.ResultWithMetadata) {
+	e.sourceStatsHook.reportResult(r.SourceID, r.SourceName)
+	e.resultSummarizer.add(r)
+	if r.Verified {
+		atomic.AddUint64(&e.metrics.VerifiedSecretsFound, 1)
+	} else {
+		atomic.AddUint64(&e.metrics.UnverifiedSecretsFound, 1)
+	}
+
+	if e.pathAnonymizer != nil {
+		r = e.pathAnonymizer.anonymizeResult(ctx, r)
+	}
+	if err := e.printer.Print(ctx, r); err != nil {
+		ctx.Logger().Error(err, "error printing result")
+	}
+	e.runResultHooks(ctx, r)
 }
 
 // SupportsLineNumbers determines if a line number can be found for a source type.
@@ -955,7 +1607,8 @@ func SupportsLineNumbers(sourceType sourcespb.SourceType) bool {
 		sourcespb.SourceType_SOURCE_TYPE_GITHUB_UNAUTHENTICATED_ORG,
 		sourcespb.SourceType_SOURCE_TYPE_PUBLIC_GIT,
 		sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM,
-		sourcespb.SourceType_SOURCE_TYPE_AZURE_REPOS:
+		sourcespb.SourceType_SOURCE_TYPE_AZURE_REPOS,
+		sourcespb.SourceType_SOURCE_TYPE_STDIN:
 		return true
 	default:
 		return false
@@ -1003,7 +1656,11 @@ func FragmentFirstLineAndLink(chunk *sources.Chunk) (int64, *int64, string) {
 		link = metadata.Github.Link
 	case *source_metadatapb.MetaData_Gitlab:
 		fragmentStart = &metadata.Gitlab.Line
-		link = metadata.Gitlab.Link
+		// Merge request descriptions and notes are linked to with their own anchor,
+		// which a line number would overwrite.
+		if metadata.Gitlab.MergeRequestIid == 0 {
+			link = metadata.Gitlab.Link
+		}
 	case *source_metadatapb.MetaData_Bitbucket:
 		fragmentStart = &metadata.Bitbucket.Line
 		link = metadata.Bitbucket.Link
@@ -1015,6 +1672,8 @@ func FragmentFirstLineAndLink(chunk *sources.Chunk) (int64, *int64, string) {
 	case *source_metadatapb.MetaData_AzureRepos:
 		fragmentStart = &metadata.AzureRepos.Line
 		link = metadata.AzureRepos.Link
+	case *source_metadatapb.MetaData_Stdin:
+		fragmentStart = &metadata.Stdin.Line
 	default:
 		return 0, nil, ""
 	}
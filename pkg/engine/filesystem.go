@@ -15,9 +15,17 @@ import (
 // ScanFileSystem scans a given file system.
 func (e *Engine) ScanFileSystem(ctx context.Context, c sources.FilesystemConfig) error {
 	connection := &sourcespb.Filesystem{
-		Paths:            c.Paths,
-		IncludePathsFile: c.IncludePathsFile,
-		ExcludePathsFile: c.ExcludePathsFile,
+		Paths:                   c.Paths,
+		IncludePathsFile:        c.IncludePathsFile,
+		ExcludePathsFile:        c.ExcludePathsFile,
+		IncludePathsRegex:       c.IncludePathsRegex,
+		ExcludePathsRegex:       c.ExcludePathsRegex,
+		FollowSymlinks:          c.FollowSymlinks,
+		ConfineToRoot:           c.ConfineToRoot,
+		SkipBinaries:            c.SkipBinaries,
+		AllowedBinaryExtensions: c.AllowedBinaryExtensions,
+		ScanExtensions:          c.ScanExtensions,
+		BufferThreshold:         c.BufferThreshold,
 	}
 	var conn anypb.Any
 	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
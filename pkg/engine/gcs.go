@@ -31,6 +31,8 @@ func (e *Engine) ScanGCS(ctx context.Context, c sources.GCSConfig) error {
 		ExcludeBuckets: c.ExcludeBuckets,
 		IncludeObjects: c.IncludeObjects,
 		ExcludeObjects: c.ExcludeObjects,
+		MaxObjectSize:  c.MaxObjectSize,
+		Prefix:         c.Prefix,
 	}
 
 	// Make sure only one auth method is selected.
@@ -48,7 +50,7 @@ func (e *Engine) ScanGCS(ctx context.Context, c sources.GCSConfig) error {
 	sourceID, jobID, _ := e.sourceManager.GetIDs(ctx, sourceName, gcs.SourceType)
 
 	gcsSource := &gcs.Source{}
-	if err := gcsSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, int(c.Concurrency)); err != nil {
+	if err := gcsSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, e.initConcurrency(gcs.SourceType, int(c.Concurrency))); err != nil {
 		return err
 	}
 	_, err = e.sourceManager.Run(ctx, sourceName, gcsSource)
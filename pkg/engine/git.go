@@ -5,6 +5,7 @@ import (
 
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
@@ -15,15 +16,26 @@ import (
 // ScanGit scans any git source.
 func (e *Engine) ScanGit(ctx context.Context, c sources.GitConfig) error {
 	connection := &sourcespb.Git{
-		Head:             c.HeadRef,
-		Base:             c.BaseRef,
-		Bare:             c.Bare,
-		Uri:              c.URI,
-		ExcludeGlobs:     c.ExcludeGlobs,
-		IncludePathsFile: c.IncludePathsFile,
-		ExcludePathsFile: c.ExcludePathsFile,
-		MaxDepth:         int64(c.MaxDepth),
-		SkipBinaries:     c.SkipBinaries,
+		Head:               c.HeadRef,
+		Base:               c.BaseRef,
+		Bare:               c.Bare,
+		Uri:                c.URI,
+		ExcludeGlobs:       c.ExcludeGlobs,
+		IncludePathsFile:   c.IncludePathsFile,
+		ExcludePathsFile:   c.ExcludePathsFile,
+		MaxDepth:           int64(c.MaxDepth),
+		SkipBinaries:       c.SkipBinaries,
+		IncludeSubmodules:  c.IncludeSubmodules,
+		Refs:               c.Refs,
+		ScanCommitMessages: c.ScanCommitMessages,
+		FetchLfs:           c.FetchLFS,
+		MaxLfsObjectSize:   c.MaxLFSObjectSize,
+		WorkingTreeOnly:    c.WorkingTreeOnly,
+		ScanNotes:          c.ScanNotes,
+		ScanStashes:        c.ScanStashes,
+	}
+	if !c.SinceDate.IsZero() {
+		connection.SinceDate = timestamppb.New(c.SinceDate)
 	}
 	var conn anypb.Any
 	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
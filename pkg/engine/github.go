@@ -27,6 +27,9 @@ func (e *Engine) ScanGitHub(ctx context.Context, c sources.GithubConfig) error {
 		IncludeGistComments:        c.IncludeGistComments,
 		IncludeWikis:               c.IncludeWikis,
 		SkipBinaries:               c.SkipBinaries,
+		IncrementalStateFile:       c.IncrementalStateFile,
+		PullRequest:                int64(c.PullRequest),
+		ScanCommitMessages:         c.ScanCommitMessages,
 	}
 	if len(c.Token) > 0 {
 		connection.Credential = &sourcespb.GitHub_Token{
@@ -54,7 +57,7 @@ func (e *Engine) ScanGitHub(ctx context.Context, c sources.GithubConfig) error {
 	sourceID, jobID, _ := e.sourceManager.GetIDs(ctx, sourceName, github.SourceType)
 
 	githubSource := &github.Source{}
-	if err := githubSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, c.Concurrency); err != nil {
+	if err := githubSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, e.initConcurrency(github.SourceType, c.Concurrency)); err != nil {
 		return err
 	}
 	githubSource.WithScanOptions(scanOptions)
@@ -7,6 +7,7 @@ import (
 	gogit "github.com/go-git/go-git/v5"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
@@ -24,7 +25,15 @@ func (e *Engine) ScanGitLab(ctx context.Context, c sources.GitlabConfig) error {
 	}
 	scanOptions := git.NewScanOptions(opts...)
 
-	connection := &sourcespb.GitLab{SkipBinaries: c.SkipBinaries}
+	connection := &sourcespb.GitLab{
+		SkipBinaries:      c.SkipBinaries,
+		ScanMergeRequests: c.ScanMergeRequests,
+		Groups:            c.Groups,
+		SkipArchived:      c.SkipArchived,
+	}
+	if !c.LastActivityAfter.IsZero() {
+		connection.LastActivityAfter = timestamppb.New(c.LastActivityAfter)
+	}
 
 	switch {
 	case len(c.Token) > 0:
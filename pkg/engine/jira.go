@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"runtime"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/jira"
+)
+
+// ScanJira scans the issues and comments of a Jira instance.
+func (e *Engine) ScanJira(ctx context.Context, c sources.JiraConfig) error {
+	connection := &sourcespb.JIRA{
+		Endpoint:              c.Endpoint,
+		Projects:              c.Projects,
+		IgnoreProjects:        c.IgnoreProjects,
+		Jql:                   c.JQL,
+		InsecureSkipVerifyTls: c.InsecureSkipVerifyTLS,
+	}
+	switch {
+	case c.Token != "":
+		connection.Credential = &sourcespb.JIRA_Token{Token: c.Token}
+	case c.Username != "" || c.Password != "":
+		connection.Credential = &sourcespb.JIRA_BasicAuth{
+			BasicAuth: &credentialspb.BasicAuth{
+				Username: c.Username,
+				Password: c.Password,
+			},
+		}
+	default:
+		connection.Credential = &sourcespb.JIRA_Unauthenticated{
+			Unauthenticated: &credentialspb.Unauthenticated{},
+		}
+	}
+	if !c.UpdatedSince.IsZero() {
+		connection.UpdatedSince = timestamppb.New(c.UpdatedSince)
+	}
+
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
+	if err != nil {
+		ctx.Logger().Error(err, "failed to marshal Jira connection")
+		return err
+	}
+
+	sourceName := "trufflehog - Jira"
+	sourceID, jobID, _ := e.sourceManager.GetIDs(ctx, sourceName, jira.SourceType)
+
+	jiraSource := &jira.Source{}
+	if err := jiraSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+		return err
+	}
+	_, err = e.sourceManager.Run(ctx, sourceName, jiraSource)
+	return err
+}
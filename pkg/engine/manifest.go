@@ -0,0 +1,253 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// Manifest lists the sources of a scan with their options, so that sources of
+// different types can be scanned in a single run.
+type Manifest struct {
+	Sources []ManifestSource
+}
+
+// ManifestSource is a source listed in a manifest. Only the configuration of
+// its type is set.
+type ManifestSource struct {
+	// Name identifies the source in errors. It defaults to its type and
+	// position in the manifest.
+	Name       string
+	Type       sourcespb.SourceType
+	Git        *sources.GitConfig
+	Filesystem *sources.FilesystemConfig
+	S3         *sources.S3Config
+}
+
+// manifestEntry holds the fields every source of a manifest has.
+type manifestEntry struct {
+	Type string `yaml:"type"`
+	Name string `yaml:"name"`
+}
+
+type manifestGit struct {
+	manifestEntry     `yaml:",inline"`
+	URI               string   `yaml:"uri"`
+	Branch            string   `yaml:"branch"`
+	SinceCommit       string   `yaml:"since_commit"`
+	MaxDepth          int      `yaml:"max_depth"`
+	Refs              []string `yaml:"refs"`
+	Bare              bool     `yaml:"bare"`
+	IncludeSubmodules bool     `yaml:"include_submodules"`
+	ExcludeGlobs      []string `yaml:"exclude_globs"`
+	IncludePathsFile  string   `yaml:"include_paths_file"`
+	ExcludePathsFile  string   `yaml:"exclude_paths_file"`
+}
+
+type manifestFilesystem struct {
+	manifestEntry     `yaml:",inline"`
+	Paths             []string `yaml:"paths"`
+	IncludePathsFile  string   `yaml:"include_paths_file"`
+	ExcludePathsFile  string   `yaml:"exclude_paths_file"`
+	IncludePathsRegex []string `yaml:"include_paths_regex"`
+	ExcludePathsRegex []string `yaml:"exclude_paths_regex"`
+	FollowSymlinks    bool     `yaml:"follow_symlinks"`
+	SkipBinaries      bool     `yaml:"skip_binaries"`
+}
+
+// manifestS3 doesn't take access keys, so that manifests can be shared:
+// buckets are read with the credentials of the environment if CloudEnvironment
+// is set, and anonymously otherwise.
+type manifestS3 struct {
+	manifestEntry      `yaml:",inline"`
+	Buckets            []string `yaml:"buckets"`
+	IgnoreBuckets      []string `yaml:"ignore_buckets"`
+	Roles              []string `yaml:"roles"`
+	ExternalID         string   `yaml:"external_id"`
+	CloudEnvironment   bool     `yaml:"cloud_environment"`
+	IncludePrefixes    []string `yaml:"include_prefixes"`
+	ExcludePrefixes    []string `yaml:"exclude_prefixes"`
+	ExcludeSuffixes    []string `yaml:"exclude_suffixes"`
+	MaxObjectSize      int64    `yaml:"max_object_size"`
+	ScanObjectVersions bool     `yaml:"scan_object_versions"`
+}
+
+// manifestSourceTypes are the types of the sources a manifest may list.
+var manifestSourceTypes = []string{"git", "filesystem", "s3"}
+
+// LoadManifest reads a manifest from a YAML or JSON file listing sources under
+// "sources". Each source has a type, an optional name, and the options of its
+// type, e.g.:
+//
+//	sources:
+//	  - type: git
+//	    uri: https://github.com/org/repo.git
+//	    max_depth: 100
+//	  - type: s3
+//	    buckets: [logs]
+//	    cloud_environment: true
+//	  - type: filesystem
+//	    paths: [/srv/app]
+//
+// Unknown source types and options are errors.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest: %w", err)
+	}
+	return ParseManifest(data)
+}
+
+// ParseManifest parses a manifest in the format read by LoadManifest.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var file struct {
+		Sources []yaml.Node `yaml:"sources"`
+	}
+	if err := decodeStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if len(file.Sources) == 0 {
+		return nil, errors.New("invalid manifest: no sources")
+	}
+
+	m := &Manifest{Sources: make([]ManifestSource, 0, len(file.Sources))}
+	names := make(map[string]struct{}, len(file.Sources))
+	for i := range file.Sources {
+		node := &file.Sources[i]
+		src, err := parseManifestSource(node)
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest source %d on line %d: %w", i+1, node.Line, err)
+		}
+		if src.Name == "" {
+			src.Name = fmt.Sprintf("%s #%d", strings.ToLower(strings.TrimPrefix(src.Type.String(), "SOURCE_TYPE_")), i+1)
+		}
+		if _, ok := names[src.Name]; ok {
+			return nil, fmt.Errorf("invalid manifest source %d on line %d: duplicate name %q", i+1, node.Line, src.Name)
+		}
+		names[src.Name] = struct{}{}
+		m.Sources = append(m.Sources, src)
+	}
+	return m, nil
+}
+
+// parseManifestSource parses a source of a manifest into the configuration of
+// its type.
+func parseManifestSource(node *yaml.Node) (ManifestSource, error) {
+	var entry manifestEntry
+	if err := node.Decode(&entry); err != nil {
+		return ManifestSource{}, err
+	}
+	// Nodes can't be decoded strictly, so the source is encoded back to be.
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return ManifestSource{}, err
+	}
+
+	src := ManifestSource{Name: entry.Name}
+	switch entry.Type {
+	case "git":
+		var c manifestGit
+		if err := decodeStrict(data, &c); err != nil {
+			return src, err
+		}
+		if c.URI == "" {
+			return src, errors.New("git sources need a uri")
+		}
+		src.Type = sourcespb.SourceType_SOURCE_TYPE_GIT
+		src.Git = &sources.GitConfig{
+			URI:               c.URI,
+			HeadRef:           c.Branch,
+			BaseRef:           c.SinceCommit,
+			MaxDepth:          c.MaxDepth,
+			Refs:              c.Refs,
+			Bare:              c.Bare,
+			IncludeSubmodules: c.IncludeSubmodules,
+			ExcludeGlobs:      strings.Join(c.ExcludeGlobs, ","),
+			IncludePathsFile:  c.IncludePathsFile,
+			ExcludePathsFile:  c.ExcludePathsFile,
+		}
+	case "filesystem":
+		var c manifestFilesystem
+		if err := decodeStrict(data, &c); err != nil {
+			return src, err
+		}
+		if len(c.Paths) == 0 {
+			return src, errors.New("filesystem sources need paths")
+		}
+		src.Type = sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM
+		src.Filesystem = &sources.FilesystemConfig{
+			Paths:             c.Paths,
+			IncludePathsFile:  c.IncludePathsFile,
+			ExcludePathsFile:  c.ExcludePathsFile,
+			IncludePathsRegex: c.IncludePathsRegex,
+			ExcludePathsRegex: c.ExcludePathsRegex,
+			FollowSymlinks:    c.FollowSymlinks,
+			SkipBinaries:      c.SkipBinaries,
+		}
+	case "s3":
+		var c manifestS3
+		if err := decodeStrict(data, &c); err != nil {
+			return src, err
+		}
+		src.Type = sourcespb.SourceType_SOURCE_TYPE_S3
+		src.S3 = &sources.S3Config{
+			Buckets:            c.Buckets,
+			IgnoreBuckets:      c.IgnoreBuckets,
+			Roles:              c.Roles,
+			ExternalID:         c.ExternalID,
+			CloudCred:          c.CloudEnvironment,
+			IncludePrefixes:    c.IncludePrefixes,
+			ExcludePrefixes:    c.ExcludePrefixes,
+			ExcludeSuffixes:    c.ExcludeSuffixes,
+			MaxObjectSize:      c.MaxObjectSize,
+			ScanObjectVersions: c.ScanObjectVersions,
+		}
+	case "":
+		return src, errors.New("missing source type")
+	default:
+		return src, fmt.Errorf("unknown source type %q, expected one of: %s", entry.Type, strings.Join(manifestSourceTypes, ", "))
+	}
+	return src, nil
+}
+
+// decodeStrict decodes YAML, or JSON, into v, rejecting the fields v doesn't have.
+func decodeStrict(data []byte, v any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(v)
+}
+
+// ScanManifest scans each source of the manifest. A source that fails to start
+// doesn't stop the others: the scan is marked incomplete, and the errors of all
+// such sources are returned once the others have started.
+func (e *Engine) ScanManifest(ctx context.Context, m *Manifest) error {
+	var errs []error
+	for _, src := range m.Sources {
+		var err error
+		switch {
+		case src.Git != nil:
+			err = e.ScanGit(ctx, *src.Git)
+		case src.Filesystem != nil:
+			err = e.ScanFileSystem(ctx, *src.Filesystem)
+		case src.S3 != nil:
+			err = e.ScanS3(ctx, *src.S3)
+		default:
+			err = fmt.Errorf("unsupported source type %s", src.Type)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to scan source %q: %w", src.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		e.markIncomplete("some sources of the manifest failed to start")
+	}
+	return errors.Join(errs...)
+}
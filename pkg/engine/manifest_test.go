@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestParseManifest(t *testing.T) {
+	m, err := ParseManifest([]byte(`
+sources:
+  - type: git
+    name: app
+    uri: https://github.com/org/app.git
+    max_depth: 100
+    exclude_globs: ["vendor/*", "*.lock"]
+  - type: s3
+    buckets: [logs]
+    cloud_environment: true
+    include_prefixes: [prod/]
+  - type: filesystem
+    paths: [/srv/app]
+    skip_binaries: true
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []ManifestSource{
+		{
+			Name: "app",
+			Type: sourcespb.SourceType_SOURCE_TYPE_GIT,
+			Git: &sources.GitConfig{
+				URI:          "https://github.com/org/app.git",
+				MaxDepth:     100,
+				ExcludeGlobs: "vendor/*,*.lock",
+			},
+		},
+		{
+			Name: "s3 #2",
+			Type: sourcespb.SourceType_SOURCE_TYPE_S3,
+			S3: &sources.S3Config{
+				Buckets:         []string{"logs"},
+				CloudCred:       true,
+				IncludePrefixes: []string{"prod/"},
+			},
+		},
+		{
+			Name: "filesystem #3",
+			Type: sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM,
+			Filesystem: &sources.FilesystemConfig{
+				Paths:        []string{"/srv/app"},
+				SkipBinaries: true,
+			},
+		},
+	}, m.Sources)
+
+	// JSON manifests are parsed the same way.
+	m, err = ParseManifest([]byte(`{"sources": [{"type": "filesystem", "paths": ["/srv/app"]}]}`))
+	assert.NoError(t, err)
+	if assert.Len(t, m.Sources, 1) {
+		assert.Equal(t, []string{"/srv/app"}, m.Sources[0].Filesystem.Paths)
+	}
+}
+
+func TestParseManifest_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{
+			name:     "no sources",
+			manifest: "sources: []",
+			wantErr:  "no sources",
+		},
+		{
+			name:     "unknown source type",
+			manifest: "sources:\n  - type: ftp\n    uri: ftp://example.com",
+			wantErr:  `source 1 on line 2: unknown source type "ftp"`,
+		},
+		{
+			name:     "missing source type",
+			manifest: "sources:\n  - paths: [/srv/app]",
+			wantErr:  "missing source type",
+		},
+		{
+			name:     "option of another type",
+			manifest: "sources:\n  - type: filesystem\n    paths: [/srv/app]\n  - type: git\n    uri: https://github.com/org/app.git\n    buckets: [logs]",
+			wantErr:  "source 2 on line 4: yaml: unmarshal errors",
+		},
+		{
+			name:     "missing uri",
+			manifest: "sources:\n  - type: git",
+			wantErr:  "git sources need a uri",
+		},
+		{
+			name:     "duplicate name",
+			manifest: "sources:\n  - type: filesystem\n    name: app\n    paths: [a]\n  - type: filesystem\n    name: app\n    paths: [b]",
+			wantErr:  `duplicate name "app"`,
+		},
+		{
+			name:     "unknown top-level field",
+			manifest: "targets:\n  - type: filesystem",
+			wantErr:  "field targets not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(tt.manifest))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEngine_ScanManifest(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.env"), []byte(fakeDetectorKeyword+" = fake secret v1"), 0o600))
+
+	m, err := ParseManifest([]byte(`
+sources:
+  - type: filesystem
+    name: broken
+    paths: [` + dir + `]
+    include_paths_regex: ["config(\\.env"]
+  - type: filesystem
+    name: app
+    paths: [` + dir + `]
+`))
+	assert.NoError(t, err)
+
+	printer := new(resultCollector)
+	e, err := Start(ctx,
+		WithConcurrency(1),
+		WithDetectors(fakeDetectorV1{}),
+		WithVerify(false),
+		WithPrinter(printer),
+	)
+	assert.NoError(t, err)
+
+	// The source that fails to start doesn't stop the other one.
+	err = e.ScanManifest(ctx, m)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `failed to scan source "broken"`)
+	}
+	assert.NoError(t, e.Finish(ctx))
+
+	assert.Len(t, printer.results, 1)
+	assert.True(t, e.GetMetrics().ScanIncomplete)
+}
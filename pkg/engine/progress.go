@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// progressHook counts the units and chunks of every source the engine runs,
+// so the progress of the scan can be reported while it runs.
+type progressHook struct {
+	sources.NoopHook
+
+	// enumerating is the number of sources that are enumerating their units.
+	// The total number of units isn't known while it is positive.
+	enumerating atomic.Int64
+	// units is the number of units enumerated and completed the number of
+	// units whose chunking ended.
+	units, completed atomic.Uint64
+	chunks, bytes    atomic.Uint64
+}
+
+func (h *progressHook) StartEnumerating(sources.JobProgressRef, time.Time) { h.enumerating.Add(1) }
+func (h *progressHook) EndEnumerating(sources.JobProgressRef, time.Time)   { h.enumerating.Add(-1) }
+func (h *progressHook) ReportUnit(sources.JobProgressRef, sources.SourceUnit) {
+	h.units.Add(1)
+}
+func (h *progressHook) EndUnitChunking(sources.JobProgressRef, sources.SourceUnit, time.Time) {
+	h.completed.Add(1)
+}
+
+func (h *progressHook) ReportChunk(_ sources.JobProgressRef, _ sources.SourceUnit, chunk *sources.Chunk) {
+	h.chunks.Add(1)
+	if chunk != nil {
+		h.bytes.Add(uint64(len(chunk.Data)))
+	}
+}
+
+// progress is a snapshot of the progress of a scan.
+type progress struct {
+	elapsed time.Duration
+	// units is zero if no source enumerates units, and totalKnown is set once
+	// every source finished enumerating its units.
+	units, completed uint64
+	totalKnown       bool
+	chunks, bytes    uint64
+}
+
+func (h *progressHook) snapshot(elapsed time.Duration) progress {
+	return progress{
+		elapsed:    elapsed,
+		units:      h.units.Load(),
+		completed:  h.completed.Load(),
+		totalKnown: h.enumerating.Load() == 0,
+		chunks:     h.chunks.Load(),
+		bytes:      h.bytes.Load(),
+	}
+}
+
+// String formats the progress as a single line. The percentage and the ETA,
+// which assumes the remaining units are scanned at the average rate so far,
+// are only reported once the total number of units is known. Otherwise, the
+// number of completed units and the rate are reported.
+func (p progress) String() string {
+	var b strings.Builder
+	seconds := p.elapsed.Seconds()
+	rate := func(n uint64) float64 {
+		if seconds <= 0 {
+			return 0
+		}
+		return float64(n) / seconds
+	}
+
+	switch {
+	case p.units > 0 && p.totalKnown:
+		fmt.Fprintf(&b, "scanned %d/%d units (%.1f%%), ", p.completed, p.units, 100*float64(p.completed)/float64(p.units))
+	case p.units > 0:
+		fmt.Fprintf(&b, "scanned %d units (%.1f units/s), ", p.completed, rate(p.completed))
+	default:
+		b.WriteString("scanned ")
+	}
+	fmt.Fprintf(&b, "%d chunks, %s (%s/s), elapsed %s", p.chunks, formatBytes(float64(p.bytes)), formatBytes(rate(p.bytes)), p.elapsed.Round(time.Second))
+
+	if p.units > 0 && p.totalKnown && p.completed > 0 && p.completed < p.units {
+		eta := time.Duration(float64(p.units-p.completed) / rate(p.completed) * float64(time.Second))
+		fmt.Fprintf(&b, ", ETA %s", eta.Round(time.Second))
+	}
+	return b.String()
+}
+
+// formatBytes formats a number of bytes with a binary unit.
+func formatBytes(n float64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%.0f B", n)
+	}
+	exp := 0
+	for n >= unit*unit && exp < 4 {
+		n /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", n/unit, "KMGTP"[exp])
+}
+
+// progressReporter writes the progress of the scan to a writer periodically.
+type progressReporter struct {
+	hook     *progressHook
+	w        io.Writer
+	interval time.Duration
+	start    time.Time
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func (r *progressReporter) run() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.report()
+			case <-r.done:
+				return
+			}
+		}
+	}()
+}
+
+func (r *progressReporter) report() {
+	_, _ = fmt.Fprintln(r.w, r.hook.snapshot(time.Since(r.start)))
+}
+
+// stop stops the periodic reports and writes the final progress.
+func (r *progressReporter) stop() {
+	close(r.done)
+	r.wg.Wait()
+	r.report()
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestProgress_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress progress
+		want     string
+	}{
+		{
+			name:     "total known",
+			progress: progress{elapsed: 10 * time.Second, units: 100, completed: 25, totalKnown: true, chunks: 40, bytes: 20 << 20},
+			want:     "scanned 25/100 units (25.0%), 40 chunks, 20.0 MiB (2.0 MiB/s), elapsed 10s, ETA 30s",
+		},
+		{
+			name:     "total known and done",
+			progress: progress{elapsed: time.Minute, units: 4, completed: 4, totalKnown: true, chunks: 4, bytes: 3 << 10},
+			want:     "scanned 4/4 units (100.0%), 4 chunks, 3.0 KiB (51 B/s), elapsed 1m0s",
+		},
+		{
+			name:     "total unknown",
+			progress: progress{elapsed: 4 * time.Second, units: 12, completed: 10, chunks: 12, bytes: 512},
+			want:     "scanned 10 units (2.5 units/s), 12 chunks, 512 B (128 B/s), elapsed 4s",
+		},
+		{
+			name:     "no units",
+			progress: progress{elapsed: 2 * time.Second, totalKnown: true, chunks: 3, bytes: 3 << 30},
+			want:     "scanned 3 chunks, 3.0 GiB (1.5 GiB/s), elapsed 2s",
+		},
+		{
+			name: "nothing scanned",
+			want: "scanned 0 chunks, 0 B (0 B/s), elapsed 0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.progress.String())
+		})
+	}
+}
+
+func TestEngine_Progress(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}
+	for _, path := range paths {
+		assert.NoError(t, os.WriteFile(path, []byte("some data"), 0o644))
+	}
+
+	var out bytes.Buffer
+	e, err := Start(ctx, WithConcurrency(1), WithVerify(false), WithPrinter(new(resultCollector)), WithProgress(&out, time.Hour))
+	assert.NoError(t, err)
+	assert.NoError(t, e.ScanFileSystem(ctx, sources.FilesystemConfig{Paths: paths}))
+	assert.NoError(t, e.Finish(ctx))
+
+	// The scan finishes before the first interval, so only the final progress
+	// is written.
+	assert.Regexp(t, `^scanned 2/2 units \(100\.0%\), 2 chunks, 18 B .*elapsed \S+\n$`, out.String())
+}
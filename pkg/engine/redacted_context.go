@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"bytes"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+const (
+	// contextKey is the ExtraData key of the redacted lines around a result.
+	contextKey = "context"
+	// redactedSecret replaces the secret in the context of a result.
+	redactedSecret = "[REDACTED]"
+)
+
+// WithContextLines configures the engine to include the lines around each
+// result found in a file-based source in the result's ExtraData under
+// "context", with the secret redacted. lines is the number of lines included
+// before and after the line of the secret. Values smaller than 1 disable it.
+func WithContextLines(lines int) Option {
+	return func(e *Engine) { e.contextLines = lines }
+}
+
+// addRedactedContext sets the context of result in data, if data contains it.
+// Multi-part credentials whose RawV2 isn't in data don't get a context: RawV2
+// concatenates the parts, so the parts other than Raw, such as the secret of
+// an AWS key ID, can't be found to be redacted.
+func addRedactedContext(result *detectors.Result, data []byte, lines int) {
+	if len(result.RawV2) > 0 && !bytes.Equal(result.RawV2, result.Raw) && !bytes.Contains(data, result.RawV2) {
+		return
+	}
+	context, ok := redactedContext(data, lines, result.Raw, result.RawV2)
+	if !ok {
+		return
+	}
+	if result.ExtraData == nil {
+		result.ExtraData = make(map[string]string, 1)
+	}
+	result.ExtraData[contextKey] = context
+}
+
+// redactedContext returns the lines around the first occurrence of secret in
+// data, including the given number of lines before and after it. Every
+// occurrence of secret and of the other secrets in the context is redacted,
+// including occurrences cut off by the edges of the context.
+func redactedContext(data []byte, lines int, secret []byte, others ...[]byte) (string, bool) {
+	if len(secret) == 0 {
+		return "", false
+	}
+	match := bytes.Index(data, secret)
+	if match == -1 {
+		return "", false
+	}
+
+	start := bytes.LastIndexByte(data[:match], '\n') + 1
+	for i := 0; i < lines && start > 0; i++ {
+		start = bytes.LastIndexByte(data[:start-1], '\n') + 1
+	}
+	end := match + len(secret)
+	for i := 0; i <= lines && end < len(data); i++ {
+		if i > 0 {
+			end++ // Skip the newline ending the previous line.
+		}
+		next := bytes.IndexByte(data[end:], '\n')
+		if next == -1 {
+			end = len(data)
+			break
+		}
+		end += next
+	}
+
+	// redacted marks the bytes of the context that belong to a secret.
+	redacted := make([]bool, end-start)
+	for _, s := range append([][]byte{secret}, others...) {
+		if len(s) == 0 {
+			continue
+		}
+		// Occurrences overlapping the start of the context begin before it.
+		from := max(start-len(s)+1, 0)
+		for {
+			i := bytes.Index(data[from:min(end+len(s)-1, len(data))], s)
+			if i == -1 {
+				break
+			}
+			i += from
+			for j := max(i, start); j < min(i+len(s), end); j++ {
+				redacted[j-start] = true
+			}
+			from = i + 1
+		}
+	}
+
+	var context bytes.Buffer
+	for i, b := range data[start:end] {
+		switch {
+		case !redacted[i]:
+			context.WriteByte(b)
+		case i == 0 || !redacted[i-1]:
+			context.WriteString(redactedSecret)
+		}
+	}
+	return context.String(), true
+}
@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// ResultHook is called with every result reported by the engine, right after
+// it is printed. Hooks are called by the notifier workers, possibly from
+// several goroutines at once, so they must be safe for concurrent use.
+//
+// Results are handed to the notifier workers through a bounded channel, so a
+// slow hook eventually blocks the detector workers. Hooks doing slow work,
+// such as network calls, should hand the result off to their own goroutine.
+type ResultHook func(ctx context.Context, r detectors.ResultWithMetadata)
+
+// WithResultHook registers hooks called with every result reported by the
+// engine. It may be given more than once; hooks are called in the order they
+// were registered. Results filtered out by the engine, for example by
+// WithResults, are not given to hooks.
+func WithResultHook(hooks ...ResultHook) Option {
+	return func(e *Engine) {
+		e.resultHooks = append(e.resultHooks, hooks...)
+	}
+}
+
+// runResultHooks calls every registered hook with r.
+func (e *Engine) runResultHooks(ctx context.Context, r *detectors.ResultWithMetadata) {
+	for _, hook := range e.resultHooks {
+		hook(ctx, *r)
+	}
+}
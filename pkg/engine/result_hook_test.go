@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+func TestEngine_ResultHooks(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	hook := func(name string) ResultHook {
+		return func(_ context.Context, r detectors.ResultWithMetadata) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, name+": "+string(r.Raw))
+		}
+	}
+
+	results, err := ScanBytes(ctx, []byte(fakeDetectorKeyword),
+		WithDetectors(fakeDetectorV1{}),
+		WithConcurrency(1),
+		WithResultHook(hook("first"), hook("second")),
+		WithResultHook(hook("third")),
+	)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, []string{
+		"first: fake secret v1",
+		"second: fake secret v1",
+		"third: fake secret v1",
+	}, calls)
+}
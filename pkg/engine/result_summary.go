@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// DetectorResults counts the results reported for a single detector.
+type DetectorResults struct {
+	DetectorType detectorspb.DetectorType `json:"detector_type"`
+	// DetectorName is the name of the detector type, or of the custom
+	// detector that reported the results.
+	DetectorName string `json:"detector_name"`
+	Verified     uint64 `json:"verified"`
+	Unverified   uint64 `json:"unverified"`
+}
+
+// Total returns the number of results reported for the detector.
+func (d DetectorResults) Total() uint64 { return d.Verified + d.Unverified }
+
+// ResultSummary counts the results reported during a scan, overall and by
+// detector. When results are deduplicated, each distinct secret is counted
+// once.
+type ResultSummary struct {
+	Total      uint64 `json:"total"`
+	Verified   uint64 `json:"verified"`
+	Unverified uint64 `json:"unverified"`
+	// Detectors is ordered by decreasing number of results, then by name.
+	Detectors []DetectorResults `json:"detectors"`
+}
+
+// detectorKey identifies a detector. Custom detectors share a detector type,
+// so they're told apart by their name.
+type detectorKey struct {
+	detectorType detectorspb.DetectorType
+	name         string
+}
+
+// resultSummarizer collects the ResultSummary of a scan.
+type resultSummarizer struct {
+	mu     sync.Mutex
+	counts map[detectorKey]*DetectorResults
+}
+
+func newResultSummarizer() *resultSummarizer {
+	return &resultSummarizer{counts: make(map[detectorKey]*DetectorResults)}
+}
+
+// add counts a reported result.
+func (s *resultSummarizer) add(r *detectors.ResultWithMetadata) {
+	key := detectorKey{detectorType: r.DetectorType, name: r.DetectorName}
+	if key.name == "" {
+		key.name = r.DetectorType.String()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.counts[key]
+	if !ok {
+		c = &DetectorResults{DetectorType: key.detectorType, DetectorName: key.name}
+		s.counts[key] = c
+	}
+	if r.Verified {
+		c.Verified++
+	} else {
+		c.Unverified++
+	}
+}
+
+// summary returns a snapshot of the counts.
+func (s *resultSummarizer) summary() ResultSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := ResultSummary{Detectors: make([]DetectorResults, 0, len(s.counts))}
+	for _, c := range s.counts {
+		summary.Verified += c.Verified
+		summary.Unverified += c.Unverified
+		summary.Detectors = append(summary.Detectors, *c)
+	}
+	summary.Total = summary.Verified + summary.Unverified
+	sort.Slice(summary.Detectors, func(i, j int) bool {
+		a, b := summary.Detectors[i], summary.Detectors[j]
+		if a.Total() != b.Total() {
+			return a.Total() > b.Total()
+		}
+		return a.DetectorName < b.DetectorName
+	})
+	return summary
+}
+
+// ResultSummary returns the number of results reported during the scan, by
+// detector and verification status. It should be called after Finish.
+func (e *Engine) ResultSummary() ResultSummary {
+	return e.resultSummarizer.summary()
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// ErrResultsLimitReached is the cause of the cancellation of the sources once
+// the limit set with WithResultsLimit is reached.
+var ErrResultsLimitReached = errors.New("results limit reached")
+
+// WithResultsLimit stops the scan once limit results are found, or limit
+// verified results if verifiedOnly is set. The sources are stopped, the chunks
+// they already produced are still scanned, and the results past the limit are
+// dropped, so exactly limit results are counted. Suppressed results don't
+// count, and with WithDedupResults each occurrence of a secret counts. Zero
+// means no limit.
+//
+// Workers find results concurrently, so which results a limited scan reports
+// depends on which workers finish first, and varies from one scan to the next.
+func WithResultsLimit(limit int, verifiedOnly bool) Option {
+	return func(e *Engine) {
+		e.resultsLimiter = nil
+		if limit > 0 {
+			e.resultsLimiter = &resultsLimiter{limit: int64(limit), verifiedOnly: verifiedOnly}
+		}
+	}
+}
+
+// resultsLimiter counts the results found by every notifier worker towards the
+// limit.
+type resultsLimiter struct {
+	limit        int64
+	verifiedOnly bool
+	count        atomic.Int64
+}
+
+// add counts r if it counts towards the limit, and reports whether it's within
+// the limit and whether it's the result that reached it.
+func (l *resultsLimiter) add(r *detectors.ResultWithMetadata) (allowed, reached bool) {
+	if l.verifiedOnly && !r.Verified {
+		return true, false
+	}
+	n := l.count.Add(1)
+	return n <= l.limit, n == l.limit
+}
+
+// reached reports whether the limit was reached.
+func (l *resultsLimiter) reached() bool {
+	return l.count.Load() >= l.limit
+}
+
+// limitResult reports whether r is within the results limit, if any, and stops
+// the sources once the limit is reached.
+func (e *Engine) limitResult(ctx context.Context, r *detectors.ResultWithMetadata) bool {
+	if e.resultsLimiter == nil {
+		return true
+	}
+	allowed, reached := e.resultsLimiter.add(r)
+	if reached {
+		ctx.Logger().Info("results limit reached, stopping the scan",
+			"limit", e.resultsLimiter.limit, "verified_only", e.resultsLimiter.verifiedOnly)
+		e.sourceManager.Stop(ErrResultsLimitReached)
+	}
+	return allowed
+}
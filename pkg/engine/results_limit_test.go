@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestResultsLimiter(t *testing.T) {
+	verified := &detectors.ResultWithMetadata{Result: detectors.Result{Verified: true}}
+	unverified := &detectors.ResultWithMetadata{}
+
+	l := &resultsLimiter{limit: 2, verifiedOnly: true}
+	for _, tt := range []struct {
+		r                        *detectors.ResultWithMetadata
+		wantAllowed, wantReached bool
+	}{
+		{r: unverified, wantAllowed: true},
+		{r: verified, wantAllowed: true},
+		{r: unverified, wantAllowed: true},
+		{r: verified, wantAllowed: true, wantReached: true},
+		{r: verified},
+		// Unverified results don't count, so they're still allowed.
+		{r: unverified, wantAllowed: true},
+	} {
+		allowed, reached := l.add(tt.r)
+		assert.Equal(t, tt.wantAllowed, allowed)
+		assert.Equal(t, tt.wantReached, reached)
+	}
+	assert.True(t, l.reached())
+}
+
+func TestEngine_ResultsLimit(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for i := 0; i < 50; i++ {
+		data := []byte(fakeDetectorKeyword + " = fake secret v1")
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("config%d.env", i)), data, 0o600))
+	}
+
+	printer := new(resultCollector)
+	e, err := Start(ctx,
+		WithConcurrency(4),
+		WithDetectors(fakeDetectorV1{}),
+		WithVerify(false),
+		WithPrinter(printer),
+		WithResultsLimit(3, false),
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, e.ScanFileSystem(ctx, sources.FilesystemConfig{Paths: []string{dir}}))
+	assert.NoError(t, e.Finish(ctx))
+
+	assert.Len(t, printer.results, 3)
+	metrics := e.GetMetrics()
+	assert.True(t, metrics.ResultsLimitReached)
+	// The scan was stopped on purpose, so it's not incomplete.
+	assert.False(t, metrics.ScanIncomplete)
+}
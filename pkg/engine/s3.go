@@ -2,7 +2,6 @@ package engine
 
 import (
 	"fmt"
-	"runtime"
 
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -52,7 +51,13 @@ func (e *Engine) ScanS3(ctx context.Context, c sources.S3Config) error {
 
 	if len(c.Roles) > 0 {
 		connection.Roles = c.Roles
+		connection.ExternalId = c.ExternalID
 	}
+	connection.MaxObjectSize = c.MaxObjectSize
+	connection.ScanObjectVersions = c.ScanObjectVersions
+	connection.IncludePrefixes = c.IncludePrefixes
+	connection.ExcludePrefixes = c.ExcludePrefixes
+	connection.ExcludeSuffixes = c.ExcludeSuffixes
 
 	var conn anypb.Any
 	err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{})
@@ -65,9 +70,10 @@ func (e *Engine) ScanS3(ctx context.Context, c sources.S3Config) error {
 	sourceID, jobID, _ := e.sourceManager.GetIDs(ctx, sourceName, s3.SourceType)
 
 	s3Source := &s3.Source{}
-	if err := s3Source.Init(ctx, sourceName, jobID, sourceID, true, &conn, runtime.NumCPU()); err != nil {
+	if err := s3Source.Init(ctx, sourceName, jobID, sourceID, true, &conn, e.initConcurrency(s3.SourceType, c.Concurrency)); err != nil {
 		return err
 	}
+	s3Source.SetCheckpoint(e.checkpoint)
 	_, err = e.sourceManager.Run(ctx, sourceName, s3Source)
 	return err
 }
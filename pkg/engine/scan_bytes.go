@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// resultCollector is a Printer that keeps the results it's given.
+type resultCollector struct {
+	mu      sync.Mutex
+	results []detectors.ResultWithMetadata
+}
+
+func (c *resultCollector) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = append(c.results, *r)
+	return nil
+}
+
+// ScanBytes scans an in-memory buffer and returns the results once the scan
+// is complete. The data goes through the same pipeline as the chunks of a
+// source, so it is decoded and its results are verified and filtered
+// according to options, like a scan started with Start. Any printer set in
+// options is replaced, as the results are returned instead of printed.
+//
+// Each call starts and finishes its own engine, so callers scanning a stream
+// of data should start an engine once and use ScanChunk instead.
+func ScanBytes(ctx context.Context, data []byte, options ...Option) ([]detectors.ResultWithMetadata, error) {
+	collector := new(resultCollector)
+	// Don't append to the caller's slice, which may have spare capacity.
+	options = append(options[:len(options):len(options)], WithPrinter(collector))
+
+	e, err := Start(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the data like a source would, so detectors see chunks of the usual size.
+	chunkReader := sources.NewChunkReader(sources.WithPeekSize(e.chunkOverlap))
+	for chunk := range chunkReader(ctx, bytes.NewReader(data)) {
+		if err := chunk.Error(); err != nil {
+			_ = e.Finish(ctx)
+			return nil, err
+		}
+		e.ScanChunk(&sources.Chunk{Data: chunk.Bytes(), Verify: e.verify})
+	}
+
+	if err := e.Finish(ctx); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return collector.results, nil
+}
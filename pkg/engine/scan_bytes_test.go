@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+func TestScanBytes(t *testing.T) {
+	ctx := context.Background()
+
+	results, err := ScanBytes(ctx, []byte("nothing to see here"), WithDetectors(fakeDetectorV1{}), WithConcurrency(1))
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	// The data is decoded before it is scanned.
+	data := []byte("token: " + base64.StdEncoding.EncodeToString([]byte("key = "+fakeDetectorKeyword+"-0123456789")))
+	results, err = ScanBytes(ctx, data, WithDetectors(fakeDetectorV1{}), WithConcurrency(1))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "fake secret v1", string(results[0].Raw))
+		assert.Equal(t, detectorspb.DecoderType_BASE64, results[0].DecoderType)
+	}
+
+	// Buffers larger than a chunk are scanned entirely.
+	data = []byte(strings.Repeat("x", 100_000) + fakeDetectorKeyword)
+	results, err = ScanBytes(ctx, data, WithDetectors(fakeDetectorV1{}), WithConcurrency(1))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	// The printer is replaced, so results are only returned.
+	printer := new(resultCollector)
+	results, err = ScanBytes(ctx, []byte(fakeDetectorKeyword), WithDetectors(fakeDetectorV1{}), WithPrinter(printer))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Empty(t, printer.results)
+}
+
+func TestScanBytesCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ScanBytes(ctx, []byte(fakeDetectorKeyword), WithDetectors(fakeDetectorV1{}), WithConcurrency(1))
+	assert.ErrorIs(t, err, ctx.Err())
+}
@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// SourceStats summarizes the scan of a single source.
+type SourceStats struct {
+	SourceName string           `json:"source_name"`
+	SourceID   sources.SourceID `json:"source_id"`
+	// Units is the number of units the source enumerated. It is zero for
+	// sources that don't support enumeration.
+	Units uint64 `json:"units"`
+	// SkippedUnits is the number of units that failed and were skipped, and
+	// SkippedErrors their errors, with WithContinueOnSourceError.
+	SkippedUnits  uint64   `json:"skipped_units,omitempty"`
+	SkippedErrors []string `json:"skipped_errors,omitempty"`
+	// Chunks and Bytes count the chunks the source produced and their size.
+	Chunks uint64 `json:"chunks"`
+	Bytes  uint64 `json:"bytes"`
+	// Results is the number of results reported for the source.
+	Results uint64        `json:"results"`
+	Elapsed time.Duration `json:"elapsed_ns"`
+}
+
+// sourceCounters holds the counters of a single source. The counters are
+// updated atomically by concurrent workers.
+type sourceCounters struct {
+	name                          string
+	units, chunks, bytes, results atomic.Uint64
+	mu                            sync.Mutex
+	start, end                    time.Time
+	skipped                       []string
+}
+
+// sourceStatsHook collects SourceStats for every source the engine runs.
+type sourceStatsHook struct {
+	sources.NoopHook
+
+	mu       sync.Mutex
+	counters map[sources.SourceID]*sourceCounters
+}
+
+func newSourceStatsHook() *sourceStatsHook {
+	return &sourceStatsHook{counters: make(map[sources.SourceID]*sourceCounters)}
+}
+
+// source returns the counters of the source, creating them if needed.
+func (h *sourceStatsHook) source(id sources.SourceID, name string) *sourceCounters {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	c, ok := h.counters[id]
+	if !ok {
+		c = &sourceCounters{name: name}
+		h.counters[id] = c
+	}
+	return c
+}
+
+func (h *sourceStatsHook) Start(ref sources.JobProgressRef, start time.Time) {
+	c := h.source(ref.SourceID, ref.SourceName)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.start = start
+}
+
+func (h *sourceStatsHook) End(ref sources.JobProgressRef, end time.Time) {
+	c := h.source(ref.SourceID, ref.SourceName)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.end = end
+}
+
+func (h *sourceStatsHook) ReportUnit(ref sources.JobProgressRef, _ sources.SourceUnit) {
+	h.source(ref.SourceID, ref.SourceName).units.Add(1)
+}
+
+func (h *sourceStatsHook) ReportError(ref sources.JobProgressRef, err error) {
+	var skipped sources.Skipped
+	if !errors.As(err, &skipped) {
+		return
+	}
+	c := h.source(ref.SourceID, ref.SourceName)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skipped = append(c.skipped, skipped.Unwrap().Error())
+}
+
+func (h *sourceStatsHook) ReportChunk(ref sources.JobProgressRef, _ sources.SourceUnit, chunk *sources.Chunk) {
+	c := h.source(ref.SourceID, ref.SourceName)
+	c.chunks.Add(1)
+	if chunk != nil {
+		c.bytes.Add(uint64(len(chunk.Data)))
+	}
+}
+
+// reportResult counts a result found in the source.
+func (h *sourceStatsHook) reportResult(id sources.SourceID, name string) {
+	h.source(id, name).results.Add(1)
+}
+
+// stats returns a snapshot of the statistics of every source, ordered by
+// source ID.
+func (h *sourceStatsHook) stats() []SourceStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := make([]SourceStats, 0, len(h.counters))
+	for id, c := range h.counters {
+		c.mu.Lock()
+		skipped := append([]string(nil), c.skipped...)
+		var elapsed time.Duration
+		switch {
+		case c.start.IsZero():
+		case c.end.IsZero():
+			elapsed = time.Since(c.start)
+		default:
+			elapsed = c.end.Sub(c.start)
+		}
+		c.mu.Unlock()
+
+		stats = append(stats, SourceStats{
+			SourceName:    c.name,
+			SourceID:      id,
+			Units:         c.units.Load(),
+			SkippedUnits:  uint64(len(skipped)),
+			SkippedErrors: skipped,
+			Chunks:        c.chunks.Load(),
+			Bytes:         c.bytes.Load(),
+			Results:       c.results.Load(),
+			Elapsed:       elapsed,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].SourceID < stats[j].SourceID })
+	return stats
+}
+
+// SourceStats returns statistics about each source the engine scanned. It
+// should be called after Finish.
+func (e *Engine) SourceStats() []SourceStats {
+	return e.sourceStatsHook.stats()
+}
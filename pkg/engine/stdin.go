@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/stdin"
+)
+
+// ScanStdin scans the content piped to the standard input.
+func (e *Engine) ScanStdin(ctx context.Context) error {
+	var conn anypb.Any
+	err := anypb.MarshalFrom(&conn, &sourcespb.Stdin{}, proto.MarshalOptions{})
+	if err != nil {
+		ctx.Logger().Error(err, "failed to marshal stdin connection")
+		return err
+	}
+
+	sourceName := "trufflehog - stdin"
+	sourceID, jobID, _ := e.sourceManager.GetIDs(ctx, sourceName, stdin.SourceType)
+
+	stdinSource := &stdin.Source{}
+	if err := stdinSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, 1); err != nil {
+		return err
+	}
+	_, err = e.sourceManager.Run(ctx, sourceName, stdinSource)
+	return err
+}
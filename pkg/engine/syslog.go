@@ -47,7 +47,6 @@ func (e *Engine) ScanSyslog(ctx context.Context, c sources.SyslogConfig) error {
 	if err := syslogSource.Init(ctx, sourceName, jobID, sourceID, true, &conn, c.Concurrency); err != nil {
 		return err
 	}
-	syslogSource.InjectConnection(connection)
 
 	_, err = e.sourceManager.Run(ctx, sourceName, syslogSource)
 	return err
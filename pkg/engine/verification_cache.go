@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"maps"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// verificationCacheSize is the number of verification outcomes kept by the
+// verification cache. The least recently used outcome is evicted first.
+const verificationCacheSize = 10_000
+
+// WithVerificationCache configures whether the outcome of verifying a secret
+// is reused for later occurrences of the same secret found by the same
+// detector, wherever they're found, instead of verifying it again. It is
+// enabled by default. Outcomes are kept in memory for the duration of the
+// scan, and verifications that failed with an error are never reused.
+func WithVerificationCache(enabled bool) Option {
+	return func(e *Engine) { e.verificationCacheEnabled = enabled }
+}
+
+// verificationCacheKey identifies the secret of a result found by a
+// detector. The secret is hashed so cached keys don't hold on to it.
+type verificationCacheKey struct {
+	detector ahocorasick.DetectorKey
+	secret   [sha256.Size]byte
+}
+
+func newVerificationCacheKey(detector detectors.Detector, r detectors.Result) verificationCacheKey {
+	secret := sha256.New()
+	secret.Write(r.Raw)
+	secret.Write([]byte{0})
+	secret.Write(r.RawV2)
+	key := verificationCacheKey{detector: ahocorasick.CreateDetectorKey(detector)}
+	secret.Sum(key.secret[:0])
+	return key
+}
+
+// verificationOutcome is the part of a result set by its verification.
+type verificationOutcome struct {
+	verified       bool
+	extraData      map[string]string
+	structuredData *detectorspb.StructuredData
+}
+
+// verificationCache stores the verification outcome of secrets, keyed by
+// detector and raw secret. It is safe for concurrent use.
+type verificationCache struct {
+	outcomes *lru.Cache[verificationCacheKey, verificationOutcome]
+}
+
+func newVerificationCache(size int) (*verificationCache, error) {
+	outcomes, err := lru.New[verificationCacheKey, verificationOutcome](size)
+	if err != nil {
+		return nil, err
+	}
+	return &verificationCache{outcomes: outcomes}, nil
+}
+
+// apply sets the cached verification outcome of every result and reports
+// whether all of them were found. The results are left unchanged otherwise.
+func (c *verificationCache) apply(detector detectors.Detector, results []detectors.Result) bool {
+	outcomes := make([]verificationOutcome, len(results))
+	for i, r := range results {
+		outcome, ok := c.outcomes.Get(newVerificationCacheKey(detector, r))
+		if !ok {
+			return false
+		}
+		outcomes[i] = outcome
+	}
+
+	for i, outcome := range outcomes {
+		results[i].Verified = outcome.verified
+		if len(outcome.extraData) > 0 {
+			if results[i].ExtraData == nil {
+				results[i].ExtraData = make(map[string]string, len(outcome.extraData))
+			}
+			maps.Copy(results[i].ExtraData, outcome.extraData)
+		}
+		if outcome.structuredData != nil {
+			results[i].StructuredData = outcome.structuredData
+		}
+	}
+	return true
+}
+
+// store caches the verification outcome of every result whose verification
+// didn't fail.
+func (c *verificationCache) store(detector detectors.Detector, results []detectors.Result) {
+	for _, r := range results {
+		if r.VerificationError() != nil {
+			continue
+		}
+		c.outcomes.Add(newVerificationCacheKey(detector, r), verificationOutcome{
+			verified:       r.Verified,
+			extraData:      maps.Clone(r.ExtraData),
+			structuredData: r.StructuredData,
+		})
+	}
+}
+
+// detectCachedMatch finds the secrets of a match without verifying them and
+// reports whether the verification outcome of every secret was cached. If
+// so, the secrets are returned with their cached outcome. Finding the
+// secrets makes no requests, so it's cheap compared to verifying them.
+func (e *Engine) detectCachedMatch(ctx context.Context, data detectableChunk, match []byte) ([]detectors.Result, bool) {
+	detectCtx, cancel := context.WithTimeout(ctx, e.timeoutFor(data.detector.Detector))
+	defer cancel()
+
+	results, err := data.detector.FromData(detectCtx, false, match)
+	if err != nil {
+		return nil, false
+	}
+	if !e.verificationCache.apply(data.detector.Detector, results) {
+		return nil, false
+	}
+	return results, true
+}
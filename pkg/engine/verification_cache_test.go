@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"bytes"
+	aCtx "context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// countingDetector reports the word after its keyword as a secret and counts
+// how many times it's called and verifies it. Secrets starting with "error"
+// fail to verify.
+type countingDetector struct {
+	calls, verifications *atomic.Int32
+}
+
+func (d countingDetector) FromData(_ aCtx.Context, verify bool, data []byte) ([]detectors.Result, error) {
+	d.calls.Add(1)
+	secret, _, _ := bytes.Cut(data[len(fakeDetectorKeyword)+1:], []byte(" "))
+	r := detectors.Result{
+		DetectorType: detectorspb.DetectorType(-1),
+		Raw:          secret,
+	}
+	if verify {
+		d.verifications.Add(1)
+		if string(secret[:5]) == "error" {
+			r.SetVerificationError(errors.New("verification failed"))
+		} else {
+			r.Verified = true
+			r.ExtraData = map[string]string{"account": "account of " + string(secret)}
+		}
+	}
+	return []detectors.Result{r}, nil
+}
+
+func (d countingDetector) Keywords() []string             { return []string{fakeDetectorKeyword} }
+func (d countingDetector) Type() detectorspb.DetectorType { return detectorspb.DetectorType(-1) }
+
+func TestEngine_VerificationCache(t *testing.T) {
+	tests := []struct {
+		name              string
+		enabled           bool
+		wantVerifications int32
+		wantCalls         int32
+	}{
+		// Every match is detected, and matches with uncached secrets are detected again to verify them.
+		{name: "enabled", enabled: true, wantVerifications: 4, wantCalls: 10},
+		{name: "disabled", enabled: false, wantVerifications: 6, wantCalls: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			e, err := Start(ctx,
+				WithConcurrency(1),
+				WithVerify(true),
+				WithPrinter(new(resultCollector)),
+				WithVerificationCache(tt.enabled),
+			)
+			assert.NoError(t, err)
+			assert.NoError(t, e.Finish(ctx))
+			assert.Equal(t, tt.enabled, e.verificationCache != nil)
+
+			detector := countingDetector{calls: new(atomic.Int32), verifications: new(atomic.Int32)}
+			data := detectableChunk{
+				detector: &ahocorasick.DetectorMatch{Detector: detector},
+				chunk:    sources.Chunk{Verify: true},
+			}
+			// The same secret is found in matches with different surroundings.
+			for _, match := range []string{"secret1", "secret1 in a config", "secret2", "secret1 in a script", "error1", "error1"} {
+				secret, _, _ := strings.Cut(match, " ")
+				results, err := e.detectMatch(ctx, data, []byte(fakeDetectorKeyword+" "+match))
+				assert.NoError(t, err)
+				assert.Len(t, results, 1)
+				r := results[0]
+				if secret == "error1" {
+					assert.False(t, r.Verified)
+					assert.Error(t, r.VerificationError())
+					continue
+				}
+				assert.True(t, r.Verified, secret)
+				assert.Equal(t, "account of "+secret, r.ExtraData["account"])
+			}
+			assert.Equal(t, tt.wantVerifications, detector.verifications.Load())
+			assert.Equal(t, tt.wantCalls, detector.calls.Load())
+
+			// Cached results can be modified without affecting later matches.
+			results, err := e.detectMatch(ctx, data, []byte(fakeDetectorKeyword+" secret2"))
+			assert.NoError(t, err)
+			results[0].ExtraData["account"] = "modified"
+			results, err = e.detectMatch(ctx, data, []byte(fakeDetectorKeyword+" secret2"))
+			assert.NoError(t, err)
+			assert.Equal(t, "account of secret2", results[0].ExtraData["account"])
+
+			// Unverified chunks don't use the cache.
+			data.chunk.Verify = false
+			results, err = e.detectMatch(ctx, data, []byte(fakeDetectorKeyword+" secret1"))
+			assert.NoError(t, err)
+			assert.False(t, results[0].Verified)
+		})
+	}
+}
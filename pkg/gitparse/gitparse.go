@@ -95,6 +95,19 @@ func (d *Diff) Len() int { return d.contentWriter.Len() }
 // ReadCloser returns a ReadCloser for the contentWriter.
 func (d *Diff) ReadCloser() (io.ReadCloser, error) { return d.contentWriter.ReadCloser() }
 
+// SetContent replaces the content of the diff, such as to blank lines that shouldn't be scanned.
+func (d *Diff) SetContent(data []byte) error {
+	writer := bufferwriter.New()
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.CloseForWriting(); err != nil {
+		return err
+	}
+	d.contentWriter = writer
+	return nil
+}
+
 // write delegates to the contentWriter.
 func (d *Diff) write(p []byte) error {
 	_, err := d.contentWriter.Write(p)
@@ -222,6 +235,33 @@ func NewParser(options ...Option) *Parser {
 // RepoPath parses the output of the `git log` command for the `source` path.
 // The Diff chan will return diffs in the order they are parsed from the log.
 func (c *Parser) RepoPath(ctx context.Context, source string, head string, abbreviatedLog bool, excludedGlobs []string, isBare bool) (chan *Diff, error) {
+	revisions := []string{"--all"}
+	if head != "" {
+		revisions = []string{head}
+	}
+	return c.RepoPathRevisions(ctx, source, revisions, abbreviatedLog, excludedGlobs, isBare)
+}
+
+// RepoPathRevisions parses the output of the `git log` command for the
+// `source` path, limited to the commits selected by `revisions` (e.g. a list
+// of refs, optionally with `^<commit>` exclusions).
+func (c *Parser) RepoPathRevisions(ctx context.Context, source string, revisions []string, abbreviatedLog bool, excludedGlobs []string, isBare bool) (chan *Diff, error) {
+	cmd := logCommand(ctx, source, revisions, abbreviatedLog, excludedGlobs, isBare)
+	return c.executeCommand(ctx, cmd, false)
+}
+
+// RepoPathCommits parses the output of the `git log` command for the
+// `source` path, limited to exactly the given commits, in the given order.
+// The commits are passed to git on stdin, so any number of them can be given.
+func (c *Parser) RepoPathCommits(ctx context.Context, source string, commits []string, abbreviatedLog bool, excludedGlobs []string, isBare bool) (chan *Diff, error) {
+	cmd := logCommand(ctx, source, []string{"--no-walk=unsorted", "--stdin"}, abbreviatedLog, excludedGlobs, isBare)
+	cmd.Stdin = strings.NewReader(strings.Join(commits, "\n") + "\n")
+	return c.executeCommand(ctx, cmd, false)
+}
+
+// logCommand returns the `git log` command that lists the commits selected
+// by `revisions` with their patches.
+func logCommand(ctx context.Context, source string, revisions []string, abbreviatedLog bool, excludedGlobs []string, isBare bool) *exec.Cmd {
 	args := []string{
 		"-C", source,
 		"log",
@@ -234,16 +274,12 @@ func (c *Parser) RepoPath(ctx context.Context, source string, head string, abbre
 	if abbreviatedLog {
 		args = append(args, "--diff-filter=AM")
 	}
-	if head != "" {
-		args = append(args, head)
-	} else {
-		args = append(args, "--all")
-	}
+	args = append(args, revisions...)
 	for _, glob := range excludedGlobs {
 		args = append(args, "--", ".", fmt.Sprintf(":(exclude)%s", glob))
 	}
 
-	cmd := exec.Command("git", args...)
+	cmd := exec.CommandContext(ctx, "git", args...)
 	absPath, err := filepath.Abs(source)
 	if err == nil {
 		if !isBare {
@@ -262,8 +298,7 @@ func (c *Parser) RepoPath(ctx context.Context, source string, head string, abbre
 			}
 		}
 	}
-
-	return c.executeCommand(ctx, cmd, false)
+	return cmd
 }
 
 // Staged parses the output of the `git diff` command for the `source` path.
@@ -271,7 +306,7 @@ func (c *Parser) Staged(ctx context.Context, source string) (chan *Diff, error)
 	// Provide the --cached flag to diff to get the diff of the staged changes.
 	args := []string{"-C", source, "diff", "-p", "--cached", "--full-history", "--diff-filter=AM", "--date=format:%a %b %d %H:%M:%S %Y %z"}
 
-	cmd := exec.Command("git", args...)
+	cmd := exec.CommandContext(ctx, "git", args...)
 
 	absPath, err := filepath.Abs(source)
 	if err == nil {
@@ -281,6 +316,31 @@ func (c *Parser) Staged(ctx context.Context, source string) (chan *Diff, error)
 	return c.executeCommand(ctx, cmd, true)
 }
 
+// Unstaged parses the output of the `git diff` command for the unstaged changes of the working
+// tree, which are the changes that haven't been added to the index.
+func (c *Parser) Unstaged(ctx context.Context, source string) (chan *Diff, error) {
+	// Without the --cached flag, diff compares the working tree to the index.
+	args := []string{"-C", source, "diff", "-p", "--full-history", "--diff-filter=AM", "--date=format:%a %b %d %H:%M:%S %Y %z"}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+
+	absPath, err := filepath.Abs(source)
+	if err == nil {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("GIT_DIR=%s", filepath.Join(absPath, ".git")))
+	}
+
+	return c.executeCommand(ctx, cmd, true)
+}
+
+// Diff parses the output of the `git diff` command for the changes between two commits, or trees.
+func (c *Parser) Diff(ctx context.Context, source, from, to string) (chan *Diff, error) {
+	args := []string{"-C", source, "diff", "-p", "--full-history", "--diff-filter=AM", "--date=format:%a %b %d %H:%M:%S %Y %z", from, to}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+
+	return c.executeCommand(ctx, cmd, true)
+}
+
 // executeCommand runs an exec.Cmd, reads stdout and stderr, and waits for the Cmd to complete.
 func (c *Parser) executeCommand(ctx context.Context, cmd *exec.Cmd, isStaged bool) (chan *Diff, error) {
 	diffChan := make(chan *Diff, 64)
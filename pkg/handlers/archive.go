@@ -1,14 +1,18 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/mholt/archiver/v4"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common/glob"
 	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
 )
 
@@ -23,22 +27,50 @@ var (
 	maxDepth   = 5
 	maxSize    = 2 << 30 // 2 GB
 	maxTimeout = time.Duration(30) * time.Second
+	// entryFilter selects the archive entries that are scanned. Every entry
+	// is scanned if it is nil.
+	entryFilter *glob.Filter
+	// scanExtensions limits the archive entries that are scanned to those with
+	// one of its extensions. Every entry is scanned if it is empty.
+	scanExtensions common.ExtensionSet
 )
 
 // SetArchiveMaxSize sets the maximum size of the archive.
 func SetArchiveMaxSize(size int) { maxSize = size }
 
-// SetArchiveMaxDepth sets the maximum depth of the archive.
+// SetArchiveMaxDepth sets the default maximum depth of the archive.
+// It can be overridden for a single HandleFile call using WithMaxArchiveDepth.
 func SetArchiveMaxDepth(depth int) { maxDepth = depth }
 
+// SetArchiveEntryFilter sets the default filter of the archive entries that are scanned.
+// It can be overridden for a single HandleFile call using WithArchiveEntryFilter.
+func SetArchiveEntryFilter(filter *glob.Filter) { entryFilter = filter }
+
+// SetScanExtensions sets the default extensions of the archive entries that are scanned.
+// It can be overridden for a single HandleFile call using WithScanExtensions.
+func SetScanExtensions(extensions common.ExtensionSet) { scanExtensions = extensions }
+
 // SetArchiveMaxTimeout sets the maximum timeout for the archive handler.
 func SetArchiveMaxTimeout(timeout time.Duration) { maxTimeout = timeout }
 
 // archiveHandler is a handler for common archive files that are supported by the archiver library.
-type archiveHandler struct{ *defaultHandler }
+type archiveHandler struct {
+	*defaultHandler
+	// maxDepth is the maximum nesting depth the handler will extract before halting.
+	maxDepth int
+	// entryFilter selects the entries that are scanned, by their path in the archive
+	// they belong to. It applies at every depth. Entries that are archives themselves
+	// are always extracted, so their own entries can be filtered.
+	entryFilter *glob.Filter
+	// scanExtensions restricts the entries that are scanned to those with one of
+	// its extensions, in the same way as entryFilter.
+	scanExtensions common.ExtensionSet
+	// depthReached records that content nested deeper than maxDepth was skipped.
+	depthReached atomic.Bool
+}
 
-func newArchiveHandler() *archiveHandler {
-	return &archiveHandler{defaultHandler: newDefaultHandler(archiveHandlerType)}
+func newArchiveHandler(maxDepth int) *archiveHandler {
+	return &archiveHandler{defaultHandler: newDefaultHandler(archiveHandlerType), maxDepth: maxDepth}
 }
 
 // HandleFile processes the input as either an archive or non-archive based on its content,
@@ -69,20 +101,26 @@ func (h *archiveHandler) HandleFile(ctx logContext.Context, input fileReader) (c
 	return dataChan, nil
 }
 
+// ErrMaxDepthReached is reported when content nested deeper than the maximum archive depth is not scanned.
 var ErrMaxDepthReached = errors.New("max archive depth reached")
 
 // openArchive recursively extracts content from an archive up to a maximum depth, handling nested archives if necessary.
 // It takes a reader from which it attempts to identify and process the archive format. Depending on the archive type,
 // it either decompresses or extracts the contents directly, sending data to the provided channel.
-// Returns an error if the archive cannot be processed due to issues like exceeding maximum depth or unsupported formats.
+// Content nested deeper than the maximum depth is skipped and recorded in depthReached.
+// Returns an error if the archive cannot be processed due to issues like unsupported formats.
 func (h *archiveHandler) openArchive(ctx logContext.Context, depth int, reader fileReader, archiveChan chan []byte) error {
 	if common.IsDone(ctx) {
 		return ctx.Err()
 	}
 
-	if depth >= maxDepth {
+	if depth >= h.maxDepth {
+		// Skip only this entry so its siblings are still extracted. HandleFile reports the skip once
+		// the archive has been handled.
+		ctx.Logger().Info("max archive depth reached, skipping nested content", "depth", depth, "max_depth", h.maxDepth)
 		h.metrics.incMaxArchiveDepthCount()
-		return ErrMaxDepthReached
+		h.depthReached.Store(true)
+		return nil
 	}
 
 	arReader := reader.BufferedFileReader
@@ -93,24 +131,29 @@ func (h *archiveHandler) openArchive(ctx logContext.Context, depth int, reader f
 	switch archive := reader.format.(type) {
 	case archiver.Decompressor:
 		// Decompress tha archive and feed the decompressed data back into the archive handler to extract any nested archives.
-		compReader, err := archive.OpenReader(arReader)
-		if err != nil {
-			return fmt.Errorf("error opening decompressor with format: %s %w", reader.format.Name(), err)
-		}
-		defer compReader.Close()
-
-		rdr, err := newFileReader(compReader)
+		rdr, err := decompress(ctx, reader, archive)
 		if err != nil {
 			if errors.Is(err, ErrEmptyReader) {
 				ctx.Logger().V(5).Info("empty reader, skipping file")
 				return nil
 			}
-			return fmt.Errorf("error creating custom reader: %w", err)
+			return err
 		}
 		defer rdr.Close()
 
 		return h.openArchive(ctx, depth+1, rdr, archiveChan)
 	case archiver.Extractor:
+		if _, ok := archive.(archiver.SevenZip); ok {
+			encrypted, err := isEncryptedSevenZip(reader)
+			if err != nil {
+				return err
+			}
+			if encrypted {
+				ctx.Logger().Info("skipping encrypted 7z archive, its content can't be read without its password")
+				h.metrics.incFilesSkipped()
+				return nil
+			}
+		}
 		err := archive.Extract(logContext.WithValue(ctx, depthKey, depth+1), arReader, nil, h.extractorHandler(archiveChan))
 		if err != nil {
 			return fmt.Errorf("error extracting archive with format: %s: %w", reader.format.Name(), err)
@@ -121,6 +164,34 @@ func (h *archiveHandler) openArchive(ctx logContext.Context, depth int, reader f
 	}
 }
 
+// decompress returns a reader of the decompressed content of reader, which is of a compression format.
+// The decompressed stream is never read fully into memory; newFileReader buffers it in a
+// BufferedFileReader, which spills to disk once its in-memory threshold is exceeded.
+// The decompressed output is capped at maxSize, the same limit applied to files
+// extracted from archives, to guard against decompression bombs.
+func decompress(ctx logContext.Context, reader fileReader, decompressor archiver.Decompressor) (fileReader, error) {
+	compReader, err := decompressor.OpenReader(reader.BufferedFileReader)
+	if err != nil {
+		return fileReader{}, fmt.Errorf("error opening decompressor with format: %s %w", reader.format.Name(), err)
+	}
+
+	rdr, err := newFileReader(struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(compReader, int64(maxSize)), compReader})
+	if err != nil {
+		if errors.Is(err, ErrEmptyReader) {
+			return rdr, err
+		}
+		return rdr, fmt.Errorf("error creating custom reader: %w", err)
+	}
+
+	if rdr.Size() >= maxSize {
+		ctx.Logger().V(2).Info("decompressed size limit reached, truncating", "format", reader.format.Name(), "max_size", maxSize)
+	}
+	return rdr, nil
+}
+
 // extractorHandler creates a closure that handles individual files extracted by an archiver.
 // It logs the extraction, checks for cancellation, and decides whether to skip the file based on its name or type,
 // particularly for binary files if configured to skip. If the file is not skipped, it recursively calls openArchive
@@ -167,6 +238,26 @@ func (h *archiveHandler) extractorHandler(archiveChan chan []byte) func(context.
 		}
 		defer f.Close()
 
+		var entry io.ReadCloser = f
+		if !h.entryFilter.ShouldInclude(file.NameInArchive) || !h.scanExtensions.Matches(file.NameInArchive) {
+			// Only the header is read to tell whether the entry is a nested archive.
+			header := make([]byte, defaultBufferSize)
+			n, err := io.ReadFull(f, header)
+			if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("error reading file %s: %w", file.Name(), err)
+			}
+			header = header[:n]
+			if !IsExtractable(file.Name(), header) {
+				lCtx.Logger().V(2).Info("skipping archive entry not matching the entry filter or extensions", "path", file.NameInArchive)
+				h.metrics.incFilesSkipped()
+				return nil
+			}
+			entry = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(header), f), f}
+		}
+
 		// Archiver v4 is in alpha and using an experimental version of
 		// rardecode. There is a bug somewhere with rar decoder format 29
 		// that can lead to a panic. An issue is open in rardecode repo
@@ -183,7 +274,7 @@ func (h *archiveHandler) extractorHandler(archiveChan chan []byte) func(context.
 			}
 		}()
 
-		rdr, err := newFileReader(f)
+		rdr, err := newFileReader(entry)
 		if err != nil {
 			if errors.Is(err, ErrEmptyReader) {
 				lCtx.Logger().V(5).Info("empty reader, skipping file")
@@ -193,6 +284,10 @@ func (h *archiveHandler) extractorHandler(archiveChan chan []byte) func(context.
 		}
 		defer rdr.Close()
 
+		if err := rdr.identifyByName(file.Name()); err != nil {
+			return fmt.Errorf("error identifying file %s: %w", file.Name(), err)
+		}
+
 		h.metrics.incFilesProcessed()
 		h.metrics.observeFileSize(fileSize)
 
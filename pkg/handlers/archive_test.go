@@ -1,16 +1,26 @@
 package handlers
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"regexp"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common/glob"
 	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sourcestest"
 )
 
 func TestArchiveHandler(t *testing.T) {
@@ -83,7 +93,7 @@ func TestArchiveHandler(t *testing.T) {
 			assert.Equal(t, http.StatusOK, resp.StatusCode)
 			defer resp.Body.Close()
 
-			handler := newArchiveHandler()
+			handler := newArchiveHandler(maxDepth)
 
 			newReader, err := newFileReader(resp.Body)
 			if err != nil {
@@ -115,7 +125,7 @@ func TestOpenInvalidArchive(t *testing.T) {
 	reader := strings.NewReader("invalid archive")
 
 	ctx := logContext.AddLogger(context.Background())
-	handler := archiveHandler{}
+	handler := archiveHandler{maxDepth: maxDepth}
 
 	rdr, err := newFileReader(io.NopCloser(reader))
 	assert.NoError(t, err)
@@ -126,3 +136,274 @@ func TestOpenInvalidArchive(t *testing.T) {
 	err = handler.openArchive(ctx, 0, rdr, archiveChan)
 	assert.Error(t, err)
 }
+
+// nestedTar builds an archive nested levels deep. Each level contains a text file naming its level,
+// followed by a tar holding the next level.
+func nestedTar(t *testing.T, levels int) []byte {
+	t.Helper()
+
+	var inner []byte
+	for level := levels - 1; level >= 0; level-- {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+
+		files := []struct {
+			name string
+			data []byte
+		}{{fmt.Sprintf("level-%d.txt", level), []byte(fmt.Sprintf("secret at level-%d", level))}}
+		if inner != nil {
+			files = append(files, struct {
+				name string
+				data []byte
+			}{fmt.Sprintf("level-%d.tar", level+1), inner})
+		}
+
+		for _, f := range files {
+			assert.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0600, Size: int64(len(f.data))}))
+			_, err := tw.Write(f.data)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, tw.Close())
+		inner = buf.Bytes()
+	}
+	return inner
+}
+
+func TestArchiveHandlerMaxDepth(t *testing.T) {
+	archive := nestedTar(t, 10)
+
+	tests := map[string]struct {
+		maxDepth   int
+		wantLevels int
+	}{
+		"depth-1": {maxDepth: 1, wantLevels: 0},
+		"depth-3": {maxDepth: 3, wantLevels: 2},
+		"depth-5": {maxDepth: 5, wantLevels: 4},
+		"unbound": {maxDepth: 20, wantLevels: 10},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rdr, err := newFileReader(io.NopCloser(bytes.NewReader(archive)))
+			assert.NoError(t, err)
+			defer rdr.Close()
+
+			handler := newArchiveHandler(tc.maxDepth)
+			archiveChan, err := handler.HandleFile(logContext.Background(), rdr)
+			assert.NoError(t, err)
+
+			levels := make(map[string]struct{})
+			re := regexp.MustCompile(`level-\d+`)
+			for chunk := range archiveChan {
+				for _, m := range re.FindAll(chunk, -1) {
+					levels[string(m)] = struct{}{}
+				}
+			}
+
+			assert.Len(t, levels, tc.wantLevels)
+			for level := 0; level < tc.wantLevels; level++ {
+				assert.Contains(t, levels, fmt.Sprintf("level-%d", level))
+			}
+			assert.Equal(t, tc.wantLevels < 10, handler.depthReached.Load())
+		})
+	}
+}
+
+func TestHandleFileMaxArchiveDepthPerBranch(t *testing.T) {
+	// Each top-level archive gets its own depth budget.
+	for i := 0; i < 2; i++ {
+		reporter := sourcestest.TestReporter{}
+		err := HandleFile(
+			logContext.Background(),
+			io.NopCloser(bytes.NewReader(nestedTar(t, 10))),
+			&sources.Chunk{},
+			&reporter,
+			WithMaxArchiveDepth(3),
+			WithFileName("nested.tar"),
+		)
+		assert.NoError(t, err)
+		assert.Len(t, reporter.Chunks, 2)
+
+		// The skipped levels are reported rather than silently dropped.
+		if assert.Len(t, reporter.ChunkErrs, 1) {
+			assert.ErrorIs(t, reporter.ChunkErrs[0], ErrMaxDepthReached)
+			assert.Contains(t, reporter.ChunkErrs[0].Error(), "nested.tar")
+		}
+	}
+}
+
+func TestHandleFileMaxArchiveDepthSiblings(t *testing.T) {
+	// An archive past the depth limit doesn't stop the extraction of the entries that follow it.
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{"nested.tar", nestedTar(t, 2)},
+		{"after.txt", []byte("secret after the nested archive")},
+	} {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0600, Size: int64(len(f.data))}))
+		_, err := tw.Write(f.data)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+
+	reporter := sourcestest.TestReporter{}
+	err := HandleFile(logContext.Background(), io.NopCloser(&buf), &sources.Chunk{}, &reporter, WithMaxArchiveDepth(2))
+	assert.NoError(t, err)
+
+	if assert.Len(t, reporter.Chunks, 1) {
+		assert.Equal(t, "secret after the nested archive", string(reporter.Chunks[0].Data))
+	}
+	assert.Len(t, reporter.ChunkErrs, 1)
+}
+
+func TestHandleFileArchiveEntryFilter(t *testing.T) {
+	filter, err := glob.NewGlobFilter(glob.WithIncludeGlobs("level-[13].txt"))
+	assert.NoError(t, err)
+
+	chunkCh := make(chan *sources.Chunk, 16)
+	err = HandleFile(
+		logContext.Background(),
+		io.NopCloser(bytes.NewReader(nestedTar(t, 5))),
+		&sources.Chunk{},
+		sources.ChanReporter{Ch: chunkCh},
+		WithArchiveEntryFilter(filter),
+	)
+	assert.NoError(t, err)
+	close(chunkCh)
+
+	// Nested archives are extracted even though they don't match, so the
+	// filter applies to their entries too.
+	var got []string
+	for chunk := range chunkCh {
+		got = append(got, string(chunk.Data))
+	}
+	assert.ElementsMatch(t, []string{"secret at level-1", "secret at level-3"}, got)
+}
+
+func TestHandleFileScanExtensions(t *testing.T) {
+	tarOf := func(files map[string][]byte) []byte {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for name, data := range files {
+			assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data))}))
+			_, err := tw.Write(data)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, tw.Close())
+		return buf.Bytes()
+	}
+	archive := tarOf(map[string][]byte{
+		"config/.env": []byte("secret in .env"),
+		"app.JSON":    []byte("secret in app.JSON"),
+		"main.go":     []byte("secret in main.go"),
+		"nested.tar": tarOf(map[string][]byte{
+			"values.yaml": []byte("secret in values.yaml"),
+			"notes.txt":   []byte("secret in notes.txt"),
+		}),
+	})
+
+	chunkCh := make(chan *sources.Chunk, 16)
+	err := HandleFile(
+		logContext.Background(),
+		io.NopCloser(bytes.NewReader(archive)),
+		&sources.Chunk{},
+		sources.ChanReporter{Ch: chunkCh},
+		WithScanExtensions(common.NewExtensionSet("env", ".json", "YAML")),
+	)
+	assert.NoError(t, err)
+	close(chunkCh)
+
+	var got []string
+	for chunk := range chunkCh {
+		got = append(got, string(chunk.Data))
+	}
+	assert.ElementsMatch(t, []string{"secret in .env", "secret in app.JSON", "secret in values.yaml"}, got)
+}
+
+// gzipStream returns a reader producing a gzip stream that decompresses to
+// size bytes of log lines, without holding the data in memory.
+func gzipStream(size int) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		gw, err := gzip.NewWriterLevel(pw, gzip.BestSpeed)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		line := []byte("2024-05-01T12:00:00Z INFO request handled path=/api/v1/items status=200\n")
+		for written := 0; written < size; written += len(line) {
+			if _, err := gw.Write(line); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.CloseWithError(gw.Close())
+	}()
+	return pr
+}
+
+// BenchmarkArchiveHandlerGzipMemory decompresses a large gzip stream and reports
+// the peak heap in use. The decompressed data is chunked as it's read, so the
+// peak stays under a ceiling that doesn't depend on the decompressed size, which
+// is far above it but small enough for an iteration to run in a few seconds.
+func BenchmarkArchiveHandlerGzipMemory(b *testing.B) {
+	if testing.Short() {
+		b.Skip("skipping the decompression of a large stream in short mode")
+	}
+	const (
+		decompressedSize = 512 << 20 // 512 MB
+		maxPeakHeap      = 128 << 20
+	)
+
+	var peak uint64
+	b.SetBytes(decompressedSize)
+	for i := 0; i < b.N; i++ {
+		rdr, err := newFileReader(gzipStream(decompressedSize))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		done := make(chan struct{})
+		sampled := make(chan struct{})
+		go func() {
+			defer close(sampled)
+			ticker := time.NewTicker(10 * time.Millisecond)
+			defer ticker.Stop()
+			var stats runtime.MemStats
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					runtime.ReadMemStats(&stats)
+					if stats.HeapInuse > peak {
+						peak = stats.HeapInuse
+					}
+				}
+			}
+		}()
+
+		dataChan, err := newArchiveHandler(maxDepth).HandleFile(logContext.Background(), rdr)
+		if err != nil {
+			b.Fatal(err)
+		}
+		total := 0
+		for data := range dataChan {
+			total += len(data)
+		}
+		close(done)
+		<-sampled
+		_ = rdr.Close()
+
+		if total < decompressedSize {
+			b.Fatalf("expected at least %d bytes, got %d", decompressedSize, total)
+		}
+	}
+	b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+	if peak > maxPeakHeap {
+		b.Fatalf("peak heap of %d MB, expected at most %d MB", peak>>20, maxPeakHeap>>20)
+	}
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// storedCredential identifies a credential stored in a credentials file.
+type storedCredential struct {
+	// host is the machine the credential is for, or "" for the default netrc entry.
+	host, login string
+}
+
+// parsedCredential is a credential parsed from a credentials file.
+type parsedCredential struct {
+	storedCredential
+	// url is the URL the credential is used with, if known.
+	url      string
+	password string
+	// line is the 1-based line of the password.
+	line int
+}
+
+// credentialsFileParsers parse the credentials files that are known by name.
+var credentialsFileParsers = map[string]func([]byte) ([]parsedCredential, error){
+	".git-credentials": parseGitCredentials,
+	"git-credentials":  parseGitCredentials,
+	".netrc":           parseNetrc,
+	"_netrc":           parseNetrc,
+	"netrc":            parseNetrc,
+}
+
+// isCredentialsFileName reports whether name is the name of a .git-credentials or netrc file.
+func isCredentialsFileName(name string) bool {
+	_, ok := credentialsFileParsers[path.Base(strings.ReplaceAll(name, "\\", "/"))]
+	return ok
+}
+
+// credentialsHandler specializes defaultHandler to parse .git-credentials and netrc files, so
+// that each credential is scanned on its own and attributed to its host and login.
+type credentialsHandler struct {
+	*defaultHandler
+	parse func([]byte) ([]parsedCredential, error)
+}
+
+// newCredentialsHandler creates a credentialsHandler for the credentials file with the given name.
+func newCredentialsHandler(fileName string) *credentialsHandler {
+	return &credentialsHandler{
+		defaultHandler: newDefaultHandler(credentialsHandlerType),
+		parse:          credentialsFileParsers[path.Base(strings.ReplaceAll(fileName, "\\", "/"))],
+	}
+}
+
+// HandleFile processes credentials files, discarding the credentials the data was parsed from.
+func (h *credentialsHandler) HandleFile(ctx logContext.Context, input fileReader) (chan []byte, error) {
+	manifestChan, err := h.HandleManifest(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return manifestDataOnly(ctx, manifestChan), nil
+}
+
+// HandleManifest processes credentials files. Each credential is reported on its own, as the
+// URL it's used with followed by its password, and the rest of the file as is. Files that
+// can't be parsed are reported as plain text.
+func (h *credentialsHandler) HandleManifest(ctx logContext.Context, input fileReader) (chan manifestData, error) {
+	return h.handleManifest(ctx, "credentials file", func(ctx logContext.Context, emit func(manifestData) error) error {
+		return h.handleCredentialsContent(ctx, input, emit)
+	}), nil
+}
+
+// handleCredentialsContent reads a credentials file and passes its data to emit in chunks.
+func (h *credentialsHandler) handleCredentialsContent(ctx logContext.Context, reader io.Reader, emit func(manifestData) error) error {
+	data, err := io.ReadAll(io.LimitReader(reader, maxManifestSize+1))
+	if err != nil {
+		return fmt.Errorf("error reading credentials file: %w", err)
+	}
+	if len(data) > maxManifestSize {
+		return h.emitLines(ctx, io.MultiReader(bytes.NewReader(data), reader), emit)
+	}
+
+	credentials, err := h.parse(data)
+	if err != nil || len(credentials) == 0 {
+		ctx.Logger().V(5).Info("file isn't a valid credentials file, scanning it as plain text", "error", err)
+		return h.emitLines(ctx, bytes.NewReader(data), emit)
+	}
+
+	// The lines of the passwords are removed from the rest of the file, so that they aren't
+	// found again without their attribution.
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for _, c := range credentials {
+		lines[c.line-1] = blankLine(lines[c.line-1])
+	}
+	if rest := bytes.Join(lines, nil); len(bytes.TrimSpace(rest)) > 0 {
+		if err := h.emitLines(ctx, bytes.NewReader(rest), emit); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range credentials {
+		var buf bytes.Buffer
+		if c.url != "" {
+			buf.WriteString(c.url + "\n")
+		}
+		buf.WriteString(c.password + "\n")
+		m := manifestData{line: int64(c.line), credential: &storedCredential{host: c.host, login: c.login}, data: buf.Bytes()}
+		if err := emit(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseGitCredentials parses the credentials stored by git's credential store, one URL with
+// a username and password per line.
+func parseGitCredentials(data []byte) ([]parsedCredential, error) {
+	var credentials []parsedCredential
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		u, err := url.Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL on line %d: %w", line, err)
+		}
+		password, ok := u.User.Password()
+		if u.Scheme == "" || u.Host == "" || !ok || password == "" {
+			return nil, fmt.Errorf("line %d isn't a URL with a username and password", line)
+		}
+		credentials = append(credentials, parsedCredential{
+			storedCredential: storedCredential{host: u.Host, login: u.User.Username()},
+			url:              text,
+			password:         password,
+			line:             line,
+		})
+	}
+	return credentials, scanner.Err()
+}
+
+// netrcToken is a token of a netrc file.
+type netrcToken struct {
+	value string
+	line  int
+}
+
+// parseNetrc parses a netrc file, made of machine entries with a login and password:
+//
+//	machine example.com login user password secret
+//
+// The default entry, which applies to any machine, is reported without a host. Macro
+// definitions are skipped.
+func parseNetrc(data []byte) ([]parsedCredential, error) {
+	tokens := netrcTokens(data)
+
+	var (
+		credentials []parsedCredential
+		entry       *parsedCredential
+	)
+	flush := func() {
+		if entry != nil && entry.password != "" {
+			if entry.host != "" {
+				entry.url = (&url.URL{Scheme: "https", User: url.UserPassword(entry.login, entry.password), Host: entry.host}).String()
+			}
+			credentials = append(credentials, *entry)
+		}
+		entry = nil
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		keyword := tokens[i]
+		switch keyword.value {
+		case "default":
+			flush()
+			entry = &parsedCredential{}
+			continue
+		case "machine", "login", "password", "account", "port", "macdef":
+		default:
+			return nil, fmt.Errorf("unexpected token %q on line %d", keyword.value, keyword.line)
+		}
+
+		if i+1 >= len(tokens) {
+			return nil, fmt.Errorf("missing value for %q on line %d", keyword.value, keyword.line)
+		}
+		i++
+		value := tokens[i]
+		switch keyword.value {
+		case "machine":
+			flush()
+			entry = &parsedCredential{storedCredential: storedCredential{host: value.value}}
+		case "macdef":
+			// Macro bodies were skipped by netrcTokens.
+		default:
+			if entry == nil {
+				return nil, fmt.Errorf("%q outside of a machine entry on line %d", keyword.value, keyword.line)
+			}
+			switch keyword.value {
+			case "login":
+				entry.login = value.value
+			case "password":
+				entry.password, entry.line = value.value, value.line
+			}
+		}
+	}
+	flush()
+	return credentials, nil
+}
+
+// netrcTokens splits a netrc file into whitespace separated tokens. Comments and the bodies of
+// macro definitions, which end at the first empty line, are skipped.
+func netrcTokens(data []byte) []netrcToken {
+	var tokens []netrcToken
+	inMacro := false
+	for i, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if inMacro {
+			inMacro = len(fields) > 0
+			continue
+		}
+		for j, field := range fields {
+			if strings.HasPrefix(field, "#") {
+				break
+			}
+			tokens = append(tokens, netrcToken{value: field, line: i + 1})
+			// The body of a macro starts on the line after its name.
+			if field == "macdef" && j+1 < len(fields) {
+				tokens = append(tokens, netrcToken{value: fields[j+1], line: i + 1})
+				inMacro = true
+				break
+			}
+		}
+	}
+	return tokens
+}
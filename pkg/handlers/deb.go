@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mholt/archiver/v4"
+	"pault.ag/go/debian/deb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	// debControlPrefix and debDataPrefix are the prefixes of the names of the members of a
+	// Debian package holding its control files and the files it installs. They're tar archives,
+	// compressed or not, such as control.tar.gz and data.tar.xz.
+	debControlPrefix = "control.tar"
+	debDataPrefix    = "data.tar"
+	// debControlDir is the directory control files are reported in, as in the tree
+	// dpkg-deb builds packages from.
+	debControlDir = "DEBIAN"
+)
+
+// debHandler specializes packageHandler to manage Debian packages. The maintainer scripts and
+// other control files of a package are scanned along with the files it installs.
+type debHandler struct{ *packageHandler }
+
+// newDebHandler creates a debHandler.
+func newDebHandler(maxDepth int) *debHandler {
+	return &debHandler{packageHandler: newPackageHandler(debHandlerType, maxDepth)}
+}
+
+// HandleFile processes Debian packages, discarding the files the data was found in.
+func (h *debHandler) HandleFile(ctx logContext.Context, input fileReader) (chan []byte, error) {
+	manifestChan, err := h.HandleManifest(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return manifestDataOnly(ctx, manifestChan), nil
+}
+
+// HandleManifest processes Debian packages. The content of each file of the package is reported
+// along with the name of the package and the path of the file.
+func (h *debHandler) HandleManifest(ctx logContext.Context, input fileReader) (chan manifestData, error) {
+	return h.handleManifest(ctx, "deb package", func(ctx logContext.Context, emit func(manifestData) error) error {
+		return h.processDebFiles(ctx, input, emit)
+	}), nil
+}
+
+// processDebFiles extracts the files of the control and data archives of a package. The name of
+// the package is read from its control file first, so that every file is reported with it.
+func (h *debHandler) processDebFiles(ctx logContext.Context, input fileReader, emit func(manifestData) error) error {
+	name, err := debPackageName(ctx, input)
+	if err != nil {
+		ctx.Logger().V(2).Info("unable to read the package name", "error", err)
+	}
+
+	return forEachDebMember(input, func(member *deb.ArEntry) error {
+		var dir string
+		switch {
+		case strings.HasPrefix(member.Name, debControlPrefix):
+			dir = debControlDir
+		case !strings.HasPrefix(member.Name, debDataPrefix):
+			// Other members, such as debian-binary and signatures, hold no files.
+			return nil
+		}
+
+		memberCtx := logContext.WithValues(ctx, "package", name, "member", member.Name)
+		rdr, err := openDebMember(member)
+		if err != nil {
+			if errors.Is(err, ErrEmptyReader) {
+				return nil
+			}
+			return err
+		}
+		defer rdr.Close()
+
+		return extractTar(memberCtx, rdr, func(ctx logContext.Context, file archiver.File) error {
+			filePath := installedPath(file.NameInArchive)
+			if dir != "" {
+				filePath = dir + filePath
+			}
+			f, err := file.Open()
+			if err != nil {
+				return fmt.Errorf("error opening package file %s: %w", filePath, err)
+			}
+			defer f.Close()
+
+			if err := h.handlePackageFile(ctx, f, file.Size(), packageFile{name: name, path: filePath}, emit); err != nil {
+				if common.IsDone(ctx) {
+					return err
+				}
+				ctx.Logger().Error(err, "error handling package file")
+				h.metrics.incErrors()
+			}
+			return nil
+		})
+	})
+}
+
+// forEachDebMember passes each member of the ar archive of a Debian package to handle.
+func forEachDebMember(input fileReader, handle func(member *deb.ArEntry) error) error {
+	arReader, err := deb.LoadAr(input)
+	if err != nil {
+		return fmt.Errorf("error reading deb package: %w", err)
+	}
+	for {
+		member, err := arReader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("error reading deb package: %w", err)
+		}
+		if err := handle(member); err != nil {
+			return err
+		}
+	}
+}
+
+// openDebMember returns a reader of a member of a Debian package, identified by its content and name.
+func openDebMember(member *deb.ArEntry) (fileReader, error) {
+	rdr, err := newFileReader(io.NopCloser(io.LimitReader(member.Data, int64(maxSize))))
+	if err != nil {
+		if errors.Is(err, ErrEmptyReader) {
+			return rdr, err
+		}
+		return rdr, fmt.Errorf("error creating custom reader: %w", err)
+	}
+	if err := rdr.identifyByName(member.Name); err != nil {
+		_ = rdr.Close()
+		return rdr, fmt.Errorf("error identifying %s: %w", member.Name, err)
+	}
+	return rdr, nil
+}
+
+// debPackageName returns the name of a Debian package, from the Package field of its control file.
+func debPackageName(ctx logContext.Context, input fileReader) (string, error) {
+	var name string
+	err := forEachDebMember(input, func(member *deb.ArEntry) error {
+		if name != "" || !strings.HasPrefix(member.Name, debControlPrefix) {
+			return nil
+		}
+		rdr, err := openDebMember(member)
+		if err != nil {
+			return err
+		}
+		defer rdr.Close()
+
+		return extractTar(ctx, rdr, func(_ logContext.Context, file archiver.File) error {
+			if installedPath(file.NameInArchive) != "/control" {
+				return nil
+			}
+			f, err := file.Open()
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				if value, ok := strings.CutPrefix(scanner.Text(), "Package:"); ok {
+					name = strings.TrimSpace(value)
+					break
+				}
+			}
+			return scanner.Err()
+		})
+	})
+	return name, err
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// packageChunkFiles handles the package at path, and returns the number of chunks reported for
+// each of its files.
+func packageChunkFiles(t *testing.T, path string, options ...func(*fileHandlingConfig)) map[packageFile]int {
+	t.Helper()
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+
+	chunkCh := make(chan *sources.Chunk, 1024)
+	chunkSkel := &sources.Chunk{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{File: path},
+			},
+		},
+	}
+	err = HandleFile(logContext.Background(), file, chunkSkel, sources.ChanReporter{Ch: chunkCh}, options...)
+	assert.NoError(t, err)
+	close(chunkCh)
+
+	files := make(map[packageFile]int)
+	for chunk := range chunkCh {
+		fs := chunk.SourceMetadata.GetFilesystem()
+		files[packageFile{name: fs.GetPackageName(), path: fs.GetPackageFile()}]++
+	}
+	return files
+}
+
+func TestHandleDebFile(t *testing.T) {
+	// The control and data archives of the package are compressed with Zstandard.
+	files := packageChunkFiles(t, "testdata/test.deb")
+	assert.Equal(t, map[packageFile]int{
+		{name: "helloworld", path: "DEBIAN/control"}: 1,
+		{name: "helloworld", path: "/main.go"}:       1,
+		{name: "helloworld", path: "/secrettest"}:    178,
+	}, files)
+
+	// The files of the package are nested in it.
+	assert.Empty(t, packageChunkFiles(t, "testdata/test.deb", WithMaxArchiveDepth(1)))
+}
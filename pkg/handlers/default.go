@@ -35,6 +35,11 @@ func newDefaultHandler(handlerType handlerType) *defaultHandler {
 // it processes it accordingly; otherwise, it handles the input as non-archive content.
 // The function returns a channel that will receive the extracted data bytes and an error if the initial setup fails.
 func (h *defaultHandler) HandleFile(ctx logContext.Context, input fileReader) (chan []byte, error) {
+	return h.handleReader(ctx, input), nil
+}
+
+// handleReader is HandleFile for content that's read as a stream, without random access.
+func (h *defaultHandler) handleReader(ctx logContext.Context, input io.Reader) chan []byte {
 	// Shared channel for both archive and non-archive content.
 	dataChan := make(chan []byte, defaultBufferSize)
 
@@ -54,7 +59,7 @@ func (h *defaultHandler) HandleFile(ctx logContext.Context, input fileReader) (c
 		}
 	}()
 
-	return dataChan, nil
+	return dataChan
 }
 
 // measureLatencyAndHandleErrors measures the latency of the file processing and updates the metrics accordingly.
@@ -77,6 +82,14 @@ func (h *defaultHandler) measureLatencyAndHandleErrors(start time.Time, err erro
 // effectively collecting the final bytes for further processing. This function is a key component in ensuring that all
 // file content, regardless of being an archive or not, is handled appropriately.
 func (h *defaultHandler) handleNonArchiveContent(ctx logContext.Context, reader io.Reader, archiveChan chan []byte) error {
+	return h.handleContent(ctx, reader, func(_ int64, data []byte) error {
+		return common.CancellableWrite(ctx, archiveChan, data)
+	})
+}
+
+// handleContent implements handleNonArchiveContent, passing each chunk of data to emit along with the
+// page of the document it was found on. The page is 0 for content that isn't paged.
+func (h *defaultHandler) handleContent(ctx logContext.Context, reader io.Reader, emit func(page int64, data []byte) error) error {
 	bufReader := bufio.NewReaderSize(reader, defaultBufferSize)
 	// A buffer of 512 bytes is used since many file formats store their magic numbers within the first 512 bytes.
 	// If fewer bytes are read, MIME type detection may still succeed.
@@ -88,21 +101,30 @@ func (h *defaultHandler) handleNonArchiveContent(ctx logContext.Context, reader
 	mime := mimetype.Detect(buffer)
 	mimeT := mimeType(mime.String())
 
+	if mimeT == pdfMime {
+		return h.handlePDFContent(ctx, bufReader, emit)
+	}
+
 	if common.SkipFile(mime.Extension()) || common.IsBinary(mime.Extension()) {
 		ctx.Logger().V(5).Info("skipping file", "ext", mimeT)
 		h.metrics.incFilesSkipped()
 		return nil
 	}
 
+	return h.chunkContent(ctx, bufReader, func(data []byte) error { return emit(0, data) })
+}
+
+// chunkContent splits the content read from reader into chunks and passes them to emit.
+func (h *defaultHandler) chunkContent(ctx logContext.Context, reader io.Reader, emit func(data []byte) error) error {
 	chunkReader := sources.NewChunkReader()
-	for data := range chunkReader(ctx, bufReader) {
+	for data := range chunkReader(ctx, reader) {
 		if err := data.Error(); err != nil {
 			ctx.Logger().Error(err, "error reading chunk")
 			h.metrics.incErrors()
 			continue
 		}
 
-		if err := common.CancellableWrite(ctx, archiveChan, data.Bytes()); err != nil {
+		if err := emit(data.Bytes()); err != nil {
 			return err
 		}
 		h.metrics.incBytesProcessed(len(data.Bytes()))
@@ -1,16 +1,25 @@
 package handlers
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"strings"
 
 	"github.com/gabriel-vasile/mimetype"
+	"github.com/klauspost/compress/zstd"
 	"github.com/mholt/archiver/v4"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common/glob"
 	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/readers"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	bufferedfilewriter "github.com/trufflesecurity/trufflehog/v3/pkg/writers/buffered_file_writer"
 )
 
 // fileReader is a custom reader that wraps an io.Reader and provides additional functionality for identifying
@@ -37,7 +46,7 @@ type fileReader struct {
 
 var ErrEmptyReader = errors.New("reader is empty")
 
-func newFileReader(r io.ReadCloser) (fileReader, error) {
+func newFileReader(r io.ReadCloser, opts ...bufferedfilewriter.Option) (fileReader, error) {
 	defer r.Close()
 
 	var (
@@ -45,7 +54,7 @@ func newFileReader(r io.ReadCloser) (fileReader, error) {
 		rdr    *readers.BufferedFileReader
 		err    error
 	)
-	rdr, err = readers.NewBufferedFileReader(r)
+	rdr, err = readers.NewBufferedFileReader(r, opts...)
 	if err != nil {
 		return reader, fmt.Errorf("error creating random access reader: %w", err)
 	}
@@ -67,6 +76,9 @@ func newFileReader(r io.ReadCloser) (fileReader, error) {
 	format, arReader, err := archiver.Identify("", rdr)
 	switch {
 	case err == nil: // Archive detected
+		if _, ok := format.(archiver.Zstd); ok {
+			format = newZstd()
+		}
 		reader.isGenericArchive = true
 		reader.mimeType = mimeType(format.Name())
 		reader.format = format
@@ -89,6 +101,104 @@ func newFileReader(r io.ReadCloser) (fileReader, error) {
 	return reader, nil
 }
 
+const (
+	// brotliExt is the file extension of Brotli compressed files.
+	brotliExt = ".br"
+	// zstdExt is the file extension of Zstandard compressed files.
+	zstdExt = ".zst"
+)
+
+// decompressorFormat is a compression format that can be probed by decoding a prefix of a file.
+type decompressorFormat interface {
+	archiver.Format
+	archiver.Decompressor
+}
+
+// newZstd returns the Zstandard format used to decompress files. The decoder is single-threaded
+// and its window size is capped at maxSize, so a crafted frame can't make it allocate more
+// memory than the decompressed output is allowed to use.
+func newZstd() archiver.Zstd {
+	return archiver.Zstd{DecoderOptions: []zstd.DOption{
+		zstd.WithDecoderConcurrency(1),
+		zstd.WithDecoderMaxMemory(uint64(maxSize)),
+	}}
+}
+
+// identifyByName identifies formats that can't be detected from their content using the
+// name of the file. Brotli streams have no magic number, and Zstandard files may start with
+// a skippable frame instead of the Zstandard magic number, so a file with a .br or .zst
+// extension is treated as that format if a prefix of it decodes successfully.
+// Readers that were already identified as archives are left untouched.
+func (r *fileReader) identifyByName(name string) error {
+	switch lower := strings.ToLower(name); {
+	case strings.HasSuffix(lower, brotliExt):
+		return r.identifyAs(archiver.Brotli{})
+	case strings.HasSuffix(lower, zstdExt):
+		return r.identifyAs(newZstd())
+	default:
+		return nil
+	}
+}
+
+// identifyByContentType identifies the same formats as identifyByName using the content type
+// and content encoding of the file, as reported by sources that store them with objects, such
+// as S3. Both are media types or HTTP content codings, like application/zstd or br.
+func (r *fileReader) identifyByContentType(contentType, contentEncoding string) error {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	for _, coding := range append(strings.Split(contentEncoding, ","), mediaType) {
+		switch strings.ToLower(strings.TrimSpace(coding)) {
+		case "br", "application/x-brotli", "application/brotli":
+			return r.identifyAs(archiver.Brotli{})
+		case "zstd", "application/zstd", "application/x-zstd":
+			return r.identifyAs(newZstd())
+		}
+	}
+	return nil
+}
+
+// identifyAs treats the file as a stream of the format if a prefix of it decodes successfully.
+// Readers that were already identified as archives are left untouched.
+func (r *fileReader) identifyAs(format decompressorFormat) error {
+	if r.isGenericArchive {
+		return nil
+	}
+
+	decompressed, err := format.OpenReader(io.LimitReader(r, defaultBufferSize))
+	if err != nil {
+		return fmt.Errorf("error opening %s reader: %w", format.Name(), err)
+	}
+	n, err := decompressed.Read(make([]byte, defaultBufferSize))
+	_ = decompressed.Close()
+
+	if _, seekErr := r.Seek(0, io.SeekStart); seekErr != nil {
+		return fmt.Errorf("error seeking to start of file: %w", seekErr)
+	}
+	if n == 0 && !errors.Is(err, io.EOF) {
+		// Not a stream of the format, despite the name or content type.
+		return nil
+	}
+
+	r.format = format
+	r.mimeType = mimeType(format.Name())
+	r.isGenericArchive = true
+	return nil
+}
+
+// IsExtractable reports whether header, the beginning of a file, identifies a format that
+// HandleFile extracts content from: an archive, a compressed file, a package or a PDF document.
+// name is used to identify compression formats without a magic number, as HandleFile does.
+func IsExtractable(name string, header []byte) bool {
+	if _, _, err := archiver.Identify("", bytes.NewReader(header)); err == nil {
+		return true
+	}
+	switch mimeType(mimetype.Detect(header).String()) {
+	case arMime, unixArMime, debMime, rpmMime, cpioMime, pdfMime:
+		return true
+	}
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, brotliExt) || strings.HasSuffix(lower, zstdExt)
+}
+
 // FileHandler represents a handler for files.
 // It has a single method, HandleFile, which takes a context and a fileReader as input,
 // and returns a channel of byte slices and an error.
@@ -97,12 +207,37 @@ type FileHandler interface {
 }
 
 // fileHandlingConfig encapsulates configuration settings that control the behavior of file processing.
-type fileHandlingConfig struct{ skipArchives bool }
+type fileHandlingConfig struct {
+	skipArchives bool
+	// fileName is the name of the file being handled, used to identify formats
+	// that can't be detected from their content.
+	fileName string
+	// contentType and contentEncoding are the metadata a source stores with the
+	// file, if any, also used to identify formats that can't be detected from
+	// their content.
+	contentType, contentEncoding string
+	// maxArchiveDepth bounds how many levels of nested archives are extracted.
+	// The depth is tracked per branch of recursion, so sibling archives each get their own budget.
+	maxArchiveDepth int
+	// archiveEntryFilter selects the archive entries that are scanned.
+	archiveEntryFilter *glob.Filter
+	// scanExtensions limits the archive entries that are scanned to those with
+	// one of its extensions, if it isn't empty.
+	scanExtensions common.ExtensionSet
+	// bufferThreshold is the size above which the content of the file is
+	// streamed or spills to disk instead of being held in memory, or 0 for the
+	// default.
+	bufferThreshold uint64
+}
+
+// defaultBufferThreshold is the bufferThreshold used if none is set, the default
+// threshold of the BufferedFileWriter.
+const defaultBufferThreshold = 10 * 1024 * 1024 // 10MB
 
 // newFileHandlingConfig creates a default fileHandlingConfig with default settings.
 // Optional functional parameters can customize the configuration.
 func newFileHandlingConfig(options ...func(*fileHandlingConfig)) fileHandlingConfig {
-	config := fileHandlingConfig{}
+	config := fileHandlingConfig{maxArchiveDepth: maxDepth, archiveEntryFilter: entryFilter, scanExtensions: scanExtensions}
 	for _, option := range options {
 		option(&config)
 	}
@@ -116,13 +251,80 @@ func WithSkipArchives(skip bool) func(*fileHandlingConfig) {
 	return func(c *fileHandlingConfig) { c.skipArchives = skip }
 }
 
+// WithFileName sets the fileName field of the fileHandlingConfig.
+// The name is used to identify compression formats without a magic number, such as Brotli.
+func WithFileName(name string) func(*fileHandlingConfig) {
+	return func(c *fileHandlingConfig) { c.fileName = name }
+}
+
+// WithContentType sets the contentType and contentEncoding fields of the fileHandlingConfig.
+// Like the file name, they're used to identify compression formats without a magic number,
+// for files whose name doesn't have the extension of their format.
+func WithContentType(contentType, contentEncoding string) func(*fileHandlingConfig) {
+	return func(c *fileHandlingConfig) {
+		c.contentType = contentType
+		c.contentEncoding = contentEncoding
+	}
+}
+
+// WithBufferThreshold sets the bufferThreshold field of the fileHandlingConfig.
+// Plain files larger than the threshold are chunked as they're read, and
+// archives larger than the threshold are copied to a temporary file and
+// extracted from there, so that at most threshold bytes of a file are held in
+// memory. Zero uses the default threshold of 10MB.
+func WithBufferThreshold(threshold uint64) func(*fileHandlingConfig) {
+	return func(c *fileHandlingConfig) { c.bufferThreshold = threshold }
+}
+
+// threshold returns the bufferThreshold, or the default if it isn't set.
+func (c fileHandlingConfig) threshold() uint64 {
+	if c.bufferThreshold == 0 {
+		return defaultBufferThreshold
+	}
+	return c.bufferThreshold
+}
+
+// WithMaxArchiveDepth sets the maxArchiveDepth field of the fileHandlingConfig.
+// Non-positive values are ignored and the package default is used instead.
+func WithMaxArchiveDepth(depth int) func(*fileHandlingConfig) {
+	return func(c *fileHandlingConfig) {
+		if depth > 0 {
+			c.maxArchiveDepth = depth
+		}
+	}
+}
+
+// WithArchiveEntryFilter sets the archiveEntryFilter field of the fileHandlingConfig.
+// Entries of tar, zip and other generic archives whose path doesn't pass the filter
+// are skipped without being read, unless they are archives themselves.
+func WithArchiveEntryFilter(filter *glob.Filter) func(*fileHandlingConfig) {
+	return func(c *fileHandlingConfig) { c.archiveEntryFilter = filter }
+}
+
+// WithScanExtensions sets the scanExtensions field of the fileHandlingConfig.
+// Entries of tar, zip and other generic archives whose extension isn't in the set
+// are skipped like those not passing the archive entry filter.
+// An empty set is ignored and the package default is used instead.
+func WithScanExtensions(extensions common.ExtensionSet) func(*fileHandlingConfig) {
+	return func(c *fileHandlingConfig) {
+		if len(extensions) > 0 {
+			c.scanExtensions = extensions
+		}
+	}
+}
+
 type handlerType string
 
 const (
-	archiveHandlerType handlerType = "archive"
-	arHandlerType      handlerType = "ar"
-	rpmHandlerType     handlerType = "rpm"
-	defaultHandlerType handlerType = "default"
+	archiveHandlerType     handlerType = "archive"
+	arHandlerType          handlerType = "ar"
+	rpmHandlerType         handlerType = "rpm"
+	debHandlerType         handlerType = "deb"
+	pdfHandlerType         handlerType = "pdf"
+	kubernetesHandlerType  handlerType = "kubernetes"
+	credentialsHandlerType handlerType = "credentials"
+	mailHandlerType        handlerType = "mail"
+	defaultHandlerType     handlerType = "default"
 )
 
 type mimeType string
@@ -133,25 +335,47 @@ const (
 	unixArMime mimeType = "application/x-unix-archive"
 	arMime     mimeType = "application/x-archive"
 	debMime    mimeType = "application/vnd.debian.binary-package"
+	pdfMime    mimeType = "application/pdf"
 )
 
 // selectHandler dynamically selects and configures a FileHandler based on the provided fileReader.
 // The fileReader contains information about the MIME type and whether the file is an archive.
 // This method uses specialized handlers for specific file types:
-// - arHandler is used for Unix archives and Debian packages ('arMime', 'unixArMime', and 'debMime').
+// - debHandler is used for Debian packages ('debMime').
+// - arHandler is used for other Unix archives ('arMime' and 'unixArMime').
 // - rpmHandler is used for RPM and CPIO archives ('rpmMime' and 'cpioMime').
-// - archiveHandler is used for common archive formats supported by the archiver library (.zip, .tar, .gz, etc.).
+// - pdfHandler is used for PDF documents ('pdfMime').
+// - archiveHandler is used for common archive formats supported by the archiver library (.zip, .tar, .gz, .br, etc.).
+// - mailHandler is used for other files named like .eml and .mbox files.
+// - credentialsHandler is used for other files named like .git-credentials and netrc files.
+// - kubernetesHandler is used for other files named like YAML files, which may be Kubernetes manifests.
 // - defaultHandler is used for non-archive files.
 // The selected handler is then returned, ready to handle the file according to its specific format and requirements.
-func selectHandler(file fileReader) FileHandler {
+func selectHandler(file fileReader, config fileHandlingConfig) FileHandler {
 	switch file.mimeType {
-	case arMime, unixArMime, debMime:
+	case debMime:
+		return newDebHandler(config.maxArchiveDepth)
+	case arMime, unixArMime:
 		return newARHandler()
 	case rpmMime, cpioMime:
-		return newRPMHandler()
+		return newRPMHandler(config.maxArchiveDepth)
+	case pdfMime:
+		return newPDFHandler()
 	default:
 		if file.isGenericArchive {
-			return newArchiveHandler()
+			handler := newArchiveHandler(config.maxArchiveDepth)
+			handler.entryFilter = config.archiveEntryFilter
+			handler.scanExtensions = config.scanExtensions
+			return handler
+		}
+		if isMailFileName(config.fileName) {
+			return newMailHandler(config.fileName, config.maxArchiveDepth)
+		}
+		if isCredentialsFileName(config.fileName) {
+			return newCredentialsHandler(config.fileName)
+		}
+		if isManifestName(config.fileName) {
+			return newKubernetesHandler()
 		}
 		return newDefaultHandler(defaultHandlerType)
 	}
@@ -177,7 +401,24 @@ func HandleFile(
 		return fmt.Errorf("reader is nil")
 	}
 
-	rdr, err := newFileReader(reader)
+	config := newFileHandlingConfig(options...)
+
+	// Read at most the threshold in memory to tell whether the file is larger.
+	head, err := io.ReadAll(io.LimitReader(reader, int64(config.threshold())+1))
+	if err != nil {
+		_ = reader.Close()
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	if uint64(len(head)) > config.threshold() {
+		streamed, err := streamFile(ctx, head, reader, chunkSkel, reporter, config)
+		if streamed || err != nil {
+			_ = reader.Close()
+			return err
+		}
+	}
+
+	rest := readCloser{Reader: io.MultiReader(bytes.NewReader(head), reader), Closer: reader}
+	rdr, err := newFileReader(rest, bufferedfilewriter.WithThreshold(config.threshold()))
 	if err != nil {
 		if errors.Is(err, ErrEmptyReader) {
 			ctx.Logger().V(5).Info("empty reader, skipping file")
@@ -187,35 +428,112 @@ func HandleFile(
 	}
 	defer rdr.Close()
 
-	config := newFileHandlingConfig(options...)
+	if err := rdr.identifyByName(config.fileName); err != nil {
+		return fmt.Errorf("error identifying file: %w", err)
+	}
+	if err := rdr.identifyByContentType(config.contentType, config.contentEncoding); err != nil {
+		return fmt.Errorf("error identifying file: %w", err)
+	}
 	if config.skipArchives && rdr.isGenericArchive {
 		ctx.Logger().V(5).Info("skipping archive file", "mime", rdr.mimeType)
 		return nil
 	}
 
-	handler := selectHandler(rdr)
+	handler := selectHandler(rdr, config)
+	if pages, ok := handler.(pageHandler); ok {
+		pageChan, err := pages.HandlePages(ctx, rdr)
+		if err != nil {
+			return fmt.Errorf("error handling file: %w", err)
+		}
+		return handlePageChunks(ctx, pageChan, chunkSkel, reporter)
+	}
+	if manifests, ok := handler.(manifestHandler); ok {
+		manifestChan, err := manifests.HandleManifest(ctx, rdr)
+		if err != nil {
+			return fmt.Errorf("error handling file: %w", err)
+		}
+		return handleManifestChunks(ctx, manifestChan, chunkSkel, reporter)
+	}
 	archiveChan, err := handler.HandleFile(ctx, rdr) // Delegate to the specific handler to process the file.
 	if err != nil {
 		return fmt.Errorf("error handling file: %w", err)
 	}
 
-	return handleChunks(ctx, archiveChan, chunkSkel, reporter)
+	// Line numbers are only meaningful for files that are chunked as a single stream, not for archive contents.
+	_, trackLines := handler.(*defaultHandler)
+	if err := handleChunks(ctx, archiveChan, chunkSkel, reporter, trackLines); err != nil {
+		return err
+	}
+
+	// Surface content skipped because of the depth limit with the scan's errors, so it isn't only in the logs.
+	if archive, ok := handler.(*archiveHandler); ok && archive.depthReached.Load() {
+		return reporter.ChunkErr(ctx, fmt.Errorf("%w: content of %q nested deeper than %d levels was not scanned",
+			ErrMaxDepthReached, config.fileName, archive.maxDepth))
+	}
+	return nil
+}
+
+// streamProbeSize is the size of the beginning of a file its type is identified from
+// when it's streamed. It's larger than the headers the archiver and mimetype packages
+// look at.
+const streamProbeSize = 64 * 1024
+
+// readCloser reads from a Reader and closes a Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// streamFile chunks a file larger than the buffer threshold as it's read, head being its
+// beginning and reader the rest of it, and reports whether it did. Archives and other
+// files whose handler needs random access to their content aren't streamed.
+func streamFile(
+	ctx logContext.Context,
+	head []byte,
+	reader io.Reader,
+	chunkSkel *sources.Chunk,
+	reporter sources.ChunkReporter,
+	config fileHandlingConfig,
+) (bool, error) {
+	probe, err := newFileReader(io.NopCloser(bytes.NewReader(head[:min(len(head), streamProbeSize)])))
+	if err != nil {
+		return false, nil
+	}
+	defer probe.Close()
+	if err := probe.identifyByName(config.fileName); err != nil {
+		return false, nil
+	}
+	if err := probe.identifyByContentType(config.contentType, config.contentEncoding); err != nil {
+		return false, nil
+	}
+	handler, ok := selectHandler(probe, config).(*defaultHandler)
+	if !ok {
+		return false, nil
+	}
+
+	ctx.Logger().V(3).Info("streaming file larger than the buffer threshold", "threshold", config.threshold())
+	dataChan := handler.handleReader(ctx, io.MultiReader(bytes.NewReader(head), reader))
+	return true, handleChunks(ctx, dataChan, chunkSkel, reporter, true)
 }
 
 // handleChunks reads data from the handlerChan and uses it to fill chunks according to a predefined skeleton (chunkSkel).
 // Each filled chunk is reported using the provided reporter. This function manages the lifecycle of the channel,
 // handling the termination condition when the channel closes and ensuring the cancellation of the operation if the context
 // is done. It returns true if all chunks are processed successfully, otherwise returns false on errors or cancellation.
+// If trackLines is set, the metadata of each chunk records the line of the file at which the chunk starts.
 func handleChunks(
 	ctx logContext.Context,
 	handlerChan chan []byte,
 	chunkSkel *sources.Chunk,
 	reporter sources.ChunkReporter,
+	trackLines bool,
 ) error {
 	if handlerChan == nil {
 		return fmt.Errorf("handler channel is nil")
 	}
 
+	// line is the 1-based line at which the next chunk starts.
+	line := int64(1)
 	for {
 		select {
 		case data, open := <-handlerChan:
@@ -225,6 +543,12 @@ func handleChunks(
 			}
 			chunk := *chunkSkel
 			chunk.Data = data
+			if trackLines {
+				chunk.SourceMetadata = withStartLine(chunkSkel.SourceMetadata, line)
+				// Consecutive chunks overlap by the peek size, so only the first ChunkSize bytes
+				// of a chunk precede the next one. Counting LF also handles CRLF line endings.
+				line += int64(bytes.Count(data[:min(len(data), sources.ChunkSize)], []byte("\n")))
+			}
 			if err := reporter.ChunkOk(ctx, chunk); err != nil {
 				return fmt.Errorf("error reporting chunk: %w", err)
 			}
@@ -233,3 +557,88 @@ func handleChunks(
 		}
 	}
 }
+
+// handlePageChunks is handleChunks for the data of paged documents. The metadata of each chunk records
+// the page it was found on and the line within the page at which it starts.
+func handlePageChunks(
+	ctx logContext.Context,
+	pageChan chan pageData,
+	chunkSkel *sources.Chunk,
+	reporter sources.ChunkReporter,
+) error {
+	page, line := int64(0), int64(1)
+	for {
+		select {
+		case data, open := <-pageChan:
+			if !open {
+				ctx.Logger().V(5).Info("handler channel closed, all chunks processed")
+				return nil
+			}
+			if data.page != page {
+				page, line = data.page, 1
+			}
+			chunk := *chunkSkel
+			chunk.Data = data.data
+			chunk.SourceMetadata = withStartLine(chunkSkel.SourceMetadata, line)
+			// withStartLine returns a copy of filesystem metadata, which can be modified.
+			if fs := chunk.SourceMetadata.GetFilesystem(); fs != nil {
+				fs.Page = page
+			}
+			line += int64(bytes.Count(data.data[:min(len(data.data), sources.ChunkSize)], []byte("\n")))
+			if err := reporter.ChunkOk(ctx, chunk); err != nil {
+				return fmt.Errorf("error reporting chunk: %w", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// handleManifestChunks is handleChunks for the data of manifests. The metadata of each chunk records
+// the line it starts at and, for data decoded from a Kubernetes Secret or ConfigMap, the key it was
+// decoded from, for credentials parsed from a credentials file, their host and login, for the
+// data of a mail file, the message and attachment it was found in, or for the data of a package,
+// the package and the file it was found in.
+func handleManifestChunks(
+	ctx logContext.Context,
+	manifestChan chan manifestData,
+	chunkSkel *sources.Chunk,
+	reporter sources.ChunkReporter,
+) error {
+	for {
+		select {
+		case data, open := <-manifestChan:
+			if !open {
+				ctx.Logger().V(5).Info("handler channel closed, all chunks processed")
+				return nil
+			}
+			chunk := *chunkSkel
+			chunk.Data = data.data
+			chunk.SourceMetadata = withManifestMetadata(chunkSkel.SourceMetadata, data)
+			if err := reporter.ChunkOk(ctx, chunk); err != nil {
+				return fmt.Errorf("error reporting chunk: %w", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// withStartLine returns a copy of metadata with its line set to line, for metadata types
+// that record the line at which a chunk starts. Other metadata is returned unchanged.
+func withStartLine(metadata *source_metadatapb.MetaData, line int64) *source_metadatapb.MetaData {
+	if metadata.GetFilesystem() == nil && metadata.GetStdin() == nil {
+		return metadata
+	}
+	clone, ok := proto.Clone(metadata).(*source_metadatapb.MetaData)
+	if !ok {
+		return metadata
+	}
+	switch data := clone.GetData().(type) {
+	case *source_metadatapb.MetaData_Filesystem:
+		data.Filesystem.Line = line
+	case *source_metadatapb.MetaData_Stdin:
+		data.Stdin.Line = line
+	}
+	return clone
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// maxManifestSize bounds the size of the YAML files parsed as Kubernetes manifests. Larger
+// files are scanned as plain text.
+const maxManifestSize = 10 << 20 // 10 MB
+
+// kubernetesValue identifies a value of a Kubernetes Secret or ConfigMap.
+type kubernetesValue struct {
+	kind, namespace, name, key string
+}
+
+// isManifestName reports whether name is the name of a file that may hold Kubernetes manifests.
+func isManifestName(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".yaml") || strings.HasSuffix(lower, ".yml")
+}
+
+// kubernetesHandler specializes defaultHandler to decode the values of Kubernetes Secret and
+// ConfigMap manifests, so that each value is scanned on its own and attributed to its key.
+type kubernetesHandler struct{ *defaultHandler }
+
+// newKubernetesHandler creates a kubernetesHandler.
+func newKubernetesHandler() *kubernetesHandler {
+	return &kubernetesHandler{defaultHandler: newDefaultHandler(kubernetesHandlerType)}
+}
+
+// HandleFile processes YAML files, discarding the values the data was decoded from.
+func (h *kubernetesHandler) HandleFile(ctx logContext.Context, input fileReader) (chan []byte, error) {
+	manifestChan, err := h.HandleManifest(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return manifestDataOnly(ctx, manifestChan), nil
+}
+
+// HandleManifest processes YAML files. The data, stringData and binaryData values of the
+// Secret and ConfigMap documents are reported decoded, and the rest of the file as is. Files
+// that aren't valid YAML are reported as plain text.
+func (h *kubernetesHandler) HandleManifest(ctx logContext.Context, input fileReader) (chan manifestData, error) {
+	return h.handleManifest(ctx, "manifest", func(ctx logContext.Context, emit func(manifestData) error) error {
+		return h.handleManifestContent(ctx, input, emit)
+	}), nil
+}
+
+// handleManifestContent reads a YAML file and passes its data to emit in chunks.
+func (h *kubernetesHandler) handleManifestContent(ctx logContext.Context, reader io.Reader, emit func(manifestData) error) error {
+	data, err := io.ReadAll(io.LimitReader(reader, maxManifestSize+1))
+	if err != nil {
+		return fmt.Errorf("error reading manifest: %w", err)
+	}
+	if len(data) > maxManifestSize {
+		return h.emitLines(ctx, io.MultiReader(bytes.NewReader(data), reader), emit)
+	}
+
+	docs, err := parseManifests(data)
+	if err != nil {
+		ctx.Logger().V(5).Info("file isn't valid YAML, scanning it as plain text", "error", err)
+		return h.emitLines(ctx, bytes.NewReader(data), emit)
+	}
+
+	// The values are removed from the rest of the file, so that they aren't found again
+	// without their attribution.
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	var values []manifestData
+	for i, doc := range docs {
+		end := len(lines)
+		if i+1 < len(docs) {
+			end = docs[i+1].Line - 1
+		}
+		for _, section := range kubernetesValues(doc, end) {
+			for l := section.start; l <= section.end && l <= len(lines); l++ {
+				lines[l-1] = blankLine(lines[l-1])
+			}
+			values = append(values, section.values...)
+		}
+	}
+
+	if err := h.emitLines(ctx, bytes.NewReader(bytes.Join(lines, nil)), emit); err != nil {
+		return err
+	}
+	for _, value := range values {
+		value := value
+		err := h.chunkContent(ctx, bytes.NewReader(value.data), func(data []byte) error {
+			return emit(manifestData{line: value.line, value: value.value, data: data})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blankLine returns an empty line in place of line, keeping its line ending.
+func blankLine(line []byte) []byte {
+	if bytes.HasSuffix(line, []byte("\n")) {
+		return []byte("\n")
+	}
+	return nil
+}
+
+// emitLines chunks the content read from reader like a file that isn't a manifest, passing
+// each chunk to emit with the line it starts at.
+func (h *defaultHandler) emitLines(ctx logContext.Context, reader io.Reader, emit func(manifestData) error) error {
+	line := int64(1)
+	return h.handleContent(ctx, reader, func(_ int64, data []byte) error {
+		m := manifestData{line: line, data: data}
+		// Consecutive chunks overlap by the peek size, so only the first ChunkSize bytes
+		// of a chunk precede the next one.
+		line += int64(bytes.Count(data[:min(len(data), sources.ChunkSize)], []byte("\n")))
+		return emit(m)
+	})
+}
+
+// parseManifests parses the documents of a YAML file.
+func parseManifests(data []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+}
+
+// valueSection is a section of a Secret or ConfigMap holding values, such as its data.
+type valueSection struct {
+	// start and end are the first and last lines of the section.
+	start, end int
+	values     []manifestData
+}
+
+// kubernetesValues returns the sections holding the values of a Secret or ConfigMap document,
+// which ends at line end, with their values decoded. Other documents have none.
+func kubernetesValues(doc *yaml.Node, end int) []valueSection {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	root := doc.Content[0]
+	kind := scalarField(root, "kind")
+	if kind != "Secret" && kind != "ConfigMap" {
+		return nil
+	}
+	var namespace, name string
+	if metadata := field(root, "metadata"); metadata != nil {
+		namespace, name = scalarField(metadata, "namespace"), scalarField(metadata, "name")
+	}
+
+	var sections []valueSection
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, values := root.Content[i], root.Content[i+1]
+		// Secrets hold base64 encoded data, and ConfigMaps base64 encoded binaryData.
+		var encoded bool
+		switch {
+		case key.Value == "binaryData", key.Value == "data" && kind == "Secret":
+			encoded = true
+		case key.Value == "data", key.Value == "stringData":
+		default:
+			continue
+		}
+		if values.Kind != yaml.MappingNode {
+			continue
+		}
+
+		section := valueSection{start: key.Line, end: end}
+		if i+2 < len(root.Content) {
+			section.end = root.Content[i+2].Line - 1
+		}
+		for j := 0; j+1 < len(values.Content); j += 2 {
+			k, v := values.Content[j], values.Content[j+1]
+			if v.Kind != yaml.ScalarNode {
+				continue
+			}
+			data := []byte(v.Value)
+			if encoded {
+				// Values that aren't valid base64 are scanned as is.
+				if decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(v.Value), "")); err == nil {
+					data = decoded
+				}
+			}
+			section.values = append(section.values, manifestData{
+				line:  int64(v.Line),
+				value: &kubernetesValue{kind: kind, namespace: namespace, name: name, key: k.Value},
+				data:  data,
+			})
+		}
+		// The section can't be removed from the rest of the file if it shares lines with
+		// other fields, as in flow style mappings. Its values are scanned twice instead.
+		if root.Style&yaml.FlowStyle != 0 || section.end < section.start {
+			section.start, section.end = 0, -1
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// field returns the value of the field of a mapping node with the given key, or nil.
+func field(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalarField returns the scalar value of the field of a mapping node with the given key, or
+// "" if it has none.
+func scalarField(mapping *yaml.Node, key string) string {
+	if value := field(mapping, key); value != nil && value.Kind == yaml.ScalarNode {
+		return value.Value
+	}
+	return ""
+}
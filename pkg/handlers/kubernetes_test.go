@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// handleManifest handles a file with the given name and content, and returns the data of the
+// chunks by the key, prefixed with the kind, namespace and name, and line they were found at.
+func handleManifest(t *testing.T, name, manifest string) map[string]string {
+	t.Helper()
+	chunkCh := make(chan *sources.Chunk, 16)
+	chunkSkel := &sources.Chunk{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{File: name},
+			},
+		},
+	}
+	err := HandleFile(logContext.Background(), io.NopCloser(strings.NewReader(manifest)), chunkSkel, sources.ChanReporter{Ch: chunkCh}, WithFileName(name))
+	assert.NoError(t, err)
+	close(chunkCh)
+
+	got := make(map[string]string)
+	for chunk := range chunkCh {
+		fs := chunk.SourceMetadata.GetFilesystem()
+		assert.Equal(t, name, fs.GetFile())
+		key := fmt.Sprintf("%d", fs.GetLine())
+		if fs.GetKubernetesKind() != "" {
+			key = fmt.Sprintf("%s %s/%s %s:%d", fs.GetKubernetesKind(), fs.GetKubernetesNamespace(), fs.GetKubernetesName(), fs.GetKubernetesKey(), fs.GetLine())
+		}
+		got[key] += string(chunk.Data)
+	}
+	// The skeleton metadata is not modified.
+	assert.Empty(t, chunkSkel.SourceMetadata.GetFilesystem().GetKubernetesKey())
+	return got
+}
+
+func TestHandleFileKubernetes(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: prod
+  annotations:
+    note: rotated
+type: Opaque
+data:
+  password: aHVudGVyMg==
+  cert: |
+    LS0tLS1CRUdJTi
+    BLRVktLS0tLQ==
+stringData:
+  token: plain-token
+---
+# Not a secret.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+data:
+  password: bm90LWRlY29kZWQ=
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  config.env: API_KEY=plain
+binaryData:
+  blob: YmluYXJ5
+`
+	got := handleManifest(t, "deploy/secrets.yaml", manifest)
+	assert.Equal(t, map[string]string{
+		"Secret prod/db password:10":        "hunter2",
+		"Secret prod/db cert:11":            "-----BEGIN KEY-----",
+		"Secret prod/db token:15":           "plain-token",
+		"ConfigMap /settings config.env:30": "API_KEY=plain",
+		"ConfigMap /settings blob:32":       "binary",
+		// The decoded values are removed from the rest of the manifest, but its lines are kept.
+		"1": `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: prod
+  annotations:
+    note: rotated
+type: Opaque
+
+
+
+
+
+
+
+---
+# Not a secret.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+data:
+  password: bm90LWRlY29kZWQ=
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+
+
+
+
+`,
+	}, got)
+}
+
+func TestHandleFileKubernetesNotManifest(t *testing.T) {
+	tests := map[string]struct {
+		name    string
+		content string
+	}{
+		"invalid yaml":  {name: "broken.yml", content: "key: [unterminated\nsecret: value\n"},
+		"not yaml name": {name: "secret.txt", content: "kind: Secret\ndata:\n  password: aHVudGVyMg==\n"},
+		"flow style":    {name: "flow.yaml", content: "{kind: Secret, data: {password: aHVudGVyMg==}}\n"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := handleManifest(t, tt.name, tt.content)
+			// The file is scanned as is.
+			assert.Equal(t, tt.content, got["1"])
+		})
+	}
+
+	// Values of flow style manifests are also decoded, as they can't be removed.
+	got := handleManifest(t, "flow.yaml", "{kind: Secret, data: {password: aHVudGVyMg==}}\n")
+	assert.Equal(t, "hunter2", got["Secret / password:1"])
+
+	assert.True(t, bytes.Equal([]byte("\n"), blankLine([]byte("  key: value\n"))))
+	assert.Empty(t, blankLine([]byte("  key: value")))
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	// emlExt is the file extension of files holding a single email message.
+	emlExt = ".eml"
+	// mboxExt is the file extension of mailboxes holding a sequence of email messages.
+	mboxExt = ".mbox"
+)
+
+// mailPart identifies the message, and attachment, the data of a mail file was found in.
+type mailPart struct {
+	subject, from string
+	// attachment is the file name of the attachment, or "" for the message itself.
+	attachment string
+}
+
+// isMailFileName reports whether name is the name of an .eml or .mbox file.
+func isMailFileName(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, emlExt) || strings.HasSuffix(lower, mboxExt)
+}
+
+// mailHandler specializes defaultHandler to parse the MIME structure of email messages, so that
+// encoded parts are scanned once decoded, and attachments are handled like the files they are.
+type mailHandler struct {
+	*defaultHandler
+	// mbox is set for mailboxes, which may hold many messages.
+	mbox bool
+	// maxDepth bounds the nesting of messages and attachments that is extracted.
+	maxDepth int
+}
+
+// newMailHandler creates a mailHandler for the mail file with the given name.
+func newMailHandler(fileName string, maxDepth int) *mailHandler {
+	return &mailHandler{
+		defaultHandler: newDefaultHandler(mailHandlerType),
+		mbox:           strings.HasSuffix(strings.ToLower(fileName), mboxExt),
+		maxDepth:       maxDepth,
+	}
+}
+
+// HandleFile processes mail files, discarding the messages the data was found in.
+func (h *mailHandler) HandleFile(ctx logContext.Context, input fileReader) (chan []byte, error) {
+	manifestChan, err := h.HandleManifest(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return manifestDataOnly(ctx, manifestChan), nil
+}
+
+// HandleManifest processes mail files. The headers of each message, its decoded text and HTML
+// parts, and the content extracted from its attachments are reported along with the subject and
+// sender of the message.
+func (h *mailHandler) HandleManifest(ctx logContext.Context, input fileReader) (chan manifestData, error) {
+	return h.handleManifest(ctx, "mail file", func(ctx logContext.Context, emit func(manifestData) error) error {
+		return h.handleMailContent(ctx, input, emit)
+	}), nil
+}
+
+// handleMailContent reads the messages of a mail file and passes their data to emit in chunks.
+// Messages that can't be parsed are skipped, except for .eml files that aren't a message at all,
+// which are scanned as plain text.
+func (h *mailHandler) handleMailContent(ctx logContext.Context, reader io.Reader, emit func(manifestData) error) error {
+	if !h.mbox {
+		data, err := io.ReadAll(io.LimitReader(reader, int64(maxSize)))
+		if err != nil {
+			return fmt.Errorf("error reading message: %w", err)
+		}
+		err = h.handleMessage(ctx, data, 1, 0, emit)
+		if errors.Is(err, errMalformedMessage) {
+			ctx.Logger().V(5).Info("file isn't a valid message, scanning it as plain text", "error", err)
+			return h.emitLines(ctx, bytes.NewReader(data), emit)
+		}
+		return err
+	}
+
+	return splitMbox(reader, func(message []byte, line int64) error {
+		err := h.handleMessage(ctx, message, line, 0, emit)
+		if errors.Is(err, errMalformedMessage) {
+			ctx.Logger().Info("skipping malformed message", "line", line, "error", err)
+			h.metrics.incFilesSkipped()
+			return nil
+		}
+		return err
+	})
+}
+
+// errMalformedMessage is returned for messages that can't be parsed.
+var errMalformedMessage = errors.New("malformed message")
+
+// handleMessage parses a message, which starts at the given line of the file, and passes its
+// headers and parts to emit.
+func (h *mailHandler) handleMessage(ctx logContext.Context, data []byte, line int64, depth int, emit func(manifestData) error) error {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errMalformedMessage, err)
+	}
+	body, err := io.ReadAll(msg.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errMalformedMessage, err)
+	}
+
+	part := mailPart{subject: decodeMailHeader(msg.Header.Get("Subject")), from: decodeMailHeader(msg.Header.Get("From"))}
+	headers := data[:len(data)-len(body)]
+	if err := emit(manifestData{line: line, mail: &part, data: headers}); err != nil {
+		return err
+	}
+	return h.handleEntity(ctx, textproto.MIMEHeader(msg.Header), bytes.NewReader(body), part, line, depth, emit)
+}
+
+// handleEntity handles the body of a message or of one of its parts according to its content
+// type, after decoding its transfer encoding.
+func (h *mailHandler) handleEntity(
+	ctx logContext.Context,
+	header textproto.MIMEHeader,
+	body io.Reader,
+	part mailPart,
+	line int64,
+	depth int,
+	emit func(manifestData) error,
+) error {
+	if common.IsDone(ctx) {
+		return ctx.Err()
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// Bodies without a valid content type are plain text.
+		mediaType = "text/plain"
+	}
+	body = decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body)
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		parts := multipart.NewReader(body, params["boundary"])
+		for {
+			// Raw parts are read so that their transfer encoding is decoded like the message's.
+			p, err := parts.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: error reading multipart body: %v", errMalformedMessage, err)
+			}
+			if err := h.handleEntity(ctx, p.Header, p, part, line, depth, emit); err != nil {
+				return err
+			}
+		}
+	case mediaType == "message/rfc822":
+		if depth+1 >= h.maxDepth {
+			ctx.Logger().V(2).Info("max depth reached, skipping attached message", "depth", depth)
+			return nil
+		}
+		data, err := io.ReadAll(io.LimitReader(body, int64(maxSize)))
+		if err != nil {
+			return fmt.Errorf("error reading attached message: %w", err)
+		}
+		err = h.handleMessage(ctx, data, line, depth+1, emit)
+		if errors.Is(err, errMalformedMessage) {
+			ctx.Logger().V(2).Info("skipping malformed attached message", "error", err)
+			return nil
+		}
+		return err
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	name := dispositionParams["filename"]
+	if name == "" {
+		name = params["name"]
+	}
+	if disposition == "attachment" || name != "" || (mediaType != "text/plain" && mediaType != "text/html") {
+		part.attachment = name
+		return h.handleAttachment(ctx, body, part, line, depth, emit)
+	}
+
+	return h.chunkContent(ctx, body, func(data []byte) error {
+		return emit(manifestData{line: line, mail: &part, data: data})
+	})
+}
+
+// handleAttachment extracts the content of an attachment with the handler selected for it, as
+// HandleFile would for a file of the same name and content.
+func (h *mailHandler) handleAttachment(ctx logContext.Context, body io.Reader, part mailPart, line int64, depth int, emit func(manifestData) error) error {
+	lCtx := logContext.WithValues(ctx, "attachment", part.attachment)
+	if depth+1 >= h.maxDepth {
+		lCtx.Logger().V(2).Info("max depth reached, skipping attachment", "depth", depth)
+		return nil
+	}
+	if common.SkipFile(part.attachment) || common.IsBinary(part.attachment) {
+		lCtx.Logger().V(5).Info("skipping attachment")
+		h.metrics.incFilesSkipped()
+		return nil
+	}
+
+	rdr, err := newFileReader(io.NopCloser(io.LimitReader(body, int64(maxSize))))
+	if err != nil {
+		if errors.Is(err, ErrEmptyReader) {
+			lCtx.Logger().V(5).Info("empty attachment, skipping it")
+			return nil
+		}
+		return fmt.Errorf("error creating custom reader: %w", err)
+	}
+	defer rdr.Close()
+
+	if err := rdr.identifyByName(part.attachment); err != nil {
+		return fmt.Errorf("error identifying attachment %s: %w", part.attachment, err)
+	}
+	config := newFileHandlingConfig(WithFileName(part.attachment), WithMaxArchiveDepth(h.maxDepth-depth-1))
+	dataChan, err := selectHandler(rdr, config).HandleFile(lCtx, rdr)
+	if err != nil {
+		return fmt.Errorf("error handling attachment %s: %w", part.attachment, err)
+	}
+	for data := range dataChan {
+		if err := emit(manifestData{line: line, mail: &part, data: data}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeTransferEncoding returns a reader of the body decoded from its transfer encoding.
+// Bodies with the identity encodings, or an unknown one, are returned unchanged.
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		// The decoder skips the line breaks of the encoded body.
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// decodeMailHeader decodes the RFC 2047 encoded words of a header value.
+func decodeMailHeader(value string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// splitMbox splits a mailbox into its messages, which are separated by "From " lines, and
+// passes each message to handle along with the line its headers start at. Lines of the body
+// that were escaped by prefixing them with ">" are unescaped. Messages larger than maxSize are
+// truncated.
+func splitMbox(reader io.Reader, handle func(message []byte, line int64) error) error {
+	var (
+		message   bytes.Buffer
+		start     int64
+		lastBlank = true
+	)
+	flush := func() error {
+		if start == 0 {
+			return nil
+		}
+		// The data of the message is emitted in chunks, so it can't share the buffer.
+		err := handle(bytes.Clone(message.Bytes()), start)
+		message.Reset()
+		return err
+	}
+
+	bufReader := bufio.NewReaderSize(reader, defaultBufferSize)
+	for line := int64(1); ; line++ {
+		text, err := bufReader.ReadBytes('\n')
+		if len(text) > 0 {
+			switch {
+			case lastBlank && bytes.HasPrefix(text, []byte("From ")):
+				if err := flush(); err != nil {
+					return err
+				}
+				start = line + 1
+			case start == 0:
+				// Mailboxes that don't start with a "From " line are read as starting with a message.
+				start = line
+				message.Write(text)
+			case message.Len()+len(text) <= maxSize:
+				if isEscapedFromLine(text) {
+					text = text[1:]
+				}
+				message.Write(text)
+			}
+			lastBlank = len(bytes.TrimSpace(text)) == 0
+		}
+		if errors.Is(err, io.EOF) {
+			return flush()
+		}
+		if err != nil {
+			return fmt.Errorf("error reading mailbox: %w", err)
+		}
+	}
+}
+
+// isEscapedFromLine reports whether line is a body line starting with "From " that was escaped
+// with one or more ">".
+func isEscapedFromLine(line []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) && line[0] == '>'
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// handleMailFile handles a mail file with the given name and content, and returns its chunks.
+func handleMailFile(t *testing.T, name, content string) []*sources.Chunk {
+	t.Helper()
+	chunkCh := make(chan *sources.Chunk, 64)
+	chunkSkel := &sources.Chunk{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{File: name},
+			},
+		},
+	}
+	err := HandleFile(logContext.Background(), io.NopCloser(strings.NewReader(content)), chunkSkel, sources.ChanReporter{Ch: chunkCh}, WithFileName(name))
+	assert.NoError(t, err)
+	close(chunkCh)
+
+	var chunks []*sources.Chunk
+	for chunk := range chunkCh {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func TestHandleFileMbox(t *testing.T) {
+	attachment := base64.StdEncoding.EncodeToString([]byte("AWS_SECRET_ACCESS_KEY=attached-secret\n"))
+	content := "From alice@example.com Mon Jan  1 00:00:00 2024\n" +
+		"From: Alice <alice@example.com>\n" +
+		"Subject: =?UTF-8?Q?Cr=C3=A9dentials?=\n" +
+		"Content-Type: text/plain; charset=utf-8\n" +
+		"Content-Transfer-Encoding: quoted-printable\n" +
+		"\n" +
+		"token=3Dquoted-secret-that-is-=\n" +
+		"wrapped\n" +
+		">From the team\n" +
+		"\n" +
+		"From bob@example.com Mon Jan  1 00:00:00 2024\n" +
+		"this line isn't a header\n" +
+		"\n" +
+		"From carol@example.com Mon Jan  1 00:00:00 2024\n" +
+		"From: carol@example.com\n" +
+		"Subject: deploy\n" +
+		"MIME-Version: 1.0\n" +
+		"Content-Type: multipart/mixed; boundary=\"outer\"\n" +
+		"\n" +
+		"--outer\n" +
+		"Content-Type: text/html\n" +
+		"Content-Transfer-Encoding: base64\n" +
+		"\n" +
+		base64.StdEncoding.EncodeToString([]byte("<p>password: html-secret</p>")) + "\n" +
+		"--outer\n" +
+		"Content-Type: application/octet-stream; name=\"prod.env\"\n" +
+		"Content-Disposition: attachment; filename=\"prod.env\"\n" +
+		"Content-Transfer-Encoding: base64\n" +
+		"\n" +
+		attachment + "\n" +
+		"--outer--\n"
+
+	chunks := handleMailFile(t, "/exports/inbox.mbox", content)
+
+	type part struct{ subject, from, attachment string }
+	got := make(map[part]string)
+	for _, chunk := range chunks {
+		fs := chunk.SourceMetadata.GetFilesystem()
+		got[part{fs.GetEmailSubject(), fs.GetEmailFrom(), fs.GetEmailAttachment()}] += string(chunk.Data)
+	}
+
+	// The malformed message is skipped, and the others are scanned once decoded.
+	assert.Len(t, got, 3)
+	first := got[part{"Crédentials", "Alice <alice@example.com>", ""}]
+	assert.Contains(t, first, "token=quoted-secret-that-is-wrapped\nFrom the team\n")
+	assert.Contains(t, first, "Subject: =?UTF-8?Q?Cr=C3=A9dentials?=\n")
+	assert.Contains(t, got[part{"deploy", "carol@example.com", ""}], "<p>password: html-secret</p>")
+	assert.Equal(t, "AWS_SECRET_ACCESS_KEY=attached-secret\n", got[part{"deploy", "carol@example.com", "prod.env"}])
+	for _, data := range got {
+		assert.NotContains(t, data, "this line isn't a header")
+	}
+
+	// Chunks are located at the line their message starts at.
+	assert.Equal(t, int64(2), chunks[0].SourceMetadata.GetFilesystem().GetLine())
+	assert.Equal(t, int64(15), chunks[len(chunks)-1].SourceMetadata.GetFilesystem().GetLine())
+}
+
+func TestHandleFileEml(t *testing.T) {
+	// Attached messages are parsed too.
+	content := "From: alice@example.com\n" +
+		"Subject: fwd\n" +
+		"Content-Type: multipart/mixed; boundary=b\n" +
+		"\n" +
+		"--b\n" +
+		"Content-Type: message/rfc822\n" +
+		"\n" +
+		"From: bob@example.com\n" +
+		"Subject: original\n" +
+		"\n" +
+		"key: forwarded-secret\n" +
+		"--b--\n"
+	var forwarded string
+	for _, chunk := range handleMailFile(t, "message.EML", content) {
+		if chunk.SourceMetadata.GetFilesystem().GetEmailSubject() == "original" {
+			forwarded += string(chunk.Data)
+		}
+	}
+	assert.Contains(t, forwarded, "key: forwarded-secret")
+
+	// Files that aren't messages are scanned as plain text.
+	chunks := handleMailFile(t, "notes.eml", "not a message\n")
+	if assert.Len(t, chunks, 1) {
+		assert.Equal(t, "not a message\n", string(chunks[0].Data))
+		assert.Empty(t, chunks[0].SourceMetadata.GetFilesystem().GetEmailFrom())
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// manifestData is a chunk of data extracted from a manifest or another structured file.
+type manifestData struct {
+	// line is the 1-based line of the manifest at which the data starts, or at which the value
+	// it was decoded from is.
+	line int64
+	// value is the value the data was decoded from, or nil for the rest of the manifest.
+	value *kubernetesValue
+	// credential is the credential the data was parsed from, for credentials files.
+	credential *storedCredential
+	// mail is the message the data was found in, for mail files.
+	mail *mailPart
+	// pkg is the file of a package the data was found in, for .deb and .rpm packages.
+	pkg  *packageFile
+	data []byte
+}
+
+// attribution returns the metadata fields recording what the data was extracted from.
+func (m manifestData) attribution() map[protoreflect.Name]string {
+	fields := make(map[protoreflect.Name]string)
+	if m.value != nil {
+		fields["kubernetes_kind"] = m.value.kind
+		fields["kubernetes_namespace"] = m.value.namespace
+		fields["kubernetes_name"] = m.value.name
+		fields["kubernetes_key"] = m.value.key
+	}
+	if m.credential != nil {
+		fields["credential_host"] = m.credential.host
+		fields["credential_login"] = m.credential.login
+	}
+	if m.mail != nil {
+		fields["email_subject"] = m.mail.subject
+		fields["email_from"] = m.mail.from
+		fields["email_attachment"] = m.mail.attachment
+	}
+	if m.pkg != nil {
+		fields["package_name"] = m.pkg.name
+		fields["package_file"] = m.pkg.path
+	}
+	return fields
+}
+
+// manifestHandler is implemented by handlers of manifests and other structured files, which
+// report the value each chunk of data was extracted from.
+type manifestHandler interface {
+	HandleManifest(ctx logContext.Context, reader fileReader) (chan manifestData, error)
+}
+
+// handleManifest runs process in the background and returns the channel the data it emits is
+// sent on. The file is processed within the handling timeout and counted in the handler's
+// metrics, and errors and panics are logged as happening while handling the given kind of file.
+func (h *defaultHandler) handleManifest(
+	ctx logContext.Context,
+	kind string,
+	process func(ctx logContext.Context, emit func(manifestData) error) error,
+) chan manifestData {
+	manifestChan := make(chan manifestData, defaultBufferSize)
+
+	go func() {
+		ctx, cancel := logContext.WithTimeout(ctx, maxTimeout)
+		defer cancel()
+		defer close(manifestChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		// Defer a panic recovery to handle any panics that occur while parsing malformed files.
+		defer func() {
+			if r := recover(); r != nil {
+				// Return the panic as an error.
+				if e, ok := r.(error); ok {
+					err = e
+				} else {
+					err = fmt.Errorf("panic occurred: %v", r)
+				}
+				ctx.Logger().Error(err, "Panic occurred when handling "+kind)
+			}
+		}()
+
+		emit := func(m manifestData) error { return common.CancellableWrite(ctx, manifestChan, m) }
+		if err = process(ctx, emit); err != nil {
+			ctx.Logger().Error(err, "error handling "+kind)
+		}
+	}()
+
+	return manifestChan
+}
+
+// manifestDataOnly forwards the data of manifestChan, discarding the values it was extracted from.
+func manifestDataOnly(ctx logContext.Context, manifestChan chan manifestData) chan []byte {
+	dataChan := make(chan []byte, defaultBufferSize)
+	go func() {
+		defer close(dataChan)
+		for m := range manifestChan {
+			if err := common.CancellableWrite(ctx, dataChan, m.data); err != nil {
+				return
+			}
+		}
+	}()
+	return dataChan
+}
+
+// withManifestMetadata returns a copy of the caller's metadata for a chunk of manifest data. The
+// line is set as by withStartLine, and the fields recording what the data was extracted from are
+// set on the source's metadata for the sources that have them. Other metadata is unchanged.
+func withManifestMetadata(metadata *source_metadatapb.MetaData, data manifestData) *source_metadatapb.MetaData {
+	withLine := withStartLine(metadata, data.line)
+	source := sourceMetadata(withLine)
+	if source == nil {
+		return withLine
+	}
+	var set []protoreflect.FieldDescriptor
+	attribution := data.attribution()
+	for name := range attribution {
+		if fd := source.Descriptor().Fields().ByName(name); fd != nil && fd.Kind() == protoreflect.StringKind {
+			set = append(set, fd)
+		}
+	}
+	if len(set) == 0 {
+		return withLine
+	}
+
+	// withStartLine returns a copy of the metadata types it sets the line of, which can be modified.
+	if withLine == metadata {
+		clone, ok := proto.Clone(metadata).(*source_metadatapb.MetaData)
+		if !ok {
+			return metadata
+		}
+		withLine, source = clone, sourceMetadata(clone)
+	}
+	for _, fd := range set {
+		source.Set(fd, protoreflect.ValueOfString(attribution[fd.Name()]))
+	}
+	return withLine
+}
+
+// sourceMetadata returns the metadata of the source set in metadata, or nil.
+func sourceMetadata(metadata *source_metadatapb.MetaData) protoreflect.Message {
+	if metadata == nil {
+		return nil
+	}
+	msg := metadata.ProtoReflect()
+	fd := msg.WhichOneof(msg.Descriptor().Oneofs().ByName("data"))
+	if fd == nil || fd.Kind() != protoreflect.MessageKind {
+		return nil
+	}
+	return msg.Get(fd).Message()
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func TestWithManifestMetadata(t *testing.T) {
+	data := manifestData{
+		line:       3,
+		credential: &storedCredential{host: "git.example.com", login: "deploy"},
+	}
+	tests := []struct {
+		name     string
+		metadata *source_metadatapb.MetaData
+		want     *source_metadatapb.MetaData
+	}{
+		{
+			name: "filesystem",
+			metadata: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{File: ".git-credentials"},
+			}},
+			want: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{
+					File:            ".git-credentials",
+					Line:            3,
+					CredentialHost:  "git.example.com",
+					CredentialLogin: "deploy",
+				},
+			}},
+		},
+		{
+			name: "stdin",
+			metadata: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Stdin{
+				Stdin: &source_metadatapb.Stdin{},
+			}},
+			want: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Stdin{
+				Stdin: &source_metadatapb.Stdin{Line: 3},
+			}},
+		},
+		{
+			name: "metadata without the fields",
+			metadata: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{File: ".git-credentials", Line: 10},
+			}},
+			want: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{File: ".git-credentials", Line: 10},
+			}},
+		},
+		{name: "no metadata"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := proto.Clone(tt.metadata)
+			got := withManifestMetadata(tt.metadata, data)
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+			// The caller's metadata is not modified.
+			assert.True(t, proto.Equal(original, tt.metadata))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/mholt/archiver/v4"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// packageFile identifies the file of a software package the data was found in.
+type packageFile struct {
+	// name is the name of the package, as recorded in its metadata, or "" if it's unknown.
+	name string
+	// path is the path of the file in the package.
+	path string
+}
+
+// packageHandler specializes defaultHandler for .deb and .rpm packages, whose files are
+// extracted with the handler selected for them and reported along with their path.
+type packageHandler struct {
+	*defaultHandler
+	// maxDepth bounds the nesting of archives that is extracted. The files of the package
+	// are at depth 1.
+	maxDepth int
+}
+
+func newPackageHandler(handlerType handlerType, maxDepth int) *packageHandler {
+	return &packageHandler{defaultHandler: newDefaultHandler(handlerType), maxDepth: maxDepth}
+}
+
+// handlePackageFile extracts the content of a file of a package with the handler selected for
+// it, as HandleFile would for a file of the same name and content, and passes it to emit.
+func (h *packageHandler) handlePackageFile(ctx logContext.Context, reader io.Reader, size int64, file packageFile, emit func(manifestData) error) error {
+	lCtx := logContext.WithValues(ctx, "package_file", file.path, "size", size)
+	if h.maxDepth <= 1 {
+		lCtx.Logger().V(2).Info("max archive depth reached, skipping package file", "max_depth", h.maxDepth)
+		h.metrics.incMaxArchiveDepthCount()
+		return nil
+	}
+	if size > int64(maxSize) {
+		lCtx.Logger().V(3).Info("skipping package file due to size")
+		h.metrics.incFilesSkipped()
+		return nil
+	}
+	if common.SkipFile(file.path) || common.IsBinary(file.path) {
+		lCtx.Logger().V(5).Info("skipping package file")
+		h.metrics.incFilesSkipped()
+		return nil
+	}
+
+	rdr, err := newFileReader(io.NopCloser(io.LimitReader(reader, int64(maxSize))))
+	if err != nil {
+		if errors.Is(err, ErrEmptyReader) {
+			lCtx.Logger().V(5).Info("empty package file, skipping it")
+			return nil
+		}
+		return fmt.Errorf("error creating custom reader: %w", err)
+	}
+	defer rdr.Close()
+
+	if err := rdr.identifyByName(file.path); err != nil {
+		return fmt.Errorf("error identifying package file %s: %w", file.path, err)
+	}
+	config := newFileHandlingConfig(WithFileName(file.path), WithMaxArchiveDepth(h.maxDepth-1))
+	dataChan, err := selectHandler(rdr, config).HandleFile(lCtx, rdr)
+	if err != nil {
+		return fmt.Errorf("error handling package file %s: %w", file.path, err)
+	}
+	for data := range dataChan {
+		if err := emit(manifestData{pkg: &file, data: data}); err != nil {
+			return err
+		}
+	}
+
+	h.metrics.incFilesProcessed()
+	h.metrics.observeFileSize(size)
+	return nil
+}
+
+// extractTar passes each regular file of a tar archive, which may be compressed, to handle.
+// Compressed archives are decompressed as nested archives are, within the same size limit.
+func extractTar(ctx logContext.Context, reader fileReader, handle func(ctx logContext.Context, file archiver.File) error) error {
+	if decompressor, ok := reader.format.(archiver.Decompressor); ok {
+		rdr, err := decompress(ctx, reader, decompressor)
+		if err != nil {
+			return err
+		}
+		defer rdr.Close()
+		reader = rdr
+	}
+	if _, ok := reader.format.(archiver.Tar); !ok {
+		return fmt.Errorf("not a tar archive: %s", reader.mimeType)
+	}
+
+	return archiver.Tar{}.Extract(ctx, reader.BufferedFileReader, nil, func(_ context.Context, file archiver.File) error {
+		if file.IsDir() || file.LinkTarget != "" || !file.Mode().IsRegular() {
+			return nil
+		}
+		if common.IsDone(ctx) {
+			return ctx.Err()
+		}
+		return handle(ctx, file)
+	})
+}
+
+// installedPath returns the path a file of a package is installed at, given its path in the
+// package's payload, such as ./usr/bin/tool.
+func installedPath(name string) string {
+	return path.Clean("/" + name)
+}
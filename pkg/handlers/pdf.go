@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// pageData is a chunk of data extracted from a paged document.
+type pageData struct {
+	// page is the 1-based page the data was found on, or 0 if the data isn't paged.
+	page int64
+	data []byte
+}
+
+// pageHandler is implemented by handlers of paged documents, which report the page of the document
+// each chunk of data was found on.
+type pageHandler interface {
+	HandlePages(ctx logContext.Context, reader fileReader) (chan pageData, error)
+}
+
+// maxPDFSize is the size of the largest PDF document that is parsed. Larger documents are truncated,
+// which keeps the text of the objects before the limit, as the parser doesn't need the trailing
+// cross-reference table.
+var maxPDFSize = 64 << 20 // 64 MB
+
+// pdfHandler specializes defaultHandler to extract the text of PDF documents.
+type pdfHandler struct{ *defaultHandler }
+
+// newPDFHandler creates a pdfHandler.
+func newPDFHandler() *pdfHandler {
+	return &pdfHandler{defaultHandler: newDefaultHandler(pdfHandlerType)}
+}
+
+// HandleFile processes PDF documents, discarding the pages the data was found on.
+func (h *pdfHandler) HandleFile(ctx logContext.Context, input fileReader) (chan []byte, error) {
+	pageChan, err := h.HandlePages(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	dataChan := make(chan []byte, defaultBufferSize)
+	go func() {
+		defer close(dataChan)
+		for p := range pageChan {
+			if err := common.CancellableWrite(ctx, dataChan, p.data); err != nil {
+				return
+			}
+		}
+	}()
+	return dataChan, nil
+}
+
+// HandlePages processes PDF documents, reporting the text of each page.
+// Malformed documents are logged and skipped.
+func (h *pdfHandler) HandlePages(ctx logContext.Context, input fileReader) (chan pageData, error) {
+	pageChan := make(chan pageData, defaultBufferSize)
+
+	go func() {
+		ctx, cancel := logContext.WithTimeout(ctx, maxTimeout)
+		defer cancel()
+		defer close(pageChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		// Defer a panic recovery to handle any panics that occur while parsing malformed documents.
+		defer func() {
+			if r := recover(); r != nil {
+				// Return the panic as an error.
+				if e, ok := r.(error); ok {
+					err = e
+				} else {
+					err = fmt.Errorf("panic occurred: %v", r)
+				}
+				ctx.Logger().Error(err, "Panic occurred when reading PDF")
+			}
+		}()
+
+		err = h.handlePDFContent(ctx, input, func(page int64, data []byte) error {
+			return common.CancellableWrite(ctx, pageChan, pageData{page: page, data: data})
+		})
+		if err != nil {
+			ctx.Logger().Error(err, "error handling PDF")
+		}
+	}()
+
+	return pageChan, nil
+}
+
+// handlePDFContent extracts the text of each page of a PDF document and passes it to emit in chunks.
+func (h *defaultHandler) handlePDFContent(ctx logContext.Context, reader io.Reader, emit func(page int64, data []byte) error) error {
+	limit := min(maxSize, maxPDFSize)
+	data, err := io.ReadAll(io.LimitReader(reader, int64(limit)+1))
+	if err != nil {
+		return fmt.Errorf("error reading PDF: %w", err)
+	}
+	if len(data) > limit {
+		ctx.Logger().V(2).Info("PDF size limit reached, truncating", "max_size", limit)
+		data = data[:limit]
+	}
+	doc, err := parsePDF(data)
+	if err != nil {
+		return fmt.Errorf("error parsing PDF: %w", err)
+	}
+
+	for i, page := range doc.pages() {
+		text := doc.pageText(page)
+		if len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+		pageNum := int64(i + 1)
+		if err := h.chunkContent(ctx, bytes.NewReader(text), func(data []byte) error { return emit(pageNum, data) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,803 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+// This file implements the subset of PDF needed to extract text. It doesn't rely on the cross-reference table, which is often broken
+// in the wild, and instead finds objects by scanning the file. Objects that
+// can't be parsed are skipped, so a partially corrupt document still yields
+// what can be read.
+
+type (
+	pdfName   string
+	pdfString []byte
+	pdfArray  []any
+	pdfDict   map[pdfName]any
+	pdfRef    struct{ num, gen int64 }
+	// pdfKeyword is a bare keyword, such as an operator in a content stream.
+	pdfKeyword string
+)
+
+// pdfObject is an indirect object. Stream is the undecoded data of stream objects.
+type pdfObject struct {
+	value  any
+	stream []byte
+}
+
+// pdfDocument is a parsed PDF document.
+type pdfDocument struct {
+	objects map[int64]*pdfObject
+	// decoded is the number of bytes decoded from streams so far.
+	decoded int
+}
+
+// maxPDFTreeDepth bounds how deeply references, the page tree and inherited
+// page attributes are followed, so a crafted cyclic document can't loop forever.
+const maxPDFTreeDepth = 64
+
+var (
+	errPDFNotFound    = errors.New("PDF header not found")
+	errPDFDecodedSize = errors.New("PDF decoded size limit reached")
+)
+
+var pdfObjectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// parsePDF parses the indirect objects of a PDF document, including the
+// objects stored in object streams.
+func parsePDF(data []byte) (*pdfDocument, error) {
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return nil, errPDFNotFound
+	}
+
+	doc := &pdfDocument{objects: make(map[int64]*pdfObject)}
+	for _, loc := range pdfObjectHeader.FindAllSubmatchIndex(data, -1) {
+		num, err := strconv.ParseInt(string(data[loc[2]:loc[3]]), 10, 64)
+		if err != nil {
+			continue
+		}
+		obj, err := parsePDFIndirectObject(data, loc[1])
+		if err != nil {
+			continue
+		}
+		// Objects redefined by incremental updates appear later in the file.
+		doc.objects[num] = obj
+	}
+
+	// Objects in object streams are compressed, so they aren't found by scanning the file.
+	for _, obj := range doc.sortedObjects() {
+		dict, ok := obj.value.(pdfDict)
+		if !ok || obj.stream == nil || dict["Type"] != pdfName("ObjStm") {
+			continue
+		}
+		doc.parseObjectStream(obj)
+	}
+	return doc, nil
+}
+
+// parsePDFIndirectObject parses the object that follows an "obj" keyword at pos.
+func parsePDFIndirectObject(data []byte, pos int) (*pdfObject, error) {
+	p := &pdfLexer{data: data, pos: pos}
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	obj := &pdfObject{value: value}
+
+	dict, ok := value.(pdfDict)
+	if !ok {
+		return obj, nil
+	}
+	p.skipSpace()
+	if !bytes.HasPrefix(data[p.pos:], []byte("stream")) {
+		return obj, nil
+	}
+	start := p.pos + len("stream")
+	// The stream keyword is followed by CRLF or LF.
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+
+	// Trust the length if it's direct and followed by endstream, otherwise
+	// search for the end of the stream.
+	if length, ok := dict["Length"].(int64); ok && length >= 0 && int64(start)+length <= int64(len(data)) {
+		end := start + int(length)
+		if bytes.HasPrefix(bytes.TrimLeft(data[end:min(len(data), end+16)], "\r\n \t"), []byte("endstream")) {
+			obj.stream = data[start:end]
+			return obj, nil
+		}
+	}
+	end := bytes.Index(data[start:], []byte("endstream"))
+	if end < 0 {
+		return nil, errors.New("unterminated stream")
+	}
+	obj.stream = bytes.TrimRight(data[start:start+end], "\r\n")
+	return obj, nil
+}
+
+// parseObjectStream adds the objects stored in an object stream to the document.
+func (d *pdfDocument) parseObjectStream(stream *pdfObject) {
+	dict := stream.value.(pdfDict)
+	data, err := d.decodeStream(stream)
+	if err != nil {
+		return
+	}
+	count, _ := d.resolve(dict["N"]).(int64)
+	first, _ := d.resolve(dict["First"]).(int64)
+	if first < 0 || first > int64(len(data)) {
+		return
+	}
+
+	header := &pdfLexer{data: data[:first]}
+	for i := int64(0); i < count; i++ {
+		num, err1 := header.value()
+		offset, err2 := header.value()
+		if err1 != nil || err2 != nil {
+			return
+		}
+		n, ok1 := num.(int64)
+		off, ok2 := offset.(int64)
+		if !ok1 || !ok2 || off < 0 || first+off >= int64(len(data)) {
+			continue
+		}
+		// Objects defined directly in the file take precedence, as they may
+		// be updates of compressed objects.
+		if _, ok := d.objects[n]; ok {
+			continue
+		}
+		value, err := (&pdfLexer{data: data, pos: int(first + off)}).value()
+		if err != nil {
+			continue
+		}
+		d.objects[n] = &pdfObject{value: value}
+	}
+}
+
+// sortedObjects returns the objects ordered by object number.
+func (d *pdfDocument) sortedObjects() []*pdfObject {
+	nums := make([]int64, 0, len(d.objects))
+	for num := range d.objects {
+		nums = append(nums, num)
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+	objects := make([]*pdfObject, 0, len(nums))
+	for _, num := range nums {
+		objects = append(objects, d.objects[num])
+	}
+	return objects
+}
+
+// resolve follows references until it reaches a direct value.
+func (d *pdfDocument) resolve(value any) any {
+	for i := 0; i < maxPDFTreeDepth; i++ {
+		ref, ok := value.(pdfRef)
+		if !ok {
+			return value
+		}
+		obj, ok := d.objects[ref.num]
+		if !ok {
+			return nil
+		}
+		value = obj.value
+	}
+	return nil
+}
+
+// object returns the indirect object a reference points to, or nil.
+func (d *pdfDocument) object(value any) *pdfObject {
+	if ref, ok := value.(pdfRef); ok {
+		return d.objects[ref.num]
+	}
+	return nil
+}
+
+// pages returns the page dictionaries in page order. Pages are found by
+// walking the page tree from the document catalog, falling back to every
+// page object in object order if the tree can't be walked.
+func (d *pdfDocument) pages() []pdfDict {
+	var pages []pdfDict
+	visited := make(map[int64]bool)
+	var walk func(node any, depth int)
+	walk = func(node any, depth int) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref.num] {
+				return
+			}
+			visited[ref.num] = true
+		}
+		dict, ok := d.resolve(node).(pdfDict)
+		if !ok || depth > maxPDFTreeDepth {
+			return
+		}
+		switch dict["Type"] {
+		case pdfName("Page"):
+			pages = append(pages, dict)
+		case pdfName("Pages"):
+			kids, _ := d.resolve(dict["Kids"]).(pdfArray)
+			for _, kid := range kids {
+				walk(kid, depth+1)
+			}
+		}
+	}
+	for _, obj := range d.sortedObjects() {
+		if dict, ok := obj.value.(pdfDict); ok && dict["Type"] == pdfName("Catalog") {
+			walk(dict["Pages"], 0)
+			break
+		}
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	for _, obj := range d.sortedObjects() {
+		if dict, ok := obj.value.(pdfDict); ok && dict["Type"] == pdfName("Page") {
+			pages = append(pages, dict)
+		}
+	}
+	return pages
+}
+
+// inherited returns the value of key in the page or, as allowed for page
+// attributes such as Resources, in its closest ancestor in the page tree.
+func (d *pdfDocument) inherited(page pdfDict, key pdfName) any {
+	node := page
+	for i := 0; i < maxPDFTreeDepth && node != nil; i++ {
+		if value, ok := node[key]; ok {
+			return d.resolve(value)
+		}
+		node, _ = d.resolve(node["Parent"]).(pdfDict)
+	}
+	return nil
+}
+
+// pageText returns the text drawn on a page.
+func (d *pdfDocument) pageText(page pdfDict) []byte {
+	var content []byte
+	contents := d.resolve(page["Contents"])
+	streams, ok := contents.(pdfArray)
+	if !ok {
+		streams = pdfArray{page["Contents"]}
+	}
+	for _, ref := range streams {
+		obj := d.object(ref)
+		if obj == nil || obj.stream == nil {
+			continue
+		}
+		data, err := d.decodeStream(obj)
+		if err != nil {
+			continue
+		}
+		content = append(content, data...)
+		content = append(content, '\n')
+	}
+	if len(content) == 0 {
+		return nil
+	}
+
+	fonts := make(map[pdfName]pdfCMap)
+	if resources, ok := d.inherited(page, "Resources").(pdfDict); ok {
+		fontDict, _ := d.resolve(resources["Font"]).(pdfDict)
+		for name, font := range fontDict {
+			fontObj, _ := d.resolve(font).(pdfDict)
+			if cmapObj := d.object(fontObj["ToUnicode"]); cmapObj != nil && cmapObj.stream != nil {
+				if data, err := d.decodeStream(cmapObj); err == nil {
+					fonts[name] = parsePDFCMap(data)
+				}
+			}
+		}
+	}
+	return extractPDFText(content, fonts)
+}
+
+// decodeStream applies the filters of a stream. Only FlateDecode, which
+// compresses nearly all text content, is supported.
+func (d *pdfDocument) decodeStream(obj *pdfObject) ([]byte, error) {
+	dict, _ := obj.value.(pdfDict)
+	var filters pdfArray
+	switch filter := d.resolve(dict["Filter"]).(type) {
+	case pdfName:
+		filters = pdfArray{filter}
+	case pdfArray:
+		filters = filter
+	}
+
+	// Bound the total size of the decoded streams, so compressed streams can't expand to more
+	// than a small multiple of the document size limit.
+	maxDecoded := 4 * maxPDFSize
+	data := obj.stream
+	for _, filter := range filters {
+		if name := d.resolve(filter); name != pdfName("FlateDecode") && name != pdfName("Fl") {
+			return nil, fmt.Errorf("unsupported stream filter %v", filter)
+		}
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing stream: %w", err)
+		}
+		// Keep what was decompressed from truncated streams.
+		decoded, err := io.ReadAll(io.LimitReader(r, int64(maxDecoded-d.decoded)))
+		if err != nil && len(decoded) == 0 {
+			return nil, fmt.Errorf("error decompressing stream: %w", err)
+		}
+		d.decoded += len(decoded)
+		if d.decoded >= maxDecoded {
+			return nil, errPDFDecodedSize
+		}
+		data = decoded
+	}
+	return data, nil
+}
+
+// pdfCMap maps character codes of a font to Unicode text.
+type pdfCMap struct {
+	// codeLen is the length in bytes of character codes.
+	codeLen int
+	chars   map[uint32][]rune
+}
+
+var (
+	pdfBFChar  = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]*)>`)
+	pdfBFRange = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]*)>`)
+	pdfSection = regexp.MustCompile(`(?s)begin(bfchar|bfrange)(.*?)end(?:bfchar|bfrange)`)
+)
+
+// maxPDFCMapRange bounds the size of a single bfrange entry.
+const maxPDFCMapRange = 1 << 16
+
+// parsePDFCMap parses the bfchar and bfrange mappings of a ToUnicode CMap.
+func parsePDFCMap(data []byte) pdfCMap {
+	cmap := pdfCMap{codeLen: 1, chars: make(map[uint32][]rune)}
+	for _, section := range pdfSection.FindAllSubmatch(data, -1) {
+		if string(section[1]) == "bfchar" {
+			for _, m := range pdfBFChar.FindAllSubmatch(section[2], -1) {
+				cmap.codeLen = max(cmap.codeLen, len(m[1])/2)
+				cmap.chars[pdfHexUint(m[1])] = utf16BEToRunes(decodePDFHex(m[2]))
+			}
+			continue
+		}
+		for _, m := range pdfBFRange.FindAllSubmatch(section[2], -1) {
+			cmap.codeLen = max(cmap.codeLen, len(m[1])/2)
+			lo, hi := pdfHexUint(m[1]), pdfHexUint(m[2])
+			dst := utf16BEToRunes(decodePDFHex(m[3]))
+			if hi < lo || hi-lo > maxPDFCMapRange || len(dst) == 0 {
+				continue
+			}
+			for code := lo; code <= hi; code++ {
+				r := append([]rune{}, dst...)
+				r[len(r)-1] += rune(code - lo)
+				cmap.chars[code] = r
+			}
+		}
+	}
+	return cmap
+}
+
+// decode converts a string shown with the font to text.
+func (c pdfCMap) decode(s []byte) []byte {
+	var buf bytes.Buffer
+	for i := 0; i+c.codeLen <= len(s); i += c.codeLen {
+		var code uint32
+		for _, b := range s[i : i+c.codeLen] {
+			code = code<<8 | uint32(b)
+		}
+		for _, r := range c.chars[code] {
+			buf.WriteRune(r)
+		}
+	}
+	return buf.Bytes()
+}
+
+// extractPDFText returns the text shown by the operators of a content stream.
+// Text is separated by spaces and newlines following the text positioning operators.
+func extractPDFText(content []byte, fonts map[pdfName]pdfCMap) []byte {
+	var (
+		text     bytes.Buffer
+		operands []any
+		cmap     *pdfCMap
+	)
+	show := func(s pdfString) {
+		if cmap != nil {
+			text.Write(cmap.decode(s))
+			return
+		}
+		// Without a ToUnicode map, assume a Latin-1 compatible encoding.
+		for _, b := range s {
+			text.WriteRune(rune(b))
+		}
+	}
+	newline := func() {
+		if text.Len() > 0 && text.Bytes()[text.Len()-1] != '\n' {
+			text.WriteByte('\n')
+		}
+	}
+
+	lex := &pdfLexer{data: content}
+	for {
+		value, err := lex.value()
+		if err != nil {
+			break
+		}
+		op, ok := value.(pdfKeyword)
+		if !ok {
+			operands = append(operands, value)
+			continue
+		}
+
+		switch op {
+		case "BI":
+			// Skip the data of inline images.
+			lex.skipInlineImage()
+		case "Tf":
+			cmap = nil
+			if len(operands) >= 2 {
+				if font, ok := fonts[pdfNameOf(operands[len(operands)-2])]; ok {
+					cmap = &font
+				}
+			}
+		case "Tj":
+			if s, ok := lastPDFOperand[pdfString](operands); ok {
+				show(s)
+			}
+		case "'", "\"":
+			newline()
+			if s, ok := lastPDFOperand[pdfString](operands); ok {
+				show(s)
+			}
+		case "TJ":
+			array, _ := lastPDFOperand[pdfArray](operands)
+			for _, elem := range array {
+				switch v := elem.(type) {
+				case pdfString:
+					show(v)
+				case int64, float64:
+					// Large negative adjustments separate words.
+					if pdfNumber(v) < -200 {
+						text.WriteByte(' ')
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 && pdfNumber(operands[len(operands)-1]) != 0 {
+				newline()
+			} else if text.Len() > 0 {
+				text.WriteByte(' ')
+			}
+		case "T*", "Tm", "ET":
+			newline()
+		}
+		operands = operands[:0]
+	}
+	return text.Bytes()
+}
+
+func lastPDFOperand[T any](operands []any) (T, bool) {
+	var zero T
+	if len(operands) == 0 {
+		return zero, false
+	}
+	v, ok := operands[len(operands)-1].(T)
+	return v, ok
+}
+
+func pdfNameOf(v any) pdfName {
+	name, _ := v.(pdfName)
+	return name
+}
+
+func pdfNumber(v any) float64 {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+func pdfHexUint(h []byte) uint32 {
+	n, _ := strconv.ParseUint(string(h[:min(len(h), 8)]), 16, 32)
+	return uint32(n)
+}
+
+// decodePDFHex decodes a hex string, ignoring whitespace. A missing final
+// digit is taken to be 0.
+func decodePDFHex(h []byte) []byte {
+	digits := make([]byte, 0, len(h)+1)
+	for _, c := range h {
+		if isPDFHexDigit(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	_, _ = hex.Decode(out, digits)
+	return out
+}
+
+func utf16BEToRunes(b []byte) []rune {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return utf16.Decode(units)
+}
+
+func isPDFHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func isPDFSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// maxPDFNesting bounds the nesting of arrays and dictionaries.
+const maxPDFNesting = 256
+
+var errPDFSyntax = errors.New("invalid PDF syntax")
+
+// pdfLexer parses PDF values from data.
+type pdfLexer struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch c := l.data[l.pos]; {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// value parses the next value. Integers followed by a generation number and
+// R are parsed as references.
+func (l *pdfLexer) value() (any, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, io.EOF
+	}
+
+	switch c := l.data[l.pos]; {
+	case c == '/':
+		return l.name(), nil
+	case c == '(':
+		return l.literalString()
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return l.dict()
+	case c == '<':
+		end := bytes.IndexByte(l.data[l.pos:], '>')
+		if end < 0 {
+			return nil, errPDFSyntax
+		}
+		s := decodePDFHex(l.data[l.pos+1 : l.pos+end])
+		l.pos += end + 1
+		return pdfString(s), nil
+	case c == '[':
+		l.pos++
+		return l.array()
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		// Stray delimiters are returned as keywords so callers can skip them.
+		l.pos++
+		return pdfKeyword(c), nil
+	}
+
+	token := l.token()
+	if n, err := strconv.ParseInt(token, 10, 64); err == nil {
+		// Look ahead for "gen R".
+		save := l.pos
+		l.skipSpace()
+		gen, err := strconv.ParseInt(l.token(), 10, 64)
+		if err == nil {
+			l.skipSpace()
+			if l.token() == "R" {
+				return pdfRef{num: n, gen: gen}, nil
+			}
+		}
+		l.pos = save
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(token, 64); err == nil {
+		return f, nil
+	}
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return pdfKeyword(token), nil
+}
+
+// token reads a run of regular characters.
+func (l *pdfLexer) token() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *pdfLexer) name() pdfName {
+	l.pos++ // Skip the slash.
+	token := l.token()
+	// Names may escape characters as #xx.
+	var buf []byte
+	for i := 0; i < len(token); i++ {
+		if token[i] == '#' && i+2 < len(token) && isPDFHexDigit(token[i+1]) && isPDFHexDigit(token[i+2]) {
+			buf = append(buf, decodePDFHex([]byte(token[i+1:i+3]))...)
+			i += 2
+			continue
+		}
+		buf = append(buf, token[i])
+	}
+	return pdfName(buf)
+}
+
+func (l *pdfLexer) literalString() (pdfString, error) {
+	l.pos++ // Skip the opening parenthesis.
+	var buf []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return buf, nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return nil, errPDFSyntax
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// A backslash at the end of a line continues the string.
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if '0' <= c && c <= '7' {
+					n := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && '0' <= l.data[l.pos] && l.data[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(n)
+				}
+			}
+		}
+		buf = append(buf, c)
+	}
+	return nil, errPDFSyntax
+}
+
+func (l *pdfLexer) array() (pdfArray, error) {
+	if l.depth++; l.depth > maxPDFNesting {
+		return nil, errPDFSyntax
+	}
+	defer func() { l.depth-- }()
+
+	var array pdfArray
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return nil, errPDFSyntax
+		}
+		if l.data[l.pos] == ']' {
+			l.pos++
+			return array, nil
+		}
+		v, err := l.value()
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, v)
+	}
+}
+
+func (l *pdfLexer) dict() (pdfDict, error) {
+	if l.depth++; l.depth > maxPDFNesting {
+		return nil, errPDFSyntax
+	}
+	defer func() { l.depth-- }()
+
+	dict := make(pdfDict)
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return nil, errPDFSyntax
+		}
+		if bytes.HasPrefix(l.data[l.pos:], []byte(">>")) {
+			l.pos += 2
+			return dict, nil
+		}
+		key, err := l.value()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			return nil, errPDFSyntax
+		}
+		v, err := l.value()
+		if err != nil {
+			return nil, err
+		}
+		dict[name] = v
+	}
+}
+
+// skipInlineImage skips past the data of an inline image, which ends with EI.
+func (l *pdfLexer) skipInlineImage() {
+	id := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if id < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += id + len("ID")
+	for {
+		ei := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if ei < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		l.pos += ei + len("EI")
+		if l.pos >= len(l.data) || isPDFSpace(l.data[l.pos]) {
+			return
+		}
+	}
+}
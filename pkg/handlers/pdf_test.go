@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// buildPDF builds a PDF document from the bodies of its objects, numbered from 1.
+// Empty bodies are skipped, for objects stored in object streams.
+func buildPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		if obj == "" {
+			continue
+		}
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		if offset == 0 {
+			buf.WriteString("0000000000 00000 f \n")
+			continue
+		}
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func pdfStream(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func flate(data string) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, _ = w.Write([]byte(data))
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func TestHandleFilePDF(t *testing.T) {
+	toUnicode := `/CIDInit /ProcSet findresource begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar
+<0001> <0073>
+<0002> <006B>
+endbfchar
+1 beginbfrange
+<0010> <0019> <0030>
+endbfrange
+endcmap`
+	pdf := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R /Names << /EmbeddedFiles << /Names [(secret.env) 9 0 R] >> >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 6 0 R /F2 12 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [7 0 R 8 0 R] >>",
+		pdfStream("", []byte("BT /F2 12 Tf 72 720 Td (token = \\(abc\\)) Tj T* (second line) Tj ET")),
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /ToUnicode 11 0 R >>",
+		pdfStream("/Filter /FlateDecode", flate("BT /F2 12 Tf [(api_) -20 (key) -500 (next)] TJ ET")),
+		pdfStream("", []byte("BT /F1 12 Tf <000100020010001100120013> Tj ET")),
+		"<< /Type /Filespec /F (secret.env) /EF << /F 10 0 R >> >>",
+		pdfStream("/Type /EmbeddedFile /Filter /FlateDecode", flate("PASSWORD=attached")),
+		pdfStream("", []byte(toUnicode)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+
+	chunkCh := make(chan *sources.Chunk, 8)
+	chunkSkel := &sources.Chunk{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{
+				Filesystem: &source_metadatapb.Filesystem{File: "doc.pdf"},
+			},
+		},
+	}
+	err := HandleFile(logContext.Background(), io.NopCloser(bytes.NewReader(pdf)), chunkSkel, sources.ChanReporter{Ch: chunkCh})
+	assert.NoError(t, err)
+	close(chunkCh)
+
+	pages := make(map[int64]string)
+	for chunk := range chunkCh {
+		metadata := chunk.SourceMetadata.GetFilesystem()
+		assert.Equal(t, "doc.pdf", metadata.GetFile())
+		assert.Equal(t, int64(1), metadata.GetLine())
+		pages[metadata.GetPage()] += string(chunk.Data)
+	}
+	assert.Equal(t, map[int64]string{
+		1: "token = (abc)\nsecond line\n",
+		2: "api_key next\nsk0123\n",
+	}, pages, "attached files aren't extracted")
+	// The skeleton metadata is not modified.
+	assert.Equal(t, int64(0), chunkSkel.SourceMetadata.GetFilesystem().GetPage())
+}
+
+func TestHandleFilePDFObjectStream(t *testing.T) {
+	// The page tree is stored in a compressed object stream, as in most modern documents.
+	objects := "<< /Type /Pages /Kids [4 0 R] /Count 1 >> << /Type /Page /Parent 3 0 R /Contents 5 0 R >>"
+	header := fmt.Sprintf("3 0 4 %d ", len("<< /Type /Pages /Kids [4 0 R] /Count 1 >> "))
+	pdf := buildPDF(
+		"<< /Type /Catalog /Pages 3 0 R >>",
+		pdfStream(fmt.Sprintf("/Type /ObjStm /N 2 /First %d /Filter /FlateDecode", len(header)), flate(header+objects)),
+		"",
+		"",
+		pdfStream("", []byte("BT (compressed page tree) Tj ET")),
+	)
+
+	chunkCh := make(chan *sources.Chunk, 8)
+	err := HandleFile(logContext.Background(), io.NopCloser(bytes.NewReader(pdf)), &sources.Chunk{}, sources.ChanReporter{Ch: chunkCh})
+	assert.NoError(t, err)
+	close(chunkCh)
+
+	var text strings.Builder
+	for chunk := range chunkCh {
+		text.Write(chunk.Data)
+	}
+	assert.Equal(t, "compressed page tree\n", text.String())
+}
+
+func TestHandleFileMalformedPDF(t *testing.T) {
+	tests := map[string][]byte{
+		"truncated":          buildPDF("<< /Type /Catalog /Pages 2 0 R >>")[:20],
+		"unterminated":       []byte("%PDF-1.4\n1 0 obj\n<< /Type /Page /Contents 2 0 R\n2 0 obj\n<< /Length 99 >>\nstream\nBT (x"),
+		"corrupt stream":     buildPDF("<< /Type /Page /Contents 2 0 R >>", pdfStream("/Filter /FlateDecode", []byte("not zlib"))),
+		"cyclic page tree":   buildPDF("<< /Type /Catalog /Pages 2 0 R >>", "<< /Type /Pages /Kids [2 0 R] /Parent 2 0 R >>"),
+		"deeply nested":      buildPDF(strings.Repeat("[", 10000)),
+		"unsupported filter": buildPDF("<< /Type /Page /Contents 2 0 R >>", pdfStream("/Filter /DCTDecode", []byte("jpeg"))),
+	}
+	for name, pdf := range tests {
+		t.Run(name, func(t *testing.T) {
+			chunkCh := make(chan *sources.Chunk, 8)
+			err := HandleFile(logContext.Background(), io.NopCloser(bytes.NewReader(pdf)), &sources.Chunk{}, sources.ChanReporter{Ch: chunkCh})
+			assert.NoError(t, err)
+			close(chunkCh)
+			assert.Empty(t, chunkCh)
+		})
+	}
+}
+
+func TestHandleFilePDFSizeLimits(t *testing.T) {
+	defer func(size int) { maxPDFSize = size }(maxPDFSize)
+	maxPDFSize = 1024
+
+	page := func(text string) string { return pdfStream("", []byte("BT ("+text+") Tj ET")) }
+	tests := []struct {
+		name string
+		pdf  []byte
+		want string
+	}{
+		{
+			name: "truncated document",
+			pdf: buildPDF(
+				"<< /Type /Page /Contents 2 0 R >>",
+				page("first page"),
+				"<< /Type /Page /Contents 4 0 R >>",
+				page(strings.Repeat("x", 2048)),
+			),
+			want: "first page\n",
+		},
+		{
+			name: "decompression bomb",
+			pdf: buildPDF(
+				"<< /Type /Page /Contents 2 0 R >>",
+				page("first page"),
+				"<< /Type /Page /Contents 4 0 R >>",
+				pdfStream("/Filter /FlateDecode", flate("BT ("+strings.Repeat("x", 8192)+") Tj ET")),
+			),
+			want: "first page\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunkCh := make(chan *sources.Chunk, 8)
+			err := HandleFile(logContext.Background(), io.NopCloser(bytes.NewReader(tt.pdf)), &sources.Chunk{}, sources.ChanReporter{Ch: chunkCh})
+			assert.NoError(t, err)
+			close(chunkCh)
+
+			var text strings.Builder
+			for chunk := range chunkCh {
+				text.Write(chunk.Data)
+			}
+			assert.Equal(t, tt.want, text.String())
+		})
+	}
+}
@@ -4,75 +4,50 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"time"
 
 	"github.com/sassoftware/go-rpmutils"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
 )
 
-// rpmHandler specializes archiveHandler to manage RPM package files.
-type rpmHandler struct{ *defaultHandler }
+// rpmHandler specializes packageHandler to manage RPM package files.
+type rpmHandler struct{ *packageHandler }
 
-// newRPMHandler creates an rpmHandler with the provided metrics.
-func newRPMHandler() *rpmHandler {
-	return &rpmHandler{defaultHandler: newDefaultHandler(rpmHandlerType)}
+// newRPMHandler creates an rpmHandler.
+func newRPMHandler(maxDepth int) *rpmHandler {
+	return &rpmHandler{packageHandler: newPackageHandler(rpmHandlerType, maxDepth)}
 }
 
-// HandleFile processes RPM formatted files. Further implementation is required to appropriately
-// handle RPM specific archive operations.
+// HandleFile processes RPM formatted files, discarding the files the data was found in.
 func (h *rpmHandler) HandleFile(ctx logContext.Context, input fileReader) (chan []byte, error) {
-	archiveChan := make(chan []byte, defaultBufferSize)
-
-	go func() {
-		ctx, cancel := logContext.WithTimeout(ctx, maxTimeout)
-		defer cancel()
-		defer close(archiveChan)
-
-		// Update the metrics for the file processing.
-		start := time.Now()
-		var err error
-		defer func() {
-			h.measureLatencyAndHandleErrors(start, err)
-			h.metrics.incFilesProcessed()
-		}()
-
-		// Defer a panic recovery to handle any panics that occur during the RPM processing.
-		defer func() {
-			if r := recover(); r != nil {
-				// Return the panic as an error.
-				if e, ok := r.(error); ok {
-					err = e
-				} else {
-					err = fmt.Errorf("panic occurred: %v", r)
-				}
-				ctx.Logger().Error(err, "Panic occurred when reading rpm archive")
-			}
-		}()
+	manifestChan, err := h.HandleManifest(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return manifestDataOnly(ctx, manifestChan), nil
+}
 
-		var rpm *rpmutils.Rpm
-		rpm, err = rpmutils.ReadRpm(input)
+// HandleManifest processes RPM formatted files. The content of each file of the cpio payload is
+// reported along with the name of the package and the path of the file.
+func (h *rpmHandler) HandleManifest(ctx logContext.Context, input fileReader) (chan manifestData, error) {
+	return h.handleManifest(ctx, "rpm archive", func(ctx logContext.Context, emit func(manifestData) error) error {
+		rpm, err := rpmutils.ReadRpm(input)
 		if err != nil {
-			ctx.Logger().Error(err, "error reading RPM")
-			return
+			return fmt.Errorf("error reading RPM: %w", err)
 		}
+		name, _ := rpm.Header.GetString(rpmutils.NAME)
 
-		var reader rpmutils.PayloadReader
-		reader, err = rpm.PayloadReaderExtended()
+		// The payload is decompressed by rpmutils, according to the compressor recorded in the header.
+		reader, err := rpm.PayloadReaderExtended()
 		if err != nil {
-			ctx.Logger().Error(err, "error getting RPM payload reader")
-			return
+			return fmt.Errorf("error getting RPM payload reader: %w", err)
 		}
-
-		if err = h.processRPMFiles(ctx, reader, archiveChan); err != nil {
-			ctx.Logger().Error(err, "error processing RPM files")
-		}
-	}()
-
-	return archiveChan, nil
+		return h.processRPMFiles(logContext.WithValues(ctx, "package", name), name, reader, emit)
+	}), nil
 }
 
-func (h *rpmHandler) processRPMFiles(ctx logContext.Context, reader rpmutils.PayloadReader, archiveChan chan []byte) error {
+func (h *rpmHandler) processRPMFiles(ctx logContext.Context, name string, reader rpmutils.PayloadReader, emit func(manifestData) error) error {
 	for {
 		select {
 		case <-ctx.Done():
@@ -86,17 +61,18 @@ func (h *rpmHandler) processRPMFiles(ctx logContext.Context, reader rpmutils.Pay
 				}
 				return fmt.Errorf("error reading RPM payload: %w", err)
 			}
+			if reader.IsLink() {
+				continue
+			}
 
-			fileSize := fileInfo.Size()
-			fileCtx := logContext.WithValues(ctx, "filename", fileInfo.Name, "size", fileSize)
-
-			if err := h.handleNonArchiveContent(fileCtx, reader, archiveChan); err != nil {
-				fileCtx.Logger().Error(err, "error handling archive content in RPM")
+			file := packageFile{name: name, path: installedPath(fileInfo.Name())}
+			if err := h.handlePackageFile(ctx, reader, fileInfo.Size(), file, emit); err != nil {
+				if common.IsDone(ctx) {
+					return err
+				}
+				ctx.Logger().Error(err, "error handling package file in RPM")
 				h.metrics.incErrors()
 			}
-
-			h.metrics.incFilesProcessed()
-			h.metrics.observeFileSize(fileSize)
 		}
 	}
 }
@@ -22,7 +22,7 @@ func TestHandleRPMFile(t *testing.T) {
 	assert.NoError(t, err)
 	defer rdr.Close()
 
-	handler := newRPMHandler()
+	handler := newRPMHandler(maxDepth)
 	archiveChan, err := handler.HandleFile(context.AddLogger(ctx), rdr)
 	assert.NoError(t, err)
 
@@ -34,3 +34,14 @@ func TestHandleRPMFile(t *testing.T) {
 
 	assert.Equal(t, wantChunkCount, count)
 }
+
+func TestHandleRPMFileMetadata(t *testing.T) {
+	// The source tarball of the package is extracted as a nested archive.
+	files := packageChunkFiles(t, "testdata/test.rpm")
+	assert.Equal(t, map[packageFile]int{
+		{name: "kktest", path: "/hello.spec"}:          1,
+		{name: "kktest", path: "/kktest-0.0.0.tar.gz"}: 178,
+	}, files)
+
+	assert.Empty(t, packageChunkFiles(t, "testdata/test.rpm", WithMaxArchiveDepth(1)))
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bodgit/sevenzip"
+)
+
+const (
+	// sevenZipSignatureHeaderSize is the size of the header starting 7z archives, which locates
+	// the header describing their content at their end.
+	sevenZipSignatureHeaderSize = 32
+	// sevenZipEncodedHeader starts the header of archives whose header is itself compressed,
+	// and possibly encrypted.
+	sevenZipEncodedHeader = 0x17
+	// maxSevenZipHeaderSize bounds the size of the header that is read to find out whether an
+	// archive is encrypted.
+	maxSevenZipHeaderSize = 1 << 20
+	// sevenZipProbeSize is the number of bytes of content read to find out whether an archive is
+	// encrypted.
+	sevenZipProbeSize = 64
+)
+
+// sevenZipAESCoder is the AES-256 coder 7-Zip encrypts archives with, as it's listed in headers:
+// the flags of a coder with a 4-byte ID and properties, followed by its ID.
+var sevenZipAESCoder = []byte{0x24, 0x06, 0xf1, 0x07, 0x01}
+
+// isEncryptedSevenZip reports whether a 7z archive is encrypted. Archives without a password
+// can't be extracted: decrypting them with the wrong key only yields garbage or errors.
+//
+// The AES coder is listed in the clear in headers that aren't compressed, or are encrypted
+// themselves. Compressed headers can't be searched without decompressing them, so the start of
+// the content is read with two passwords instead, as it only depends on the password if it's
+// encrypted. The reader is left at its start.
+func isEncryptedSevenZip(reader fileReader) (bool, error) {
+	defer func() { _, _ = reader.Seek(0, io.SeekStart) }()
+
+	size := int64(reader.Size())
+	var signature [sevenZipSignatureHeaderSize]byte
+	if _, err := io.ReadFull(io.NewSectionReader(reader, 0, size), signature[:]); err != nil {
+		return false, fmt.Errorf("error reading 7z signature header: %w", err)
+	}
+	offset := binary.LittleEndian.Uint64(signature[12:20])
+	headerSize := binary.LittleEndian.Uint64(signature[20:28])
+	if headerSize > maxSevenZipHeaderSize || offset > uint64(size) {
+		return false, fmt.Errorf("invalid 7z header size %d at offset %d", headerSize, offset)
+	}
+
+	header := make([]byte, headerSize)
+	section := io.NewSectionReader(reader, sevenZipSignatureHeaderSize+int64(offset), int64(headerSize))
+	if _, err := io.ReadFull(section, header); err != nil {
+		return false, fmt.Errorf("error reading 7z header: %w", err)
+	}
+	if bytes.Contains(header, sevenZipAESCoder) {
+		return true, nil
+	}
+	if len(header) == 0 || header[0] != sevenZipEncodedHeader {
+		return false, nil
+	}
+
+	withoutPassword, err := probeSevenZip(reader, size, "")
+	if err != nil {
+		return false, err
+	}
+	withPassword, err := probeSevenZip(reader, size, "\x00")
+	if err != nil {
+		return false, err
+	}
+	return withoutPassword != withPassword, nil
+}
+
+// probeSevenZip returns the start of the first file of a 7z archive with content, decrypted with
+// password if it's encrypted, or the error reading it, which is as telling.
+func probeSevenZip(reader fileReader, size int64, password string) (string, error) {
+	zr, err := sevenzip.NewReaderWithPassword(reader, size, password)
+	if err != nil {
+		return "", fmt.Errorf("error reading 7z archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || f.UncompressedSize == 0 {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err.Error(), nil
+		}
+		defer rc.Close()
+
+		buf := make([]byte, sevenZipProbeSize)
+		n, err := io.ReadFull(rc, buf)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err.Error(), nil
+		}
+		return string(buf[:n]), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestHandleFileSevenZip(t *testing.T) {
+	file, err := os.Open("testdata/test.7z")
+	assert.NoError(t, err)
+
+	chunkCh := make(chan *sources.Chunk)
+	go func() {
+		defer close(chunkCh)
+		err := HandleFile(logContext.Background(), file, &sources.Chunk{}, sources.ChanReporter{Ch: chunkCh})
+		assert.NoError(t, err)
+	}()
+
+	var data []string
+	for chunk := range chunkCh {
+		data = append(data, string(chunk.Data))
+	}
+	// The gzipped file nested in the archive is decompressed.
+	assert.ElementsMatch(t, []string{
+		"DB_HOST=db.internal\nDB_PASSWORD=sevenzip-secret-1\n",
+		"nested sevenzip-secret-2\n",
+	}, data)
+}
+
+func TestHandleFileEncryptedSevenZip(t *testing.T) {
+	for _, name := range []string{"testdata/encrypted.7z", "testdata/encrypted-compressed-header.7z"} {
+		t.Run(name, func(t *testing.T) {
+			file, err := os.Open(name)
+			assert.NoError(t, err)
+
+			chunkCh := make(chan *sources.Chunk, 1)
+			assert.NoError(t, HandleFile(logContext.Background(), file, &sources.Chunk{}, sources.ChanReporter{Ch: chunkCh}))
+			assert.Empty(t, chunkCh)
+		})
+	}
+}
+
+func TestIsEncryptedSevenZip(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{file: "testdata/test.7z", want: false},
+		// The AES coder is listed in the header.
+		{file: "testdata/encrypted.7z", want: true},
+		// The header is compressed, so the content is read with two passwords.
+		{file: "testdata/encrypted-compressed-header.7z", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			file, err := os.Open(tt.file)
+			assert.NoError(t, err)
+			rdr, err := newFileReader(file)
+			assert.NoError(t, err)
+			defer rdr.Close()
+
+			got, err := isEncryptedSevenZip(rdr)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			// The archive can still be read from its start.
+			header := make([]byte, 6)
+			_, err = rdr.Read(header)
+			assert.NoError(t, err)
+			assert.Equal(t, "7z\xbc\xaf\x27\x1c", string(header))
+		})
+	}
+}
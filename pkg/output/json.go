@@ -3,6 +3,8 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"sync"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
@@ -14,9 +16,108 @@ import (
 )
 
 // JSONPrinter is a printer that prints results in JSON format.
-type JSONPrinter struct{ mu sync.Mutex }
+type JSONPrinter struct {
+	mu sync.Mutex
+	w  io.Writer
+	jsonOptions
+}
+
+// JSONOption configures the printers of JSON results.
+type JSONOption func(*jsonOptions)
+
+type jsonOptions struct {
+	redaction Redaction
+}
+
+// WithRedaction sets the policy for writing the secret fields of results.
+func WithRedaction(redaction Redaction) JSONOption {
+	return func(o *jsonOptions) { o.redaction = redaction }
+}
+
+// NewJSONPrinter creates a JSONPrinter with the given options.
+func NewJSONPrinter(opts ...JSONOption) *JSONPrinter {
+	p := new(JSONPrinter)
+	for _, opt := range opts {
+		opt(&p.jsonOptions)
+	}
+	return p
+}
 
 func (p *JSONPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	out, err := json.Marshal(p.redaction.apply(newJSONResult(r)))
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+
+	p.mu.Lock()
+	fmt.Fprintln(p.writer(), string(out))
+	p.mu.Unlock()
+	return nil
+}
+
+// jsonIncomplete is the last line printed when the scan stopped before scanning all of the
+// content, which tells it apart from the results printed before it.
+type jsonIncomplete struct {
+	Incomplete bool   `json:"incomplete"`
+	Reason     string `json:"reason"`
+}
+
+// MarkIncomplete prints a line recording that the scan stopped before scanning all of the
+// content, for the reason given. It's printed after the results, as no more are printed once
+// the scan is marked incomplete.
+func (p *JSONPrinter) MarkIncomplete(reason string) {
+	out, err := json.Marshal(jsonIncomplete{Incomplete: true, Reason: reason})
+	if err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	fmt.Fprintln(p.writer(), string(out))
+	p.mu.Unlock()
+}
+
+func (p *JSONPrinter) writer() io.Writer {
+	if p.w == nil {
+		return os.Stdout
+	}
+	return p.w
+}
+
+// jsonResult is the JSON representation of a result.
+type jsonResult struct {
+	// SourceMetadata contains source-specific contextual information.
+	SourceMetadata *source_metadatapb.MetaData
+	// SourceID is the ID of the source that the API uses to map secrets to specific sources.
+	SourceID sources.SourceID
+	// SourceType is the type of Source.
+	SourceType sourcespb.SourceType
+	// SourceName is the name of the Source.
+	SourceName string
+	// DetectorType is the type of Detector.
+	DetectorType detectorspb.DetectorType
+	// DetectorName is the string name of the DetectorType.
+	DetectorName string
+	// DecoderName is the string name of the DecoderType.
+	DecoderName       string
+	Verified          bool
+	VerificationError string `json:",omitempty"`
+	// Raw contains the raw secret data.
+	Raw string
+	// RawV2 contains the raw secret identifier that is a combination of both the ID and the secret.
+	// This is used for secrets that are multi part and could have the same ID. Ex: AWS credentials
+	RawV2 string
+	// Redacted contains the redacted version of the raw secret identification data for display purposes.
+	// A secret ID should be used if available.
+	Redacted string
+	// Fingerprint identifies the secret across scans, for use in allowlists.
+	Fingerprint    string
+	ExtraData      map[string]string
+	StructuredData *detectorspb.StructuredData
+	// Locations lists every occurrence of the secret when results are deduplicated.
+	Locations []detectors.ResultLocation `json:",omitempty"`
+}
+
+func newJSONResult(r *detectors.ResultWithMetadata) *jsonResult {
 	verificationErr := func(err error) string {
 		if err != nil {
 			return err.Error()
@@ -24,34 +125,7 @@ func (p *JSONPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 		return ""
 	}(r.VerificationError())
 
-	v := &struct {
-		// SourceMetadata contains source-specific contextual information.
-		SourceMetadata *source_metadatapb.MetaData
-		// SourceID is the ID of the source that the API uses to map secrets to specific sources.
-		SourceID sources.SourceID
-		// SourceType is the type of Source.
-		SourceType sourcespb.SourceType
-		// SourceName is the name of the Source.
-		SourceName string
-		// DetectorType is the type of Detector.
-		DetectorType detectorspb.DetectorType
-		// DetectorName is the string name of the DetectorType.
-		DetectorName string
-		// DecoderName is the string name of the DecoderType.
-		DecoderName       string
-		Verified          bool
-		VerificationError string `json:",omitempty"`
-		// Raw contains the raw secret data.
-		Raw string
-		// RawV2 contains the raw secret identifier that is a combination of both the ID and the secret.
-		// This is used for secrets that are multi part and could have the same ID. Ex: AWS credentials
-		RawV2 string
-		// Redacted contains the redacted version of the raw secret identification data for display purposes.
-		// A secret ID should be used if available.
-		Redacted       string
-		ExtraData      map[string]string
-		StructuredData *detectorspb.StructuredData
-	}{
+	return &jsonResult{
 		SourceMetadata:    r.SourceMetadata,
 		SourceID:          r.SourceID,
 		SourceType:        r.SourceType,
@@ -64,16 +138,9 @@ func (p *JSONPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 		Raw:               string(r.Raw),
 		RawV2:             string(r.RawV2),
 		Redacted:          r.Redacted,
+		Fingerprint:       r.Fingerprint(),
 		ExtraData:         r.ExtraData,
 		StructuredData:    r.StructuredData,
+		Locations:         r.Locations,
 	}
-	out, err := json.Marshal(v)
-	if err != nil {
-		return fmt.Errorf("could not marshal result: %w", err)
-	}
-
-	p.mu.Lock()
-	fmt.Println(string(out))
-	p.mu.Unlock()
-	return nil
 }
@@ -0,0 +1,31 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+func TestJSONPrinterIncomplete(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewJSONPrinter()
+	p.w = &buf
+	result := &detectors.ResultWithMetadata{Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Raw: []byte("AKIAEXAMPLE")}}
+	assert.NoError(t, p.Print(context.Background(), result))
+	p.MarkIncomplete("maximum scan duration exceeded")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Contains(t, lines[0], `"Raw":"AKIAEXAMPLE"`)
+		var incomplete map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &incomplete))
+		assert.Equal(t, map[string]any{"incomplete": true, "reason": "maximum scan duration exceeded"}, incomplete)
+	}
+}
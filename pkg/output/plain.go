@@ -37,7 +37,7 @@ func (p *PlainPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 		Raw:               strings.TrimSpace(string(r.Result.Raw)),
 	}
 
-	meta, err := structToMap(out.MetaData.Data)
+	aggregateData, aggregateDataKeys, err := metadataFields(out.MetaData)
 	if err != nil {
 		return fmt.Errorf("could not marshal result: %w", err)
 	}
@@ -58,6 +58,7 @@ func (p *PlainPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 	printer.Printf("Detector Type: %s\n", out.DetectorType)
 	printer.Printf("Decoder Type: %s\n", out.DecoderType)
 	printer.Printf("Raw result: %s\n", whitePrinter.Sprint(out.Raw))
+	printer.Printf("Fingerprint: %s\n", r.Result.Fingerprint())
 
 	for k, v := range r.Result.ExtraData {
 		printer.Printf(
@@ -82,6 +83,36 @@ func (p *PlainPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 		}
 	}
 
+	for _, k := range aggregateDataKeys {
+		printer.Printf("%s: %v\n", cases.Title(language.AmericanEnglish).String(k), aggregateData[k])
+	}
+
+	// Deduplicated results also list the other locations of the secret.
+	if len(r.Locations) > 1 {
+		printer.Printf("Occurrences: %d\n", len(r.Locations))
+		for i, loc := range r.Locations[1:] {
+			data, keys, err := metadataFields(loc.SourceMetadata)
+			if err != nil {
+				return fmt.Errorf("could not marshal result: %w", err)
+			}
+			fields := make([]string, 0, len(keys))
+			for _, k := range keys {
+				fields = append(fields, fmt.Sprintf("%s: %v", cases.Title(language.AmericanEnglish).String(k), data[k]))
+			}
+			printer.Printf("Location %d: %s\n", i+2, strings.Join(fields, ", "))
+		}
+	}
+	fmt.Println("")
+	return nil
+}
+
+// metadataFields flattens source metadata into its fields and their sorted keys.
+func metadataFields(metadata *source_metadatapb.MetaData) (map[string]any, []string, error) {
+	meta, err := structToMap(metadata.GetData())
+	if err != nil {
+		return nil, nil, err
+	}
+
 	aggregateData := make(map[string]any)
 	var aggregateDataKeys []string
 
@@ -91,12 +122,14 @@ func (p *PlainPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 			aggregateData[k] = v
 		}
 	}
-	sort.Strings(aggregateDataKeys)
-	for _, k := range aggregateDataKeys {
-		printer.Printf("%s: %v\n", cases.Title(language.AmericanEnglish).String(k), aggregateData[k])
+	// Display the location of the result as path:line when the line is known.
+	if line, ok := aggregateData["line"].(float64); ok && line > 0 {
+		if file, ok := aggregateData["file"].(string); ok && file != "" {
+			aggregateData["file"] = fmt.Sprintf("%s:%d", file, int64(line))
+		}
 	}
-	fmt.Println("")
-	return nil
+	sort.Strings(aggregateDataKeys)
+	return aggregateData, aggregateDataKeys, nil
 }
 
 func structToMap(obj any) (m map[string]map[string]any, err error) {
@@ -0,0 +1,120 @@
+package output
+
+import (
+	"crypto"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redaction is a policy for writing the secret fields of results, Raw, RawV2 and Redacted,
+// in the JSON output, as well as the ExtraData entries holding credentials: passwords, tokens,
+// and URIs, which may embed them. The zero value writes them as is.
+type Redaction struct {
+	omit bool
+	hash crypto.Hash
+}
+
+// redactionHashes are the hash algorithms supported by ParseRedaction.
+var redactionHashes = map[string]crypto.Hash{
+	"sha256": crypto.SHA256,
+	"sha384": crypto.SHA384,
+	"sha512": crypto.SHA512,
+}
+
+// RedactionPolicies lists the names of the policies accepted by ParseRedaction.
+var RedactionPolicies = []string{"none", "omit", "sha256", "sha384", "sha512"}
+
+// ParseRedaction returns the redaction policy with the given name: "none" keeps the secret
+// fields, "omit" removes them, and the name of a hash algorithm replaces Raw and RawV2 by
+// their hash and removes Redacted, which may contain fragments of the secret.
+func ParseRedaction(name string) (Redaction, error) {
+	switch name = strings.ToLower(name); name {
+	case "", "none":
+		return Redaction{}, nil
+	case "omit":
+		return Redaction{omit: true}, nil
+	}
+	if h, ok := redactionHashes[name]; ok {
+		return Redaction{hash: h}, nil
+	}
+	return Redaction{}, fmt.Errorf("unknown redaction policy %q, expected one of %s", name, strings.Join(RedactionPolicies, ", "))
+}
+
+// redactedJSONResult is a jsonResult whose secret fields are shadowed by redacted ones, which
+// are omitted when empty.
+type redactedJSONResult struct {
+	*jsonResult
+	Raw       string `json:",omitempty"`
+	RawV2     string `json:",omitempty"`
+	Redacted  string `json:",omitempty"`
+	ExtraData map[string]string
+}
+
+// apply returns the value to marshal for r according to the policy.
+func (p Redaction) apply(r *jsonResult) any {
+	switch {
+	case p.omit:
+		return &redactedJSONResult{jsonResult: r, ExtraData: p.extraData(r.ExtraData)}
+	case p.hash != 0:
+		return &redactedJSONResult{
+			jsonResult: r,
+			Raw:        p.digest(r.Raw),
+			RawV2:      p.digest(r.RawV2),
+			ExtraData:  p.extraData(r.ExtraData),
+		}
+	default:
+		return r
+	}
+}
+
+// secretExtraDataKey matches the names of the ExtraData entries detectors put credentials in.
+var secretExtraDataKey = regexp.MustCompile(`(?i)(^|[_ ])(password|passwd|secret|token|token_value|private_key|uri|connection_string)$`)
+
+// isSecretExtraData reports whether an ExtraData entry holds a credential, because of its
+// name or because its value is a URL with a password.
+func isSecretExtraData(key, value string) bool {
+	if secretExtraDataKey.MatchString(key) {
+		return true
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return false
+	}
+	_, hasPassword := u.User.Password()
+	return hasPassword
+}
+
+// extraData returns a copy of extraData whose secret entries are removed or hashed. Other
+// entries, such as account names or permissions, are kept.
+func (p Redaction) extraData(extraData map[string]string) map[string]string {
+	if extraData == nil {
+		return nil
+	}
+	out := make(map[string]string, len(extraData))
+	for k, v := range extraData {
+		switch {
+		case !isSecretExtraData(k, v):
+			out[k] = v
+		case !p.omit:
+			out[k] = p.digest(v)
+		}
+	}
+	return out
+}
+
+// digest returns the hash of the secret, prefixed by the name of the algorithm so that hashes
+// of different algorithms can't be mistaken for each other. The hash is unsalted, so the same
+// secret has the same hash across scans.
+func (p Redaction) digest(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	h := p.hash.New()
+	h.Write([]byte(secret))
+	return strings.ToLower(strings.ReplaceAll(p.hash.String(), "-", "")) + ":" + hex.EncodeToString(h.Sum(nil))
+}
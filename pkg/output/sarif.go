@@ -0,0 +1,263 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFPrinter is a printer that collects results and prints them as a single SARIF 2.1.0 log
+// when Flush is called. It prints a valid log even if no results were found.
+type SARIFPrinter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	rules   map[string]sarifRule
+	results []sarifResult
+	// incomplete is the reason the scan didn't cover all of the content, if it didn't.
+	incomplete string
+}
+
+func (p *SARIFPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	// Deduplicated results carry every location of the secret, including the first.
+	metadata := []*source_metadatapb.MetaData{r.SourceMetadata}
+	if len(r.Locations) > 0 {
+		metadata = metadata[:0]
+		for _, loc := range r.Locations {
+			metadata = append(metadata, loc.SourceMetadata)
+		}
+	}
+	var locations []sarifLocation
+	for _, m := range metadata {
+		location, ok, err := sarifLocationOf(m)
+		if err != nil {
+			return fmt.Errorf("could not marshal result: %w", err)
+		}
+		if ok {
+			locations = append(locations, location)
+		}
+	}
+
+	ruleID := r.Result.DetectorType.String()
+	verifiedStatus, level := "unverified", "warning"
+	if r.Result.Verified {
+		verifiedStatus, level = "verified", "error"
+	}
+
+	message := fmt.Sprintf("Found %s %s result", verifiedStatus, ruleID)
+	if r.Result.DecoderType != detectorspb.DecoderType_PLAIN {
+		message = fmt.Sprintf("Found %s %s result with %s encoding", verifiedStatus, ruleID, r.Result.DecoderType)
+	}
+
+	result := sarifResult{
+		RuleID:  ruleID,
+		Level:   level,
+		Message: sarifMessage{Text: message},
+		PartialFingerprints: map[string]string{
+			"secretHash/v1": r.Result.Fingerprint(),
+		},
+		Properties: map[string]any{
+			"verified":    r.Result.Verified,
+			"decoderType": r.Result.DecoderType.String(),
+			"sourceName":  r.SourceName,
+		},
+	}
+	result.Locations = locations
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.rules == nil {
+		p.rules = make(map[string]sarifRule)
+	}
+	if _, ok := p.rules[ruleID]; !ok {
+		p.rules[ruleID] = sarifRule{
+			ID:               ruleID,
+			Name:             ruleID,
+			ShortDescription: sarifMessage{Text: fmt.Sprintf("%s secret", ruleID)},
+		}
+	}
+	p.results = append(p.results, result)
+	return nil
+}
+
+// sarifLocationOf returns the SARIF location of the file described by the
+// source metadata. It returns false if the metadata doesn't name a file.
+func sarifLocationOf(metadata *source_metadatapb.MetaData) (sarifLocation, bool, error) {
+	meta, err := structToMap(metadata.GetData())
+	if err != nil {
+		return sarifLocation{}, false, err
+	}
+
+	var (
+		file string
+		line int64
+	)
+	for _, data := range meta {
+		for k, v := range data {
+			switch k {
+			case "file", "filename":
+				if filename, ok := v.(string); ok && file == "" {
+					file = filename
+				}
+			case "line":
+				if l, ok := v.(float64); ok {
+					line = int64(l)
+				}
+			}
+		}
+	}
+	if file == "" {
+		return sarifLocation{}, false, nil
+	}
+
+	location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: file},
+	}}
+	if line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+	}
+	return location, true, nil
+}
+
+// MarkIncomplete records that the scan stopped before scanning all of the content, for the
+// reason given. The log then reports the run as unsuccessful.
+func (p *SARIFPrinter) MarkIncomplete(reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.incomplete = reason
+}
+
+// Flush prints the SARIF log containing all results printed so far.
+func (p *SARIFPrinter) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rules := make([]sarifRule, 0, len(p.rules))
+	for _, rule := range p.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	results := p.results
+	if results == nil {
+		results = []sarifResult{}
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "TruffleHog",
+				InformationURI: "https://github.com/trufflesecurity/trufflehog",
+				Version:        version.BuildVersion,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	if p.incomplete != "" {
+		log.Runs[0].Invocations = []sarifInvocation{{
+			ExecutionSuccessful: false,
+			ToolExecutionNotifications: []sarifNotification{{
+				Level:   "error",
+				Message: sarifMessage{Text: "The scan is incomplete: " + p.incomplete},
+			}},
+		}}
+	}
+	out, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal SARIF log: %w", err)
+	}
+
+	w := p.w
+	if w == nil {
+		w = os.Stdout
+	}
+	if _, err := fmt.Fprintln(w, string(out)); err != nil {
+		return fmt.Errorf("could not write SARIF log: %w", err)
+	}
+	return nil
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations,omitempty"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int64 `json:"startLine"`
+}
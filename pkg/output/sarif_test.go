@@ -0,0 +1,100 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func TestSARIFPrinter(t *testing.T) {
+	tests := map[string]struct {
+		results   []detectors.ResultWithMetadata
+		wantRules []string
+		wantLevel []string
+	}{
+		"no results": {},
+		"verified and unverified": {
+			results: []detectors.ResultWithMetadata{
+				{
+					SourceMetadata: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{
+						Git: &source_metadatapb.Git{File: "config.yaml", Line: 12},
+					}},
+					Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Verified: true, Redacted: "AKIAXXXX"},
+				},
+				{
+					SourceMetadata: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Filesystem{
+						Filesystem: &source_metadatapb.Filesystem{File: "/tmp/env"},
+					}},
+					Result: detectors.Result{DetectorType: detectorspb.DetectorType_Github, Raw: []byte("ghp_secret")},
+				},
+			},
+			wantRules: []string{"AWS", "Github"},
+			wantLevel: []string{"error", "warning"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := &SARIFPrinter{w: &buf}
+			for i := range tt.results {
+				assert.NoError(t, p.Print(context.Background(), &tt.results[i]))
+			}
+			assert.NoError(t, p.Flush())
+
+			var log sarifLog
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+			assert.Equal(t, sarifVersion, log.Version)
+			assert.Len(t, log.Runs, 1)
+			assert.NotContains(t, buf.String(), `"results": null`)
+			assert.NotContains(t, buf.String(), "ghp_secret")
+
+			run := log.Runs[0]
+			var rules []string
+			for _, rule := range run.Tool.Driver.Rules {
+				rules = append(rules, rule.ID)
+			}
+			assert.Equal(t, tt.wantRules, rules)
+
+			var levels []string
+			for _, result := range run.Results {
+				levels = append(levels, result.Level)
+				assert.NotEmpty(t, result.PartialFingerprints["secretHash/v1"])
+				assert.Len(t, result.Locations, 1)
+			}
+			assert.Equal(t, tt.wantLevel, levels)
+			if len(run.Results) > 0 {
+				region := run.Results[0].Locations[0].PhysicalLocation.Region
+				assert.Equal(t, int64(12), region.StartLine)
+				assert.Nil(t, run.Results[1].Locations[0].PhysicalLocation.Region)
+			}
+		})
+	}
+}
+
+func TestSARIFPrinterIncomplete(t *testing.T) {
+	var buf bytes.Buffer
+	p := &SARIFPrinter{w: &buf}
+	assert.NoError(t, p.Flush())
+	assert.NotContains(t, buf.String(), "invocations")
+
+	buf.Reset()
+	p.MarkIncomplete("maximum scan duration exceeded")
+	assert.NoError(t, p.Flush())
+
+	var log sarifLog
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	invocations := log.Runs[0].Invocations
+	if assert.Len(t, invocations, 1) {
+		assert.False(t, invocations[0].ExecutionSuccessful)
+		assert.Contains(t, invocations[0].ToolExecutionNotifications[0].Message.Text, "maximum scan duration exceeded")
+	}
+	assert.Contains(t, buf.String(), `"executionSuccessful": false`)
+}
@@ -342,6 +342,10 @@ func (m *VerifierConfig) validate(all bool) error {
 
 	// no validation rules for Unsafe
 
+	// no validation rules for Method
+
+	// no validation rules for Body
+
 	if len(errors) > 0 {
 		return VerifierConfigMultiError(errors)
 	}
@@ -635,6 +635,8 @@ func (m *Confluence) validate(all bool) error {
 
 	// no validation rules for File
 
+	// no validation rules for PageId
+
 	if len(errors) > 0 {
 		return ConfluenceMultiError(errors)
 	}
@@ -960,6 +962,30 @@ func (m *Filesystem) validate(all bool) error {
 
 	// no validation rules for Line
 
+	// no validation rules for Page
+
+	// no validation rules for KubernetesKind
+
+	// no validation rules for KubernetesNamespace
+
+	// no validation rules for KubernetesName
+
+	// no validation rules for KubernetesKey
+
+	// no validation rules for CredentialHost
+
+	// no validation rules for CredentialLogin
+
+	// no validation rules for EmailSubject
+
+	// no validation rules for EmailFrom
+
+	// no validation rules for EmailAttachment
+
+	// no validation rules for PackageName
+
+	// no validation rules for PackageFile
+
 	if len(errors) > 0 {
 		return FilesystemMultiError(errors)
 	}
@@ -1070,6 +1096,20 @@ func (m *Git) validate(all bool) error {
 
 	// no validation rules for Line
 
+	// no validation rules for ParentRepository
+
+	// no validation rules for SubmodulePath
+
+	// no validation rules for WorkingTree
+
+	// no validation rules for Staged
+
+	// no validation rules for NotesRef
+
+	// no validation rules for Stash
+
+	// no validation rules for StashMessage
+
 	if len(errors) > 0 {
 		return GitMultiError(errors)
 	}
@@ -1186,6 +1226,8 @@ func (m *Github) validate(all bool) error {
 
 	// no validation rules for Visibility
 
+	// no validation rules for PullRequest
+
 	if len(errors) > 0 {
 		return GithubMultiError(errors)
 	}
@@ -1304,6 +1346,10 @@ func (m *Gitlab) validate(all bool) error {
 
 	// no validation rules for ProjectOwner
 
+	// no validation rules for MergeRequestIid
+
+	// no validation rules for NoteId
+
 	if len(errors) > 0 {
 		return GitlabMultiError(errors)
 	}
@@ -1416,6 +1462,8 @@ func (m *GCS) validate(all bool) error {
 
 	// no validation rules for ContentType
 
+	// no validation rules for Generation
+
 	if len(errors) > 0 {
 		return GCSMultiError(errors)
 	}
@@ -1526,6 +1574,10 @@ func (m *Jira) validate(all bool) error {
 
 	// no validation rules for Timestamp
 
+	// no validation rules for Project
+
+	// no validation rules for CommentId
+
 	if len(errors) > 0 {
 		return JiraMultiError(errors)
 	}
@@ -1850,6 +1902,10 @@ func (m *S3) validate(all bool) error {
 
 	// no validation rules for Timestamp
 
+	// no validation rules for RoleArn
+
+	// no validation rules for VersionId
+
 	if len(errors) > 0 {
 		return S3MultiError(errors)
 	}
@@ -2253,6 +2309,106 @@ var _ interface {
 	ErrorName() string
 } = TestValidationError{}
 
+// Validate checks the field values on Stdin with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Stdin) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Stdin with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in StdinMultiError, or nil if none found.
+func (m *Stdin) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Stdin) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Line
+
+	if len(errors) > 0 {
+		return StdinMultiError(errors)
+	}
+
+	return nil
+}
+
+// StdinMultiError is an error wrapping multiple validation errors returned by
+// Stdin.ValidateAll() if the designated constraints aren't met.
+type StdinMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m StdinMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m StdinMultiError) AllErrors() []error { return m }
+
+// StdinValidationError is the validation error returned by Stdin.Validate if
+// the designated constraints aren't met.
+type StdinValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e StdinValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e StdinValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e StdinValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e StdinValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e StdinValidationError) ErrorName() string { return "StdinValidationError" }
+
+// Error satisfies the builtin error interface
+func (e StdinValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sStdin.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = StdinValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = StdinValidationError{}
+
 // Validate checks the field values on Jenkins with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
@@ -5074,6 +5230,47 @@ func (m *MetaData) validate(all bool) error {
 			}
 		}
 
+	case *MetaData_Stdin:
+		if v == nil {
+			err := MetaDataValidationError{
+				field:  "Data",
+				reason: "oneof value cannot be a typed-nil",
+			}
+			if !all {
+				return err
+			}
+			errors = append(errors, err)
+		}
+
+		if all {
+			switch v := interface{}(m.GetStdin()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Stdin",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Stdin",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetStdin()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Stdin",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
 	default:
 		_ = v // ensures v is used
 	}
@@ -1132,6 +1132,35 @@ func (m *Confluence) validate(all bool) error {
 
 	// no validation rules for SkipHistory
 
+	if all {
+		switch v := interface{}(m.GetModifiedSince()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ConfluenceValidationError{
+					field:  "ModifiedSince",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ConfluenceValidationError{
+					field:  "ModifiedSince",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetModifiedSince()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ConfluenceValidationError{
+				field:  "ModifiedSince",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	switch v := m.Credential.(type) {
 	case *Confluence_Unauthenticated:
 		if v == nil {
@@ -1687,6 +1716,14 @@ func (m *Filesystem) validate(all bool) error {
 
 	// no validation rules for ExcludePathsFile
 
+	// no validation rules for FollowSymlinks
+
+	// no validation rules for ConfineToRoot
+
+	// no validation rules for SkipBinaries
+
+	// no validation rules for BufferThreshold
+
 	if len(errors) > 0 {
 		return FilesystemMultiError(errors)
 	}
@@ -1789,6 +1826,8 @@ func (m *GCS) validate(all bool) error {
 
 	// no validation rules for MaxObjectSize
 
+	// no validation rules for Prefix
+
 	switch v := m.Credential.(type) {
 	case *GCS_JsonServiceAccount:
 		if v == nil {
@@ -2071,6 +2110,49 @@ func (m *Git) validate(all bool) error {
 
 	// no validation rules for SkipArchives
 
+	// no validation rules for IncludeSubmodules
+
+	// no validation rules for ScanCommitMessages
+
+	// no validation rules for FetchLfs
+
+	// no validation rules for MaxLfsObjectSize
+
+	// no validation rules for WorkingTreeOnly
+
+	// no validation rules for ScanNotes
+
+	if all {
+		switch v := interface{}(m.GetSinceDate()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "SinceDate",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "SinceDate",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSinceDate()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitValidationError{
+				field:  "SinceDate",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for ScanStashes
+
 	switch v := m.Credential.(type) {
 	case *Git_BasicAuth:
 		if v == nil {
@@ -2313,6 +2395,39 @@ func (m *GitLab) validate(all bool) error {
 
 	// no validation rules for SkipArchives
 
+	// no validation rules for ScanMergeRequests
+
+	// no validation rules for SkipArchived
+
+	if all {
+		switch v := interface{}(m.GetLastActivityAfter()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "LastActivityAfter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "LastActivityAfter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetLastActivityAfter()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitLabValidationError{
+				field:  "LastActivityAfter",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	switch v := m.Credential.(type) {
 	case *GitLab_Token:
 		if v == nil {
@@ -2542,6 +2657,12 @@ func (m *GitHub) validate(all bool) error {
 
 	// no validation rules for IncludeWikis
 
+	// no validation rules for IncrementalStateFile
+
+	// no validation rules for PullRequest
+
+	// no validation rules for ScanCommitMessages
+
 	switch v := m.Credential.(type) {
 	case *GitHub_GithubApp:
 		if v == nil {
@@ -2910,6 +3031,37 @@ func (m *JIRA) validate(all bool) error {
 
 	// no validation rules for InsecureSkipVerifyTls
 
+	// no validation rules for Jql
+
+	if all {
+		switch v := interface{}(m.GetUpdatedSince()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, JIRAValidationError{
+					field:  "UpdatedSince",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, JIRAValidationError{
+					field:  "UpdatedSince",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetUpdatedSince()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return JIRAValidationError{
+				field:  "UpdatedSince",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	switch v := m.Credential.(type) {
 	case *JIRA_BasicAuth:
 		if v == nil {
@@ -3446,6 +3598,10 @@ func (m *S3) validate(all bool) error {
 
 	// no validation rules for MaxObjectSize
 
+	// no validation rules for ExternalId
+
+	// no validation rules for ScanObjectVersions
+
 	switch v := m.Credential.(type) {
 	case *S3_AccessKey:
 		if v == nil {
@@ -3958,6 +4114,104 @@ var _ interface {
 	ErrorName() string
 } = TestValidationError{}
 
+// Validate checks the field values on Stdin with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Stdin) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Stdin with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in StdinMultiError, or nil if none found.
+func (m *Stdin) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Stdin) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if len(errors) > 0 {
+		return StdinMultiError(errors)
+	}
+
+	return nil
+}
+
+// StdinMultiError is an error wrapping multiple validation errors returned by
+// Stdin.ValidateAll() if the designated constraints aren't met.
+type StdinMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m StdinMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m StdinMultiError) AllErrors() []error { return m }
+
+// StdinValidationError is the validation error returned by Stdin.Validate if
+// the designated constraints aren't met.
+type StdinValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e StdinValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e StdinValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e StdinValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e StdinValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e StdinValidationError) ErrorName() string { return "StdinValidationError" }
+
+// Error satisfies the builtin error interface
+func (e StdinValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sStdin.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = StdinValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = StdinValidationError{}
+
 // Validate checks the field values on Buildkite with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
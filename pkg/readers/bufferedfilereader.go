@@ -20,11 +20,12 @@ type BufferedFileReader struct {
 
 // NewBufferedFileReader initializes a BufferedFileReader from an io.Reader by using
 // the BufferedFileWriter's functionality to read and store data, then setting up a io.ReadSeekCloser
-// for random access reading and seeking.
+// for random access reading and seeking. The options configure the BufferedFileWriter, such as the
+// threshold above which the data spills to disk instead of being held in memory.
 // Close should be called when the BufferedFileReader is no longer needed.
 // It returns the initialized BufferedFileReader and any error encountered during the process.
-func NewBufferedFileReader(r io.Reader) (*BufferedFileReader, error) {
-	writer, err := bufferedfilewriter.NewFromReader(r)
+func NewBufferedFileReader(r io.Reader, opts ...bufferedfilewriter.Option) (*BufferedFileReader, error) {
+	writer, err := bufferedfilewriter.NewFromReader(r, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("error creating BufferedFileReader: %w", err)
 	}
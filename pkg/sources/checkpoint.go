@@ -0,0 +1,265 @@
+package sources
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	checkpointVersion = 1
+	// defaultCheckpointInterval is the minimum time between checkpoint writes.
+	defaultCheckpointInterval = 10 * time.Second
+)
+
+// Checkpoint records which source units have been fully scanned, so an
+// interrupted scan can be resumed without rescanning them. Sources can also
+// record positions to resume partially scanned work from, such as the token
+// to resume listing a bucket from. The state is
+// periodically persisted to a JSON file and is only reused if it was written
+// with the same configuration hash. It is safe for concurrent use.
+type Checkpoint struct {
+	path       string
+	configHash string
+	interval   time.Duration
+
+	mu        sync.Mutex
+	completed map[string]struct{}
+	positions map[string]string
+	lastWrite time.Time
+	dirty     bool
+
+	// chunks maps the chunks being scanned to the tracker of the work they
+	// belong to.
+	chunks sync.Map
+}
+
+// checkpointFile is the on-disk representation of a Checkpoint.
+type checkpointFile struct {
+	Version        int               `json:"version"`
+	ConfigHash     string            `json:"config_hash"`
+	CompletedUnits []string          `json:"completed_units"`
+	Positions      map[string]string `json:"positions,omitempty"`
+}
+
+// LoadCheckpoint creates a Checkpoint persisted at path. If the file exists
+// and was written with the same configHash, its completed units are loaded.
+// A missing file or a file written with a different configuration results
+// in an empty checkpoint that will overwrite it.
+func LoadCheckpoint(ctx context.Context, path, configHash string) (*Checkpoint, error) {
+	cp := &Checkpoint{
+		path:       path,
+		configHash: configHash,
+		interval:   defaultCheckpointInterval,
+		completed:  make(map[string]struct{}),
+		positions:  make(map[string]string),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return cp, nil
+		}
+		return nil, fmt.Errorf("unable to read checkpoint file: %w", err)
+	}
+
+	var file checkpointFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("unable to parse checkpoint file %s: %w", path, err)
+	}
+	if file.Version != checkpointVersion || file.ConfigHash != configHash {
+		ctx.Logger().Info("configuration changed, ignoring checkpoint", "path", path)
+		return cp, nil
+	}
+
+	for _, key := range file.CompletedUnits {
+		cp.completed[key] = struct{}{}
+	}
+	for key, position := range file.Positions {
+		cp.positions[key] = position
+	}
+	ctx.Logger().Info("resuming from checkpoint", "path", path, "completed_units", len(cp.completed), "positions", len(cp.positions))
+	return cp, nil
+}
+
+// CheckpointKey returns the stable identifier used to record a unit of the
+// named source in a Checkpoint.
+func CheckpointKey(sourceName string, unit SourceUnit) string {
+	id, kind := unit.SourceUnitID()
+	return fmt.Sprintf("%s/%s/%s", sourceName, kind, id)
+}
+
+// Completed returns whether the unit identified by key has already been scanned.
+func (c *Checkpoint) Completed(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.completed[key]
+	return ok
+}
+
+// MarkCompleted records the unit identified by key as scanned. The checkpoint
+// file is rewritten if the last write is older than the checkpoint interval.
+func (c *Checkpoint) MarkCompleted(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.completed[key] = struct{}{}
+	delete(c.positions, key)
+	return c.changed()
+}
+
+// Position returns the position recorded for key with SetPosition, or an
+// empty string if there is none.
+func (c *Checkpoint) Position(key string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.positions[key]
+}
+
+// SetPosition records the position to resume the work identified by key
+// from. An empty position removes it. The checkpoint file is rewritten if the
+// last write is older than the checkpoint interval.
+func (c *Checkpoint) SetPosition(key, position string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if position == "" {
+		delete(c.positions, key)
+	} else {
+		c.positions[key] = position
+	}
+	return c.changed()
+}
+
+// changed marks the state as changed and writes it if the last write is
+// older than the checkpoint interval. The caller must hold the lock.
+func (c *Checkpoint) changed() error {
+	c.dirty = true
+	if time.Since(c.lastWrite) < c.interval {
+		return nil
+	}
+	return c.write()
+}
+
+// Flush writes any unpersisted state to the checkpoint file.
+func (c *Checkpoint) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+	return c.write()
+}
+
+// write persists the checkpoint by writing to a temporary file and renaming
+// it, so an interruption never leaves a partially written checkpoint behind.
+// The caller must hold the lock.
+func (c *Checkpoint) write() error {
+	file := checkpointFile{
+		Version:        checkpointVersion,
+		ConfigHash:     c.configHash,
+		CompletedUnits: make([]string, 0, len(c.completed)),
+		Positions:      c.positions,
+	}
+	for key := range c.completed {
+		file.CompletedUnits = append(file.CompletedUnits, key)
+	}
+	sort.Strings(file.CompletedUnits)
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("unable to marshal checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("unable to create checkpoint file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("unable to write checkpoint file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write checkpoint file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("unable to replace checkpoint file: %w", err)
+	}
+
+	c.lastWrite = time.Now()
+	c.dirty = false
+	return nil
+}
+
+// TrackChunk records that chunk is part of the work tracked by t, which holds
+// a reference for it until it is released by the consumer of the chunk. It
+// does nothing if either c or t is nil.
+func (c *Checkpoint) TrackChunk(chunk *Chunk, t *ChunkTracker) {
+	if c == nil || t == nil {
+		return
+	}
+	t.Add()
+	c.chunks.Store(chunk, t)
+}
+
+// ChunkTracker returns the tracker chunk was recorded with by TrackChunk, if
+// any, and stops tracking the chunk. The caller must release the chunk's
+// reference with Done once its results have been reported.
+func (c *Checkpoint) ChunkTracker(chunk *Chunk) *ChunkTracker {
+	if c == nil {
+		return nil
+	}
+	t, ok := c.chunks.LoadAndDelete(chunk)
+	if !ok {
+		return nil
+	}
+	return t.(*ChunkTracker)
+}
+
+// ChunkTracker counts the chunks of a unit, and the work derived from them,
+// that are still being processed, so the unit is only checkpointed once its
+// results have been reported. Its creator holds a reference until it has
+// handed off every chunk, and each chunk holds another one while it's being
+// scanned. A nil ChunkTracker tracks nothing.
+type ChunkTracker struct {
+	pending atomic.Int64
+	onDone  func()
+}
+
+// NewChunkTracker returns a ChunkTracker holding its creator's reference,
+// which calls onDone once every reference has been released.
+func NewChunkTracker(onDone func()) *ChunkTracker {
+	t := &ChunkTracker{onDone: onDone}
+	t.pending.Store(1)
+	return t
+}
+
+// Add takes a reference for work that is being processed.
+func (t *ChunkTracker) Add() {
+	if t == nil {
+		return
+	}
+	t.pending.Add(1)
+}
+
+// Done releases a reference.
+func (t *ChunkTracker) Done() {
+	if t == nil {
+		return
+	}
+	if t.pending.Add(-1) == 0 {
+		t.onDone()
+	}
+}
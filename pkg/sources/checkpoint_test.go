@@ -0,0 +1,82 @@
+package sources
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	cp, err := LoadCheckpoint(ctx, path, "hash")
+	assert.NoError(t, err)
+	assert.False(t, cp.Completed("a"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, cp.MarkCompleted(fmt.Sprintf("unit-%d", i)))
+		}(i)
+	}
+	wg.Wait()
+	assert.NoError(t, cp.Flush())
+
+	resumed, err := LoadCheckpoint(ctx, path, "hash")
+	assert.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		assert.True(t, resumed.Completed(fmt.Sprintf("unit-%d", i)))
+	}
+	assert.False(t, resumed.Completed("unit-100"))
+
+	// A different configuration invalidates the checkpoint.
+	changed, err := LoadCheckpoint(ctx, path, "other hash")
+	assert.NoError(t, err)
+	assert.False(t, changed.Completed("unit-0"))
+}
+
+func TestCheckpointPositions(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	cp, err := LoadCheckpoint(ctx, path, "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "", cp.Position("bucket-a"))
+	assert.NoError(t, cp.SetPosition("bucket-a", "token-1"))
+	assert.NoError(t, cp.SetPosition("bucket-a", "token-2"))
+	assert.NoError(t, cp.SetPosition("bucket-b", "token-1"))
+	assert.NoError(t, cp.SetPosition("bucket-c", "token-1"))
+	assert.NoError(t, cp.SetPosition("bucket-c", ""))
+	// Completing the work identified by a key removes its position.
+	assert.NoError(t, cp.MarkCompleted("bucket-b"))
+	assert.NoError(t, cp.Flush())
+
+	resumed, err := LoadCheckpoint(ctx, path, "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-2", resumed.Position("bucket-a"))
+	assert.Equal(t, "", resumed.Position("bucket-b"))
+	assert.True(t, resumed.Completed("bucket-b"))
+	assert.Equal(t, "", resumed.Position("bucket-c"))
+}
+
+func TestCheckpointInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	assert.NoError(t, os.WriteFile(path, []byte("not json"), 0644))
+
+	_, err := LoadCheckpoint(context.Background(), path, "hash")
+	assert.Error(t, err)
+}
+
+func TestCheckpointKey(t *testing.T) {
+	key := CheckpointKey("trufflehog - filesystem", CommonSourceUnit{ID: "/tmp/file"})
+	assert.Equal(t, "trufflehog - filesystem/unit//tmp/file", key)
+}
@@ -18,6 +18,30 @@ const (
 	TotalChunkSize = ChunkSize + PeekSize
 )
 
+type chunkOverlapKey struct{}
+
+// ChunkOverlapContext returns a copy of ctx with which chunk readers make
+// consecutive chunks overlap by size bytes, so that a secret crossing the
+// boundary between them is whole in the first one. It should be at least as
+// large as the longest secret that has to be found. Each chunk holds its
+// overlap on top of ChunkSize bytes, so larger overlaps use more memory per
+// chunk and scan more data twice. Sizes below 1 are ignored.
+func ChunkOverlapContext(ctx context.Context, size int) context.Context {
+	if size < 1 {
+		return ctx
+	}
+	return context.WithValue(ctx, chunkOverlapKey{}, size)
+}
+
+// ChunkOverlap returns the number of bytes consecutive chunks read with ctx
+// overlap by. It defaults to PeekSize.
+func ChunkOverlap(ctx context.Context) int {
+	if size, ok := ctx.Value(chunkOverlapKey{}).(int); ok {
+		return size
+	}
+	return PeekSize
+}
+
 // Chunker takes a chunk and splits it into chunks of ChunkSize.
 func Chunker(originalChunk *Chunk) chan *Chunk {
 	chunkChan := make(chan *Chunk, 1)
@@ -51,8 +75,8 @@ func Chunker(originalChunk *Chunk) chan *Chunk {
 
 type chunkReaderConfig struct {
 	chunkSize int
-	totalSize int
-	peekSize  int
+	// peekSize is taken from the context of each read if it isn't set.
+	peekSize int
 }
 
 // ConfigOption is a function that configures a chunker.
@@ -65,7 +89,9 @@ func WithChunkSize(size int) ConfigOption {
 	}
 }
 
-// WithPeekSize sets the peek size.
+// WithPeekSize sets the peek size, which is the number of bytes consecutive
+// chunks overlap by. It defaults to the ChunkOverlap of the context the
+// chunks are read with.
 func WithPeekSize(size int) ConfigOption {
 	return func(c *chunkReaderConfig) {
 		c.peekSize = size
@@ -105,15 +131,12 @@ func applyOptions(opts []ConfigOption) *chunkReaderConfig {
 	// Set defaults.
 	config := &chunkReaderConfig{
 		chunkSize: ChunkSize, // default
-		peekSize:  PeekSize,  // default
 	}
 
 	for _, opt := range opts {
 		opt(config)
 	}
 
-	config.totalSize = config.chunkSize + config.peekSize
-
 	return config
 }
 
@@ -125,7 +148,13 @@ func createReaderFn(config *chunkReaderConfig) ChunkReader {
 
 func readInChunks(ctx context.Context, reader io.Reader, config *chunkReaderConfig) <-chan ChunkResult {
 	const channelSize = 1
-	chunkReader := bufio.NewReaderSize(reader, config.chunkSize)
+	peekSize := config.peekSize
+	if peekSize == 0 {
+		peekSize = ChunkOverlap(ctx)
+	}
+	totalSize := config.chunkSize + peekSize
+	// The buffer must hold the whole peek for Peek to return it.
+	chunkReader := bufio.NewReaderSize(reader, max(config.chunkSize, peekSize))
 	chunkResultChan := make(chan ChunkResult, channelSize)
 
 	go func() {
@@ -133,11 +162,11 @@ func readInChunks(ctx context.Context, reader io.Reader, config *chunkReaderConf
 
 		for {
 			chunkRes := ChunkResult{}
-			chunkBytes := make([]byte, config.totalSize)
+			chunkBytes := make([]byte, totalSize)
 			chunkBytes = chunkBytes[:config.chunkSize]
 			n, err := io.ReadFull(chunkReader, chunkBytes)
 			if n > 0 {
-				peekData, _ := chunkReader.Peek(config.totalSize - n)
+				peekData, _ := chunkReader.Peek(totalSize - n)
 				chunkBytes = append(chunkBytes[:n], peekData...)
 				chunkRes.data = chunkBytes
 			}
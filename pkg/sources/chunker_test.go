@@ -152,6 +152,14 @@ func TestNewChunkedReader(t *testing.T) {
 			wantChunks: []string{strings.Repeat("a", 2048), strings.Repeat("a", 2048), strings.Repeat("a", 2048), strings.Repeat("a", 1024)},
 			wantErr:    false,
 		},
+		{
+			name:       "Larger peekSize than chunkSize",
+			input:      strings.Repeat("a", 2048),
+			chunkSize:  512,
+			peekSize:   1024,
+			wantChunks: []string{strings.Repeat("a", 1536), strings.Repeat("a", 1536), strings.Repeat("a", 1024), strings.Repeat("a", 512)},
+			wantErr:    false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -178,6 +186,27 @@ func TestNewChunkedReader(t *testing.T) {
 	}
 }
 
+func TestChunkOverlapContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, PeekSize, ChunkOverlap(ctx))
+	assert.Equal(t, PeekSize, ChunkOverlap(ChunkOverlapContext(ctx, 0)))
+
+	// The overlap of the context is used unless a peek size is set.
+	ctx = ChunkOverlapContext(ctx, 1024)
+	input := strings.Repeat("a", 2048)
+	var chunks []int
+	for data := range NewChunkReader(WithChunkSize(512))(ctx, strings.NewReader(input)) {
+		chunks = append(chunks, len(data.Bytes()))
+	}
+	assert.Equal(t, []int{1536, 1536, 1024, 512}, chunks)
+
+	chunks = nil
+	for data := range NewChunkReader(WithChunkSize(512), WithPeekSize(256))(ctx, strings.NewReader(input)) {
+		chunks = append(chunks, len(data.Bytes()))
+	}
+	assert.Equal(t, []int{768, 768, 768, 512}, chunks)
+}
+
 func BenchmarkChunkReader(b *testing.B) {
 	var bigChunk = make([]byte, 1<<24) // 16MB
 
@@ -0,0 +1,225 @@
+package confluence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	// pageSize is the number of spaces, pages or attachments requested per page.
+	pageSize = 50
+	// pageExpand are the fields of pages expanded in search results.
+	pageExpand = "body.storage,version,space"
+)
+
+// errNotFound is returned for requests the API responded to with a 404.
+var errNotFound = errors.New("not found")
+
+// client is a client of the Confluence REST API, which is supported by both
+// Confluence Cloud and Confluence Data Center.
+type client struct {
+	// baseURL is the URL of the Confluence instance, including its context
+	// path, e.g. /wiki on Confluence Cloud.
+	baseURL *url.URL
+	http    *http.Client
+	// auth sets the credentials of a request.
+	auth func(*http.Request)
+}
+
+type space struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type user struct {
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+}
+
+type version struct {
+	Number int    `json:"number"`
+	When   string `json:"when"`
+	By     user   `json:"by"`
+}
+
+type links struct {
+	WebUI    string `json:"webui"`
+	Download string `json:"download"`
+	Next     string `json:"next"`
+}
+
+// content is a page or an attachment.
+type content struct {
+	ID      string  `json:"id"`
+	Type    string  `json:"type"`
+	Title   string  `json:"title"`
+	Space   space   `json:"space"`
+	Version version `json:"version"`
+	Body    struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Links links `json:"_links"`
+}
+
+// modified returns the time the content was last modified.
+func (c content) modified() (time.Time, error) {
+	return time.Parse(time.RFC3339, c.Version.When)
+}
+
+// resultPage is a page of results. Pages are linked to the next one, which
+// is the only way to paginate search results on Confluence Cloud.
+type resultPage[T any] struct {
+	Results []T   `json:"results"`
+	Links   links `json:"_links"`
+}
+
+// url returns the URL of the endpoint at the given path, relative to the
+// context path of the instance.
+func (c *client) url(endpoint string, query url.Values) string {
+	u := *c.baseURL
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// do sends a GET request to the endpoint at the given path and returns the
+// response if its status is 200.
+func (c *client) do(ctx context.Context, endpoint string, query url.Values, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(endpoint, query), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	c.auth(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", endpoint, errNotFound)
+	}
+	return nil, fmt.Errorf("%s: unexpected status code: %d", endpoint, resp.StatusCode)
+}
+
+// get sends a GET request to the endpoint at the given path and decodes the
+// JSON response into v.
+func (c *client) get(ctx context.Context, endpoint string, query url.Values, v any) error {
+	resp, err := c.do(ctx, endpoint, query, "application/json")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%s: error decoding response: %w", endpoint, err)
+	}
+	return nil
+}
+
+// paginate calls visit with every result of the endpoint, following the
+// links from each page to the next.
+func paginate[T any](ctx context.Context, c *client, endpoint string, query url.Values, visit func(T) error) error {
+	query.Set("limit", strconv.Itoa(pageSize))
+	for {
+		var page resultPage[T]
+		if err := c.get(ctx, endpoint, query, &page); err != nil {
+			return err
+		}
+		for _, result := range page.Results {
+			if err := visit(result); err != nil {
+				return err
+			}
+		}
+
+		if page.Links.Next == "" || len(page.Results) == 0 {
+			return nil
+		}
+		// The link is relative to the context path of the instance. Some
+		// versions leave parameters such as expand out of it.
+		next, err := url.Parse(page.Links.Next)
+		if err != nil {
+			return fmt.Errorf("%s: invalid next page link: %w", endpoint, err)
+		}
+		nextQuery := next.Query()
+		for key, values := range query {
+			if !nextQuery.Has(key) {
+				nextQuery[key] = values
+			}
+		}
+		endpoint, query = next.Path, nextQuery
+	}
+}
+
+// spaces returns every space visible to the user. The type is either empty,
+// global or personal.
+func (c *client) spaces(ctx context.Context, spaceType string) ([]space, error) {
+	query := url.Values{}
+	if spaceType != "" {
+		query.Set("type", spaceType)
+	}
+	var spaces []space
+	err := paginate(ctx, c, "rest/api/space", query, func(s space) error {
+		spaces = append(spaces, s)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing spaces: %w", err)
+	}
+	return spaces, nil
+}
+
+// searchPages calls visit with every page matching the CQL query, with its
+// body in storage format.
+func (c *client) searchPages(ctx context.Context, cql string, visit func(content) error) error {
+	query := url.Values{
+		"cql":    {cql},
+		"expand": {pageExpand},
+	}
+	if err := paginate(ctx, c, "rest/api/content/search", query, visit); err != nil {
+		return fmt.Errorf("error searching pages: %w", err)
+	}
+	return nil
+}
+
+// attachments calls visit with every attachment of a page.
+func (c *client) attachments(ctx context.Context, pageID string, visit func(content) error) error {
+	query := url.Values{"expand": {"version"}}
+	if err := paginate(ctx, c, path.Join("rest/api/content", pageID, "child/attachment"), query, visit); err != nil {
+		return fmt.Errorf("error listing attachments: %w", err)
+	}
+	return nil
+}
+
+// download returns the content of an attachment. The caller must close it.
+func (c *client) download(ctx context.Context, attachment content) (io.ReadCloser, error) {
+	link, err := url.Parse(attachment.Links.Download)
+	if err != nil || link.Path == "" {
+		return nil, fmt.Errorf("attachment %s has no download link", attachment.ID)
+	}
+	resp, err := c.do(ctx, link.Path, link.Query(), "*/*")
+	if err != nil {
+		return nil, fmt.Errorf("error downloading attachment %s: %w", attachment.ID, err)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,328 @@
+package confluence
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/roundtripper"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	SourceType = sourcespb.SourceType_SOURCE_TYPE_CONFLUENCE
+
+	spaceUnitKind sources.SourceUnitKind = "space"
+
+	// cqlTimeLayout is the layout of dates in CQL queries.
+	cqlTimeLayout = "2006/01/02 15:04"
+	// cqlTimezoneMargin is subtracted from the time pages are searched from,
+	// as CQL dates are in the timezone of the user rather than in UTC. Pages
+	// modified before the requested time are skipped after the search.
+	cqlTimezoneMargin = 24 * time.Hour
+)
+
+type Source struct {
+	name     string
+	sourceId sources.SourceID
+	jobId    sources.JobID
+	verify   bool
+	client   *client
+	// spaceType restricts the spaces listed when no space is configured. It
+	// is either empty, global or personal.
+	spaceType string
+	// spaces are the keys of the spaces to scan. Every visible space of
+	// spaceType is scanned if it is empty.
+	spaces       []string
+	ignoreSpaces map[string]struct{}
+	// includeAttachments scans the attachments of pages too.
+	includeAttachments bool
+	// modifiedSince skips pages that weren't modified since this time, if set.
+	modifiedSince time.Time
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.SourceUnitEnumChunker = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return SourceType
+}
+
+func (s *Source) SourceID() sources.SourceID {
+	return s.sourceId
+}
+
+func (s *Source) JobID() sources.JobID {
+	return s.jobId
+}
+
+// Init returns an initialized Confluence source.
+func (s *Source) Init(ctx context.Context, name string, jobId sources.JobID, sourceId sources.SourceID, verify bool, connection *anypb.Any, _ int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+
+	var conn sourcespb.Confluence
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return fmt.Errorf("error unmarshalling connection: %w", err)
+	}
+
+	baseURL, err := url.Parse(conn.GetEndpoint())
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return fmt.Errorf("invalid endpoint URL given for Confluence source: %q", conn.GetEndpoint())
+	}
+
+	var auth func(*http.Request)
+	switch cred := conn.GetCredential().(type) {
+	case *sourcespb.Confluence_BasicAuth:
+		username, password := cred.BasicAuth.GetUsername(), cred.BasicAuth.GetPassword()
+		if username == "" || password == "" {
+			return errors.New("Confluence basic auth credential requires a username and a password")
+		}
+		auth = func(req *http.Request) { req.SetBasicAuth(username, password) }
+	case *sourcespb.Confluence_Token:
+		if cred.Token == "" {
+			return errors.New("Confluence token is empty")
+		}
+		auth = func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+cred.Token) }
+	case *sourcespb.Confluence_Unauthenticated:
+		auth = func(*http.Request) {}
+	default:
+		return errors.New("credential type not implemented for Confluence")
+	}
+
+	// Requests rejected by the rate limit are retried after the delay given
+	// in their Retry-After header.
+	opts := []func(*roundtripper.RoundTripper){
+		roundtripper.WithLogger(ctx.Logger()),
+		roundtripper.WithRetryable(),
+	}
+	if conn.GetInsecureSkipVerifyTls() {
+		opts = append(opts, roundtripper.WithInsecureTLS())
+	}
+	s.client = &client{
+		baseURL: baseURL,
+		http:    &http.Client{Transport: roundtripper.NewRoundTripper(nil, opts...)},
+		auth:    auth,
+	}
+
+	switch conn.GetSpacesScope() {
+	case sourcespb.Confluence_GLOBAL:
+		s.spaceType = "global"
+	case sourcespb.Confluence_PERSONAL:
+		s.spaceType = "personal"
+	}
+	s.spaces = conn.GetSpaces()
+	s.ignoreSpaces = make(map[string]struct{}, len(conn.GetIgnoreSpaces()))
+	for _, key := range conn.GetIgnoreSpaces() {
+		s.ignoreSpaces[strings.ToUpper(key)] = struct{}{}
+	}
+	s.includeAttachments = conn.GetIncludeAttachments()
+	if since := conn.GetModifiedSince(); since != nil {
+		s.modifiedSince = since.AsTime()
+	}
+
+	return nil
+}
+
+// spaceKeys returns the keys of the spaces to scan.
+func (s *Source) spaceKeys(ctx context.Context) ([]string, error) {
+	keys := s.spaces
+	if len(keys) == 0 {
+		spaces, err := s.client.spaces(ctx, s.spaceType)
+		if err != nil {
+			return nil, err
+		}
+		for _, sp := range spaces {
+			keys = append(keys, sp.Key)
+		}
+	}
+
+	filtered := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := s.ignoreSpaces[strings.ToUpper(key)]; !ok {
+			filtered = append(filtered, key)
+		}
+	}
+	return filtered, nil
+}
+
+// Enumerate implements SourceUnitEnumerator interface. Each space is a unit.
+func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) error {
+	keys, err := s.spaceKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := reporter.UnitOk(ctx, sources.CommonSourceUnit{ID: key, Kind: spaceUnitKind}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChunkUnit implements SourceUnitChunker interface.
+func (s *Source) ChunkUnit(ctx context.Context, unit sources.SourceUnit, reporter sources.ChunkReporter) error {
+	key, _ := unit.SourceUnitID()
+	return s.scanSpace(ctx, key, reporter)
+}
+
+// Chunks emits chunks of bytes over a channel.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ ...sources.ChunkingTarget) error {
+	keys, err := s.spaceKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	reporter := sources.ChanReporter{Ch: chunksChan}
+	for i, key := range keys {
+		if common.IsDone(ctx) {
+			return nil
+		}
+		s.SetProgressComplete(i, len(keys), fmt.Sprintf("Space: %s", key), "")
+
+		if err := s.scanSpace(ctx, key, reporter); err != nil {
+			ctx.Logger().Error(err, "error scanning space", "space", key)
+		}
+	}
+
+	s.SetProgressComplete(len(keys), len(keys), fmt.Sprintf("Done scanning source %s", s.name), "")
+	return nil
+}
+
+// scanSpace reports the chunks of every page of a space modified since the
+// configured time.
+func (s *Source) scanSpace(ctx context.Context, key string, reporter sources.ChunkReporter) error {
+	ctx = context.WithValues(ctx, "space", key)
+	ctx.Logger().V(2).Info("scanning space")
+
+	err := s.client.searchPages(ctx, pagesCQL(key, s.modifiedSince), func(page content) error {
+		if !s.modifiedSince.IsZero() {
+			modified, err := page.modified()
+			if err == nil && modified.Before(s.modifiedSince) {
+				return nil
+			}
+		}
+		return s.scanPage(ctx, key, page, reporter)
+	})
+	if err != nil {
+		return reporter.ChunkErr(ctx, err)
+	}
+	return nil
+}
+
+// scanPage reports the body of a page and, if configured, its attachments.
+func (s *Source) scanPage(ctx context.Context, spaceKey string, page content, reporter sources.ChunkReporter) error {
+	metadata := &source_metadatapb.Confluence{
+		Page:      page.Title,
+		PageId:    page.ID,
+		Space:     spaceKey,
+		Version:   strconv.Itoa(page.Version.Number),
+		Link:      s.link(page.Links.WebUI),
+		Email:     page.Version.By.Email,
+		Timestamp: page.Version.When,
+		Location:  "body",
+	}
+	if body := stripMarkup(page.Body.Storage.Value); strings.TrimSpace(body) != "" {
+		if err := reporter.ChunkOk(ctx, s.chunk([]byte(body), metadata)); err != nil {
+			return err
+		}
+	}
+
+	if !s.includeAttachments {
+		return nil
+	}
+	err := s.client.attachments(ctx, page.ID, func(attachment content) error {
+		m := proto.Clone(metadata).(*source_metadatapb.Confluence)
+		m.Location = "attachment"
+		m.File = attachment.Title
+		m.Version = strconv.Itoa(attachment.Version.Number)
+		m.Email = attachment.Version.By.Email
+		m.Timestamp = attachment.Version.When
+		m.Link = s.link(attachment.Links.Download)
+		if err := s.scanAttachment(ctx, attachment, m, reporter); err != nil {
+			return reporter.ChunkErr(ctx, fmt.Errorf("page %s: %w", page.ID, err))
+		}
+		return nil
+	})
+	if err != nil {
+		return reporter.ChunkErr(ctx, fmt.Errorf("page %s: %w", page.ID, err))
+	}
+	return nil
+}
+
+// scanAttachment downloads an attachment and reports its chunks.
+func (s *Source) scanAttachment(ctx context.Context, attachment content, metadata *source_metadatapb.Confluence, reporter sources.ChunkReporter) error {
+	ctx = context.WithValues(ctx, "attachment", attachment.Title)
+	if common.SkipFile(attachment.Title) {
+		ctx.Logger().V(5).Info("skipping attachment")
+		return nil
+	}
+
+	data, err := s.client.download(ctx, attachment)
+	if err != nil {
+		return err
+	}
+	chunkSkel := s.chunk(nil, metadata)
+	return handlers.HandleFile(ctx, data, &chunkSkel, reporter, handlers.WithFileName(attachment.Title))
+}
+
+// chunk returns a chunk of data with the given metadata.
+func (s *Source) chunk(data []byte, metadata *source_metadatapb.Confluence) sources.Chunk {
+	return sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		JobID:      s.JobID(),
+		Data:       data,
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Confluence{Confluence: metadata},
+		},
+		Verify: s.verify,
+	}
+}
+
+// link returns the absolute URL of a link relative to the context path of
+// the instance.
+func (s *Source) link(relative string) string {
+	if relative == "" {
+		return ""
+	}
+	return strings.TrimSuffix(s.client.baseURL.String(), "/") + relative
+}
+
+// pagesCQL returns the CQL query of the pages of a space modified since the
+// given time, if it isn't zero.
+func pagesCQL(spaceKey string, modifiedSince time.Time) string {
+	clauses := []string{"space = " + quoteCQL(spaceKey), "type = page"}
+	if !modifiedSince.IsZero() {
+		since := modifiedSince.Add(-cqlTimezoneMargin).UTC().Format(cqlTimeLayout)
+		clauses = append(clauses, "lastmodified >= "+quoteCQL(since))
+	}
+	return strings.Join(clauses, " AND ") + " ORDER BY created ASC"
+}
+
+// quoteCQL returns s as a quoted CQL string.
+func quoteCQL(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
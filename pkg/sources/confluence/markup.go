@@ -0,0 +1,89 @@
+package confluence
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+const (
+	cdataStart = "<![CDATA["
+	cdataEnd   = "]]>"
+)
+
+// blockTags are the elements of the storage format whose content is
+// separated from the surrounding text. Other tags, like those changing the
+// style of text, are removed without a separator, so they don't split the
+// text they wrap.
+var blockTags = map[string]struct{}{
+	"p": {}, "div": {}, "br": {}, "hr": {}, "pre": {}, "blockquote": {},
+	"h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"ul": {}, "ol": {}, "li": {}, "table": {}, "tr": {}, "td": {}, "th": {},
+	"ac:structured-macro": {}, "ac:parameter": {}, "ac:plain-text-body": {},
+	"ac:rich-text-body": {}, "ac:task": {}, "ac:task-body": {},
+}
+
+// linkAttrPat matches the attributes of a tag holding a URL, which may
+// embed credentials.
+var linkAttrPat = regexp.MustCompile(`(?i)\b(?:href|src|ri:value)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// stripMarkup returns the text of a page body in storage format, which is
+// XHTML with Confluence specific elements. HTML entities are decoded, so that
+// they don't hide secrets, and the content of CDATA sections, which holds the
+// text of code blocks, is kept as is. Links are kept next to their text.
+func stripMarkup(body string) string {
+	var text strings.Builder
+	text.Grow(len(body))
+	for len(body) > 0 {
+		switch {
+		case strings.HasPrefix(body, cdataStart):
+			body = body[len(cdataStart):]
+			end := strings.Index(body, cdataEnd)
+			if end < 0 {
+				end = len(body)
+			}
+			text.WriteString(body[:end])
+			body = body[min(end+len(cdataEnd), len(body)):]
+		case strings.HasPrefix(body, "<!--"):
+			end := strings.Index(body, "-->")
+			if end < 0 {
+				// Only terminated comments are removed, so that an
+				// unclosed one doesn't hide the rest of the body.
+				body = body[len("<!--"):]
+				continue
+			}
+			body = body[end+len("-->"):]
+		case body[0] == '<':
+			end := strings.IndexByte(body, '>')
+			if end < 0 {
+				text.WriteString(html.UnescapeString(body))
+				return text.String()
+			}
+			writeTag(&text, body[1:end])
+			body = body[end+1:]
+		default:
+			end := strings.IndexByte(body, '<')
+			if end < 0 {
+				end = len(body)
+			}
+			text.WriteString(html.UnescapeString(body[:end]))
+			body = body[end:]
+		}
+	}
+	return text.String()
+}
+
+// writeTag writes the separator and the links of a tag to text.
+func writeTag(text *strings.Builder, tag string) {
+	for _, match := range linkAttrPat.FindAllStringSubmatch(tag, -1) {
+		text.WriteString(" " + html.UnescapeString(match[1]+match[2]) + " ")
+	}
+
+	name := strings.TrimPrefix(tag, "/")
+	if i := strings.IndexAny(name, " \t\r\n/"); i >= 0 {
+		name = name[:i]
+	}
+	if _, ok := blockTags[strings.ToLower(name)]; ok {
+		text.WriteByte('\n')
+	}
+}
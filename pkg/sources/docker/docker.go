@@ -141,11 +141,17 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 				return nil
 			}
 
-			for _, layer := range layers {
-				if err := s.processLayer(ctx, layer, imgInfo, chunksChan); err != nil {
+			// Layers are scanned from the top of the image down, so the files
+			// deleted by a layer are known before the layers that added them
+			// are scanned.
+			removed := newWhiteouts()
+			for i := len(layers) - 1; i >= 0; i-- {
+				layerWhiteouts, err := s.processLayer(ctx, layers[i], imgInfo, removed, chunksChan)
+				if err != nil {
 					scanErrs.Add(err)
 					return nil
 				}
+				removed.merge(layerWhiteouts)
 				dockerLayersScanned.WithLabelValues(s.name).Inc()
 			}
 
@@ -286,8 +292,10 @@ func (s *Source) processHistoryEntry(ctx context.Context, historyInfo historyEnt
 	return common.CancellableWrite(ctx, chunksChan, chunk)
 }
 
-// processLayer processes an individual layer of an image.
-func (s *Source) processLayer(ctx context.Context, layer v1.Layer, imgInfo imageInfo, chunksChan chan *sources.Chunk) error {
+// processLayer processes an individual layer of an image. Files removed by
+// the whiteouts of the layers above it are skipped. It returns the whiteouts
+// of the layer.
+func (s *Source) processLayer(ctx context.Context, layer v1.Layer, imgInfo imageInfo, removed *whiteouts, chunksChan chan *sources.Chunk) (*whiteouts, error) {
 	layerInfo := layerInfo{
 		base: imgInfo.base,
 		tag:  imgInfo.tag,
@@ -296,23 +304,24 @@ func (s *Source) processLayer(ctx context.Context, layer v1.Layer, imgInfo image
 	var err error
 	layerInfo.digest, err = layer.Digest()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ctx.Logger().WithValues("layer", layerInfo.digest.String()).V(2).Info("scanning layer")
 
 	rc, err := layer.Compressed()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer rc.Close()
 
 	gzipReader, err := gzip.NewReader(rc)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer gzipReader.Close()
 
+	layerWhiteouts := newWhiteouts()
 	tarReader := tar.NewReader(gzipReader)
 	for {
 		header, err := tarReader.Next()
@@ -320,16 +329,24 @@ func (s *Source) processLayer(ctx context.Context, layer v1.Layer, imgInfo image
 			break
 		}
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		if layerWhiteouts.add(header.Name) {
+			continue
+		}
+		if removed.removes(header.Name) {
+			ctx.Logger().V(4).Info("skipping file removed by a later layer", "file", header.Name)
+			continue
 		}
 
 		info := chunkProcessingInfo{size: header.Size, name: header.Name, reader: tarReader, layer: layerInfo}
 		if err := s.processChunk(ctx, info, chunksChan); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	return nil
+	return layerWhiteouts, nil
 }
 
 type chunkProcessingInfo struct {
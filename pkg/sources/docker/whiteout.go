@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"path"
+	"strings"
+)
+
+const (
+	// whiteoutPrefix marks a file that deletes the file of the same name,
+	// without the prefix, from the layers below it.
+	// See https://github.com/opencontainers/image-spec/blob/main/layer.md#whiteouts
+	whiteoutPrefix = ".wh."
+	// whiteoutOpaque marks a directory whose contents in the layers below it
+	// are deleted.
+	whiteoutOpaque = whiteoutPrefix + whiteoutPrefix + ".opq"
+)
+
+// whiteouts is a set of paths deleted by the whiteout files of image layers.
+type whiteouts struct {
+	// deleted holds paths deleted along with anything beneath them.
+	deleted map[string]struct{}
+	// opaque holds directories whose contents are deleted.
+	opaque map[string]struct{}
+}
+
+func newWhiteouts() *whiteouts {
+	return &whiteouts{deleted: make(map[string]struct{}), opaque: make(map[string]struct{})}
+}
+
+// add records the deletion of the whiteout file name, and reports whether
+// name is a whiteout file.
+func (w *whiteouts) add(name string) bool {
+	dir, base := path.Split(cleanLayerPath(name))
+	if !strings.HasPrefix(base, whiteoutPrefix) {
+		return false
+	}
+	if base == whiteoutOpaque {
+		w.opaque[path.Clean(dir)] = struct{}{}
+	} else {
+		w.deleted[path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix))] = struct{}{}
+	}
+	return true
+}
+
+// removes reports whether the file name is deleted by the whiteouts.
+func (w *whiteouts) removes(name string) bool {
+	p := cleanLayerPath(name)
+	if _, ok := w.deleted[p]; ok {
+		return true
+	}
+	for p != "/" {
+		p = path.Dir(p)
+		if _, ok := w.deleted[p]; ok {
+			return true
+		}
+		if _, ok := w.opaque[p]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// merge adds the whiteouts of other to w.
+func (w *whiteouts) merge(other *whiteouts) {
+	for p := range other.deleted {
+		w.deleted[p] = struct{}{}
+	}
+	for p := range other.opaque {
+		w.opaque[p] = struct{}{}
+	}
+}
+
+// cleanLayerPath returns the absolute path of a file name in a layer tarball,
+// where names may be relative and directories may have a trailing slash.
+func cleanLayerPath(name string) string { return path.Clean("/" + name) }
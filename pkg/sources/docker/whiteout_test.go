@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestWhiteouts(t *testing.T) {
+	w := newWhiteouts()
+	assert.False(t, w.add("etc/passwd"))
+	assert.True(t, w.add("etc/.wh.secret"))
+	assert.True(t, w.add("./app/.wh..wh..opq"))
+	assert.True(t, w.add(".wh.tmp"))
+
+	assert.True(t, w.removes("etc/secret"))
+	assert.True(t, w.removes("/etc/secret"))
+	assert.False(t, w.removes("etc/secret2"))
+	assert.False(t, w.removes("etc/passwd"))
+	assert.True(t, w.removes("app/config/key.pem"))
+	assert.False(t, w.removes("app/"))
+	assert.True(t, w.removes("tmp/cache/file"))
+	assert.False(t, w.removes("tmpfile"))
+
+	other := newWhiteouts()
+	assert.False(t, other.removes("etc/secret"))
+	other.merge(w)
+	assert.True(t, other.removes("etc/secret"))
+	assert.True(t, other.removes("app/key"))
+}
+
+func testLayer(t *testing.T, files map[string]string) v1.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	layer, err := tarball.LayerFromReader(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	return layer
+}
+
+func TestDockerImageScan_Whiteouts(t *testing.T) {
+	img, err := mutate.AppendLayers(empty.Image,
+		testLayer(t, map[string]string{
+			"app/secret.txt":  "deleted secret",
+			"app/keep.txt":    "kept",
+			"cache/token.txt": "cached token",
+		}),
+		testLayer(t, map[string]string{
+			"app/.wh.secret.txt":  "",
+			"cache/.wh..wh..opq":  "",
+			"cache/new-token.txt": "new token",
+		}),
+	)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "image.tar")
+	ref, err := name.NewTag("trufflehog/whiteouts:latest")
+	assert.NoError(t, err)
+	assert.NoError(t, tarball.WriteToFile(path, ref, img))
+
+	conn := &anypb.Any{}
+	assert.NoError(t, conn.MarshalFrom(&sourcespb.Docker{
+		Credential: &sourcespb.Docker_Unauthenticated{Unauthenticated: &credentialspb.Unauthenticated{}},
+		Images:     []string{"file://" + path},
+	}))
+	s := &Source{}
+	assert.NoError(t, s.Init(context.TODO(), "test source", 0, 0, false, conn, 1))
+
+	chunksChan := make(chan *sources.Chunk, 10)
+	assert.NoError(t, s.Chunks(context.TODO(), chunksChan))
+	close(chunksChan)
+
+	files := make(map[string]string)
+	for chunk := range chunksChan {
+		if isHistoryChunk(t, chunk) {
+			continue
+		}
+		files[chunk.SourceMetadata.GetDocker().GetFile()] = string(chunk.Data)
+	}
+	assert.Equal(t, map[string]string{
+		"/app/keep.txt":        "kept",
+		"/cache/new-token.txt": "new token",
+	}, files)
+}
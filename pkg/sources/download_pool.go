@@ -0,0 +1,53 @@
+package sources
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// DownloadPool downloads and scans the objects of a bucket on a bounded
+// number of workers. Each worker reports every chunk of the object it
+// downloads, so the chunks of an object keep its metadata and are reported
+// in order. Submitting an object blocks while every worker is busy, so
+// objects are listed no faster than they are downloaded and at most one
+// object per worker is held in memory.
+type DownloadPool struct {
+	workers chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewDownloadPool returns a pool of concurrency workers. If concurrency isn't
+// positive, the pool has one worker per CPU.
+func NewDownloadPool(concurrency int) *DownloadPool {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	return &DownloadPool{workers: make(chan struct{}, concurrency)}
+}
+
+// Go runs download on a worker once one is free. It returns false without
+// running download if the context is done before a worker is free.
+func (p *DownloadPool) Go(ctx context.Context, download func()) bool {
+	select {
+	case p.workers <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.workers
+			p.wg.Done()
+		}()
+		download()
+	}()
+	return true
+}
+
+// Wait waits for the running downloads to finish.
+func (p *DownloadPool) Wait() {
+	p.wg.Wait()
+}
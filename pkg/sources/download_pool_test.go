@@ -0,0 +1,108 @@
+package sources
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestDownloadPool(t *testing.T) {
+	ctx := context.Background()
+	pool := NewDownloadPool(3)
+
+	var running, maxRunning, done atomic.Int32
+	for i := 0; i < 20; i++ {
+		assert.True(t, pool.Go(ctx, func() {
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			done.Add(1)
+		}))
+	}
+	pool.Wait()
+
+	assert.Equal(t, int32(20), done.Load())
+	assert.Equal(t, int32(3), maxRunning.Load())
+}
+
+func TestDownloadPool_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := NewDownloadPool(1)
+
+	release := make(chan struct{})
+	assert.True(t, pool.Go(ctx, func() { <-release }))
+
+	// The only worker is busy, so the download waits until the context is done.
+	cancel()
+	assert.False(t, pool.Go(ctx, func() { t.Error("download ran after the context was done") }))
+
+	close(release)
+	pool.Wait()
+}
+
+// simulatedBucket serves objects of the given size, each after a delay that
+// simulates the round-trip of a request to a bucket.
+func simulatedBucket(size int, latency time.Duration) *httptest.Server {
+	object := strings.Repeat("a", size)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(latency)
+		_, _ = io.WriteString(w, object)
+	}))
+}
+
+func BenchmarkDownloadPool(b *testing.B) {
+	const (
+		objects    = 64
+		objectSize = 4 * 1024
+	)
+	server := simulatedBucket(objectSize, 2*time.Millisecond)
+	defer server.Close()
+
+	download := func(b *testing.B, url string) {
+		resp, err := http.Get(url)
+		if err != nil {
+			b.Error(err)
+			return
+		}
+		defer resp.Body.Close()
+		for chunk := range NewChunkReader()(context.Background(), resp.Body) {
+			if err := chunk.Error(); err != nil {
+				b.Error(err)
+			}
+		}
+	}
+
+	for _, concurrency := range []int{1, 8, 32} {
+		name := "sequential"
+		if concurrency > 1 {
+			name = fmt.Sprintf("parallel-%d", concurrency)
+		}
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			b.SetBytes(objects * objectSize)
+			for i := 0; i < b.N; i++ {
+				pool := NewDownloadPool(concurrency)
+				for j := 0; j < objects; j++ {
+					url := fmt.Sprintf("%s/object-%d", server.URL, j)
+					pool.Go(ctx, func() { download(b, url) })
+				}
+				pool.Wait()
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package filesystem
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+)
+
+// sniffSize is the number of bytes read from the start of a file to decide
+// whether it is binary.
+const sniffSize = 512
+
+// binaryExtensions returns the set of allowed binary file extensions,
+// lowercased and without a leading dot.
+func binaryExtensions(extensions []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(extensions))
+	for _, ext := range extensions {
+		set[normalizeExtension(ext)] = struct{}{}
+	}
+	return set
+}
+
+func normalizeExtension(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
+// skipBinary reports whether file is a binary file that should not be
+// scanned. Binary files are scanned anyway if their extension is allowed or
+// if they are in a format the handlers extract content from, such as an
+// archive. The file is rewound to its start.
+func (s *Source) skipBinary(file *os.File, path string) (bool, error) {
+	if _, ok := s.allowedBinaryExtensions[normalizeExtension(filepath.Ext(path))]; ok {
+		return false, nil
+	}
+
+	header, err := readHeader(file)
+	if err != nil {
+		return false, err
+	}
+	return isBinary(header) && !handlers.IsExtractable(path, header), nil
+}
+
+// skipExtension reports whether file should not be scanned because its
+// extension isn't one of the extensions to scan. Archives and other formats
+// the handlers extract content from are scanned anyway, so that their entries
+// are filtered by extension in turn. The file is rewound to its start.
+func (s *Source) skipExtension(file *os.File, path string) (bool, error) {
+	if s.scanExtensions.Matches(path) {
+		return false, nil
+	}
+
+	header, err := readHeader(file)
+	if err != nil {
+		return false, err
+	}
+	return !handlers.IsExtractable(path, header), nil
+}
+
+// readHeader returns the first sniffSize bytes of file and rewinds it.
+func readHeader(file *os.File) ([]byte, error) {
+	header := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("unable to read file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("unable to seek to start of file: %w", err)
+	}
+	return header[:n], nil
+}
+
+// isBinary reports whether header, the beginning of a file, belongs to a
+// binary file: it contains a null byte or its MIME type isn't a text type.
+func isBinary(header []byte) bool {
+	if len(header) == 0 {
+		return false
+	}
+	if bytes.IndexByte(header, 0) != -1 {
+		return true
+	}
+	for mime := mimetype.Detect(header); mime != nil; mime = mime.Parent() {
+		if mime.Is("text/plain") {
+			return false
+		}
+	}
+	return true
+}
@@ -33,6 +33,24 @@ type Source struct {
 	paths       []string
 	log         logr.Logger
 	filter      *common.Filter
+	// pathFilter is matched against paths relative to the directory being scanned.
+	pathFilter *common.Filter
+	// followSymlinks makes the source scan the files and directories symlinks
+	// point to instead of skipping them.
+	followSymlinks bool
+	// confineToRoot skips followed symlinks that resolve outside the path
+	// being scanned.
+	confineToRoot bool
+	// skipBinaries skips binary files, unless they are archives or have an
+	// allowed extension.
+	skipBinaries            bool
+	allowedBinaryExtensions map[string]struct{}
+	// scanExtensions limits the files and archive entries that are scanned
+	// to those with one of its extensions, if it isn't empty.
+	scanExtensions common.ExtensionSet
+	// bufferThreshold is the size above which files aren't held in memory,
+	// or 0 for the handlers' default.
+	bufferThreshold uint64
 	sources.Progress
 	sources.CommonSourceUnitUnmarshaller
 }
@@ -78,6 +96,18 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 	}
 	s.filter = filter
 
+	pathFilter, err := common.FilterFromRegexes(conn.IncludePathsRegex, conn.ExcludePathsRegex)
+	if err != nil {
+		return fmt.Errorf("invalid path regex: %w", err)
+	}
+	s.pathFilter = pathFilter
+	s.followSymlinks = conn.GetFollowSymlinks()
+	s.confineToRoot = conn.GetConfineToRoot()
+	s.skipBinaries = conn.GetSkipBinaries()
+	s.allowedBinaryExtensions = binaryExtensions(conn.GetAllowedBinaryExtensions())
+	s.scanExtensions = common.NewExtensionSet(conn.GetScanExtensions()...)
+	s.bufferThreshold = conn.GetBufferThreshold()
+
 	return nil
 }
 
@@ -91,7 +121,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 		s.SetProgressComplete(i, len(s.paths), fmt.Sprintf("Path: %s", path), "")
 
 		cleanPath := filepath.Clean(path)
-		fileInfo, err := os.Lstat(cleanPath)
+		fileInfo, err := s.stat(cleanPath)
 		if err != nil {
 			logger.Error(err, "unable to get file info")
 			continue
@@ -122,7 +152,7 @@ func (s *Source) scanDir(ctx context.Context, path string, chunksChan chan *sour
 	workerPool.SetLimit(s.concurrency)
 	defer func() { _ = workerPool.Wait() }()
 
-	return fs.WalkDir(os.DirFS(path), ".", func(relativePath string, d fs.DirEntry, err error) error {
+	return s.walkDir(ctx, path, func(relativePath string, d fs.DirEntry, err error) error {
 		if err != nil {
 			ctx.Logger().Error(err, "error walking directory")
 			return nil
@@ -135,7 +165,7 @@ func (s *Source) scanDir(ctx context.Context, path string, chunksChan chan *sour
 		if !d.Type().IsRegular() {
 			return nil
 		}
-		if s.filter != nil && !s.filter.Pass(fullPath) {
+		if !s.pass(relativePath, fullPath) {
 			return nil
 		}
 
@@ -150,6 +180,25 @@ func (s *Source) scanDir(ctx context.Context, path string, chunksChan chan *sour
 	})
 }
 
+// pass reports whether a file found while walking a directory should be scanned.
+// The file filter is matched against the full path, while the path regexes are
+// matched against the path relative to the walked directory.
+func (s *Source) pass(relativePath, fullPath string) bool {
+	if s.filter != nil && !s.filter.Pass(fullPath) {
+		return false
+	}
+	return s.pathFilter.Pass(relativePath)
+}
+
+// stat returns the file info of path. Symlinks are resolved if the source
+// follows them.
+func (s *Source) stat(path string) (os.FileInfo, error) {
+	if s.followSymlinks {
+		return os.Stat(path)
+	}
+	return os.Lstat(path)
+}
+
 var skipSymlinkErr = errors.New("skipping symlink")
 
 func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sources.Chunk) error {
@@ -158,7 +207,7 @@ func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sou
 	if err != nil {
 		return fmt.Errorf("unable to stat file: %w", err)
 	}
-	if fileStat.Mode()&os.ModeSymlink != 0 {
+	if fileStat.Mode()&os.ModeSymlink != 0 && !s.followSymlinks {
 		return skipSymlinkErr
 	}
 
@@ -166,6 +215,24 @@ func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sou
 	if err != nil {
 		return fmt.Errorf("unable to open file: %w", err)
 	}
+	if s.skipBinaries {
+		if skip, err := s.skipBinary(inputFile, path); err != nil || skip {
+			_ = inputFile.Close()
+			if skip {
+				logger.V(5).Info("skipping binary file")
+			}
+			return err
+		}
+	}
+	if len(s.scanExtensions) > 0 {
+		if skip, err := s.skipExtension(inputFile, path); err != nil || skip {
+			_ = inputFile.Close()
+			if skip {
+				logger.V(5).Info("skipping file not matching the extensions to scan")
+			}
+			return err
+		}
+	}
 
 	logger.V(3).Info("scanning file")
 
@@ -184,7 +251,9 @@ func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sou
 		Verify: s.verify,
 	}
 
-	return handlers.HandleFile(ctx, inputFile, chunkSkel, sources.ChanReporter{Ch: chunksChan})
+	return handlers.HandleFile(ctx, inputFile, chunkSkel, sources.ChanReporter{Ch: chunksChan},
+		handlers.WithFileName(path), handlers.WithScanExtensions(s.scanExtensions),
+		handlers.WithBufferThreshold(s.bufferThreshold))
 }
 
 // Enumerate implements SourceUnitEnumerator interface. This implementation simply
@@ -192,7 +261,7 @@ func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sou
 // filepath or a directory.
 func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) error {
 	for _, path := range s.paths {
-		fileInfo, err := os.Lstat(filepath.Clean(path))
+		fileInfo, err := s.stat(filepath.Clean(path))
 		if err != nil {
 			if err := reporter.UnitErr(ctx, err); err != nil {
 				return err
@@ -206,15 +275,17 @@ func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) e
 			}
 			continue
 		}
-		err = fs.WalkDir(os.DirFS(path), ".", func(relativePath string, d fs.DirEntry, err error) error {
+		err = s.walkDir(ctx, path, func(relativePath string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return reporter.UnitErr(ctx, err)
 			}
-			if d.IsDir() {
+			// Symlinks are only reported when they are followed, as the
+			// file they point to.
+			if !d.Type().IsRegular() {
 				return nil
 			}
 			fullPath := filepath.Join(path, relativePath)
-			if s.filter != nil && !s.filter.Pass(fullPath) {
+			if !s.pass(relativePath, fullPath) {
 				return nil
 			}
 			item := sources.CommonSourceUnit{ID: fullPath}
@@ -235,7 +306,7 @@ func (s *Source) ChunkUnit(ctx context.Context, unit sources.SourceUnit, reporte
 	logger := ctx.Logger().WithValues("path", path)
 
 	cleanPath := filepath.Clean(path)
-	fileInfo, err := os.Lstat(cleanPath)
+	fileInfo, err := s.stat(cleanPath)
 	if err != nil {
 		return reporter.ChunkErr(ctx, fmt.Errorf("unable to get file info: %w", err))
 	}
@@ -1,15 +1,19 @@
 package filesystem
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-logr/logr"
-	"github.com/kylelemons/godebug/pretty"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
@@ -47,6 +51,7 @@ func TestSource_Scan(t *testing.T) {
 				Data: &source_metadatapb.MetaData_Filesystem{
 					Filesystem: &source_metadatapb.Filesystem{
 						File: "filesystem.go",
+						Line: 1,
 					},
 				},
 			},
@@ -83,8 +88,9 @@ func TestSource_Scan(t *testing.T) {
 			for chunk := range chunksCh {
 				if chunk.SourceMetadata.GetFilesystem().GetFile() == "filesystem.go" {
 					counter++
-					if diff := pretty.Compare(chunk.SourceMetadata, tt.wantSourceMetadata); diff != "" {
-						t.Errorf("Source.Chunks() %s diff: (-got +want)\n%s", tt.name, diff)
+					// Chunk metadata is cloned to set its line, so compare it with proto.Equal.
+					if !proto.Equal(chunk.SourceMetadata, tt.wantSourceMetadata) {
+						t.Errorf("Source.Chunks() %s got %v, want %v", tt.name, chunk.SourceMetadata, tt.wantSourceMetadata)
 					}
 				}
 			}
@@ -233,6 +239,275 @@ func TestChunkUnit(t *testing.T) {
 	assert.Contains(t, dataFound, "baz")
 }
 
+func TestPathRegexFilter(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"src/main.go":       "main",
+		"src/main_test.go":  "test",
+		"docs/readme.md":    "readme",
+		"vendor/lib/lib.go": "vendored",
+	}
+	for name, content := range files {
+		fullPath := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		assert.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+	}
+
+	conn, err := anypb.New(&sourcespb.Filesystem{
+		Paths:             []string{dir},
+		IncludePathsRegex: []string{`\.go$`, `^docs/`},
+		ExcludePathsRegex: []string{`_test\.go$`, `^vendor/`},
+	})
+	assert.NoError(t, err)
+
+	s := Source{}
+	err = s.Init(ctx, "test path regex", 0, 0, true, conn, 1)
+	assert.NoError(t, err)
+
+	want := []sources.CommonSourceUnit{
+		{ID: filepath.Join(dir, "src/main.go")},
+		{ID: filepath.Join(dir, "docs/readme.md")},
+	}
+
+	units := sourcestest.TestReporter{}
+	assert.NoError(t, s.Enumerate(ctx, &units))
+	assert.ElementsMatch(t, want, units.Units)
+
+	chunks := sourcestest.TestReporter{}
+	assert.NoError(t, s.ChunkUnit(ctx, sources.CommonSourceUnit{ID: dir}, &chunks))
+	dataFound := make([]string, 0, len(chunks.Chunks))
+	for _, chunk := range chunks.Chunks {
+		dataFound = append(dataFound, string(chunk.Data))
+	}
+	assert.ElementsMatch(t, []string{"main", "readme"}, dataFound)
+}
+
+func TestFollowSymlinks(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	outside := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(outside, "outside.txt"), []byte("outside"), 0644))
+
+	root := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "dir"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "dir/file.txt"), []byte("file"), 0644))
+	// A loop back to the root and a link to a sibling directory.
+	assert.NoError(t, os.Symlink(root, filepath.Join(root, "dir/loop")))
+	assert.NoError(t, os.Symlink(filepath.Join(root, "dir"), filepath.Join(root, "linked")))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(root, "outside")))
+
+	tests := []struct {
+		name           string
+		followSymlinks bool
+		confineToRoot  bool
+		want           []string
+	}{
+		{
+			name: "skip symlinks",
+			want: []string{"dir/file.txt"},
+		},
+		{
+			name:           "follow symlinks",
+			followSymlinks: true,
+			want:           []string{"dir/file.txt", "linked/file.txt", "outside/outside.txt"},
+		},
+		{
+			name:           "confine to root",
+			followSymlinks: true,
+			confineToRoot:  true,
+			want:           []string{"dir/file.txt", "linked/file.txt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := anypb.New(&sourcespb.Filesystem{
+				Paths:          []string{root},
+				FollowSymlinks: tt.followSymlinks,
+				ConfineToRoot:  tt.confineToRoot,
+			})
+			assert.NoError(t, err)
+
+			s := Source{}
+			assert.NoError(t, s.Init(ctx, "test follow symlinks", 0, 0, true, conn, 1))
+
+			want := make([]sources.CommonSourceUnit, 0, len(tt.want))
+			for _, path := range tt.want {
+				want = append(want, sources.CommonSourceUnit{ID: filepath.Join(root, path)})
+			}
+			units := sourcestest.TestReporter{}
+			assert.NoError(t, s.Enumerate(ctx, &units))
+			assert.ElementsMatch(t, want, units.Units)
+			assert.Empty(t, units.UnitErrs)
+
+			chunks := sourcestest.TestReporter{}
+			assert.NoError(t, s.ChunkUnit(ctx, sources.CommonSourceUnit{ID: root}, &chunks))
+			assert.Len(t, chunks.Chunks, len(tt.want))
+		})
+	}
+}
+
+func TestSkipBinaries(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	var gzipped bytes.Buffer
+	w := gzip.NewWriter(&gzipped)
+	_, err := w.Write([]byte("archived"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"text.txt":    "text",
+		"empty.txt":   "",
+		"null.bin":    "null\x00byte",
+		"image.png":   "\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR",
+		"archive.gz":  gzipped.String(),
+		"allowed.DAT": "allowed\x00",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	tests := []struct {
+		name         string
+		skipBinaries bool
+		want         []string
+	}{
+		{
+			name: "disabled",
+			want: []string{"text", "null\x00byte", "archived", "allowed\x00"},
+		},
+		{
+			name:         "enabled",
+			skipBinaries: true,
+			want:         []string{"text", "archived", "allowed\x00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := anypb.New(&sourcespb.Filesystem{
+				Paths:                   []string{dir},
+				SkipBinaries:            tt.skipBinaries,
+				AllowedBinaryExtensions: []string{".dat"},
+			})
+			assert.NoError(t, err)
+
+			s := Source{}
+			assert.NoError(t, s.Init(ctx, "test skip binaries", 0, 0, true, conn, 1))
+
+			chunks := sourcestest.TestReporter{}
+			assert.NoError(t, s.ChunkUnit(ctx, sources.CommonSourceUnit{ID: dir}, &chunks))
+			dataFound := make([]string, 0, len(chunks.Chunks))
+			for _, chunk := range chunks.Chunks {
+				dataFound = append(dataFound, string(chunk.Data))
+			}
+			assert.ElementsMatch(t, tt.want, dataFound)
+		})
+	}
+}
+
+func TestScanExtensions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	for name, content := range map[string]string{"values.yaml": "archived yaml", "notes.txt": "archived text"} {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":        "env",
+		"config.JSON": "json",
+		"main.go":     "go",
+		"bundle.tar":  archive.String(),
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	tests := []struct {
+		name       string
+		extensions []string
+		want       []string
+	}{
+		{
+			name: "all files",
+			want: []string{"env", "json", "go", "archived yaml", "archived text"},
+		},
+		{
+			name:       "only extensions",
+			extensions: []string{"env", ".json", "YAML"},
+			want:       []string{"env", "json", "archived yaml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := anypb.New(&sourcespb.Filesystem{
+				Paths:          []string{dir},
+				ScanExtensions: tt.extensions,
+			})
+			assert.NoError(t, err)
+
+			s := Source{}
+			assert.NoError(t, s.Init(ctx, "test scan extensions", 0, 0, true, conn, 1))
+
+			chunks := sourcestest.TestReporter{}
+			assert.NoError(t, s.ChunkUnit(ctx, sources.CommonSourceUnit{ID: dir}, &chunks))
+			dataFound := make([]string, 0, len(chunks.Chunks))
+			for _, chunk := range chunks.Chunks {
+				dataFound = append(dataFound, string(chunk.Data))
+			}
+			assert.ElementsMatch(t, tt.want, dataFound)
+		})
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "empty", header: "", want: false},
+		{name: "text", header: "password=hunter2\n", want: false},
+		{name: "json", header: `{"key": "value"}`, want: false},
+		{name: "utf-8", header: "clé secrète", want: false},
+		{name: "null byte", header: "text\x00", want: true},
+		{name: "elf", header: "\x7fELF\x02\x01\x01", want: true},
+		{name: "control characters", header: "\x01\x02\x03\x04", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isBinary([]byte(tt.header)))
+		})
+	}
+}
+
+func TestInitInvalidPathRegex(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	conn, err := anypb.New(&sourcespb.Filesystem{
+		Paths:             []string{"."},
+		ExcludePathsRegex: []string{"[unterminated"},
+	})
+	assert.NoError(t, err)
+
+	s := Source{}
+	err = s.Init(ctx, "test invalid path regex", 0, 0, true, conn, 1)
+	assert.ErrorContains(t, err, "[unterminated")
+}
+
 func TestEnumerateReporterErr(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -337,3 +612,85 @@ func createTempDir(dir string, contents ...string) (string, func(), error) {
 	}
 	return tmpdir, func() { _ = os.RemoveAll(tmpdir) }, nil
 }
+
+// BenchmarkScanFileMemory scans a large text file and reports the peak heap in
+// use. Files larger than the buffer threshold are chunked as they're read, so
+// the peak stays under a ceiling that doesn't depend on the size of the file.
+func BenchmarkScanFileMemory(b *testing.B) {
+	const (
+		fileSize  = 1 << 30 // 1 GB
+		threshold = 1 << 20
+		// maxPeakHeap leaves room for the chunks in flight and the runtime, but
+		// is far below the size of the file.
+		maxPeakHeap = 64 << 20
+	)
+
+	path := filepath.Join(b.TempDir(), "large.log")
+	file, err := os.Create(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	line := []byte(strings.Repeat("2024-01-01T00:00:00Z INFO request handled without secrets ", 2) + "\n")
+	for written := 0; written < fileSize; written += len(line) {
+		if _, err := file.Write(line); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		b.Fatal(err)
+	}
+
+	ctx := context.WithLogger(context.Background(), logr.Discard())
+	source := &Source{bufferThreshold: threshold}
+	runtime.GC()
+
+	var peak uint64
+	b.SetBytes(fileSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		done := make(chan struct{})
+		sampled := make(chan struct{})
+		go func() {
+			defer close(sampled)
+			ticker := time.NewTicker(10 * time.Millisecond)
+			defer ticker.Stop()
+			var stats runtime.MemStats
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					runtime.ReadMemStats(&stats)
+					if stats.HeapInuse > peak {
+						peak = stats.HeapInuse
+					}
+				}
+			}
+		}()
+
+		chunksChan := make(chan *sources.Chunk, 64)
+		total := 0
+		drained := make(chan struct{})
+		go func() {
+			defer close(drained)
+			for chunk := range chunksChan {
+				total += len(chunk.Data)
+			}
+		}()
+		if err := source.scanFile(ctx, path, chunksChan); err != nil {
+			b.Fatal(err)
+		}
+		close(chunksChan)
+		<-drained
+		close(done)
+		<-sampled
+
+		if total < fileSize {
+			b.Fatalf("expected at least %d bytes, got %d", fileSize, total)
+		}
+	}
+	b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+	if peak > maxPeakHeap {
+		b.Fatalf("peak heap of %d MB, expected at most %d MB", peak>>20, maxPeakHeap>>20)
+	}
+}
@@ -0,0 +1,150 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// walkDir walks the directory tree rooted at root like fs.WalkDir. If the
+// source follows symlinks, each symlink is reported as the file or directory
+// it points to, under the path of the symlink.
+func (s *Source) walkDir(ctx context.Context, root string, fn fs.WalkDirFunc) error {
+	if !s.followSymlinks {
+		return fs.WalkDir(os.DirFS(root), ".", fn)
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return fn(".", nil, err)
+	}
+	info, err := os.Stat(realRoot)
+	if err != nil {
+		return fn(".", nil, err)
+	}
+
+	w := &symlinkWalker{ctx: ctx, root: realRoot, confineToRoot: s.confineToRoot, fn: fn}
+	err = w.walk(root, ".", fs.FileInfoToDirEntry(info), []os.FileInfo{info})
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// symlinkWalker walks a directory tree following symlinks. A symlink to a
+// directory that is already on the path being walked would loop forever, so
+// it is logged and skipped.
+type symlinkWalker struct {
+	ctx context.Context
+	// root is the scanned directory with its symlinks resolved.
+	root          string
+	confineToRoot bool
+	fn            fs.WalkDirFunc
+}
+
+// walk calls fn for the entry at path and, if it is a directory, walks its
+// contents. ancestors holds the directories on the current path, including
+// the entry itself if it is a directory.
+func (w *symlinkWalker) walk(path, relativePath string, d fs.DirEntry, ancestors []os.FileInfo) error {
+	if err := w.fn(relativePath, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, fs.SkipDir) && d.IsDir() {
+			return nil
+		}
+		return err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if err := w.fn(relativePath, d, err); !errors.Is(err, fs.SkipDir) {
+			return err
+		}
+		return nil
+	}
+
+	for _, entry := range entries {
+		if common.IsDone(w.ctx) {
+			return w.ctx.Err()
+		}
+		entryPath := filepath.Join(path, entry.Name())
+		entryRelativePath := pathpkg.Join(relativePath, entry.Name())
+
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if entry, err = w.resolve(entryPath); err != nil {
+				if err := w.fn(entryRelativePath, nil, err); err != nil {
+					return err
+				}
+				continue
+			}
+			if entry == nil {
+				continue
+			}
+		}
+
+		entryAncestors := ancestors
+		if entry.IsDir() {
+			info, err := entry.Info()
+			if err != nil {
+				if err := w.fn(entryRelativePath, entry, err); err != nil {
+					return err
+				}
+				continue
+			}
+			if onPath(info, ancestors) {
+				w.ctx.Logger().Info("skipping symlink loop", "path", entryPath)
+				continue
+			}
+			entryAncestors = append(ancestors, info)
+		}
+
+		if err := w.walk(entryPath, entryRelativePath, entry, entryAncestors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the entry the symlink at path points to, or nil if the
+// symlink should be skipped.
+func (w *symlinkWalker) resolve(path string) (fs.DirEntry, error) {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve symlink: %w", err)
+	}
+	if w.confineToRoot && !isWithin(w.root, target) {
+		w.ctx.Logger().V(2).Info("skipping symlink outside of the scanned path", "path", path, "target", target)
+		return nil, nil
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, fmt.Errorf("unable to stat symlink target: %w", err)
+	}
+	return fs.FileInfoToDirEntry(info), nil
+}
+
+// onPath reports whether the directory is one of the ancestors, comparing
+// device and inode numbers.
+func onPath(dir os.FileInfo, ancestors []os.FileInfo) bool {
+	for _, ancestor := range ancestors {
+		if os.SameFile(dir, ancestor) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithin reports whether path is root or inside it. Both must be clean,
+// absolute or relative to the same directory.
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
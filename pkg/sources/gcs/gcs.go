@@ -175,6 +175,7 @@ func configureGCSManager(aCtx context.Context, conn *sourcespb.GCS, concurrency
 	gcsManagerOpts := []gcsManagerOption{
 		withConcurrency(concurrency),
 		withMaxObjectSize(conn.MaxObjectSize),
+		withPrefix(conn.GetPrefix()),
 		gcsManagerAuthOption,
 	}
 	if setGCSManagerBucketOptions(conn) != nil {
@@ -261,7 +262,11 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 	s.chunksCh = chunksChan
 	s.Progress.Message = "starting to process objects..."
 
-	var wg sync.WaitGroup
+	// Objects are downloaded by the workers of the pool, which stops the
+	// listing from getting ahead of the downloads once the channel is full.
+	// Once the context is done, the remaining objects are drained without
+	// being downloaded, so the listing can finish.
+	pool := sources.NewDownloadPool(s.concurrency)
 	for obj := range objectCh {
 		obj := obj
 		o, ok := obj.(object)
@@ -275,18 +280,15 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 			continue
 		}
 
-		wg.Add(1)
-		go func(obj object) {
-			defer wg.Done()
-
+		pool.Go(ctx, func() {
 			if err := s.processObject(ctx, o); err != nil {
 				ctx.Logger().V(1).Info("error setting start progress progress", "name", o.name, "error", err)
 				return
 			}
 			s.setProgress(ctx, o.md5, o.name, persistableCache)
-		}(o)
+		})
 	}
-	wg.Wait()
+	pool.Wait()
 
 	s.completeProgress(ctx)
 	return nil
@@ -345,6 +347,7 @@ func (s *Source) processObject(ctx context.Context, o object) error {
 					Link:        o.link,
 					Email:       o.owner,
 					ContentType: o.contentType,
+					Generation:  o.generation,
 					Acls:        o.acl,
 					CreatedAt:   strconv.FormatInt(o.createdAt.Unix(), 10), // Unix time as string
 					UpdatedAt:   o.updatedAt.String(),
@@ -353,5 +356,5 @@ func (s *Source) processObject(ctx context.Context, o object) error {
 		},
 	}
 
-	return handlers.HandleFile(ctx, io.NopCloser(o), chunkSkel, sources.ChanReporter{Ch: s.chunksCh})
+	return handlers.HandleFile(ctx, io.NopCloser(o), chunkSkel, sources.ChanReporter{Ch: s.chunksCh}, handlers.WithFileName(o.name))
 }
@@ -51,8 +51,10 @@ type gcsManager struct {
 	workerPool  *errgroup.Group
 
 	maxObjectSize int64
-	numBuckets    uint32
-	numObjects    uint64
+	// prefix limits the listed objects to those whose names start with it.
+	prefix     string
+	numBuckets uint32
+	numObjects uint64
 
 	includeBuckets,
 	excludeBuckets,
@@ -273,6 +275,16 @@ func withExcludeObjects(objects []string) gcsManagerOption {
 	}
 }
 
+// withPrefix limits the scan to objects whose names start with prefix.
+// Unlike withIncludeObjects, the prefix is applied when listing objects,
+// so objects outside of it are never retrieved.
+func withPrefix(prefix string) gcsManagerOption {
+	return func(m *gcsManager) error {
+		m.prefix = prefix
+		return nil
+	}
+}
+
 // withConcurrency sets the number of concurrent workers that will be used
 // to process objects.
 // If not set, or set to a negative number the default value is runtime.NumCPU().
@@ -368,10 +380,11 @@ type object struct {
 	md5         string
 	// acl represents an ACLEntities.
 	// https://pkg.go.dev/cloud.google.com/go/storage#ACLEntity
-	acl       []string
-	size      int64
-	createdAt time.Time
-	updatedAt time.Time
+	acl        []string
+	size       int64
+	generation int64
+	createdAt  time.Time
+	updatedAt  time.Time
 
 	io.Reader
 }
@@ -402,7 +415,7 @@ func (g *gcsManager) enumerate(ctx context.Context, bkts []bucket) (*attributes,
 			// List all the objects in the bucket and calculate attributes.
 			g.setupBktHandle(&bkt)
 
-			q, err := setObjectQuery(&bkt)
+			q, err := setObjectQuery(&bkt, g.prefix)
 			if err != nil {
 				logger.Error(err, "failed to set object query", "bucket", bkt.name)
 				return nil
@@ -563,7 +576,7 @@ func (g *gcsManager) setupBktHandle(bkt *bucket) {
 }
 
 func (g *gcsManager) bucketObjects(ctx context.Context, bkt *bucket, ch chan<- io.Reader) error {
-	q, err := setObjectQuery(bkt)
+	q, err := setObjectQuery(bkt, g.prefix)
 	if err != nil {
 		return fmt.Errorf("failed to set object query: %w", err)
 	}
@@ -597,10 +610,10 @@ func (g *gcsManager) bucketObjects(ctx context.Context, bkt *bucket, ch chan<- i
 	return nil
 }
 
-func setObjectQuery(bkt *bucket) (*storage.Query, error) {
+func setObjectQuery(bkt *bucket, prefix string) (*storage.Query, error) {
 	// Setting the attribute selection is a performance optimization.
 	// https://pkg.go.dev/cloud.google.com/go/storage#Query.SetAttrSelection
-	q := new(storage.Query)
+	q := &storage.Query{Prefix: prefix}
 	err := q.SetAttrSelection([]string{
 		"Name",
 		"ContentType",
@@ -609,6 +622,7 @@ func setObjectQuery(bkt *bucket) (*storage.Query, error) {
 		"Updated",
 		"Created",
 		"ACL",
+		"Generation",
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to set attribute selection: %w", err)
@@ -629,15 +643,11 @@ func (g *gcsManager) constructObject(ctx context.Context, obj *storage.ObjectHan
 		return o, fmt.Errorf("failed to retrieve object attributes: %w", err)
 	}
 
+	ctx = context.WithValues(ctx, "object-name", attrs.Name)
 	if !isObjectTypeValid(ctx, attrs.Name) || !g.isObjectSizeValid(ctx, attrs.Size) {
 		return o, fmt.Errorf("object is not valid")
 	}
 
-	rc, err := obj.NewReader(ctx)
-	if err != nil {
-		return o, fmt.Errorf("failed to retrieve object reader: %w", err)
-	}
-
 	o.name = attrs.Name
 	o.bucket = attrs.Bucket
 	o.contentType = attrs.ContentType
@@ -648,13 +658,42 @@ func (g *gcsManager) constructObject(ctx context.Context, obj *storage.ObjectHan
 	o.updatedAt = attrs.Updated
 	o.acl = objectACLs(attrs.ACL)
 	o.size = attrs.Size
-	o.Reader = rc
+	o.generation = attrs.Generation
+	o.Reader = &objectReader{ctx: ctx, obj: obj}
 
 	atomic.AddUint64(&g.numObjects, 1)
 
 	return o, nil
 }
 
+// objectReader downloads an object when it's first read, so that objects are
+// downloaded by the worker scanning them rather than while they are listed.
+// The download is closed once it's read to the end or fails.
+type objectReader struct {
+	ctx context.Context
+	obj *storage.ObjectHandle
+	rc  io.ReadCloser
+	err error
+}
+
+func (r *objectReader) Read(p []byte) (int, error) {
+	if r.rc == nil && r.err == nil {
+		if r.rc, r.err = r.obj.NewReader(r.ctx); r.err != nil {
+			r.err = fmt.Errorf("failed to retrieve object reader: %w", r.err)
+		}
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	n, err := r.rc.Read(p)
+	if err != nil {
+		_ = r.rc.Close()
+		r.err = err
+	}
+	return n, err
+}
+
 func objectACLs(acl []storage.ACLRule) []string {
 	acls := make([]string, 0, len(acl))
 	for _, rule := range acl {
@@ -673,8 +712,11 @@ func isObjectTypeValid(ctx context.Context, name string) bool {
 }
 
 func (g *gcsManager) isObjectSizeValid(ctx context.Context, size int64) bool {
-	isValid := size > 0 && size <= g.maxObjectSize
-	if !isValid {
+	if size > g.maxObjectSize {
+		ctx.Logger().V(2).Info("skipping object larger than the max object size", "object-size", size, "max-object-size", g.maxObjectSize)
+		return false
+	}
+	if size <= 0 {
 		ctx.Logger().V(2).Info("object size is invalid", "object-size", size)
 		return false
 	}
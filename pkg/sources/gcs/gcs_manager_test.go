@@ -329,6 +329,17 @@ func TestNewGcsManager(t *testing.T) {
 				maxObjectSize: defaultMaxObjectSize,
 			},
 		},
+		{
+			name:   "new gcs manager, with prefix",
+			projID: testProjectID,
+			opts:   []gcsManagerOption{withHTTPClient(ctx, &http.Client{}), withPrefix("logs/")},
+			want: &gcsManager{
+				projectID:     testProjectID,
+				concurrency:   defaultConcurrency,
+				maxObjectSize: defaultMaxObjectSize,
+				prefix:        "logs/",
+			},
+		},
 		{
 			name:   "new gcs manager, with HTTP client",
 			projID: testProjectID,
@@ -857,6 +868,13 @@ func Test_isObjectTypeValid(t *testing.T) {
 	}
 }
 
+func Test_setObjectQuery(t *testing.T) {
+	q, err := setObjectQuery(&bucket{name: "bkt", startOffset: "logs/b"}, "logs/")
+	assert.Nil(t, err)
+	assert.Equal(t, "logs/", q.Prefix)
+	assert.Equal(t, "logs/b", q.StartOffset)
+}
+
 func Test_isObjectSizeValid(t *testing.T) {
 	testCases := []struct {
 		name    string
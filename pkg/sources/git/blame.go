@@ -0,0 +1,142 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/gitparse"
+)
+
+// lineAttribution attributes the lines of the diffs of commits to the commits that introduced
+// them, so that lines introduced before a date can be left out of the scan. The lines of a
+// commit's diff are usually introduced by that commit, but not when they were moved or copied
+// from another file or another part of the file, such as when a file is renamed.
+//
+// Attribution runs git blame, following moves and copies from any file of the parent commit, on
+// each file changed by each commit authored after the date, which is much slower than scanning
+// the diffs alone.
+type lineAttribution struct {
+	path  string
+	since time.Time
+
+	// The blame of the last blamed file is cached, as a file's diff may be split in several hunks.
+	commit, file string
+	introduced   map[int]time.Time
+}
+
+func newLineAttribution(path string, since time.Time) *lineAttribution {
+	return &lineAttribution{path: path, since: since}
+}
+
+// filter blanks the lines of the diff that were introduced before the date, keeping their line
+// breaks so that line numbers are kept. It reports whether any line is left to scan.
+func (a *lineAttribution) filter(diff *gitparse.Diff) (bool, error) {
+	introduced, err := a.blame(diff.Commit.Hash, diff.PathB)
+	if err != nil {
+		return false, err
+	}
+
+	reader, err := diff.ReadCloser()
+	if err != nil {
+		return false, err
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		filtered bytes.Buffer
+		kept     bool
+	)
+	for i, line := range bytes.SplitAfter(data, []byte("\n")) {
+		when, ok := introduced[diff.LineStart+i]
+		if ok && when.Before(a.since) {
+			if bytes.HasSuffix(line, []byte("\n")) {
+				filtered.WriteByte('\n')
+			}
+			continue
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			kept = true
+		}
+		filtered.Write(line)
+	}
+	if !kept {
+		return false, nil
+	}
+	return true, diff.SetContent(filtered.Bytes())
+}
+
+// blame returns the author time of the commit that introduced each line of the file at the
+// commit, by line number.
+func (a *lineAttribution) blame(commit, file string) (map[int]time.Time, error) {
+	if commit == a.commit && file == a.file {
+		return a.introduced, nil
+	}
+
+	out, err := exec.Command("git", "-C", a.path, "blame", "--porcelain", "-M", "-C", "-C", commit, "--", file).Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to blame %s at %s: %w", file, commit, err)
+	}
+	introduced, err := parseBlamePorcelain(out)
+	if err != nil {
+		return nil, err
+	}
+
+	a.commit, a.file, a.introduced = commit, file, introduced
+	return introduced, nil
+}
+
+// parseBlamePorcelain parses the output of git blame --porcelain into the author time of the
+// commit of each line. Each line of the file is preceded by a header with the commit and the
+// line number, and the first header of each commit is followed by the commit's information.
+func parseBlamePorcelain(out []byte) (map[int]time.Time, error) {
+	var (
+		lineCommits = make(map[int]string)
+		authorTimes = make(map[string]time.Time)
+		current     string
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\t") {
+			// The content of the line.
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && (len(fields[0]) == 40 || len(fields[0]) == 64) {
+			if finalLine, err := strconv.Atoi(fields[2]); err == nil {
+				current = fields[0]
+				lineCommits[finalLine] = current
+				continue
+			}
+		}
+		if len(fields) == 2 && fields[0] == "author-time" {
+			seconds, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid blame author time %q", fields[1])
+			}
+			authorTimes[current] = time.Unix(seconds, 0)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	introduced := make(map[int]time.Time, len(lineCommits))
+	for line, commit := range lineCommits {
+		if when, ok := authorTimes[commit]; ok {
+			introduced[line] = when
+		}
+	}
+	return introduced, nil
+}
@@ -46,6 +46,7 @@ type Source struct {
 
 	useCustomContentWriter bool
 	git                    *Git
+	gitConfig              *Config
 	scanOptions            *ScanOptions
 
 	sources.Progress
@@ -66,6 +67,10 @@ type Git struct {
 	concurrency        *semaphore.Weighted
 	skipBinaries       bool
 	skipArchives       bool
+	// fetchLFS downloads the objects referenced by Git LFS pointer files from
+	// the LFS server of the repository and scans them instead of the pointers.
+	fetchLFS         bool
+	maxLFSObjectSize int64
 
 	parser *gitparse.Parser
 }
@@ -86,6 +91,10 @@ type Config struct {
 	Verify       bool
 	SkipBinaries bool
 	SkipArchives bool
+	// FetchLFS scans the objects referenced by Git LFS pointer files, downloaded using the
+	// credentials of the repository's remote. Objects larger than MaxLFSObjectSize are skipped.
+	FetchLFS         bool
+	MaxLFSObjectSize int64
 
 	// UseCustomContentWriter indicates whether to use a custom contentWriter.
 	// When set to true, the parser will use a custom contentWriter provided through the WithContentWriter option.
@@ -113,6 +122,8 @@ func NewGit(config *Config) *Git {
 		concurrency:        semaphore.NewWeighted(int64(config.Concurrency)),
 		skipBinaries:       config.SkipBinaries,
 		skipArchives:       config.SkipArchives,
+		fetchLFS:           config.FetchLFS,
+		maxLFSObjectSize:   config.MaxLFSObjectSize,
 		parser:             parser,
 	}
 }
@@ -159,10 +170,17 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 	}
 
 	if uri := conn.GetUri(); uri != "" {
-		repoPath, _, err := prepareRepoSinceCommit(aCtx, uri, conn.GetBase())
+		// A cloned repository has no uncommitted changes to scan.
+		if conn.GetWorkingTreeOnly() && !strings.HasPrefix(uri, "file://") {
+			return fmt.Errorf("the working tree can only be scanned in a local repository")
+		}
+		repoPath, remote, err := prepareRepoSinceCommit(aCtx, uri, conn.GetBase())
 		if err != nil || repoPath == "" {
 			return fmt.Errorf("error preparing repo: %w", err)
 		}
+		if remote {
+			fetchRefs(aCtx, repoPath, conn.GetRefs())
+		}
 		conn.Directories = append(conn.Directories, repoPath)
 	}
 
@@ -188,6 +206,24 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 	if isBare := conn.GetBare(); isBare {
 		opts = append(opts, ScanOptionBare(isBare))
 	}
+	if refs := conn.GetRefs(); len(refs) > 0 {
+		opts = append(opts, ScanOptionRefs(refs))
+	}
+	if conn.GetScanCommitMessages() {
+		opts = append(opts, ScanOptionCommitMessages(true))
+	}
+	if conn.GetWorkingTreeOnly() {
+		opts = append(opts, ScanOptionWorkingTreeOnly(true))
+	}
+	if conn.GetScanNotes() {
+		opts = append(opts, ScanOptionNotes(true))
+	}
+	if conn.GetScanStashes() {
+		opts = append(opts, ScanOptionStashes(true))
+	}
+	if since := conn.GetSinceDate(); since != nil {
+		opts = append(opts, ScanOptionSinceDate(since.AsTime()))
+	}
 	s.withScanOptions(NewScanOptions(opts...))
 
 	s.conn = &conn
@@ -201,34 +237,45 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 	}
 
 	cfg := &Config{
-		SourceName:   s.name,
-		JobID:        s.jobID,
-		SourceID:     s.sourceID,
-		SourceType:   s.Type(),
-		Verify:       s.verify,
-		SkipBinaries: conn.GetSkipBinaries(),
-		SkipArchives: conn.GetSkipArchives(),
-		Concurrency:  concurrency,
-		SourceMetadataFunc: func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
-			return &source_metadatapb.MetaData{
-				Data: &source_metadatapb.MetaData_Git{
-					Git: &source_metadatapb.Git{
-						Commit:     sanitizer.UTF8(commit),
-						File:       sanitizer.UTF8(file),
-						Email:      sanitizer.UTF8(email),
-						Repository: sanitizer.UTF8(repository),
-						Timestamp:  sanitizer.UTF8(timestamp),
-						Line:       line,
-					},
-				},
-			}
-		},
+		SourceName:             s.name,
+		JobID:                  s.jobID,
+		SourceID:               s.sourceID,
+		SourceType:             s.Type(),
+		Verify:                 s.verify,
+		SkipBinaries:           conn.GetSkipBinaries(),
+		SkipArchives:           conn.GetSkipArchives(),
+		FetchLFS:               conn.GetFetchLfs(),
+		MaxLFSObjectSize:       conn.GetMaxLfsObjectSize(),
+		Concurrency:            concurrency,
+		SourceMetadataFunc:     gitMetadataFunc("", ""),
 		UseCustomContentWriter: s.useCustomContentWriter,
 	}
+	s.gitConfig = cfg
 	s.git = NewGit(cfg)
 	return nil
 }
 
+// gitMetadataFunc returns a SourceMetadataFunc for git chunks. When parentRepo is non-empty the
+// metadata attributes the chunk to the submodule at submodulePath within parentRepo.
+func gitMetadataFunc(parentRepo, submodulePath string) func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+	return func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+		return &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{
+				Git: &source_metadatapb.Git{
+					Commit:           sanitizer.UTF8(commit),
+					File:             sanitizer.UTF8(file),
+					Email:            sanitizer.UTF8(email),
+					Repository:       sanitizer.UTF8(repository),
+					Timestamp:        sanitizer.UTF8(timestamp),
+					Line:             line,
+					ParentRepository: sanitizer.UTF8(parentRepo),
+					SubmodulePath:    sanitizer.UTF8(submodulePath),
+				},
+			},
+		}
+	}
+}
+
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ ...sources.ChunkingTarget) error {
 	reporter := sources.ChanReporter{Ch: chunksChan}
@@ -238,6 +285,16 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 	if err := s.scanDirs(ctx, reporter); err != nil {
 		return err
 	}
+	if s.conn.GetIncludeSubmodules() {
+		visitor := sources.VisitorReporter{
+			VisitUnit: func(ctx context.Context, unit sources.SourceUnit) error {
+				return s.ChunkUnit(ctx, unit, reporter)
+			},
+		}
+		if err := s.enumerateSubmodules(ctx, visitor); err != nil {
+			return err
+		}
+	}
 
 	totalRepos := len(s.conn.Repositories) + len(s.conn.Directories)
 	ctx.Logger().V(1).Info("Git source finished scanning", "repo_count", totalRepos)
@@ -269,32 +326,16 @@ func (s *Source) scanRepos(ctx context.Context, reporter sources.ChunkReporter)
 
 // scanRepo scans a single provided repository.
 func (s *Source) scanRepo(ctx context.Context, repoURI string, reporter sources.ChunkReporter) error {
-	var cloneFunc func() (string, *git.Repository, error)
-	switch cred := s.conn.GetCredential().(type) {
-	case *sourcespb.Git_BasicAuth:
-		cloneFunc = func() (string, *git.Repository, error) {
-			user := cred.BasicAuth.Username
-			token := cred.BasicAuth.Password
-			return CloneRepoUsingToken(ctx, token, repoURI, user)
-		}
-	case *sourcespb.Git_Unauthenticated:
-		cloneFunc = func() (string, *git.Repository, error) {
-			return CloneRepoUsingUnauthenticated(ctx, repoURI)
-		}
-	case *sourcespb.Git_SshAuth:
-		cloneFunc = func() (string, *git.Repository, error) {
-			return CloneRepoUsingSSH(ctx, repoURI)
-		}
-	default:
-		return errors.New("invalid connection type for git source")
-	}
-
 	err := func() error {
-		path, repo, err := cloneFunc()
+		path, repo, err := s.clone(ctx, repoURI)
 		defer os.RemoveAll(path)
 		if err != nil {
 			return err
 		}
+		fetchRefs(ctx, path, s.scanOptions.Refs)
+		if s.scanOptions.Notes {
+			fetchNotes(ctx, path)
+		}
 		return s.git.ScanRepo(ctx, repo, path, s.scanOptions, reporter)
 	}()
 	if err != nil {
@@ -303,6 +344,22 @@ func (s *Source) scanRepo(ctx context.Context, repoURI string, reporter sources.
 	return nil
 }
 
+// clone clones repoURI with the credentials of the source.
+func (s *Source) clone(ctx context.Context, repoURI string, args ...string) (string, *git.Repository, error) {
+	switch cred := s.conn.GetCredential().(type) {
+	case *sourcespb.Git_BasicAuth:
+		user := cred.BasicAuth.Username
+		token := cred.BasicAuth.Password
+		return CloneRepoUsingToken(ctx, token, repoURI, user, args...)
+	case *sourcespb.Git_Unauthenticated:
+		return CloneRepoUsingUnauthenticated(ctx, repoURI, args...)
+	case *sourcespb.Git_SshAuth:
+		return CloneRepoUsingSSH(ctx, repoURI, args...)
+	default:
+		return "", nil, errors.New("invalid connection type for git source")
+	}
+}
+
 // scanDirs scans the configured directories in s.conn.Directories.
 func (s *Source) scanDirs(ctx context.Context, reporter sources.ChunkReporter) error {
 	totalRepos := len(s.conn.Repositories) + len(s.conn.Directories)
@@ -512,8 +569,38 @@ func CloneRepoUsingSSH(ctx context.Context, gitURL string, args ...string) (stri
 	return CloneRepo(ctx, userInfo, gitURL, args...)
 }
 
+// fetchRefs fetches refs that aren't part of a default clone, such as
+// refs/pull/123/head, from origin into the repository at path. Refs that
+// already resolve locally or that don't look like full ref names are skipped.
+// Failures are only logged; unresolvable refs are reported when the repo is
+// scanned.
+func fetchRefs(ctx context.Context, path string, refs []string) {
+	for _, ref := range refs {
+		if !strings.HasPrefix(ref, "refs/") {
+			continue
+		}
+		verifyCmd := exec.CommandContext(ctx, "git", "-C", path, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+		if err := verifyCmd.Run(); err == nil {
+			continue
+		}
+		fetchCmd := exec.CommandContext(ctx, "git", "-C", path, "fetch", "--quiet", "origin", ref+":"+ref)
+		if output, err := fetchCmd.CombinedOutput(); err != nil {
+			ctx.Logger().V(1).Info("failed to fetch ref", "ref", ref, "error", err, "output", string(output))
+		}
+	}
+}
+
 var codeCommitRE = regexp.MustCompile(`ssh://git-codecommit\.[\w-]+\.amazonaws\.com`)
 
+// fetchNotes fetches the notes refs of the origin of a cloned repository, which git clone
+// doesn't fetch. Failures are only logged, as the repository may have no notes.
+func fetchNotes(ctx context.Context, path string) {
+	fetchCmd := exec.Command("git", "-C", path, "fetch", "--quiet", "origin", "refs/notes/*:refs/notes/*")
+	if output, err := fetchCmd.CombinedOutput(); err != nil {
+		ctx.Logger().V(1).Info("failed to fetch notes", "error", err, "output", string(output))
+	}
+}
+
 func isCodeCommitURL(gitURL string) bool { return codeCommitRE.MatchString(gitURL) }
 
 func (s *Git) CommitsScanned() uint64 {
@@ -548,8 +635,29 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 	if scanOptions.MaxDepth > 0 {
 		logValues = append(logValues, "max_depth", scanOptions.MaxDepth)
 	}
+	if len(scanOptions.Refs) > 0 {
+		logValues = append(logValues, "refs", scanOptions.Refs)
+	}
 
-	diffChan, err := s.parser.RepoPath(repoCtx, path, scanOptions.HeadHash, scanOptions.BaseHash == "", scanOptions.ExcludeGlobs, scanOptions.Bare)
+	revisions, err := logRevisions(repo, scanOptions)
+	if err != nil {
+		return err
+	}
+
+	var diffChan chan *gitparse.Diff
+	if scanOptions.MaxDepth > 0 {
+		// Only log the most recent commits of each revision, rather than walking the whole history.
+		commits, err := recentCommits(ctx, path, revisions, scanOptions.MaxDepth)
+		if err != nil {
+			return err
+		}
+		if len(commits) == 0 {
+			return nil
+		}
+		diffChan, err = s.parser.RepoPathCommits(repoCtx, path, commits, scanOptions.BaseHash == "", scanOptions.ExcludeGlobs, scanOptions.Bare)
+	} else {
+		diffChan, err = s.parser.RepoPathRevisions(repoCtx, path, revisions, scanOptions.BaseHash == "", scanOptions.ExcludeGlobs, scanOptions.Bare)
+	}
 	if err != nil {
 		return err
 	}
@@ -559,16 +667,23 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 
 	gitDir := filepath.Join(path, gitDirName)
 
+	var lfs *lfsClient
+	if s.fetchLFS {
+		if lfs, err = newLFSClient(repo, s.maxLFSObjectSize); err != nil {
+			logger.Info("unable to fetch LFS objects, scanning LFS pointers instead", "error", err)
+		}
+	}
+
+	var attribution *lineAttribution
+	if !scanOptions.SinceDate.IsZero() {
+		attribution = newLineAttribution(path, scanOptions.SinceDate)
+		logValues = append(logValues, "since_date", scanOptions.SinceDate)
+	}
+
 	logger.Info("scanning repo", logValues...)
 
-	var depth int64
 	var lastCommitHash string
 	for diff := range diffChan {
-		if scanOptions.MaxDepth > 0 && depth >= scanOptions.MaxDepth {
-			logger.V(1).Info("reached max depth", "depth", depth)
-			break
-		}
-
 		commit := diff.Commit
 		fullHash := commit.Hash
 		if scanOptions.BaseHash != "" && scanOptions.BaseHash == fullHash {
@@ -576,11 +691,14 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 			break
 		}
 
+		if !scanOptions.SinceDate.IsZero() && commit.Date.Before(scanOptions.SinceDate) {
+			continue
+		}
+
 		email := commit.Author
 		when := commit.Date.UTC().Format("2006-01-02 15:04:05 -0700")
 
 		if fullHash != lastCommitHash {
-			depth++
 			lastCommitHash = fullHash
 			atomic.AddUint64(&s.metrics.commitsScanned, 1)
 			logger.V(5).Info("scanning commit", "commit", fullHash)
@@ -595,7 +713,9 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 			sb.WriteString("\n")
 			sb.WriteString(commit.Committer)
 			sb.WriteString("\n")
-			sb.WriteString(commit.Message.String())
+			if !scanOptions.CommitMessages {
+				sb.WriteString(commit.Message.String())
+			}
 			chunk := sources.Chunk{
 				SourceName:     s.sourceName,
 				SourceID:       s.sourceID,
@@ -608,6 +728,15 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 			if err := reporter.ChunkOk(ctx, chunk); err != nil {
 				return err
 			}
+
+			// The message is sent whole, so secrets spanning several lines or
+			// paragraphs of it aren't split across chunks.
+			if message := commit.Message.String(); scanOptions.CommitMessages && strings.TrimSpace(message) != "" {
+				chunk.Data = []byte(message)
+				if err := reporter.ChunkOk(ctx, chunk); err != nil {
+					return err
+				}
+			}
 		}
 
 		fileName := diff.PathB
@@ -644,6 +773,16 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 			continue
 		}
 
+		// Lines moved or copied from older commits are left out, as they weren't introduced by this one.
+		if attribution != nil {
+			ok, err := attribution.filter(diff)
+			if err != nil {
+				logger.V(1).Info("unable to attribute lines, scanning the whole diff", "error", err, "commit", fullHash, "file", fileName)
+			} else if !ok {
+				continue
+			}
+		}
+
 		if diff.Len() > sources.ChunkSize+sources.PeekSize {
 			s.gitChunk(ctx, diff, fileName, email, fullHash, when, remoteURL, reporter)
 			continue
@@ -683,6 +822,20 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 				Data:           data,
 				Verify:         s.verify,
 			}
+
+			// Scan the content of LFS objects rather than their pointer, attributed to the pointer's path.
+			if lfs != nil {
+				if pointer, ok := parseLFSPointer(data); ok {
+					chunkSkel := chunk
+					chunkSkel.Data = nil
+					chunkSkel.SourceMetadata = s.sourceMetadataFunc(fileName, email, fullHash, when, remoteURL, 0)
+					err := s.handleLFSObject(ctx, lfs, pointer, &chunkSkel, fileName, reporter)
+					if err == nil {
+						return nil
+					}
+					ctx.Logger().V(1).Info("error handling LFS object", "error", err, "filename", fileName, "commit", fullHash)
+				}
+			}
 			return reporter.ChunkOk(ctx, chunk)
 		}
 		if err := chunkData(diff); err != nil {
@@ -693,13 +846,42 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 }
 
 func (s *Git) gitChunk(ctx context.Context, diff *gitparse.Diff, fileName, email, hash, when, urlMetadata string, reporter sources.ChunkReporter) {
+	metadata := func(line int64) *source_metadatapb.MetaData {
+		return s.sourceMetadataFunc(fileName, email, hash, when, urlMetadata, line)
+	}
+	// TODO: Return error.
+	_ = s.chunkDiff(ctx, diff, hash, metadata, reporter)
+}
+
+// chunkDiff reads a diff line by line and reports it in chunks of at most ChunkSize bytes, except
+// for longer lines, which are reported on their own. metadata returns the metadata of a chunk
+// starting at the given line.
+func (s *Git) chunkDiff(
+	ctx context.Context,
+	diff *gitparse.Diff,
+	hash string,
+	metadata func(line int64) *source_metadatapb.MetaData,
+	reporter sources.ChunkReporter,
+) error {
 	reader, err := diff.ReadCloser()
 	if err != nil {
-		ctx.Logger().Error(err, "error creating reader for chunk", "filename", fileName, "commit", hash, "file", diff.PathB)
-		return
+		ctx.Logger().Error(err, "error creating reader for chunk", "commit", hash, "file", diff.PathB)
+		return nil
 	}
 	defer reader.Close()
 
+	newChunk := func(line int64, data []byte) sources.Chunk {
+		return sources.Chunk{
+			SourceName:     s.sourceName,
+			SourceID:       s.sourceID,
+			JobID:          s.jobID,
+			SourceType:     s.sourceType,
+			SourceMetadata: metadata(line),
+			Data:           data,
+			Verify:         s.verify,
+		}
+	}
+
 	originalChunk := bufio.NewScanner(reader)
 	newChunkBuffer := bytes.Buffer{}
 	lastOffset := 0
@@ -711,19 +893,9 @@ func (s *Git) gitChunk(ctx context.Context, diff *gitparse.Diff, fileName, email
 			// Add oversize chunk info
 			if newChunkBuffer.Len() > 0 {
 				// Send the existing fragment.
-				metadata := s.sourceMetadataFunc(fileName, email, hash, when, urlMetadata, int64(diff.LineStart+lastOffset))
-				chunk := sources.Chunk{
-					SourceName:     s.sourceName,
-					SourceID:       s.sourceID,
-					JobID:          s.jobID,
-					SourceType:     s.sourceType,
-					SourceMetadata: metadata,
-					Data:           append([]byte{}, newChunkBuffer.Bytes()...),
-					Verify:         s.verify,
-				}
+				chunk := newChunk(int64(diff.LineStart+lastOffset), append([]byte{}, newChunkBuffer.Bytes()...))
 				if err := reporter.ChunkOk(ctx, chunk); err != nil {
-					// TODO: Return error.
-					return
+					return err
 				}
 
 				newChunkBuffer.Reset()
@@ -731,45 +903,25 @@ func (s *Git) gitChunk(ctx context.Context, diff *gitparse.Diff, fileName, email
 			}
 			if len(line) > sources.ChunkSize {
 				// Send the oversize line.
-				metadata := s.sourceMetadataFunc(fileName, email, hash, when, urlMetadata, int64(diff.LineStart+offset))
-				chunk := sources.Chunk{
-					SourceName:     s.sourceName,
-					SourceID:       s.sourceID,
-					JobID:          s.jobID,
-					SourceType:     s.sourceType,
-					SourceMetadata: metadata,
-					Data:           line,
-					Verify:         s.verify,
-				}
-				if err := reporter.ChunkOk(ctx, chunk); err != nil {
-					// TODO: Return error.
-					return
+				if err := reporter.ChunkOk(ctx, newChunk(int64(diff.LineStart+offset), line)); err != nil {
+					return err
 				}
 				continue
 			}
 		}
 
 		if _, err := newChunkBuffer.Write(line); err != nil {
-			ctx.Logger().Error(err, "error writing to chunk buffer", "filename", fileName, "commit", hash, "file", diff.PathB)
+			ctx.Logger().Error(err, "error writing to chunk buffer", "commit", hash, "file", diff.PathB)
 		}
 	}
 	// Send anything still in the new chunk buffer
 	if newChunkBuffer.Len() > 0 {
-		metadata := s.sourceMetadataFunc(fileName, email, hash, when, urlMetadata, int64(diff.LineStart+lastOffset))
-		chunk := sources.Chunk{
-			SourceName:     s.sourceName,
-			SourceID:       s.sourceID,
-			JobID:          s.jobID,
-			SourceType:     s.sourceType,
-			SourceMetadata: metadata,
-			Data:           append([]byte{}, newChunkBuffer.Bytes()...),
-			Verify:         s.verify,
-		}
+		chunk := newChunk(int64(diff.LineStart+lastOffset), append([]byte{}, newChunkBuffer.Bytes()...))
 		if err := reporter.ChunkOk(ctx, chunk); err != nil {
-			// TODO: Return error.
-			return
+			return err
 		}
 	}
+	return nil
 }
 
 // ScanStaged chunks staged changes.
@@ -903,6 +1055,384 @@ func (s *Git) ScanStaged(ctx context.Context, repo *git.Repository, path string,
 	return nil
 }
 
+// ScanWorkingTree chunks the uncommitted changes of the working tree: the
+// changes staged in the index, then the changes not yet added to it, then the
+// files that aren't tracked or ignored. Only the added and modified lines of
+// tracked files are scanned, and binary files are skipped.
+func (s *Git) ScanWorkingTree(ctx context.Context, repo *git.Repository, path string, scanOptions *ScanOptions, reporter sources.ChunkReporter) error {
+	// Get the URL metadata for reporting (may be empty).
+	urlMetadata := getSafeRemoteURL(repo, "origin")
+
+	ctx.Logger().V(1).Info("scanning working tree changes", "path", path)
+
+	for _, staged := range []bool{true, false} {
+		diffs := s.parser.Unstaged
+		if staged {
+			diffs = s.parser.Staged
+		}
+		diffChan, err := diffs(ctx, path)
+		if err != nil {
+			return err
+		}
+		if diffChan == nil {
+			continue
+		}
+
+		for diff := range diffChan {
+			fileName := diff.PathB
+			if fileName == "" || !scanOptions.Filter.Pass(fileName) {
+				continue
+			}
+			if diff.IsBinary {
+				ctx.Logger().V(5).Info("skipping binary file", "file", fileName, "staged", staged)
+				continue
+			}
+
+			metadata := func(line int64) *source_metadatapb.MetaData {
+				return s.workingTreeMetadata(fileName, urlMetadata, line, staged)
+			}
+			if err := s.chunkDiff(ctx, diff, "", metadata, reporter); err != nil {
+				return err
+			}
+		}
+	}
+
+	out, err := exec.CommandContext(ctx, "git", "-C", path, "ls-files", "--others", "--exclude-standard", "-z").Output()
+	if err != nil {
+		return fmt.Errorf("unable to list untracked files: %w", err)
+	}
+	for _, fileName := range strings.Split(string(out), "\x00") {
+		if fileName == "" || !scanOptions.Filter.Pass(fileName) {
+			continue
+		}
+		if err := s.scanUntrackedFile(ctx, path, fileName, urlMetadata, reporter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workingTreeMetadata returns the metadata of a chunk of a working tree change starting at line.
+func (s *Git) workingTreeMetadata(fileName, urlMetadata string, line int64, staged bool) *source_metadatapb.MetaData {
+	metadata := s.sourceMetadataFunc(fileName, "", "", "", urlMetadata, line)
+	if gitMetadata := metadata.GetGit(); gitMetadata != nil {
+		gitMetadata.WorkingTree = true
+		gitMetadata.Staged = staged
+	}
+	return metadata
+}
+
+// binarySniffLen is the length of the beginning of a file that is searched for
+// a NUL byte to tell whether it's binary, as git does.
+const binarySniffLen = 8000
+
+// scanUntrackedFile chunks an untracked file of the working tree as it's read.
+// Binary files, and files that can't be read, are skipped.
+func (s *Git) scanUntrackedFile(ctx context.Context, path, fileName, urlMetadata string, reporter sources.ChunkReporter) error {
+	logger := ctx.Logger().WithValues("file", fileName)
+	f, err := os.Open(filepath.Join(path, fileName))
+	if err != nil {
+		logger.Error(err, "unable to open untracked file")
+		return nil
+	}
+	defer f.Close()
+
+	reader := bufio.NewReaderSize(f, binarySniffLen)
+	if head, _ := reader.Peek(binarySniffLen); bytes.IndexByte(head, 0) >= 0 {
+		logger.V(5).Info("skipping binary file")
+		return nil
+	}
+
+	line := int64(1)
+	for data := range sources.NewChunkReader()(ctx, reader) {
+		if err := data.Error(); err != nil {
+			logger.Error(err, "error reading untracked file")
+			return nil
+		}
+		chunk := sources.Chunk{
+			SourceName:     s.sourceName,
+			SourceID:       s.sourceID,
+			JobID:          s.jobID,
+			SourceType:     s.sourceType,
+			SourceMetadata: s.workingTreeMetadata(fileName, urlMetadata, line, false),
+			Data:           data.Bytes(),
+			Verify:         s.verify,
+		}
+		// Consecutive chunks overlap by the peek size, so only the first ChunkSize bytes
+		// of a chunk precede the next one.
+		line += int64(bytes.Count(data.Bytes()[:min(len(data.Bytes()), sources.ChunkSize)], []byte("\n")))
+		if err := reporter.ChunkOk(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScanNotes scans the notes of every notes ref (refs/notes/*) of the repository. Each note is
+// reported as a chunk attributed to the object it annotates, usually a commit, and to its
+// notes ref. Repositories without notes have nothing to scan.
+func (s *Git) ScanNotes(ctx context.Context, repo *git.Repository, path string, reporter sources.ChunkReporter) error {
+	// Get the URL metadata for reporting (may be empty).
+	urlMetadata := getSafeRemoteURL(repo, "origin")
+
+	notesRefs, err := gitLines(ctx, path, "for-each-ref", "--format=%(refname)", "refs/notes/")
+	if err != nil {
+		return fmt.Errorf("unable to list notes refs: %w", err)
+	}
+
+	for _, notesRef := range notesRefs {
+		logger := ctx.Logger().WithValues("notes_ref", notesRef)
+		logger.V(1).Info("scanning notes")
+
+		// Each line lists the blob of a note and the object it annotates.
+		fields, err := gitLines(ctx, path, "notes", "--ref="+notesRef, "list")
+		if err != nil {
+			logger.Error(err, "unable to list notes")
+			continue
+		}
+		for i := 0; i+1 < len(fields); i += 2 {
+			if common.IsDone(ctx) {
+				return ctx.Err()
+			}
+			noteHash, target := fields[i], fields[i+1]
+			data, err := readBlob(repo, plumbing.NewHash(noteHash))
+			if err != nil {
+				logger.Error(err, "unable to read note", "object", target)
+				continue
+			}
+			if len(bytes.TrimSpace(data)) == 0 {
+				continue
+			}
+
+			// Notes are usually attached to commits, whose author they're attributed to.
+			var email, when string
+			if commit, err := repo.CommitObject(plumbing.NewHash(target)); err == nil {
+				email = commit.Author.String()
+				when = commit.Author.When.String()
+			}
+			metadata := s.sourceMetadataFunc("", email, target, when, urlMetadata, 0)
+			if gitMetadata := metadata.GetGit(); gitMetadata != nil {
+				gitMetadata.NotesRef = notesRef
+			}
+			chunk := sources.Chunk{
+				SourceName:     s.sourceName,
+				SourceID:       s.sourceID,
+				JobID:          s.jobID,
+				SourceType:     s.sourceType,
+				SourceMetadata: metadata,
+				Data:           data,
+				Verify:         s.verify,
+			}
+			if err := reporter.ChunkOk(ctx, chunk); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// emptyTreeHash is the hash of the empty tree. The commit of the untracked files of a stash entry
+// has no parent, so its files are diffed against it.
+const emptyTreeHash = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// ScanStashes scans the entries of the stash (refs/stash). An entry is a commit of the working tree
+// whose parents are the commit it was created on, a commit of the index and, for entries created
+// with --include-untracked, a commit of the untracked files. The changes of the index from the base
+// commit, the changes of the working tree from the index and the untracked files are reported as
+// chunks attributed to their commit and to the entry, e.g. stash@{0}, and its message.
+// Repositories without a stash have nothing to scan.
+func (s *Git) ScanStashes(ctx context.Context, repo *git.Repository, path string, scanOptions *ScanOptions, reporter sources.ChunkReporter) error {
+	// Get the URL metadata for reporting (may be empty).
+	urlMetadata := getSafeRemoteURL(repo, "origin")
+
+	stashRefs, err := gitLines(ctx, path, "for-each-ref", "--format=%(refname)", "refs/stash")
+	if err != nil {
+		return fmt.Errorf("unable to list the stash: %w", err)
+	}
+	if len(stashRefs) == 0 {
+		return nil
+	}
+
+	// Each line lists the commit of an entry and its message, from stash@{0} on.
+	out, err := exec.CommandContext(ctx, "git", "-C", path, "log", "--walk-reflogs", "--format=%H %gs", "refs/stash").Output()
+	if err != nil {
+		return fmt.Errorf("unable to list the stash entries: %w", err)
+	}
+	for i, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		hash, message, _ := strings.Cut(line, " ")
+		stash := fmt.Sprintf("stash@{%d}", i)
+		logger := ctx.Logger().WithValues("stash", stash)
+		logger.V(1).Info("scanning stash entry")
+
+		commit, err := repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			logger.Error(err, "unable to read stash entry")
+			continue
+		}
+		if commit.NumParents() < 2 {
+			logger.V(1).Info("skipping stash entry without an index commit")
+			continue
+		}
+		index := commit.ParentHashes[1].String()
+		changes := [][2]string{{commit.ParentHashes[0].String(), index}, {index, hash}}
+		if commit.NumParents() > 2 {
+			changes = append(changes, [2]string{emptyTreeHash, commit.ParentHashes[2].String()})
+		}
+
+		email := commit.Author.String()
+		when := commit.Author.When.String()
+		for _, change := range changes {
+			diffChan, err := s.parser.Diff(ctx, path, change[0], change[1])
+			if err != nil {
+				return err
+			}
+			for diff := range diffChan {
+				fileName := diff.PathB
+				if fileName == "" || !scanOptions.Filter.Pass(fileName) {
+					continue
+				}
+				metadata := func(line int64) *source_metadatapb.MetaData {
+					metadata := s.sourceMetadataFunc(fileName, email, change[1], when, urlMetadata, line)
+					if gitMetadata := metadata.GetGit(); gitMetadata != nil {
+						gitMetadata.Stash = stash
+						gitMetadata.StashMessage = message
+					}
+					return metadata
+				}
+
+				if diff.IsBinary {
+					chunkSkel := &sources.Chunk{
+						SourceName:     s.sourceName,
+						SourceID:       s.sourceID,
+						JobID:          s.jobID,
+						SourceType:     s.sourceType,
+						SourceMetadata: metadata(int64(diff.LineStart)),
+						Verify:         s.verify,
+					}
+					if err := s.handleBinary(ctx, path, reporter, chunkSkel, plumbing.NewHash(change[1]), fileName); err != nil {
+						logger.V(1).Info("error handling binary file", "error", err, "filename", fileName)
+					}
+					continue
+				}
+
+				// Stash changes are chunked as they're read, like large commit diffs.
+				if err := s.chunkDiff(ctx, diff, change[1], metadata, reporter); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// readBlob returns the content of the blob with the given hash.
+func readBlob(repo *git.Repository, hash plumbing.Hash) ([]byte, error) {
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// logRevisions returns the revisions to pass to `git log` for the given scan
+// options. When refs are configured, only commits reachable from them are
+// selected, excluding anything reachable from the base commit.
+func logRevisions(repo *git.Repository, scanOptions *ScanOptions) ([]string, error) {
+	if len(scanOptions.Refs) == 0 {
+		if scanOptions.HeadHash != "" {
+			return []string{scanOptions.HeadHash}, nil
+		}
+		return []string{"--all"}, nil
+	}
+
+	revisions, err := resolveRefs(repo, scanOptions.Refs)
+	if err != nil {
+		return nil, err
+	}
+	if scanOptions.BaseHash != "" {
+		revisions = append(revisions, "^"+scanOptions.BaseHash)
+	}
+	return revisions, nil
+}
+
+// recentCommits returns up to maxDepth of the most recent commits reachable
+// from each of the revisions returned by logRevisions, newest first. Each
+// revision gets its own budget of commits, and commits excluded by a
+// "^<commit>" revision are left out. "--all" stands for every ref and HEAD.
+func recentCommits(ctx context.Context, path string, revisions []string, maxDepth int64) ([]string, error) {
+	var tips, exclusions []string
+	for _, rev := range revisions {
+		switch {
+		case rev == "--all":
+			refs, err := gitLines(ctx, path, "for-each-ref", "--format=%(objectname)")
+			if err != nil {
+				return nil, fmt.Errorf("unable to list refs: %w", err)
+			}
+			tips = append(tips, refs...)
+			// HEAD may be detached, and doesn't exist in empty repositories.
+			if head, err := gitLines(ctx, path, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+				tips = append(tips, head...)
+			}
+		case strings.HasPrefix(rev, "^"):
+			exclusions = append(exclusions, rev)
+		default:
+			tips = append(tips, rev)
+		}
+	}
+
+	var commits []string
+	seen := make(map[string]struct{})
+	for _, tip := range tips {
+		args := append([]string{"rev-list", fmt.Sprintf("--max-count=%d", maxDepth), tip}, exclusions...)
+		hashes, err := gitLines(ctx, path, args...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list commits of %s: %w", tip, err)
+		}
+		for _, hash := range hashes {
+			if _, ok := seen[hash]; ok {
+				continue
+			}
+			seen[hash] = struct{}{}
+			commits = append(commits, hash)
+		}
+	}
+	return commits, nil
+}
+
+// gitLines runs a git command in the repository at path and returns the
+// non-empty lines of its output.
+func gitLines(ctx context.Context, path string, args ...string) ([]string, error) {
+	out, err := exec.CommandContext(ctx, "git", append([]string{"-C", path}, args...)...).Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// resolveRefs resolves each ref to a commit hash. If any ref can't be
+// resolved, it returns an error listing all of them.
+func resolveRefs(repo *git.Repository, refs []string) ([]string, error) {
+	hashes := make([]string, 0, len(refs))
+	var unresolved []string
+	for _, ref := range refs {
+		hash, err := resolveHash(repo, ref)
+		if err != nil {
+			unresolved = append(unresolved, ref)
+			continue
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(unresolved) > 0 {
+		return nil, fmt.Errorf("unable to resolve refs: %s", strings.Join(unresolved, ", "))
+	}
+	return hashes, nil
+}
+
 func (s *Git) ScanRepo(ctx context.Context, repo *git.Repository, repoPath string, scanOptions *ScanOptions, reporter sources.ChunkReporter) error {
 	if scanOptions == nil {
 		scanOptions = NewScanOptions()
@@ -912,9 +1442,26 @@ func (s *Git) ScanRepo(ctx context.Context, repo *git.Repository, repoPath strin
 	}
 	start := time.Now().Unix()
 
+	if scanOptions.WorkingTreeOnly {
+		if scanOptions.Bare {
+			return fmt.Errorf("a bare repository has no working tree to scan")
+		}
+		return s.ScanWorkingTree(ctx, repo, repoPath, scanOptions, reporter)
+	}
+
 	if err := s.ScanCommits(ctx, repo, repoPath, scanOptions, reporter); err != nil {
 		return err
 	}
+	if scanOptions.Notes {
+		if err := s.ScanNotes(ctx, repo, repoPath, reporter); err != nil {
+			return err
+		}
+	}
+	if scanOptions.Stashes {
+		if err := s.ScanStashes(ctx, repo, repoPath, scanOptions, reporter); err != nil {
+			return err
+		}
+	}
 	if !scanOptions.Bare {
 		if err := s.ScanStaged(ctx, repo, repoPath, scanOptions, reporter); err != nil {
 			ctx.Logger().V(1).Info("error scanning unstaged changes", "error", err)
@@ -1242,7 +1789,7 @@ func (s *Git) handleBinary(ctx context.Context, gitDir string, reporter sources.
 		return fmt.Errorf("error starting git cat-file: %w\n%s", err, stderr.Bytes())
 	}
 
-	return handlers.HandleFile(fileCtx, stdout, chunkSkel, reporter, handlers.WithSkipArchives(s.skipArchives))
+	return handlers.HandleFile(fileCtx, stdout, chunkSkel, reporter, handlers.WithSkipArchives(s.skipArchives), handlers.WithFileName(path))
 }
 
 func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) error {
@@ -1264,6 +1811,9 @@ func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) e
 			return err
 		}
 	}
+	if s.conn.GetIncludeSubmodules() {
+		return s.enumerateSubmodules(ctx, reporter)
+	}
 	return nil
 }
 
@@ -1275,6 +1825,12 @@ func (s *Source) ChunkUnit(ctx context.Context, unit sources.SourceUnit, reporte
 		return s.scanRepo(ctx, unitID, reporter)
 	case UnitDir:
 		return s.scanDir(ctx, unitID, reporter)
+	case UnitSubmodule:
+		gitUnit, ok := unit.(SourceUnit)
+		if !ok {
+			return fmt.Errorf("unexpected git unit type: %T", unit)
+		}
+		return s.scanSubmodule(ctx, gitUnit, reporter)
 	default:
 		return fmt.Errorf("unexpected git unit kind: %q", kind)
 	}
@@ -0,0 +1,233 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// lfsPointerMaxSize is the largest size of a Git LFS pointer file.
+	lfsPointerMaxSize = 1024
+	// defaultMaxLFSObjectSize is the size above which Git LFS objects aren't
+	// downloaded, unless configured otherwise.
+	defaultMaxLFSObjectSize = 100 << 20 // 100 MB
+
+	lfsMediaType = "application/vnd.git-lfs+json"
+)
+
+var lfsOIDPat = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// lfsPointer identifies a Git LFS object.
+type lfsPointer struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// parseLFSPointer parses data as a Git LFS pointer file.
+// See https://github.com/git-lfs/git-lfs/blob/main/docs/spec.md.
+// Diffs only hold the lines added by a commit, so the version line, which
+// doesn't change when a pointer is updated, isn't required.
+func parseLFSPointer(data []byte) (lfsPointer, bool) {
+	if len(data) > lfsPointerMaxSize {
+		return lfsPointer{}, false
+	}
+
+	var pointer lfsPointer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			return lfsPointer{}, false
+		}
+		switch key {
+		case "version":
+			if !strings.HasPrefix(value, "https://git-lfs.github.com/spec/") {
+				return lfsPointer{}, false
+			}
+		case "oid":
+			oid, ok := strings.CutPrefix(value, "sha256:")
+			if !ok || !lfsOIDPat.MatchString(oid) {
+				return lfsPointer{}, false
+			}
+			pointer.OID = oid
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return lfsPointer{}, false
+			}
+			pointer.Size = size
+		default:
+			// Extension lines are allowed, anything else isn't a pointer.
+			if !strings.HasPrefix(key, "ext-") {
+				return lfsPointer{}, false
+			}
+		}
+	}
+	return pointer, pointer.OID != "" && scanner.Err() == nil
+}
+
+// lfsClient downloads objects from the Git LFS server of a repository using
+// the batch API. See https://github.com/git-lfs/git-lfs/blob/main/docs/api/batch.md.
+type lfsClient struct {
+	// endpoint is the LFS server URL, without credentials.
+	endpoint *url.URL
+	user     *url.Userinfo
+	client   *http.Client
+	maxSize  int64
+}
+
+// newLFSClient returns a client of the LFS server of the remote of repo,
+// authenticated with the credentials of the remote URL. Only HTTP remotes
+// are supported.
+func newLFSClient(repo *git.Repository, maxSize int64) (*lfsClient, error) {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		remotes, err := repo.Remotes()
+		if err != nil || len(remotes) == 0 {
+			return nil, fmt.Errorf("repository has no remote")
+		}
+		remote = remotes[0]
+	}
+
+	endpoint, err := url.Parse(remote.Config().URLs[0])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing remote URL: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported remote URL scheme %q", endpoint.Scheme)
+	}
+	user := endpoint.User
+	endpoint.User = nil
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/")
+	if !strings.HasSuffix(endpoint.Path, ".git") {
+		endpoint.Path += ".git"
+	}
+	endpoint.Path += "/info/lfs"
+
+	if maxSize <= 0 {
+		maxSize = defaultMaxLFSObjectSize
+	}
+	return &lfsClient{endpoint: endpoint, user: user, client: common.RetryableHTTPClient(), maxSize: maxSize}, nil
+}
+
+type lfsBatchResponse struct {
+	Objects []struct {
+		lfsPointer
+		Actions struct {
+			Download *struct {
+				Href   string            `json:"href"`
+				Header map[string]string `json:"header"`
+			} `json:"download"`
+		} `json:"actions"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"objects"`
+}
+
+// setAuth sets the credentials of the remote on requests to the LFS server.
+func (c *lfsClient) setAuth(req *http.Request) {
+	if c.user == nil || req.URL.Host != c.endpoint.Host {
+		return
+	}
+	password, _ := c.user.Password()
+	req.SetBasicAuth(c.user.Username(), password)
+}
+
+// download returns the content of the object identified by pointer.
+func (c *lfsClient) download(ctx context.Context, pointer lfsPointer) (io.ReadCloser, error) {
+	body, err := json.Marshal(map[string]any{
+		"operation": "download",
+		"transfers": []string{"basic"},
+		"objects":   []lfsPointer{pointer},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint.String()+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	c.setAuth(req)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting LFS batch API: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected LFS batch API status: %d", res.StatusCode)
+	}
+	var batch lfsBatchResponse
+	if err := json.NewDecoder(res.Body).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("error decoding LFS batch API response: %w", err)
+	}
+	if len(batch.Objects) != 1 {
+		return nil, fmt.Errorf("unexpected number of LFS objects: %d", len(batch.Objects))
+	}
+	object := batch.Objects[0]
+	if object.Error != nil {
+		return nil, fmt.Errorf("LFS object error %d: %s", object.Error.Code, object.Error.Message)
+	}
+	if object.Actions.Download == nil {
+		return nil, fmt.Errorf("LFS object has no download action")
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, object.Actions.Download.Href, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range object.Actions.Download.Header {
+		req.Header.Set(key, value)
+	}
+	if req.Header.Get("Authorization") == "" {
+		c.setAuth(req)
+	}
+	res, err = c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading LFS object: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("unexpected LFS download status: %d", res.StatusCode)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(res.Body, c.maxSize), res.Body}, nil
+}
+
+// handleLFSObject downloads the object identified by pointer and reports its
+// chunks. Objects larger than the configured maximum size are skipped.
+func (s *Git) handleLFSObject(ctx context.Context, lfs *lfsClient, pointer lfsPointer, chunkSkel *sources.Chunk, path string, reporter sources.ChunkReporter) error {
+	fileCtx := context.WithValues(ctx, "path", path, "lfs_oid", pointer.OID)
+	if pointer.Size > lfs.maxSize {
+		fileCtx.Logger().V(2).Info("skipping LFS object above the maximum size", "size", pointer.Size, "max_size", lfs.maxSize)
+		return nil
+	}
+
+	object, err := lfs.download(fileCtx, pointer)
+	if err != nil {
+		return err
+	}
+	fileCtx.Logger().V(5).Info("scanning LFS object", "size", pointer.Size)
+	return handlers.HandleFile(fileCtx, object, chunkSkel, reporter, handlers.WithSkipArchives(s.skipArchives), handlers.WithFileName(path))
+}
@@ -0,0 +1,119 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sourcestest"
+)
+
+const testLFSOID = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+
+func TestParseLFSPointer(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		want   lfsPointer
+		wantOK bool
+	}{
+		{
+			name:   "pointer",
+			data:   "version https://git-lfs.github.com/spec/v1\noid sha256:" + testLFSOID + "\nsize 12345\n",
+			want:   lfsPointer{OID: testLFSOID, Size: 12345},
+			wantOK: true,
+		},
+		{
+			name:   "updated pointer without version line",
+			data:   "oid sha256:" + testLFSOID + "\nsize 42\n",
+			want:   lfsPointer{OID: testLFSOID, Size: 42},
+			wantOK: true,
+		},
+		{name: "text", data: "password = hunter2\n"},
+		{name: "invalid oid", data: "version https://git-lfs.github.com/spec/v1\noid sha256:1234\nsize 1\n"},
+		{name: "other content", data: "oid sha256:" + testLFSOID + "\nsize 42\ntoken abc\n"},
+		{name: "too large", data: "oid sha256:" + testLFSOID + "\nsize 42\n" + strings.Repeat("ext-0-foo sha256:"+testLFSOID+"\n", 20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseLFSPointer([]byte(tt.data))
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleLFSObject(t *testing.T) {
+	const content = "aws_access_key_id = AKIAEXAMPLE"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		switch r.URL.Path {
+		case "/org/repo.git/info/lfs/objects/batch":
+			assert.Equal(t, "user", user)
+			assert.Equal(t, "token", password)
+			var req struct {
+				Operation string       `json:"operation"`
+				Objects   []lfsPointer `json:"objects"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "download", req.Operation)
+			w.Header().Set("Content-Type", lfsMediaType)
+			fmt.Fprintf(w, `{"objects": [{"oid": %q, "size": %d, "actions": {"download": {"href": "http://%s/objects/%s", "header": {"Authorization": "RemoteAuth signed"}}}}]}`,
+				req.Objects[0].OID, req.Objects[0].Size, r.Host, req.Objects[0].OID)
+		case "/objects/" + testLFSOID:
+			assert.Equal(t, "RemoteAuth signed", r.Header.Get("Authorization"))
+			fmt.Fprint(w, content)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	repo, err := git.PlainInit(t.TempDir(), false)
+	assert.NoError(t, err)
+	remoteURL := strings.Replace(server.URL, "http://", "http://user:token@", 1) + "/org/repo"
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteURL}})
+	assert.NoError(t, err)
+
+	lfs, err := newLFSClient(repo, 1024)
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/org/repo.git/info/lfs", lfs.endpoint.String())
+
+	s := NewGit(&Config{Concurrency: 1})
+	reporter := sourcestest.TestReporter{}
+	err = s.handleLFSObject(context.Background(), lfs, lfsPointer{OID: testLFSOID, Size: int64(len(content))}, &sources.Chunk{}, "secrets.txt", &reporter)
+	assert.NoError(t, err)
+	if assert.Len(t, reporter.Chunks, 1) {
+		assert.Equal(t, content, string(reporter.Chunks[0].Data))
+	}
+
+	// Objects above the maximum size aren't downloaded.
+	reporter = sourcestest.TestReporter{}
+	err = s.handleLFSObject(context.Background(), lfs, lfsPointer{OID: testLFSOID, Size: 4096}, &sources.Chunk{}, "large.bin", &reporter)
+	assert.NoError(t, err)
+	assert.Empty(t, reporter.Chunks)
+}
+
+func TestNewLFSClientUnsupportedRemote(t *testing.T) {
+	repo, err := git.PlainInit(t.TempDir(), false)
+	assert.NoError(t, err)
+	_, err = newLFSClient(repo, 0)
+	assert.Error(t, err)
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"git@github.com:org/repo.git"}})
+	assert.NoError(t, err)
+	_, err = newLFSClient(repo, 0)
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package git
 
 import (
+	"time"
+
 	"github.com/go-git/go-git/v5"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 )
@@ -9,10 +11,25 @@ type ScanOptions struct {
 	Filter       *common.Filter
 	BaseHash     string // When scanning a git.Log, this is the oldest/first commit.
 	HeadHash     string
-	MaxDepth     int64
+	MaxDepth     int64 // When positive, only this many of the most recent commits of each ref are scanned.
 	Bare         bool
 	ExcludeGlobs []string
+	Refs         []string // When set, only commits reachable from these refs are scanned.
 	LogOptions   *git.LogOptions
+	// CommitMessages scans each commit message as its own chunk rather than
+	// as part of the commit metadata.
+	CommitMessages bool
+	// WorkingTreeOnly scans only the staged and unstaged changes of the
+	// working tree instead of the history of the repository.
+	WorkingTreeOnly bool
+	// Notes scans the notes of the notes refs along with the commits.
+	Notes bool
+	// Stashes scans the changes of the entries of the stash along with the commits.
+	Stashes bool
+	// SinceDate, when set, only scans the lines introduced by commits authored
+	// at or after it. Lines are attributed to the commit that introduced them
+	// with git blame, following moves and copies, which is slow.
+	SinceDate time.Time
 }
 
 type ScanOption func(*ScanOptions)
@@ -47,6 +64,42 @@ func ScanOptionExcludeGlobs(globs []string) ScanOption {
 	}
 }
 
+func ScanOptionRefs(refs []string) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.Refs = refs
+	}
+}
+
+func ScanOptionCommitMessages(commitMessages bool) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.CommitMessages = commitMessages
+	}
+}
+
+func ScanOptionWorkingTreeOnly(workingTreeOnly bool) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.WorkingTreeOnly = workingTreeOnly
+	}
+}
+
+func ScanOptionNotes(notes bool) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.Notes = notes
+	}
+}
+
+func ScanOptionStashes(stashes bool) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.Stashes = stashes
+	}
+}
+
+func ScanOptionSinceDate(since time.Time) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.SinceDate = since
+	}
+}
+
 func ScanOptionLogOptions(logOptions *git.LogOptions) ScanOption {
 	return func(scanOptions *ScanOptions) {
 		scanOptions.LogOptions = logOptions
@@ -0,0 +1,266 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// enumerateSubmodules reports a unit for every submodule of the configured directories and
+// repositories. Each unit scans the submodule at its checked-out commit, which for remote
+// repositories is the commit recorded by the parent, and its chunks carry the parent repository and submodule path in their metadata. Submodules are deduplicated by
+// their resolved remote, so a remote is only reported once, and never when it is one of the
+// configured repositories.
+func (s *Source) enumerateSubmodules(ctx context.Context, reporter sources.UnitReporter) error {
+	seen := make(map[string]struct{})
+	for _, repo := range s.conn.GetRepositories() {
+		seen[repo] = struct{}{}
+	}
+	report := func(units []SourceUnit, remotes []string) error {
+		for i, unit := range units {
+			if _, ok := seen[remotes[i]]; ok {
+				ctx.Logger().V(2).Info("skipping duplicate submodule", "parent", unit.Parent, "submodule", unit.Path, "remote", remotes[i])
+				continue
+			}
+			seen[remotes[i]] = struct{}{}
+			if err := reporter.UnitOk(ctx, unit); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, dir := range s.conn.GetDirectories() {
+		if dir == "" {
+			continue
+		}
+		if s.scanOptions.Bare {
+			ctx.Logger().V(2).Info("skipping submodules of bare repository", "path", dir)
+			continue
+		}
+		units, remotes, err := s.dirSubmodules(ctx, dir)
+		if err != nil {
+			if err := reporter.UnitErr(ctx, fmt.Errorf("error listing submodules of %s: %w", dir, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := report(units, remotes); err != nil {
+			return err
+		}
+	}
+
+	for _, repoURI := range s.conn.GetRepositories() {
+		if repoURI == "" {
+			continue
+		}
+		units, remotes, err := s.repoSubmodules(ctx, repoURI)
+		if err != nil {
+			if err := reporter.UnitErr(ctx, fmt.Errorf("error listing submodules of %s: %w", repoURI, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := report(units, remotes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dirSubmodules returns the units of the initialized submodules of the repository checked out at
+// dir, along with their resolved remotes. The units point at the local checkouts of the submodules.
+func (s *Source) dirSubmodules(ctx context.Context, dir string) ([]SourceUnit, []string, error) {
+	repo, err := RepoFromPath(dir, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	parentURL := getSafeRemoteURL(repo, "origin")
+	parent := parentURL
+	if parent == "" {
+		parent = dir
+	}
+
+	submodules, err := listSubmodules(ctx, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		units   []SourceUnit
+		remotes []string
+	)
+	for _, submodule := range submodules {
+		logger := ctx.Logger().WithValues("parent", parent, "submodule", submodule.Path)
+		subDir := filepath.Join(dir, submodule.Path)
+		if _, err := os.Stat(filepath.Join(subDir, ".git")); err != nil {
+			logger.V(2).Info("skipping uninitialized submodule")
+			continue
+		}
+		commit, err := gitLines(ctx, subDir, "rev-parse", "HEAD")
+		if err != nil || len(commit) == 0 {
+			logger.V(1).Info("error resolving submodule commit", "error", err)
+			continue
+		}
+		remote, err := resolveSubmoduleURL(parentURL, dir, submodule.URL)
+		if err != nil {
+			logger.V(1).Info("error resolving submodule url", "error", err, "url", submodule.URL)
+			continue
+		}
+		units = append(units, SourceUnit{Kind: UnitSubmodule, ID: subDir, Parent: parent, Path: submodule.Path, Commit: commit[0]})
+		remotes = append(remotes, remote)
+	}
+	return units, remotes, nil
+}
+
+// repoSubmodules returns the units of the submodules of the remote repository repoURI, along with
+// their resolved remotes. The units point at the remotes of the submodules, which are cloned when
+// the units are scanned. Only the trees of the parent are fetched to list its submodules.
+func (s *Source) repoSubmodules(ctx context.Context, repoURI string) ([]SourceUnit, []string, error) {
+	path, _, err := s.clone(ctx, repoURI, "--no-checkout", "--filter=blob:none")
+	defer os.RemoveAll(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	submodules, err := listSubmodules(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		units   []SourceUnit
+		remotes []string
+	)
+	for _, submodule := range submodules {
+		if submodule.commit == "" {
+			ctx.Logger().V(2).Info("skipping submodule without a commit", "parent", repoURI, "submodule", submodule.Path)
+			continue
+		}
+		remote, err := resolveSubmoduleURL(repoURI, path, submodule.URL)
+		if err != nil {
+			ctx.Logger().V(1).Info("error resolving submodule url", "parent", repoURI, "submodule", submodule.Path, "error", err, "url", submodule.URL)
+			continue
+		}
+		units = append(units, SourceUnit{Kind: UnitSubmodule, ID: remote, Parent: repoURI, Path: submodule.Path, Commit: submodule.commit})
+		remotes = append(remotes, remote)
+	}
+	return units, remotes, nil
+}
+
+// submodule is a submodule declared in .gitmodules, along with the commit its parent records for it.
+type submodule struct {
+	*config.Submodule
+	commit string
+}
+
+// listSubmodules returns the submodules declared in the .gitmodules of the HEAD commit of the
+// repository at repoPath. A repository without a .gitmodules has no submodules.
+func listSubmodules(ctx context.Context, repoPath string) ([]submodule, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", repoPath, "show", "HEAD:.gitmodules")
+	out, err := cmd.Output()
+	if err != nil {
+		ctx.Logger().V(3).Info("no .gitmodules found", "path", repoPath, "error", err)
+		return nil, nil
+	}
+	modules := config.NewModules()
+	if err := modules.Unmarshal(out); err != nil {
+		return nil, fmt.Errorf("error reading .gitmodules: %w", err)
+	}
+	if len(modules.Submodules) == 0 {
+		return nil, nil
+	}
+
+	args := []string{"-C", repoPath, "ls-tree", "-z", "HEAD", "--"}
+	for _, cfg := range modules.Submodules {
+		args = append(args, cfg.Path)
+	}
+	out, err = exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing submodule commits: %w", err)
+	}
+	// Entries are "<mode> <type> <object>\t<path>"; submodules are recorded as commits.
+	commits := make(map[string]string)
+	for _, entry := range bytes.Split(out, []byte{0}) {
+		info, entryPath, ok := strings.Cut(string(entry), "\t")
+		if fields := strings.Fields(info); ok && len(fields) == 3 && fields[1] == "commit" {
+			commits[entryPath] = fields[2]
+		}
+	}
+
+	submodules := make([]submodule, 0, len(modules.Submodules))
+	for _, cfg := range modules.Submodules {
+		if err := cfg.Validate(); err != nil {
+			ctx.Logger().V(1).Info("skipping invalid submodule", "path", repoPath, "submodule", cfg.Name, "error", err)
+			continue
+		}
+		submodules = append(submodules, submodule{Submodule: cfg, commit: commits[cfg.Path]})
+	}
+	return submodules, nil
+}
+
+// scanSubmodule scans a submodule unit at its commit. Submodules checked out locally are scanned
+// in place, others are cloned.
+func (s *Source) scanSubmodule(ctx context.Context, unit SourceUnit, reporter sources.ChunkReporter) error {
+	err := func() error {
+		var (
+			path = unit.ID
+			repo *git.Repository
+			err  error
+		)
+		if info, statErr := os.Stat(unit.ID); statErr == nil && info.IsDir() {
+			repo, err = RepoFromPath(unit.ID, false)
+		} else {
+			path, repo, err = s.clone(ctx, unit.ID)
+			defer os.RemoveAll(path)
+		}
+		if err != nil {
+			return err
+		}
+
+		subOptions := *s.scanOptions
+		subOptions.BaseHash = ""
+		subOptions.HeadHash = unit.Commit
+		subOptions.Refs = nil
+
+		subCfg := *s.gitConfig
+		subCfg.SourceMetadataFunc = gitMetadataFunc(unit.Parent, unit.Path)
+
+		ctx.Logger().V(1).Info("scanning submodule", "parent", unit.Parent, "submodule", unit.Path, "commit", unit.Commit)
+		return NewGit(&subCfg).ScanRepo(ctx, repo, path, &subOptions, reporter)
+	}()
+	if err != nil {
+		return reporter.ChunkErr(ctx, fmt.Errorf("error scanning submodule %s: %w", unit.Path, err))
+	}
+	return nil
+}
+
+// resolveSubmoduleURL returns the absolute URL of a submodule. Relative submodule URLs (./ or ../)
+// are resolved against the parent's remote URL, or against the parent's path when it has no remote,
+// matching the behavior of `git submodule`.
+func resolveSubmoduleURL(parentURL, parentPath, submoduleURL string) (string, error) {
+	if !strings.HasPrefix(submoduleURL, "./") && !strings.HasPrefix(submoduleURL, "../") {
+		return submoduleURL, nil
+	}
+
+	if parentURL == "" {
+		return filepath.Join(parentPath, submoduleURL), nil
+	}
+
+	u, err := GitURLParse(parentURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, submoduleURL)
+	return u.String(), nil
+}
@@ -0,0 +1,204 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sourcestest"
+)
+
+func TestResolveSubmoduleURL(t *testing.T) {
+	tests := map[string]struct {
+		parentURL  string
+		parentPath string
+		url        string
+		want       string
+	}{
+		"absolute": {
+			parentURL: "https://github.com/org/parent.git",
+			url:       "https://github.com/org/child.git",
+			want:      "https://github.com/org/child.git",
+		},
+		"relative sibling": {
+			parentURL: "https://github.com/org/parent.git",
+			url:       "../child.git",
+			want:      "https://github.com/org/child.git",
+		},
+		"relative nested": {
+			parentURL: "https://github.com/org/parent.git",
+			url:       "./child.git",
+			want:      "https://github.com/org/parent.git/child.git",
+		},
+		"relative scp-like parent": {
+			parentURL: "git@github.com:org/parent.git",
+			url:       "../child.git",
+			want:      "ssh://git@github.com/org/child.git",
+		},
+		"relative without remote": {
+			parentPath: "/tmp/repos/parent",
+			url:        "../child",
+			want:       "/tmp/repos/child",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolveSubmoduleURL(tt.parentURL, tt.parentPath, tt.url)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-c", "protocol.file.allow=always", "-C", dir}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func initRepo(t *testing.T, dir, file, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "init", "--quiet")
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "--quiet", "-m", "initial")
+}
+
+func TestSource_ScanSubmodules(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+
+	initRepo(t, filepath.Join(root, "child"), "child.txt", "submodule secret")
+	initRepo(t, filepath.Join(root, "uninit"), "uninit.txt", "uninitialized secret")
+	initRepo(t, filepath.Join(root, "parent"), "parent.txt", "parent content")
+
+	parent := filepath.Join(root, "parent")
+	runGit(t, parent, "submodule", "add", "--quiet", filepath.Join(root, "child"), "vendor/child")
+	runGit(t, parent, "submodule", "add", "--quiet", filepath.Join(root, "child"), "vendor/child-copy")
+	runGit(t, parent, "submodule", "add", "--quiet", filepath.Join(root, "uninit"), "vendor/uninit")
+	runGit(t, parent, "commit", "--quiet", "-m", "add submodules")
+	runGit(t, parent, "submodule", "deinit", "--quiet", "vendor/uninit")
+
+	conn, err := anypb.New(&sourcespb.Git{
+		Directories:       []string{parent},
+		IncludeSubmodules: true,
+	})
+	assert.NoError(t, err)
+
+	s := Source{}
+	err = s.Init(ctx, "test submodules", 0, 0, false, conn, 1)
+	assert.NoError(t, err)
+
+	// Both initialized submodules point at the same remote, so only one of them is
+	// enumerated, and the uninitialized one is skipped.
+	units := sourcestest.TestReporter{}
+	err = s.Enumerate(ctx, &units)
+	assert.NoError(t, err)
+	assert.Empty(t, units.UnitErrs)
+	var submodules []SourceUnit
+	for _, unit := range units.Units {
+		if unit.(SourceUnit).Kind == UnitSubmodule {
+			submodules = append(submodules, unit.(SourceUnit))
+		}
+	}
+	if !assert.Len(t, submodules, 1) {
+		return
+	}
+	submodule := submodules[0]
+	assert.Contains(t, []string{"vendor/child", "vendor/child-copy"}, submodule.Path)
+	assert.Equal(t, filepath.Join(parent, submodule.Path), submodule.ID)
+	assert.Equal(t, parent, submodule.Parent)
+	assert.Len(t, submodule.Commit, 40)
+
+	// The parent unit no longer includes the chunks of its submodules.
+	reporter := sourcestest.TestReporter{}
+	err = s.ChunkUnit(ctx, SourceUnit{ID: parent, Kind: UnitDir}, &reporter)
+	assert.NoError(t, err)
+	assert.Empty(t, reporter.ChunkErrs)
+	for _, chunk := range reporter.Chunks {
+		assert.Empty(t, chunk.SourceMetadata.GetGit().GetSubmodulePath())
+	}
+
+	reporter = sourcestest.TestReporter{}
+	err = s.ChunkUnit(ctx, submodule, &reporter)
+	assert.NoError(t, err)
+	assert.Empty(t, reporter.ChunkErrs)
+	var found bool
+	for _, chunk := range reporter.Chunks {
+		meta := chunk.SourceMetadata.GetGit()
+		assert.Equal(t, parent, meta.GetParentRepository())
+		assert.Equal(t, submodule.Path, meta.GetSubmodulePath())
+		found = found || meta.GetFile() == "child.txt"
+	}
+	assert.True(t, found)
+}
+
+func TestSource_EnumerateRepoSubmodules(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+
+	initRepo(t, filepath.Join(root, "child"), "child.txt", "submodule secret")
+	initRepo(t, filepath.Join(root, "parent"), "parent.txt", "parent content")
+
+	parent := filepath.Join(root, "parent")
+	runGit(t, parent, "submodule", "add", "--quiet", "../child", "vendor/child")
+	runGit(t, parent, "commit", "--quiet", "-m", "add submodule")
+
+	parentURL := "file://" + parent
+	conn, err := anypb.New(&sourcespb.Git{
+		Repositories:      []string{parentURL},
+		IncludeSubmodules: true,
+		Credential:        &sourcespb.Git_Unauthenticated{},
+	})
+	assert.NoError(t, err)
+
+	s := Source{}
+	err = s.Init(ctx, "test repo submodules", 0, 0, false, conn, 1)
+	assert.NoError(t, err)
+
+	units := sourcestest.TestReporter{}
+	err = s.Enumerate(ctx, &units)
+	assert.NoError(t, err)
+	assert.Empty(t, units.UnitErrs)
+	if !assert.Len(t, units.Units, 2) {
+		return
+	}
+	// Relative submodule URLs resolve against the remote of the parent.
+	submodule := units.Units[1].(SourceUnit)
+	assert.Equal(t, UnitSubmodule, submodule.Kind)
+	assert.Equal(t, "file://"+filepath.Join(root, "child"), submodule.ID)
+	assert.Equal(t, parentURL, submodule.Parent)
+	assert.Equal(t, "vendor/child", submodule.Path)
+
+	reporter := sourcestest.TestReporter{}
+	err = s.ChunkUnit(ctx, submodule, &reporter)
+	assert.NoError(t, err)
+	assert.Empty(t, reporter.ChunkErrs)
+	var found bool
+	for _, chunk := range reporter.Chunks {
+		meta := chunk.SourceMetadata.GetGit()
+		assert.Equal(t, parentURL, meta.GetParentRepository())
+		assert.Equal(t, "vendor/child", meta.GetSubmodulePath())
+		found = found || string(chunk.Data) == "submodule secret\n"
+	}
+	assert.True(t, found)
+}
@@ -11,18 +11,26 @@ import (
 )
 
 const (
-	UnitRepo sources.SourceUnitKind = "repo"
-	UnitDir  sources.SourceUnitKind = "dir"
+	UnitRepo      sources.SourceUnitKind = "repo"
+	UnitDir       sources.SourceUnitKind = "dir"
+	UnitSubmodule sources.SourceUnitKind = "submodule"
 )
 
 // Ensure SourceUnit implements the interface at compile time.
 var _ sources.SourceUnit = SourceUnit{}
 
-// A git source unit can be two kinds of units: either a local directory path
-// or a remote repository.
+// A git source unit can be three kinds of units: a local directory path, a
+// remote repository, or a submodule of either. The ID of a submodule is the
+// path of its local checkout or its remote.
 type SourceUnit struct {
 	Kind sources.SourceUnitKind `json:"kind"`
 	ID   string                 `json:"id"`
+
+	// Submodule units are scanned at Commit and attributed to the submodule
+	// at Path within the Parent repository.
+	Parent string `json:"parent,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Commit string `json:"commit,omitempty"`
 }
 
 // Implement sources.SourceUnit interface.
@@ -47,6 +55,12 @@ func (u SourceUnit) Display() string {
 		return strings.TrimSuffix(repo, ".git")
 	case UnitDir:
 		return filepath.Base(u.ID)
+	case UnitSubmodule:
+		parent := SourceUnit{Kind: UnitRepo, ID: u.Parent}
+		if filepath.IsAbs(u.Parent) {
+			parent.Kind = UnitDir
+		}
+		return parent.Display() + " -> " + u.Path
 	default:
 		return "mysterious git unit"
 	}
@@ -58,7 +72,7 @@ func UnmarshalUnit(data []byte) (sources.SourceUnit, error) {
 	if err := json.Unmarshal(data, &unit); err != nil {
 		return nil, err
 	}
-	if unit.ID == "" || (unit.Kind != UnitRepo && unit.Kind != UnitDir) {
+	if unit.ID == "" || (unit.Kind != UnitRepo && unit.Kind != UnitDir && unit.Kind != UnitSubmodule) {
 		return nil, fmt.Errorf("not a git.SourceUnit")
 	}
 	return unit, nil
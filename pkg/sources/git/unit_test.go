@@ -40,3 +40,24 @@ func TestDisplayUnit(t *testing.T) {
 	unit = SourceUnit{ID: "ssh://github.com/trufflesecurity/test_keys", Kind: UnitRepo}
 	assert.Equal(t, "trufflesecurity/test_keys", unit.Display())
 }
+
+func TestSubmoduleUnit(t *testing.T) {
+	unit := SourceUnit{
+		Kind:   UnitSubmodule,
+		ID:     "https://github.com/trufflesecurity/child.git",
+		Parent: "https://github.com/trufflesecurity/parent.git",
+		Path:   "vendor/child",
+		Commit: "0123456789abcdef0123456789abcdef01234567",
+	}
+	b, err := json.Marshal(unit)
+	assert.NoError(t, err)
+
+	gotUnit, err := UnmarshalUnit(b)
+	assert.NoError(t, err)
+	assert.Equal(t, unit, gotUnit)
+
+	assert.Equal(t, "trufflesecurity/parent -> vendor/child", unit.Display())
+
+	unit.Parent = "/path/to/parent"
+	assert.Equal(t, "parent -> vendor/child", unit.Display())
+}
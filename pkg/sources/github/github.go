@@ -70,6 +70,9 @@ type Source struct {
 	scanOptMu   sync.Mutex // protects the scanOptions
 	scanOptions *git.ScanOptions
 
+	// commitStore records the last scanned commit of each repository for incremental scans.
+	commitStore *commitStore
+
 	httpClient      *http.Client
 	log             logr.Logger
 	conn            *sourcespb.GitHub
@@ -82,6 +85,10 @@ type Source struct {
 	includeIssueComments bool
 	includeGistComments  bool
 
+	// unitErrFunc is called with the repositories, organizations and users
+	// that fail and are skipped, if it's set.
+	unitErrFunc func(unit sources.SourceUnit, err error)
+
 	sources.Progress
 	sources.CommonSourceUnitUnmarshaller
 }
@@ -103,6 +110,24 @@ func (s *Source) setScanOptions(base, head string) {
 // Ensure the Source satisfies the interfaces at compile time
 var _ sources.Source = (*Source)(nil)
 var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.UnitErrorSetter = (*Source)(nil)
+
+// unitAccount is the kind of the units of organizations and users.
+const unitAccount sources.SourceUnitKind = "account"
+
+// SetUnitErrorFunc sets the function the repositories, organizations and users
+// that fail to be enumerated or scanned are reported to.
+func (s *Source) SetUnitErrorFunc(f func(unit sources.SourceUnit, err error)) {
+	s.unitErrFunc = f
+}
+
+// reportUnitError reports a repository, organization or user that failed and
+// is skipped.
+func (s *Source) reportUnitError(kind sources.SourceUnitKind, id string, err error) {
+	if s.unitErrFunc != nil {
+		s.unitErrFunc(sources.CommonSourceUnit{Kind: kind, ID: id}, err)
+	}
+}
 
 var endsWithGithub = regexp.MustCompile(`github\.com/?$`)
 
@@ -239,6 +264,25 @@ func (s *Source) Init(aCtx context.Context, name string, jobID sources.JobID, so
 		return fmt.Errorf("cannot specify head or base with multiple repositories")
 	}
 
+	if s.conn.GetPullRequest() > 0 {
+		if len(s.repos) != 1 {
+			return fmt.Errorf("cannot specify a pull request without exactly one repository")
+		}
+		if len(s.conn.Head) > 0 || len(s.conn.Base) > 0 {
+			return fmt.Errorf("cannot specify head or base with a pull request")
+		}
+	}
+
+	if stateFile := s.conn.GetIncrementalStateFile(); stateFile != "" {
+		if len(s.conn.Head) > 0 || len(s.conn.Base) > 0 {
+			return fmt.Errorf("cannot specify head or base with incremental scanning")
+		}
+		s.commitStore, err = loadCommitStore(stateFile)
+		if err != nil {
+			return err
+		}
+	}
+
 	cfg := &git.Config{
 		SourceName:   s.name,
 		JobID:        s.jobID,
@@ -418,6 +462,7 @@ RepoLoop:
 			_, urlParts, err := getRepoURLParts(repo)
 			if err != nil {
 				repoCtx.Logger().Error(err, "Failed to parse repository URL")
+				s.reportUnitError(git.UnitRepo, repo, err)
 				continue
 			}
 
@@ -434,6 +479,7 @@ RepoLoop:
 					}
 					if err != nil {
 						repoCtx.Logger().Error(err, "Failed to fetch gist")
+						s.reportUnitError(git.UnitRepo, repo, err)
 						continue RepoLoop
 					}
 					s.cacheGistInfo(gist)
@@ -448,6 +494,7 @@ RepoLoop:
 					}
 					if err != nil {
 						repoCtx.Logger().Error(err, "Failed to fetch repository")
+						s.reportUnitError(git.UnitRepo, repo, err)
 						continue RepoLoop
 					}
 					s.cacheRepoInfo(ghRepo)
@@ -481,6 +528,7 @@ func (s *Source) enumerateBasicAuth(ctx context.Context, apiEndpoint string, bas
 		userType, err := s.getReposByOrgOrUser(ctx, org)
 		if err != nil {
 			orgCtx.Logger().Error(err, "error fetching repos for org or user")
+			s.reportUnitError(unitAccount, org, err)
 			continue
 		}
 
@@ -509,6 +557,7 @@ func (s *Source) enumerateUnauthenticated(ctx context.Context, apiEndpoint strin
 		userType, err := s.getReposByOrgOrUser(ctx, org)
 		if err != nil {
 			orgCtx.Logger().Error(err, "error fetching repos for org or user")
+			s.reportUnitError(unitAccount, org, err)
 			continue
 		}
 
@@ -578,6 +627,7 @@ func (s *Source) enumerateWithToken(ctx context.Context, apiEndpoint, token stri
 			userType, err := s.getReposByOrgOrUser(ctx, org)
 			if err != nil {
 				orgCtx.Logger().Error(err, "Unable to fetch repos for org or user")
+				s.reportUnitError(unitAccount, org, err)
 				continue
 			}
 
@@ -699,6 +749,9 @@ func (s *Source) scan(ctx context.Context, installationClient *github.Client, ch
 	if s.scanOptions == nil {
 		s.scanOptions = &git.ScanOptions{}
 	}
+	if s.conn.GetScanCommitMessages() {
+		s.scanOptions.CommitMessages = true
+	}
 
 	for i, repoURL := range s.repos {
 		i, repoURL := i, repoURL
@@ -717,7 +770,9 @@ func (s *Source) scan(ctx context.Context, installationClient *github.Client, ch
 			}(s, repoURL)
 
 			if !strings.HasSuffix(repoURL, ".git") {
-				scanErrs.Add(fmt.Errorf("repo %s does not end in .git", repoURL))
+				err := fmt.Errorf("repo %s does not end in .git", repoURL)
+				scanErrs.Add(err)
+				s.reportUnitError(git.UnitRepo, repoURL, err)
 				return nil
 			}
 
@@ -730,23 +785,39 @@ func (s *Source) scan(ctx context.Context, installationClient *github.Client, ch
 				return nil
 			}
 			repoCtx := context.WithValues(ctx, "repo", repoURL)
-			duration, err := s.cloneAndScanRepo(repoCtx, installationClient, repoURL, repoInfo, chunksChan)
+
+			// Only scan the lines added by the pull request, if one is configured.
+			var (
+				duration time.Duration
+				head     string
+				err      error
+			)
+			if s.conn.GetPullRequest() > 0 {
+				err = s.scanPullRequest(repoCtx, repoURL, repoInfo, chunksChan)
+			} else {
+				duration, head, err = s.cloneAndScanRepo(repoCtx, installationClient, repoURL, repoInfo, chunksChan)
+			}
 			if err != nil {
 				scanErrs.Add(err)
+				s.reportUnitError(git.UnitRepo, repoURL, err)
 				return nil
 			}
 
 			// Scan the wiki, if enabled, and the repo has one.
-			if s.conn.IncludeWikis && repoInfo.hasWiki && s.wikiIsReachable(ctx, repoURL) {
+			if s.conn.GetPullRequest() == 0 && s.conn.IncludeWikis && repoInfo.hasWiki && s.wikiIsReachable(ctx, repoURL) {
 				wikiURL := strings.TrimSuffix(repoURL, ".git") + ".wiki.git"
 				wikiCtx := context.WithValue(ctx, "repo", wikiURL)
 
-				_, err := s.cloneAndScanRepo(wikiCtx, installationClient, wikiURL, repoInfo, chunksChan)
+				_, wikiHead, err := s.cloneAndScanRepo(wikiCtx, installationClient, wikiURL, repoInfo, chunksChan)
+				if err == nil && wikiHead != "" {
+					s.commitStore.set(wikiURL, wikiHead)
+				}
 				if err != nil {
 					// Ignore "Repository not found" errors.
 					// It's common for GitHub's API to say a repo has a wiki when it doesn't.
 					if !strings.Contains(err.Error(), "not found") {
 						scanErrs.Add(fmt.Errorf("error scanning wiki: %w", err))
+						s.reportUnitError(git.UnitRepo, wikiURL, err)
 					}
 
 					// Don't return, it still might be possible to scan comments.
@@ -756,11 +827,16 @@ func (s *Source) scan(ctx context.Context, installationClient *github.Client, ch
 			// Scan comments, if enabled.
 			if s.includeGistComments || s.includeIssueComments || s.includePRComments {
 				if err = s.scanComments(repoCtx, repoURL, repoInfo, chunksChan); err != nil {
-					scanErrs.Add(fmt.Errorf("error scanning comments in repo %s: %w", repoURL, err))
+					err = fmt.Errorf("error scanning comments in repo %s: %w", repoURL, err)
+					scanErrs.Add(err)
+					s.reportUnitError(git.UnitRepo, repoURL, err)
 					return nil
 				}
 			}
 
+			if head != "" {
+				s.commitStore.set(repoURL, head)
+			}
 			repoCtx.Logger().V(2).Info(fmt.Sprintf("scanned %d/%d repos", scannedCount, len(s.repos)), "duration_seconds", duration)
 			githubReposScanned.WithLabelValues(s.name).Inc()
 			atomic.AddUint64(&scannedCount, 1)
@@ -772,23 +848,36 @@ func (s *Source) scan(ctx context.Context, installationClient *github.Client, ch
 	if scanErrs.Count() > 0 {
 		s.log.V(0).Info("failed to scan some repositories", "error_count", scanErrs.Count(), "errors", scanErrs.String())
 	}
+	// Only record the scanned commits once every repository has been processed, and not at
+	// all if the scan was interrupted, so an aborted scan never skips unscanned commits.
+	if s.commitStore != nil && ctx.Err() == nil {
+		if err := s.commitStore.save(); err != nil {
+			s.log.Error(err, "unable to record scanned commits")
+		}
+	}
 	s.SetProgressComplete(len(s.repos), len(s.repos), "Completed GitHub scan", "")
 
 	return nil
 }
 
-func (s *Source) cloneAndScanRepo(ctx context.Context, client *github.Client, repoURL string, repoInfo repoInfo, chunksChan chan *sources.Chunk) (time.Duration, error) {
+// cloneAndScanRepo clones and scans the history of repoURL. For incremental scans it also returns
+// the HEAD commit to record once everything in the repository has been scanned.
+func (s *Source) cloneAndScanRepo(ctx context.Context, client *github.Client, repoURL string, repoInfo repoInfo, chunksChan chan *sources.Chunk) (time.Duration, string, error) {
 	var duration time.Duration
 
 	ctx.Logger().V(2).Info("attempting to clone repo")
 	path, repo, err := s.cloneRepo(ctx, repoURL, client)
 	if err != nil {
-		return duration, err
+		return duration, "", err
 	}
 	defer os.RemoveAll(path)
 
 	// TODO: Can this be set once or does it need to be set on every iteration? Is |s.scanOptions| set every clone?
 	s.setScanOptions(s.conn.Base, s.conn.Head)
+	scanOptions, head := s.scanOptions, ""
+	if s.commitStore != nil {
+		scanOptions, head = s.incrementalScanOptions(ctx, repoURL, repo)
+	}
 
 	// Repo size is not collected for wikis.
 	var logger logr.Logger
@@ -800,11 +889,11 @@ func (s *Source) cloneAndScanRepo(ctx context.Context, client *github.Client, re
 	logger.V(2).Info("scanning repo")
 
 	start := time.Now()
-	if err = s.git.ScanRepo(ctx, repo, path, s.scanOptions, sources.ChanReporter{Ch: chunksChan}); err != nil {
-		return duration, fmt.Errorf("error scanning repo %s: %w", repoURL, err)
+	if err = s.git.ScanRepo(ctx, repo, path, scanOptions, sources.ChanReporter{Ch: chunksChan}); err != nil {
+		return duration, "", fmt.Errorf("error scanning repo %s: %w", repoURL, err)
 	}
 	duration = time.Since(start)
-	return duration, nil
+	return duration, head, nil
 }
 
 var (
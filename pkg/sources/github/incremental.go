@@ -0,0 +1,152 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+// commitStore records the HEAD commit of each repository at the time it was
+// last scanned, so subsequent scans only need to process newer commits.
+// The state is persisted to a JSON file keyed by repository URL.
+type commitStore struct {
+	path string
+
+	mu      sync.Mutex
+	commits map[string]string
+	// scanned holds the commits of the repositories scanned successfully
+	// since the store was loaded. They are only persisted by save.
+	scanned map[string]string
+}
+
+// loadCommitStore reads the commit store persisted at path. A missing file
+// results in an empty store.
+func loadCommitStore(path string) (*commitStore, error) {
+	store := &commitStore{path: path, commits: make(map[string]string), scanned: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("unable to read incremental state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.commits); err != nil {
+		return nil, fmt.Errorf("unable to parse incremental state file %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// get returns the commit of repoURL recorded by the previous scan, if any.
+func (c *commitStore) get(repoURL string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.commits[repoURL]
+}
+
+// set records commit as the last scanned commit of repoURL. It must only be
+// called once everything in the repository has been scanned successfully, and
+// the commit is only persisted by save.
+func (c *commitStore) set(repoURL, commit string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scanned[repoURL] = commit
+}
+
+// save persists the commits recorded by set along with the state of the
+// repositories that weren't scanned.
+func (c *commitStore) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for repoURL, commit := range c.scanned {
+		c.commits[repoURL] = commit
+	}
+	clear(c.scanned)
+	data, err := json.Marshal(c.commits)
+	if err != nil {
+		return fmt.Errorf("unable to marshal incremental state: %w", err)
+	}
+
+	// Write to a temporary file and rename it so an interruption never leaves
+	// a partially written state file behind.
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("unable to create incremental state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("unable to write incremental state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write incremental state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("unable to replace incremental state file: %w", err)
+	}
+	return nil
+}
+
+// incrementalScanOptions returns the scan options for an incremental scan of
+// repoURL and the HEAD commit to record once the scan of the repository succeeds. If a commit was
+// recorded by a previous scan, only the commits between it and HEAD are scanned.
+// If the recorded commit is no longer reachable from HEAD, for example after a
+// force-push, the full history is scanned instead.
+func (s *Source) incrementalScanOptions(ctx context.Context, repoURL string, repo *gogit.Repository) (*git.ScanOptions, string) {
+	s.scanOptMu.Lock()
+	scanOptions := *s.scanOptions
+	s.scanOptMu.Unlock()
+
+	ref, err := repo.Head()
+	if err != nil {
+		ctx.Logger().V(1).Info("unable to resolve HEAD, scanning full history", "error", err)
+		return &scanOptions, ""
+	}
+	head := ref.Hash().String()
+
+	last := s.commitStore.get(repoURL)
+	if last == "" {
+		return &scanOptions, head
+	}
+	if !isAncestor(repo, last, ref.Hash()) {
+		ctx.Logger().Info("previously scanned commit is no longer reachable, scanning full history", "commit", last)
+		return &scanOptions, head
+	}
+
+	ctx.Logger().V(2).Info("scanning commits since last scan", "commit", last)
+	scanOptions.BaseHash = last
+	scanOptions.HeadHash = head
+	return &scanOptions, head
+}
+
+// isAncestor returns whether the commit with hash base is head or one of its ancestors.
+func isAncestor(repo *gogit.Repository, base string, head plumbing.Hash) bool {
+	if !plumbing.IsHash(base) {
+		return false
+	}
+	baseCommit, err := repo.CommitObject(plumbing.NewHash(base))
+	if err != nil {
+		return false
+	}
+	if baseCommit.Hash == head {
+		return true
+	}
+	headCommit, err := repo.CommitObject(head)
+	if err != nil {
+		return false
+	}
+	ok, err := baseCommit.IsAncestor(headCommit)
+	return err == nil && ok
+}
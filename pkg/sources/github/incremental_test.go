@@ -0,0 +1,109 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+func commitFile(t *testing.T, repo *gogit.Repository, dir, name, content string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add(name); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := wt.Commit("add "+name, &gogit.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
+
+func TestCommitStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	store, err := loadCommitStore(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "", store.get("https://github.com/org/repo.git"))
+
+	// Recorded commits are neither visible nor persisted until the store is saved.
+	store.set("https://github.com/org/repo.git", "abc")
+	assert.Equal(t, "", store.get("https://github.com/org/repo.git"))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Nil(t, store.save())
+	reloaded, err := loadCommitStore(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", reloaded.get("https://github.com/org/repo.git"))
+
+	// Saving keeps the state of repositories that weren't scanned again.
+	reloaded.set("https://github.com/org/other.git", "def")
+	assert.Nil(t, reloaded.save())
+	reloaded, err = loadCommitStore(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", reloaded.get("https://github.com/org/repo.git"))
+	assert.Equal(t, "def", reloaded.get("https://github.com/org/other.git"))
+
+	assert.Nil(t, os.WriteFile(path, []byte("not json"), 0644))
+	_, err = loadCommitStore(path)
+	assert.NotNil(t, err)
+}
+
+func TestIncrementalScanOptions(t *testing.T) {
+	ctx := context.Background()
+	const repoURL = "https://github.com/org/repo.git"
+
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := commitFile(t, repo, dir, "a.txt", "a")
+	second := commitFile(t, repo, dir, "b.txt", "b")
+
+	store, err := loadCommitStore(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Source{scanOptions: &git.ScanOptions{}, commitStore: store}
+
+	// No recorded commit scans the full history.
+	opts, head := s.incrementalScanOptions(ctx, repoURL, repo)
+	assert.Equal(t, second, head)
+	assert.Equal(t, "", opts.BaseHash)
+	assert.Equal(t, "", opts.HeadHash)
+
+	// A reachable recorded commit limits the scan to newer commits.
+	store.set(repoURL, first)
+	assert.Nil(t, store.save())
+	opts, head = s.incrementalScanOptions(ctx, repoURL, repo)
+	assert.Equal(t, second, head)
+	assert.Equal(t, first, opts.BaseHash)
+	assert.Equal(t, second, opts.HeadHash)
+	// The shared scan options are not modified.
+	assert.Equal(t, "", s.scanOptions.BaseHash)
+
+	// An unreachable recorded commit falls back to a full scan.
+	store.set(repoURL, "0123456789abcdef0123456789abcdef01234567")
+	assert.Nil(t, store.save())
+	opts, head = s.incrementalScanOptions(ctx, repoURL, repo)
+	assert.Equal(t, second, head)
+	assert.Equal(t, "", opts.BaseHash)
+}
@@ -0,0 +1,193 @@
+package github
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v62/github"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/giturl"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// addedRegion is a run of consecutive lines added by a patch.
+type addedRegion struct {
+	// line is the line number of the first added line in the new version of the file.
+	line int64
+	data string
+}
+
+// addedRegions returns the runs of added lines in a unified diff patch, as
+// returned by the GitHub API for a pull request file. Removed and context
+// lines are dropped, so only content introduced by the patch is returned.
+func addedRegions(patch string) []addedRegion {
+	var (
+		regions []addedRegion
+		current *addedRegion
+		sb      strings.Builder
+		newLine int64
+	)
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.data = sb.String()
+		regions = append(regions, *current)
+		current = nil
+		sb.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(patch))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), len(patch)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			flush()
+			newLine = hunkNewStart(line)
+		case strings.HasPrefix(line, "+"):
+			if current == nil {
+				current = &addedRegion{line: newLine}
+			}
+			sb.WriteString(line[1:])
+			sb.WriteString("\n")
+			newLine++
+		case strings.HasPrefix(line, "-"):
+			flush()
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" doesn't belong to either version of the file.
+		default:
+			flush()
+			newLine++
+		}
+	}
+	flush()
+	return regions
+}
+
+// hunkNewStart parses the starting line number of the new file from a hunk
+// header like "@@ -1,4 +1,5 @@".
+func hunkNewStart(header string) int64 {
+	fields := strings.Fields(header)
+	for _, field := range fields[1:] {
+		if !strings.HasPrefix(field, "+") {
+			continue
+		}
+		start, _, _ := strings.Cut(field[1:], ",")
+		n, err := strconv.ParseInt(start, 10, 64)
+		if err != nil {
+			return 0
+		}
+		return n
+	}
+	return 0
+}
+
+// scanPullRequest scans only the lines added by the configured pull request
+// of the repository, rather than the repository's history.
+func (s *Source) scanPullRequest(ctx context.Context, repoURL string, repoInfo repoInfo, chunksChan chan *sources.Chunk) error {
+	number := int(s.conn.GetPullRequest())
+
+	var (
+		pr  *github.PullRequest
+		err error
+	)
+	for {
+		pr, _, err = s.apiClient.PullRequests.Get(ctx, repoInfo.owner, repoInfo.name, number)
+		if s.handleRateLimit(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error getting pull request %d: %w", number, err)
+		}
+		break
+	}
+
+	headSHA := pr.GetHead().GetSHA()
+	opts := &github.ListOptions{PerPage: defaultPagination, Page: initialPage}
+	for {
+		files, _, err := s.apiClient.PullRequests.ListFiles(ctx, repoInfo.owner, repoInfo.name, number, opts)
+		if s.handleRateLimit(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error listing files of pull request %d: %w", number, err)
+		}
+
+		for _, file := range files {
+			if err := s.chunkPullRequestFile(ctx, repoURL, repoInfo, pr, headSHA, file, chunksChan); err != nil {
+				return err
+			}
+		}
+
+		opts.Page++
+		if len(files) < defaultPagination {
+			break
+		}
+	}
+	return nil
+}
+
+// chunkPullRequestFile sends a chunk for each region of lines the pull request
+// adds to file.
+func (s *Source) chunkPullRequestFile(
+	ctx context.Context,
+	repoURL string,
+	repoInfo repoInfo,
+	pr *github.PullRequest,
+	headSHA string,
+	file *github.CommitFile,
+	chunksChan chan *sources.Chunk,
+) error {
+	fileName := file.GetFilename()
+	if file.GetStatus() == "removed" {
+		return nil
+	}
+	if s.scanOptions != nil && s.scanOptions.Filter != nil && !s.scanOptions.Filter.Pass(fileName) {
+		return nil
+	}
+	// The API omits the patch of binary files and of diffs that are too large.
+	patch := file.GetPatch()
+	if patch == "" {
+		ctx.Logger().V(2).Info("skipping pull request file without a patch", "file", fileName)
+		return nil
+	}
+
+	for _, region := range addedRegions(patch) {
+		chunk := &sources.Chunk{
+			SourceName: s.name,
+			SourceID:   s.SourceID(),
+			SourceType: s.Type(),
+			JobID:      s.JobID(),
+			SourceMetadata: &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Github{
+					Github: &source_metadatapb.Github{
+						Link:        giturl.GenerateLink(repoURL, headSHA, fileName, region.line),
+						Username:    sanitizer.UTF8(pr.GetUser().GetLogin()),
+						Repository:  sanitizer.UTF8(repoURL),
+						Commit:      sanitizer.UTF8(headSHA),
+						File:        sanitizer.UTF8(fileName),
+						Timestamp:   sanitizer.UTF8(pr.GetUpdatedAt().String()),
+						Line:        region.line,
+						Visibility:  repoInfo.visibility,
+						PullRequest: int64(pr.GetNumber()),
+					},
+				},
+			},
+			Data:   []byte(sanitizer.UTF8(region.data)),
+			Verify: s.verify,
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case chunksChan <- chunk:
+		}
+	}
+	return nil
+}
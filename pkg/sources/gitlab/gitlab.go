@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -32,6 +33,8 @@ const SourceType = sourcespb.SourceType_SOURCE_TYPE_GITLAB
 // This is the URL for gitlab hosted at gitlab.com
 const gitlabBaseURL = "https://gitlab.com/"
 
+const paginationLimit = 100 // Default is 20, max is 100.
+
 type Source struct {
 	name     string
 	sourceID sources.SourceID
@@ -45,6 +48,17 @@ type Source struct {
 	url         string
 	repos       []string
 	ignoreRepos []string
+	// groups are the IDs or full paths of the groups whose projects are
+	// enumerated, including those of their subgroups. Every project visible
+	// to the user is enumerated if it is empty.
+	groups []string
+	// skipArchived skips archived projects during enumeration.
+	skipArchived bool
+	// lastActivityAfter skips projects without activity since this time
+	// during enumeration, if set.
+	lastActivityAfter time.Time
+
+	scanMergeRequests bool
 
 	useCustomContentWriter bool
 	git                    *git.Git
@@ -56,6 +70,11 @@ type Source struct {
 
 	jobPool *errgroup.Group
 	sources.CommonSourceUnitUnmarshaller
+
+	// apiClientOnce creates the API client shared by the units of the source.
+	apiClientOnce sync.Once
+	apiClient     *gitlab.Client
+	apiClientErr  error
 }
 
 // WithCustomContentWriter sets the useCustomContentWriter flag on the source.
@@ -102,6 +121,12 @@ func (s *Source) Init(ctx context.Context, name string, jobId sources.JobID, sou
 
 	s.repos = conn.Repositories
 	s.ignoreRepos = conn.IgnoreRepos
+	s.scanMergeRequests = conn.GetScanMergeRequests()
+	s.groups = conn.GetGroups()
+	s.skipArchived = conn.GetSkipArchived()
+	if after := conn.GetLastActivityAfter(); after != nil {
+		s.lastActivityAfter = after.AsTime()
+	}
 	ctx.Logger().V(3).Info("setting ignore repos patterns", "patterns", s.ignoreRepos)
 
 	switch cred := conn.GetCredential().(type) {
@@ -209,7 +234,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, tar
 	// We must sort the repos so we can resume later if necessary.
 	slices.Sort(s.repos)
 
-	return s.scanRepos(ctx, chunksChan)
+	return s.scanRepos(ctx, apiClient, chunksChan)
 }
 
 func (s *Source) scanTargets(ctx context.Context, client *gitlab.Client, targets []sources.ChunkingTarget, chunksChan chan *sources.Chunk) error {
@@ -337,6 +362,13 @@ func (s *Source) Validate(ctx context.Context) []error {
 	return errs
 }
 
+// sharedClient returns the API client of the source, which is created once and
+// shared by every unit.
+func (s *Source) sharedClient() (*gitlab.Client, error) {
+	s.apiClientOnce.Do(func() { s.apiClient, s.apiClientErr = s.newClient() })
+	return s.apiClient, s.apiClientErr
+}
+
 func (s *Source) newClient() (*gitlab.Client, error) {
 	// Initialize a new api instance.
 	switch s.authMethod {
@@ -387,8 +419,9 @@ func (s *Source) basicAuthSuccessful(apiClient *gitlab.Client) bool {
 }
 
 // getAllProjectRepos enumerates all GitLab projects using the provided API
-// client. The reporter is used to report the valid repository found for
-// projects that are not ignored.
+// client, or only those of the configured groups and their subgroups if any
+// are set. The reporter is used to report the valid repository found for
+// projects that are not ignored, archived or inactive, if configured so.
 func (s *Source) getAllProjectRepos(
 	ctx context.Context,
 	apiClient *gitlab.Client,
@@ -423,6 +456,14 @@ func (s *Source) getAllProjectRepos(
 				ctx.Logger().V(3).Info("skipping project", "reason", "ignored in config")
 				continue
 			}
+			if s.skipArchived && proj.Archived {
+				ctx.Logger().V(3).Info("skipping project", "reason", "archived")
+				continue
+			}
+			if !s.lastActivityAfter.IsZero() && proj.LastActivityAt != nil && proj.LastActivityAt.Before(s.lastActivityAfter) {
+				ctx.Logger().V(3).Info("skipping project", "reason", "inactive", "last_activity_at", proj.LastActivityAt)
+				continue
+			}
 			// Record that we've seen this project.
 			uniqueProjects[proj.ID] = proj
 			// Report an error if we could not convert the project into a URL.
@@ -449,13 +490,33 @@ func (s *Source) getAllProjectRepos(
 		return nil
 	}
 
-	const (
-		orderBy         = "last_activity_at"
-		paginationLimit = 100 // Default is 20, max is 100.
-	)
+	const orderBy = "last_activity_at"
 	listOpts := gitlab.ListOptions{PerPage: paginationLimit}
 
-	projectQueryOptions := &gitlab.ListProjectsOptions{OrderBy: gitlab.Ptr(orderBy), ListOptions: listOpts}
+	// Archived projects are only listed if they aren't skipped.
+	var archived *bool
+	if s.skipArchived {
+		archived = gitlab.Ptr(false)
+	}
+
+	// Only the projects of the configured groups are enumerated if there are any.
+	if len(s.groups) > 0 {
+		groups, err := s.getConfiguredGroups(ctx, apiClient, reporter)
+		if err != nil {
+			return err
+		}
+		if err := s.getGroupsProjects(ctx, apiClient, groups, archived, processProjects, reporter); err != nil {
+			return err
+		}
+		ctx.Logger().Info("Enumerated GitLab projects", "count", len(projectsWithNamespace))
+		ctx.Logger().V(2).Info("Enumerated GitLab projects", "projects", projectsWithNamespace)
+		return nil
+	}
+
+	projectQueryOptions := &gitlab.ListProjectsOptions{OrderBy: gitlab.Ptr(orderBy), ListOptions: listOpts, Archived: archived}
+	if !s.lastActivityAfter.IsZero() {
+		projectQueryOptions.LastActivityAfter = gitlab.Ptr(s.lastActivityAfter)
+	}
 	for {
 		userProjects, res, err := apiClient.Projects.ListUserProjects(user.ID, projectQueryOptions)
 		if err != nil {
@@ -513,14 +574,57 @@ func (s *Source) getAllProjectRepos(
 	ctx.Logger().Info("got groups", "group_count", len(groups))
 	ctx.Logger().V(2).Info("got groups", "groups", groups)
 
+	if err := s.getGroupsProjects(ctx, apiClient, groups, archived, processProjects, reporter); err != nil {
+		return err
+	}
+
+	ctx.Logger().Info("Enumerated GitLab projects", "count", len(projectsWithNamespace))
+	ctx.Logger().V(2).Info("Enumerated GitLab projects", "projects", projectsWithNamespace)
+
+	return nil
+}
+
+// getConfiguredGroups looks up the groups configured to be scanned. Groups
+// that can't be found are reported as unit errors.
+func (s *Source) getConfiguredGroups(ctx context.Context, apiClient *gitlab.Client, reporter sources.UnitReporter) ([]*gitlab.Group, error) {
+	gitlabGroupsEnumerated.WithLabelValues(s.name).Set(0)
+
+	groups := make([]*gitlab.Group, 0, len(s.groups))
+	for _, id := range s.groups {
+		group, _, err := apiClient.Groups.GetGroup(id, &gitlab.GetGroupOptions{WithProjects: gitlab.Ptr(false)}, gitlab.WithContext(ctx))
+		if err != nil {
+			err = fmt.Errorf("could not get group %q: %w", id, err)
+			if err := reporter.UnitErr(ctx, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		groups = append(groups, group)
+		gitlabGroupsEnumerated.WithLabelValues(s.name).Inc()
+	}
+	return groups, nil
+}
+
+// getGroupsProjects lists the projects of every group, including the projects
+// of their subgroups and the projects shared with them, and passes each page
+// to processProjects.
+func (s *Source) getGroupsProjects(
+	ctx context.Context,
+	apiClient *gitlab.Client,
+	groups []*gitlab.Group,
+	archived *bool,
+	processProjects func([]*gitlab.Project) error,
+	reporter sources.UnitReporter,
+) error {
 	for _, group := range groups {
 		listGroupProjectOptions := &gitlab.ListGroupProjectsOptions{
-			ListOptions:      listOpts,
-			OrderBy:          gitlab.Ptr(orderBy),
+			ListOptions:      gitlab.ListOptions{PerPage: paginationLimit},
+			OrderBy:          gitlab.Ptr("last_activity_at"),
 			IncludeSubGroups: gitlab.Ptr(true),
+			Archived:         archived,
 		}
 		for {
-			grpPrjs, res, err := apiClient.Groups.ListGroupProjects(group.ID, listGroupProjectOptions)
+			grpPrjs, res, err := apiClient.Groups.ListGroupProjects(group.ID, listGroupProjectOptions, gitlab.WithContext(ctx))
 			if err != nil {
 				err = fmt.Errorf(
 					"received error on listing group projects for %q, you probably don't have permissions to do that: %w",
@@ -531,7 +635,7 @@ func (s *Source) getAllProjectRepos(
 				}
 				break
 			}
-			ctx.Logger().V(3).Info("listed group projects", "count", len(grpPrjs))
+			ctx.Logger().V(3).Info("listed group projects", "group", group.FullPath, "count", len(grpPrjs))
 			if err := processProjects(grpPrjs); err != nil {
 				return err
 			}
@@ -541,14 +645,10 @@ func (s *Source) getAllProjectRepos(
 			}
 		}
 	}
-
-	ctx.Logger().Info("Enumerated GitLab projects", "count", len(projectsWithNamespace))
-	ctx.Logger().V(2).Info("Enumerated GitLab projects", "projects", projectsWithNamespace)
-
 	return nil
 }
 
-func (s *Source) scanRepos(ctx context.Context, chunksChan chan *sources.Chunk) error {
+func (s *Source) scanRepos(ctx context.Context, apiClient *gitlab.Client, chunksChan chan *sources.Chunk) error {
 	// If there is resume information available, limit this scan to only the repos that still need scanning.
 	reposToScan, progressIndexOffset := sources.FilterReposToResume(s.repos, s.GetProgress().EncodedResumeInfo)
 	ctx.Logger().V(2).Info("filtered repos to resume", "before", len(s.repos), "after", len(reposToScan))
@@ -604,6 +704,12 @@ func (s *Source) scanRepos(ctx context.Context, chunksChan chan *sources.Chunk)
 				scanErrs.Add(err)
 				return nil
 			}
+			if s.scanMergeRequests {
+				if err := s.scanProjectMergeRequests(ctx, apiClient, repoURL, sources.ChanReporter{Ch: chunksChan}); err != nil {
+					scanErrs.Add(err)
+					return nil
+				}
+			}
 			gitlabReposScanned.WithLabelValues(s.name).Inc()
 
 			logger.V(2).Info("completed scan", "num", i+1, "total", len(s.repos))
@@ -722,11 +828,11 @@ func normalizeGitlabEndpoint(gitlabEndpoint string) (string, error) {
 
 // Enumerate reports all GitLab repositories to be scanned to the reporter. If
 // none are configured, it will find all repositories within all projects that
-// the configured user has access to, while respecting the configured ignore
-// rules.
+// the configured user has access to, or within the configured groups, while
+// respecting the configured ignore rules.
 func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) error {
 	// Start client.
-	apiClient, err := s.newClient()
+	apiClient, err := s.sharedClient()
 	if err != nil {
 		return err
 	}
@@ -790,5 +896,16 @@ func (s *Source) ChunkUnit(ctx context.Context, unit sources.SourceUnit, reporte
 	}
 	defer os.RemoveAll(path)
 
-	return s.git.ScanRepo(ctx, repo, path, s.scanOptions, reporter)
+	if err := s.git.ScanRepo(ctx, repo, path, s.scanOptions, reporter); err != nil {
+		return err
+	}
+	if !s.scanMergeRequests {
+		return nil
+	}
+
+	apiClient, err := s.sharedClient()
+	if err != nil {
+		return err
+	}
+	return s.scanProjectMergeRequests(ctx, apiClient, repoURL, reporter)
 }
@@ -1,14 +1,20 @@
 package gitlab
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/xanzy/go-gitlab"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sourcestest"
 )
 
 func Test_setProgressCompleteWithRepo_resumeInfo(t *testing.T) {
@@ -121,7 +127,7 @@ func Test_scanRepos_SetProgressComplete(t *testing.T) {
 			src.jobPool = &errgroup.Group{}
 			src.scanOptions = &git.ScanOptions{}
 
-			_ = src.scanRepos(context.Background(), nil)
+			_ = src.scanRepos(context.Background(), nil, nil)
 			if !tc.wantErr {
 				assert.Equal(t, "", src.GetProgress().EncodedResumeInfo)
 			}
@@ -187,3 +193,68 @@ func Test_normalizeGitlabEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAllProjectReposGroups(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1, "username": "alice"}`)
+	})
+	mux.HandleFunc("/api/v4/groups/acme", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 10, "full_path": "acme"}`)
+	})
+	mux.HandleFunc("/api/v4/groups/other%2Fteam", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 20, "full_path": "other/team"}`)
+	})
+	mux.HandleFunc("/api/v4/groups/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "404 Group Not Found"}`)
+	})
+	mux.HandleFunc("/api/v4/groups/10/projects", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("include_subgroups"))
+		assert.Equal(t, "false", r.URL.Query().Get("archived"))
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[
+				{"id": 2, "path_with_namespace": "acme/sub/api", "http_url_to_repo": "https://gitlab.example.com/acme/sub/api.git", "last_activity_at": "2024-05-01T00:00:00Z"},
+				{"id": 3, "path_with_namespace": "acme/old", "http_url_to_repo": "https://gitlab.example.com/acme/old.git", "last_activity_at": "2020-01-01T00:00:00Z"}
+			]`)
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		fmt.Fprint(w, `[{"id": 1, "path_with_namespace": "acme/web", "http_url_to_repo": "https://gitlab.example.com/acme/web.git", "last_activity_at": "2024-06-01T00:00:00Z"}]`)
+	})
+	mux.HandleFunc("/api/v4/groups/20/projects", func(w http.ResponseWriter, r *http.Request) {
+		// Project 1 is shared with this group too.
+		fmt.Fprint(w, `[
+			{"id": 1, "path_with_namespace": "acme/web", "http_url_to_repo": "https://gitlab.example.com/acme/web.git", "last_activity_at": "2024-06-01T00:00:00Z"},
+			{"id": 4, "path_with_namespace": "other/team/archived", "http_url_to_repo": "https://gitlab.example.com/other/team/archived.git", "archived": true}
+		]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	apiClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Source{
+		name:              "test",
+		groups:            []string{"acme", "other/team", "missing"},
+		skipArchived:      true,
+		lastActivityAfter: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	reporter := sourcestest.TestReporter{}
+	err = s.getAllProjectRepos(context.Background(), apiClient, func(string) bool { return false }, &reporter)
+	assert.NoError(t, err)
+
+	var repos []string
+	for _, unit := range reporter.Units {
+		id, _ := unit.SourceUnitID()
+		repos = append(repos, id)
+	}
+	assert.Equal(t, []string{
+		"https://gitlab.example.com/acme/web.git",
+		"https://gitlab.example.com/acme/sub/api.git",
+	}, repos)
+	assert.Len(t, reporter.UnitErrs, 1)
+}
@@ -0,0 +1,187 @@
+package gitlab
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// scanProjectMergeRequests reports the description and notes of every merge request in the project
+// of repoURL as separate chunks. Projects the token can't read, or that have merge requests
+// disabled, are skipped. Rate limiting is handled by the retries built into the API client.
+func (s *Source) scanProjectMergeRequests(ctx context.Context, apiClient *gitlab.Client, repoURL string, reporter sources.ChunkReporter) error {
+	projectPath, err := projectPathFromURL(s.url, repoURL)
+	if err != nil {
+		return err
+	}
+	ctx = context.WithValues(ctx, "project", projectPath)
+
+	project, _, err := apiClient.Projects.GetProject(projectPath, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		if isAccessDenied(err) {
+			ctx.Logger().V(2).Info("skipping merge requests of inaccessible project", "error", err)
+			return nil
+		}
+		return fmt.Errorf("error getting project %s: %w", projectPath, err)
+	}
+	if project.MergeRequestsAccessLevel == gitlab.DisabledAccessControl {
+		ctx.Logger().V(3).Info("skipping project with merge requests disabled")
+		return nil
+	}
+
+	listOpts := &gitlab.ListProjectMergeRequestsOptions{ListOptions: gitlab.ListOptions{PerPage: paginationLimit}}
+	for {
+		mergeRequests, res, err := apiClient.MergeRequests.ListProjectMergeRequests(project.ID, listOpts, gitlab.WithContext(ctx))
+		if err != nil {
+			if isAccessDenied(err) {
+				ctx.Logger().V(2).Info("skipping merge requests of inaccessible project", "error", err)
+				return nil
+			}
+			return fmt.Errorf("error listing merge requests of project %s: %w", projectPath, err)
+		}
+
+		for _, mr := range mergeRequests {
+			if err := s.scanMergeRequest(ctx, apiClient, project, repoURL, mr, reporter); err != nil {
+				return err
+			}
+		}
+
+		listOpts.Page = res.NextPage
+		if res.NextPage == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+// scanMergeRequest reports the description and the non-system notes of a single merge request.
+func (s *Source) scanMergeRequest(
+	ctx context.Context,
+	apiClient *gitlab.Client,
+	project *gitlab.Project,
+	repoURL string,
+	mr *gitlab.MergeRequest,
+	reporter sources.ChunkReporter,
+) error {
+	if content := strings.TrimSpace(mr.Title + "\n" + mr.Description); content != "" {
+		meta := s.mergeRequestMetadata(project, repoURL, mr, mr.WebURL, mr.CreatedAt)
+		if mr.Author != nil {
+			meta.Email = sanitizer.UTF8(mr.Author.Username)
+		}
+		if err := s.reportMergeRequestChunk(ctx, content, meta, reporter); err != nil {
+			return err
+		}
+	}
+
+	notesOpts := &gitlab.ListMergeRequestNotesOptions{ListOptions: gitlab.ListOptions{PerPage: paginationLimit}}
+	for {
+		notes, res, err := apiClient.Notes.ListMergeRequestNotes(project.ID, mr.IID, notesOpts, gitlab.WithContext(ctx))
+		if err != nil {
+			if isAccessDenied(err) {
+				ctx.Logger().V(2).Info("skipping notes of inaccessible merge request", "merge_request", mr.IID, "error", err)
+				return nil
+			}
+			return fmt.Errorf("error listing notes of merge request %d: %w", mr.IID, err)
+		}
+
+		for _, note := range notes {
+			// System notes are generated by GitLab, such as "added 1 commit".
+			if note.System || note.Body == "" {
+				continue
+			}
+			link := fmt.Sprintf("%s#note_%d", mr.WebURL, note.ID)
+			meta := s.mergeRequestMetadata(project, repoURL, mr, link, note.CreatedAt)
+			meta.NoteId = int64(note.ID)
+			meta.Email = sanitizer.UTF8(note.Author.Email)
+			if meta.Email == "" {
+				meta.Email = sanitizer.UTF8(note.Author.Username)
+			}
+			if err := s.reportMergeRequestChunk(ctx, note.Body, meta, reporter); err != nil {
+				return err
+			}
+		}
+
+		notesOpts.Page = res.NextPage
+		if res.NextPage == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *Source) mergeRequestMetadata(project *gitlab.Project, repoURL string, mr *gitlab.MergeRequest, link string, created *time.Time) *source_metadatapb.Gitlab {
+	meta := &source_metadatapb.Gitlab{
+		Link:            link,
+		Repository:      sanitizer.UTF8(repoURL),
+		ProjectId:       int64(project.ID),
+		ProjectName:     sanitizer.UTF8(project.NameWithNamespace),
+		MergeRequestIid: int64(mr.IID),
+	}
+	if project.Owner != nil {
+		meta.ProjectOwner = sanitizer.UTF8(project.Owner.Username)
+	}
+	if created != nil {
+		meta.Timestamp = created.String()
+	}
+	return meta
+}
+
+func (s *Source) reportMergeRequestChunk(ctx context.Context, data string, meta *source_metadatapb.Gitlab, reporter sources.ChunkReporter) error {
+	chunk := sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		JobID:      s.JobID(),
+		Data:       []byte(data),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Gitlab{Gitlab: meta},
+		},
+		Verify: s.verify,
+	}
+	if err := reporter.ChunkOk(ctx, chunk); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// projectPathFromURL returns the namespaced path of the project cloned from repoURL, relative to
+// the GitLab instance at baseURL.
+func projectPathFromURL(baseURL, repoURL string) (string, error) {
+	repo, err := url.Parse(repoURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse repository URL %q: %w", repoURL, err)
+	}
+	path := repo.Path
+	if base, err := url.Parse(baseURL); err == nil && strings.EqualFold(base.Host, repo.Host) {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/"))
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if path == "" {
+		return "", fmt.Errorf("no project path in repository URL %q", repoURL)
+	}
+	return path, nil
+}
+
+// isAccessDenied reports whether err is a GitLab API response indicating the token can't read
+// the requested resource.
+func isAccessDenied(err error) bool {
+	var errResp *gitlab.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+	switch errResp.Response.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestScanProjectMergeRequests(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/group%2Fproject", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1, "name_with_namespace": "group / project", "merge_requests_access_level": "enabled"}`)
+	})
+	mux.HandleFunc("/api/v4/projects/group%2Fprivate", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "404 Project Not Found"}`)
+	})
+	mux.HandleFunc("/api/v4/projects/1/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"iid": 6, "title": "second", "web_url": "https://gitlab.example.com/group/project/-/merge_requests/6"}]`)
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		fmt.Fprint(w, `[{"iid": 5, "title": "first", "description": "token abc", "web_url": "https://gitlab.example.com/group/project/-/merge_requests/5"}]`)
+	})
+	mux.HandleFunc("/api/v4/projects/1/merge_requests/5/notes", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"id": 10, "body": "secret in a review", "author": {"username": "alice"}},
+			{"id": 11, "body": "added 1 commit", "system": true}
+		]`)
+	})
+	mux.HandleFunc("/api/v4/projects/1/merge_requests/6/notes", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	apiClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunksCh := make(chan *sources.Chunk, 10)
+	reporter := sources.ChanReporter{Ch: chunksCh}
+	collect := func() []*sources.Chunk {
+		var chunks []*sources.Chunk
+		for len(chunksCh) > 0 {
+			chunks = append(chunks, <-chunksCh)
+		}
+		return chunks
+	}
+
+	ctx := context.Background()
+	s := &Source{url: server.URL + "/"}
+	err = s.scanProjectMergeRequests(ctx, apiClient, server.URL+"/group/project.git", reporter)
+	assert.NoError(t, err)
+
+	chunks := collect()
+
+	if assert.Len(t, chunks, 3) {
+		description := chunks[0].SourceMetadata.GetGitlab()
+		assert.Equal(t, "first\ntoken abc", string(chunks[0].Data))
+		assert.Equal(t, int64(5), description.GetMergeRequestIid())
+		assert.Equal(t, int64(0), description.GetNoteId())
+		assert.Equal(t, int64(1), description.GetProjectId())
+
+		note := chunks[1].SourceMetadata.GetGitlab()
+		assert.Equal(t, "secret in a review", string(chunks[1].Data))
+		assert.Equal(t, int64(5), note.GetMergeRequestIid())
+		assert.Equal(t, int64(10), note.GetNoteId())
+		assert.Equal(t, "alice", note.GetEmail())
+		assert.Equal(t, "https://gitlab.example.com/group/project/-/merge_requests/5#note_10", note.GetLink())
+
+		assert.Equal(t, int64(6), chunks[2].SourceMetadata.GetGitlab().GetMergeRequestIid())
+	}
+
+	// Projects the token can't read are skipped without an error.
+	err = s.scanProjectMergeRequests(ctx, apiClient, server.URL+"/group/private.git", reporter)
+	assert.NoError(t, err)
+	assert.Empty(t, collect())
+}
+
+func Test_projectPathFromURL(t *testing.T) {
+	tests := map[string]struct {
+		baseURL string
+		repoURL string
+		want    string
+		wantErr bool
+	}{
+		"gitlab.com": {
+			baseURL: "https://gitlab.com/",
+			repoURL: "https://gitlab.com/group/subgroup/project.git",
+			want:    "group/subgroup/project",
+		},
+		"self-hosted with path": {
+			baseURL: "https://example.com/gitlab/",
+			repoURL: "https://example.com/gitlab/group/project.git",
+			want:    "group/project",
+		},
+		"no path": {
+			baseURL: "https://gitlab.com/",
+			repoURL: "https://gitlab.com/",
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := projectPathFromURL(tt.baseURL, tt.repoURL)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSharedClient(t *testing.T) {
+	s := &Source{authMethod: "TOKEN", token: "token", url: "https://gitlab.example.com/"}
+
+	first, err := s.sharedClient()
+	assert.NoError(t, err)
+	second, err := s.sharedClient()
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+}
@@ -0,0 +1,201 @@
+package jira
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	// pageSize is the number of issues or comments requested per page.
+	pageSize = 100
+	// issueFields are the issue fields requested when searching for issues.
+	issueFields = "summary,description,comment,project,reporter,updated"
+	// timeLayout is the layout of the timestamps returned by the API.
+	timeLayout = "2006-01-02T15:04:05.000-0700"
+)
+
+// errNotFound is returned for requests the API responded to with a 404.
+var errNotFound = errors.New("not found")
+
+// client is a client of the Jira REST API, version 2, which is supported by
+// both Jira Cloud and Jira Data Center.
+type client struct {
+	baseURL *url.URL
+	http    *http.Client
+	// auth sets the credentials of a request.
+	auth func(*http.Request)
+	// legacySearch is set once the enhanced search endpoint turned out to be
+	// unavailable, as is the case for Jira Data Center.
+	legacySearch bool
+}
+
+type project struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type user struct {
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+type comment struct {
+	ID      string `json:"id"`
+	Body    string `json:"body"`
+	Author  user   `json:"author"`
+	Updated string `json:"updated"`
+}
+
+type commentPage struct {
+	Comments   []comment `json:"comments"`
+	StartAt    int       `json:"startAt"`
+	MaxResults int       `json:"maxResults"`
+	Total      int       `json:"total"`
+}
+
+type issue struct {
+	ID     string `json:"id"`
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string      `json:"summary"`
+		Description string      `json:"description"`
+		Comment     commentPage `json:"comment"`
+		Project     project     `json:"project"`
+		Reporter    user        `json:"reporter"`
+		Updated     string      `json:"updated"`
+	} `json:"fields"`
+}
+
+// updated returns the time the issue was last updated.
+func (i issue) updated() (time.Time, error) {
+	return time.Parse(timeLayout, i.Fields.Updated)
+}
+
+// searchPage is a page of issues returned by either the enhanced search
+// endpoint, which is paginated with tokens, or the legacy one, which is
+// paginated with offsets.
+type searchPage struct {
+	Issues        []issue `json:"issues"`
+	NextPageToken string  `json:"nextPageToken"`
+	StartAt       int     `json:"startAt"`
+	Total         int     `json:"total"`
+}
+
+// get sends a GET request to the endpoint at the given path and decodes the
+// JSON response into v.
+func (c *client) get(ctx context.Context, endpoint string, query url.Values, v any) error {
+	u := *c.baseURL
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	c.auth(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s: %w", endpoint, errNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s: unexpected status code: %d", endpoint, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%s: error decoding response: %w", endpoint, err)
+	}
+	return nil
+}
+
+// projects returns every project visible to the user.
+func (c *client) projects(ctx context.Context) ([]project, error) {
+	var projects []project
+	if err := c.get(ctx, "rest/api/2/project", nil, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// searchIssues calls visit with every issue matching jql, page by page.
+func (c *client) searchIssues(ctx context.Context, jql string, visit func(issue) error) error {
+	query := url.Values{
+		"jql":        {jql},
+		"fields":     {issueFields},
+		"maxResults": {strconv.Itoa(pageSize)},
+	}
+	for {
+		var page searchPage
+		var err error
+		if !c.legacySearch {
+			err = c.get(ctx, "rest/api/2/search/jql", query, &page)
+			if errors.Is(err, errNotFound) {
+				c.legacySearch = true
+				query.Set("startAt", "0")
+			}
+		}
+		if c.legacySearch {
+			err = c.get(ctx, "rest/api/2/search", query, &page)
+		}
+		if err != nil {
+			return fmt.Errorf("error searching issues: %w", err)
+		}
+
+		for _, issue := range page.Issues {
+			if err := visit(issue); err != nil {
+				return err
+			}
+		}
+
+		if c.legacySearch {
+			next := page.StartAt + len(page.Issues)
+			if len(page.Issues) == 0 || next >= page.Total {
+				return nil
+			}
+			query.Set("startAt", strconv.Itoa(next))
+			continue
+		}
+		if page.NextPageToken == "" {
+			return nil
+		}
+		query.Set("nextPageToken", page.NextPageToken)
+	}
+}
+
+// comments returns the comments of an issue starting at the given offset.
+func (c *client) comments(ctx context.Context, issueKey string, startAt int) ([]comment, error) {
+	var comments []comment
+	for {
+		query := url.Values{
+			"startAt":    {strconv.Itoa(startAt)},
+			"maxResults": {strconv.Itoa(pageSize)},
+		}
+		var page commentPage
+		if err := c.get(ctx, path.Join("rest/api/2/issue", issueKey, "comment"), query, &page); err != nil {
+			return comments, fmt.Errorf("error listing comments: %w", err)
+		}
+		comments = append(comments, page.Comments...)
+
+		startAt = page.StartAt + len(page.Comments)
+		if len(page.Comments) == 0 || startAt >= page.Total {
+			return comments, nil
+		}
+	}
+}
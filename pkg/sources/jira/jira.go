@@ -0,0 +1,325 @@
+package jira
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/roundtripper"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	SourceType = sourcespb.SourceType_SOURCE_TYPE_JIRA
+
+	projectUnitKind sources.SourceUnitKind = "project"
+
+	// jqlTimeLayout is the layout of dates in JQL queries.
+	jqlTimeLayout = "2006/01/02 15:04"
+	// jqlTimezoneMargin is subtracted from the time issues are searched
+	// from, as JQL dates are in the timezone of the user rather than in UTC.
+	// Issues updated before the requested time are skipped after the search.
+	jqlTimezoneMargin = 24 * time.Hour
+)
+
+type Source struct {
+	name     string
+	sourceId sources.SourceID
+	jobId    sources.JobID
+	verify   bool
+	client   *client
+	// projects are the keys of the projects to scan. Every visible
+	// project is scanned if it is empty.
+	projects       []string
+	ignoreProjects map[string]struct{}
+	// jql restricts the issues to scan.
+	jql string
+	// updatedSince skips issues that weren't updated since this time, if set.
+	updatedSince time.Time
+	sources.Progress
+	sources.CommonSourceUnitUnmarshaller
+}
+
+// Ensure the Source satisfies the interfaces at compile time.
+var _ sources.Source = (*Source)(nil)
+var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
+var _ sources.SourceUnitEnumChunker = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return SourceType
+}
+
+func (s *Source) SourceID() sources.SourceID {
+	return s.sourceId
+}
+
+func (s *Source) JobID() sources.JobID {
+	return s.jobId
+}
+
+// Init returns an initialized Jira source.
+func (s *Source) Init(ctx context.Context, name string, jobId sources.JobID, sourceId sources.SourceID, verify bool, connection *anypb.Any, _ int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+
+	var conn sourcespb.JIRA
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return fmt.Errorf("error unmarshalling connection: %w", err)
+	}
+
+	baseURL, err := url.Parse(conn.GetEndpoint())
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return fmt.Errorf("invalid endpoint URL given for Jira source: %q", conn.GetEndpoint())
+	}
+
+	var auth func(*http.Request)
+	switch cred := conn.GetCredential().(type) {
+	case *sourcespb.JIRA_BasicAuth:
+		username, password := cred.BasicAuth.GetUsername(), cred.BasicAuth.GetPassword()
+		if username == "" || password == "" {
+			return errors.New("Jira basic auth credential requires a username and a password")
+		}
+		auth = func(req *http.Request) { req.SetBasicAuth(username, password) }
+	case *sourcespb.JIRA_Token:
+		if cred.Token == "" {
+			return errors.New("Jira token is empty")
+		}
+		auth = func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+cred.Token) }
+	case *sourcespb.JIRA_Unauthenticated:
+		auth = func(*http.Request) {}
+	default:
+		return errors.New("credential type not implemented for Jira")
+	}
+
+	// Requests rejected by the rate limit are retried after the delay given
+	// in their Retry-After header.
+	opts := []func(*roundtripper.RoundTripper){
+		roundtripper.WithLogger(ctx.Logger()),
+		roundtripper.WithRetryable(),
+	}
+	if conn.GetInsecureSkipVerifyTls() {
+		opts = append(opts, roundtripper.WithInsecureTLS())
+	}
+	s.client = &client{
+		baseURL: baseURL,
+		http:    &http.Client{Transport: roundtripper.NewRoundTripper(nil, opts...)},
+		auth:    auth,
+	}
+
+	s.projects = conn.GetProjects()
+	s.ignoreProjects = make(map[string]struct{}, len(conn.GetIgnoreProjects()))
+	for _, key := range conn.GetIgnoreProjects() {
+		s.ignoreProjects[strings.ToUpper(key)] = struct{}{}
+	}
+	s.jql = conn.GetJql()
+	if since := conn.GetUpdatedSince(); since != nil {
+		s.updatedSince = since.AsTime()
+	}
+
+	return nil
+}
+
+// projectKeys returns the keys of the projects to scan.
+func (s *Source) projectKeys(ctx context.Context) ([]string, error) {
+	keys := s.projects
+	if len(keys) == 0 {
+		projects, err := s.client.projects(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing projects: %w", err)
+		}
+		for _, p := range projects {
+			keys = append(keys, p.Key)
+		}
+	}
+
+	filtered := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := s.ignoreProjects[strings.ToUpper(key)]; !ok {
+			filtered = append(filtered, key)
+		}
+	}
+	return filtered, nil
+}
+
+// Enumerate implements SourceUnitEnumerator interface. Each project is a unit.
+func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) error {
+	keys, err := s.projectKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := reporter.UnitOk(ctx, sources.CommonSourceUnit{ID: key, Kind: projectUnitKind}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChunkUnit implements SourceUnitChunker interface.
+func (s *Source) ChunkUnit(ctx context.Context, unit sources.SourceUnit, reporter sources.ChunkReporter) error {
+	key, _ := unit.SourceUnitID()
+	return s.scanProject(ctx, key, reporter)
+}
+
+// Chunks emits chunks of bytes over a channel.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ ...sources.ChunkingTarget) error {
+	keys, err := s.projectKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	reporter := sources.ChanReporter{Ch: chunksChan}
+	for i, key := range keys {
+		if common.IsDone(ctx) {
+			return nil
+		}
+		s.SetProgressComplete(i, len(keys), fmt.Sprintf("Project: %s", key), "")
+
+		if err := s.scanProject(ctx, key, reporter); err != nil {
+			ctx.Logger().Error(err, "error scanning project", "project", key)
+		}
+	}
+
+	s.SetProgressComplete(len(keys), len(keys), fmt.Sprintf("Done scanning source %s", s.name), "")
+	return nil
+}
+
+// scanProject reports the chunks of every issue of a project matching the
+// configured filters.
+func (s *Source) scanProject(ctx context.Context, key string, reporter sources.ChunkReporter) error {
+	ctx = context.WithValues(ctx, "project", key)
+	ctx.Logger().V(2).Info("scanning project")
+
+	jql := issuesJQL(key, s.jql, s.updatedSince)
+	err := s.client.searchIssues(ctx, jql, func(i issue) error {
+		if !s.updatedSince.IsZero() {
+			updated, err := i.updated()
+			if err == nil && updated.Before(s.updatedSince) {
+				return nil
+			}
+		}
+		return s.scanIssue(ctx, i, reporter)
+	})
+	if err != nil {
+		return reporter.ChunkErr(ctx, err)
+	}
+	return nil
+}
+
+// scanIssue reports the summary, the description and every comment of an
+// issue as separate chunks.
+func (s *Source) scanIssue(ctx context.Context, i issue, reporter sources.ChunkReporter) error {
+	link := s.issueLink(i.Key)
+	fields := i.Fields
+	metadata := func(location string) *source_metadatapb.Jira {
+		return &source_metadatapb.Jira{
+			Project:   fields.Project.Key,
+			Issue:     i.Key,
+			Author:    fields.Reporter.DisplayName,
+			Email:     fields.Reporter.EmailAddress,
+			Link:      link,
+			Location:  location,
+			Timestamp: fields.Updated,
+		}
+	}
+
+	if err := s.report(ctx, reporter, fields.Summary, metadata("summary")); err != nil {
+		return err
+	}
+	if err := s.report(ctx, reporter, fields.Description, metadata("description")); err != nil {
+		return err
+	}
+
+	// Search results only include the first page of comments.
+	comments := fields.Comment.Comments
+	if loaded := fields.Comment.StartAt + len(comments); loaded < fields.Comment.Total {
+		more, err := s.client.comments(ctx, i.Key, loaded)
+		comments = append(comments, more...)
+		if err != nil {
+			if err := reporter.ChunkErr(ctx, fmt.Errorf("issue %s: %w", i.Key, err)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, c := range comments {
+		m := metadata("comment")
+		m.CommentId = c.ID
+		m.Author = c.Author.DisplayName
+		m.Email = c.Author.EmailAddress
+		m.Link = link + "?focusedCommentId=" + url.QueryEscape(c.ID)
+		m.Timestamp = c.Updated
+		if err := s.report(ctx, reporter, c.Body, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// report reports data as a chunk with the given metadata, unless it is empty.
+func (s *Source) report(ctx context.Context, reporter sources.ChunkReporter, data string, metadata *source_metadatapb.Jira) error {
+	if strings.TrimSpace(data) == "" {
+		return nil
+	}
+	return reporter.ChunkOk(ctx, sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		JobID:      s.JobID(),
+		Data:       []byte(data),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Jira{Jira: metadata},
+		},
+		Verify: s.verify,
+	})
+}
+
+// issueLink returns the link to an issue in the Jira web interface.
+func (s *Source) issueLink(key string) string {
+	return strings.TrimSuffix(s.client.baseURL.String(), "/") + "/browse/" + url.PathEscape(key)
+}
+
+// orderByPat matches the ORDER BY clause of a JQL query.
+var orderByPat = regexp.MustCompile(`(?i)\border\s+by\b`)
+
+// issuesJQL returns the JQL query of the issues of a project matching the
+// user's query that were updated since the given time, if it isn't zero.
+func issuesJQL(projectKey, jql string, updatedSince time.Time) string {
+	clauses := []string{"project = " + quoteJQL(projectKey)}
+
+	// The user's ORDER BY clause has to stay at the end of the query.
+	orderBy := "ORDER BY key ASC"
+	if loc := orderByPat.FindStringIndex(jql); loc != nil {
+		orderBy = strings.TrimSpace(jql[loc[0]:])
+		jql = jql[:loc[0]]
+	}
+	if jql = strings.TrimSpace(jql); jql != "" {
+		clauses = append(clauses, "("+jql+")")
+	}
+
+	if !updatedSince.IsZero() {
+		since := updatedSince.Add(-jqlTimezoneMargin).UTC().Format(jqlTimeLayout)
+		clauses = append(clauses, "updated >= "+quoteJQL(since))
+	}
+	return strings.Join(clauses, " AND ") + " " + orderBy
+}
+
+// quoteJQL returns s as a quoted JQL string.
+func quoteJQL(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
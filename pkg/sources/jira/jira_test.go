@@ -0,0 +1,238 @@
+package jira
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sourcestest"
+)
+
+func testIssue(key, summary, description, updated string, comments commentPage) issue {
+	var i issue
+	i.Key = key
+	i.Fields.Summary = summary
+	i.Fields.Description = description
+	i.Fields.Updated = updated
+	i.Fields.Project = project{Key: "ABC"}
+	i.Fields.Reporter = user{DisplayName: "Reporter", EmailAddress: "reporter@example.com"}
+	i.Fields.Comment = comments
+	return i
+}
+
+// fakeJira serves a Jira instance with two projects. The issues of project
+// ABC are split across two pages, and the comments of ABC-1 across the search
+// results and the comments endpoint. If legacy is set, the enhanced search
+// endpoint is missing, like it is in Jira Data Center.
+func fakeJira(t *testing.T, legacy bool) *httptest.Server {
+	issues := []issue{
+		testIssue("ABC-1", "first issue", "password=hunter2", "2024-03-01T10:00:00.000+0000", commentPage{
+			Comments: []comment{{ID: "10", Body: "first comment", Author: user{DisplayName: "Commenter"}}},
+			Total:    2,
+		}),
+		testIssue("ABC-2", "old issue", "", "2020-01-01T10:00:00.000+0000", commentPage{}),
+	}
+	reply := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(v))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/jira/rest/api/2/project":
+			reply(w, []project{{Key: "ABC"}, {Key: "IGN"}})
+		case "/jira/rest/api/2/search/jql":
+			if legacy {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			assert.Contains(t, query.Get("jql"), `project = "ABC"`)
+			if query.Get("nextPageToken") == "" {
+				reply(w, searchPage{Issues: issues[:1], NextPageToken: "next"})
+				return
+			}
+			reply(w, searchPage{Issues: issues[1:]})
+		case "/jira/rest/api/2/search":
+			if !legacy {
+				t.Errorf("unexpected legacy search")
+			}
+			if query.Get("startAt") == "0" {
+				reply(w, searchPage{Issues: issues[:1], Total: 2})
+				return
+			}
+			reply(w, searchPage{Issues: issues[1:], StartAt: 1, Total: 2})
+		case "/jira/rest/api/2/issue/ABC-1/comment":
+			assert.Equal(t, "1", query.Get("startAt"))
+			reply(w, commentPage{
+				Comments: []comment{{ID: "11", Body: "second comment", Author: user{DisplayName: "Commenter"}}},
+				StartAt:  1,
+				Total:    2,
+			})
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestSource_ChunkUnit(t *testing.T) {
+	tests := []struct {
+		name         string
+		legacy       bool
+		updatedSince time.Time
+		want         []string
+	}{
+		{
+			name: "enhanced search",
+			want: []string{
+				"summary ABC-1 first issue",
+				"description ABC-1 password=hunter2",
+				"comment 10 first comment",
+				"comment 11 second comment",
+				"summary ABC-2 old issue",
+			},
+		},
+		{
+			name:   "legacy search",
+			legacy: true,
+			want: []string{
+				"summary ABC-1 first issue",
+				"description ABC-1 password=hunter2",
+				"comment 10 first comment",
+				"comment 11 second comment",
+				"summary ABC-2 old issue",
+			},
+		},
+		{
+			name:         "updated since",
+			updatedSince: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: []string{
+				"summary ABC-1 first issue",
+				"description ABC-1 password=hunter2",
+				"comment 10 first comment",
+				"comment 11 second comment",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := fakeJira(t, tt.legacy)
+			defer server.Close()
+
+			jira := &sourcespb.JIRA{
+				Endpoint:       server.URL + "/jira/",
+				Credential:     &sourcespb.JIRA_Token{Token: "token"},
+				IgnoreProjects: []string{"ign"},
+			}
+			if !tt.updatedSince.IsZero() {
+				jira.UpdatedSince = timestamppb.New(tt.updatedSince)
+			}
+			conn, err := anypb.New(jira)
+			assert.NoError(t, err)
+			s := Source{}
+			assert.NoError(t, s.Init(ctx, "test", 0, 0, false, conn, 1))
+
+			units := sourcestest.TestReporter{}
+			assert.NoError(t, s.Enumerate(ctx, &units))
+			assert.Equal(t, []sources.SourceUnit{sources.CommonSourceUnit{ID: "ABC", Kind: projectUnitKind}}, units.Units)
+
+			chunks := sourcestest.TestReporter{}
+			assert.NoError(t, s.ChunkUnit(ctx, units.Units[0], &chunks))
+			assert.Empty(t, chunks.ChunkErrs)
+
+			var got []string
+			for _, chunk := range chunks.Chunks {
+				metadata := chunk.SourceMetadata.GetJira()
+				assert.Equal(t, "ABC", metadata.GetProject())
+				id := metadata.GetIssue()
+				if metadata.GetLocation() == "comment" {
+					id = metadata.GetCommentId()
+					assert.Equal(t, server.URL+"/jira/browse/ABC-1?focusedCommentId="+id, metadata.GetLink())
+					assert.Equal(t, "Commenter", metadata.GetAuthor())
+				}
+				got = append(got, metadata.GetLocation()+" "+id+" "+string(chunk.Data))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSource_Init(t *testing.T) {
+	tests := []struct {
+		name    string
+		conn    *sourcespb.JIRA
+		wantErr bool
+	}{
+		{
+			name: "basic auth",
+			conn: &sourcespb.JIRA{
+				Endpoint:   "https://example.atlassian.net",
+				Credential: &sourcespb.JIRA_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: "user", Password: "api-token"}},
+			},
+		},
+		{
+			name: "basic auth without password",
+			conn: &sourcespb.JIRA{
+				Endpoint:   "https://example.atlassian.net",
+				Credential: &sourcespb.JIRA_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: "user"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "missing endpoint",
+			conn:    &sourcespb.JIRA{Credential: &sourcespb.JIRA_Token{Token: "token"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing credential",
+			conn:    &sourcespb.JIRA{Endpoint: "https://example.atlassian.net"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := anypb.New(tt.conn)
+			assert.NoError(t, err)
+			s := Source{}
+			err = s.Init(context.Background(), "test", 0, 0, false, conn, 1)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestIssuesJQL(t *testing.T) {
+	since := time.Date(2024, 3, 2, 15, 4, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		jql   string
+		since time.Time
+		want  string
+	}{
+		{name: "project only", want: `project = "ABC" ORDER BY key ASC`},
+		{name: "query", jql: "status = Done OR labels = secret", want: `project = "ABC" AND (status = Done OR labels = secret) ORDER BY key ASC`},
+		{name: "order by", jql: "status = Done order by created DESC", want: `project = "ABC" AND (status = Done) order by created DESC`},
+		{name: "updated since", since: since, want: `project = "ABC" AND updated >= "2024/03/01 15:04" ORDER BY key ASC`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, issuesJQL("ABC", tt.jql, tt.since))
+		})
+	}
+	assert.Equal(t, `"a\"b\\c"`, quoteJQL(`a"b\c`))
+}
@@ -102,6 +102,15 @@ func (f ChunkError) Error() string {
 }
 func (f ChunkError) Unwrap() error { return f.Err }
 
+// Skipped is a wrapper around the error of a unit that failed to be enumerated
+// or scanned, and was skipped so that its source could continue with its other
+// units. Managers created with WithContinueOnSourceError report it instead of
+// a Fatal error.
+type Skipped struct{ error }
+
+func (f Skipped) Error() string { return fmt.Sprintf("skipped: %s", f.error.Error()) }
+func (f Skipped) Unwrap() error { return f.error }
+
 // JobProgress aggregates information about a run of a Source.
 type JobProgress struct {
 	// Unique identifiers for this job.
@@ -336,6 +345,18 @@ func (m JobProgressMetrics) FatalError() error {
 	return nil
 }
 
+// SkippedErrors returns the errors of the units that were skipped.
+func (m JobProgressMetrics) SkippedErrors() []error {
+	var skipped []error
+	for _, err := range m.Errors {
+		var skippedErr Skipped
+		if errors.As(err, &skippedErr) {
+			skipped = append(skipped, skippedErr)
+		}
+	}
+	return skipped
+}
+
 // FatalErrors returns all of the encountered fatal errors joined together.
 func (m JobProgressMetrics) FatalErrors() error {
 	var aggregate []error
@@ -21,4 +21,18 @@ var (
 		Name:      "hooks_channel_size",
 		Help:      "Total number of metrics waiting in the finished channel.",
 	}, nil)
+
+	outputChunksLen = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: common.MetricsNamespace,
+		Subsystem: common.MetricsSubsystem,
+		Name:      "output_chunks_channel_length",
+		Help:      "Number of chunks buffered waiting to be scanned, sampled each time a chunk is queued.",
+	})
+
+	outputChunksCap = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: common.MetricsNamespace,
+		Subsystem: common.MetricsSubsystem,
+		Name:      "output_chunks_channel_capacity",
+		Help:      "Maximum number of chunks buffered waiting to be scanned.",
+	})
 )
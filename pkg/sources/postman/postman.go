@@ -179,7 +179,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 
 	// Scan workspaces
 	for _, workspaceID := range s.conn.Workspaces {
-		w, err := s.client.GetWorkspace(workspaceID)
+		w, err := s.client.GetWorkspace(ctx, workspaceID)
 		if err != nil {
 			return fmt.Errorf("error getting workspace %s: %w", workspaceID, err)
 		}
@@ -194,21 +194,47 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 			continue
 		}
 
-		collection, err := s.client.GetCollection(collectionID)
+		collection, err := s.client.GetCollection(ctx, collectionID)
 		if err != nil {
 			return fmt.Errorf("error getting collection %s: %w", collectionID, err)
 		}
 		s.scanCollection(ctx, chunksChan, Metadata{}, collection)
 	}
 
+	// Scan environments
+	for _, environmentID := range s.conn.Environments {
+		if shouldSkip(environmentID, s.conn.IncludeEnvironments, s.conn.ExcludeEnvironments) {
+			continue
+		}
+
+		envVars, err := s.client.GetEnvironmentVariables(ctx, environmentID)
+		if err != nil {
+			return fmt.Errorf("error getting environment %s: %w", environmentID, err)
+		}
+		metadata := Metadata{
+			Type:            ENVIRONMENT_TYPE,
+			Link:            LINK_BASE_URL + "environments/" + environmentID,
+			FullID:          envVars.ID,
+			EnvironmentID:   environmentID,
+			EnvironmentName: envVars.Name,
+		}
+		s.scanVariableData(ctx, chunksChan, metadata, envVars)
+	}
+
 	// Scan personal workspaces (from API token)
 	if s.conn.Workspaces == nil && s.conn.Collections == nil && s.conn.Environments == nil && s.conn.GetToken() != "" {
-		workspaces, err := s.client.EnumerateWorkspaces()
+		workspaces, err := s.client.EnumerateWorkspaces(ctx)
 		if err != nil {
 			return fmt.Errorf("error enumerating postman workspaces: %w", err)
 		}
 		for _, workspace := range workspaces {
-			if err = s.scanWorkspace(ctx, chunksChan, workspace); err != nil {
+			// The workspaces list doesn't include the collections and
+			// environments of each workspace, so fetch it in full.
+			w, err := s.client.GetWorkspace(ctx, workspace.ID)
+			if err != nil {
+				return fmt.Errorf("error getting workspace %s: %w", workspace.ID, err)
+			}
+			if err = s.scanWorkspace(ctx, chunksChan, w); err != nil {
 				return fmt.Errorf("error scanning workspace %s: %w", workspace.ID, err)
 			}
 		}
@@ -251,7 +277,7 @@ func (s *Source) scanWorkspace(ctx context.Context, chunksChan chan *sources.Chu
 
 	// scan global variables
 	ctx.Logger().V(2).Info("starting scanning global variables")
-	globalVars, err := s.client.GetGlobalVariables(workspace.ID)
+	globalVars, err := s.client.GetGlobalVariables(ctx, workspace.ID)
 	if err != nil {
 		// NOTE: global endpoint is finicky
 		ctx.Logger().V(2).Error(err, "skipping global variables")
@@ -266,12 +292,12 @@ func (s *Source) scanWorkspace(ctx context.Context, chunksChan chan *sources.Chu
 
 	// gather and scan environment variables
 	for _, envID := range workspace.Environments {
-		envVars, err := s.client.GetEnvironmentVariables(envID.UUID)
-		if err != nil {
-			ctx.Logger().Error(err, "could not get env variables", "environment_uuid", envID.UUID)
+		if shouldSkip(envID.UUID, s.conn.IncludeEnvironments, s.conn.ExcludeEnvironments) {
 			continue
 		}
-		if shouldSkip(envID.UUID, s.conn.IncludeEnvironments, s.conn.ExcludeEnvironments) {
+		envVars, err := s.client.GetEnvironmentVariables(ctx, envID.UUID)
+		if err != nil {
+			ctx.Logger().Error(err, "could not get env variables", "environment_uuid", envID.UUID)
 			continue
 		}
 		metadata.Type = ENVIRONMENT_TYPE
@@ -292,11 +318,15 @@ func (s *Source) scanWorkspace(ctx context.Context, chunksChan chan *sources.Chu
 	// scan all the collections in the workspace.
 	// at this point we have all the possible
 	// substitutions from Global and Environment variables
-	for _, collectionID := range workspace.Collections {
+	collections, err := s.client.ListCollections(ctx, workspace.ID)
+	if err != nil {
+		return err
+	}
+	for _, collectionID := range collections {
 		if shouldSkip(collectionID.UUID, s.conn.IncludeCollections, s.conn.ExcludeCollections) {
 			continue
 		}
-		collection, err := s.client.GetCollection(collectionID.UUID)
+		collection, err := s.client.GetCollection(ctx, collectionID.UUID)
 		if err != nil {
 			return err
 		}
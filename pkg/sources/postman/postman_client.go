@@ -6,6 +6,10 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 )
 
 const (
@@ -17,12 +21,21 @@ const (
 	WORKSPACE_URL    = "https://api.getpostman.com/workspaces/%s"
 	ENVIRONMENTS_URL = "https://api.getpostman.com/environments/%s"
 	COLLECTIONS_URL  = "https://api.getpostman.com/collections/%s"
+	// Collections of a workspace, listed a page at a time.
+	WORKSPACE_COLLECTIONS_URL = "https://api.getpostman.com/collections?workspace=%s&limit=%d&offset=%d"
 
 	userAgent     = "PostmanRuntime/7.26.8"
 	alt_userAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
 	//Since we're using the undocumented API endpoint for global vars, we need a different user agent.
 	//We'll shift this once that behavior is resolved and stable.
 	defaultContentType = "*"
+
+	// The Postman API allows 300 requests per minute per API key.
+	// https://learning.postman.com/docs/developer/postman-api/postman-api-rate-limits/
+	requestsPerMinute = 300
+
+	// Number of collections requested per page when listing collections.
+	collectionsPageSize = 100
 )
 
 type Workspace struct {
@@ -196,6 +209,9 @@ type Client struct {
 
 	// Headers to attach to every requests made with the client.
 	Headers map[string]string
+
+	// Rate limiter keeping requests within the API's rate limit.
+	limiter *rate.Limiter
 }
 
 // NewClient returns a new Postman API client.
@@ -209,6 +225,7 @@ func NewClient(postmanToken string) *Client {
 	c := &Client{
 		HTTPClient: http.DefaultClient,
 		Headers:    bh,
+		limiter:    rate.NewLimiter(rate.Every(time.Minute/requestsPerMinute), 1),
 	}
 
 	return c
@@ -216,18 +233,16 @@ func NewClient(postmanToken string) *Client {
 
 // NewRequest creates an API request (Only GET needed for our interaction w/ Postman)
 // If specified, the map provided by headers will be used to update request headers.
-func (c *Client) NewRequest(urlStr string, headers map[string]string) (*http.Request, error) {
-	req, err := http.NewRequest("GET", urlStr, nil)
+func (c *Client) NewRequest(ctx context.Context, urlStr string, headers map[string]string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var h = c.Headers
-	for k, v := range headers {
-		h[k] = v
+	for k, v := range c.Headers {
+		req.Header.Set(k, v)
 	}
-
-	for k, v := range h {
+	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
 
@@ -243,8 +258,17 @@ func checkResponseStatus(r *http.Response) error {
 	return fmt.Errorf("postman Request failed with status code: %d", r.StatusCode)
 }
 
-func (c *Client) getPostmanReq(url string, headers map[string]string) (*http.Response, error) {
-	req, err := c.NewRequest(url, headers)
+// getPostmanReq makes a GET request once the rate limiter allows it. Requests
+// rejected with a 429 are retried by the HTTP client after the delay given in
+// the Retry-After header.
+func (c *Client) getPostmanReq(ctx context.Context, url string, headers map[string]string) (*http.Response, error) {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := c.NewRequest(ctx, url, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -255,6 +279,7 @@ func (c *Client) getPostmanReq(url string, headers map[string]string) (*http.Res
 	}
 
 	if err := checkResponseStatus(resp); err != nil {
+		resp.Body.Close()
 		return nil, err
 	}
 	return resp, nil
@@ -262,13 +287,13 @@ func (c *Client) getPostmanReq(url string, headers map[string]string) (*http.Res
 
 // EnumerateWorkspaces returns the workspaces for a given user (both private, public, team and personal).
 // Consider adding additional flags to support filtering.
-func (c *Client) EnumerateWorkspaces() ([]Workspace, error) {
+func (c *Client) EnumerateWorkspaces(ctx context.Context) ([]Workspace, error) {
 	var workspaces []Workspace
 	workspacesObj := struct {
 		Workspaces []Workspace `json:"workspaces"`
 	}{}
 
-	r, err := c.getPostmanReq("https://api.getpostman.com/workspaces", nil)
+	r, err := c.getPostmanReq(ctx, "https://api.getpostman.com/workspaces", nil)
 	if err != nil {
 		err = fmt.Errorf("could not get workspaces")
 		return workspaces, err
@@ -290,14 +315,14 @@ func (c *Client) EnumerateWorkspaces() ([]Workspace, error) {
 }
 
 // GetWorkspace returns the workspace for a given workspace
-func (c *Client) GetWorkspace(workspaceUUID string) (Workspace, error) {
+func (c *Client) GetWorkspace(ctx context.Context, workspaceUUID string) (Workspace, error) {
 	var workspace Workspace
 	obj := struct {
 		Workspace Workspace `json:"workspace"`
 	}{}
 
 	url := fmt.Sprintf(WORKSPACE_URL, workspaceUUID)
-	r, err := c.getPostmanReq(url, nil)
+	r, err := c.getPostmanReq(ctx, url, nil)
 	if err != nil {
 		err = fmt.Errorf("could not get workspace: %s", workspaceUUID)
 		return workspace, err
@@ -319,13 +344,13 @@ func (c *Client) GetWorkspace(workspaceUUID string) (Workspace, error) {
 }
 
 // GetGlobalVariables returns the global variables for a given workspace
-func (c *Client) GetGlobalVariables(workspace_uuid string) (VariableData, error) {
+func (c *Client) GetGlobalVariables(ctx context.Context, workspace_uuid string) (VariableData, error) {
 	obj := struct {
 		VariableData VariableData `json:"data"`
 	}{}
 
 	url := fmt.Sprintf(GLOBAL_VARS_URL, workspace_uuid)
-	r, err := c.getPostmanReq(url, map[string]string{"User-Agent": alt_userAgent})
+	r, err := c.getPostmanReq(ctx, url, map[string]string{"User-Agent": alt_userAgent})
 	if err != nil {
 		err = fmt.Errorf("could not get global variables for workspace: %s", workspace_uuid)
 		return VariableData{}, err
@@ -346,13 +371,13 @@ func (c *Client) GetGlobalVariables(workspace_uuid string) (VariableData, error)
 }
 
 // GetEnvironmentVariables returns the environment variables for a given environment
-func (c *Client) GetEnvironmentVariables(environment_uuid string) (VariableData, error) {
+func (c *Client) GetEnvironmentVariables(ctx context.Context, environment_uuid string) (VariableData, error) {
 	obj := struct {
 		VariableData VariableData `json:"environment"`
 	}{}
 
 	url := fmt.Sprintf(ENVIRONMENTS_URL, environment_uuid)
-	r, err := c.getPostmanReq(url, nil)
+	r, err := c.getPostmanReq(ctx, url, nil)
 	if err != nil {
 		err = fmt.Errorf("could not get env variables for environment: %s", environment_uuid)
 		return VariableData{}, err
@@ -373,13 +398,13 @@ func (c *Client) GetEnvironmentVariables(environment_uuid string) (VariableData,
 }
 
 // GetCollection returns the collection for a given collection
-func (c *Client) GetCollection(collection_uuid string) (Collection, error) {
+func (c *Client) GetCollection(ctx context.Context, collection_uuid string) (Collection, error) {
 	obj := struct {
 		Collection Collection `json:"collection"`
 	}{}
 
 	url := fmt.Sprintf(COLLECTIONS_URL, collection_uuid)
-	r, err := c.getPostmanReq(url, nil)
+	r, err := c.getPostmanReq(ctx, url, nil)
 	if err != nil {
 		err = fmt.Errorf("could not get collection: %s", collection_uuid)
 		return Collection{}, err
@@ -398,3 +423,44 @@ func (c *Client) GetCollection(collection_uuid string) (Collection, error) {
 
 	return obj.Collection, nil
 }
+
+// ListCollections returns the collections of a given workspace. The collections are listed a page
+// at a time until the total reported by the API has been reached.
+func (c *Client) ListCollections(ctx context.Context, workspaceUUID string) ([]IDNameUUID, error) {
+	var collections []IDNameUUID
+	for offset := 0; ; {
+		obj := struct {
+			Collections []IDNameUUID `json:"collections"`
+			Meta        struct {
+				Total  int `json:"total"`
+				Offset int `json:"offset"`
+				Limit  int `json:"limit"`
+			} `json:"meta"`
+		}{}
+
+		url := fmt.Sprintf(WORKSPACE_COLLECTIONS_URL, workspaceUUID, collectionsPageSize, offset)
+		r, err := c.getPostmanReq(ctx, url, nil)
+		if err != nil {
+			err = fmt.Errorf("could not list collections for workspace: %s", workspaceUUID)
+			return nil, err
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			err = fmt.Errorf("could not read response body for collections of workspace: %s", workspaceUUID)
+			return nil, err
+		}
+		r.Body.Close()
+		if err := json.Unmarshal([]byte(body), &obj); err != nil {
+			err = fmt.Errorf("could not unmarshal JSON for collections of workspace: %s", workspaceUUID)
+			return nil, err
+		}
+
+		collections = append(collections, obj.Collections...)
+		offset += len(obj.Collections)
+		// A response without a limit isn't paginated and holds every collection.
+		if obj.Meta.Limit == 0 || len(obj.Collections) == 0 || offset >= obj.Meta.Total {
+			return collections, nil
+		}
+	}
+}
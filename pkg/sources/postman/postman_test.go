@@ -1,6 +1,7 @@
 package postman
 
 import (
+	"net/http"
 	"reflect"
 	"sort"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 	"google.golang.org/protobuf/types/known/anypb"
+	"gopkg.in/h2non/gock.v1"
 )
 
 func createTestSource(src *sourcespb.Postman) (*Source, *anypb.Any) {
@@ -238,3 +240,120 @@ func TestSource_ScanVariableData(t *testing.T) {
 		})
 	}
 }
+
+func TestSource_ChunksEnumeratedWorkspaces(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.getpostman.com").
+		Get("/workspaces").
+		Reply(200).
+		JSON(map[string]any{"workspaces": []map[string]string{{"id": "ws1", "name": "Workspace"}}})
+	gock.New("https://api.getpostman.com").
+		Get("/workspaces/ws1").
+		Reply(200).
+		JSON(map[string]any{"workspace": map[string]any{
+			"id":           "ws1",
+			"name":         "Workspace",
+			"environments": []map[string]string{{"id": "env1", "name": "Env", "uid": "user-env1"}},
+		}})
+	gock.New("https://www.postman.com").
+		Get("/_api/workspace/ws1/globals").
+		Reply(404)
+	gock.New("https://api.getpostman.com").
+		Get("/collections").
+		MatchParams(map[string]string{"workspace": "ws1", "offset": "0"}).
+		Reply(200).
+		JSON(map[string]any{"collections": []map[string]string{}, "meta": map[string]int{"total": 0, "offset": 0, "limit": 100}})
+	gock.New("https://api.getpostman.com").
+		Get("/environments/user-env1").
+		Reply(200).
+		JSON(map[string]any{"environment": map[string]any{
+			"id":     "env1",
+			"name":   "Env",
+			"values": []map[string]string{{"key": "api_key", "value": "hunter2"}},
+		}})
+
+	s, conn := createTestSource(&sourcespb.Postman{
+		Credential: &sourcespb.Postman_Token{Token: "super secret token"},
+	})
+	s.DetectorKeywords = map[string]struct{}{"api": {}}
+	ctx := context.Background()
+	if err := s.Init(ctx, "test - postman", 0, 1, false, conn, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.client.HTTPClient = http.DefaultClient
+
+	chunksChan := make(chan *sources.Chunk, 8)
+	if err := s.Chunks(ctx, chunksChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	close(chunksChan)
+
+	var found bool
+	for chunk := range chunksChan {
+		if strings.Contains(string(chunk.Data), "hunter2") {
+			found = true
+			if got := chunk.SourceMetadata.GetPostman().GetEnvironmentId(); got != "user-env1" {
+				t.Errorf("expected environment ID user-env1, got: %s", got)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected a chunk with the environment variable")
+	}
+	if !gock.IsDone() {
+		t.Errorf("expected all Postman API requests to be made")
+	}
+}
+
+func TestClient_ListCollections(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.getpostman.com").
+		Get("/collections").
+		MatchParams(map[string]string{"workspace": "ws1", "offset": "0"}).
+		Reply(200).
+		JSON(map[string]any{
+			"collections": []map[string]string{{"id": "col1", "uid": "user-col1"}, {"id": "col2", "uid": "user-col2"}},
+			"meta":        map[string]int{"total": 3, "offset": 0, "limit": 2},
+		})
+	gock.New("https://api.getpostman.com").
+		Get("/collections").
+		MatchParams(map[string]string{"workspace": "ws1", "offset": "2"}).
+		Reply(200).
+		JSON(map[string]any{
+			"collections": []map[string]string{{"id": "col3", "uid": "user-col3"}},
+			"meta":        map[string]int{"total": 3, "offset": 2, "limit": 2},
+		})
+	gock.New("https://api.getpostman.com").
+		Get("/collections").
+		MatchParams(map[string]string{"workspace": "ws2"}).
+		Reply(200).
+		JSON(map[string]any{"collections": []map[string]string{{"id": "col4", "uid": "user-col4"}}})
+
+	c := NewClient("super secret token")
+	c.limiter = nil
+
+	tests := []struct {
+		workspace string
+		want      []string
+	}{
+		{workspace: "ws1", want: []string{"user-col1", "user-col2", "user-col3"}},
+		// Responses without pagination metadata hold every collection.
+		{workspace: "ws2", want: []string{"user-col4"}},
+	}
+	for _, tt := range tests {
+		collections, err := c.ListCollections(context.Background(), tt.workspace)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []string
+		for _, collection := range collections {
+			got = append(got, collection.UUID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expected collections %v for %s, got: %v", tt.want, tt.workspace, got)
+		}
+	}
+	if !gock.IsDone() {
+		t.Errorf("expected every page of collections to be requested")
+	}
+}
@@ -0,0 +1,122 @@
+package s3
+
+import (
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// pageTracker tracks the objects of the pages of a bucket listing that are
+// being scanned. Once every object of a page, and of the pages listed before
+// it, has been scanned, the token to list the page after it is saved, so that
+// an interrupted scan resumes listing the bucket from there. An empty token
+// is saved once the last page has been scanned.
+type pageTracker struct {
+	save func(nextToken string)
+
+	mu sync.Mutex
+	// pages are the listed pages that haven't been scanned, in listing order.
+	pages []*listedPage
+}
+
+// listedPage is a page whose objects are being scanned. A nil page tracks
+// nothing.
+type listedPage struct {
+	tracker   *pageTracker
+	nextToken string
+	// pending is the number of objects of the page being scanned.
+	pending int
+	// dispatched is set once every object of the page has been dispatched.
+	dispatched bool
+}
+
+func newPageTracker(save func(nextToken string)) *pageTracker {
+	return &pageTracker{save: save}
+}
+
+// add starts tracking a listed page, whose next page is listed with the
+// given token.
+func (t *pageTracker) add(nextToken string) *listedPage {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	page := &listedPage{tracker: t, nextToken: nextToken}
+	t.pages = append(t.pages, page)
+	return page
+}
+
+// start records that an object of the page is dispatched to be scanned.
+func (p *listedPage) start() {
+	if p == nil {
+		return
+	}
+	p.tracker.mu.Lock()
+	defer p.tracker.mu.Unlock()
+	p.pending++
+}
+
+// done records that an object of the page has been scanned.
+func (p *listedPage) done() {
+	if p == nil {
+		return
+	}
+	p.tracker.mu.Lock()
+	defer p.tracker.mu.Unlock()
+	p.pending--
+	p.tracker.advance()
+}
+
+// dispatchedAll records that every object of the page has been dispatched.
+func (p *listedPage) dispatchedAll() {
+	if p == nil {
+		return
+	}
+	p.tracker.mu.Lock()
+	defer p.tracker.mu.Unlock()
+	p.dispatched = true
+	p.tracker.advance()
+}
+
+// advance saves the token after the last of the oldest pages that have been
+// scanned. The caller must hold the lock.
+func (t *pageTracker) advance() {
+	scanned := 0
+	for _, page := range t.pages {
+		if !page.dispatched || page.pending > 0 {
+			break
+		}
+		scanned++
+	}
+	if scanned == 0 {
+		return
+	}
+	nextToken := t.pages[scanned-1].nextToken
+	t.pages = t.pages[scanned:]
+	t.save(nextToken)
+}
+
+// trackingReporter reports the chunks of an object to a channel, tracking
+// each of them in the checkpoint so the object is only recorded as scanned
+// once their results have been reported.
+type trackingReporter struct {
+	ch         chan<- *sources.Chunk
+	checkpoint *sources.Checkpoint
+	tracker    *sources.ChunkTracker
+}
+
+func (r trackingReporter) ChunkOk(ctx context.Context, chunk sources.Chunk) error {
+	r.checkpoint.TrackChunk(&chunk, r.tracker)
+	if err := common.CancellableWrite(ctx, r.ch, &chunk); err != nil {
+		r.checkpoint.ChunkTracker(&chunk).Done()
+		return err
+	}
+	return nil
+}
+
+func (r trackingReporter) ChunkErr(ctx context.Context, err error) error {
+	return sources.ChanReporter{Ch: r.ch}.ChunkErr(ctx, err)
+}
@@ -0,0 +1,149 @@
+package s3
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestPageTracker(t *testing.T) {
+	var saved []string
+	tracker := newPageTracker(func(nextToken string) { saved = append(saved, nextToken) })
+
+	first, second, last := tracker.add("2"), tracker.add("3"), tracker.add("")
+	first.start()
+	first.start()
+	first.dispatchedAll()
+	second.start()
+	second.dispatchedAll()
+	// The last page is empty.
+	last.dispatchedAll()
+
+	// The second page was scanned, but not every object of the first one.
+	second.done()
+	first.done()
+	assert.Empty(t, saved)
+
+	first.done()
+	assert.Equal(t, []string{""}, saved)
+
+	// A nil tracker tracks nothing.
+	var none *pageTracker
+	page := none.add("2")
+	page.start()
+	page.done()
+	page.dispatchedAll()
+}
+
+// fakePagedBucket serves a bucket whose objects are listed one per page. The
+// listing of the page with the failing token is denied.
+func fakePagedBucket(t *testing.T, failingToken string) (*httptest.Server, *[]string) {
+	var tokens []string
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodHead && r.URL.Path == "/bucket":
+			w.Header().Set("X-Amz-Bucket-Region", "us-east-1")
+		case r.URL.Path == "/bucket" && query.Get("list-type") == "2":
+			token := query.Get("continuation-token")
+			tokens = append(tokens, token)
+			if token == failingToken {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+				return
+			}
+			page, next := 1, "<NextContinuationToken>page-2</NextContinuationToken><IsTruncated>true</IsTruncated>"
+			switch token {
+			case "page-2":
+				page, next = 2, "<NextContinuationToken>page-3</NextContinuationToken><IsTruncated>true</IsTruncated>"
+			case "page-3":
+				page, next = 3, "<IsTruncated>false</IsTruncated>"
+			}
+			fmt.Fprintf(w, `<ListBucketResult>
+<Contents><Key>object-%d.txt</Key><LastModified>2024-01-01T00:00:00.000Z</LastModified><Size>8</Size><StorageClass>STANDARD</StorageClass></Contents>
+%s</ListBucketResult>`, page, next)
+		default:
+			fmt.Fprintf(w, "secret %s", filepath.Base(r.URL.Path)[len("object-"):][:1])
+		}
+	})), &tokens
+}
+
+func TestSource_ResumeListing(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	// scan reports the results of the chunks it gets if processed is set.
+	scan := func(failingToken string, processed bool) ([]string, []string) {
+		server, tokens := fakePagedBucket(t, failingToken)
+		defer server.Close()
+
+		conn, err := anypb.New(&sourcespb.S3{
+			Credential: &sourcespb.S3_Unauthenticated{Unauthenticated: &credentialspb.Unauthenticated{}},
+			Buckets:    []string{"bucket"},
+		})
+		assert.NoError(t, err)
+		s := Source{}
+		assert.NoError(t, s.Init(ctx, "test", 0, 0, false, conn, 2))
+		cp, err := sources.LoadCheckpoint(ctx, path, "hash")
+		assert.NoError(t, err)
+		s.SetCheckpoint(cp)
+
+		sess, err := session.NewSession(&aws.Config{
+			Endpoint:         aws.String(server.URL),
+			Region:           aws.String(defaultAWSRegion),
+			Credentials:      credentials.AnonymousCredentials,
+			S3ForcePathStyle: aws.Bool(true),
+		})
+		assert.NoError(t, err)
+
+		chunksCh := make(chan *sources.Chunk, 8)
+		s.scanBuckets(ctx, s3.New(sess), "", []string{"bucket"}, chunksCh)
+		close(chunksCh)
+
+		var got []string
+		for chunk := range chunksCh {
+			got = append(got, string(chunk.Data))
+			if processed {
+				cp.ChunkTracker(chunk).Done()
+			}
+		}
+		assert.NoError(t, cp.Flush())
+		sort.Strings(got)
+		return got, *tokens
+	}
+
+	// Objects whose chunks were handed off but not processed aren't recorded.
+	got, tokens := scan("page-3", false)
+	assert.Equal(t, []string{"secret 1", "secret 2"}, got)
+	assert.Equal(t, []string{"", "page-2", "page-3"}, tokens)
+
+	// The scan is interrupted while listing the last page, so the listing
+	// resumes from it.
+	got, tokens = scan("page-3", true)
+	assert.Equal(t, []string{"secret 1", "secret 2"}, got)
+	assert.Equal(t, []string{"", "page-2", "page-3"}, tokens)
+
+	got, tokens = scan("", true)
+	assert.Equal(t, []string{"secret 3"}, got)
+	assert.Equal(t, []string{"page-3"}, tokens)
+
+	// The bucket was fully scanned.
+	got, tokens = scan("", true)
+	assert.Empty(t, got)
+	assert.Empty(t, tokens)
+}
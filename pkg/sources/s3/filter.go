@@ -0,0 +1,63 @@
+package s3
+
+import (
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// keyFilter selects the objects that are scanned by their key, so that the other objects of a
+// bucket are skipped as they're listed, without being downloaded.
+type keyFilter struct {
+	includePrefixes []string
+	excludePrefixes []string
+	excludeSuffixes []string
+}
+
+func newKeyFilter(conn *sourcespb.S3) keyFilter {
+	return keyFilter{
+		includePrefixes: conn.GetIncludePrefixes(),
+		excludePrefixes: conn.GetExcludePrefixes(),
+		excludeSuffixes: conn.GetExcludeSuffixes(),
+	}
+}
+
+// matches reports whether the object with the given key is scanned: its key starts with one of
+// the included prefixes, if there are any, and neither starts nor ends with an excluded prefix
+// or suffix. Exclusions take precedence over inclusions.
+func (f keyFilter) matches(key string) bool {
+	for _, prefix := range f.excludePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	for _, suffix := range f.excludeSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return false
+		}
+	}
+	if len(f.includePrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range f.includePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// listPrefix returns the prefix the listing of a bucket is limited to, which is the longest
+// prefix common to all the included prefixes, so that S3 only lists the keys that may match.
+func (f keyFilter) listPrefix() string {
+	if len(f.includePrefixes) == 0 {
+		return ""
+	}
+	common := f.includePrefixes[0]
+	for _, prefix := range f.includePrefixes[1:] {
+		for !strings.HasPrefix(prefix, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	return common
+}
@@ -2,6 +2,7 @@ package s3
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -16,7 +17,6 @@ import (
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/go-errors/errors"
 	"github.com/go-logr/logr"
-	"golang.org/x/sync/errgroup"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
@@ -35,6 +35,9 @@ const (
 	defaultAWSRegion     = "us-east-1"
 	defaultMaxObjectSize = 250 * 1024 * 1024 // 250 MiB
 	maxObjectSizeLimit   = 250 * 1024 * 1024 // 250 MiB
+
+	// assumeRoleExpiryWindow is how long before expiry assumed role credentials are refreshed.
+	assumeRoleExpiryWindow = 5 * time.Minute
 )
 
 type Source struct {
@@ -47,8 +50,14 @@ type Source struct {
 	sources.Progress
 	errorCount    *sync.Map
 	conn          *sourcespb.S3
-	jobPool       *errgroup.Group
+	jobPool       *sources.DownloadPool
 	maxObjectSize int64
+	// keys selects the objects that are scanned by their key.
+	keys keyFilter
+	// checkpoint records the position of the listing of each bucket, if set.
+	checkpoint *sources.Checkpoint
+	// unitErrFunc is called with the buckets that fail and are skipped, if set.
+	unitErrFunc func(unit sources.SourceUnit, err error)
 	sources.CommonSourceUnitUnmarshaller
 }
 
@@ -56,6 +65,10 @@ type Source struct {
 var _ sources.Source = (*Source)(nil)
 var _ sources.SourceUnitUnmarshaller = (*Source)(nil)
 var _ sources.Validator = (*Source)(nil)
+var _ sources.UnitErrorSetter = (*Source)(nil)
+
+// unitBucket is the kind of the units of buckets.
+const unitBucket sources.SourceUnitKind = "bucket"
 
 // Type returns the type of source
 func (s *Source) Type() sourcespb.SourceType {
@@ -81,8 +94,7 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 	s.concurrency = concurrency
 	s.errorCount = &sync.Map{}
 	s.log = aCtx.Logger()
-	s.jobPool = &errgroup.Group{}
-	s.jobPool.SetLimit(concurrency)
+	s.jobPool = sources.NewDownloadPool(concurrency)
 
 	var conn sourcespb.S3
 	err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{})
@@ -92,6 +104,7 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 	s.conn = &conn
 
 	s.setMaxObjectSize(conn.GetMaxObjectSize())
+	s.keys = newKeyFilter(&conn)
 
 	if len(conn.Buckets) > 0 && len(conn.IgnoreBuckets) > 0 {
 		return fmt.Errorf("either a bucket include list or a bucket ignore list can be specified, but not both")
@@ -100,6 +113,74 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 	return nil
 }
 
+// SetCheckpoint records the listing position of each bucket in cp, and the
+// buckets that have been fully scanned, so that an interrupted scan resumes
+// listing each bucket where it left off. Listing the versions of objects
+// always starts from the beginning of a bucket.
+func (s *Source) SetCheckpoint(cp *sources.Checkpoint) {
+	s.checkpoint = cp
+}
+
+// SetUnitErrorFunc sets the function the buckets that can't be listed are
+// reported to. The buckets a role can't list aren't reported, as roles are
+// expected not to have access to every bucket of their account.
+func (s *Source) SetUnitErrorFunc(f func(unit sources.SourceUnit, err error)) {
+	s.unitErrFunc = f
+}
+
+// reportBucketError reports a bucket that failed and is skipped.
+func (s *Source) reportBucketError(bucket string, err error) {
+	if s.unitErrFunc != nil {
+		s.unitErrFunc(sources.CommonSourceUnit{Kind: unitBucket, ID: bucket}, err)
+	}
+}
+
+// checkpointKey returns the key the listing position of a bucket is recorded
+// with.
+func (s *Source) checkpointKey(role, bucket string) string {
+	return sources.CheckpointKey(s.name, sources.CommonSourceUnit{ID: strings.TrimPrefix(role+"/"+bucket, "/"), Kind: unitBucket})
+}
+
+// chunkReporter returns the reporter of the chunks of an object, which are
+// tracked by tracker when the listing is checkpointed.
+func (s *Source) chunkReporter(ch chan<- *sources.Chunk, tracker *sources.ChunkTracker) sources.ChunkReporter {
+	if tracker == nil {
+		return sources.ChanReporter{Ch: ch}
+	}
+	return trackingReporter{ch: ch, checkpoint: s.checkpoint, tracker: tracker}
+}
+
+// bucketPages returns the input listing the objects of a bucket from the
+// position recorded in the checkpoint, and the tracker of the listed pages
+// that records the position as they're scanned. The tracker is nil without a
+// checkpoint.
+func (s *Source) bucketPages(ctx context.Context, role, bucket string) (*s3.ListObjectsV2Input, *pageTracker) {
+	input := &s3.ListObjectsV2Input{Bucket: &bucket}
+	if prefix := s.keys.listPrefix(); prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	if s.checkpoint == nil {
+		return input, nil
+	}
+
+	key := s.checkpointKey(role, bucket)
+	if token := s.checkpoint.Position(key); token != "" {
+		ctx.Logger().V(2).Info("resuming bucket listing from checkpoint", "bucket", bucket)
+		input.ContinuationToken = aws.String(token)
+	}
+	return input, newPageTracker(func(nextToken string) {
+		var err error
+		if nextToken == "" {
+			err = s.checkpoint.MarkCompleted(key)
+		} else {
+			err = s.checkpoint.SetPosition(key, nextToken)
+		}
+		if err != nil {
+			ctx.Logger().Error(err, "error writing checkpoint")
+		}
+	})
+}
+
 func (s *Source) Validate(ctx context.Context) []error {
 	var errs []error
 	visitor := func(c context.Context, defaultRegionClient *s3.S3, roleArn string, buckets []string) {
@@ -154,6 +235,11 @@ func (s *Source) newClient(region, roleArn string) (*s3.S3, error) {
 		stsClient := sts.New(sess)
 		cfg.Credentials = stscreds.NewCredentialsWithClient(stsClient, roleArn, func(p *stscreds.AssumeRoleProvider) {
 			p.RoleSessionName = "trufflehog"
+			// Refresh the temporary credentials before they expire so long scans are not interrupted.
+			p.ExpiryWindow = assumeRoleExpiryWindow
+			if externalID := s.conn.GetExternalId(); externalID != "" {
+				p.ExternalID = aws.String(externalID)
+			}
 		})
 	}
 
@@ -206,7 +292,7 @@ func (s *Source) scanBuckets(ctx context.Context, client *s3.S3, role string, bu
 		logger := logger.WithValues("bucket", bucket)
 
 		if common.IsDone(ctx) {
-			return
+			break
 		}
 
 		s.SetProgressComplete(i, len(bucketsToScan), fmt.Sprintf("Bucket: %s", bucket), "")
@@ -215,21 +301,45 @@ func (s *Source) scanBuckets(ctx context.Context, client *s3.S3, role string, bu
 		regionalClient, err := s.getRegionalClientForBucket(ctx, client, role, bucket)
 		if err != nil {
 			logger.Error(err, "could not get regional client for bucket")
+			if role == "" {
+				s.reportBucketError(bucket, err)
+			}
+			continue
+		}
+
+		if s.checkpoint != nil && s.checkpoint.Completed(s.checkpointKey(role, bucket)) {
+			logger.V(3).Info("Skipping bucket completed in checkpoint")
 			continue
 		}
 
 		errorCount := sync.Map{}
 
-		err = regionalClient.ListObjectsV2PagesWithContext(
-			ctx, &s3.ListObjectsV2Input{Bucket: &bucket},
-			func(page *s3.ListObjectsV2Output, last bool) bool {
-				s.pageChunker(ctx, regionalClient, chunksChan, bucket, page, &errorCount, i+1, &objectCount)
-				return true
-			})
+		if s.conn.GetScanObjectVersions() && s.isVersioned(ctx, regionalClient, bucket) {
+			input := &s3.ListObjectVersionsInput{Bucket: &bucket}
+			if prefix := s.keys.listPrefix(); prefix != "" {
+				input.Prefix = aws.String(prefix)
+			}
+			err = regionalClient.ListObjectVersionsPagesWithContext(
+				ctx, input,
+				func(page *s3.ListObjectVersionsOutput, last bool) bool {
+					s.pageChunker(ctx, regionalClient, chunksChan, bucket, role, versionObjects(page), nil, &errorCount, i+1, &objectCount)
+					return true
+				})
+		} else {
+			input, pages := s.bucketPages(ctx, role, bucket)
+			err = regionalClient.ListObjectsV2PagesWithContext(
+				ctx, input,
+				func(page *s3.ListObjectsV2Output, last bool) bool {
+					listed := pages.add(aws.StringValue(page.NextContinuationToken))
+					s.pageChunker(ctx, regionalClient, chunksChan, bucket, role, currentObjects(page), listed, &errorCount, i+1, &objectCount)
+					return true
+				})
+		}
 
 		if err != nil {
 			if role == "" {
 				logger.Error(err, "could not list objects in bucket")
+				s.reportBucketError(bucket, err)
 			} else {
 				// Our documentation blesses specifying a role to assume without specifying buckets to scan, which will
 				// often cause this to happen a lot (because in that case the scanner tries to scan every bucket in the
@@ -240,6 +350,11 @@ func (s *Source) scanBuckets(ctx context.Context, client *s3.S3, role string, bu
 			}
 		}
 	}
+	// Objects are still being downloaded when the last page is listed.
+	s.jobPool.Wait()
+	if common.IsDone(ctx) {
+		return
+	}
 	s.SetProgressComplete(len(bucketsToScan), len(bucketsToScan), fmt.Sprintf("Completed scanning source %s. %d objects scanned.", s.name, objectCount), "")
 }
 
@@ -270,15 +385,74 @@ func (s *Source) getRegionalClientForBucket(ctx context.Context, defaultRegionCl
 	return regionalClient, nil
 }
 
-// pageChunker emits chunks onto the given channel from a page
-func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, page *s3.ListObjectsV2Output, errorCount *sync.Map, pageNumber int, objectCount *uint64) {
+// isVersioned reports whether versioning is, or was, enabled on a bucket, so
+// it may retain prior versions of its objects. Buckets whose versioning
+// status can't be read are treated as unversioned.
+func (s *Source) isVersioned(ctx context.Context, client *s3.S3, bucket string) bool {
+	res, err := client.GetBucketVersioningWithContext(ctx, &s3.GetBucketVersioningInput{Bucket: &bucket})
+	if err != nil {
+		s.log.V(2).Info("could not get bucket versioning, scanning current versions only", "bucket", bucket, "err", err)
+		return false
+	}
+	// The status is only set once versioning has been enabled. Suspended
+	// buckets keep the versions created while it was enabled.
+	return res.Status != nil
+}
+
+// object is an S3 object, or one of its versions, to scan.
+type object struct {
+	*s3.Object
+	// versionID is only set when the versions of the objects are listed.
+	versionID string
+}
+
+// currentObjects returns the current versions of the objects of a page.
+func currentObjects(page *s3.ListObjectsV2Output) []object {
+	objects := make([]object, 0, len(page.Contents))
 	for _, obj := range page.Contents {
+		if obj != nil {
+			objects = append(objects, object{Object: obj})
+		}
+	}
+	return objects
+}
+
+// versionObjects returns every version of the objects of a page. Delete
+// markers are not versions with content, so they are skipped.
+func versionObjects(page *s3.ListObjectVersionsOutput) []object {
+	objects := make([]object, 0, len(page.Versions))
+	for _, v := range page.Versions {
+		if v == nil {
+			continue
+		}
+		objects = append(objects, object{
+			Object: &s3.Object{
+				Key:          v.Key,
+				LastModified: v.LastModified,
+				Owner:        v.Owner,
+				Size:         v.Size,
+				StorageClass: v.StorageClass,
+			},
+			versionID: aws.StringValue(v.VersionId),
+		})
+	}
+	return objects
+}
+
+// pageChunker emits chunks onto the given channel from the objects of a page.
+// The objects are downloaded on the job pool, so the next page is listed while
+// they are scanned. It only waits for a worker to be free. The scanned objects
+// are recorded in the listed page, if set.
+func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket, role string, objects []object, page *listedPage, errorCount *sync.Map, pageNumber int, objectCount *uint64) {
+	for _, obj := range objects {
 		obj := obj
 		if common.IsDone(ctx) {
 			return
 		}
 
-		if obj == nil {
+		// Objects filtered out by their key aren't downloaded, whatever their size.
+		if !s.keys.matches(*obj.Key) {
+			s.log.V(5).Info("Skipping object filtered out by its key", "object", *obj.Key)
 			continue
 		}
 
@@ -306,12 +480,20 @@ func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan
 			continue
 		}
 
-		s.jobPool.Go(func() error {
+		page.start()
+		scheduled := s.jobPool.Go(ctx, func() {
+			// The object is recorded as scanned once the results of its chunks have been
+			// reported. It isn't recorded if the scan exits.
+			var tracker *sources.ChunkTracker
+			if page != nil {
+				tracker = sources.NewChunkTracker(page.done)
+			}
+			defer tracker.Done()
 			defer common.RecoverWithExit(ctx)
 
 			if strings.HasSuffix(*obj.Key, "/") {
 				s.log.V(5).Info("Skipping directory", "object", *obj.Key)
-				return nil
+				return
 			}
 
 			path := strings.Split(*obj.Key, "/")
@@ -323,17 +505,21 @@ func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan
 			}
 			if nErr.(int) > 3 {
 				s.log.V(2).Info("Skipped due to excessive errors", "object", *obj.Key)
-				return nil
+				return
 			}
 
 			// files break with spaces, must replace with +
 			// objKey := strings.ReplaceAll(*obj.Key, " ", "+")
 			ctx, cancel := context.WithTimeout(ctx, time.Second*5)
 			defer cancel()
-			res, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+			input := &s3.GetObjectInput{
 				Bucket: &bucket,
 				Key:    obj.Key,
-			})
+			}
+			if obj.versionID != "" {
+				input.VersionId = aws.String(obj.versionID)
+			}
+			res, err := client.GetObjectWithContext(ctx, input)
 			if err != nil {
 				if !strings.Contains(err.Error(), "AccessDenied") {
 					s.log.Error(err, "could not get S3 object", "object", *obj.Key)
@@ -345,7 +531,7 @@ func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan
 				}
 				if nErr.(int) > 3 {
 					s.log.V(3).Info("Skipped due to excessive errors", "object", *obj.Key)
-					return nil
+					return
 				}
 				nErr = nErr.(int) + 1
 				errorCount.Store(prefix, nErr)
@@ -353,7 +539,7 @@ func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan
 				if nErr.(int) > 3 {
 					s.log.V(2).Info("Too many consecutive errors, excluding prefix", "prefix", prefix)
 				}
-				return nil
+				return
 			}
 
 			email := "Unknown"
@@ -361,6 +547,10 @@ func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan
 				email = *obj.Owner.DisplayName
 			}
 			modified := obj.LastModified.String()
+			link := makeS3Link(bucket, *client.Config.Region, *obj.Key)
+			if obj.versionID != "" {
+				link += "?versionId=" + url.QueryEscape(obj.versionID)
+			}
 			chunkSkel := &sources.Chunk{
 				SourceType: s.Type(),
 				SourceName: s.name,
@@ -371,18 +561,25 @@ func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan
 						S3: &source_metadatapb.S3{
 							Bucket:    bucket,
 							File:      sanitizer.UTF8(*obj.Key),
-							Link:      sanitizer.UTF8(makeS3Link(bucket, *client.Config.Region, *obj.Key)),
+							Link:      sanitizer.UTF8(link),
 							Email:     sanitizer.UTF8(email),
 							Timestamp: sanitizer.UTF8(modified),
+							RoleArn:   role,
+							VersionId: obj.versionID,
 						},
 					},
 				},
 				Verify: s.verify,
 			}
 
-			if err := handlers.HandleFile(ctx, res.Body, chunkSkel, sources.ChanReporter{Ch: chunksChan}); err != nil {
+			// Compressed objects without the extension of their format are identified by their
+			// content type or encoding, so they're decompressed like files named after it.
+			if err := handlers.HandleFile(ctx, res.Body, chunkSkel, s.chunkReporter(chunksChan, tracker),
+				handlers.WithFileName(*obj.Key),
+				handlers.WithContentType(aws.StringValue(res.ContentType), aws.StringValue(res.ContentEncoding)),
+			); err != nil {
 				ctx.Logger().Error(err, "error handling file")
-				return nil
+				return
 			}
 
 			atomic.AddUint64(objectCount, 1)
@@ -394,12 +591,12 @@ func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan
 			if nErr.(int) > 0 {
 				errorCount.Store(prefix, 0)
 			}
-
-			return nil
 		})
+		if !scheduled {
+			return
+		}
 	}
-
-	_ = s.jobPool.Wait()
+	page.dispatchedAll()
 }
 
 func (s *Source) validateBucketAccess(ctx context.Context, client *s3.S3, roleArn string, buckets []string) []error {
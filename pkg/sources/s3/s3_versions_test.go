@@ -0,0 +1,138 @@
+package s3
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// fakeVersionedBucket serves a bucket with two versions of an object, a
+// delete marker and a version that is too large to scan.
+func fakeVersionedBucket(t *testing.T, versioning string) *httptest.Server {
+	objects := map[string]string{
+		"null": "current secret",
+		"v1":   "overwritten secret",
+		"v2":   "current secret",
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodHead && r.URL.Path == "/bucket":
+			w.Header().Set("X-Amz-Bucket-Region", "us-east-1")
+		case r.URL.Path == "/bucket" && query.Has("versioning"):
+			fmt.Fprintf(w, `<VersioningConfiguration>%s</VersioningConfiguration>`, versioning)
+		case r.URL.Path == "/bucket" && query.Has("versions"):
+			fmt.Fprint(w, `<ListVersionsResult>
+<Version><Key>config.env</Key><VersionId>v2</VersionId><LastModified>2024-01-01T00:00:00.000Z</LastModified><IsLatest>true</IsLatest><Size>14</Size><StorageClass>STANDARD</StorageClass></Version>
+<DeleteMarker><Key>config.env</Key><VersionId>v3</VersionId><LastModified>2024-01-01T00:00:00.000Z</LastModified><IsLatest>false</IsLatest></DeleteMarker>
+<Version><Key>config.env</Key><VersionId>v1</VersionId><LastModified>2024-01-01T00:00:00.000Z</LastModified><IsLatest>false</IsLatest><Size>18</Size><StorageClass>STANDARD</StorageClass></Version>
+<Version><Key>config.env</Key><VersionId>v0</VersionId><LastModified>2024-01-01T00:00:00.000Z</LastModified><IsLatest>false</IsLatest><Size>4096</Size><StorageClass>STANDARD</StorageClass></Version>
+</ListVersionsResult>`)
+		case r.URL.Path == "/bucket" && query.Get("list-type") == "2":
+			fmt.Fprint(w, `<ListBucketResult>
+<Contents><Key>config.env</Key><LastModified>2024-01-01T00:00:00.000Z</LastModified><Size>14</Size><StorageClass>STANDARD</StorageClass></Contents>
+</ListBucketResult>`)
+		case r.URL.Path == "/bucket/config.env":
+			version := query.Get("versionId")
+			if version == "" {
+				version = "null"
+			}
+			body, ok := objects[version]
+			if !ok {
+				t.Errorf("unexpected version %q", version)
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, body)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestSource_ScanObjectVersions(t *testing.T) {
+	tests := []struct {
+		name       string
+		versions   bool
+		versioning string
+		want       []string
+	}{
+		{
+			name:       "versioned bucket",
+			versions:   true,
+			versioning: "<Status>Enabled</Status>",
+			want:       []string{"v1 overwritten secret", "v2 current secret"},
+		},
+		{
+			name:       "suspended versioning",
+			versions:   true,
+			versioning: "<Status>Suspended</Status>",
+			want:       []string{"v1 overwritten secret", "v2 current secret"},
+		},
+		{
+			name:     "unversioned bucket",
+			versions: true,
+			want:     []string{" current secret"},
+		},
+		{
+			name:       "versions not requested",
+			versioning: "<Status>Enabled</Status>",
+			want:       []string{" current secret"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := fakeVersionedBucket(t, tt.versioning)
+			defer server.Close()
+
+			conn, err := anypb.New(&sourcespb.S3{
+				Credential:         &sourcespb.S3_Unauthenticated{Unauthenticated: &credentialspb.Unauthenticated{}},
+				Buckets:            []string{"bucket"},
+				MaxObjectSize:      1024,
+				ScanObjectVersions: tt.versions,
+			})
+			assert.NoError(t, err)
+			s := Source{}
+			assert.NoError(t, s.Init(ctx, "test", 0, 0, false, conn, 1))
+
+			sess, err := session.NewSession(&aws.Config{
+				Endpoint:         aws.String(server.URL),
+				Region:           aws.String(defaultAWSRegion),
+				Credentials:      credentials.AnonymousCredentials,
+				S3ForcePathStyle: aws.Bool(true),
+			})
+			assert.NoError(t, err)
+
+			chunksCh := make(chan *sources.Chunk, 8)
+			s.scanBuckets(ctx, s3.New(sess), "", []string{"bucket"}, chunksCh)
+			close(chunksCh)
+
+			var got []string
+			for chunk := range chunksCh {
+				metadata := chunk.SourceMetadata.GetS3()
+				got = append(got, metadata.GetVersionId()+" "+string(chunk.Data))
+				if metadata.GetVersionId() != "" {
+					assert.Contains(t, metadata.GetLink(), "?versionId="+metadata.GetVersionId())
+				}
+			}
+			sort.Strings(got)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package sources
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"runtime"
@@ -27,11 +28,29 @@ type SourceManager struct {
 	wg          sync.WaitGroup
 	// Max number of units to scan concurrently per source.
 	concurrentUnits int
+	// Optional max number of units to scan concurrently per source type,
+	// overriding concurrentUnits.
+	concurrentUnitsFunc func(sourcespb.SourceType) int
 	// Run the sources using source unit enumeration / chunking if available.
 	// Checked at runtime to allow feature flagging.
 	useSourceUnitsFunc func() bool
 	// Downstream chunks channel to be scanned.
 	outputChunks chan *Chunk
+	// Optional record of completed units used to resume interrupted scans.
+	checkpoint *Checkpoint
+	// Number of bytes consecutive chunks of the sources overlap by, if set.
+	chunkOverlap int
+	// Only enumerate or validate sources without producing any chunks.
+	enumerateOnly bool
+	// Skip the units that fail instead of failing their source.
+	continueOnSourceError bool
+	// Sources still running at the deadline are cancelled, if it's set.
+	deadline         time.Time
+	deadlineExceeded atomic.Bool
+	// Sources still running when the manager is stopped are cancelled with
+	// the cause of the stop.
+	stopCtx context.Context
+	stop    context.CancelCauseFunc
 	// Set when Wait() returns.
 	firstErr chan error
 	waitErr  error
@@ -74,6 +93,7 @@ func WithConcurrentTargets(concurrency int) func(*SourceManager) {
 }
 
 // WithBufferedOutput sets the size of the buffer used for the Chunks() channel.
+// Sources block when the buffer is full until chunks are consumed.
 func WithBufferedOutput(size int) func(*SourceManager) {
 	return func(mgr *SourceManager) { mgr.outputChunks = make(chan *Chunk, size) }
 }
@@ -100,6 +120,59 @@ func WithConcurrentUnits(n int) func(*SourceManager) {
 	return func(mgr *SourceManager) { mgr.concurrentUnits = n }
 }
 
+// WithConcurrentUnitsFunc dynamically limits the number of units to be
+// scanned concurrently depending on the type of the source, so that sources
+// that spend most of their time waiting on the network can scan more units at
+// once. It takes precedence over WithConcurrentUnits.
+func WithConcurrentUnitsFunc(f func(sourcespb.SourceType) int) func(*SourceManager) {
+	return func(mgr *SourceManager) { mgr.concurrentUnitsFunc = f }
+}
+
+// WithCheckpoint skips units already recorded as completed in the checkpoint
+// and records units once the results of all of their chunks have been
+// reported. The consumer of the chunks must release the tracker of each of
+// them, from Checkpoint.ChunkTracker, once its results have been reported.
+// Only sources that support unit
+// enumeration and chunking are checkpointed.
+func WithCheckpoint(cp *Checkpoint) func(*SourceManager) {
+	return func(mgr *SourceManager) { mgr.checkpoint = cp }
+}
+
+// ErrDeadlineExceeded is the cause of the cancellation of the sources still
+// running at the deadline set with WithDeadline.
+var ErrDeadlineExceeded = errors.New("maximum scan duration exceeded")
+
+// WithChunkOverlap makes the consecutive chunks the sources read overlap by
+// size bytes, by running them with a ChunkOverlapContext.
+func WithChunkOverlap(size int) func(*SourceManager) {
+	return func(mgr *SourceManager) { mgr.chunkOverlap = size }
+}
+
+// WithDeadline cancels the sources still running at the deadline, and skips
+// the sources run after it. The sources stop producing chunks, but the chunks
+// already produced can still be read. The errors of the cancelled sources are
+// not returned by Wait.
+func WithDeadline(deadline time.Time) func(*SourceManager) {
+	return func(mgr *SourceManager) { mgr.deadline = deadline }
+}
+
+// WithEnumerationOnly runs sources without reading any of their content.
+// Units are enumerated and reported to the hooks instead of being chunked.
+// Sources that don't support enumeration are validated if they implement
+// Validator and otherwise only initialized.
+func WithEnumerationOnly() func(*SourceManager) {
+	return func(mgr *SourceManager) { mgr.enumerateOnly = true }
+}
+
+// WithContinueOnSourceError skips the units that fail to be enumerated or
+// scanned instead of failing their source, so that one inaccessible repository
+// doesn't fail the scan of the others. Their errors are logged and reported as
+// Skipped. Sources that don't enumerate units report theirs if they implement
+// UnitErrorSetter.
+func WithContinueOnSourceError() func(*SourceManager) {
+	return func(mgr *SourceManager) { mgr.continueOnSourceError = true }
+}
+
 // The default channel size for all the channels that are used to transport chunks.
 const defaultChannelSize = 64
 
@@ -113,9 +186,11 @@ func NewManager(opts ...func(*SourceManager)) *SourceManager {
 		outputChunks: make(chan *Chunk, defaultChannelSize),
 		firstErr:     make(chan error, 1),
 	}
+	mgr.stopCtx, mgr.stop = context.WithCancelCause(context.Background())
 	for _, opt := range opts {
 		opt(&mgr)
 	}
+	outputChunksCap.Set(float64(cap(mgr.outputChunks)))
 	return &mgr
 }
 
@@ -143,7 +218,28 @@ func (s *SourceManager) Run(ctx context.Context, sourceName string, source Sourc
 	}
 	ctx, cancel := context.WithCancelCause(ctx)
 	progress := NewJobProgress(jobID, sourceID, sourceName, WithHooks(s.hooks...), WithCancel(cancel))
-	if err := sem.Acquire(ctx, 1); err != nil {
+	cancelDeadline := context.CancelFunc(func() {})
+	if !s.deadline.IsZero() {
+		ctx, cancelDeadline = context.WithDeadlineCause(ctx, s.deadline, ErrDeadlineExceeded)
+	}
+	stopSource := context.AfterFunc(s.stopCtx, func() { cancel(context.Cause(s.stopCtx)) })
+	var err error
+	if s.stopCtx.Err() != nil {
+		// Sources run after the stop aren't started, even if the pool has room.
+		cancel(context.Cause(s.stopCtx))
+		err = ctx.Err()
+	} else {
+		err = sem.Acquire(ctx, 1)
+	}
+	if err != nil {
+		defer cancelDeadline()
+		defer stopSource()
+		if s.pastDeadline(ctx) || s.wasStopped(ctx) {
+			// The source is skipped without an error, like the sources cancelled at the deadline.
+			progress.ReportError(Fatal{context.Cause(ctx)})
+			progress.Finish()
+			return progress.Ref(), nil
+		}
 		// Context cancelled.
 		progress.ReportError(Fatal{err})
 		return progress.Ref(), Fatal{err}
@@ -157,9 +253,21 @@ func (s *SourceManager) Run(ctx context.Context, sourceName string, source Sourc
 		ctx := context.WithValues(ctx,
 			"source_manager_worker_id", common.RandomID(5),
 		)
+		ctx = ChunkOverlapContext(ctx, s.chunkOverlap)
 		defer common.Recover(ctx)
 		defer cancel(nil)
-		if err := s.run(ctx, source, progress, targets...); err != nil {
+		defer cancelDeadline()
+		defer stopSource()
+		err := s.run(ctx, source, progress, targets...)
+		if s.pastDeadline(ctx) {
+			ctx.Logger().Info("stopped source at the maximum scan duration")
+			return
+		}
+		if s.wasStopped(ctx) {
+			ctx.Logger().Info("stopped source", "cause", context.Cause(ctx).Error())
+			return
+		}
+		if err != nil {
 			select {
 			case s.firstErr <- err:
 			default:
@@ -169,6 +277,35 @@ func (s *SourceManager) Run(ctx context.Context, sourceName string, source Sourc
 	return progress.Ref(), nil
 }
 
+// pastDeadline reports whether ctx was cancelled at the deadline, and if so
+// records it.
+func (s *SourceManager) pastDeadline(ctx context.Context) bool {
+	if !errors.Is(context.Cause(ctx), ErrDeadlineExceeded) {
+		return false
+	}
+	s.deadlineExceeded.Store(true)
+	return true
+}
+
+// Stop cancels the running sources with cause, and skips the sources run
+// after it. Like at the deadline set with WithDeadline, the sources stop
+// producing chunks, but the chunks already produced can still be read, and the
+// errors of the stopped sources are not returned by Wait.
+func (s *SourceManager) Stop(cause error) {
+	s.stop(cause)
+}
+
+// wasStopped reports whether ctx was cancelled by Stop.
+func (s *SourceManager) wasStopped(ctx context.Context) bool {
+	return s.stopCtx.Err() != nil && errors.Is(context.Cause(ctx), context.Cause(s.stopCtx))
+}
+
+// DeadlineExceeded reports whether any source was cancelled or skipped
+// because of the deadline set with WithDeadline.
+func (s *SourceManager) DeadlineExceeded() bool {
+	return s.deadlineExceeded.Load()
+}
+
 // Chunks returns the read only channel of all the chunks produced by all of
 // the sources managed by this manager.
 func (s *SourceManager) Chunks() <-chan *Chunk {
@@ -206,7 +343,14 @@ func (s *SourceManager) Wait() error {
 // This method should rarely be used. TODO(THOG-1577): Remove when dependencies
 // no longer rely on this functionality.
 func (s *SourceManager) ScanChunk(chunk *Chunk) {
+	s.sendChunk(chunk)
+}
+
+// sendChunk queues chunk for scanning, blocking while the output buffer is
+// full, and records the depth of the buffer.
+func (s *SourceManager) sendChunk(chunk *Chunk) {
 	s.outputChunks <- chunk
+	outputChunksLen.Set(float64(len(s.outputChunks)))
 }
 
 // AvailableCapacity returns the number of concurrent jobs the manager can
@@ -272,12 +416,20 @@ func (s *SourceManager) run(ctx context.Context, source Source, report *JobProgr
 		ctx = context.WithValue(ctx, "source_type", source.Type().String())
 	}
 
+	if s.enumerateOnly {
+		return s.runEnumerationOnly(ctx, source, report)
+	}
+
 	// Check for the preferred method of tracking source units.
 	canUseSourceUnits := len(targets) == 0 && s.useSourceUnitsFunc != nil
 	if enumChunker, ok := source.(SourceUnitEnumChunker); ok && canUseSourceUnits && s.useSourceUnitsFunc() {
 		ctx.Logger().Info("running source",
 			"with_units", true)
-		return s.runWithUnits(ctx, enumChunker, report)
+		concurrentUnits := s.concurrentUnits
+		if s.concurrentUnitsFunc != nil {
+			concurrentUnits = s.concurrentUnitsFunc(source.Type())
+		}
+		return s.runWithUnits(ctx, enumChunker, report, concurrentUnits)
 	}
 	ctx.Logger().Info("running source",
 		"with_units", false,
@@ -286,6 +438,45 @@ func (s *SourceManager) run(ctx context.Context, source Source, report *JobProgr
 	return s.runWithoutUnits(ctx, source, report, targets...)
 }
 
+// runEnumerationOnly is a helper method to report the units of a Source
+// without chunking them. Sources that can't be enumerated are validated
+// instead, so configuration errors are still surfaced.
+func (s *SourceManager) runEnumerationOnly(ctx context.Context, source Source, report *JobProgress) error {
+	if enumerator, ok := source.(SourceUnitEnumerator); ok {
+		report.StartEnumerating(time.Now())
+		defer func() { report.EndEnumerating(time.Now()) }()
+		ctx.Logger().V(2).Info("enumerating source")
+		unitReporter := VisitorReporter{
+			VisitUnit: func(ctx context.Context, unit SourceUnit) error {
+				report.ReportUnit(unit)
+				return ctx.Err()
+			},
+			VisitErr: func(ctx context.Context, err error) error {
+				report.ReportError(err)
+				return nil
+			},
+		}
+		if err := enumerator.Enumerate(ctx, unitReporter); err != nil {
+			report.ReportError(Fatal{err})
+			return Fatal{err}
+		}
+		return nil
+	}
+
+	validator, ok := source.(Validator)
+	if !ok {
+		ctx.Logger().Info("source does not support enumeration or validation, skipping")
+		return nil
+	}
+	ctx.Logger().V(2).Info("validating source")
+	if errs := validator.Validate(ctx); len(errs) > 0 {
+		err := Fatal{errors.Join(errs...)}
+		report.ReportError(err)
+		return err
+	}
+	return nil
+}
+
 // runWithoutUnits is a helper method to run a Source. It has coarse-grained
 // job reporting.
 func (s *SourceManager) runWithoutUnits(ctx context.Context, source Source, report *JobProgress, targets ...ChunkingTarget) error {
@@ -299,7 +490,7 @@ func (s *SourceManager) runWithoutUnits(ctx context.Context, source Source, repo
 		for chunk := range ch {
 			chunk.JobID = source.JobID()
 			report.ReportChunk(nil, chunk)
-			s.outputChunks <- chunk
+			s.sendChunk(chunk)
 		}
 	}()
 	// Don't return from this function until the goroutine has finished
@@ -308,6 +499,9 @@ func (s *SourceManager) runWithoutUnits(ctx context.Context, source Source, repo
 	// stack.
 	defer wg.Wait()
 	defer close(ch)
+	if setter, ok := source.(UnitErrorSetter); ok && s.continueOnSourceError {
+		setter.SetUnitErrorFunc(func(unit SourceUnit, err error) { skipUnit(ctx, report, unit, err) })
+	}
 	if err := source.Chunks(ctx, ch, targets...); err != nil {
 		report.ReportError(Fatal{err})
 		return Fatal{err}
@@ -317,11 +511,13 @@ func (s *SourceManager) runWithoutUnits(ctx context.Context, source Source, repo
 
 // runWithUnits is a helper method to run a Source that is also a
 // SourceUnitEnumChunker. This allows better introspection of what is getting
-// scanned and any errors encountered.
-func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumChunker, report *JobProgress) error {
+// scanned and any errors encountered. At most concurrentUnits units are
+// scanned at once.
+func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumChunker, report *JobProgress, concurrentUnits int) error {
 	unitReporter := &mgrUnitReporter{
-		unitCh: make(chan SourceUnit, 1),
-		report: report,
+		unitCh:          make(chan SourceUnit, 1),
+		report:          report,
+		continueOnError: s.continueOnSourceError,
 	}
 	// Create a function that will save the first error encountered (if
 	// any) and discard the rest.
@@ -347,17 +543,43 @@ func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumC
 	var wg sync.WaitGroup
 	// TODO: Maybe switch to using a semaphore.Weighted.
 	var unitPool errgroup.Group
-	if s.concurrentUnits != 0 {
+	if concurrentUnits != 0 {
 		// Negative values indicated no limit.
-		unitPool.SetLimit(s.concurrentUnits)
+		unitPool.SetLimit(concurrentUnits)
 	}
 	for unit := range unitReporter.unitCh {
 		unit := unit
+		checkpointKey := CheckpointKey(report.SourceName, unit)
+		if s.checkpoint != nil && s.checkpoint.Completed(checkpointKey) {
+			id, kind := unit.SourceUnitID()
+			ctx.Logger().V(3).Info("skipping unit completed in checkpoint", "unit", id, "unit_kind", kind)
+			continue
+		}
+		var unitErr error
 		chunkReporter := &mgrChunkReporter{
 			unit:    unit,
 			chunkCh: make(chan *Chunk, defaultChannelSize),
 			report:  report,
 		}
+		// The unit is only recorded once the results of all of its chunks
+		// have been reported.
+		var (
+			tracker *ChunkTracker
+			// interrupted is set if the scan was cancelled while the unit
+			// was being chunked. The job's context may be done by the time
+			// the results are reported.
+			interrupted bool
+		)
+		if s.checkpoint != nil {
+			tracker = NewChunkTracker(func() {
+				if unitErr != nil || interrupted {
+					return
+				}
+				if err := s.checkpoint.MarkCompleted(checkpointKey); err != nil {
+					ctx.Logger().Error(err, "error writing checkpoint")
+				}
+			})
+		}
 		// Consume units and produce chunks.
 		unitPool.Go(func() error {
 			report.StartUnitChunking(unit, time.Now())
@@ -367,6 +589,11 @@ func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumC
 			ctx := context.WithValues(ctx, "unit", id, "unit_kind", kind)
 			ctx.Logger().V(3).Info("chunking unit")
 			if err := source.ChunkUnit(ctx, unit, chunkReporter); err != nil {
+				unitErr = err
+				if s.continueOnSourceError && ctx.Err() == nil {
+					skipUnit(ctx, report, unit, err)
+					return nil
+				}
 				report.ReportError(Fatal{ChunkError{Unit: unit, Err: err}})
 				catchFirstFatal(Fatal{err})
 			}
@@ -381,8 +608,12 @@ func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumC
 				if src, ok := source.(Source); ok {
 					chunk.JobID = src.JobID()
 				}
-				s.outputChunks <- chunk
+				s.checkpoint.TrackChunk(chunk, tracker)
+				s.sendChunk(chunk)
 			}
+			// Every chunk of the unit has been handed off for scanning.
+			interrupted = ctx.Err() != nil
+			tracker.Done()
 		}()
 	}
 	wg.Wait()
@@ -394,6 +625,13 @@ func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumC
 	}
 }
 
+// skipUnit logs and reports the error of a unit that failed and is skipped.
+func skipUnit(ctx context.Context, report *JobProgress, unit SourceUnit, err error) {
+	err = Skipped{ChunkError{Unit: unit, Err: err}}
+	ctx.Logger().Error(err, "failed to scan unit, continuing with the other units")
+	report.ReportError(err)
+}
+
 // headlessAPI implements the apiClient interface locally.
 type headlessAPI struct {
 	// Counters for assigning source and job IDs.
@@ -411,6 +649,8 @@ var _ UnitReporter = (*mgrUnitReporter)(nil)
 type mgrUnitReporter struct {
 	unitCh chan SourceUnit
 	report *JobProgress
+	// Report errors as Skipped units.
+	continueOnError bool
 }
 
 // UnitOk implements the UnitReporter interface by recording the unit in the
@@ -423,6 +663,10 @@ func (s *mgrUnitReporter) UnitOk(ctx context.Context, unit SourceUnit) error {
 // UnitErr implements the UnitReporter interface by recording the error in the
 // report.
 func (s *mgrUnitReporter) UnitErr(ctx context.Context, err error) error {
+	if s.continueOnError {
+		err = Skipped{err}
+		ctx.Logger().Error(err, "failed to enumerate unit, continuing with the other units")
+	}
 	s.report.ReportError(err)
 	return nil
 }
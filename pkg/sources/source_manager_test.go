@@ -1,8 +1,11 @@
 package sources
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"sort"
 	"testing"
 	"time"
@@ -161,6 +164,7 @@ func TestSourceManagerReport(t *testing.T) {
 		{WithBufferedOutput(8)},
 		{WithBufferedOutput(8), WithSourceUnits()},
 		{WithBufferedOutput(8), WithSourceUnits(), WithConcurrentUnits(1)},
+		{WithBufferedOutput(8), WithSourceUnits(), WithConcurrentUnitsFunc(func(sourcespb.SourceType) int { return 1 })},
 	} {
 		mgr := NewManager(opts...)
 		source, err := buildDummy(&counterChunker{count: 4})
@@ -173,6 +177,133 @@ func TestSourceManagerReport(t *testing.T) {
 	}
 }
 
+func TestSourceManagerConcurrentUnitsFunc(t *testing.T) {
+	var sourceTypes []sourcespb.SourceType
+	mgr := NewManager(WithBufferedOutput(8), WithSourceUnits(), WithConcurrentUnits(1),
+		WithConcurrentUnitsFunc(func(sourceType sourcespb.SourceType) int {
+			sourceTypes = append(sourceTypes, sourceType)
+			return -1
+		}),
+	)
+	source, err := buildDummy(&counterChunker{count: 4})
+	assert.NoError(t, err)
+	ref, err := mgr.Run(context.Background(), "dummy", source)
+	assert.NoError(t, err)
+	<-ref.Done()
+	assert.NoError(t, mgr.Wait())
+
+	assert.Equal(t, []sourcespb.SourceType{1337}, sourceTypes)
+	assert.Equal(t, uint64(4), ref.Snapshot().TotalChunks)
+}
+
+func TestSourceManagerCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	// run scans the units and reports the results of every chunk except
+	// those with the unprocessed data.
+	run := func(steps []unitChunk, unprocessed ...string) []string {
+		cp, err := LoadCheckpoint(ctx, path, "hash")
+		assert.NoError(t, err)
+		mgr := NewManager(WithBufferedOutput(8), WithSourceUnits(), WithCheckpoint(cp))
+		source, err := buildDummy(&unitChunker{steps: steps})
+		assert.NoError(t, err)
+		ref, err := mgr.Run(ctx, "dummy", source)
+		assert.NoError(t, err)
+		<-ref.Done()
+
+		var output []string
+		for {
+			chunk, err := tryRead(mgr.Chunks())
+			if err != nil {
+				break
+			}
+			output = append(output, string(chunk.Data))
+			if !slices.Contains(unprocessed, string(chunk.Data)) {
+				cp.ChunkTracker(chunk).Done()
+			}
+		}
+		assert.NoError(t, cp.Flush())
+		sort.Strings(output)
+		return output
+	}
+
+	// A unit whose chunks were handed off but not processed isn't recorded.
+	assert.Equal(t, []string{"bar", "foo"}, run([]unitChunk{
+		{unit: "one", output: "foo"},
+		{unit: "two", output: "bar"},
+	}, "bar"))
+	assert.Equal(t, []string{"bar", "baz"}, run([]unitChunk{
+		{unit: "one", output: "foo"},
+		{unit: "two", output: "bar"},
+		{unit: "three", output: "baz"},
+	}))
+	// Completed units are skipped when the scan is resumed.
+	assert.Empty(t, run([]unitChunk{
+		{unit: "one", output: "foo"},
+		{unit: "two", output: "bar"},
+		{unit: "three", output: "baz"},
+	}))
+}
+
+// overlapChunker reads a single unit of data in chunks.
+type overlapChunker struct{ data []byte }
+
+func (c overlapChunker) Chunks(context.Context, chan *Chunk, ...ChunkingTarget) error { return nil }
+func (c overlapChunker) Enumerate(ctx context.Context, reporter UnitReporter) error {
+	return reporter.UnitOk(ctx, CommonSourceUnit{ID: "data"})
+}
+func (c overlapChunker) ChunkUnit(ctx context.Context, _ SourceUnit, reporter ChunkReporter) error {
+	for data := range NewChunkReader()(ctx, bytes.NewReader(c.data)) {
+		if err := reporter.ChunkOk(ctx, Chunk{Data: data.Bytes()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSourceManagerChunkOverlap(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 3*ChunkSize)
+
+	// Managers with different overlaps can run side by side.
+	for _, overlap := range []int{0, PeekSize / 2, 2 * PeekSize} {
+		overlap := overlap
+		t.Run(fmt.Sprintf("overlap=%d", overlap), func(t *testing.T) {
+			t.Parallel()
+			mgr := NewManager(WithBufferedOutput(8), WithSourceUnits(), WithChunkOverlap(overlap))
+			source, err := buildDummy(overlapChunker{data: data})
+			assert.NoError(t, err)
+			ref, err := mgr.Run(context.Background(), "dummy", source)
+			assert.NoError(t, err)
+			<-ref.Done()
+
+			want := overlap
+			if want == 0 {
+				want = PeekSize
+			}
+			chunk, err := tryRead(mgr.Chunks())
+			if assert.NoError(t, err) {
+				assert.Len(t, chunk.Data, ChunkSize+want)
+			}
+		})
+	}
+}
+
+func TestSourceManagerEnumerationOnly(t *testing.T) {
+	mgr := NewManager(WithBufferedOutput(8), WithSourceUnits(), WithEnumerationOnly())
+	source, err := buildDummy(&counterChunker{count: 4})
+	assert.NoError(t, err)
+	ref, err := mgr.Run(context.Background(), "dummy", source)
+	assert.NoError(t, err)
+	<-ref.Done()
+	assert.NoError(t, mgr.Wait())
+
+	assert.Equal(t, uint64(4), ref.Snapshot().TotalUnits)
+	assert.Equal(t, uint64(0), ref.Snapshot().TotalChunks)
+	_, ok := <-mgr.Chunks()
+	assert.False(t, ok)
+}
+
 type unitChunk struct {
 	unit   string
 	output string
@@ -302,6 +433,169 @@ func TestSourceManagerCancelRun(t *testing.T) {
 	assert.True(t, errors.Is(ref.Snapshot().FatalErrors(), cancelErr))
 }
 
+func TestSourceManagerDeadline(t *testing.T) {
+	// Sources that finish before the deadline are unaffected.
+	mgr := NewManager(WithBufferedOutput(8), WithDeadline(time.Now().Add(time.Minute)))
+	source, err := buildDummy(&counterChunker{count: 1})
+	assert.NoError(t, err)
+	_, err = mgr.Run(context.Background(), "dummy", source)
+	assert.NoError(t, err)
+	assert.NoError(t, mgr.Wait())
+	assert.False(t, mgr.DeadlineExceeded())
+
+	// Sources running at the deadline are cancelled without failing the scan, and the
+	// chunks they produced can still be read.
+	mgr = NewManager(WithBufferedOutput(8), WithDeadline(time.Now().Add(50*time.Millisecond)))
+	source, err = buildDummy(callbackChunker{func(ctx context.Context, ch chan *Chunk) error {
+		ch <- &Chunk{Data: []byte("partial")}
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	assert.NoError(t, err)
+	ref, err := mgr.Run(context.Background(), "dummy", source)
+	assert.NoError(t, err)
+	<-ref.Done()
+	assert.Error(t, ref.Snapshot().FatalError())
+
+	// Sources run after the deadline are skipped.
+	ref, err = mgr.Run(context.Background(), "dummy", source)
+	assert.NoError(t, err)
+	<-ref.Done()
+	assert.ErrorIs(t, ref.Snapshot().FatalError(), ErrDeadlineExceeded)
+
+	chunk, err := tryRead(mgr.Chunks())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("partial"), chunk.Data)
+	assert.NoError(t, mgr.Wait())
+	assert.True(t, mgr.DeadlineExceeded())
+}
+
+func TestSourceManagerStop(t *testing.T) {
+	mgr := NewManager(WithBufferedOutput(8))
+	started := make(chan struct{})
+	source, err := buildDummy(callbackChunker{func(ctx context.Context, ch chan *Chunk) error {
+		ch <- &Chunk{Data: []byte("partial")}
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	assert.NoError(t, err)
+	ref, err := mgr.Run(context.Background(), "dummy", source)
+	assert.NoError(t, err)
+
+	// Running sources are cancelled with the cause without failing the scan.
+	stopErr := errors.New("enough")
+	<-started
+	mgr.Stop(stopErr)
+	<-ref.Done()
+	assert.ErrorIs(t, ref.Snapshot().FatalErrors(), stopErr)
+
+	// Sources run after the stop are skipped.
+	other, err := buildDummy(&counterChunker{count: 1})
+	assert.NoError(t, err)
+	ref, err = mgr.Run(context.Background(), "dummy", other)
+	assert.NoError(t, err)
+	<-ref.Done()
+	assert.ErrorIs(t, ref.Snapshot().FatalError(), stopErr)
+
+	chunk, err := tryRead(mgr.Chunks())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("partial"), chunk.Data)
+	assert.NoError(t, mgr.Wait())
+}
+
+// failingUnitChunker fails to enumerate a group of units, and to scan the
+// "gone" unit.
+type failingUnitChunker struct {
+	unitErrFunc func(SourceUnit, error)
+}
+
+func (c *failingUnitChunker) Chunks(ctx context.Context, ch chan *Chunk, _ ...ChunkingTarget) error {
+	if c.unitErrFunc != nil {
+		c.unitErrFunc(CommonSourceUnit{ID: "gone"}, errors.New("not found"))
+	}
+	return common.CancellableWrite(ctx, ch, &Chunk{Data: []byte("ok")})
+}
+func (c *failingUnitChunker) Enumerate(ctx context.Context, rep UnitReporter) error {
+	if err := rep.UnitErr(ctx, errors.New("could not list group")); err != nil {
+		return err
+	}
+	for _, id := range []string{"ok", "gone"} {
+		if err := rep.UnitOk(ctx, CommonSourceUnit{ID: id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *failingUnitChunker) ChunkUnit(ctx context.Context, unit SourceUnit, rep ChunkReporter) error {
+	if id, _ := unit.SourceUnitID(); id == "gone" {
+		return errors.New("not found")
+	}
+	return rep.ChunkOk(ctx, Chunk{Data: []byte("ok")})
+}
+
+// unitErrorSource is a source that doesn't enumerate units but reports the
+// units that fail.
+type unitErrorSource struct {
+	DummySource
+	failing *failingUnitChunker
+}
+
+func (s *unitErrorSource) SetUnitErrorFunc(f func(SourceUnit, error)) { s.failing.unitErrFunc = f }
+
+func TestSourceManagerContinueOnSourceError(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []func(*SourceManager)
+		wantSkipped []string
+		wantErr     bool
+	}{
+		{
+			name:        "units",
+			opts:        []func(*SourceManager){WithSourceUnits(), WithContinueOnSourceError()},
+			wantSkipped: []string{`skipped: could not list group`, `skipped: error chunking unit "gone": not found`},
+		},
+		{
+			name:    "units without continuing",
+			opts:    []func(*SourceManager){WithSourceUnits()},
+			wantErr: true,
+		},
+		{
+			name:        "without units",
+			opts:        []func(*SourceManager){WithContinueOnSourceError()},
+			wantSkipped: []string{`skipped: error chunking unit "gone": not found`},
+		},
+		{
+			name: "without units or continuing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := NewManager(append(tt.opts, WithBufferedOutput(8))...)
+			failing := &failingUnitChunker{}
+			source := &unitErrorSource{DummySource: DummySource{chunker: failing}, failing: failing}
+			ref, err := mgr.Run(context.Background(), "dummy", source)
+			assert.NoError(t, err)
+			<-ref.Done()
+
+			var skipped []string
+			for _, err := range ref.Snapshot().SkippedErrors() {
+				skipped = append(skipped, err.Error())
+			}
+			assert.Equal(t, tt.wantSkipped, skipped)
+			chunk, err := tryRead(mgr.Chunks())
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("ok"), chunk.Data)
+			if tt.wantErr {
+				assert.Error(t, mgr.Wait())
+			} else {
+				assert.NoError(t, mgr.Wait())
+			}
+		})
+	}
+}
+
 func TestSourceManagerAvailableCapacity(t *testing.T) {
 	mgr := NewManager(WithConcurrentSources(1337))
 	start, end := make(chan struct{}), make(chan struct{})
@@ -323,6 +617,34 @@ func TestSourceManagerAvailableCapacity(t *testing.T) {
 	assert.Equal(t, 1337, mgr.AvailableCapacity())
 }
 
+func TestSourceManagerBufferedOutputBackPressure(t *testing.T) {
+	mgr := NewManager(WithBufferedOutput(2))
+	source, err := buildDummy(&counterChunker{count: 5})
+	assert.NoError(t, err)
+	ref, err := mgr.Run(context.Background(), "dummy", source)
+	assert.NoError(t, err)
+
+	// The source fills the buffer, then can't finish until its chunks are consumed.
+	assert.Eventually(t, func() bool { return len(mgr.Chunks()) == 2 }, 5*time.Second, time.Millisecond)
+	select {
+	case <-ref.Done():
+		t.Fatal("job should not finish while the output buffer is full")
+	default:
+	}
+
+	count := make(chan int)
+	go func() {
+		var chunks int
+		for range mgr.Chunks() {
+			chunks++
+		}
+		count <- chunks
+	}()
+	<-ref.Done()
+	assert.NoError(t, mgr.Wait())
+	assert.Equal(t, 5, <-count)
+}
+
 func TestSourceManagerUnitHook(t *testing.T) {
 	hook, ch := NewUnitHook(context.TODO())
 
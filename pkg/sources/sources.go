@@ -2,6 +2,7 @@ package sources
 
 import (
 	"sync"
+	"time"
 
 	"google.golang.org/protobuf/types/known/anypb"
 
@@ -169,7 +170,8 @@ type GCSConfig struct {
 	ServiceAccount string
 	// MaxObjectSize is the maximum object size to scan.
 	MaxObjectSize int64
-	// Concurrency is the number of concurrent workers to use to scan the source.
+	// Concurrency is the number of objects downloaded concurrently. It
+	// defaults to the engine's source concurrency.
 	Concurrency int
 	// IncludeBuckets is a list of buckets to include in the scan.
 	IncludeBuckets,
@@ -179,6 +181,8 @@ type GCSConfig struct {
 	IncludeObjects,
 	// ExcludeObjects is a list of objects to exclude from the scan.
 	ExcludeObjects []string
+	// Prefix limits the scan to objects whose names start with it.
+	Prefix string
 }
 
 // GitConfig defines the optional configuration for a git source.
@@ -187,7 +191,8 @@ type GitConfig struct {
 	HeadRef string
 	// BaseRef is the base reference to use to scan from.
 	BaseRef string
-	// MaxDepth is the maximum depth to scan the source.
+	// MaxDepth is the maximum number of most recent commits to scan on each
+	// scanned ref. Secrets introduced and removed in older commits are missed.
 	MaxDepth int
 	// Bare is an indicator to handle bare repositories properly.
 	Bare bool
@@ -202,6 +207,25 @@ type GitConfig struct {
 	ExcludeGlobs string
 	// SkipBinaries allows skipping binary files from the scan.
 	SkipBinaries bool
+	// IncludeSubmodules indicates whether to scan initialized submodules of the repository.
+	IncludeSubmodules bool
+	// Refs is a list of branches, tags, or other refs (e.g. refs/pull/123/head) to scan.
+	// When set, only commits reachable from these refs are scanned.
+	Refs []string
+	// ScanCommitMessages indicates whether to scan each commit message as its own chunk.
+	ScanCommitMessages bool
+	// FetchLFS indicates whether to download and scan the objects referenced by Git LFS pointer files.
+	FetchLFS bool
+	// MaxLFSObjectSize is the size in bytes above which LFS objects are skipped. Zero uses the default.
+	MaxLFSObjectSize int64
+	// WorkingTreeOnly indicates whether to scan only the uncommitted changes of the working tree.
+	WorkingTreeOnly bool
+	// ScanNotes indicates whether to scan the notes of the notes refs (refs/notes/*).
+	ScanNotes bool
+	// ScanStashes indicates whether to scan the changes of the entries of the stash (refs/stash).
+	ScanStashes bool
+	// SinceDate, if set, only scans the lines introduced by commits authored at or after this time.
+	SinceDate time.Time
 }
 
 // GithubConfig defines the optional configuration for a github source.
@@ -214,7 +238,8 @@ type GithubConfig struct {
 	IncludeForks bool
 	// IncludeMembers indicates whether to include members in the scan.
 	IncludeMembers bool
-	// Concurrency is the number of concurrent workers to use to scan the source.
+	// Concurrency is the number of repositories scanned concurrently. It
+	// defaults to the engine's source concurrency.
 	Concurrency int
 	// Repos is the list of repositories to scan.
 	Repos []string
@@ -236,6 +261,14 @@ type GithubConfig struct {
 	SkipBinaries bool
 	// IncludeWikis indicates whether to include repository wikis in the scan.
 	IncludeWikis bool
+	// IncrementalStateFile is the path of the file recording the last scanned commit of each
+	// repository. If set, only commits made since the previous scan are scanned.
+	IncrementalStateFile string
+	// PullRequest is the number of a pull request to scan. If set, only the lines added by the
+	// pull request are scanned. Requires exactly one repository.
+	PullRequest int
+	// ScanCommitMessages indicates whether to scan each commit message as its own chunk.
+	ScanCommitMessages bool
 }
 
 // GitlabConfig defines the optional configuration for a gitlab source.
@@ -250,6 +283,16 @@ type GitlabConfig struct {
 	Filter *common.Filter
 	// SkipBinaries allows skipping binary files from the scan.
 	SkipBinaries bool
+	// ScanMergeRequests enables scanning the descriptions and notes of merge requests.
+	ScanMergeRequests bool
+	// Groups are the IDs or full paths of groups whose projects, including
+	// those of their subgroups, are scanned if no repositories are given.
+	Groups []string
+	// SkipArchived skips archived projects when enumerating projects.
+	SkipArchived bool
+	// LastActivityAfter skips projects without activity since this time when
+	// enumerating projects, if set.
+	LastActivityAfter time.Time
 }
 
 // FilesystemConfig defines the optional configuration for a filesystem source.
@@ -260,6 +303,31 @@ type FilesystemConfig struct {
 	IncludePathsFile string
 	// ExcludePathsFile is the path to a file containing a list of regexps to exclude from the scan.
 	ExcludePathsFile string
+	// IncludePathsRegex is a list of regexps matched against paths relative to the scanned
+	// directory. If not empty, only matching files are scanned.
+	IncludePathsRegex []string
+	// ExcludePathsRegex is a list of regexps matched against paths relative to the scanned
+	// directory. Matching files are not scanned, even if they match IncludePathsRegex.
+	ExcludePathsRegex []string
+	// FollowSymlinks scans the files and directories symlinks point to instead
+	// of skipping them. Symlinks that loop back to a directory being scanned are skipped.
+	FollowSymlinks bool
+	// ConfineToRoot skips followed symlinks that point outside the scanned path.
+	ConfineToRoot bool
+	// SkipBinaries skips binary files, unless they are archives or other formats
+	// the handlers extract content from.
+	SkipBinaries bool
+	// AllowedBinaryExtensions is a list of extensions of binary files that are
+	// scanned even if SkipBinaries is set.
+	AllowedBinaryExtensions []string
+	// ScanExtensions limits the files and archive entries that are scanned to those
+	// with one of these extensions, with or without a leading dot and in any case.
+	// Archives are extracted whatever their extension. Every file is scanned if it's empty.
+	ScanExtensions []string
+	// BufferThreshold is the size in bytes above which files are chunked as they're read,
+	// or extracted from a temporary file for archives, instead of being held in memory.
+	// Zero uses the default of 10MB.
+	BufferThreshold uint64
 }
 
 // S3Config defines the optional configuration for an S3 source.
@@ -279,8 +347,22 @@ type S3Config struct {
 	IgnoreBuckets []string
 	// Roles is the list of Roles to use.
 	Roles []string
+	// ExternalID is the external ID to pass when assuming Roles.
+	ExternalID string
 	// MaxObjectSize is the maximum object size to scan.
 	MaxObjectSize int64
+	// ScanObjectVersions scans every version of the objects in buckets
+	// that have versioning enabled, instead of only the current versions.
+	ScanObjectVersions bool
+	// IncludePrefixes limits the objects that are scanned to those whose key
+	// starts with one of the prefixes, if it isn't empty.
+	IncludePrefixes []string
+	// ExcludePrefixes and ExcludeSuffixes skip the objects whose key starts
+	// or ends with one of them. They take precedence over IncludePrefixes.
+	ExcludePrefixes, ExcludeSuffixes []string
+	// Concurrency is the number of objects downloaded concurrently. It
+	// defaults to the engine's source concurrency.
+	Concurrency int
 }
 
 // SyslogConfig defines the optional configuration for a syslog source.
@@ -342,6 +424,53 @@ type ElasticsearchConfig struct {
 	BestEffortScan bool
 }
 
+// JiraConfig defines the optional configuration for a Jira source.
+type JiraConfig struct {
+	// Endpoint is the base URL of the Jira instance.
+	Endpoint string
+	// Token is a personal access token to authenticate with.
+	Token string
+	// Username and Password are the basic auth credentials to authenticate
+	// with. On Jira Cloud, the password is an API token.
+	Username string
+	Password string
+	// Projects is the list of keys of the projects to scan. Every project
+	// visible to the user is scanned if it is empty.
+	Projects []string
+	// IgnoreProjects is the list of keys of projects to skip.
+	IgnoreProjects []string
+	// JQL is a JQL query restricting the issues to scan.
+	JQL string
+	// UpdatedSince skips issues that weren't updated since this time, if set.
+	UpdatedSince time.Time
+	// InsecureSkipVerifyTLS disables TLS certificate verification.
+	InsecureSkipVerifyTLS bool
+}
+
+// ConfluenceConfig defines the optional configuration for a Confluence source.
+type ConfluenceConfig struct {
+	// Endpoint is the base URL of the Confluence instance, including its
+	// context path, e.g. https://example.atlassian.net/wiki.
+	Endpoint string
+	// Token is a personal access token to authenticate with.
+	Token string
+	// Username and Password are the basic auth credentials to authenticate
+	// with. On Confluence Cloud, the password is an API token.
+	Username string
+	Password string
+	// Spaces is the list of keys of the spaces to scan. Every space visible
+	// to the user is scanned if it is empty.
+	Spaces []string
+	// IgnoreSpaces is the list of keys of spaces to skip.
+	IgnoreSpaces []string
+	// IncludeAttachments scans the attachments of pages too.
+	IncludeAttachments bool
+	// ModifiedSince skips pages that weren't modified since this time, if set.
+	ModifiedSince time.Time
+	// InsecureSkipVerifyTLS disables TLS certificate verification.
+	InsecureSkipVerifyTLS bool
+}
+
 // Progress is used to update job completion progress across sources.
 type Progress struct {
 	mut               sync.Mutex
@@ -358,6 +487,14 @@ type Validator interface {
 	Validate(ctx context.Context) []error
 }
 
+// UnitErrorSetter is an interface for sources that scan several repositories,
+// buckets or other parts without enumerating them as units, and continue past
+// the ones that fail. Sources can optionally implement this interface to
+// report those failures to managers created with WithContinueOnSourceError.
+type UnitErrorSetter interface {
+	SetUnitErrorFunc(f func(unit SourceUnit, err error))
+}
+
 // SetProgressComplete sets job progress information for a running job based on the highest level objects in the source.
 // i is the current iteration in the loop of target scope
 // scope should be the len(scopedItems)
@@ -0,0 +1,83 @@
+package stdin
+
+import (
+	"io"
+	"os"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const SourceType = sourcespb.SourceType_SOURCE_TYPE_STDIN
+
+// Source scans the content piped to the standard input. The content is handled like a
+// file, so large inputs are buffered to disk and archives are extracted.
+type Source struct {
+	name     string
+	sourceId sources.SourceID
+	jobId    sources.JobID
+	verify   bool
+	// reader is the input that is scanned, os.Stdin unless set by tests.
+	reader io.Reader
+	sources.Progress
+}
+
+// Ensure the Source satisfies the interface at compile time.
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+// It is used for matching source types in configuration and job input.
+func (s *Source) Type() sourcespb.SourceType {
+	return SourceType
+}
+
+func (s *Source) SourceID() sources.SourceID {
+	return s.sourceId
+}
+
+func (s *Source) JobID() sources.JobID {
+	return s.jobId
+}
+
+// Init returns an initialized stdin source.
+func (s *Source) Init(_ context.Context, name string, jobId sources.JobID, sourceId sources.SourceID, verify bool, connection *anypb.Any, _ int) error {
+	s.name = name
+	s.sourceId = sourceId
+	s.jobId = jobId
+	s.verify = verify
+	if s.reader == nil {
+		s.reader = os.Stdin
+	}
+
+	var conn sourcespb.Stdin
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return errors.WrapPrefix(err, "error unmarshalling connection", 0)
+	}
+	return nil
+}
+
+// Chunks emits the chunks of the input read from the standard input.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ ...sources.ChunkingTarget) error {
+	chunkSkel := &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		JobID:      s.JobID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Stdin{Stdin: &source_metadatapb.Stdin{}},
+		},
+		Verify: s.verify,
+	}
+	if err := handlers.HandleFile(ctx, io.NopCloser(s.reader), chunkSkel, sources.ChanReporter{Ch: chunksChan}); err != nil {
+		return errors.WrapPrefix(err, "error scanning stdin", 0)
+	}
+	s.SetProgressComplete(1, 1, "Finished scanning stdin", "")
+	return nil
+}
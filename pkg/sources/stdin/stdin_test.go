@@ -0,0 +1,46 @@
+package stdin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func TestSource_Chunks(t *testing.T) {
+	ctx := context.Background()
+	conn, err := anypb.New(&sourcespb.Stdin{})
+	assert.NoError(t, err)
+
+	input := "first line\n" + strings.Repeat("x\n", sources.ChunkSize/2) + "secret=value\n"
+	s := Source{reader: strings.NewReader(input)}
+	assert.NoError(t, s.Init(ctx, "test", 1, 2, true, conn, 1))
+
+	chunksCh := make(chan *sources.Chunk, 8)
+	assert.NoError(t, s.Chunks(ctx, chunksCh))
+	close(chunksCh)
+
+	var (
+		data  strings.Builder
+		lines []int64
+	)
+	for chunk := range chunksCh {
+		assert.Equal(t, SourceType, chunk.SourceType)
+		assert.Equal(t, sources.SourceID(2), chunk.SourceID)
+		assert.True(t, chunk.Verify)
+		metadata := chunk.SourceMetadata.GetStdin()
+		if assert.NotNil(t, metadata) {
+			lines = append(lines, metadata.GetLine())
+		}
+		data.Write(chunk.Data[:min(len(chunk.Data), sources.ChunkSize)])
+	}
+	assert.Equal(t, input, data.String())
+	// The second chunk starts on the line after the first chunk.
+	assert.Equal(t, []int64{1, 1 + int64(strings.Count(input[:sources.ChunkSize], "\n"))}, lines)
+	assert.Equal(t, int64(100), s.GetProgress().PercentComplete)
+}
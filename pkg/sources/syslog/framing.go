@@ -0,0 +1,71 @@
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+)
+
+const (
+	// maxMessageSize bounds the size of a message read from a TCP stream.
+	// Longer newline-delimited messages are split, and frames with a larger
+	// octet count are read as newline-delimited.
+	maxMessageSize = 64 * 1024
+	// maxOctetCountDigits is the number of digits of maxMessageSize.
+	maxOctetCountDigits = 5
+)
+
+// newFrameReader returns a reader of the messages framed in a TCP stream.
+func newFrameReader(r io.Reader) *bufio.Reader {
+	return bufio.NewReaderSize(r, maxMessageSize)
+}
+
+// readFrame reads the next message of a TCP stream. Messages are framed
+// either with a leading octet count (RFC 6587 section 3.4.1), as is required
+// over TLS, or with a trailing newline (RFC 6587 section 3.4.2). The partial
+// message read before an error is returned along with the error.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	if n, ok := readOctetCount(r); ok {
+		message := make([]byte, n)
+		read, err := io.ReadFull(r, message)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.EOF
+		}
+		return message[:read], err
+	}
+
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		err = nil
+	}
+	// The slice is only valid until the next read.
+	return bytes.Clone(bytes.TrimRight(line, "\r\n")), err
+}
+
+// readOctetCount reads the octet count framing the next message, if it is
+// framed with one. Newline-delimited messages start with a priority in angle
+// brackets, so only as many bytes as needed to tell the framings apart are
+// peeked, and reading a complete newline-delimited message never blocks.
+func readOctetCount(r *bufio.Reader) (int, bool) {
+	for i := 1; i <= maxOctetCountDigits+1; i++ {
+		header, err := r.Peek(i)
+		if err != nil {
+			return 0, false
+		}
+		c := header[i-1]
+		switch {
+		case c == ' ' && i > 1:
+			n, err := strconv.Atoi(string(header[:i-1]))
+			if err != nil || n > maxMessageSize {
+				return 0, false
+			}
+			_, _ = r.Discard(i)
+			return n, true
+		case c < '0' || c > '9' || (i == 1 && c == '0'):
+			return 0, false
+		}
+	}
+	return 0, false
+}
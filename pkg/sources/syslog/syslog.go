@@ -1,10 +1,12 @@
 package syslog
 
 import (
+	"bytes"
 	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
+	"regexp"
 	"runtime"
 	"strconv"
 	"time"
@@ -226,65 +228,119 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 	}
 }
 
+var (
+	// priorityPrefix matches the priority every syslog message starts with.
+	priorityPrefix = regexp.MustCompile(`^<[0-9]{1,3}>`)
+	// rfc5424Prefix matches the priority and version of RFC 5424 messages,
+	// which RFC 3164 messages never have.
+	rfc5424Prefix = regexp.MustCompile(`^<[0-9]{1,3}>[1-9][0-9]{0,2} `)
+)
+
+// parseSyslogMetadata returns the metadata of a message. The format of each
+// message is detected from its header, so messages in either format are
+// parsed. Malformed messages are still scanned as raw text, so metadata with
+// only the client is returned along with the error.
 func (s *Source) parseSyslogMetadata(input []byte, remote string) (*source_metadatapb.MetaData, error) {
-	var metadata *source_metadatapb.MetaData
-	switch s.conn.Format {
+	malformed := s.syslog.sourceMetadataFunc(nilString, nilString, nilString, nilString, nilString, remote)
+	if !priorityPrefix.Match(input) {
+		return malformed, fmt.Errorf("message doesn't start with a priority")
+	}
+
+	format := "rfc3164"
+	if rfc5424Prefix.Match(input) {
+		format = "rfc5424"
+	}
+	metadata, err := s.parseFormat(format, input, remote)
+	if err != nil {
+		return malformed, err
+	}
+	return metadata, nil
+}
+
+func (s *Source) parseFormat(format string, input []byte, remote string) (*source_metadatapb.MetaData, error) {
+	switch format {
 	case "rfc5424":
 		message := &rfc5424.Message{}
 		err := message.UnmarshalBinary(input)
 		if err != nil {
-			return metadata, errors.WrapPrefix(err, "could not parse syslog as rfc5424", 0)
+			return nil, errors.WrapPrefix(err, "could not parse syslog as rfc5424", 0)
 		}
-		metadata = s.syslog.sourceMetadataFunc(message.Hostname, message.AppName, message.ProcessID, message.Timestamp.String(), nilString, remote)
-	case "rfc3164":
+		return s.syslog.sourceMetadataFunc(message.Hostname, message.AppName, message.ProcessID, message.Timestamp.String(), nilString, remote), nil
+	default:
 		parser := rfc3164.NewParser(input)
 		err := parser.Parse()
 		if err != nil {
-			return metadata, errors.WrapPrefix(err, "could not parse syslog as rfc3164", 0)
+			return nil, errors.WrapPrefix(err, "could not parse syslog as rfc3164", 0)
 		}
 		data := parser.Dump()
-		metadata = s.syslog.sourceMetadataFunc(data["hostname"].(string), nilString, nilString, data["timestamp"].(time.Time).String(), strconv.Itoa(data["facility"].(int)), remote)
+		hostname, _ := data["hostname"].(string)
+		appname, _ := data["tag"].(string)
+		timestamp, _ := data["timestamp"].(time.Time)
+		facility, _ := data["facility"].(int)
+		return s.syslog.sourceMetadataFunc(hostname, appname, nilString, timestamp.String(), strconv.Itoa(facility), remote), nil
 	}
-	return metadata, nil
 }
 
+// sendMessage emits a message as a chunk.
+func (s *Source) sendMessage(ctx context.Context, message []byte, remote string, chunksChan chan *sources.Chunk) error {
+	message = bytes.TrimRight(message, "\r\n\x00")
+	if len(message) == 0 {
+		return nil
+	}
+	metadata, err := s.parseSyslogMetadata(message, remote)
+	if err != nil {
+		ctx.Logger().V(2).Info("failed to parse syslog message, scanning it as raw text", "error", err)
+	}
+	return common.CancellableWrite(ctx, chunksChan, &sources.Chunk{
+		SourceName:     s.syslog.sourceName,
+		SourceID:       s.syslog.sourceID,
+		SourceType:     s.syslog.sourceType,
+		JobID:          s.JobID(),
+		SourceMetadata: metadata,
+		Data:           message,
+		Verify:         s.verify,
+	})
+}
+
+// closeWhenDone closes c once ctx is done, to interrupt blocked calls. The
+// returned function stops waiting for ctx.
+func closeWhenDone(ctx context.Context, c io.Closer) func() {
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.Close()
+		case <-stop:
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// monitorConnection emits each message framed in a TCP connection as a chunk.
 func (s *Source) monitorConnection(ctx context.Context, conn net.Conn, chunksChan chan *sources.Chunk) {
 	defer common.RecoverWithExit(ctx)
+	defer conn.Close()
+	defer closeWhenDone(ctx, conn)()
+
+	remote := conn.RemoteAddr().String()
+	reader := newFrameReader(conn)
 	for {
-		if common.IsDone(ctx) {
+		message, err := readFrame(reader)
+		ctx.Logger().V(5).Info(string(message))
+		if err := s.sendMessage(ctx, message, remote, chunksChan); err != nil {
 			return
 		}
-		err := conn.SetDeadline(time.Now().Add(time.Second))
-		if err != nil {
-			ctx.Logger().V(2).Info("could not set connection deadline", "error", err)
-		}
-		input := make([]byte, 8096)
-		remote := conn.RemoteAddr()
-		_, err = conn.Read(input)
 		if err != nil {
-			if errors.Is(err, io.EOF) {
-				return
+			if !errors.Is(err, io.EOF) && !common.IsDone(ctx) {
+				ctx.Logger().V(2).Info("failed to read from TCP connection", "error", err)
 			}
-			continue
-		}
-		ctx.Logger().V(5).Info(string(input))
-		metadata, err := s.parseSyslogMetadata(input, remote.String())
-		if err != nil {
-			ctx.Logger().V(2).Info("failed to generate metadata", "error", err)
-		}
-		chunksChan <- &sources.Chunk{
-			SourceName:     s.syslog.sourceName,
-			SourceID:       s.syslog.sourceID,
-			SourceType:     s.syslog.sourceType,
-			JobID:          s.JobID(),
-			SourceMetadata: metadata,
-			Data:           input,
-			Verify:         s.verify,
+			return
 		}
 	}
 }
 
 func (s *Source) acceptTCPConnections(ctx context.Context, netListener net.Listener, chunksChan chan *sources.Chunk) error {
+	defer closeWhenDone(ctx, netListener)()
 	for {
 		if common.IsDone(ctx) {
 			return nil
@@ -307,26 +363,17 @@ func (s *Source) acceptUDPConnections(ctx context.Context, netListener net.Packe
 		if err != nil {
 			ctx.Logger().V(2).Info("could not update connection deadline", "error", err)
 		}
+		// Each datagram holds a single message.
 		input := make([]byte, 65535)
-		_, remote, err := netListener.ReadFrom(input)
+		n, remote, err := netListener.ReadFrom(input)
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				return nil
 			}
 			continue
 		}
-		metadata, err := s.parseSyslogMetadata(input, remote.String())
-		if err != nil {
-			ctx.Logger().V(2).Info("failed to parse metadata", "error", err)
-		}
-		chunksChan <- &sources.Chunk{
-			SourceName:     s.syslog.sourceName,
-			SourceID:       s.syslog.sourceID,
-			JobID:          s.JobID(),
-			SourceType:     s.syslog.sourceType,
-			SourceMetadata: metadata,
-			Data:           input,
-			Verify:         s.verify,
+		if err := s.sendMessage(ctx, input[:n], remote.String(), chunksChan); err != nil {
+			return nil
 		}
 	}
 }
@@ -0,0 +1,173 @@
+package syslog
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	rfc5424Message = `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 - password=hunter2`
+	rfc3164Message = `<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8`
+)
+
+func TestReadFrame(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "newline delimited",
+			input: rfc3164Message + "\n" + rfc5424Message + "\r\n",
+			want:  []string{rfc3164Message, rfc5424Message},
+		},
+		{
+			name:  "octet counted",
+			input: "12 line1\nline2 7 <1>text",
+			want:  []string{"line1\nline2 ", "<1>text"},
+		},
+		{
+			name:  "mixed",
+			input: "5 hello<1>world\n",
+			want:  []string{"hello", "<1>world"},
+		},
+		{
+			name:  "invalid octet count",
+			input: "0 zero\n123456789 too large\n12abc\n",
+			want:  []string{"0 zero", "123456789 too large", "12abc"},
+		},
+		{
+			name:  "truncated",
+			input: "20 short",
+			want:  []string{"short"},
+		},
+		{
+			name:  "unterminated",
+			input: "<1>no newline",
+			want:  []string{"<1>no newline"},
+		},
+		{
+			name:  "too long",
+			input: strings.Repeat("x", maxMessageSize+10) + "\n",
+			want:  []string{strings.Repeat("x", maxMessageSize), strings.Repeat("x", 10)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFrameReader(strings.NewReader(tt.input))
+			var got []string
+			for {
+				message, err := readFrame(r)
+				if len(message) > 0 {
+					got = append(got, string(message))
+				}
+				if err != nil {
+					assert.ErrorIs(t, err, io.EOF)
+					break
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadFrameDoesNotBlock(t *testing.T) {
+	// A complete newline-delimited message is read without waiting for more data.
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	go func() { _, _ = client.Write([]byte("<1>msg\n")) }()
+
+	_ = server.SetDeadline(time.Now().Add(5 * time.Second))
+	message, err := readFrame(newFrameReader(server))
+	assert.NoError(t, err)
+	assert.Equal(t, "<1>msg", string(message))
+}
+
+func newTestSource(t *testing.T, conn *sourcespb.Syslog) *Source {
+	t.Helper()
+	connection, err := anypb.New(conn)
+	assert.NoError(t, err)
+	s := &Source{}
+	assert.NoError(t, s.Init(context.Background(), "test", 0, 0, false, connection, 1))
+	return s
+}
+
+func TestParseSyslogMetadata(t *testing.T) {
+	for _, format := range []string{"rfc3164", "rfc5424"} {
+		t.Run(format, func(t *testing.T) {
+			s := newTestSource(t, &sourcespb.Syslog{Format: format})
+
+			metadata, err := s.parseSyslogMetadata([]byte(rfc5424Message), "10.0.0.1:514")
+			assert.NoError(t, err)
+			assert.Equal(t, "mymachine.example.com", metadata.GetSyslog().GetHostname())
+			assert.Equal(t, "evntslog", metadata.GetSyslog().GetAppname())
+			assert.Contains(t, metadata.GetSyslog().GetTimestamp(), "2003-10-11 22:14:15")
+
+			metadata, err = s.parseSyslogMetadata([]byte(rfc3164Message), "10.0.0.1:514")
+			assert.NoError(t, err)
+			assert.Equal(t, "mymachine", metadata.GetSyslog().GetHostname())
+			assert.Equal(t, "su", metadata.GetSyslog().GetAppname())
+			assert.Contains(t, metadata.GetSyslog().GetTimestamp(), "22:14:15")
+			assert.Equal(t, "4", metadata.GetSyslog().GetFacility())
+
+			metadata, err = s.parseSyslogMetadata([]byte("token=abc"), "10.0.0.1:514")
+			assert.Error(t, err)
+			assert.Equal(t, "10.0.0.1:514", metadata.GetSyslog().GetClient())
+		})
+	}
+}
+
+func TestSource_ChunksTCP(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	s := newTestSource(t, &sourcespb.Syslog{Protocol: "tcp", ListenAddress: lis.Addr().String()})
+
+	chunksCh := make(chan *sources.Chunk, 8)
+	done := make(chan error)
+	go func() { done <- s.acceptTCPConnections(ctx, lis, chunksCh) }()
+
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	assert.NoError(t, err)
+	var input bytes.Buffer
+	input.WriteString(rfc3164Message + "\n")
+	input.WriteString("not syslog: password=hunter2\n")
+	input.WriteString(strconv.Itoa(len(rfc5424Message)) + " " + rfc5424Message)
+	_, err = conn.Write(input.Bytes())
+	assert.NoError(t, err)
+	assert.NoError(t, conn.Close())
+
+	var got []string
+	for len(got) < 3 {
+		select {
+		case chunk := <-chunksCh:
+			got = append(got, chunk.SourceMetadata.GetSyslog().GetHostname()+" "+string(chunk.Data))
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for chunks")
+		}
+	}
+	assert.Equal(t, []string{
+		"mymachine " + rfc3164Message,
+		" not syslog: password=hunter2",
+		"mymachine.example.com " + rfc5424Message,
+	}, got)
+
+	// The listener is closed once the scan is done.
+	cancel()
+	assert.NoError(t, <-done)
+}